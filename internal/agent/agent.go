@@ -0,0 +1,381 @@
+// Package agent runs tasks for a runbook server on another machine. An
+// agent registers with the server's HTTP API under a set of tags, polls for
+// runs of tasks whose runner names one of them, and streams their output
+// and results back to the server's session logs.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/task"
+)
+
+// apiPath is the prefix of the server's REST API, server.APIPath.
+const apiPath = "/api"
+
+// flushInterval is how often a running job's output is sent to the server.
+const flushInterval = 500 * time.Millisecond
+
+// keepaliveInterval is how often a running job checks in with the server
+// when it has no output, so the server knows the agent is alive and the
+// agent learns when the server has given up on the job.
+const keepaliveInterval = task.AgentTimeout / 4
+
+// maxChunk bounds one output request, below the server's limit.
+const maxChunk = 512 << 10
+
+// retryInterval is how long the agent waits after failing to reach the
+// server before trying again.
+const retryInterval = 5 * time.Second
+
+// errJobGone is returned when the server no longer wants a job's output,
+// because the run timed out or was cancelled there.
+var errJobGone = errors.New("job is gone")
+
+// errUnregistered is returned when the server does not know the agent,
+// usually because it restarted; the agent registers again.
+var errUnregistered = errors.New("agent is not registered")
+
+// Options configures an agent.
+type Options struct {
+	Server string               // Address of the runbook serve instance, e.g. http://build-host:8080
+	Name   string               // Name the server shows for the agent
+	Tags   []string             // Runner tags of the tasks the agent runs
+	Dir    string               // Directory relative job working directories resolve against
+	Jobs   int                  // Jobs run at once; 0 means 1
+	Log    io.Writer            // Progress messages; nil discards them
+	Client mcputil.ClientConfig // Credentials and TLS settings for the server
+}
+
+// Agent runs jobs for one server.
+type Agent struct {
+	opts   Options
+	base   string
+	client *http.Client
+	mu     sync.Mutex
+	id     string
+}
+
+// New creates an agent for opts. It fails when the client TLS files cannot
+// be loaded.
+func New(opts Options) (*Agent, error) {
+	if opts.Jobs <= 0 {
+		opts.Jobs = 1
+	}
+	if opts.Log == nil {
+		opts.Log = io.Discard
+	}
+	base := strings.TrimSuffix(strings.TrimRight(opts.Server, "/"), mcputil.EndpointPath)
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	client, err := opts.Client.HTTPClient(task.AgentPollWait + 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &Agent{
+		opts:   opts,
+		base:   base,
+		client: client,
+	}, nil
+}
+
+// Run registers the agent and runs jobs until ctx is done. It returns an
+// error when the first registration fails; later connection failures are
+// retried.
+func (a *Agent) Run(ctx context.Context) error {
+	if err := a.register(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.opts.Log, "Registered with %s as '%s' (tags: %s)\n", a.base, a.opts.Name, strings.Join(a.opts.Tags, ", "))
+
+	slots := make(chan struct{}, a.opts.Jobs)
+	var running sync.WaitGroup
+	defer running.Wait()
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+
+		job, err := a.next(ctx)
+		if err != nil {
+			<-slots
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, errUnregistered) {
+				fmt.Fprintln(a.opts.Log, "Server does not know this agent, registering again")
+				if err := a.register(ctx); err == nil {
+					continue
+				}
+			}
+			fmt.Fprintf(a.opts.Log, "Warning: %v; retrying in %s\n", err, retryInterval)
+			select {
+			case <-time.After(retryInterval):
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+		if job == nil {
+			<-slots
+			continue
+		}
+
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			defer func() { <-slots }()
+			a.runJob(ctx, job)
+		}()
+	}
+}
+
+// register registers the agent with the server and keeps the ID it gets.
+func (a *Agent) register(ctx context.Context) error {
+	body, _ := json.Marshal(task.AgentRegistration{Name: a.opts.Name, Tags: a.opts.Tags})
+	resp, err := a.do(ctx, http.MethodPost, "/agents", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to register with %s: %w", a.base, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to register with %s: %s", a.base, responseError(resp))
+	}
+	var info task.AgentInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return fmt.Errorf("failed to register with %s: %w", a.base, err)
+	}
+	a.mu.Lock()
+	a.id = info.ID
+	a.mu.Unlock()
+	return nil
+}
+
+// next waits for the server to hand the agent a job. It returns nil when
+// the server had none within its poll window.
+func (a *Agent) next(ctx context.Context) (*task.AgentJob, error) {
+	resp, err := a.do(ctx, http.MethodGet, "/agents/"+a.agentID()+"/jobs/next", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var job task.AgentJob
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			return nil, fmt.Errorf("invalid job: %w", err)
+		}
+		return &job, nil
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusNotFound:
+		return nil, errUnregistered
+	}
+	return nil, fmt.Errorf("failed to get a job: %s", responseError(resp))
+}
+
+// runJob runs a job's command, streaming its output to the server, and
+// reports how it ended. The command is killed when its timeout passes or
+// the server gives up on it.
+func (a *Agent) runJob(ctx context.Context, job *task.AgentJob) {
+	fmt.Fprintf(a.opts.Log, "Running '%s' (session %s)\n", job.Task, job.SessionID)
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if job.Timeout > 0 {
+		jobCtx, cancel = context.WithTimeout(jobCtx, time.Duration(job.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	shell := job.Shell
+	if shell == "" {
+		shell = "/bin/bash"
+	}
+	argv := config.ShellArgs(shell, job.Command)
+	cmd := exec.CommandContext(jobCtx, argv[0], argv[1:]...)
+	cmd.Dir = a.opts.Dir
+	if job.WorkingDir != "" {
+		cmd.Dir = job.WorkingDir
+		if !filepath.IsAbs(job.WorkingDir) {
+			cmd.Dir = filepath.Join(a.opts.Dir, job.WorkingDir)
+		}
+	}
+	cmd.Env = job.EnvPolicy.Filter(os.Environ())
+	for key, value := range job.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	var stdout, stderr outputBuffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	result := task.AgentResult{}
+	if err := cmd.Start(); err != nil {
+		result = task.AgentResult{ExitCode: -1, Error: fmt.Sprintf("failed to start command: %v", err)}
+		a.complete(ctx, job, result)
+		return
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	lastSent := time.Now()
+	for waiting := true; waiting; {
+		select {
+		case <-done:
+			waiting = false
+		case <-flush.C:
+			sent, err := a.sendOutput(ctx, job, &stdout, &stderr, time.Since(lastSent) >= keepaliveInterval)
+			if sent {
+				lastSent = time.Now()
+			}
+			if errors.Is(err, errJobGone) || errors.Is(err, errUnregistered) {
+				fmt.Fprintf(a.opts.Log, "Server gave up on '%s' (session %s), killing it\n", job.Task, job.SessionID)
+				cancel()
+				<-done
+				return
+			}
+		}
+	}
+	_, _ = a.sendOutput(ctx, job, &stdout, &stderr, false)
+
+	result.ExitCode = cmd.ProcessState.ExitCode()
+	if errors.Is(jobCtx.Err(), context.DeadlineExceeded) {
+		result = task.AgentResult{ExitCode: -1, Error: fmt.Sprintf("command timed out after %d seconds", job.Timeout)}
+	}
+	a.complete(ctx, job, result)
+}
+
+// sendOutput sends the output buffered since the last call, or an empty
+// keepalive when there is none and keepalive is set. It reports whether it
+// sent anything.
+func (a *Agent) sendOutput(ctx context.Context, job *task.AgentJob, stdout, stderr *outputBuffer, keepalive bool) (bool, error) {
+	sent := false
+	for _, s := range []struct {
+		name string
+		buf  *outputBuffer
+	}{{"stdout", stdout}, {"stderr", stderr}} {
+		data := s.buf.take()
+		if len(data) == 0 && (!keepalive || s.name != "stdout") {
+			continue
+		}
+		for first := true; first || len(data) > 0; first = false {
+			chunk := data[:min(len(data), maxChunk)]
+			data = data[len(chunk):]
+			resp, err := a.do(ctx, http.MethodPost, "/agents/"+a.agentID()+"/jobs/"+job.ID+"/output?stream="+s.name, bytes.NewReader(chunk))
+			if err != nil {
+				return sent, err
+			}
+			resp.Body.Close()
+			if err := jobStatusError(resp); err != nil {
+				return sent, err
+			}
+			sent = true
+		}
+	}
+	return sent, nil
+}
+
+// complete reports how a job ended.
+func (a *Agent) complete(ctx context.Context, job *task.AgentJob, result task.AgentResult) {
+	body, _ := json.Marshal(result)
+	resp, err := a.do(ctx, http.MethodPost, "/agents/"+a.agentID()+"/jobs/"+job.ID+"/result", bytes.NewReader(body))
+	if err == nil {
+		resp.Body.Close()
+		err = jobStatusError(resp)
+	}
+	if err != nil {
+		fmt.Fprintf(a.opts.Log, "Warning: failed to report the result of '%s' (session %s): %v\n", job.Task, job.SessionID, err)
+		return
+	}
+	fmt.Fprintf(a.opts.Log, "Finished '%s' (session %s) with exit code %d\n", job.Task, job.SessionID, result.ExitCode)
+}
+
+// do sends a request to the server's API, with the client credentials and
+// the agent token when $RUNBOOK_AGENT_TOKEN is set.
+func (a *Agent) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, a.base+apiPath+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range a.opts.Client.Headers() {
+		req.Header.Set(key, value)
+	}
+	if token := os.Getenv(mcputil.AgentTokenEnv); token != "" {
+		req.Header.Set(mcputil.AgentTokenHeader, token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return a.client.Do(req)
+}
+
+// agentID returns the ID the server gave the agent.
+func (a *Agent) agentID() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.id
+}
+
+// jobStatusError returns the error a response to a job request stands for,
+// or nil for success.
+func jobStatusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	case http.StatusGone:
+		return errJobGone
+	case http.StatusNotFound:
+		return errUnregistered
+	}
+	return fmt.Errorf("server answered %s", resp.Status)
+}
+
+// responseError describes a failed API response, using the server's error
+// message when it sent one.
+func responseError(resp *http.Response) string {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Error != "" {
+		return body.Error
+	}
+	return resp.Status
+}
+
+// outputBuffer collects a command's output between sends.
+type outputBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write appends p.
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// take returns the buffered output and empties the buffer.
+func (b *outputBuffer) take() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data := bytes.Clone(b.buf.Bytes())
+	b.buf.Reset()
+	return data
+}
@@ -0,0 +1,142 @@
+// Package auth authenticates requests to the HTTP server. An Authenticator
+// decides whether a request may proceed; Middleware enforces that decision
+// in front of the MCP endpoint, dashboard, and metrics.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// DefaultTokenEnv is the environment variable holding the bearer token, for
+// both the server and CLI clients.
+const DefaultTokenEnv = "RUNBOOK_TOKEN"
+
+// DefaultTimeout bounds how long a command or webhook may take to decide.
+const DefaultTimeout = 5 * time.Second
+
+// ErrUnauthorized is returned by authenticators that reject a request.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Identity describes an authenticated caller.
+type Identity struct {
+	Subject string `json:"subject"`
+}
+
+// Authenticator decides whether a request may proceed. Returning an error
+// rejects the request with 401 Unauthorized.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Identity, error)
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(r *http.Request) (Identity, error)
+
+// Authenticate calls f(r).
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (Identity, error) {
+	return f(r)
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity Middleware attached to a request
+// context, if any.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// Middleware rejects requests that a fails to authenticate and attaches the
+// caller's identity to the context of the rest.
+func Middleware(a Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := a.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="runbook"`)
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	})
+}
+
+// FromConfig builds the authenticator for the server.auth settings. It
+// returns nil when auth is not configured.
+func FromConfig(cfg config.AuthConfig) (Authenticator, error) {
+	timeout := DefaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case config.AuthTypeBearer:
+		env := cfg.TokenEnv
+		if env == "" {
+			env = DefaultTokenEnv
+		}
+		token := os.Getenv(env)
+		if token == "" {
+			return nil, fmt.Errorf("bearer auth requires a token in $%s", env)
+		}
+		return BearerToken(token), nil
+	case config.AuthTypeMTLS:
+		return ClientCert(cfg.AllowedSubjects), nil
+	case config.AuthTypeCommand:
+		return &Command{Command: cfg.Command, Timeout: timeout}, nil
+	case config.AuthTypeWebhook:
+		return &Webhook{URL: cfg.URL, Timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth type '%s'", cfg.Type)
+	}
+}
+
+// BearerToken accepts requests carrying "Authorization: Bearer <token>".
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (Identity, error) {
+		got, ok := bearerToken(r)
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return Identity{}, ErrUnauthorized
+		}
+		return Identity{Subject: "bearer"}, nil
+	})
+}
+
+// ClientCert accepts requests whose TLS client certificate was verified by
+// the server. With allowed subjects, the certificate's common name must be
+// one of them.
+func ClientCert(allowedSubjects []string) Authenticator {
+	allowed := make(map[string]bool, len(allowedSubjects))
+	for _, s := range allowedSubjects {
+		allowed[s] = true
+	}
+	return AuthenticatorFunc(func(r *http.Request) (Identity, error) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return Identity{}, errors.New("client certificate required")
+		}
+		subject := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		if len(allowed) > 0 && !allowed[subject] {
+			return Identity{}, fmt.Errorf("client certificate '%s' is not allowed", subject)
+		}
+		return Identity{Subject: subject}, nil
+	})
+}
+
+// bearerToken extracts the token from an Authorization: Bearer header.
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
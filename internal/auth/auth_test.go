@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestMiddleware(t *testing.T) {
+	var got Identity
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = IdentityFromContext(r.Context())
+	})
+	h := Middleware(BearerToken("secret"), next)
+
+	req := httptest.NewRequest("GET", "/mcp", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status = %d, want 401", rec.Code)
+	}
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("expected WWW-Authenticate header")
+	}
+
+	req = httptest.NewRequest("GET", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: status = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid token: status = %d, want 200", rec.Code)
+	}
+	if got.Subject != "bearer" {
+		t.Errorf("subject = %q, want bearer", got.Subject)
+	}
+}
+
+func TestClientCert(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ci-bot"}}
+	withCert := func() *http.Request {
+		req := httptest.NewRequest("GET", "/mcp", nil)
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		return req
+	}
+
+	id, err := ClientCert(nil).Authenticate(withCert())
+	if err != nil || id.Subject != "ci-bot" {
+		t.Errorf("any subject: got (%v, %v)", id, err)
+	}
+	if _, err := ClientCert([]string{"ci-bot"}).Authenticate(withCert()); err != nil {
+		t.Errorf("allowed subject rejected: %v", err)
+	}
+	if _, err := ClientCert([]string{"deploy"}).Authenticate(withCert()); err == nil {
+		t.Error("expected subject outside the allowlist to be rejected")
+	}
+	if _, err := ClientCert(nil).Authenticate(httptest.NewRequest("GET", "/mcp", nil)); err == nil {
+		t.Error("expected request without a certificate to be rejected")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	a := &Command{Command: `[ "$RUNBOOK_AUTH_TOKEN" = "letmein" ] && echo "alice"`}
+
+	req := httptest.NewRequest("GET", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer letmein")
+	id, err := a.Authenticate(req)
+	if err != nil {
+		t.Fatalf("expected allow, got %v", err)
+	}
+	if id.Subject != "alice" {
+		t.Errorf("subject = %q, want alice", id.Subject)
+	}
+
+	req.Header.Set("Authorization", "Bearer nope")
+	if _, err := a.Authenticate(req); err == nil {
+		t.Error("expected non-zero exit to reject")
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body webhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body.Headers["Authorization"]) == 0 || body.Headers["Authorization"][0] != "Bearer sso" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"subject": "bob"})
+	}))
+	defer hook.Close()
+
+	a := &Webhook{URL: hook.URL}
+	req := httptest.NewRequest("POST", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer sso")
+	id, err := a.Authenticate(req)
+	if err != nil {
+		t.Fatalf("expected allow, got %v", err)
+	}
+	if id.Subject != "bob" {
+		t.Errorf("subject = %q, want bob", id.Subject)
+	}
+
+	req.Header.Set("Authorization", "Bearer other")
+	if _, err := a.Authenticate(req); err == nil {
+		t.Error("expected non-2xx response to reject")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	a, err := FromConfig(config.AuthConfig{})
+	if err != nil || a != nil {
+		t.Errorf("empty config: got (%v, %v), want (nil, nil)", a, err)
+	}
+
+	t.Setenv("TEST_RUNBOOK_TOKEN", "")
+	if _, err := FromConfig(config.AuthConfig{Type: config.AuthTypeBearer, TokenEnv: "TEST_RUNBOOK_TOKEN"}); err == nil {
+		t.Error("expected error for bearer auth without a token")
+	}
+
+	t.Setenv("TEST_RUNBOOK_TOKEN", "abc")
+	a, err = FromConfig(config.AuthConfig{Type: config.AuthTypeBearer, TokenEnv: "TEST_RUNBOOK_TOKEN"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	if _, err := a.Authenticate(req); err != nil {
+		t.Errorf("expected token from env to be accepted: %v", err)
+	}
+}
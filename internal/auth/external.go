@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Command delegates the decision to a shell command. The command receives the
+// request in RUNBOOK_AUTH_TOKEN, RUNBOOK_AUTH_HEADER, RUNBOOK_AUTH_METHOD,
+// RUNBOOK_AUTH_PATH, and RUNBOOK_AUTH_REMOTE_ADDR. Exit status 0 allows the
+// request; the first line of stdout, if any, names the subject.
+type Command struct {
+	Command string
+	Timeout time.Duration
+}
+
+// Authenticate runs the command for r.
+func (c *Command) Authenticate(r *http.Request) (Identity, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeout())
+	defer cancel()
+
+	token, _ := bearerToken(r)
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+	cmd.Env = append(os.Environ(),
+		"RUNBOOK_AUTH_TOKEN="+token,
+		"RUNBOOK_AUTH_HEADER="+r.Header.Get("Authorization"),
+		"RUNBOOK_AUTH_METHOD="+r.Method,
+		"RUNBOOK_AUTH_PATH="+r.URL.Path,
+		"RUNBOOK_AUTH_REMOTE_ADDR="+r.RemoteAddr,
+	)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return Identity{}, fmt.Errorf("auth command timed out after %s", c.timeout())
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return Identity{}, ErrUnauthorized
+		}
+		return Identity{}, fmt.Errorf("auth command failed: %w", err)
+	}
+
+	line, _ := bufio.NewReader(&stdout).ReadString('\n')
+	return Identity{Subject: strings.TrimSpace(line)}, nil
+}
+
+func (c *Command) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// webhookRequest is the JSON body POSTed to an auth webhook.
+type webhookRequest struct {
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	RemoteAddr string              `json:"remote_addr"`
+	Headers    map[string][]string `json:"headers"`
+}
+
+// Webhook delegates the decision to an HTTP endpoint. The request's method,
+// path, remote address, and headers are POSTed as JSON; a 2xx response
+// allows the request and may name the subject with {"subject": "..."}.
+type Webhook struct {
+	URL     string
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Authenticate asks the webhook about r.
+func (wh *Webhook) Authenticate(r *http.Request) (Identity, error) {
+	body, err := json.Marshal(webhookRequest{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		Headers:    r.Header,
+	})
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to encode auth request: %w", err)
+	}
+
+	timeout := wh.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to create auth request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := wh.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("auth webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Identity{}, ErrUnauthorized
+	}
+
+	var id Identity
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if len(bytes.TrimSpace(data)) > 0 {
+		_ = json.Unmarshal(data, &id)
+	}
+	return id, nil
+}
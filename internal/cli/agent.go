@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/agent"
+	"runbookmcp.dev/internal/config"
+)
+
+func newAgentCmd() *cobra.Command {
+	var opts agent.Options
+	cmd := &cobra.Command{
+		Use:   "agent --server=ADDR --tag=TAG [--tag=TAG...]",
+		Short: "Run tasks for a runbook server on this machine",
+		Long: `Register this machine with a runbook serve instance and run the tasks whose
+runner names one of its tags. Output is streamed back to the server's
+session logs. Set RUNBOOK_TOKEN (or the env var named by the project's
+server.auth.token_env) when the server requires a bearer token, and
+RUNBOOK_AGENT_TOKEN to the server's agent token when it has one. For a
+server with TLS, RUNBOOK_CA_CERT names the CA that signed its certificate and
+RUNBOOK_CLIENT_CERT and RUNBOOK_CLIENT_KEY the certificate to present.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Server == "" {
+				return fmt.Errorf("--server is required")
+			}
+			if len(opts.Tags) == 0 {
+				return fmt.Errorf("at least one --tag is required")
+			}
+			for _, tag := range opts.Tags {
+				if !config.ValidAgentTag(tag) {
+					return fmt.Errorf("invalid tag '%s' (use lowercase letters, digits, '.', '_', and '-')", tag)
+				}
+			}
+			if opts.Name == "" {
+				opts.Name, _ = os.Hostname()
+			}
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			if opts.Dir == "" {
+				opts.Dir, _ = os.Getwd()
+			}
+			opts.Log = os.Stderr
+			opts.Client = clientConfig()
+
+			a, err := agent.New(opts)
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return a.Run(ctx)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Server, "server", "", "Address of the runbook server, e.g. http://build-host:8080")
+	cmd.Flags().StringSliceVar(&opts.Tags, "tag", nil, "Runner tag of the tasks to run here (repeatable)")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name the server shows for this agent (default: hostname)")
+	cmd.Flags().StringVar(&opts.Dir, "dir", "", "Directory task working directories resolve against (default: current directory)")
+	cmd.Flags().IntVar(&opts.Jobs, "jobs", 1, "Tasks to run at once")
+	return cmd
+}
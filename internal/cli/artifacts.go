@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/logs"
+)
+
+func newArtifactsCmd() *cobra.Command {
+	var out string
+	cmd := &cobra.Command{
+		Use:   "artifacts <session|task>",
+		Short: "List or copy out the artifacts of a session",
+		Long: `List the artifacts a task run kept, by session ID or by task name for its
+latest session. With --out, copy them into a directory.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTargetsFunc(completeAllTasks, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			// Artifacts are always read locally, like logs.
+			if code := cmdArtifacts(args[0], out); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "Copy the artifacts into this directory")
+	return cmd
+}
+
+// resolveArtifactSession returns the session ID for a session ID or the
+// latest session of a task.
+func resolveArtifactSession(target string) (string, error) {
+	if _, err := os.Stat(logs.GetSessionMetadataPath(target)); err == nil {
+		return target, nil
+	}
+	sessionID, err := logs.GetLatestSessionID(qualifiedName(target))
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a session ID or a task with sessions", target)
+	}
+	return sessionID, nil
+}
+
+func cmdArtifacts(target, out string) int {
+	sessionID, err := resolveArtifactSession(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	metadata, err := logs.ReadSessionMetadata(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s (%s)\n", color(colorDim, "Session:"), sessionID, metadata.TaskName)
+	if len(metadata.Artifacts) == 0 {
+		fmt.Fprintln(os.Stderr, "No artifacts recorded.")
+		return 0
+	}
+
+	dir := logs.GetSessionArtifactsDir(sessionID)
+	if out == "" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\t%s\n", colorOut(colorBold, "PATH"), colorOut(colorBold, "SIZE"))
+		for _, a := range metadata.Artifacts {
+			fmt.Fprintf(w, "%s\t%d\n", filepath.Join(dir, filepath.FromSlash(a.Path)), a.Size)
+		}
+		w.Flush()
+		return 0
+	}
+
+	for _, a := range metadata.Artifacts {
+		rel := filepath.FromSlash(a.Path)
+		data, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		dst := filepath.Join(out, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s %d file(s) to %s\n", color(colorGreen+colorBold, "[COPIED]"), len(metadata.Artifacts), out)
+	return 0
+}
@@ -4,18 +4,22 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/dirs"
 	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/mcputil"
 	"runbookmcp.dev/internal/process"
 	"runbookmcp.dev/internal/server"
 	"runbookmcp.dev/internal/task"
+	"runbookmcp.dev/runbook"
 )
 
 // Package-level vars are the standard way to bind Cobra persistent flags (same
@@ -34,31 +38,30 @@ type exitError struct{ code int }
 
 func (e *exitError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
 
-// newMCPServer performs the common server bootstrap: sets up logging, loads the
-// manifest, and creates the process manager, task manager, and MCP server.
-func newMCPServer(v string) (*server.Server, *process.Manager, error) {
-	if err := logs.Setup(); err != nil {
-		return nil, nil, fmt.Errorf("failed to setup logs: %w", err)
-	}
-
-	manifest, loaded, err := config.LoadManifest(globalConfig)
+// newMCPServer performs the common server bootstrap through the public
+// runbook package: loads the manifest and creates the MCP server.
+func newMCPServer(v string) (*runbook.Server, error) {
+	manifest, loaded, err := runbook.LoadManifest(globalConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load manifest: %w", err)
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
 	if !loaded {
 		fmt.Fprintln(os.Stderr, "Warning: No config file found. Server starting with empty configuration.")
 		fmt.Fprintf(os.Stderr, "Create %s/ directory with YAML files, or use --config flag\n", dirs.ConfigDir)
+		manifest = nil
+	} else {
+		for _, warning := range config.LintManifest(manifest) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
 	}
 
-	processManager := process.NewManager()
-	taskManager := task.NewManager(manifest, processManager)
-	mcpServer := server.NewServer(manifest, taskManager, processManager, loaded, v, globalConfig)
-	return mcpServer, processManager, nil
+	return runbook.NewServer(manifest, runbook.Options{Version: v, ConfigPath: globalConfig})
 }
 
 // newRootCmd builds and returns the full Cobra command tree.
 // It is separated from Execute so tests can construct a fresh command.
 func newRootCmd(v string) *cobra.Command {
+	var fallbackLocal bool
 	root := &cobra.Command{
 		Use:           "runbook",
 		Short:         "MCP server for shell tasks",
@@ -69,13 +72,24 @@ func newRootCmd(v string) *cobra.Command {
 			if !globalLocal {
 				serverData, err := process.ReadServerFile(globalWorkingDir)
 				if err == nil {
-					if !process.IsProcessAlive(serverData.PID) || !process.ProbeHTTP(serverData.Addr) {
-						fmt.Fprintf(os.Stderr, "error: server.json exists but the server is not running (PID %d dead).\n", serverData.PID)
-						fmt.Fprintf(os.Stderr, "Remove %s to continue in local mode.\n", process.ServerRegistryFile)
+					if !checkRegisteredServer(serverData) {
 						return &exitError{code: 1}
 					}
 					fmt.Fprintf(os.Stderr, "Proxying stdio to server at %s\n", serverData.Addr)
-					if err := server.ServeStdioProxy(serverData.Addr); err != nil {
+					opts := runbook.ProxyOptions{
+						Verify:      func() error { return reverifyServer(serverData.Addr) },
+						TokenEnv:    projectTokenEnv(),
+						ServerToken: registryToken,
+					}
+					if fallbackLocal {
+						opts.Fallback = func() (*runbook.Server, error) {
+							if err := applyWorkingDir(); err != nil {
+								return nil, err
+							}
+							return newMCPServer(v)
+						}
+					}
+					if err := runbook.ServeStdioProxyWithOptions(serverData.Addr, opts); err != nil {
 						return fmt.Errorf("proxy error: %w", err)
 					}
 					return nil
@@ -88,7 +102,7 @@ func newRootCmd(v string) *cobra.Command {
 
 			fmt.Fprintln(os.Stderr, "runbook: standalone mode")
 
-			mcpServer, processManager, err := newMCPServer(v)
+			mcpServer, err := newMCPServer(v)
 			if err != nil {
 				return err
 			}
@@ -98,13 +112,13 @@ func newRootCmd(v string) *cobra.Command {
 			go func() {
 				<-sigChan
 				fmt.Fprintln(os.Stderr, "\nShutting down...")
-				if err := processManager.StopAll(); err != nil {
+				if err := mcpServer.Close(); err != nil {
 					fmt.Fprintf(os.Stderr, "Error stopping daemons: %v\n", err)
 				}
 				os.Exit(0)
 			}()
 
-			return mcpServer.Serve()
+			return mcpServer.ServeStdio()
 		},
 	}
 
@@ -112,21 +126,41 @@ func newRootCmd(v string) *cobra.Command {
 	root.PersistentFlags().StringVar(&globalConfig, "config", "", "Path to task manifest file or directory")
 	root.PersistentFlags().StringVar(&globalWorkingDir, "working-dir", "", "Set project working directory")
 	root.PersistentFlags().BoolVar(&globalLocal, "local", false, "Run locally, bypassing any running server")
+	root.PersistentFlags().StringVar(&globalProject, "project", "", "Select a project hosted by a multi-project server")
+	root.PersistentFlags().BoolVarP(&globalYes, "yes", "y", false, "Run tasks that require confirmation without prompting")
+	root.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also set by NO_COLOR)")
 
-	root.AddCommand(newServeCmd(v), newInitCmd(), newListCmd(), newRunCmd(), newStartCmd(), newStopCmd(), newStatusCmd(), newLogsCmd())
+	root.Flags().BoolVar(&fallbackLocal, "fallback-local", false, "When proxying, serve locally if the server goes away and does not come back")
+
+	root.AddCommand(newServeCmd(v), newInitCmd(), newListCmd(), newRunCmd(), newWorkflowCmd(), newStartCmd(), newStopCmd(), newRestartCmd(), newReloadCmd(), newStatusCmd(), newPsCmd(), newLogsCmd(), newArtifactsCmd(), newSessionsCmd(), newWaitCmd(), newExecCmd(), newAgentCmd(), newExportCmd(v), newUpdateImportsCmd(), newValidateCmd(), newLintCmd(), newCompletionCmd())
 	return root
 }
 
 func newServeCmd(v string) *cobra.Command {
 	var serveAddr string
+	var replace bool
 	cmd := &cobra.Command{
 		Use:   "serve",
-		Short: "Run as standalone HTTP server",
+		Short: "Run as standalone HTTP server with a web dashboard at /ui",
+		Long: `Run as a standalone HTTP server with a web dashboard at /ui. Only one server
+runs per project: if one is already running, serve refuses to start unless
+--replace is given, which gracefully stops the old server and adopts its
+daemons.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := applyWorkingDir(); err != nil {
 				return err
 			}
-			mcpServer, _, err := newMCPServer(v)
+			// Before creating the server, whose process manager adopts the
+			// daemons of a replaced server
+			if err := checkRunningServer(replace); err != nil {
+				return err
+			}
+			release, err := acquireServerLock()
+			if err != nil {
+				return err
+			}
+			defer release()
+			mcpServer, err := newMCPServer(v)
 			if err != nil {
 				return err
 			}
@@ -134,9 +168,57 @@ func newServeCmd(v string) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Listen address for HTTP mode")
+	cmd.Flags().BoolVar(&replace, "replace", false, "Take over from a server already running for this project")
 	return cmd
 }
 
+// checkRunningServer guards against two servers for one project fighting
+// over PID files and logs. A live server is an error unless replace is set,
+// in which case it is stopped gracefully. A stale registry is removed.
+func checkRunningServer(replace bool) error {
+	data, err := process.ReadServerFile("")
+	if err != nil {
+		return nil
+	}
+	client, err := registryClient()
+	if err != nil {
+		return err
+	}
+	if !process.IsProcessAlive(data.PID) || !process.ProbeHTTP(data.Addr, client) {
+		process.DeleteServerFile("")
+		return nil
+	}
+	if err := process.VerifyServer(data, client); err != nil {
+		return fmt.Errorf("%s is registered as this project's server, but %v", process.ServerRegistryFile, err)
+	}
+	if !replace {
+		return fmt.Errorf("a server is already running for this project at %s (PID %d); use --replace to take over", data.Addr, data.PID)
+	}
+
+	// Give the old server its shutdown grace to drain, plus time to stop
+	grace := 0
+	if manifest, loaded, err := config.LoadManifest(globalConfig); err == nil && loaded {
+		grace = manifest.Server.ShutdownGrace
+	}
+	fmt.Fprintf(os.Stderr, "Replacing server at %s (PID %d)...\n", data.Addr, data.PID)
+	if err := process.ReplaceServer(data.PID, server.ResolveShutdownGrace(grace)+10*time.Second); err != nil {
+		return err
+	}
+	return nil
+}
+
+// acquireServerLock takes the project's server lock, which keeps a second
+// server from starting in the directory while the first is still coming up
+// and has not registered yet.
+func acquireServerLock() (func(), error) {
+	release, err := process.AcquireServerLock()
+	var locked *process.ServerLockedError
+	if errors.As(err, &locked) {
+		return nil, fmt.Errorf("%w; stop it, or use --replace once it is serving", err)
+	}
+	return release, err
+}
+
 func newInitCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "init",
@@ -150,12 +232,110 @@ func newInitCmd() *cobra.Command {
 	}
 }
 
+func newUpdateImportsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-imports",
+		Short: "Re-fetch remote imports and rewrite " + dirs.LockFile,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			if err := config.UpdateRemoteImports("."); err != nil {
+				return err
+			}
+			// Loading the config fetches every remote import and pins it again
+			if _, _, err := config.LoadManifest(globalConfig); err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Remote imports updated; pinned versions written to %s\n", dirs.LockFile)
+			return nil
+		},
+	}
+}
+
+func newValidateCmd() *cobra.Command {
+	var strict bool
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config and lint task commands for unsafe parameter interpolation",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			manifest, loaded, err := config.LoadManifest(globalConfig)
+			if err != nil {
+				return err
+			}
+			if !loaded {
+				return fmt.Errorf("no config found; create %s/ or use --config", dirs.ConfigDir)
+			}
+
+			warnings := config.LintManifest(manifest)
+			for _, warning := range warnings {
+				fmt.Fprintf(os.Stderr, "%s %s\n", color(colorYellow+colorBold, "[WARN]"), warning)
+			}
+			fmt.Fprintf(os.Stderr, "Config is valid: %d tasks, %d workflows, %d warnings\n",
+				len(manifest.Tasks), len(manifest.Workflows), len(warnings))
+			if strict && len(warnings) > 0 {
+				return &exitError{code: 1}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit with status 1 if there are warnings")
+	return cmd
+}
+
+func newLintCmd() *cobra.Command {
+	var strict bool
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Warn about config that is valid but likely to cause trouble",
+		Long: `Lint the config: everything validate warns about, plus descriptions that
+only repeat a name, oneshot tasks without a timeout, unused parameters,
+daemons whose command detaches, tasks that run the same command, and task
+groups that list disabled tasks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			manifest, loaded, err := config.LoadManifest(globalConfig)
+			if err != nil {
+				return err
+			}
+			if !loaded {
+				return fmt.Errorf("no config found; create %s/ or use --config", dirs.ConfigDir)
+			}
+
+			warnings := config.LintConfig(manifest)
+			for _, warning := range warnings {
+				fmt.Fprintf(os.Stderr, "%s %s\n", color(colorYellow+colorBold, "[WARN]"), warning)
+			}
+			fmt.Fprintf(os.Stderr, "%d warnings\n", len(warnings))
+			if strict && len(warnings) > 0 {
+				return &exitError{code: 1}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit with status 1 if there are warnings")
+	return cmd
+}
+
 // Execute sets up and runs the Cobra command tree.
 func Execute(v string) {
 	// Reset global state for each invocation.
 	globalConfig = ""
 	globalWorkingDir = ""
 	globalLocal = false
+	globalProject = ""
+	globalYes = false
+	runNoCache = false
+	statusShowEvents = false
+	statusLogLines = 5
+	noColor = false
 
 	cmd := newRootCmd(v)
 	if err := cmd.Execute(); err != nil {
@@ -210,6 +390,7 @@ func parseTaskParams(taskDef config.Task, args []string) (map[string]interface{}
 		}
 		flagPtrs[name] = fs.String(name, defaultVal, param.Description)
 	}
+	aliasPtrs := aliasFlags(fs, taskDef.Parameters)
 
 	if err := fs.Parse(args); err != nil {
 		return nil, err
@@ -224,6 +405,12 @@ func parseTaskParams(taskDef config.Task, args []string) (map[string]interface{}
 			params[name] = *ptr
 		}
 	}
+	if err := applyAliasFlags(fs, taskDef.Parameters, aliasPtrs, params); err != nil {
+		return nil, err
+	}
+	if err := readParamSources(taskDef, params); err != nil {
+		return nil, err
+	}
 
 	for name, param := range taskDef.Parameters {
 		if param.Required {
@@ -236,9 +423,57 @@ func parseTaskParams(taskDef config.Task, args []string) (map[string]interface{}
 	return params, nil
 }
 
+// aliasFlags registers a flag for each parameter alias and deprecated name.
+func aliasFlags(fs *flag.FlagSet, defs map[string]config.Param) map[string]*string {
+	ptrs := make(map[string]*string)
+	for name, param := range defs {
+		for _, alias := range param.Aliases {
+			ptrs[alias] = fs.String(alias, "", fmt.Sprintf("Alias for --%s", name))
+		}
+		for _, old := range param.DeprecatedNames {
+			ptrs[old] = fs.String(old, "", fmt.Sprintf("Deprecated, use --%s", name))
+		}
+	}
+	return ptrs
+}
+
+// applyAliasFlags moves alias and deprecated flags that were set onto the
+// parameters they stand for, replacing defaults, and prints deprecation
+// warnings.
+func applyAliasFlags(fs *flag.FlagSet, defs map[string]config.Param, aliasPtrs map[string]*string, params map[string]interface{}) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	raw := make(map[string]interface{})
+	for alias, ptr := range aliasPtrs {
+		if set[alias] {
+			raw[alias] = *ptr
+		}
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	for name := range defs {
+		if set[name] {
+			raw[name] = params[name]
+		}
+	}
+
+	warnings, err := config.ResolveParamNames(defs, raw)
+	if err != nil {
+		return err
+	}
+	for name, value := range raw {
+		params[name] = value
+	}
+	printParamWarnings(warnings)
+	return nil
+}
+
 // isMCPEnabled returns false when the first arg names a task that has
-// disable_mcp: true, indicating the task should bypass any running server and
-// execute locally. Returns true on any error or when no task matches.
+// disable_mcp: true, or an interactive oneshot task that needs this terminal,
+// indicating the task should bypass any running server and execute locally.
+// Returns true on any error or when no task matches.
 //
 // This must use config.LoadManifest directly rather than bootstrap to avoid
 // creating a process.Manager, which calls restoreFromPIDFiles() and would kill
@@ -252,7 +487,7 @@ func isMCPEnabled(args []string) bool {
 	if err != nil || !loaded {
 		return true // no config available; let remote handle it
 	}
-	if t, exists := manifest.Tasks[taskName]; exists && t.DisableMCP {
+	if t, exists := manifest.Tasks[taskName]; exists && (t.DisableMCP || t.Interactive && t.Type == config.TaskTypeOneShot) {
 		return false
 	}
 	return true
@@ -275,13 +510,67 @@ func tryRemoteExecute(subcmd string, args []string) (int, bool) {
 	if err != nil {
 		return 0, false
 	}
-	if !process.IsProcessAlive(serverData.PID) || !process.ProbeHTTP(serverData.Addr) {
-		fmt.Fprintf(os.Stderr, "error: server.json exists but the server is not running (PID %d dead).\n", serverData.PID)
-		fmt.Fprintf(os.Stderr, "Remove %s to continue in local mode.\n", process.ServerRegistryFile)
+	if !checkRegisteredServer(serverData) {
 		return 1, true
 	}
 	fmt.Fprintf(os.Stderr, "runbook: proxying to server at %s\n", serverData.Addr)
-	return remoteExecute(serverData.Addr, subcmd, args), true
+	return remoteExecute(serverData, subcmd, args), true
+}
+
+// checkRegisteredServer reports whether the server in the registry is running
+// and proves, by the handshake, that it wrote the registry. Otherwise it
+// prints why the CLI won't connect to it.
+func checkRegisteredServer(data *process.ServerFileData) bool {
+	client, err := registryClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return false
+	}
+	if !process.IsProcessAlive(data.PID) || !process.ProbeHTTP(data.Addr, client) {
+		fmt.Fprintf(os.Stderr, "error: server.json exists but the server is not running (PID %d dead).\n", data.PID)
+		fmt.Fprintf(os.Stderr, "Remove %s to continue in local mode.\n", process.ServerRegistryFile)
+		return false
+	}
+	if err := process.VerifyServer(data, client); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v.\n", err)
+		fmt.Fprintf(os.Stderr, "Refusing to connect; stop that process, or remove %s to continue in local mode.\n", process.ServerRegistryFile)
+		return false
+	}
+	return true
+}
+
+// reverifyServer re-reads the registry before the stdio proxy reconnects to
+// addr, and checks that the server there still holds its token. A server
+// that was replaced writes a new registry, which is verified instead.
+func reverifyServer(addr string) error {
+	data, err := process.ReadServerFile(globalWorkingDir)
+	if err != nil {
+		return fmt.Errorf("no server registered: %w", err)
+	}
+	if data.Addr != addr {
+		return fmt.Errorf("the server moved to %s", data.Addr)
+	}
+	client, err := registryClient()
+	if err != nil {
+		return err
+	}
+	return process.VerifyServer(data, client)
+}
+
+// registryToken returns the token in the current server registry, which
+// the stdio proxy sends on every connect: a replaced server has a new one.
+func registryToken() string {
+	data, err := process.ReadServerFile(globalWorkingDir)
+	if err != nil {
+		return ""
+	}
+	return data.Token
+}
+
+// registryClient returns the HTTP client that probes and verifies the
+// registered server, with the CLI's client TLS settings.
+func registryClient() (*http.Client, error) {
+	return mcputil.NewClientConfig("").HTTPClient(0)
 }
 
 // runWithRemoteFallback handles the common pattern used by most subcommand RunE
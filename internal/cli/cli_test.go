@@ -2,6 +2,8 @@ package cli
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +12,7 @@ import (
 
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/process"
 )
 
 // resetGlobals resets package-level state between tests to avoid cross-test contamination.
@@ -18,14 +21,17 @@ func resetGlobals(t *testing.T) {
 	oldConfig := globalConfig
 	oldWorkingDir := globalWorkingDir
 	oldLocal := globalLocal
+	oldOutput := runOutput
 	t.Cleanup(func() {
 		globalConfig = oldConfig
 		globalWorkingDir = oldWorkingDir
 		globalLocal = oldLocal
+		runOutput = oldOutput
 	})
 	globalConfig = ""
 	globalWorkingDir = ""
 	globalLocal = false
+	runOutput = outputText
 }
 
 // ---------------------------------------------------------------------------
@@ -113,6 +119,76 @@ func TestRunSubcommand(t *testing.T) {
 	}
 }
 
+func TestRunTaskHelp(t *testing.T) {
+	resetGlobals(t)
+	t.Chdir(t.TempDir())
+	manifest := `version: "1.0"
+tasks:
+  deploy:
+    description: "Deploy the service"
+    command: "echo {{.env}}"
+    type: oneshot
+    parameters:
+      env:
+        type: string
+        required: true
+        description: "Target environment"
+      replicas:
+        type: number
+        default: "2"
+        description: "Instances to run"
+    parameter_presets:
+      prod: {env: prod}
+`
+	if err := os.MkdirAll(dirs.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newRootCmd("test-version")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{"run", "deploy", "--help"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run deploy --help error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"runbook run deploy [--preset=name] [--param=value...]",
+		"Deploy the service",
+		"--env",
+		"(required)",
+		"--replicas",
+		"number",
+		"(default: 2)",
+		"Presets: prod",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("help output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "--env") > strings.Index(out, "--replicas") {
+		t.Error("required parameters should be listed first")
+	}
+
+	// An unknown task falls back to the command's help
+	buf.Reset()
+	cmd = newRootCmd("test-version")
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{"run", "missing", "--help"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run missing --help error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Run a oneshot task or workflow") {
+		t.Errorf("expected the run command's help, got:\n%s", buf.String())
+	}
+}
+
 func TestRunConfigFlag(t *testing.T) {
 	// Verify that "run --config=foo.yaml mytask" routes to the run subcommand.
 	// We use Find instead of Execute because run has DisableFlagParsing and
@@ -661,12 +737,14 @@ func TestParseTaskParams(t *testing.T) {
 				Type:        "string",
 				Required:    true,
 				Description: "The name",
+				Aliases:     []string{"n"},
 			},
 			"count": {
-				Type:        "string",
-				Required:    false,
-				Description: "The count",
-				Default:     &defaultVal,
+				Type:            "string",
+				Required:        false,
+				Description:     "The count",
+				Default:         &defaultVal,
+				DeprecatedNames: []string{"num"},
 			},
 		},
 	}
@@ -701,6 +779,26 @@ func TestParseTaskParams(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "alias and deprecated name",
+			args: []string{"--n=hello", "--num=7"},
+			check: func(t *testing.T, params map[string]interface{}) {
+				if params["name"] != "hello" {
+					t.Errorf("name = %v, want hello", params["name"])
+				}
+				if params["count"] != "7" {
+					t.Errorf("count = %v, want 7", params["count"])
+				}
+				if _, ok := params["num"]; ok {
+					t.Error("deprecated name should be replaced by count")
+				}
+			},
+		},
+		{
+			name:    "name and alias both given",
+			args:    []string{"--name=hello", "--n=other"},
+			wantErr: true,
+		},
 		{
 			name:    "missing required param",
 			args:    []string{},
@@ -748,6 +846,63 @@ func TestParseTaskParamsNoParams(t *testing.T) {
 	}
 }
 
+func TestPresetFlags(t *testing.T) {
+	taskDef := config.Task{
+		Parameters: map[string]config.Param{
+			"flags": {Type: "string", Description: "Flags"},
+			"path":  {Type: "string", Description: "Packages"},
+		},
+		ParameterPresets: map[string]map[string]string{
+			"quick": {"flags": "-short", "path": "./internal/..."},
+		},
+	}
+
+	for _, args := range [][]string{{"--preset=quick", "--path=./cmd/..."}, {"--path=./cmd/...", "--preset", "quick"}} {
+		preset, rest := extractPresetFlag(args)
+		if preset != "quick" || strings.Join(rest, " ") != "--path=./cmd/..." {
+			t.Errorf("extractPresetFlag(%v) = (%q, %v)", args, preset, rest)
+		}
+	}
+
+	presetArgs, err := presetFlags(taskDef, "quick")
+	if err != nil {
+		t.Fatalf("presetFlags failed: %v", err)
+	}
+	params, err := parseTaskParams(taskDef, append(presetArgs, "--path=./cmd/..."))
+	if err != nil {
+		t.Fatalf("parseTaskParams failed: %v", err)
+	}
+	if params["flags"] != "-short" || params["path"] != "./cmd/..." {
+		t.Errorf("expected preset flags with the explicit path, got %v", params)
+	}
+
+	if _, err := presetFlags(taskDef, "slow"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
+
+func TestOutputFlag(t *testing.T) {
+	resetGlobals(t)
+
+	format, rest := extractOutputFlag(nil, []string{"--output", "json", "--path=./..."})
+	if format != "json" || strings.Join(rest, " ") != "--path=./..." {
+		t.Errorf("extractOutputFlag = (%q, %v)", format, rest)
+	}
+	if err := setRunOutput(format); err != nil || runOutput != outputJSON {
+		t.Errorf("expected json output, got %q (err %v)", runOutput, err)
+	}
+	if err := setRunOutput("yaml"); err == nil {
+		t.Error("expected error for unknown output format")
+	}
+
+	// A task's own output parameter keeps the flag
+	params := map[string]config.Param{"output": {Type: "string", Description: "Output file"}}
+	format, rest = extractOutputFlag(params, []string{"--output=out.txt"})
+	if format != "" || strings.Join(rest, " ") != "--output=out.txt" {
+		t.Errorf("expected the task's output parameter to be kept, got (%q, %v)", format, rest)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // isMCPEnabled tests
 // ---------------------------------------------------------------------------
@@ -768,6 +923,11 @@ tasks:
     command: "./scripts/setup-secrets.sh"
     type: oneshot
     disable_mcp: true
+  psql:
+    description: "Database shell"
+    command: "psql"
+    type: oneshot
+    interactive: true
   build:
     description: "Build the project"
     command: "go build ./..."
@@ -789,6 +949,7 @@ tasks:
 		{[]string{}, true},                        // no task name -> enabled
 		{[]string{"build"}, true},                 // normal task -> enabled
 		{[]string{"setup-secrets"}, false},        // disable_mcp task -> not enabled
+		{[]string{"psql"}, false},                 // interactive oneshot -> runs on this terminal
 		{[]string{"nonexistent"}, true},           // unknown task -> enabled (pass through)
 		{[]string{"setup-secrets", "--foo"}, false}, // extra args don't matter; first arg checked
 	}
@@ -826,3 +987,159 @@ func TestFormatDuration(t *testing.T) {
 func toDuration(ms int) time.Duration {
 	return time.Duration(ms) * time.Millisecond
 }
+
+func TestDaemonCommandsRequireTaskOrAll(t *testing.T) {
+	for _, args := range [][]string{
+		{"stop"},
+		{"status"},
+		{"restart"},
+		{"stop", "dev", "--all"},
+		{"restart", "dev", "--all"},
+	} {
+		resetGlobals(t)
+		cmd := newRootCmd("test-version")
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(buf)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestSessionsDiffSubcommand(t *testing.T) {
+	cmd := newRootCmd("test-version")
+	found, _, err := cmd.Find([]string{"sessions", "diff"})
+	if err != nil || found.Name() != "diff" {
+		t.Fatalf("expected 'sessions diff' subcommand, got %v (%v)", found, err)
+	}
+}
+
+func TestExportToolsRejectsUnknownFormat(t *testing.T) {
+	resetGlobals(t)
+	cmd := newRootCmd("test-version")
+	cmd.SetArgs([]string{"export", "tools", "--format", "yaml"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid --format") {
+		t.Errorf("expected invalid --format error, got %v", err)
+	}
+}
+
+func TestCheckRunningServer(t *testing.T) {
+	resetGlobals(t)
+	t.Chdir(t.TempDir())
+
+	// A registry for a dead server is stale and removed
+	if err := process.WriteServerFile(process.ServerFileData{Addr: "http://127.0.0.1:1", PID: 999999}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkRunningServer(false); err != nil {
+		t.Fatalf("stale registry: %v", err)
+	}
+	if _, err := process.ReadServerFile(""); err == nil {
+		t.Error("stale server.json should be removed")
+	}
+
+	// A live server is refused without --replace
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	if err := process.WriteServerFile(process.ServerFileData{Addr: ts.URL, PID: os.Getpid()}); err != nil {
+		t.Fatal(err)
+	}
+	err := checkRunningServer(false)
+	if err == nil || !strings.Contains(err.Error(), "--replace") {
+		t.Errorf("expected a refusal mentioning --replace, got %v", err)
+	}
+
+	// A live process that fails the handshake is not treated as the server
+	if err := process.WriteServerFile(process.ServerFileData{Addr: ts.URL, PID: os.Getpid(), Token: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	err = checkRunningServer(true)
+	if err == nil || !strings.Contains(err.Error(), "handshake") {
+		t.Errorf("expected a handshake failure, got %v", err)
+	}
+}
+
+func TestLogsSearchSubcommand(t *testing.T) {
+	cmd := newRootCmd("test-version")
+	found, _, err := cmd.Find([]string{"logs", "search"})
+	if err != nil || found.Name() != "search" {
+		t.Fatalf("expected 'logs search' subcommand, got %v (%v)", found, err)
+	}
+}
+
+func TestParseTaskParamsSources(t *testing.T) {
+	old := paramInput
+	defer func() { paramInput = old }()
+
+	taskDef := config.Task{
+		Parameters: map[string]config.Param{
+			"patch": {Type: "string", Description: "Patch", Source: config.ParamSourceFile},
+			"sql":   {Type: "string", Description: "SQL", Required: true, Source: config.ParamSourceStdin},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "fix.patch")
+	if err := os.WriteFile(path, []byte("--- a\n+++ b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paramInput = strings.NewReader("select 1;\n")
+	params, err := parseTaskParams(taskDef, []string{"--patch=@" + path})
+	if err != nil {
+		t.Fatalf("parseTaskParams failed: %v", err)
+	}
+	if params["patch"] != "--- a\n+++ b\n" || params["sql"] != "select 1;\n" {
+		t.Errorf("expected file and piped contents, got %v", params)
+	}
+
+	paramInput = strings.NewReader("")
+	if _, err := parseTaskParams(taskDef, nil); err == nil || !strings.Contains(err.Error(), "--sql is missing") {
+		t.Errorf("expected missing stdin parameter error, got %v", err)
+	}
+
+	paramInput = strings.NewReader("diff")
+	params, err = parseTaskParams(taskDef, []string{"--patch=-", "--sql=select 2;"})
+	if err != nil {
+		t.Fatalf("parseTaskParams failed: %v", err)
+	}
+	if params["patch"] != "diff" || params["sql"] != "select 2;" {
+		t.Errorf("expected stdin patch and literal sql, got %v", params)
+	}
+}
+
+func TestValidateSubcommand(t *testing.T) {
+	resetGlobals(t)
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(dirs.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `version: "1.0"
+tasks:
+  checkout:
+    description: "Checkout a branch"
+    command: "git checkout {{.branch}}"
+    type: oneshot
+    parameters:
+      branch:
+        type: string
+        required: true
+        description: "Branch"
+`
+	if err := os.WriteFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newRootCmd("test-version")
+	cmd.SetArgs([]string{"validate"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("validate should pass with warnings, got %v", err)
+	}
+
+	cmd = newRootCmd("test-version")
+	cmd.SetArgs([]string{"validate", "--strict"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("validate --strict should fail on warnings")
+	}
+}
@@ -10,15 +10,28 @@ import (
 	"text/tabwriter"
 
 	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
 	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/server"
 	"runbookmcp.dev/internal/task"
 )
 
-// newMCPClient creates, starts, and initializes an MCP HTTP client against addr.
-// The returned cleanup function should be deferred by the caller.
-func newMCPClient(addr string) (*mcpclient.Client, func(), error) {
-	c, err := mcpclient.NewStreamableHttpClient(mcputil.Endpoint(addr))
+// newMCPClient creates, starts, and initializes an MCP HTTP client against
+// the server in data, sending its registry token. The returned cleanup
+// function should be deferred by the caller.
+func newMCPClient(data *process.ServerFileData) (*mcpclient.Client, func(), error) {
+	cfg := clientConfig()
+	cfg.ServerToken = data.Token
+	httpClient, err := cfg.HTTPClient(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := mcpclient.NewStreamableHttpClient(mcputil.Endpoint(data.Addr),
+		transport.WithHTTPHeaders(cfg.Headers()), transport.WithHTTPBasicClient(httpClient))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create MCP client: %w", err)
 	}
@@ -32,7 +45,7 @@ func newMCPClient(addr string) (*mcpclient.Client, func(), error) {
 		Params: mcp.InitializeParams{
 			ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
 			ClientInfo: mcp.Implementation{
-				Name:    "runbook-cli",
+				Name:    mcputil.CLIClientName,
 				Version: "0.0.1",
 			},
 		},
@@ -45,27 +58,44 @@ func newMCPClient(addr string) (*mcpclient.Client, func(), error) {
 }
 
 // remoteExecute routes a CLI command through the running HTTP server.
-func remoteExecute(addr, subcmd string, args []string) int {
-	c, cleanup, err := newMCPClient(addr)
+func remoteExecute(serverData *process.ServerFileData, subcmd string, args []string) int {
+	c, cleanup, err := newMCPClient(serverData)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error connecting to server at %s: %v\n", addr, err)
+		fmt.Fprintf(os.Stderr, "Error connecting to server at %s: %v\n", serverData.Addr, err)
 		return 1
 	}
 	defer cleanup()
 
 	ctx := context.Background()
+	manifest := remoteManifest()
+	toolPrefix = manifest.Defaults.ToolPrefix
 	switch subcmd {
 	case "list":
-		return remoteList(ctx, c)
+		return remoteList(ctx, c, manifest)
 	case "run":
 		// Try as oneshot first; if not found, try as workflow group.
-		return remoteRun(ctx, c, args)
+		return remoteRun(ctx, c, manifest, args)
 	case "start":
-		return remoteToolCall(ctx, c, "start_", args)
+		return remoteToolCall(ctx, c, manifest, config.ToolStart, args)
 	case "stop":
-		return remoteToolCall(ctx, c, "stop_", args)
+		return remoteToolCall(ctx, c, manifest, config.ToolStop, args)
+	case "reload":
+		return remoteToolCall(ctx, c, manifest, config.ToolReload, args)
 	case "status":
-		return remoteToolCall(ctx, c, "status_", args)
+		return remoteToolCall(ctx, c, manifest, config.ToolStatus, args)
+	case "stop-all":
+		code, _ := callTool(ctx, c, "stop_all_daemons", map[string]any{})
+		return code
+	case "restart-all":
+		params := map[string]any{}
+		if len(args) > 0 {
+			params["tasks"] = args
+		}
+		code, _ := callTool(ctx, c, "restart_all_daemons", params)
+		return code
+	case "status-all":
+		code, _ := callTool(ctx, c, "status_all", map[string]any{})
+		return code
 	case "logs":
 		// Logs are stored as files on disk regardless of whether the server is running.
 		// Read them locally rather than routing through the server.
@@ -76,8 +106,41 @@ func remoteExecute(addr, subcmd string, args []string) int {
 	}
 }
 
+// toolPrefix is the project's defaults.tool_prefix, which the server puts
+// in front of the names of task and workflow tools.
+var toolPrefix string
+
+// remoteManifest returns the project's manifest, which names the server's
+// task tools, or an empty one when it cannot be loaded.
+func remoteManifest() *config.Manifest {
+	manifest, loaded, err := config.LoadManifest(globalConfig)
+	if err != nil || !loaded {
+		return &config.Manifest{}
+	}
+	return manifest
+}
+
+// clientConfig returns the credentials CLI commands present to a running
+// server: the token named by the project's server.auth.token_env, and the
+// client TLS files from the environment.
+func clientConfig() mcputil.ClientConfig {
+	return mcputil.NewClientConfig(remoteManifest().Server.Auth.TokenEnv)
+}
+
+// projectTokenEnv returns the server.auth.token_env of the project in
+// --working-dir without staying there, for the stdio proxy, which runs from
+// the directory it was started in.
+func projectTokenEnv() string {
+	wd, err := os.Getwd()
+	if err != nil || applyWorkingDir() != nil {
+		return clientConfig().TokenEnv
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	return clientConfig().TokenEnv
+}
+
 // remoteList fetches and displays tools from the remote server.
-func remoteList(ctx context.Context, c *mcpclient.Client) int {
+func remoteList(ctx context.Context, c *mcpclient.Client, manifest *config.Manifest) int {
 	result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error listing tools: %v\n", err)
@@ -92,15 +155,44 @@ func remoteList(ctx context.Context, c *mcpclient.Client) int {
 	var tasks, daemons []entry
 	var workflows []entry
 
+	// Tasks with a tool_name are listed under their own names
+	renamed := make(map[string]string)
+	for name, def := range manifest.Tasks {
+		if def.ToolName != "" {
+			for _, kind := range []string{config.ToolRun, config.ToolStart} {
+				renamed[manifest.ToolName(kind, name)] = name
+			}
+		}
+	}
+
 	for _, t := range result.Tools {
+		var e entry
+		toolName := strings.TrimPrefix(t.Name, toolPrefix)
 		switch {
-		case strings.HasPrefix(t.Name, "run_workflow_"):
-			workflows = append(workflows, entry{t.Name[13:], "workflow", t.Description})
-		case strings.HasPrefix(t.Name, "run_"):
-			tasks = append(tasks, entry{t.Name[4:], "oneshot", t.Description})
-		case strings.HasPrefix(t.Name, "start_"):
-			desc := strings.TrimPrefix(t.Description, "Start daemon: ")
-			daemons = append(daemons, entry{t.Name[6:], "daemon", desc})
+		case strings.HasPrefix(toolName, "run_workflow_"):
+			e = entry{toolName[13:], "workflow", t.Description}
+		case strings.HasPrefix(toolName, "run_"):
+			e = entry{toolName[4:], "oneshot", t.Description}
+		case strings.HasPrefix(toolName, "start_"):
+			e = entry{toolName[6:], "daemon", strings.TrimPrefix(t.Description, "Start daemon: ")}
+		default:
+			continue
+		}
+		if name, ok := renamed[t.Name]; ok {
+			e.name = name
+		}
+		name, ok := projectLocalName(e.name)
+		if !ok {
+			continue
+		}
+		e.name = name
+		switch e.kind {
+		case "workflow":
+			workflows = append(workflows, e)
+		case "oneshot":
+			tasks = append(tasks, e)
+		default:
+			daemons = append(daemons, e)
 		}
 	}
 
@@ -113,9 +205,9 @@ func remoteList(ctx context.Context, c *mcpclient.Client) int {
 
 	if len(all) > 0 {
 		fmt.Fprintf(w, "%s\t%s\t%s\n",
-			color(colorBold, "TASK"),
-			color(colorBold, "TYPE"),
-			color(colorBold, "DESCRIPTION"))
+			colorOut(colorBold, "TASK"),
+			colorOut(colorBold, "TYPE"),
+			colorOut(colorBold, "DESCRIPTION"))
 		for _, e := range all {
 			fmt.Fprintf(w, "%s\t%s\t%s\n", e.name, e.kind, e.desc)
 		}
@@ -126,8 +218,8 @@ func remoteList(ctx context.Context, c *mcpclient.Client) int {
 			fmt.Fprintln(w)
 		}
 		fmt.Fprintf(w, "%s\t%s\n",
-			color(colorBold, "WORKFLOW"),
-			color(colorBold, "DESCRIPTION"))
+			colorOut(colorBold, "WORKFLOW"),
+			colorOut(colorBold, "DESCRIPTION"))
 		for _, wf := range workflows {
 			fmt.Fprintf(w, "%s\t%s\n", wf.name, wf.desc)
 		}
@@ -138,22 +230,50 @@ func remoteList(ctx context.Context, c *mcpclient.Client) int {
 }
 
 // remoteRun handles "runbook run <task>" by trying oneshot first, then workflow.
-func remoteRun(ctx context.Context, c *mcpclient.Client, args []string) int {
+func remoteRun(ctx context.Context, c *mcpclient.Client, manifest *config.Manifest, args []string) int {
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: runbook run <task> [--param=value...]")
 		return 1
 	}
 	taskName := args[0]
-	params := parseRawParams(args[1:])
+	rest := args[1:]
+
+	// Send the content of file and stdin parameters, not their local paths
+	var outputParams map[string]config.Param
+	if taskDef, ok := manifest.Tasks[taskName]; ok {
+		outputParams = taskDef.Parameters
+	} else if wfDef, ok := manifest.Workflows[taskName]; ok {
+		outputParams = wfDef.Parameters
+	}
+	format, rest := extractOutputFlag(outputParams, rest)
+	if err := setRunOutput(format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	params := parseRawParams(rest)
 	params["max_output_lines"] = float64(0) // request unlimited output for CLI
+	if taskDef, ok := manifest.Tasks[taskName]; ok {
+		// The CLI waits for async tasks unless asked not to
+		if _, given := params[server.RunAsyncParam]; !given && taskDef.Async {
+			params[server.RunAsyncParam] = false
+		}
+		if runNoCache && taskDef.Cache != nil {
+			params[task.NoCacheParam] = true
+		}
+		if err := readParamSources(taskDef, params); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
 
 	// Try oneshot tool first.
-	code, found := callTool(ctx, c, "run_"+taskName, params)
+	code, found := callTool(ctx, c, manifest.ToolName(config.ToolRun, taskName), params)
 	if found {
 		return code
 	}
 	// Fall back to workflow group.
-	code, found = callTool(ctx, c, "run_workflow_"+taskName, params)
+	code, found = callTool(ctx, c, manifest.WorkflowToolName(taskName), params)
 	if found {
 		return code
 	}
@@ -161,17 +281,16 @@ func remoteRun(ctx context.Context, c *mcpclient.Client, args []string) int {
 	return 1
 }
 
-// remoteToolCall invokes a named tool on the remote server and prints the result.
-// prefix is "start_", "stop_", "status_", or "logs_".
+// remoteToolCall invokes a task's tool on the remote server and prints the
+// result. kind is config.ToolStart, ToolStop, ToolReload, or ToolStatus.
 // args should be [taskName, --param=value, ...]
-func remoteToolCall(ctx context.Context, c *mcpclient.Client, prefix string, args []string) int {
+func remoteToolCall(ctx context.Context, c *mcpclient.Client, manifest *config.Manifest, kind string, args []string) int {
 	if len(args) == 0 {
-		cmd := strings.TrimSuffix(prefix, "_")
-		fmt.Fprintf(os.Stderr, "Usage: runbook %s <task> [--param=value...]\n", cmd)
+		fmt.Fprintf(os.Stderr, "Usage: runbook %s <task> [--param=value...]\n", kind)
 		return 1
 	}
 	taskName := args[0]
-	toolName := prefix + taskName
+	toolName := manifest.ToolName(kind, taskName)
 	params := parseRawParams(args[1:])
 
 	code, found := callTool(ctx, c, toolName, params)
@@ -203,6 +322,18 @@ func callTool(ctx context.Context, c *mcpclient.Client, toolName string, params
 		return 1, true
 	}
 
+	if !result.IsError && len(result.Content) == 1 {
+		if tc, ok := mcp.AsTextContent(result.Content[0]); ok {
+			if pending, ok := parseRemoteConfirmation(tc.Text); ok {
+				if !confirm(toolName, pending.Preview) {
+					return 1, true
+				}
+				params[server.ConfirmationTokenParam] = pending.ConfirmationToken
+				return callTool(ctx, c, toolName, params)
+			}
+		}
+	}
+
 	for _, content := range result.Content {
 		if tc, ok := mcp.AsTextContent(content); ok {
 			if result.IsError {
@@ -220,21 +351,38 @@ func callTool(ctx context.Context, c *mcpclient.Client, toolName string, params
 
 // remoteOneShotResponse mirrors the server's private oneShotResponse for JSON decoding.
 type remoteOneShotResponse struct {
-	TaskName        string `json:"task_name"`
-	SessionID       string `json:"session_id"`
-	Success         bool   `json:"success"`
-	ExitCode        int    `json:"exit_code"`
-	Duration        string `json:"duration"`
-	Error           string `json:"error"`
-	TimedOut        bool   `json:"timed_out"`
-	Stdout          string `json:"stdout"`
-	StdoutTruncated bool   `json:"stdout_truncated"`
-	Stderr          string `json:"stderr"`
-	StderrTruncated bool   `json:"stderr_truncated"`
+	TaskName        string              `json:"task_name"`
+	SessionID       string              `json:"session_id"`
+	Success         bool                `json:"success"`
+	Status          string              `json:"status"`
+	StatusReason    string              `json:"status_reason"`
+	ExitCode        int                 `json:"exit_code"`
+	Duration        string              `json:"duration"`
+	Error           string              `json:"error"`
+	TimedOut        bool                `json:"timed_out"`
+	Stdout          string              `json:"stdout"`
+	StdoutTruncated bool                `json:"stdout_truncated"`
+	Output          json.RawMessage     `json:"output"`
+	OutputError     string              `json:"output_error"`
+	Stderr          string              `json:"stderr"`
+	StderrTruncated bool                `json:"stderr_truncated"`
+	Resources       *logs.ResourceUsage `json:"resources"`
+	Artifacts       []logs.Artifact     `json:"artifacts"`
+	ArtifactsDir    string              `json:"artifacts_dir"`
+	Async           bool                `json:"async"`
+	PreconditionFailed *task.PreconditionFailure `json:"precondition_failed"`
 }
 
 // printRemoteOneShotResponse formats a remote oneshot result like printExecutionResult.
 func printRemoteOneShotResponse(r *remoteOneShotResponse) {
+	if r.Async {
+		fmt.Fprintf(os.Stderr, "Started %s in the background (session %s)\n", r.TaskName, r.SessionID)
+		fmt.Fprintf(os.Stderr, "Wait for it with: runbook wait %s\n", r.SessionID)
+		return
+	}
+	if len(r.Output) > 0 && r.Stdout == "" {
+		fmt.Println(string(r.Output))
+	}
 	if r.Stdout != "" {
 		fmt.Print(r.Stdout)
 		if !strings.HasSuffix(r.Stdout, "\n") {
@@ -249,6 +397,8 @@ func printRemoteOneShotResponse(r *remoteOneShotResponse) {
 	}
 	fmt.Fprintln(os.Stderr)
 	switch {
+	case r.Status == task.StatusWarning:
+		fmt.Fprintf(os.Stderr, "%s  exit code %d  %s\n", color(colorYellow+colorBold, "[WARN]"), r.ExitCode, color(colorDim, r.Duration))
 	case r.Success:
 		fmt.Fprintf(os.Stderr, "%s  %s\n", color(colorGreen+colorBold, "[OK]"), color(colorDim, r.Duration))
 	case r.TimedOut:
@@ -256,12 +406,23 @@ func printRemoteOneShotResponse(r *remoteOneShotResponse) {
 	default:
 		fmt.Fprintf(os.Stderr, "%s  exit code %d  %s\n", color(colorRed+colorBold, "[FAIL]"), r.ExitCode, color(colorDim, r.Duration))
 	}
+	if r.StatusReason != "" {
+		fmt.Fprintf(os.Stderr, "%s %s (exit code %d)\n", color(colorDim, "Status:"), r.StatusReason, r.ExitCode)
+	}
 	if r.Error != "" {
 		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorRed, "Error:"), r.Error)
 	}
+	if r.OutputError != "" {
+		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorYellow, "Output:"), r.OutputError)
+	}
+	printPreconditionFailure(r.PreconditionFailed)
 	if r.SessionID != "" {
 		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Session:"), r.SessionID)
 	}
+	printResources(r.Resources)
+	if len(r.Artifacts) > 0 {
+		fmt.Fprintf(os.Stderr, "%s %d file(s) in %s\n", color(colorDim, "Artifacts:"), len(r.Artifacts), r.ArtifactsDir)
+	}
 	if r.StdoutTruncated || r.StderrTruncated {
 		fmt.Fprintf(os.Stderr, "%s output truncated (use logs for full output)\n", color(colorDim, "Note:"))
 	}
@@ -269,6 +430,20 @@ func printRemoteOneShotResponse(r *remoteOneShotResponse) {
 
 // printRemoteResult dispatches formatted printing based on the tool name prefix.
 func printRemoteResult(toolName, text string) {
+	toolName = strings.TrimPrefix(toolName, toolPrefix)
+	var w struct {
+		Warnings []string `json:"warnings"`
+	}
+	if json.Unmarshal([]byte(text), &w) == nil {
+		printParamWarnings(w.Warnings)
+	}
+
+	// JSON output prints run results as the server returned them
+	if runOutput == outputJSON && strings.HasPrefix(toolName, "run_") {
+		fmt.Println(text)
+		return
+	}
+
 	switch {
 	case strings.HasPrefix(toolName, "run_workflow_"):
 		var r task.WorkflowResult
@@ -288,12 +463,34 @@ func printRemoteResult(toolName, text string) {
 			printDaemonStartResult(&r)
 			return
 		}
+	case toolName == "stop_all_daemons" || toolName == "restart_all_daemons":
+		var r struct {
+			Daemons []task.DaemonBulkResult `json:"daemons"`
+		}
+		if json.Unmarshal([]byte(text), &r) == nil {
+			printDaemonBulkResults(r.Daemons)
+			return
+		}
+	case toolName == "status_all":
+		var r struct {
+			Daemons []task.NamedDaemonStatus `json:"daemons"`
+		}
+		if json.Unmarshal([]byte(text), &r) == nil {
+			printDaemonStatuses(r.Daemons)
+			return
+		}
 	case strings.HasPrefix(toolName, "stop_"):
 		var r task.DaemonStopResult
 		if json.Unmarshal([]byte(text), &r) == nil {
 			printDaemonStopResult(&r)
 			return
 		}
+	case strings.HasPrefix(toolName, "reload_"):
+		var r task.DaemonReloadResult
+		if json.Unmarshal([]byte(text), &r) == nil {
+			printDaemonReloadResult(&r)
+			return
+		}
 	case strings.HasPrefix(toolName, "status_"):
 		var r task.DaemonStatus
 		if json.Unmarshal([]byte(text), &r) == nil {
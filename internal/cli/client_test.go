@@ -5,6 +5,8 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"runbookmcp.dev/internal/logs"
 )
 
 func TestParseRawParams(t *testing.T) {
@@ -177,3 +179,68 @@ func TestPrintRemoteResult_DaemonStatus(t *testing.T) {
 		t.Errorf("stderr %q does not contain '1234'", stderr)
 	}
 }
+
+func TestPrintRemoteResult_StatusAllTable(t *testing.T) {
+	input := `{"daemons":[` +
+		`{"task":"api","running":true,"pid":1234,"uptime":"5m0s","usage":{"rss_bytes":52428800,"cpu_percent":2.5,"cpu_seconds":7.5,"children":2}},` +
+		`{"task":"worker-long-name","running":false},` +
+		`{"task":"broken","error":"task 'broken' not found"}]}`
+	_, stderr := captureOutput(func() {
+		printRemoteResult("status_all", input)
+	})
+
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want a header and 3 rows:\n%s", len(lines), stderr)
+	}
+	col := strings.Index(lines[0], "STATUS")
+	for _, line := range lines[1:] {
+		if len(line) <= col || line[col-1] != ' ' || line[col] == ' ' {
+			t.Errorf("STATUS column not aligned at %d in %q", col, line)
+		}
+	}
+	if !strings.Contains(lines[1], "rss 50.0 MB, cpu 2.5% (7.5s), 2 children") {
+		t.Errorf("running row %q does not show its usage", lines[1])
+	}
+	if !strings.Contains(lines[3], "not found") {
+		t.Errorf("error row %q does not show the error", lines[3])
+	}
+}
+
+func TestPrintLogPreview(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := "preview-session"
+	if err := os.MkdirAll(logs.GetSessionDirectory(sessionID), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "one\ntwo\nthree\n" + strings.Repeat("x", previewLineWidth+20) + "\n"
+	if err := os.WriteFile(logs.GetSessionLogPath(sessionID), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr := captureOutput(func() {
+		printLogPreview(sessionID, 2)
+	})
+	if !strings.Contains(stderr, "2 earlier line(s)") {
+		t.Errorf("preview %q does not count the hidden lines", stderr)
+	}
+	if strings.Contains(stderr, "two") || !strings.Contains(stderr, "three") {
+		t.Errorf("preview %q should show only the last 2 lines", stderr)
+	}
+	if strings.Contains(stderr, strings.Repeat("x", previewLineWidth+1)) {
+		t.Errorf("preview did not cut the long line")
+	}
+}
+
+func TestExtractNoColorFlag(t *testing.T) {
+	off, rest := extractNoColorFlag([]string{"build", "--no-color", "--target=x"})
+	if !off {
+		t.Error("expected --no-color to be found")
+	}
+	if len(rest) != 2 || rest[0] != "build" || rest[1] != "--target=x" {
+		t.Errorf("remaining = %v", rest)
+	}
+}
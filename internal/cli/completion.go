@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/config"
+)
+
+// completionParam is a task or workflow parameter offered as a --name=value
+// completion.
+type completionParam struct {
+	Name        string
+	Description string
+	Required    bool
+	Default     string
+}
+
+// completionTarget is a task or workflow name a subcommand can complete,
+// with the parameters it accepts.
+type completionTarget struct {
+	Name        string
+	Description string
+	Params      []completionParam
+}
+
+// Target kinds accepted by each subcommand.
+const (
+	completeRunnable  = iota // oneshot and file_ops tasks, and workflows (run)
+	completeDaemons          // daemon and compose tasks (start, stop, status)
+	completeAllTasks         // any task (logs)
+	completeWorkflows        // workflows only (workflow run, workflow show)
+)
+
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion <bash|zsh|fish>",
+		Short: "Generate shell completion for task names, workflows, and --param flags",
+		Long: `Generate a shell completion script. Task names, workflow names, and each
+task's --param flags are completed from the manifest in the current directory.
+
+  bash:  source <(runbook completion bash)
+  zsh:   runbook completion zsh > "${fpath[1]}/_runbook"
+  fish:  runbook completion fish > ~/.config/fish/completions/runbook.fish`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			}
+			return fmt.Errorf("unsupported shell %q (use bash, zsh, or fish)", args[0])
+		},
+	}
+}
+
+// completionTargets lists the tasks and workflows of manifest that match kind,
+// sorted by name. Disabled items are left out.
+func completionTargets(manifest *config.Manifest, kind int) []completionTarget {
+	var targets []completionTarget
+	for name, t := range manifest.Tasks {
+		if t.Disabled || kind == completeWorkflows {
+			continue
+		}
+		switch kind {
+		case completeRunnable:
+			if t.Type.IsDaemon() {
+				continue
+			}
+		case completeDaemons:
+			if !t.Type.IsDaemon() {
+				continue
+			}
+		}
+		targets = append(targets, completionTarget{Name: name, Description: t.Description, Params: completionParams(t.Parameters)})
+	}
+	if kind == completeRunnable || kind == completeWorkflows {
+		for name, wf := range manifest.Workflows {
+			if wf.Disabled {
+				continue
+			}
+			targets = append(targets, completionTarget{Name: name, Description: wf.Description, Params: completionParams(wf.Parameters)})
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets
+}
+
+// completionParams converts parameter definitions, required ones first.
+func completionParams(params map[string]config.Param) []completionParam {
+	result := make([]completionParam, 0, len(params))
+	for name, p := range params {
+		cp := completionParam{Name: name, Description: p.Description, Required: p.Required}
+		if p.Default != nil {
+			cp.Default = *p.Default
+		}
+		result = append(result, cp)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Required != result[j].Required {
+			return result[i].Required
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// completeParamFlags returns --name= completions for the parameters not yet
+// given in args. A parameter being typed with its "=" completes to its default.
+func completeParamFlags(params []completionParam, args []string, toComplete string) []string {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		given[name] = true
+	}
+
+	var completions []string
+	for _, p := range params {
+		flag := "--" + p.Name + "="
+		if strings.HasPrefix(toComplete, flag) {
+			if p.Default != "" {
+				completions = append(completions, flag+p.Default)
+			}
+			continue
+		}
+		if given[p.Name] || !strings.HasPrefix(flag, toComplete) {
+			continue
+		}
+		desc := p.Description
+		if p.Required {
+			desc = "(required) " + desc
+		}
+		completions = append(completions, flag+"\t"+desc)
+	}
+	return completions
+}
+
+// completeTargetsFunc completes the task or workflow argument of a subcommand
+// and, when withParams is set, the target's --param flags. The manifest is
+// read directly, without starting a process manager or contacting a server.
+func completeTargetsFunc(kind int, withParams bool) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		configPath, workingDir, _, remaining := extractGlobalFlagsManual(args)
+		_, remaining = extractProjectFlag(remaining)
+		_, remaining = extractYesFlag(remaining)
+		_, remaining = extractNoColorFlag(remaining)
+		if configPath == "" {
+			configPath = globalConfig
+		}
+		if workingDir == "" {
+			workingDir = globalWorkingDir
+		}
+		if workingDir != "" {
+			if err := os.Chdir(workingDir); err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+
+		manifest, loaded, err := config.LoadManifest(configPath)
+		if err != nil || !loaded {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		targets := completionTargets(manifest, kind)
+
+		if len(remaining) == 0 {
+			var completions []string
+			for _, t := range targets {
+				if strings.HasPrefix(t.Name, toComplete) {
+					completions = append(completions, t.Name+"\t"+t.Description)
+				}
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		if !withParams {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		for _, t := range targets {
+			if t.Name == remaining[0] {
+				return completeParamFlags(t.Params, remaining[1:], toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+			}
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
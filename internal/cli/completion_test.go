@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const completionManifest = `version: "1.0"
+tasks:
+  build:
+    description: "Build the project"
+    command: "go build ./..."
+    type: oneshot
+  deploy:
+    description: "Deploy"
+    command: "./deploy.sh {{.env}}"
+    type: oneshot
+    parameters:
+      env:
+        type: string
+        required: true
+        description: "Target environment"
+      region:
+        type: string
+        required: false
+        description: "Region"
+        default: "us-east-1"
+  dev:
+    description: "Dev server"
+    command: "npm run dev"
+    type: daemon
+workflows:
+  ci:
+    description: "CI pipeline"
+    steps:
+      - task: build
+`
+
+// complete runs cobra's hidden completion command and returns the
+// completions it prints, without the trailing directive line.
+func complete(t *testing.T, args ...string) []string {
+	t.Helper()
+	cmd := newRootCmd("test-version")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs(append([]string{"__complete"}, args...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("__complete %v: %v", args, err)
+	}
+	var completions []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.HasPrefix(line, ":") {
+			completions = append(completions, line)
+		}
+	}
+	return completions
+}
+
+func TestCompletion(t *testing.T) {
+	resetGlobals(t)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "tasks.yaml")
+	if err := os.WriteFile(configPath, []byte(completionManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"run targets", []string{"run", "--config", configPath, ""}, []string{"build\tBuild the project", "ci\tCI pipeline", "deploy\tDeploy"}},
+		{"run prefix", []string{"run", "--config", configPath, "d"}, []string{"deploy\tDeploy"}},
+		{"start targets", []string{"start", "--config=" + configPath, ""}, []string{"dev\tDev server"}},
+		{"param flags", []string{"run", "--config", configPath, "deploy", ""}, []string{"--env=\t(required) Target environment", "--region=\tRegion"}},
+		{"param flag prefix", []string{"run", "--config", configPath, "deploy", "--e"}, []string{"--env=\t(required) Target environment"}},
+		{"given params are skipped", []string{"run", "--config", configPath, "deploy", "--env=prod", ""}, []string{"--region=\tRegion"}},
+		{"param default", []string{"run", "--config", configPath, "deploy", "--region="}, []string{"--region=us-east-1"}},
+		{"logs targets", []string{"--config", configPath, "logs", ""}, []string{"search\tSearch all session logs for a regex", "build\tBuild the project", "deploy\tDeploy", "dev\tDev server"}},
+		{"stop takes one task", []string{"--config", configPath, "stop", "dev", ""}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetGlobals(t)
+			got := complete(t, tt.args...)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("completions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	resetGlobals(t)
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		cmd := newRootCmd("test-version")
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetArgs([]string{"completion", shell})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("completion %s: %v", shell, err)
+		}
+		if !strings.Contains(buf.String(), "__complete") {
+			t.Errorf("%s script should call back into runbook for dynamic completion", shell)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"runbookmcp.dev/internal/task"
+)
+
+// globalYes is bound to --yes. It answers yes to confirmation prompts for
+// tasks with requires_confirmation.
+var globalYes bool
+
+// confirmInput is where confirmation answers are read from; tests replace it.
+var confirmInput io.Reader = os.Stdin
+
+// extractYesFlag scans raw args for --yes or -y and returns whether it was
+// present plus the remaining args. Used by DisableFlagParsing commands.
+func extractYesFlag(args []string) (bool, []string) {
+	yes := false
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--yes" || arg == "-y" {
+			yes = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return yes, remaining
+}
+
+// confirm asks the user to approve running name, showing preview if set.
+// It returns true without asking under --yes, and false when stdin is not a
+// terminal, since nobody is there to answer.
+func confirm(name, preview string) bool {
+	if globalYes {
+		return true
+	}
+	if f, ok := confirmInput.(*os.File); ok {
+		if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			fmt.Fprintf(os.Stderr, "Error: '%s' requires confirmation; re-run with --yes to run it non-interactively\n", name)
+			return false
+		}
+	}
+
+	if preview != "" {
+		fmt.Fprintf(os.Stderr, "%s\n%s\n", color(colorDim, "Command:"), preview)
+	}
+	fmt.Fprintf(os.Stderr, "Run '%s'? [y/N] ", name)
+	answer, _ := bufio.NewReader(confirmInput).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	fmt.Fprintln(os.Stderr, "Aborted.")
+	return false
+}
+
+// remoteConfirmation mirrors the server's confirmation_required response.
+type remoteConfirmation struct {
+	ConfirmationRequired bool   `json:"confirmation_required"`
+	ConfirmationToken    string `json:"confirmation_token"`
+	Preview              string `json:"preview"`
+}
+
+// parseRemoteConfirmation reports whether text is a confirmation_required
+// response and returns it.
+func parseRemoteConfirmation(text string) (*remoteConfirmation, bool) {
+	var r remoteConfirmation
+	if json.Unmarshal([]byte(text), &r) != nil || !r.ConfirmationRequired || r.ConfirmationToken == "" {
+		return nil, false
+	}
+	return &r, true
+}
+
+// confirmTask prompts before running a task with requires_confirmation,
+// showing the command it would run.
+func confirmTask(manager *task.Manager, taskName string, params map[string]interface{}) bool {
+	preview := ""
+	if resolved, err := manager.Resolve(taskName, params); err == nil {
+		preview = resolved.Command
+	}
+	return confirm(taskName, preview)
+}
+
+// confirmWorkflow prompts before running a workflow with a step that
+// requires confirmation, showing each step's command.
+func confirmWorkflow(manager *task.Manager, workflowName string, params map[string]interface{}) bool {
+	var commands []string
+	if steps, err := manager.ResolveWorkflow(workflowName, params); err == nil {
+		for _, step := range steps {
+			commands = append(commands, step.Command)
+		}
+	}
+	return confirm(workflowName, strings.Join(commands, "\n"))
+}
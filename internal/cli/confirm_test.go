@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExtractYesFlag(t *testing.T) {
+	yes, rest := extractYesFlag([]string{"deploy", "--env=prod", "--yes"})
+	if !yes || strings.Join(rest, " ") != "deploy --env=prod" {
+		t.Errorf("got (%v, %v)", yes, rest)
+	}
+	yes, rest = extractYesFlag([]string{"deploy", "-y"})
+	if !yes || len(rest) != 1 {
+		t.Errorf("-y: got (%v, %v)", yes, rest)
+	}
+	if yes, _ := extractYesFlag([]string{"deploy"}); yes {
+		t.Error("expected no --yes")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	old := confirmInput
+	t.Cleanup(func() {
+		confirmInput = old
+		globalYes = false
+	})
+
+	for answer, want := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false} {
+		confirmInput = strings.NewReader(answer)
+		if got := confirm("deploy", "echo deploy"); got != want {
+			t.Errorf("answer %q: confirm = %v, want %v", answer, got, want)
+		}
+	}
+
+	// Without a terminal there is nobody to ask
+	f, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	confirmInput = f
+	if confirm("deploy", "") {
+		t.Error("expected non-interactive input to be refused")
+	}
+
+	globalYes = true
+	if !confirm("deploy", "") {
+		t.Error("expected --yes to confirm")
+	}
+}
+
+func TestParseRemoteConfirmation(t *testing.T) {
+	if _, ok := parseRemoteConfirmation(`{"success":true,"stdout":"ok"}`); ok {
+		t.Error("normal result parsed as a confirmation")
+	}
+	r, ok := parseRemoteConfirmation(`{"confirmation_required":true,"confirmation_token":"abc","preview":"make deploy"}`)
+	if !ok || r.ConfirmationToken != "abc" || r.Preview != "make deploy" {
+		t.Errorf("got (%+v, %v)", r, ok)
+	}
+}
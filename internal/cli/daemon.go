@@ -3,37 +3,58 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/server"
+	"runbookmcp.dev/internal/task"
 )
 
 func newStartCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:                "start <task> [--param=value...]",
+		Use:                "start <task> [--fresh] [--param=value...]",
 		Short:              "Start a daemon",
 		DisableFlagParsing: true,
+		ValidArgsFunction:  completeTargetsFunc(completeDaemons, true),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			for _, a := range args {
-				if a == "--help" || a == "-h" {
-					return cmd.Help()
-				}
+			if hasHelpFlag(args) {
+				return targetHelp(cmd, completeDaemons, args)
 			}
 			extractedConfig, extractedWorkingDir, extractedLocal, remaining := extractGlobalFlagsManual(args)
 			mergeExtractedGlobals(extractedConfig, extractedWorkingDir, extractedLocal)
+			if project, rest := extractProjectFlag(remaining); project != "" {
+				globalProject = project
+				remaining = rest
+			}
+			if yes, rest := extractYesFlag(remaining); yes {
+				globalYes = true
+				remaining = rest
+			}
+			if off, rest := extractNoColorFlag(remaining); off {
+				noColor = true
+				remaining = rest
+			}
+			fresh, remaining := extractFreshFlag(remaining)
+			remaining = qualifyArgs(remaining)
 
 			if err := applyWorkingDir(); err != nil {
 				return err
 			}
 			if !globalLocal && isMCPEnabled(remaining) {
-				if code, handled := tryRemoteExecute("start", remaining); handled {
+				remoteArgs := remaining
+				if fresh {
+					remoteArgs = append(remoteArgs, "--"+server.FreshParam+"=true")
+				}
+				if code, handled := tryRemoteExecute("start", remoteArgs); handled {
 					if code != 0 {
 						return &exitError{code: code}
 					}
 					return nil
 				}
 			}
-			if code := cmdStart(remaining); code != 0 {
+			if code := cmdStart(remaining, fresh); code != 0 {
 				return &exitError{code: code}
 			}
 			return nil
@@ -42,11 +63,22 @@ func newStartCmd() *cobra.Command {
 }
 
 func newStopCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "stop <task>",
-		Short: "Stop a daemon",
-		Args:  cobra.ExactArgs(1),
+	var all bool
+	cmd := &cobra.Command{
+		Use:               "stop <task> | --all",
+		Short:             "Stop a daemon, or every running daemon",
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeTargetsFunc(completeDaemons, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkAllArgs(all, args); err != nil {
+				return err
+			}
+			if all {
+				return runWithRemoteFallback("stop-all", nil, func(_ []string) int {
+					return cmdStopAll()
+				})
+			}
+			args = qualifyArgs(args)
 			if !globalLocal && !isMCPEnabled(args) {
 				if code := cmdStop(args[0]); code != 0 {
 					return &exitError{code: code}
@@ -58,14 +90,82 @@ func newStopCmd() *cobra.Command {
 			})
 		},
 	}
+	cmd.Flags().BoolVar(&all, "all", false, "Stop every running daemon, dependents first")
+	return cmd
 }
 
-func newStatusCmd() *cobra.Command {
+func newReloadCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "status <task>",
-		Short: "Show daemon status",
-		Args:  cobra.ExactArgs(1),
+		Use:   "reload <task>",
+		Short: "Signal a daemon to reload its config without restarting",
+		Long: `Send a running daemon its reload_signal (default SIGHUP), for daemons that
+reload their configuration on a signal. The task must set reload: true.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTargetsFunc(completeDaemons, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			args = qualifyArgs(args)
+			if !globalLocal && !isMCPEnabled(args) {
+				if code := cmdReload(args[0]); code != 0 {
+					return &exitError{code: code}
+				}
+				return nil
+			}
+			return runWithRemoteFallback("reload", args, func(a []string) int {
+				return cmdReload(a[0])
+			})
+		},
+	}
+}
+
+func newRestartCmd() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "restart <task>... | --all",
+		Short: "Restart running daemons with the parameters they were started with",
+		Long: `Restart running daemons with the parameters they were started with. All of
+them are stopped first, dependents first, and then started again in
+dependency order. Daemons that are not running are skipped.`,
+		ValidArgsFunction: completeTargetsFunc(completeDaemons, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all && len(args) > 0 {
+				return fmt.Errorf("give task names or --all, not both")
+			}
+			if !all && len(args) == 0 {
+				return fmt.Errorf("requires a task name or --all")
+			}
+			args = qualifyArgs(args)
+			return runWithRemoteFallback("restart-all", args, cmdRestart)
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Restart every running daemon")
+	return cmd
+}
+
+// statusShowEvents is bound to "status --events". It is package-level so the
+// status printer can honor it for both local and proxied results.
+var statusShowEvents bool
+
+// statusLogLines is bound to "status --log-lines": how many of a running
+// daemon's latest log lines status previews.
+var statusLogLines int
+
+func newStatusCmd() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:               "status <task> [--events] [--log-lines=N] | --all",
+		Short:             "Show daemon status",
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: completeTargetsFunc(completeDaemons, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkAllArgs(all, args); err != nil {
+				return err
+			}
+			if all {
+				return runWithRemoteFallback("status-all", nil, func(_ []string) int {
+					return cmdStatusAll()
+				})
+			}
+			args = qualifyArgs(args)
 			if !globalLocal && !isMCPEnabled(args) {
 				if code := cmdStatus(args[0]); code != 0 {
 					return &exitError{code: code}
@@ -77,11 +177,30 @@ func newStatusCmd() *cobra.Command {
 			})
 		},
 	}
+	cmd.Flags().BoolVar(&statusShowEvents, "events", false, "Show recent lifecycle events (start, stop, crash, adopt)")
+	cmd.Flags().IntVar(&statusLogLines, "log-lines", 5, "Preview this many of a running daemon's latest log lines (0 = none)")
+	cmd.Flags().BoolVar(&all, "all", false, "Show the status of every daemon")
+	return cmd
+}
+
+// extractFreshFlag reports whether args contain --fresh and returns args
+// without it.
+func extractFreshFlag(args []string) (bool, []string) {
+	fresh := false
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--fresh" || arg == "-fresh" {
+			fresh = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return fresh, remaining
 }
 
-func cmdStart(args []string) int {
+func cmdStart(args []string, fresh bool) int {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: runbook start <task> [--param=value...]")
+		fmt.Fprintln(os.Stderr, "Usage: runbook start <task> [--fresh] [--param=value...]")
 		return 1
 	}
 
@@ -100,7 +219,7 @@ func cmdStart(args []string) int {
 		return 1
 	}
 
-	if taskDef.Type != config.TaskTypeDaemon {
+	if !taskDef.Type.IsDaemon() {
 		fmt.Fprintf(os.Stderr, "Error: '%s' is not a daemon task. Use 'runbook run %s' instead.\n", taskName, taskName)
 		return 1
 	}
@@ -111,7 +230,15 @@ func cmdStart(args []string) int {
 		return 1
 	}
 
-	result, err := manager.StartDaemon(taskName, params)
+	if taskDef.RequiresConfirmation && !confirmTask(manager, taskName, params) {
+		return 1
+	}
+
+	start := manager.StartDaemon
+	if fresh {
+		start = manager.StartDaemonFresh
+	}
+	result, err := start(taskName, params)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
@@ -146,6 +273,27 @@ func cmdStop(taskName string) int {
 	return 0
 }
 
+func cmdReload(taskName string) int {
+	_, manager, _, err := bootstrap(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := manager.ReloadDaemon(taskName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	printDaemonReloadResult(result)
+
+	if !result.Success {
+		return 1
+	}
+	return 0
+}
+
 func cmdStatus(taskName string) int {
 	_, manager, _, err := bootstrap(globalConfig)
 	if err != nil {
@@ -162,3 +310,101 @@ func cmdStatus(taskName string) int {
 	printDaemonStatus(status)
 	return 0
 }
+
+// checkAllArgs checks that a command got either one task name or --all.
+func checkAllArgs(all bool, args []string) error {
+	if all && len(args) > 0 {
+		return fmt.Errorf("give a task name or --all, not both")
+	}
+	if !all && len(args) == 0 {
+		return fmt.Errorf("requires a task name or --all")
+	}
+	return nil
+}
+
+// localDaemonNames returns the enabled daemons of the manifest, limited to
+// the selected project.
+func localDaemonNames(manifest *config.Manifest) []string {
+	var names []string
+	for name, t := range manifest.Tasks {
+		if !t.Type.IsDaemon() || t.Disabled {
+			continue
+		}
+		if _, ok := projectLocalName(name); !ok {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bulkExitCode is 1 if any daemon of a bulk operation failed.
+func bulkExitCode(results []task.DaemonBulkResult) int {
+	for _, r := range results {
+		if !r.Success {
+			return 1
+		}
+	}
+	return 0
+}
+
+func cmdStopAll() int {
+	manifest, manager, _, err := bootstrap(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	results := manager.StopDaemons(localDaemonNames(manifest))
+	printDaemonBulkResults(results)
+	return bulkExitCode(results)
+}
+
+// cmdRestart restarts the named daemons, or every daemon if names is empty.
+func cmdRestart(names []string) int {
+	manifest, manager, _, err := bootstrap(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if len(names) == 0 {
+		names = localDaemonNames(manifest)
+	}
+	var gated []string
+	for _, name := range names {
+		t, exists := manifest.Tasks[name]
+		if !exists {
+			fmt.Fprintf(os.Stderr, "Error: task '%s' not found\n", name)
+			return 1
+		}
+		if !t.Type.IsDaemon() {
+			fmt.Fprintf(os.Stderr, "Error: '%s' is not a daemon task\n", name)
+			return 1
+		}
+		if t.RequiresConfirmation {
+			if status, err := manager.DaemonStatus(name); err == nil && status.Running {
+				gated = append(gated, name)
+			}
+		}
+	}
+	if len(gated) > 0 && !confirm("restart", strings.Join(gated, "\n")) {
+		return 1
+	}
+
+	results := manager.RestartDaemons(names)
+	printDaemonBulkResults(results)
+	return bulkExitCode(results)
+}
+
+func cmdStatusAll() int {
+	manifest, manager, _, err := bootstrap(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	printDaemonStatuses(manager.DaemonStatuses(localDaemonNames(manifest)))
+	return 0
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/task"
+)
+
+func newExecCmd() *cobra.Command {
+	var (
+		execTimeout int
+		execCwd     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "exec [--timeout=N] [--cwd=DIR] <command...>",
+		Short: "Run an ad-hoc shell command as a logged session",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			// Ad-hoc commands always run locally; the exec_command tool on a
+			// shared server is opt-in and may not be enabled.
+			if code := cmdExec(strings.Join(args, " "), execCwd, execTimeout); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+
+	// Stop flag parsing at the first positional arg so the command's own flags
+	// are passed through untouched (e.g. "runbook exec ls -la").
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().IntVar(&execTimeout, "timeout", 0, "Timeout in seconds (default: exec/defaults timeout)")
+	cmd.Flags().StringVar(&execCwd, "cwd", "", "Working directory for the command")
+
+	return cmd
+}
+
+func cmdExec(command, workingDir string, timeout int) int {
+	if err := logs.Setup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to setup logs: %v\n", err)
+		return 1
+	}
+
+	// A config is optional for ad-hoc commands; when present its exec and
+	// defaults settings (timeout, shell, env) apply.
+	manifest, _, err := config.LoadManifest(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		return 1
+	}
+
+	// No process manager: ad-hoc commands never start daemons.
+	manager := task.NewManager(manifest, nil)
+	manager.SetStreaming(os.Stdout, os.Stderr)
+
+	result, err := manager.ExecuteCommand(command, workingDir, timeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	printExecutionResult(result)
+
+	if !result.Success {
+		if result.ExitCode > 0 {
+			return result.ExitCode
+		}
+		return 1
+	}
+	return 0
+}
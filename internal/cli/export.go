@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/server"
+)
+
+// exportFormats are the formats accepted by export tools --format.
+var exportFormats = []string{"json", "openapi"}
+
+// exportFileFormats are the formats accepted by export --format.
+var exportFileFormats = []string{config.ExportGitHubActions, config.ExportMakefile}
+
+func newExportCmd(v string) *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "export [task|workflow...]",
+		Short: "Export tasks and workflows as CI config, or descriptions of the configuration",
+		Long: `Convert tasks and workflows into a GitHub Actions workflow
+(--format github-actions) or a Makefile (--format makefile) on stdout, so CI
+config doesn't have to be kept in step with the runbook by hand. Oneshot
+tasks and workflows are exported; name some to export only those (default:
+all). Commands are inlined where they can be; tasks that need runbook, such
+as those with file parameters or template logic beyond {{.name}}, run
+through runbook run.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				return cmd.Help()
+			}
+			if format != config.ExportGitHubActions && format != config.ExportMakefile {
+				return fmt.Errorf("invalid --format %q (use github-actions or makefile)", format)
+			}
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			if code := cmdExport(format, args); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "Output format: github-actions or makefile")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(exportFileFormats, cobra.ShellCompDirectiveNoFileComp))
+	cmd.AddCommand(newExportToolsCmd(v))
+	return cmd
+}
+
+func cmdExport(format string, names []string) int {
+	manifest, loaded, err := config.LoadManifest(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		return 1
+	}
+	if !loaded {
+		fmt.Fprintf(os.Stderr, "Error: no config found; create %s/ or use --config\n", dirs.ConfigDir)
+		return 1
+	}
+
+	var out string
+	if format == config.ExportMakefile {
+		out, err = config.ExportMakefileText(manifest, names)
+	} else {
+		out, err = config.ExportGitHubActionsText(manifest, names)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Print(out)
+	return 0
+}
+
+func newExportToolsCmd(v string) *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "tools",
+		Short: "Print every generated tool's name, description, and input schema",
+		Long: `Print the tools the MCP server generates from the configuration, after
+overrides, so systems that don't speak MCP can consume the task catalog.
+--format json lists the tools; --format openapi describes each one as an
+OpenAPI operation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" && format != "openapi" {
+				return fmt.Errorf("invalid --format %q (use json or openapi)", format)
+			}
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			// The catalog comes from the local manifest, even when a server is running.
+			if code := cmdExportTools(v, format); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "Output format: json or openapi")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(exportFormats, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func cmdExportTools(v, format string) int {
+	manifest, loaded, err := config.LoadManifest(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		return 1
+	}
+	if !loaded {
+		fmt.Fprintf(os.Stderr, "Error: no config found; create %s/ or use --config\n", dirs.ConfigDir)
+		return 1
+	}
+
+	var doc interface{}
+	if format == "openapi" {
+		doc = server.ToolsOpenAPI(manifest, v)
+	} else {
+		doc = map[string]interface{}{"tools": server.DescribeTools(manifest)}
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
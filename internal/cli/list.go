@@ -3,67 +3,257 @@ package cli
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
 )
 
+// listOptions are the filters of the list command.
+type listOptions struct {
+	Type  string // "oneshot", "daemon", or "workflow"; empty lists everything
+	Group string // only tasks in this task group
+	All   bool   // include disabled tasks and workflows
+}
+
+// listTypes are the values accepted by list --type.
+var listTypes = []string{"oneshot", "daemon", "workflow"}
+
 func newListCmd() *cobra.Command {
-	return &cobra.Command{
+	var opts listOptions
+	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List available tasks",
+		Short: "List available tasks and workflows",
+		Long: `List tasks, grouped by task group, and workflows. Daemons show whether they
+are running. --type, --group, and --all read the local manifest, even when a
+server is running.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runWithRemoteFallback("list", args, func(_ []string) int {
-				return cmdList()
-			})
+			if opts.Type != "" && !slices.Contains(listTypes, opts.Type) {
+				return fmt.Errorf("invalid --type %q (use %s)", opts.Type, strings.Join(listTypes, ", "))
+			}
+			if opts == (listOptions{}) {
+				return runWithRemoteFallback("list", args, func(_ []string) int {
+					return cmdList(opts)
+				})
+			}
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			if code := cmdList(opts); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
 		},
 	}
+	cmd.Flags().StringVar(&opts.Type, "type", "", "Only list items of this type: "+strings.Join(listTypes, ", "))
+	cmd.Flags().StringVar(&opts.Group, "group", "", "Only list tasks in this task group")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Include disabled tasks and workflows")
+	cmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(listTypes, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
 }
 
-func cmdList() int {
-	manifest, _, _, err := bootstrap(globalConfig)
+// listTaskGroup is a task group heading and the tasks listed under it.
+type listTaskGroup struct {
+	Name        string
+	Description string
+	Tasks       []string
+}
+
+func cmdList(opts listOptions) int {
+	manifest, _, processManager, err := bootstrap(globalConfig)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
+	tasks := projectItems(manifest.Tasks)
+	workflows := projectItems(manifest.Workflows)
+	groups := projectItems(manifest.TaskGroups)
+
+	var overrides *config.Overrides
+	if opts.All {
+		overrides, _ = config.LoadOverrides("./" + dirs.OverridesFile)
+	}
+
+	if opts.Group != "" {
+		if _, ok := groups[opts.Group]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: task group '%s' not found\n", opts.Group)
+			return 1
+		}
+	}
+
 	var taskNames []string
-	for name, t := range manifest.Tasks {
-		if t.Disabled {
-			continue
+	if opts.Type != "workflow" {
+		for name, t := range tasks {
+			if t.Disabled && !opts.All {
+				continue
+			}
+			if (opts.Type == "daemon" && !t.Type.IsDaemon()) || (opts.Type == "oneshot" && t.Type.IsDaemon()) {
+				continue
+			}
+			taskNames = append(taskNames, name)
 		}
-		taskNames = append(taskNames, name)
+		sort.Strings(taskNames)
 	}
-	sort.Strings(taskNames)
 
 	var workflowNames []string
-	for name, wf := range manifest.Workflows {
-		if wf.Disabled {
-			continue
+	if (opts.Type == "" || opts.Type == "workflow") && opts.Group == "" {
+		for name, wf := range workflows {
+			if wf.Disabled && !opts.All {
+				continue
+			}
+			workflowNames = append(workflowNames, name)
 		}
-		workflowNames = append(workflowNames, name)
+		sort.Strings(workflowNames)
+	}
+
+	taskGroups := groupTasks(taskNames, groups, opts.Group)
+	taskNames = nil
+	for _, g := range taskGroups {
+		taskNames = append(taskNames, g.Tasks...)
 	}
-	sort.Strings(workflowNames)
 
 	if len(taskNames) == 0 && len(workflowNames) == 0 {
 		fmt.Fprintln(os.Stderr, "No tasks or workflows defined.")
 		return 0
 	}
 
+	// STATUS shows whether daemons are running and why items are disabled.
+	status := make(map[string]string)
+	for _, name := range taskNames {
+		t := tasks[name]
+		switch {
+		case t.Disabled:
+			status[name] = disabledReason(overrides.TaskDisabled(qualifiedName(name)))
+		case t.Type.IsDaemon():
+			status[name] = "stopped"
+			if running, _, err := processManager.Status(qualifiedName(name)); err == nil && running {
+				status[name] = "running"
+			}
+		}
+	}
+	workflowStatus := make(map[string]string)
+	for _, name := range workflowNames {
+		if workflows[name].Disabled {
+			workflowStatus[name] = disabledReason(overrides.WorkflowDisabled(qualifiedName(name)))
+		}
+	}
+
 	if len(taskNames) > 0 {
-		// Compute column widths from data (no ANSI codes involved).
-		// Include param label lengths in col1 so they never bleed into the TYPE column.
-		col1 := len("TASK")
-		col2 := len("TYPE")
-		for _, name := range taskNames {
+		printTaskTable(tasks, taskGroups, status)
+	}
+
+	if len(workflowNames) > 0 {
+		if len(taskNames) > 0 {
+			fmt.Println()
+		}
+
+		col1 := len("WORKFLOW")
+		col2 := len("STEPS")
+		col3 := len("STATUS")
+		for _, name := range workflowNames {
+			if len(name) > col1 {
+				col1 = len(name)
+			}
+			if stepsStr := workflowSteps(workflows[name]); len(stepsStr) > col2 {
+				col2 = len(stepsStr)
+			}
+			if len(workflowStatus[name]) > col3 {
+				col3 = len(workflowStatus[name])
+			}
+		}
+
+		fmt.Printf("%s%s  %s%s  %s%s  %s\n",
+			colorOut(colorBold, "WORKFLOW"), strings.Repeat(" ", col1-len("WORKFLOW")),
+			colorOut(colorBold, "STEPS"), strings.Repeat(" ", col2-len("STEPS")),
+			colorOut(colorBold, "STATUS"), strings.Repeat(" ", col3-len("STATUS")),
+			colorOut(colorBold, "DESCRIPTION"))
+
+		for _, name := range workflowNames {
+			wf := workflows[name]
+			fmt.Printf("%-*s  %-*s  %s  %s\n", col1, name, col2, workflowSteps(wf), statusCell(workflowStatus[name], col3), wf.Description)
+		}
+	}
+
+	return 0
+}
+
+// groupTasks sorts taskNames under the task groups that list them. Tasks in
+// no group are listed last, under a heading only if there are other groups.
+// With only set, just that group is returned. A task in several groups is
+// listed under each.
+func groupTasks(taskNames []string, groups map[string]config.TaskGroup, only string) []listTaskGroup {
+	listed := make(map[string]bool, len(taskNames))
+	for _, name := range taskNames {
+		listed[name] = true
+	}
+
+	var groupNames []string
+	for name := range groups {
+		if only == "" || name == only {
+			groupNames = append(groupNames, name)
+		}
+	}
+	sort.Strings(groupNames)
+
+	var result []listTaskGroup
+	grouped := make(map[string]bool)
+	for _, groupName := range groupNames {
+		g := listTaskGroup{Name: groupName, Description: groups[groupName].Description}
+		for _, member := range groups[groupName].Tasks {
+			name, ok := projectLocalName(member)
+			if !ok || !listed[name] {
+				continue
+			}
+			g.Tasks = append(g.Tasks, name)
+			grouped[name] = true
+		}
+		if len(g.Tasks) > 0 {
+			sort.Strings(g.Tasks)
+			result = append(result, g)
+		}
+	}
+	if only != "" {
+		return result
+	}
+
+	rest := listTaskGroup{}
+	if len(result) > 0 {
+		rest.Name = "other"
+	}
+	for _, name := range taskNames {
+		if !grouped[name] {
+			rest.Tasks = append(rest.Tasks, name)
+		}
+	}
+	if len(rest.Tasks) > 0 {
+		result = append(result, rest)
+	}
+	return result
+}
+
+// printTaskTable prints the TASK table, with a heading before each named group.
+func printTaskTable(tasks map[string]config.Task, groups []listTaskGroup, status map[string]string) {
+	// Compute column widths from data (no ANSI codes involved).
+	// Include param label lengths in col1 so they never bleed into the TYPE column.
+	col1 := len("TASK")
+	col2 := len("TYPE")
+	col3 := len("STATUS")
+	for _, g := range groups {
+		for _, name := range g.Tasks {
 			if len(name) > col1 {
 				col1 = len(name)
 			}
-			t := manifest.Tasks[name]
+			t := tasks[name]
 			if len(string(t.Type)) > col2 {
 				col2 = len(string(t.Type))
 			}
+			if len(status[name]) > col3 {
+				col3 = len(status[name])
+			}
 			for pn, p := range t.Parameters {
 				var plainLabel string
 				if p.Required {
@@ -78,16 +268,27 @@ func cmdList() int {
 				}
 			}
 		}
+	}
+
+	// Header: color the words, pad with plain spaces so alignment is exact
+	fmt.Printf("%s%s  %s%s  %s%s  %s\n",
+		colorOut(colorBold, "TASK"), strings.Repeat(" ", col1-len("TASK")),
+		colorOut(colorBold, "TYPE"), strings.Repeat(" ", col2-len("TYPE")),
+		colorOut(colorBold, "STATUS"), strings.Repeat(" ", col3-len("STATUS")),
+		colorOut(colorBold, "DESCRIPTION"))
 
-		// Header: color the words, pad with plain spaces so alignment is exact
-		fmt.Printf("%s%s  %s%s  %s\n",
-			color(colorBold, "TASK"), strings.Repeat(" ", col1-len("TASK")),
-			color(colorBold, "TYPE"), strings.Repeat(" ", col2-len("TYPE")),
-			color(colorBold, "DESCRIPTION"))
+	for _, g := range groups {
+		if g.Name != "" {
+			heading := colorOut(colorBold, "["+g.Name+"]")
+			if g.Description != "" {
+				heading += " " + colorOut(colorDim, g.Description)
+			}
+			fmt.Println(heading)
+		}
 
-		for _, name := range taskNames {
-			t := manifest.Tasks[name]
-			fmt.Printf("%-*s  %-*s  %s\n", col1, name, col2, string(t.Type), t.Description)
+		for _, name := range g.Tasks {
+			t := tasks[name]
+			fmt.Printf("%-*s  %-*s  %s  %s\n", col1, name, col2, string(t.Type), statusCell(status[name], col3), t.Description)
 
 			if len(t.Parameters) > 0 {
 				var paramNames []string
@@ -99,60 +300,61 @@ func cmdList() int {
 				for _, pn := range paramNames {
 					p := t.Parameters[pn]
 
-					// Param rows: col1=label, col2=empty, col3=description.
+					// Param rows: col1=label, col2/col3=empty, col4=description.
 					// Using %-*s for col1 ensures description aligns with DESCRIPTION header.
 					var displayLabel string
 					if p.Required {
-						displayLabel = fmt.Sprintf("  --%s %s", pn, color(colorRed, "(required)"))
+						displayLabel = fmt.Sprintf("  --%s %s", pn, colorOut(colorRed, "(required)"))
 						plainLabel := fmt.Sprintf("  --%s (required)", pn)
-						fmt.Printf("%s%s  %-*s  %s\n", displayLabel, strings.Repeat(" ", col1-len(plainLabel)), col2, "", p.Description)
+						fmt.Printf("%s%s  %-*s  %-*s  %s\n", displayLabel, strings.Repeat(" ", col1-len(plainLabel)), col2, "", col3, "", p.Description)
 					} else if p.Default != nil {
 						displayLabel = fmt.Sprintf("  --%s [default: %v]", pn, *p.Default)
-						fmt.Printf("%-*s  %-*s  %s\n", col1, displayLabel, col2, "", p.Description)
+						fmt.Printf("%-*s  %-*s  %-*s  %s\n", col1, displayLabel, col2, "", col3, "", p.Description)
 					} else {
 						displayLabel = fmt.Sprintf("  --%s", pn)
-						fmt.Printf("%-*s  %-*s  %s\n", col1, displayLabel, col2, "", p.Description)
+						fmt.Printf("%-*s  %-*s  %-*s  %s\n", col1, displayLabel, col2, "", col3, "", p.Description)
 					}
 				}
 			}
 		}
 	}
+}
 
-	if len(workflowNames) > 0 {
-		if len(taskNames) > 0 {
-			fmt.Println()
-		}
-
-		col1 := len("WORKFLOW")
-		col2 := len("STEPS")
-		for _, name := range workflowNames {
-			if len(name) > col1 {
-				col1 = len(name)
-			}
-			wf := manifest.Workflows[name]
-			var steps []string
-			for _, s := range wf.Steps {
-				steps = append(steps, s.Task)
-			}
-			if stepsStr := strings.Join(steps, " -> "); len(stepsStr) > col2 {
-				col2 = len(stepsStr)
-			}
-		}
+// statusCell colors a STATUS value and pads it to width.
+func statusCell(status string, width int) string {
+	padding := strings.Repeat(" ", width-len(status))
+	switch {
+	case status == "running":
+		return colorOut(colorGreen, status) + padding
+	case strings.HasPrefix(status, "disabled"):
+		return colorOut(colorDim, status) + padding
+	}
+	return status + padding
+}
 
-		fmt.Printf("%s%s  %s%s  %s\n",
-			color(colorBold, "WORKFLOW"), strings.Repeat(" ", col1-len("WORKFLOW")),
-			color(colorBold, "STEPS"), strings.Repeat(" ", col2-len("STEPS")),
-			color(colorBold, "DESCRIPTION"))
+// disabledReason is the STATUS of a disabled task or workflow.
+func disabledReason(byOverrides bool) string {
+	if byOverrides {
+		return "disabled (" + dirs.OverridesFile + ")"
+	}
+	return "disabled (config)"
+}
 
-		for _, name := range workflowNames {
-			wf := manifest.Workflows[name]
-			var steps []string
-			for _, s := range wf.Steps {
-				steps = append(steps, s.Task)
-			}
-			fmt.Printf("%-*s  %-*s  %s\n", col1, name, col2, strings.Join(steps, " -> "), wf.Description)
-		}
+// qualifiedName returns the manifest name of an item listed under its
+// project-local name.
+func qualifiedName(name string) string {
+	if globalProject == "" {
+		return name
 	}
+	return globalProject + config.ProjectSeparator + name
+}
 
-	return 0
+// workflowSteps returns the steps of wf joined with arrows.
+func workflowSteps(wf config.Workflow) string {
+	var steps []string
+	for _, s := range wf.Steps {
+		step, _ := projectLocalName(s.Target())
+		steps = append(steps, step)
+	}
+	return strings.Join(steps, " -> ")
 }
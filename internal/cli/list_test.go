@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+const listManifest = `version: "1.0"
+tasks:
+  build:
+    description: "Build the project"
+    command: "go build ./..."
+    type: oneshot
+  lint:
+    description: "Lint"
+    command: "golangci-lint run"
+    type: oneshot
+  dev:
+    description: "Dev server"
+    command: "npm run dev"
+    type: daemon
+  legacy:
+    description: "Old deploy"
+    command: "./old-deploy.sh"
+    type: oneshot
+    disabled: true
+  scratch:
+    description: "Scratch"
+    command: "true"
+    type: oneshot
+task_groups:
+  backend:
+    description: "Backend tasks"
+    tasks: [build, dev]
+workflows:
+  ci:
+    description: "CI pipeline"
+    steps:
+      - task: build
+`
+
+func setupListTest(t *testing.T) {
+	t.Helper()
+	resetGlobals(t)
+	dir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+
+	globalConfig = filepath.Join(dir, "tasks.yaml")
+	if err := os.WriteFile(globalConfig, []byte(listManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	overrides := "tasks:\n  scratch:\n    disabled: true\n"
+	if err := os.WriteFile(dirs.OverridesFile, []byte(overrides), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// listLines runs cmdList and returns its stdout lines with spacing collapsed.
+func listLines(t *testing.T, opts listOptions) []string {
+	t.Helper()
+	var code int
+	stdout, stderr := captureOutput(func() { code = cmdList(opts) })
+	if code != 0 {
+		t.Fatalf("cmdList(%+v) = %d, stderr: %s", opts, code, stderr)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	return lines
+}
+
+func TestListGroupsAndStatus(t *testing.T) {
+	setupListTest(t)
+
+	got := strings.Join(listLines(t, listOptions{}), "\n")
+	want := strings.Join([]string{
+		"TASK TYPE STATUS DESCRIPTION",
+		"[backend] Backend tasks",
+		"build oneshot Build the project",
+		"dev daemon stopped Dev server",
+		"[other]",
+		"lint oneshot Lint",
+		"",
+		"WORKFLOW STEPS STATUS DESCRIPTION",
+		"ci build CI pipeline",
+	}, "\n")
+	if got != want {
+		t.Errorf("list output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	setupListTest(t)
+
+	tests := []struct {
+		name     string
+		opts     listOptions
+		contains []string
+		excludes []string
+	}{
+		{"daemons", listOptions{Type: "daemon"}, []string{"dev daemon stopped"}, []string{"build", "ci"}},
+		{"oneshots", listOptions{Type: "oneshot"}, []string{"build oneshot", "lint oneshot"}, []string{"dev", "WORKFLOW"}},
+		{"workflows", listOptions{Type: "workflow"}, []string{"ci build CI pipeline"}, []string{"TASK"}},
+		{"group", listOptions{Group: "backend"}, []string{"build oneshot", "dev daemon"}, []string{"lint", "[other]", "WORKFLOW"}},
+		{"all", listOptions{All: true}, []string{
+			"legacy oneshot disabled (config) Old deploy",
+			"scratch oneshot disabled (" + dirs.OverridesFile + ") Scratch",
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := strings.Join(listLines(t, tt.opts), "\n")
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("output missing %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(out, s) {
+					t.Errorf("output should not contain %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestListUnknownGroup(t *testing.T) {
+	setupListTest(t)
+	var code int
+	_, stderr := captureOutput(func() { code = cmdList(listOptions{Group: "missing"}) })
+	if code == 0 || !strings.Contains(stderr, "task group 'missing' not found") {
+		t.Errorf("expected unknown group error, got code %d, stderr %q", code, stderr)
+	}
+}
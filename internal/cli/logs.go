@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"runbookmcp.dev/internal/logs"
@@ -15,18 +16,21 @@ func newLogsCmd() *cobra.Command {
 		logsFilter  string
 		logsSession string
 		logsOffset  int
+		logsStream  string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "logs <task>",
-		Short: "Show task logs",
-		Args:  cobra.ExactArgs(1),
+		Use:               "logs <task>",
+		Short:             "Show task logs",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTargetsFunc(completeAllTasks, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := applyWorkingDir(); err != nil {
 				return err
 			}
 			// Logs always read locally (even when server is running).
-			if code := execLogs(args[0], logsLines, logsFilter, logsSession, logsOffset); code != 0 {
+			args = qualifyArgs(args)
+			if code := execLogs(args[0], logsLines, logsFilter, logsSession, logsOffset, logsStream); code != 0 {
 				return &exitError{code: code}
 			}
 			return nil
@@ -37,14 +41,87 @@ func newLogsCmd() *cobra.Command {
 	cmd.Flags().StringVar(&logsFilter, "filter", "", "Regex pattern to filter lines")
 	cmd.Flags().StringVar(&logsSession, "session", "", "Session ID to read from (default: latest)")
 	cmd.Flags().IntVar(&logsOffset, "offset", 0, "Skip last N lines (for paging backwards through history)")
+	cmd.Flags().StringVar(&logsStream, "stream", "", "Only show stdout or stderr (default: both, interleaved)")
+	cmd.RegisterFlagCompletionFunc("stream", cobra.FixedCompletions([]string{logs.StreamStdout, logs.StreamStderr}, cobra.ShellCompDirectiveNoFileComp))
 
+	cmd.AddCommand(newLogsSearchCmd())
 	return cmd
 }
 
+func newLogsSearchCmd() *cobra.Command {
+	var (
+		task  string
+		since string
+		until string
+		limit int
+	)
+	cmd := &cobra.Command{
+		Use:   "search <pattern>",
+		Short: "Search all session logs for a regex",
+		Long: `Search the logs of all sessions for lines matching a regex, newest session
+first, to find when an error last occurred. --since and --until take an
+RFC 3339 time, a date (YYYY-MM-DD), or a duration like 24h (that long ago).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			opts := logs.SearchOptions{Limit: limit}
+			if task != "" {
+				opts.TaskName = qualifiedName(task)
+			}
+			now := time.Now()
+			var err error
+			if since != "" {
+				if opts.Since, err = logs.ParseTimeBound(since, now); err != nil {
+					return fmt.Errorf("--since: %w", err)
+				}
+			}
+			if until != "" {
+				if opts.Until, err = logs.ParseTimeBound(until, now); err != nil {
+					return fmt.Errorf("--until: %w", err)
+				}
+			}
+			// Logs always read locally (even when server is running).
+			if code := cmdLogsSearch(args[0], opts); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&task, "task", "", "Only search this task's sessions")
+	cmd.Flags().StringVar(&since, "since", "", "Only sessions started at or after this time")
+	cmd.Flags().StringVar(&until, "until", "", "Only sessions started before this time")
+	cmd.Flags().IntVar(&limit, "limit", 100, "Maximum number of matches (0 = all)")
+	cmd.RegisterFlagCompletionFunc("task", completeTargetsFunc(completeAllTasks, false))
+	return cmd
+}
+
+func cmdLogsSearch(pattern string, opts logs.SearchOptions) int {
+	matches, truncated, err := logs.SearchLogs(pattern, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(matches) == 0 {
+		fmt.Fprintln(os.Stderr, "No matches found.")
+		return 0
+	}
+
+	for _, m := range matches {
+		prefix := fmt.Sprintf("%s %s %s:%d", m.StartTime.Local().Format("2006-01-02 15:04:05"), m.SessionID, m.TaskName, m.Line)
+		fmt.Printf("%s  %s\n", colorOut(colorDim, prefix), m.Text)
+	}
+	if truncated {
+		fmt.Fprintf(os.Stderr, "%s showing the first %d matches (raise --limit)\n", color(colorDim, "Note:"), len(matches))
+	}
+	return 0
+}
+
 // cmdLogs accepts a raw arg slice (used by client.go's remoteExecute fallback).
 func cmdLogs(args []string) int {
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: runbook logs <task> [--lines=N] [--filter=REGEX] [--session=ID] [--offset=N]")
+		fmt.Fprintln(os.Stderr, "Usage: runbook logs <task> [--lines=N] [--filter=REGEX] [--session=ID] [--offset=N] [--stream=stdout|stderr]")
 		return 1
 	}
 
@@ -57,16 +134,17 @@ func cmdLogs(args []string) int {
 	filter := fs.String("filter", "", "Regex pattern to filter lines")
 	sessionID := fs.String("session", "", "Session ID to read from (default: latest)")
 	offset := fs.Int("offset", 0, "Skip last N lines (for paging backwards through history)")
+	stream := fs.String("stream", "", "Only show stdout or stderr (default: both, interleaved)")
 
 	if err := fs.Parse(flagArgs); err != nil {
 		return 1
 	}
 
-	return execLogs(taskName, *lines, *filter, *sessionID, *offset)
+	return execLogs(taskName, *lines, *filter, *sessionID, *offset, *stream)
 }
 
 // execLogs is the typed implementation shared by both entry points.
-func execLogs(taskName string, lines int, filter string, sessionID string, offset int) int {
+func execLogs(taskName string, lines int, filter string, sessionID string, offset int, stream string) int {
 	manifest, _, _, err := bootstrap(globalConfig)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -83,6 +161,7 @@ func execLogs(taskName string, lines int, filter string, sessionID string, offse
 		Filter:    filter,
 		SessionID: sessionID,
 		Offset:    offset,
+		Stream:    stream,
 	}
 
 	logLines, _, err := logs.ReadLog(taskName, opts)
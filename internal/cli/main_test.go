@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain points XDG_STATE_HOME at a temporary directory, so daemons the commands start in these tests
+// record their projects there instead of in the user's real index.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "runbook-state-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("XDG_STATE_HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
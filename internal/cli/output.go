@@ -3,9 +3,11 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"runbookmcp.dev/internal/logs"
 	"runbookmcp.dev/internal/task"
 )
 
@@ -29,14 +31,61 @@ func isTerminal(f *os.File) bool {
 	return info.Mode()&os.ModeCharDevice != 0
 }
 
+// noColor is bound to --no-color. Setting NO_COLOR in the environment has
+// the same effect.
+var noColor bool
+
+// extractNoColorFlag scans raw args for --no-color and returns whether it was
+// present plus the remaining args. Used by DisableFlagParsing commands.
+func extractNoColorFlag(args []string) (bool, []string) {
+	off := false
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--no-color" || arg == "-no-color" {
+			off = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return off, remaining
+}
+
+// colorEnabled reports whether output written to f should be colored:
+// f is a terminal and color was not turned off.
+func colorEnabled(f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(f)
+}
+
 // color wraps text in ANSI color if stderr is a terminal.
 func color(code, text string) string {
-	if !isTerminal(os.Stderr) {
+	if !colorEnabled(os.Stderr) {
+		return text
+	}
+	return code + text + colorReset
+}
+
+// colorOut wraps text in ANSI color if stdout is a terminal, for tables
+// and other output written to stdout.
+func colorOut(code, text string) string {
+	if !colorEnabled(os.Stdout) {
 		return text
 	}
 	return code + text + colorReset
 }
 
+// padRight pads text to width with spaces, measuring it before it is
+// colored so ANSI codes do not throw off the columns.
+func padRight(text string, width int, paint func(string) string) string {
+	padding := ""
+	if width > len(text) {
+		padding = strings.Repeat(" ", width-len(text))
+	}
+	return paint(text) + padding
+}
+
 // printExecutionResult prints a oneshot execution result with human-friendly formatting.
 // Task output goes to stdout (pipeable), metadata goes to stderr.
 // When r.Streamed is true the output was already written in real time; skip reprinting.
@@ -59,7 +108,12 @@ func printExecutionResult(r *task.ExecutionResult) {
 
 	// Print summary to stderr
 	fmt.Fprintln(os.Stderr)
-	if r.Success {
+	if r.Status == task.StatusWarning {
+		fmt.Fprintf(os.Stderr, "%s  exit code %d  %s\n",
+			color(colorYellow+colorBold, "[WARN]"),
+			r.ExitCode,
+			color(colorDim, formatDuration(r.Duration)))
+	} else if r.Success {
 		fmt.Fprintf(os.Stderr, "%s  %s\n",
 			color(colorGreen+colorBold, "[OK]"),
 			color(colorDim, formatDuration(r.Duration)))
@@ -73,12 +127,63 @@ func printExecutionResult(r *task.ExecutionResult) {
 			r.ExitCode,
 			color(colorDim, formatDuration(r.Duration)))
 	}
+	if r.StatusReason != "" {
+		fmt.Fprintf(os.Stderr, "%s %s (exit code %d)\n", color(colorDim, "Status:"), r.StatusReason, r.ExitCode)
+	}
+	if r.Cached {
+		fmt.Fprintf(os.Stderr, "%s result of session %s; pass --no-cache to run again\n", color(colorDim, "Cached:"), r.SessionID)
+	}
+	if r.StdoutLog != "" && !r.Streamed {
+		fmt.Fprintf(os.Stderr, "%s cut by log_output; all %d lines are in %s\n", color(colorDim, "Stdout:"), r.StdoutTotalLines, r.StdoutLog)
+	}
 	if r.Error != "" {
 		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorRed, "Error:"), r.Error)
 	}
+	if r.OutputError != "" {
+		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorYellow, "Output:"), r.OutputError)
+	}
+	printPreconditionFailure(r.PreconditionFailed)
 	if r.SessionID != "" {
 		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Session:"), r.SessionID)
 	}
+	if len(r.DaemonsStarted) > 0 {
+		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Started daemons:"), strings.Join(r.DaemonsStarted, ", "))
+	}
+	printResources(r.Resources)
+	if len(r.Artifacts) > 0 {
+		fmt.Fprintf(os.Stderr, "%s %d file(s) in %s\n", color(colorDim, "Artifacts:"), len(r.Artifacts), logs.GetSessionArtifactsDir(r.SessionID))
+	}
+}
+
+// printResources prints the resource usage line of a result, if recorded.
+func printResources(r *logs.ResourceUsage) {
+	if r == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Resources:"), formatResources(r))
+}
+
+// printParamWarnings prints the warnings for deprecated parameter names.
+func printParamWarnings(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorYellow+colorBold, "Warning:"), w)
+	}
+}
+
+// formatResources summarizes resource usage, e.g. "cpu 1.2s user + 0.3s sys, max rss 48.0 MB".
+func formatResources(r *logs.ResourceUsage) string {
+	s := fmt.Sprintf("cpu %.1fs user + %.1fs sys", r.UserCPUSeconds, r.SystemCPUSeconds)
+	if r.MaxRSSBytes > 0 {
+		s += fmt.Sprintf(", max rss %.1f MB", float64(r.MaxRSSBytes)/(1024*1024))
+	}
+	return s
+}
+
+// formatGroupUsage summarizes a daemon's live usage, e.g.
+// "rss 48.0 MB, cpu 2.5% (12.0s), 3 children".
+func formatGroupUsage(u *logs.GroupUsage) string {
+	return fmt.Sprintf("rss %.1f MB, cpu %.1f%% (%.1fs), %d children",
+		float64(u.RSSBytes)/(1024*1024), u.CPUPercent, u.CPUSeconds, u.Children)
 }
 
 // printWorkflowResult prints a workflow execution result with human-friendly formatting.
@@ -86,6 +191,13 @@ func printWorkflowResult(r *task.WorkflowResult) {
 	fmt.Fprintln(os.Stderr)
 	for _, step := range r.Steps {
 		if step.Skipped {
+			if step.SkipReason != "" {
+				fmt.Fprintf(os.Stderr, "  %s %s %s\n",
+					color(colorDim, "[SKIP]"),
+					step.TaskName,
+					color(colorDim, "("+step.SkipReason+")"))
+				continue
+			}
 			fmt.Fprintf(os.Stderr, "  %s %s\n",
 				color(colorDim, "[SKIP]"),
 				step.TaskName)
@@ -107,17 +219,23 @@ func printWorkflowResult(r *task.WorkflowResult) {
 				fmt.Fprintln(os.Stderr)
 			}
 		}
+		attempts := ""
+		if step.Attempts > 1 {
+			attempts = fmt.Sprintf("  %d attempts", step.Attempts)
+		}
 		if step.Result.Success {
-			fmt.Fprintf(os.Stderr, "  %s %s  %s\n",
+			fmt.Fprintf(os.Stderr, "  %s %s  %s%s\n",
 				color(colorGreen, "[OK]"),
 				step.TaskName,
-				color(colorDim, formatDuration(step.Result.Duration)))
+				color(colorDim, formatDuration(step.Result.Duration)),
+				attempts)
 		} else {
-			fmt.Fprintf(os.Stderr, "  %s %s  exit code %d  %s\n",
+			fmt.Fprintf(os.Stderr, "  %s %s  exit code %d  %s%s\n",
 				color(colorRed, "[FAIL]"),
 				step.TaskName,
 				step.Result.ExitCode,
-				color(colorDim, formatDuration(step.Result.Duration)))
+				color(colorDim, formatDuration(step.Result.Duration)),
+				attempts)
 		}
 	}
 
@@ -142,8 +260,12 @@ func printWorkflowResult(r *task.WorkflowResult) {
 // printDaemonStartResult prints a daemon start result.
 func printDaemonStartResult(r *task.DaemonStartResult) {
 	if r.Success {
+		label := "[STARTED]"
+		if r.Restarted {
+			label = "[RESTARTED]"
+		}
 		fmt.Fprintf(os.Stderr, "%s  PID %d\n",
-			color(colorGreen+colorBold, "[STARTED]"),
+			color(colorGreen+colorBold, label),
 			r.PID)
 		fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Logs:"), r.LogPath)
 		if r.SessionID != "" {
@@ -167,12 +289,34 @@ func printDaemonStopResult(r *task.DaemonStopResult) {
 	}
 }
 
+// printDaemonReloadResult prints a daemon reload result.
+func printDaemonReloadResult(r *task.DaemonReloadResult) {
+	if r.Success {
+		fmt.Fprintf(os.Stderr, "%s  sent %s to PID %d\n",
+			color(colorGreen+colorBold, "[RELOADED]"),
+			r.Signal, r.PID)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s %s\n",
+			color(colorRed+colorBold, "[ERROR]"),
+			r.Error)
+	}
+}
+
 // printDaemonStatus prints daemon status information.
 func printDaemonStatus(s *task.DaemonStatus) {
 	if s.Running {
 		fmt.Fprintf(os.Stderr, "%s  PID %d\n",
 			color(colorGreen+colorBold, "[RUNNING]"),
 			s.PID)
+		if s.Uptime != "" {
+			fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Uptime:"), s.Uptime)
+		}
+		if s.Usage != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Resources:"), formatGroupUsage(s.Usage))
+		}
+		if len(s.Ports) > 0 {
+			fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Ports:"), formatPorts(s.Ports))
+		}
 		if s.LogPath != "" {
 			fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Logs:"), s.LogPath)
 		}
@@ -181,6 +325,152 @@ func printDaemonStatus(s *task.DaemonStatus) {
 		}
 	} else {
 		fmt.Fprintf(os.Stderr, "%s\n", color(colorYellow+colorBold, "[STOPPED]"))
+		if e := s.LastExit; e != nil {
+			fmt.Fprintf(os.Stderr, "%s %s  %s\n", color(colorDim, "Last exit:"), e.Time.Local().Format("2006-01-02 15:04:05"), e.Reason)
+		}
+	}
+	for _, svc := range s.Services {
+		state := svc.State
+		if svc.Health != "" {
+			state += " (" + svc.Health + ")"
+		}
+		fmt.Fprintf(os.Stderr, "  %s %s\n", color(colorDim, svc.Service+":"), state)
+	}
+	if s.Running && statusLogLines > 0 {
+		printLogPreview(s.SessionID, statusLogLines)
+	}
+	if statusShowEvents {
+		printDaemonEvents(s.LastEvents)
+	}
+}
+
+// previewLineWidth is how much of each log line a preview shows.
+const previewLineWidth = 160
+
+// printLogPreview prints the last lines of a session log, each cut to
+// previewLineWidth, with a note on how to see the rest.
+func printLogPreview(sessionID string, lines int) {
+	if sessionID == "" {
+		return
+	}
+	tail, total, err := logs.ReadSessionLog(sessionID, logs.ReadOptions{Lines: lines})
+	if err != nil || len(tail) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, color(colorDim, "Recent output:"))
+	if hidden := total - len(tail); hidden > 0 {
+		fmt.Fprintf(os.Stderr, "  %s\n", color(colorDim, fmt.Sprintf("... %d earlier line(s); raise --log-lines or use runbook logs", hidden)))
+	}
+	for _, line := range tail {
+		if len(line) > previewLineWidth {
+			line = line[:previewLineWidth] + color(colorDim, "...")
+		}
+		fmt.Fprintf(os.Stderr, "  %s\n", line)
+	}
+}
+
+// printDaemonBulkResults prints one line per daemon of a bulk stop or
+// restart.
+func printDaemonBulkResults(results []task.DaemonBulkResult) {
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "No daemons defined.")
+		return
+	}
+	for _, r := range results {
+		switch {
+		case !r.Success:
+			fmt.Fprintf(os.Stderr, "%s %s: %s\n", color(colorRed+colorBold, "[ERROR]"), r.Task, r.Error)
+		case r.Action == task.BulkActionRestarted:
+			fmt.Fprintf(os.Stderr, "%s %s  PID %d\n", color(colorGreen+colorBold, "[RESTARTED]"), r.Task, r.PID)
+		case r.Action == task.BulkActionStopped:
+			fmt.Fprintf(os.Stderr, "%s %s\n", color(colorGreen+colorBold, "[STOPPED]"), r.Task)
+		default:
+			fmt.Fprintf(os.Stderr, "%s %s %s\n", color(colorDim, "[SKIPPED]"), r.Task, color(colorDim, "("+r.Message+")"))
+		}
+	}
+}
+
+// printDaemonStatuses prints a table of every daemon's status.
+func printDaemonStatuses(statuses []task.NamedDaemonStatus) {
+	if len(statuses) == 0 {
+		fmt.Fprintln(os.Stderr, "No daemons defined.")
+		return
+	}
+
+	type row struct {
+		task, state, pid, uptime, ports, usage string
+		paint                                  func(string) string
+	}
+	rows := make([]row, len(statuses))
+	widths := [5]int{len("DAEMON"), len("STATUS"), len("PID"), len("UPTIME"), len("PORTS")}
+	for i, s := range statuses {
+		r := row{task: s.Task}
+		switch {
+		case s.Error != "":
+			r.state, r.usage = "error", s.Error
+			r.paint = func(t string) string { return color(colorRed+colorBold, t) }
+		case s.Running:
+			r.state, r.pid, r.uptime, r.ports = "running", strconv.Itoa(s.PID), s.Uptime, formatPorts(s.Ports)
+			r.paint = func(t string) string { return color(colorGreen+colorBold, t) }
+			if s.Usage != nil {
+				r.usage = formatGroupUsage(s.Usage)
+			}
+		default:
+			r.state = "stopped"
+			r.paint = func(t string) string { return color(colorYellow+colorBold, t) }
+		}
+		for j, cell := range []string{r.task, r.state, r.pid, r.uptime, r.ports} {
+			widths[j] = max(widths[j], len(cell))
+		}
+		rows[i] = r
+	}
+
+	bold := func(t string) string { return color(colorBold, t) }
+	plain := func(t string) string { return t }
+	fmt.Fprintf(os.Stderr, "%s  %s  %s  %s  %s  %s\n",
+		padRight("DAEMON", widths[0], bold), padRight("STATUS", widths[1], bold),
+		padRight("PID", widths[2], bold), padRight("UPTIME", widths[3], bold),
+		padRight("PORTS", widths[4], bold), bold("RESOURCES"))
+	for _, r := range rows {
+		line := fmt.Sprintf("%s  %s  %s  %s  %s  %s",
+			padRight(r.task, widths[0], plain), padRight(r.state, widths[1], r.paint),
+			padRight(r.pid, widths[2], plain), padRight(r.uptime, widths[3], plain),
+			padRight(r.ports, widths[4], plain), color(colorDim, r.usage))
+		fmt.Fprintln(os.Stderr, strings.TrimRight(line, " "))
+	}
+}
+
+// formatPorts formats a daemon's ports as a comma-separated list.
+func formatPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, ",")
+}
+
+// printDaemonEvents prints daemon lifecycle events, oldest first.
+func printDaemonEvents(events []logs.DaemonEvent) {
+	fmt.Fprintln(os.Stderr)
+	if len(events) == 0 {
+		fmt.Fprintln(os.Stderr, color(colorDim, "No recorded events"))
+		return
+	}
+	for _, e := range events {
+		eventColor := colorDim
+		switch e.Event {
+		case logs.EventStart:
+			eventColor = colorGreen
+		case logs.EventCrash:
+			eventColor = colorRed + colorBold
+		case logs.EventStop, logs.EventExit, logs.EventAdopt:
+			eventColor = colorYellow
+		}
+		fmt.Fprintf(os.Stderr, "%s  %s  PID %d  %s\n",
+			color(colorDim, e.Time.Local().Format("2006-01-02 15:04:05")),
+			color(eventColor, fmt.Sprintf("%-6s", e.Event)),
+			e.PID,
+			e.Reason)
 	}
 }
 
@@ -194,3 +484,15 @@ func formatDuration(d time.Duration) string {
 	}
 	return fmt.Sprintf("%dm%ds", int(d.Minutes()), int(d.Seconds())%60)
 }
+
+// printPreconditionFailure prints which precondition kept a task from
+// running, and the output of a failed command check.
+func printPreconditionFailure(f *task.PreconditionFailure) {
+	if f == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s preconditions[%d] %s %s\n", color(colorDim, "Check:"), f.Index, f.Check, f.Target)
+	if f.Output != "" {
+		fmt.Fprintf(os.Stderr, "%s\n%s\n", color(colorDim, "Check output:"), f.Output)
+	}
+}
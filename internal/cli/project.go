@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"strings"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// globalProject is bound to --project. It selects a project hosted by a
+// multi-project server; task names are qualified as "<project>__<task>".
+var globalProject string
+
+// extractProjectFlag scans raw args for --project and returns its value plus
+// the remaining args. Used by DisableFlagParsing commands.
+func extractProjectFlag(args []string) (project string, remaining []string) {
+	remaining = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--project" || arg == "-project":
+			if i+1 < len(args) {
+				project = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--project=") || strings.HasPrefix(arg, "-project="):
+			project = arg[strings.IndexByte(arg, '=')+1:]
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+	return
+}
+
+// qualifyArgs returns args with the task name in args[0] qualified by the
+// selected project. Without --project, args are returned unchanged.
+func qualifyArgs(args []string) []string {
+	if globalProject == "" || len(args) == 0 {
+		return args
+	}
+	return append([]string{config.ProjectTaskName(globalProject, args[0])}, args[1:]...)
+}
+
+// projectLocalName reports whether name belongs to the selected project and
+// returns it without the project prefix. Without --project every name
+// belongs and is returned unchanged.
+func projectLocalName(name string) (string, bool) {
+	if globalProject == "" {
+		return name, true
+	}
+	prefix := globalProject + config.ProjectSeparator
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(name, prefix), true
+}
+
+// projectItems returns the entries of m that belong to the selected project,
+// keyed by their names without the project prefix.
+func projectItems[V any](m map[string]V) map[string]V {
+	if globalProject == "" {
+		return m
+	}
+	items := make(map[string]V)
+	for name, v := range m {
+		if local, ok := projectLocalName(name); ok {
+			items[local] = v
+		}
+	}
+	return items
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractProjectFlag(t *testing.T) {
+	project, remaining := extractProjectFlag([]string{"test", "--project", "api", "--flags=-v"})
+	if project != "api" || !reflect.DeepEqual(remaining, []string{"test", "--flags=-v"}) {
+		t.Errorf("got project=%q remaining=%v", project, remaining)
+	}
+	project, remaining = extractProjectFlag([]string{"--project=web", "dev"})
+	if project != "web" || !reflect.DeepEqual(remaining, []string{"dev"}) {
+		t.Errorf("got project=%q remaining=%v", project, remaining)
+	}
+}
+
+func TestProjectNames(t *testing.T) {
+	defer func() { globalProject = "" }()
+
+	globalProject = ""
+	if got := qualifyArgs([]string{"test"}); got[0] != "test" {
+		t.Errorf("expected unqualified name without --project, got %v", got)
+	}
+
+	globalProject = "api"
+	if got := qualifyArgs([]string{"test", "--v=1"}); !reflect.DeepEqual(got, []string{"api__test", "--v=1"}) {
+		t.Errorf("unexpected qualified args %v", got)
+	}
+	if name, ok := projectLocalName("api__test"); !ok || name != "test" {
+		t.Errorf("expected api__test to belong to api, got %q %v", name, ok)
+	}
+	if _, ok := projectLocalName("web__test"); ok {
+		t.Error("expected web__test not to belong to api")
+	}
+
+	items := projectItems(map[string]int{"api__a": 1, "b": 2})
+	if !reflect.DeepEqual(items, map[string]int{"a": 1}) {
+		t.Errorf("unexpected project items %v", items)
+	}
+}
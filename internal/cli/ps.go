@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/process"
+)
+
+func newPsCmd() *cobra.Command {
+	var killOrphans bool
+	cmd := &cobra.Command{
+		Use:   "ps [--kill-orphans]",
+		Short: "List the daemons runbook is running on this machine, across projects",
+		Long: `List every runbook daemon on this machine, in every project that has started
+one, with its project, task, PID, uptime, ports, and the runbook process that
+manages it. A daemon is orphaned when the process that started it has exited
+and no server is running for its project, as after "runbook start --local".
+--kill-orphans stops the orphaned daemons.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := process.ListAllDaemons()
+			if err != nil {
+				return err
+			}
+			if killOrphans {
+				return killOrphanedDaemons(entries)
+			}
+			printAllDaemons(entries)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&killOrphans, "kill-orphans", false, "Stop the daemons no runbook process manages")
+	return cmd
+}
+
+// printAllDaemons prints the daemons found by process.ListAllDaemons.
+func printAllDaemons(entries []process.DaemonEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "No daemons running.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		colorOut(colorBold, "PROJECT"),
+		colorOut(colorBold, "TASK"),
+		colorOut(colorBold, "PID"),
+		colorOut(colorBold, "UPTIME"),
+		colorOut(colorBold, "PORTS"),
+		colorOut(colorBold, "OWNER"))
+	for _, e := range entries {
+		owner := "PID " + strconv.Itoa(e.OwnerPID)
+		if e.Orphaned {
+			owner = colorOut(colorYellow+colorBold, "orphaned")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", e.Project, e.Task, e.PID,
+			time.Since(e.StartTime).Round(time.Second), formatPorts(e.Ports), owner)
+	}
+	w.Flush()
+}
+
+// killOrphanedDaemons stops the orphaned daemons among entries.
+func killOrphanedDaemons(entries []process.DaemonEntry) error {
+	killed, failed := 0, 0
+	for _, e := range entries {
+		if !e.Orphaned {
+			continue
+		}
+		if err := process.KillDaemon(e); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s  PID %d  %s: %v\n", color(colorRed+colorBold, "[ERROR]"), e.Task, e.PID, e.Project, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s %s  PID %d  %s\n", color(colorGreen+colorBold, "[STOPPED]"), e.Task, e.PID, e.Project)
+		killed++
+	}
+	if killed == 0 && failed == 0 {
+		fmt.Fprintln(os.Stderr, "No orphaned daemons.")
+	}
+	if failed > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
@@ -1,47 +1,96 @@
 package cli
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/server"
 	"runbookmcp.dev/internal/task"
 )
 
+// Formats of --output on run. JSON prints the result as the run_ tool
+// returns it, on stdout, instead of the task's output and a summary.
+const (
+	outputFlag = "output"
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// runOutput is the --output format of the current run.
+var runOutput = outputText
+
+// runNoCache is set by --no-cache. It makes tasks with a cache run their
+// command instead of returning a cached result.
+var runNoCache bool
+
 func newRunCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:                "run <task> [--param=value...]",
+		Use:                "run <task> [--preset=name] [--output=text|json] [--no-cache] [--param=value...]",
 		Short:              "Run a oneshot task or workflow",
 		DisableFlagParsing: true,
+		ValidArgsFunction:  completeTargetsFunc(completeRunnable, true),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			for _, a := range args {
-				if a == "--help" || a == "-h" {
-					return cmd.Help()
-				}
+			if hasHelpFlag(args) {
+				return targetHelp(cmd, completeRunnable, args)
 			}
-			extractedConfig, extractedWorkingDir, extractedLocal, remaining := extractGlobalFlagsManual(args)
-			mergeExtractedGlobals(extractedConfig, extractedWorkingDir, extractedLocal)
+			return runTarget(args, false)
+		},
+	}
+}
 
-			if err := applyWorkingDir(); err != nil {
+// runTarget runs the task or workflow named in the raw args of run, or of
+// workflow run with workflowsOnly set, through a running server when there
+// is one.
+func runTarget(args []string, workflowsOnly bool) error {
+	extractedConfig, extractedWorkingDir, extractedLocal, remaining := extractGlobalFlagsManual(args)
+	mergeExtractedGlobals(extractedConfig, extractedWorkingDir, extractedLocal)
+	if project, rest := extractProjectFlag(remaining); project != "" {
+		globalProject = project
+		remaining = rest
+	}
+	if yes, rest := extractYesFlag(remaining); yes {
+		globalYes = true
+		remaining = rest
+	}
+	if off, rest := extractNoColorFlag(remaining); off {
+		noColor = true
+		remaining = rest
+	}
+	if off, rest := extractNoCacheFlag(remaining); off {
+		runNoCache = true
+		remaining = rest
+	}
+	remaining = qualifyArgs(remaining)
+
+	if err := applyWorkingDir(); err != nil {
+		return err
+	}
+	if workflowsOnly && len(remaining) > 0 {
+		// A missing manifest is left for the run itself to report
+		if manifest, loaded, err := config.LoadManifest(globalConfig); err == nil && loaded {
+			if err := checkWorkflow(manifest, remaining[0]); err != nil {
 				return err
 			}
-			if !globalLocal && isMCPEnabled(remaining) {
-				if code, handled := tryRemoteExecute("run", remaining); handled {
-					if code != 0 {
-						return &exitError{code: code}
-					}
-					return nil
-				}
-			}
-			if code := cmdRun(remaining); code != 0 {
+		}
+	}
+	if !globalLocal && isMCPEnabled(remaining) {
+		if code, handled := tryRemoteExecute("run", remaining); handled {
+			if code != 0 {
 				return &exitError{code: code}
 			}
 			return nil
-		},
+		}
+	}
+	if code := cmdRun(remaining); code != 0 {
+		return &exitError{code: code}
 	}
+	return nil
 }
 
 func cmdRun(args []string) int {
@@ -79,11 +128,31 @@ func cmdRun(args []string) int {
 	}
 
 	// Suggest 'start' for daemons
-	if taskDef.Type == config.TaskTypeDaemon {
+	if taskDef.Type.IsDaemon() {
 		fmt.Fprintf(os.Stderr, "Error: '%s' is a daemon task. Use 'runbook start %s' instead.\n", taskName, taskName)
 		return 1
 	}
 
+	// Expand a preset into flags ahead of the explicit ones, so those win
+	if len(taskDef.ParameterPresets) > 0 {
+		preset, rest := extractPresetFlag(taskArgs)
+		if preset != "" {
+			presetArgs, err := presetFlags(taskDef, preset)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+			rest = append(presetArgs, rest...)
+		}
+		taskArgs = rest
+	}
+
+	format, taskArgs := extractOutputFlag(taskDef.Parameters, taskArgs)
+	if err := setRunOutput(format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
 	// Parse task parameters
 	params, err := parseTaskParams(taskDef, taskArgs)
 	if err != nil {
@@ -91,14 +160,37 @@ func cmdRun(args []string) int {
 		return 1
 	}
 
-	// Execute
+	if runNoCache && taskDef.Cache != nil {
+		params[task.NoCacheParam] = true
+	}
+
+	if taskDef.RequiresConfirmation && !confirmTask(manager, taskName, params) {
+		return 1
+	}
+
+	// Interactive tasks take over this terminal
+	manager.SetTerminal(&task.Terminal{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr})
+
+	// Execute; JSON output is printed whole afterwards, not streamed
+	if runOutput == outputJSON {
+		manager.SetStreaming(nil, nil)
+	}
 	result, err := manager.ExecuteOneShot(taskName, params)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	printExecutionResult(result)
+	if runOutput == outputJSON {
+		resultJSON, err := server.MarshalOneShotResult(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(resultJSON))
+	} else {
+		printExecutionResult(result)
+	}
 
 	if !result.Success {
 		if result.ExitCode != 0 {
@@ -109,20 +201,117 @@ func cmdRun(args []string) int {
 	return 0
 }
 
+// extractNoCacheFlag scans raw args for --no-cache and returns whether it
+// was present plus the remaining args.
+func extractNoCacheFlag(args []string) (bool, []string) {
+	found := false
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--no-cache" {
+			found = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return found, remaining
+}
+
+// extractPresetFlag returns the value of --preset (as --preset=NAME or
+// --preset NAME) and args without it.
+func extractPresetFlag(args []string) (string, []string) {
+	return extractValueFlag(config.PresetParam, args)
+}
+
+// extractOutputFlag returns the value of --output (as --output=FORMAT or
+// --output FORMAT) and args without it. A task or workflow with its own
+// output parameter keeps the flag, and the format is left empty.
+func extractOutputFlag(params map[string]config.Param, args []string) (string, []string) {
+	if _, taken := params[outputFlag]; taken {
+		return "", args
+	}
+	return extractValueFlag(outputFlag, args)
+}
+
+// extractValueFlag returns the value of the flag name (as --name=VALUE or
+// --name VALUE) and args without it.
+func extractValueFlag(name string, args []string) (string, []string) {
+	value := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimLeft(args[i], "-")
+		switch {
+		case arg == args[i]:
+			remaining = append(remaining, args[i])
+		case arg == name && i+1 < len(args):
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(arg, name+"="):
+			value = strings.TrimPrefix(arg, name+"=")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return value, remaining
+}
+
+// setRunOutput applies the --output format of a run, rejecting unknown ones.
+func setRunOutput(format string) error {
+	switch format {
+	case "":
+	case outputText, outputJSON:
+		runOutput = format
+	default:
+		return fmt.Errorf("invalid --output %q (use text or json)", format)
+	}
+	return nil
+}
+
+// presetFlags returns the named preset of taskDef as --param=value flags.
+func presetFlags(taskDef config.Task, preset string) ([]string, error) {
+	values := make(map[string]interface{})
+	if err := config.ApplyPreset(taskDef, preset, values); err != nil {
+		return nil, err
+	}
+	flags := make([]string, 0, len(values))
+	for name, value := range values {
+		flags = append(flags, fmt.Sprintf("--%s=%v", name, value))
+	}
+	sort.Strings(flags)
+	return flags, nil
+}
+
 func runWorkflow(manager *task.Manager, workflowName string, wfDef config.Workflow, args []string) int {
+	format, args := extractOutputFlag(wfDef.Parameters, args)
+	if err := setRunOutput(format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
 	params, err := parseWorkflowParams(wfDef, args)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
+	if config.WorkflowRequiresConfirmation(wfDef, manager.GetManifest().Tasks, manager.GetManifest().Workflows) && !confirmWorkflow(manager, workflowName, params) {
+		return 1
+	}
+
+	if runOutput == outputJSON {
+		manager.SetStreaming(nil, nil)
+	}
 	result, err := manager.ExecuteWorkflow(workflowName, params)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	printWorkflowResult(result)
+	if runOutput == outputJSON {
+		resultJSON, _ := json.Marshal(result)
+		fmt.Println(string(resultJSON))
+	} else {
+		printWorkflowResult(result)
+	}
 
 	if !result.Success {
 		return 1
@@ -151,6 +340,7 @@ func parseWorkflowParams(wfDef config.Workflow, args []string) (map[string]inter
 		}
 		flagPtrs[name] = fs.String(name, defaultVal, param.Description)
 	}
+	aliasPtrs := aliasFlags(fs, wfDef.Parameters)
 
 	if err := fs.Parse(args); err != nil {
 		return nil, err
@@ -165,6 +355,9 @@ func parseWorkflowParams(wfDef config.Workflow, args []string) (map[string]inter
 			params[name] = *ptr
 		}
 	}
+	if err := applyAliasFlags(fs, wfDef.Parameters, aliasPtrs, params); err != nil {
+		return nil, err
+	}
 
 	for name, param := range wfDef.Parameters {
 		if param.Required {
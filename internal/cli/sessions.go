@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/logs"
+)
+
+func newSessionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Inspect task sessions",
+	}
+	cmd.AddCommand(newSessionsDiffCmd())
+	return cmd
+}
+
+func newSessionsDiffCmd() *cobra.Command {
+	var maxLines int
+	cmd := &cobra.Command{
+		Use:   "diff <session> <session>",
+		Short: "Diff the logs of two sessions of the same task",
+		Long: `Diff the logs of two sessions of the same task, from the older to the newer.
+Timestamps, durations, UUIDs, and colors are ignored, and added lines that
+look like errors are highlighted.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			// Sessions are always read locally, like logs.
+			if code := cmdSessionsDiff(args[0], args[1], maxLines); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&maxLines, "max-lines", 0, "Maximum number of diff lines to show (0 = all)")
+	return cmd
+}
+
+func cmdSessionsDiff(sessionA, sessionB string, maxLines int) int {
+	diff, err := logs.DiffSessions(sessionA, sessionB, maxLines)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	printSessionDiff(diff)
+	return 0
+}
+
+// printSessionDiff prints a session diff as unified-diff hunks, with new
+// error lines in bold.
+func printSessionDiff(d *logs.SessionDiff) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "---"), d.From)
+	fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "+++"), d.To)
+	if len(d.Hunks) == 0 {
+		fmt.Fprintln(os.Stderr, "No differences.")
+		return
+	}
+	for _, h := range d.Hunks {
+		fmt.Println(colorOut(colorDim, fmt.Sprintf("@@ -%d +%d @@", h.FromLine, h.ToLine)))
+		for _, l := range h.Lines {
+			switch {
+			case l.Error:
+				fmt.Println(colorOut(colorRed+colorBold, l.Op+l.Text))
+			case l.Op == logs.DiffAdded:
+				fmt.Println(colorOut(colorGreen, l.Op+l.Text))
+			case l.Op == logs.DiffRemoved:
+				fmt.Println(colorOut(colorRed, l.Op+l.Text))
+			default:
+				fmt.Println(l.Op + l.Text)
+			}
+		}
+	}
+	if d.Truncated {
+		fmt.Fprintf(os.Stderr, "%s diff truncated (raise --max-lines)\n", color(colorDim, "Note:"))
+	}
+	fmt.Fprintf(os.Stderr, "\n%s +%d -%d", color(colorBold, d.TaskName), d.Added, d.Removed)
+	if len(d.NewErrors) > 0 {
+		fmt.Fprintf(os.Stderr, "  %s", color(colorRed+colorBold, fmt.Sprintf("%d new error line(s)", len(d.NewErrors))))
+	}
+	fmt.Fprintln(os.Stderr)
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// paramInput is where stdin parameters are read from; tests replace it.
+var paramInput io.Reader = os.Stdin
+
+// readParamSources replaces the values of taskDef's parameters that have a
+// source with the content they name: --name=@path reads a file and --name=-
+// reads stdin. A stdin parameter that was not given is read from stdin when
+// input is piped in.
+func readParamSources(taskDef config.Task, params map[string]interface{}) error {
+	for name, param := range taskDef.Parameters {
+		if param.Source == "" {
+			continue
+		}
+		value, given := params[name].(string)
+		switch {
+		case given && value == "-":
+			data, err := io.ReadAll(paramInput)
+			if err != nil {
+				return fmt.Errorf("failed to read --%s from stdin: %w", name, err)
+			}
+			params[name] = string(data)
+		case given && strings.HasPrefix(value, "@"):
+			data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+			if err != nil {
+				return fmt.Errorf("failed to read --%s: %w", name, err)
+			}
+			params[name] = string(data)
+		case !given && param.Source == config.ParamSourceStdin:
+			if f, ok := paramInput.(*os.File); ok && isTerminal(f) {
+				continue
+			}
+			data, err := io.ReadAll(paramInput)
+			if err != nil {
+				return fmt.Errorf("failed to read --%s from stdin: %w", name, err)
+			}
+			if len(data) > 0 {
+				params[name] = string(data)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/config"
+)
+
+// hasHelpFlag reports whether raw args ask for help.
+func hasHelpFlag(args []string) bool {
+	for _, a := range args {
+		if a == "--help" || a == "-h" {
+			return true
+		}
+	}
+	return false
+}
+
+// targetHelp prints the help of the task or workflow named in the raw args
+// of a DisableFlagParsing command, with its parameters read from the
+// manifest. It prints the command's own help when no known target is named.
+func targetHelp(cmd *cobra.Command, kind int, args []string) error {
+	configPath, workingDir, _, remaining := extractGlobalFlagsManual(args)
+	mergeExtractedGlobals(configPath, workingDir, false)
+	if project, rest := extractProjectFlag(remaining); project != "" {
+		globalProject = project
+		remaining = rest
+	}
+
+	var name string
+	for _, arg := range remaining {
+		if !strings.HasPrefix(arg, "-") {
+			name = arg
+			break
+		}
+	}
+	if name == "" {
+		return cmd.Help()
+	}
+	name = qualifyArgs([]string{name})[0]
+	if err := applyWorkingDir(); err != nil {
+		return err
+	}
+	manifest, loaded, err := config.LoadManifest(globalConfig)
+	if err != nil || !loaded {
+		return cmd.Help()
+	}
+
+	for _, t := range completionTargets(manifest, kind) {
+		if t.Name != name {
+			continue
+		}
+		var params map[string]config.Param
+		var presets []string
+		if def, ok := manifest.Tasks[name]; ok {
+			params = def.Parameters
+			for preset := range def.ParameterPresets {
+				presets = append(presets, preset)
+			}
+			sort.Strings(presets)
+		} else {
+			params = manifest.Workflows[name].Parameters
+		}
+		printTargetHelp(cmd.OutOrStdout(), cmd.Name(), name, t.Description, params, presets)
+		return nil
+	}
+	return cmd.Help()
+}
+
+// printTargetHelp prints the usage line, description, and parameter table
+// of a task or workflow to out.
+func printTargetHelp(out io.Writer, subcmd, name, description string, params map[string]config.Param, presets []string) {
+	usage := fmt.Sprintf("runbook %s %s", subcmd, name)
+	if len(presets) > 0 {
+		usage += " [--preset=name]"
+	}
+	if len(params) > 0 {
+		usage += " [--param=value...]"
+	}
+	fmt.Fprintf(out, "%s %s\n", colorOut(colorBold, "Usage:"), usage)
+	if description != "" {
+		fmt.Fprintf(out, "\n%s\n", description)
+	}
+
+	if len(params) > 0 {
+		fmt.Fprintf(out, "\n%s\n", colorOut(colorBold, "Parameters:"))
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for _, p := range completionParams(params) {
+			def := params[p.Name]
+			paramType := def.Type
+			if paramType == "" {
+				paramType = "string"
+			}
+			var notes []string
+			if p.Required {
+				notes = append(notes, "required")
+			}
+			if def.Default != nil {
+				notes = append(notes, "default: "+*def.Default)
+			}
+			if len(def.Aliases) > 0 {
+				notes = append(notes, "aliases: "+strings.Join(def.Aliases, ", "))
+			}
+			if def.Source != "" {
+				notes = append(notes, "passed via "+def.Source)
+			}
+			note := ""
+			if len(notes) > 0 {
+				note = "(" + strings.Join(notes, "; ") + ")"
+			}
+			fmt.Fprintf(w, "  --%s\t%s\t%s\t%s\n", p.Name, paramType, note, p.Description)
+		}
+		w.Flush()
+	}
+	if len(presets) > 0 {
+		fmt.Fprintf(out, "\n%s %s\n", colorOut(colorBold, "Presets:"), strings.Join(presets, ", "))
+	}
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+// waitPollInterval is how often wait checks on a run.
+const waitPollInterval = time.Second
+
+func newWaitCmd() *cobra.Command {
+	var timeout int
+	cmd := &cobra.Command{
+		Use:   "wait <session>",
+		Short: "Wait for a task run to finish and print its result",
+		Long: `Wait for a task run, such as one started with run_async, to finish and print
+its result. The exit code is 0 if the run succeeded. Runs started in the
+background by the server are followed through it; other sessions are read
+from their metadata.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			if code := cmdWait(args[0], time.Duration(timeout)*time.Second); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&timeout, "timeout", 0, "Seconds to wait before giving up (0 = no limit)")
+	return cmd
+}
+
+func cmdWait(sessionID string, timeout time.Duration) int {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	if serverData, err := process.ReadServerFile(globalWorkingDir); err == nil {
+		if !checkRegisteredServer(serverData) {
+			return 1
+		}
+		c, cleanup, err := newMCPClient(serverData)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error connecting to server at %s: %v\n", serverData.Addr, err)
+			return 1
+		}
+		defer cleanup()
+		return remoteWait(context.Background(), c, sessionID, deadline)
+	}
+	return waitForSession(sessionID, deadline)
+}
+
+// remoteWait polls task_status until the run completes, then prints its
+// result from task_result. Sessions the server did not start in the
+// background have no result there and are printed from their metadata.
+func remoteWait(ctx context.Context, c *mcpclient.Client, sessionID string, deadline time.Time) int {
+	for {
+		text, isError, err := callToolText(ctx, c, "task_status", map[string]any{"session_id": sessionID})
+		if err != nil || isError {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", errorText(text, err))
+			return 1
+		}
+		var status task.BackgroundStatus
+		if err := json.Unmarshal([]byte(text), &status); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid task_status response: %v\n", err)
+			return 1
+		}
+		if status.State == task.BackgroundCompleted {
+			break
+		}
+		if waitExpired(deadline, sessionID) {
+			return 1
+		}
+		time.Sleep(waitPollInterval)
+	}
+
+	text, isError, err := callToolText(ctx, c, "task_result", map[string]any{"session_id": sessionID, "max_output_lines": float64(0)})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if isError {
+		return printSessionResult(sessionID)
+	}
+	var r remoteOneShotResponse
+	if err := json.Unmarshal([]byte(text), &r); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid task_result response: %v\n", err)
+		return 1
+	}
+	printRemoteOneShotResponse(&r)
+	if !r.Success {
+		return 1
+	}
+	return 0
+}
+
+// waitForSession polls a session's metadata until it records an end time,
+// then prints the run's outcome.
+func waitForSession(sessionID string, deadline time.Time) int {
+	for {
+		metadata, err := logs.ReadSessionMetadata(sessionID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: session '%s' not found\n", sessionID)
+			return 1
+		}
+		if metadata.EndTime != nil {
+			return printSessionResult(sessionID)
+		}
+		if waitExpired(deadline, sessionID) {
+			return 1
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+// waitExpired reports, and prints, whether the wait's deadline has passed.
+func waitExpired(deadline time.Time, sessionID string) bool {
+	if deadline.IsZero() || time.Now().Before(deadline) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Error: session '%s' is still running\n", sessionID)
+	return true
+}
+
+// printSessionResult prints a finished session's outcome from its metadata.
+// The output is in the session log, not repeated here.
+func printSessionResult(sessionID string) int {
+	metadata, err := logs.ReadSessionMetadata(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: session '%s' not found\n", sessionID)
+		return 1
+	}
+	r := &task.ExecutionResult{
+		TaskName:  metadata.TaskName,
+		SessionID: sessionID,
+		Status:    metadata.Status,
+		TimedOut:  metadata.TimedOut,
+		Resources: metadata.Resources,
+		Artifacts: metadata.Artifacts,
+		Streamed:  true,
+	}
+	if metadata.Success != nil {
+		r.Success = *metadata.Success
+	}
+	if metadata.ExitCode != nil {
+		r.ExitCode = *metadata.ExitCode
+	}
+	if metadata.Duration != nil {
+		r.Duration = *metadata.Duration
+	}
+	printExecutionResult(r)
+	fmt.Fprintf(os.Stderr, "%s %s\n", color(colorDim, "Log:"), logs.GetSessionLogPath(sessionID))
+	if !r.Success {
+		return 1
+	}
+	return 0
+}
+
+// callToolText calls a tool and returns its text and whether it is an error.
+func callToolText(ctx context.Context, c *mcpclient.Client, toolName string, params map[string]any) (string, bool, error) {
+	result, err := c.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: toolName, Arguments: params},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	var text strings.Builder
+	for _, content := range result.Content {
+		if tc, ok := mcp.AsTextContent(content); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	return text.String(), result.IsError, nil
+}
+
+// errorText is the message of a failed tool call.
+func errorText(text string, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return text
+}
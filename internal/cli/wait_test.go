@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestWaitForSession(t *testing.T) {
+	resetGlobals(t)
+	t.Chdir(t.TempDir())
+
+	sessionID := logs.GenerateSessionID()
+	if err := logs.CreateSessionDirectory(sessionID); err != nil {
+		t.Fatal(err)
+	}
+	metadata := &logs.SessionMetadata{SessionID: sessionID, TaskName: "suite", TaskType: "oneshot", StartTime: time.Now()}
+	if err := logs.WriteSessionMetadata(sessionID, metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := cmdWait(sessionID, time.Millisecond); code != 1 {
+		t.Errorf("expected a running session to time out with code 1, got %d", code)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		end, exitCode, success := time.Now(), 2, false
+		metadata.EndTime, metadata.ExitCode, metadata.Success = &end, &exitCode, &success
+		_ = logs.WriteSessionMetadata(sessionID, metadata)
+	}()
+	if code := cmdWait(sessionID, 10*time.Second); code != 1 {
+		t.Errorf("expected the failed session to exit 1, got %d", code)
+	}
+
+	if code := cmdWait(logs.GenerateSessionID(), 0); code != 1 {
+		t.Errorf("expected an unknown session to fail, got %d", code)
+	}
+}
@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"runbookmcp.dev/internal/config"
+)
+
+func newWorkflowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workflow",
+		Short: "List, run, and inspect workflows",
+	}
+	cmd.AddCommand(newWorkflowListCmd(), newWorkflowRunCmd(), newWorkflowShowCmd())
+	return cmd
+}
+
+func newWorkflowListCmd() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List workflows and their steps",
+		Long: `List workflows and their steps. Like list --type=workflow, it reads the
+local manifest, even when a server is running.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			if code := cmdList(listOptions{Type: "workflow", All: all}); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Include disabled workflows")
+	return cmd
+}
+
+func newWorkflowRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:                "run <workflow> [--output=text|json] [--param=value...]",
+		Short:              "Run a workflow",
+		Long:               "Run a workflow, like run, but fail instead of running a task of the same name.",
+		DisableFlagParsing: true,
+		ValidArgsFunction:  completeTargetsFunc(completeWorkflows, true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if hasHelpFlag(args) {
+				return targetHelp(cmd, completeWorkflows, args)
+			}
+			return runTarget(args, true)
+		},
+	}
+}
+
+func newWorkflowShowCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "show <workflow>",
+		Short: "Show a workflow's parameters and resolved steps",
+		Long: `Show a workflow's parameters and its steps, with nested workflows expanded
+and each step's retries, timeout, and required daemons resolved against the
+task it runs. "uses" lists the earlier steps whose output or status a step
+reads. The local manifest is read, even when a server is running.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTargetsFunc(completeWorkflows, false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setRunOutput(output); err != nil {
+				return err
+			}
+			if err := applyWorkingDir(); err != nil {
+				return err
+			}
+			if code := cmdWorkflowShow(cmd.OutOrStdout(), qualifyArgs(args)[0]); code != 0 {
+				return &exitError{code: code}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&output, outputFlag, "", "Output format: text or json")
+	cmd.RegisterFlagCompletionFunc(outputFlag, cobra.FixedCompletions([]string{outputText, outputJSON}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// checkWorkflow returns an error when manifest has no workflow named name.
+func checkWorkflow(manifest *config.Manifest, name string) error {
+	if _, ok := manifest.Workflows[name]; ok {
+		return nil
+	}
+	local, _ := projectLocalName(name)
+	if _, ok := manifest.Tasks[name]; ok {
+		return fmt.Errorf("'%s' is a task, not a workflow; use 'runbook run %s'", local, local)
+	}
+	return fmt.Errorf("workflow '%s' not found", local)
+}
+
+// workflowGraph is a workflow with its steps resolved, as printed by
+// workflow show.
+type workflowGraph struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description,omitempty"`
+	Timeout     int                     `json:"timeout,omitempty"`
+	Parameters  map[string]config.Param `json:"parameters,omitempty"`
+	Steps       []workflowGraphStep     `json:"steps"`
+}
+
+// workflowGraphStep is a workflow step with the settings it runs with:
+// its own, or those of the task it runs. Workflow steps hold the steps of
+// the workflow they run.
+type workflowGraphStep struct {
+	Name              string              `json:"name"`
+	Kind              string              `json:"kind"`                // "task", "workflow", "wait", or "wait_for"
+	Target            string              `json:"target,omitempty"`    // Task or workflow the step runs
+	TaskType          string              `json:"task_type,omitempty"` // Type of the task the step runs
+	Detail            string              `json:"detail,omitempty"`    // What a wait or wait_for step waits for
+	When              string              `json:"when,omitempty"`
+	Uses              []string            `json:"uses,omitempty"` // Earlier steps whose output or status the step reads
+	Params            map[string]string   `json:"params,omitempty"`
+	RequiresDaemon    []string            `json:"requires_daemon,omitempty"`
+	Retries           int                 `json:"retries,omitempty"`
+	RetryDelay        int                 `json:"retry_delay,omitempty"`
+	Timeout           int                 `json:"timeout,omitempty"`
+	ContinueOnFailure bool                `json:"continue_on_failure,omitempty"`
+	Fresh             bool                `json:"fresh,omitempty"`
+	Steps             []workflowGraphStep `json:"steps,omitempty"`
+}
+
+func cmdWorkflowShow(out io.Writer, name string) int {
+	manifest, loaded, err := config.LoadManifest(globalConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		return 1
+	}
+	if !loaded {
+		fmt.Fprintln(os.Stderr, "Error: no config file found")
+		return 1
+	}
+	if err := checkWorkflow(manifest, name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	wf := manifest.Workflows[name]
+	local, _ := projectLocalName(name)
+	graph := workflowGraph{
+		Name:        local,
+		Description: wf.Description,
+		Timeout:     wf.Timeout,
+		Parameters:  wf.Parameters,
+		Steps:       resolveWorkflowSteps(wf, manifest),
+	}
+
+	if runOutput == outputJSON {
+		data, _ := json.Marshal(graph)
+		fmt.Fprintln(out, string(data))
+		return 0
+	}
+
+	printTargetHelp(out, "workflow run", local, wf.Description, wf.Parameters, nil)
+	if wf.Timeout > 0 {
+		fmt.Fprintf(out, "\n%s %ds\n", colorOut(colorBold, "Timeout:"), wf.Timeout)
+	}
+	fmt.Fprintf(out, "\n%s\n", colorOut(colorBold, "Steps:"))
+	printWorkflowSteps(out, graph.Steps, "  ", "")
+	return 0
+}
+
+// resolveWorkflowSteps returns the steps of wf with the settings they run
+// with, expanding the steps of nested workflows. Workflows are checked for
+// cycles when the manifest is loaded.
+func resolveWorkflowSteps(wf config.Workflow, manifest *config.Manifest) []workflowGraphStep {
+	steps := make([]workflowGraphStep, 0, len(wf.Steps))
+	for _, step := range wf.Steps {
+		target := step.Target()
+		if local, ok := projectLocalName(target); ok {
+			target = local
+		}
+		gs := workflowGraphStep{
+			Name:              step.Name(),
+			Kind:              "task",
+			Target:            target,
+			When:              step.When,
+			Uses:              stepUses(step),
+			Params:            step.Params,
+			RequiresDaemon:    appendMissing(nil, step.RequiresDaemon),
+			Retries:           step.Retries,
+			RetryDelay:        step.RetryDelay,
+			Timeout:           step.Timeout,
+			ContinueOnFailure: step.ContinueOnFailure,
+			Fresh:             step.Fresh,
+		}
+		switch {
+		case step.Wait != nil:
+			gs.Kind, gs.Target = config.StepWaitName, ""
+			gs.Detail = fmt.Sprintf("%gs", step.Wait.Seconds)
+		case step.WaitFor != nil:
+			gs.Kind, gs.Target = config.StepWaitForName, ""
+			gs.Detail = waitForDetail(step.WaitFor)
+		case step.Workflow != "":
+			gs.Kind = "workflow"
+			gs.Steps = resolveWorkflowSteps(manifest.Workflows[step.Workflow], manifest)
+		default:
+			t := manifest.Tasks[step.Task]
+			gs.TaskType = string(t.Type)
+			gs.RequiresDaemon = appendMissing(gs.RequiresDaemon, t.RequiresDaemon)
+			if gs.Retries == 0 {
+				gs.Retries = t.Retries
+			}
+			if gs.RetryDelay == 0 {
+				gs.RetryDelay = t.RetryDelay
+			}
+			if gs.Timeout == 0 {
+				gs.Timeout = t.Timeout
+			}
+		}
+		steps = append(steps, gs)
+	}
+	return steps
+}
+
+// stepUses returns the earlier steps a step's params, when expression, or
+// wait_for conditions refer to, in the order they first appear.
+func stepUses(step config.WorkflowStep) []string {
+	texts := []string{step.When}
+	keys := make([]string, 0, len(step.Params))
+	for key := range step.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		texts = append(texts, step.Params[key])
+	}
+	if step.WaitFor != nil {
+		texts = append(texts, step.WaitFor.URL, step.WaitFor.File)
+	}
+
+	var uses []string
+	for _, text := range texts {
+		var names []string
+		for _, m := range config.StepOutputPattern.FindAllStringSubmatch(text, -1) {
+			names = append(names, m[1])
+		}
+		for _, m := range config.StepConditionRefPattern.FindAllStringSubmatch(text, -1) {
+			names = append(names, m[1])
+		}
+		uses = appendMissing(uses, names)
+	}
+	return uses
+}
+
+// appendMissing appends the names not already in list.
+func appendMissing(list, names []string) []string {
+	for _, name := range names {
+		if !slices.Contains(list, name) {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// waitForDetail describes the conditions of a wait_for step.
+func waitForDetail(w *config.StepWaitFor) string {
+	var conds []string
+	if w.URL != "" {
+		conds = append(conds, "url "+w.URL)
+	}
+	if w.Port != 0 {
+		conds = append(conds, fmt.Sprintf("port %d", w.Port))
+	}
+	if w.File != "" {
+		conds = append(conds, "file "+w.File)
+	}
+	detail := strings.Join(conds, ", ")
+	if w.Timeout > 0 {
+		detail += fmt.Sprintf(" (timeout %ds)", w.Timeout)
+	}
+	return detail
+}
+
+// printWorkflowSteps prints steps as a numbered tree, with nested workflow
+// steps numbered under their parent, e.g. 2.1.
+func printWorkflowSteps(out io.Writer, steps []workflowGraphStep, indent, prefix string) {
+	for i, step := range steps {
+		number := fmt.Sprintf("%s%d", prefix, i+1)
+		runs := step.Kind + " " + step.Target
+		switch step.Kind {
+		case "task":
+			if step.TaskType != "" {
+				runs += " (" + step.TaskType + ")"
+			}
+		case config.StepWaitName, config.StepWaitForName:
+			runs = step.Kind + " " + step.Detail
+		}
+		fmt.Fprintf(out, "%s%s. %s  %s\n", indent, number, colorOut(colorBold, step.Name), colorOut(colorDim, runs))
+
+		detail := indent + strings.Repeat(" ", len(number)+2)
+		if step.When != "" {
+			fmt.Fprintf(out, "%swhen: %s\n", detail, step.When)
+		}
+		if len(step.Uses) > 0 {
+			fmt.Fprintf(out, "%suses: %s\n", detail, strings.Join(step.Uses, ", "))
+		}
+		if len(step.Params) > 0 {
+			keys := make([]string, 0, len(step.Params))
+			for key := range step.Params {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Fprintf(out, "%s--%s=%s\n", detail, key, step.Params[key])
+			}
+		}
+		if len(step.RequiresDaemon) > 0 {
+			fmt.Fprintf(out, "%srequires daemon: %s\n", detail, strings.Join(step.RequiresDaemon, ", "))
+		}
+		var settings []string
+		if step.Timeout > 0 {
+			settings = append(settings, fmt.Sprintf("timeout %ds", step.Timeout))
+		}
+		if step.Retries > 0 {
+			settings = append(settings, fmt.Sprintf("retries %d (delay %ds)", step.Retries, step.RetryDelay))
+		}
+		if step.ContinueOnFailure {
+			settings = append(settings, "continue on failure")
+		}
+		if step.Fresh {
+			settings = append(settings, "fresh daemons")
+		}
+		if len(settings) > 0 {
+			fmt.Fprintf(out, "%s%s\n", detail, strings.Join(settings, ", "))
+		}
+		if len(step.Steps) > 0 {
+			printWorkflowSteps(out, step.Steps, detail, number+".")
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+const workflowManifest = `version: "1.0"
+tasks:
+  version:
+    description: "Print the version"
+    command: "git describe"
+    type: oneshot
+  build:
+    description: "Build"
+    command: "go build -ldflags={{.version}}"
+    type: oneshot
+    timeout: 300
+    retries: 2
+    retry_delay: 5
+    parameters:
+      version:
+        type: string
+        required: true
+        description: "Version"
+  db:
+    description: "Database"
+    command: "postgres"
+    type: daemon
+  test:
+    description: "Test"
+    command: "go test ./..."
+    type: oneshot
+    requires_daemon: [db]
+workflows:
+  check:
+    description: "Checks"
+    steps:
+      - task: test
+        continue_on_failure: true
+  release:
+    description: "Cut a release"
+    parameters:
+      env:
+        type: string
+        required: true
+        description: "Target environment"
+    steps:
+      - task: version
+      - task: build
+        params:
+          version: "{{ steps.version.stdout }}"
+      - workflow: check
+        when: "{{ .steps.build.success }}"
+      - wait_for:
+          port: 8080
+          timeout: 10
+`
+
+func setupWorkflowTest(t *testing.T) {
+	t.Helper()
+	resetGlobals(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	globalConfig = filepath.Join(dir, "tasks.yaml")
+	if err := os.WriteFile(globalConfig, []byte(workflowManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkflowShow(t *testing.T) {
+	setupWorkflowTest(t)
+
+	var out bytes.Buffer
+	if code := cmdWorkflowShow(&out, "release"); code != 0 {
+		t.Fatalf("cmdWorkflowShow() = %d", code)
+	}
+	for _, want := range []string{
+		"Usage: runbook workflow run release [--param=value...]",
+		"--env",
+		"1. version  task version (oneshot)",
+		"2. build  task build (oneshot)",
+		"uses: version",
+		"--version={{ steps.version.stdout }}",
+		"timeout 300s, retries 2 (delay 5s)",
+		"3. check  workflow check",
+		"3.1. test  task test (oneshot)",
+		"requires daemon: db",
+		"continue on failure",
+		"4. wait_for  wait_for port 8080 (timeout 10s)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("workflow show output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWorkflowShowJSON(t *testing.T) {
+	setupWorkflowTest(t)
+	runOutput = outputJSON
+
+	var out bytes.Buffer
+	if code := cmdWorkflowShow(&out, "release"); code != 0 {
+		t.Fatalf("cmdWorkflowShow() = %d", code)
+	}
+	var graph workflowGraph
+	if err := json.Unmarshal(out.Bytes(), &graph); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(graph.Steps) != 4 || graph.Steps[2].Kind != "workflow" || len(graph.Steps[2].Steps) != 1 {
+		t.Fatalf("steps = %+v, want 4 with the nested workflow expanded", graph.Steps)
+	}
+	if uses := graph.Steps[2].Uses; len(uses) != 1 || uses[0] != "build" {
+		t.Errorf("when reference uses = %v, want [build]", uses)
+	}
+	if nested := graph.Steps[2].Steps[0]; nested.RequiresDaemon[0] != "db" {
+		t.Errorf("nested step = %+v, want the task's requires_daemon", nested)
+	}
+}
+
+func TestCheckWorkflow(t *testing.T) {
+	setupWorkflowTest(t)
+	manifest, _, err := config.LoadManifest(globalConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkWorkflow(manifest, "release"); err != nil {
+		t.Errorf("checkWorkflow(release) = %v", err)
+	}
+	if err := checkWorkflow(manifest, "build"); err == nil || !strings.Contains(err.Error(), "runbook run build") {
+		t.Errorf("checkWorkflow(build) = %v, want a pointer to run", err)
+	}
+	if err := checkWorkflow(manifest, "missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("checkWorkflow(missing) = %v, want not found", err)
+	}
+}
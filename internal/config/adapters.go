@@ -0,0 +1,329 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Adapter defaults.
+const (
+	DefaultMakefile    = "Makefile"
+	DefaultPackageJSON = "package.json"
+	defaultMakePrefix  = "make_"
+	defaultNPMPrefix   = "npm_"
+)
+
+// npmClients are the package managers adapters.npm.client may name.
+var npmClients = []string{"npm", "pnpm", "yarn", "bun"}
+
+// npmLifecycleScripts run on their own during install, pack, and publish,
+// so they are not imported as tasks.
+var npmLifecycleScripts = []string{
+	"preinstall", "install", "postinstall", "preuninstall", "uninstall", "postuninstall",
+	"prepublish", "preprepare", "prepare", "postprepare", "prepublishOnly",
+	"prepack", "postpack", "publish", "postpublish",
+}
+
+// makeRulePattern matches a Makefile rule line, capturing its targets and
+// the rest of the line. Variable assignments such as "A := b" do not match.
+var makeRulePattern = regexp.MustCompile(`^([^\s:#=][^:#=]*?)\s*::?([^=].*|)$`)
+
+// nonToolChars are replaced in imported task names, which become tool names.
+var nonToolChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// plainShellWord matches arguments that need no quoting in a shell command.
+var plainShellWord = regexp.MustCompile(`^[A-Za-z0-9_./:@+-]+$`)
+
+// mergeAdapters fills the adapters dst leaves unset from src.
+// The first manifest to configure an adapter wins.
+func mergeAdapters(dst *AdaptersConfig, src AdaptersConfig) {
+	if dst.Make == nil {
+		dst.Make = src.Make
+	}
+	if dst.NPM == nil {
+		dst.NPM = src.NPM
+	}
+}
+
+// applyAdapters adds a task for each Makefile target and package.json script
+// the manifest's adapters import, reading the files relative to root. Tasks
+// the manifest defines itself win over imported ones with the same name.
+func applyAdapters(manifest *Manifest, root string) error {
+	var imported map[string]Task
+	if adapter := manifest.Adapters.Make; adapter != nil {
+		tasks, err := makeTasks(*adapter, root)
+		if err != nil {
+			return fmt.Errorf("adapters.make: %w", err)
+		}
+		imported = addMissing(imported, tasks)
+	}
+	if adapter := manifest.Adapters.NPM; adapter != nil {
+		tasks, err := npmTasks(*adapter, root)
+		if err != nil {
+			return fmt.Errorf("adapters.npm: %w", err)
+		}
+		imported = addMissing(imported, tasks)
+	}
+	manifest.Tasks = addMissing(manifest.Tasks, imported)
+	return nil
+}
+
+// MakeTarget is a target read from a Makefile.
+type MakeTarget struct {
+	Name        string
+	Description string // From its "##" comment or the comment lines above it
+}
+
+// NPMScript is a package.json script that runs as a task of its own.
+type NPMScript struct {
+	Name    string
+	Command string
+}
+
+// makeTasks returns a task per target of the adapter's Makefile.
+func makeTasks(adapter AdapterConfig, root string) (map[string]Task, error) {
+	file := adapterFile(adapter, DefaultMakefile)
+	targets, err := ReadMakeTargets(filepath.Join(root, file))
+	if err != nil {
+		return nil, err
+	}
+
+	command := "make "
+	switch filepath.Base(file) {
+	case "GNUmakefile", "makefile", "Makefile":
+	default:
+		command = "make -f " + ShellWord(filepath.Base(file)) + " "
+	}
+
+	tasks := make(map[string]Task)
+	for _, target := range targets {
+		if !adapterSelects(adapter, target.Name) {
+			continue
+		}
+		description := target.Description
+		if description == "" {
+			description = "make " + target.Name
+		}
+		name := adapterTaskName(adapter, defaultMakePrefix, target.Name)
+		if _, exists := tasks[name]; exists {
+			continue
+		}
+		tasks[name] = Task{
+			Description:      description,
+			Command:          command + ShellWord(target.Name),
+			Type:             TaskTypeOneShot,
+			WorkingDirectory: adapterWorkingDirectory(file),
+		}
+	}
+	return tasks, nil
+}
+
+// ReadMakeTargets returns the targets of a Makefile in the order they appear.
+// A target is described by a "## text" comment after its prerequisites or, if
+// it has none, by the comment lines right above it. Special targets such as
+// .PHONY, pattern rules, and targets built from variables are skipped.
+func ReadMakeTargets(path string) ([]MakeTarget, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var targets []MakeTarget
+	seen := make(map[string]bool)
+	var comment []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(line, "#")))
+			continue
+		}
+		match := makeRulePattern.FindStringSubmatch(line)
+		if match == nil || strings.HasPrefix(line, "\t") || strings.HasPrefix(match[2], ":=") || strings.ContainsAny(match[1], "$()") {
+			comment = nil
+			continue
+		}
+
+		description := strings.Join(comment, " ")
+		if _, doc, ok := strings.Cut(match[2], "##"); ok {
+			description = strings.TrimSpace(doc)
+		}
+		comment = nil
+		for _, name := range strings.Fields(match[1]) {
+			if strings.HasPrefix(name, ".") || strings.Contains(name, "%") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			targets = append(targets, MakeTarget{Name: name, Description: description})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return targets, nil
+}
+
+// npmTasks returns a task per script of the adapter's package.json.
+func npmTasks(adapter AdapterConfig, root string) (map[string]Task, error) {
+	file := adapterFile(adapter, DefaultPackageJSON)
+	scripts, err := ReadNPMScripts(filepath.Join(root, file))
+	if err != nil {
+		return nil, err
+	}
+
+	client := adapter.Client
+	if client == "" {
+		client = "npm"
+	}
+	tasks := make(map[string]Task)
+	for _, script := range scripts {
+		if !adapterSelects(adapter, script.Name) {
+			continue
+		}
+		name := adapterTaskName(adapter, defaultNPMPrefix, script.Name)
+		if _, exists := tasks[name]; exists {
+			continue
+		}
+		tasks[name] = Task{
+			Description:      fmt.Sprintf("%s run %s: %s", client, script.Name, script.Command),
+			Command:          client + " run " + ShellWord(script.Name),
+			Type:             TaskTypeOneShot,
+			WorkingDirectory: adapterWorkingDirectory(file),
+		}
+	}
+	return tasks, nil
+}
+
+// ReadNPMScripts returns the scripts of a package.json sorted by name.
+// Lifecycle scripts and pre and post hooks, which the package manager runs
+// on its own, are skipped.
+func ReadNPMScripts(path string) ([]NPMScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var scripts []NPMScript
+	for _, name := range names {
+		if slices.Contains(npmLifecycleScripts, name) || isNPMHook(name, pkg.Scripts) {
+			continue
+		}
+		scripts = append(scripts, NPMScript{Name: name, Command: pkg.Scripts[name]})
+	}
+	return scripts, nil
+}
+
+// isNPMHook reports whether script is a pre or post hook of another script,
+// which the package manager runs along with it.
+func isNPMHook(script string, scripts map[string]string) bool {
+	for _, prefix := range []string{"pre", "post"} {
+		if base, ok := strings.CutPrefix(script, prefix); ok {
+			if _, exists := scripts[base]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// adapterFile returns the file an adapter reads.
+func adapterFile(adapter AdapterConfig, def string) string {
+	if adapter.File == "" {
+		return def
+	}
+	return adapter.File
+}
+
+// adapterWorkingDirectory returns the working directory of the tasks
+// imported from file: the directory it is in, or "" for the project root.
+func adapterWorkingDirectory(file string) string {
+	if dir := filepath.Dir(file); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// adapterSelects reports whether an adapter imports the target or script
+// name: it matches an include pattern (if there are any) and no exclude
+// pattern.
+func adapterSelects(adapter AdapterConfig, name string) bool {
+	for _, pattern := range adapter.Exclude {
+		if matchesPattern(pattern, name) {
+			return false
+		}
+	}
+	if len(adapter.Include) == 0 {
+		return true
+	}
+	for _, pattern := range adapter.Include {
+		if matchesPattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// adapterTaskName returns the task name of an imported target or script.
+func adapterTaskName(adapter AdapterConfig, defaultPrefix, name string) string {
+	prefix := defaultPrefix
+	if adapter.Prefix != nil {
+		prefix = *adapter.Prefix
+	}
+	return prefix + ToolSafeName(name)
+}
+
+// ToolSafeName returns name with the characters that are not valid in an
+// MCP tool name replaced by underscores.
+func ToolSafeName(name string) string {
+	return nonToolChars.ReplaceAllString(name, "_")
+}
+
+// ShellWord returns s as a single shell word, quoting it if needed.
+func ShellWord(s string) string {
+	if plainShellWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// validateAdapters checks the adapters section.
+func validateAdapters(adapters AdaptersConfig) []string {
+	var errors []string
+	for _, a := range []struct {
+		name    string
+		adapter *AdapterConfig
+	}{{"make", adapters.Make}, {"npm", adapters.NPM}} {
+		if a.adapter == nil {
+			continue
+		}
+		for _, pattern := range append(slices.Clone(a.adapter.Include), a.adapter.Exclude...) {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				errors = append(errors, fmt.Sprintf("adapters.%s: invalid pattern '%s'", a.name, pattern))
+			}
+		}
+		if a.adapter.Client != "" && (a.name != "npm" || !slices.Contains(npmClients, a.adapter.Client)) {
+			errors = append(errors, fmt.Sprintf("adapters.%s: invalid client '%s' (only adapters.npm takes a client: %s)", a.name, a.adapter.Client, strings.Join(npmClients, ", ")))
+		}
+	}
+	return errors
+}
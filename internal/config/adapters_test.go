@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+const testMakefile = `.PHONY: build test lint
+
+GO := go
+VERSION ::= 1.0
+
+# Build the binary
+build: deps ## Compile the server
+	$(GO) build ./...
+
+# Run the tests
+test:
+	$(GO) test ./...
+
+lint vet: ## Run the linters
+	golangci-lint run
+
+%.o: %.c
+	cc -c $<
+
+$(BIN): build
+
+docs/site:
+	mkdocs build
+`
+
+func TestReadMakeTargets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Makefile")
+	if err := os.WriteFile(path, []byte(testMakefile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	targets, err := ReadMakeTargets(path)
+	if err != nil {
+		t.Fatalf("ReadMakeTargets() error = %v", err)
+	}
+	want := []MakeTarget{
+		{"build", "Compile the server"},
+		{"test", "Run the tests"},
+		{"lint", "Run the linters"},
+		{"vet", "Run the linters"},
+		{"docs/site", ""},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("ReadMakeTargets() = %+v, want %+v", targets, want)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("target %d = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+}
+
+func TestLoaderAppliesAdapters(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("Makefile", testMakefile)
+	writeFile("web/package.json", `{
+  "scripts": {
+    "build": "tsc -p .",
+    "prebuild": "rm -rf dist",
+    "test:unit": "vitest",
+    "postinstall": "patch-package",
+    "dev": "vite"
+  }
+}`)
+	writeFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), `version: "1.0"
+adapters:
+  make:
+    exclude: [vet]
+  npm:
+    file: web/package.json
+    prefix: "web_"
+    client: pnpm
+tasks:
+  make_test:
+    description: "Tests with race detection"
+    command: "go test -race ./..."
+`)
+
+	manifest, _, err := LoadManifest("")
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+
+	build := manifest.Tasks["make_build"]
+	if build.Command != "make build" || build.Description != "Compile the server" || build.Type != TaskTypeOneShot {
+		t.Errorf("make_build = %+v", build)
+	}
+	if got := manifest.Tasks["make_test"].Command; got != "go test -race ./..." {
+		t.Errorf("make_test command = %q, want the manifest's own task", got)
+	}
+	if got := manifest.Tasks["make_docs_site"].Command; got != "make docs/site" {
+		t.Errorf("make_docs_site command = %q", got)
+	}
+	if _, ok := manifest.Tasks["make_vet"]; ok {
+		t.Error("excluded target 'vet' should not be imported")
+	}
+
+	unit := manifest.Tasks["web_test_unit"]
+	if unit.Command != "pnpm run test:unit" || unit.WorkingDirectory != "web" || !strings.Contains(unit.Description, "vitest") {
+		t.Errorf("web_test_unit = %+v", unit)
+	}
+	for _, skipped := range []string{"web_prebuild", "web_postinstall"} {
+		if _, ok := manifest.Tasks[skipped]; ok {
+			t.Errorf("%s should not be imported", skipped)
+		}
+	}
+	if _, ok := manifest.Tasks["web_dev"]; !ok {
+		t.Error("expected web_dev to be imported")
+	}
+}
+
+func TestAdaptersMissingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(dirs.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), []byte("version: \"1.0\"\nadapters:\n  npm: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := LoadManifest("")
+	if err == nil || !strings.Contains(err.Error(), "adapters.npm") {
+		t.Errorf("LoadManifest() error = %v, want an adapters.npm error", err)
+	}
+}
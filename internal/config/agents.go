@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// agentTagPattern is the form of the agent tags a runner may name.
+var agentTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// AgentTag returns the tag of the remote agents that run the task, or "" when
+// it runs on the server's host. Any runner other than shell and docker names
+// an agent tag.
+func (t Task) AgentTag() string {
+	switch t.Runner {
+	case "", RunnerShell, RunnerDocker:
+		return ""
+	}
+	return t.Runner
+}
+
+// ValidAgentTag reports whether tag may be used as an agent tag.
+func ValidAgentTag(tag string) bool {
+	return agentTagPattern.MatchString(tag)
+}
+
+// validateAgentRunner checks a task that runs on remote agents. The agent
+// runs the command alone, so features that need the server's filesystem or
+// terminal are rejected.
+func validateAgentRunner(name string, task Task) []string {
+	var errors []string
+	if !ValidAgentTag(task.Runner) {
+		return append(errors, fmt.Sprintf("task '%s': invalid runner '%s' (must be shell, docker, or an agent tag of lowercase letters, digits, '.', '_', and '-')", name, task.Runner))
+	}
+	if task.Type != TaskTypeOneShot {
+		errors = append(errors, fmt.Sprintf("task '%s': agent runners are only supported on oneshot tasks", name))
+	}
+	if task.Container != nil {
+		errors = append(errors, fmt.Sprintf("task '%s': container requires runner: docker", name))
+	}
+	if task.Interactive {
+		errors = append(errors, fmt.Sprintf("task '%s': interactive tasks cannot run on agents", name))
+	}
+	if len(task.Artifacts) > 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': artifacts are not supported on agent runners", name))
+	}
+	if len(task.Preconditions) > 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': preconditions are not supported on agent runners", name))
+	}
+	for _, pn := range sortedParamNames(task.Parameters) {
+		if task.Parameters[pn].Source != "" {
+			errors = append(errors, fmt.Sprintf("task '%s': parameter '%s': source is not supported on agent runners", name, pn))
+		}
+	}
+	return errors
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"text/template/parse"
+	"time"
+)
+
+// FilesHashKey is the name a cache key template reaches the hash of the
+// task's working tree under, as in {{.files_hash}}.
+const FilesHashKey = "files_hash"
+
+// Expiry returns how long a cached result is reused, or 0 when ttl is not
+// set and results are reused until the key changes.
+func (c TaskCache) Expiry() time.Duration {
+	d, err := time.ParseDuration(c.TTL)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// validateCache checks a task's cache block.
+func validateCache(name string, task Task) []string {
+	if task.Cache == nil {
+		return nil
+	}
+	var errors []string
+	if task.Type != TaskTypeOneShot || task.Interactive {
+		errors = append(errors, fmt.Sprintf("task '%s': cache is only supported on non-interactive oneshot tasks", name))
+	}
+	if task.Cache.Key == "" {
+		errors = append(errors, fmt.Sprintf("task '%s': cache.key is required", name))
+	} else {
+		tree := parse.New("cache")
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(task.Cache.Key, "", "", map[string]*parse.Tree{}); err != nil {
+			errors = append(errors, fmt.Sprintf("task '%s': cache.key: %v", name, err))
+		}
+	}
+	if task.Cache.TTL != "" && task.Cache.Expiry() == 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': cache.ttl must be a positive duration such as 30m or 1h, got '%s'", name, task.Cache.TTL))
+	}
+	return errors
+}
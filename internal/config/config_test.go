@@ -396,6 +396,1407 @@ func TestValidate(t *testing.T) {
 			},
 			wantError: false,
 		},
+		{
+			name: "requires_daemon on a daemon task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev":  {Description: "npm run dev", Command: "npm run dev", Type: TaskTypeDaemon, Ready: &ReadyCheck{Port: 3000}},
+					"e2e":  {Description: "npm run e2e", Command: "npm run e2e", Type: TaskTypeOneShot, RequiresDaemon: []string{"dev"}},
+					"lint": {Description: "npm run lint", Command: "npm run lint", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "requires_daemon referencing a oneshot",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "make", Command: "make", Type: TaskTypeOneShot},
+					"e2e":   {Description: "npm run e2e", Command: "npm run e2e", Type: TaskTypeOneShot, RequiresDaemon: []string{"build"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "required daemon 'build' is not a daemon task",
+		},
+		{
+			name: "requires_daemon on a daemon with a required parameter",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "dev", Command: "serve {{.port}}", Type: TaskTypeDaemon, Parameters: map[string]Param{"port": {Type: "string", Required: true}}},
+					"e2e": {Description: "npm run e2e", Command: "npm run e2e", Type: TaskTypeOneShot, RequiresDaemon: []string{"dev"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "required daemon 'dev' has required parameter 'port' without a default",
+		},
+		{
+			name: "requires_daemon cycle",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"api": {Description: "api", Command: "api", Type: TaskTypeDaemon, RequiresDaemon: []string{"db"}},
+					"db":  {Description: "db", Command: "db", Type: TaskTypeDaemon, RequiresDaemon: []string{"api"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "requires_daemon forms a cycle",
+		},
+		{
+			name: "allowed_working_directories without expose_working_directory",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, AllowedWorkingDirectories: []string{"services/*"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "allowed_working_directories requires expose_working_directory",
+		},
+		{
+			name: "invalid allowed_working_directories pattern",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, ExposeWorkingDirectory: true, AllowedWorkingDirectories: []string{"services/["}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid allowed_working_directories pattern 'services/['",
+		},
+		{
+			name: "negative max_concurrent_tasks",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{MaxConcurrentTasks: -1},
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: true,
+			errorMsg:  "defaults: max_concurrent_tasks cannot be negative",
+		},
+		{
+			name: "ready on a oneshot task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "make", Command: "make", Type: TaskTypeOneShot, Ready: &ReadyCheck{Port: 80}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "ready is only supported on daemon tasks",
+		},
+		{
+			name: "invalid ready log_pattern",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "dev", Command: "dev", Type: TaskTypeDaemon, Ready: &ReadyCheck{LogPattern: "("}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid ready log_pattern",
+		},
+		{
+			name: "workflow step requires missing daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"e2e": {Description: "npm run e2e", Command: "npm run e2e", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"ci": {Steps: []WorkflowStep{{Task: "e2e", RequiresDaemon: []string{"dev"}}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "required daemon 'dev' does not exist",
+		},
+		{
+			name: "latency budget disabled",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "make", Command: "make", Type: TaskTypeOneShot, LatencyBudget: -1},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid latency budget",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "make", Command: "make", Type: TaskTypeOneShot, LatencyBudget: -5},
+				},
+			},
+			wantError: true,
+			errorMsg:  "latency_budget must be -1 (disabled) or a number of seconds",
+		},
+		{
+			name: "compose task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"stack": {Description: "s", Type: TaskTypeCompose, Compose: &ComposeConfig{Services: []string{"db"}}},
+					"test":  {Description: "t", Command: "go test", Type: TaskTypeOneShot, RequiresDaemon: []string{"stack"}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "compose task with command",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"stack": {Description: "s", Command: "docker compose up", Type: TaskTypeCompose},
+				},
+			},
+			wantError: true,
+			errorMsg:  "command is not used by compose tasks",
+		},
+		{
+			name: "compose settings on a shell daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"stack": {Description: "s", Command: "docker compose up", Type: TaskTypeDaemon, Compose: &ComposeConfig{}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "compose is only supported on compose tasks",
+		},
+		{
+			name: "file_ops task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"setup": {Description: "s", Type: TaskTypeFileOps, Operations: []FileOp{
+						{Op: FileOpMkdir, Path: "build"},
+						{Op: FileOpTemplate, Src: "config.tmpl", Dest: "build/config.yaml", Mode: "0644"},
+					}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "negative step timeout",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"ci": {Description: "c", Steps: []WorkflowStep{{Task: "test", Timeout: -1}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "workflow 'ci': step 0: timeout cannot be negative",
+		},
+		{
+			name: "negative step retries",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, Retries: 2, RetryDelay: 5},
+				},
+				Workflows: map[string]Workflow{
+					"ci": {Description: "c", Steps: []WorkflowStep{{Task: "test", Retries: -1}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "retries and retry_delay cannot be negative",
+		},
+		{
+			name: "prompt includes defined partial",
+			manifest: &Manifest{
+				Version:        "1.0",
+				Tasks:          map[string]Task{},
+				PromptPartials: map[string]PromptPartial{"safety": {Content: "Never push to main."}},
+				Prompts:        map[string]Prompt{"review": {Description: "r", Content: `Review. {{partial "safety"}}`}},
+			},
+			wantError: false,
+		},
+		{
+			name: "prompt includes undefined partial",
+			manifest: &Manifest{
+				Version:        "1.0",
+				Tasks:          map[string]Task{},
+				PromptPartials: map[string]PromptPartial{"safety": {Content: "Never push to main."}},
+				Prompts:        map[string]Prompt{"review": {Description: "r", Content: `{{- partial "saftey" }}`}},
+			},
+			wantError: true,
+			errorMsg:  "prompt 'review': includes undefined partial 'saftey'",
+		},
+		{
+			name: "empty prompt partial",
+			manifest: &Manifest{
+				Version:        "1.0",
+				Tasks:          map[string]Task{},
+				PromptPartials: map[string]PromptPartial{"safety": {}},
+			},
+			wantError: true,
+			errorMsg:  "prompt partial 'safety': either content or file is required",
+		},
+		{
+			name: "invalid redact pattern",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"deploy": {Description: "d", Command: "./deploy.sh", Type: TaskTypeOneShot, Redact: []string{`token=(\w+`}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'deploy': invalid redact pattern",
+		},
+		{
+			name: "prompt with empty localized content",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Prompts: map[string]Prompt{"guide": {Description: "g", Content: "Hello", ContentByLocale: map[string]string{"de": ""}}},
+			},
+			wantError: true,
+			errorMsg:  "content_by_locale entries need a locale and content",
+		},
+		{
+			name: "invalid exec deny pattern",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Exec:    ExecConfig{Enabled: true, Deny: []string{`rm (-rf`}},
+			},
+			wantError: true,
+			errorMsg:  "exec: invalid deny pattern",
+		},
+		{
+			name: "testing fault with invalid rate",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Testing: TestingConfig{Faults: map[string]Fault{"build": {FailRate: 1.5}}},
+			},
+			wantError: true,
+			errorMsg:  "testing: fault 'build': fail_rate must be between 0 and 1",
+		},
+		{
+			name: "invalid env_policy mode",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Tasks:    map[string]Task{},
+				Defaults: Defaults{EnvPolicy: &EnvPolicy{Mode: "some"}},
+			},
+			wantError: true,
+			errorMsg:  "defaults: invalid env_policy mode 'some'",
+		},
+		{
+			name: "env_policy vars with mode none",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, EnvPolicy: &EnvPolicy{Mode: EnvPolicyNone, Vars: []string{"PATH"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'test': env_policy vars only apply to the allowlist and denylist modes",
+		},
+		{
+			name: "parameter alias used twice",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test {{.pkg}}", Type: TaskTypeOneShot, Parameters: map[string]Param{
+						"pkg":  {Type: "string", Description: "Package", Aliases: []string{"package"}},
+						"path": {Type: "string", Description: "Path", DeprecatedNames: []string{"package"}},
+					}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'test': parameter 'pkg' alias 'package' is already used by 'path'",
+		},
+		{
+			name: "parameter alias shadows a parameter",
+			manifest: &Manifest{
+				Version: "1.0",
+				Workflows: map[string]Workflow{
+					"ci": {Description: "c", Steps: []WorkflowStep{{Task: "test"}}, Parameters: map[string]Param{
+						"pkg":  {Type: "string", Description: "Package"},
+						"path": {Type: "string", Description: "Path", Aliases: []string{"pkg"}},
+					}},
+				},
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: true,
+			errorMsg:  "workflow 'ci': parameter 'path' alias 'pkg' is already used by 'pkg'",
+		},
+		{
+			name: "negative shutdown grace",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Server:  ServerConfig{ShutdownGrace: -5},
+			},
+			wantError: true,
+			errorMsg:  "server.shutdown_grace must be -1 (don't wait) or a number of seconds",
+		},
+		{
+			name: "unknown capability",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Server:  ServerConfig{Capabilities: []string{"tools", "sampling"}},
+			},
+			wantError: true,
+			errorMsg:  "server.capabilities: unknown capability 'sampling'",
+		},
+		{
+			name: "exit code classified twice",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"lint": {Description: "l", Command: "lint", Type: TaskTypeOneShot, ExpectedExitCodes: map[int]string{2: "warnings"}, WarningExitCodes: map[int]string{2: "warnings"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'lint': exit code 2 is in both expected_exit_codes and warning_exit_codes",
+		},
+		{
+			name: "exit code classification on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, ExpectedExitCodes: map[int]string{1: "stopped"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "expected_exit_codes and warning_exit_codes are only supported on oneshot tasks",
+		},
+		{
+			name: "artifacts on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, Artifacts: []string{"out.log"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'dev': artifacts are only supported on oneshot tasks",
+		},
+		{
+			name: "invalid artifact pattern",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, Artifacts: []string{"cover[.out"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'test': invalid artifact pattern 'cover[.out'",
+		},
+		{
+			name: "file_ops task without operations",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"setup": {Description: "s", Type: TaskTypeFileOps},
+				},
+			},
+			wantError: true,
+			errorMsg:  "file_ops tasks need at least one operation",
+		},
+		{
+			name: "file_ops invalid operation",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"setup": {Description: "s", Type: TaskTypeFileOps, Operations: []FileOp{{Op: "move", Path: "a"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid op 'move'",
+		},
+		{
+			name: "file_ops chmod with invalid mode",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"setup": {Description: "s", Type: TaskTypeFileOps, Operations: []FileOp{{Op: FileOpChmod, Path: "run.sh", Mode: "rwx"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid mode 'rwx'",
+		},
+		{
+			name: "interactive oneshot",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"psql": {Description: "p", Command: "psql", Type: TaskTypeOneShot, Interactive: true},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "interactive file_ops task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"setup": {Description: "s", Type: TaskTypeFileOps, Interactive: true, Operations: []FileOp{{Op: FileOpMkdir, Path: "build"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "interactive is only supported on daemon and oneshot tasks",
+		},
+		{
+			name: "interactive oneshot with runner docker",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"psql": {Description: "p", Command: "psql", Type: TaskTypeOneShot, Interactive: true, Runner: RunnerDocker, Container: &ContainerConfig{Image: "postgres"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "interactive oneshot tasks cannot use runner docker",
+		},
+		{
+			name: "interactive task as a workflow step",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"init": {Description: "i", Command: "npm init", Type: TaskTypeOneShot, Interactive: true},
+				},
+				Workflows: map[string]Workflow{
+					"setup": {Description: "Setup", Steps: []WorkflowStep{{Task: "init"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "references interactive task 'init', which needs a terminal",
+		},
+		{
+			name: "json output_format",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"report": {Description: "r", Command: "go test -json ./...", Type: TaskTypeOneShot, OutputFormat: OutputFormatJSON},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid var name",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Vars:    map[string]string{"image-name": "api"},
+			},
+			wantError: true,
+			errorMsg:  "vars: invalid name 'image-name'",
+		},
+		{
+			name: "parameter named Vars",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, Parameters: map[string]Param{"Vars": {Type: "string"}}},
+				},
+				Vars: map[string]string{"registry": `{{env "REGISTRY"}}`},
+			},
+			wantError: true,
+			errorMsg:  "parameter name 'Vars' is reserved for vars",
+		},
+		{
+			name: "reserved parameter name",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, Parameters: map[string]Param{"__session_id": {Type: "string", Description: "s"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "parameter name '__session_id' is reserved",
+		},
+		{
+			name: "invalid parameter pattern",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"deploy": {Description: "d", Command: "deploy", Type: TaskTypeOneShot, Parameters: map[string]Param{
+						"env": {Type: "string", Pattern: "(prod"},
+					}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "parameter 'env' has an invalid pattern",
+		},
+		{
+			name: "min on a string parameter",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"scale": {Description: "s", Command: "scale", Type: TaskTypeOneShot, Parameters: map[string]Param{
+						"replicas": {Type: "string", Min: new(float64)},
+					}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "min and max require type number or integer",
+		},
+		{
+			name: "default outside enum",
+			manifest: &Manifest{
+				Version: "1.0",
+				Workflows: map[string]Workflow{
+					"release": {Description: "r", Steps: []WorkflowStep{{Task: "deploy"}}, Parameters: map[string]Param{
+						"env": {Type: "string", Enum: []string{"staging", "prod"}, Default: ptr("dev")},
+					}},
+				},
+				Tasks: map[string]Task{
+					"deploy": {Description: "d", Command: "deploy", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: true,
+			errorMsg:  "parameter 'env' must be one of staging, prod, got 'dev'",
+		},
+		{
+			name: "invalid tool prefix",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{ToolPrefix: "my proj"},
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid tool_prefix 'my proj'",
+		},
+		{
+			name: "tool name used by another task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test":      {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+					"unit-test": {Description: "u", Command: "go test -short", Type: TaskTypeOneShot, ToolName: "test"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "tool name 'run_test' is already used by task 'test'",
+		},
+		{
+			name: "wait and wait_for steps",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"ci": {Description: "ci", Steps: []WorkflowStep{
+						{Wait: &StepWait{Seconds: 2}},
+						{WaitFor: &StepWaitFor{URL: "http://localhost:8080/health", Timeout: 60}},
+						{Task: "test"},
+					}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "wait_for without a condition",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"ci": {Description: "ci", Steps: []WorkflowStep{{WaitFor: &StepWaitFor{Timeout: 10}}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "wait_for needs a url, port, or file",
+		},
+		{
+			name: "wait step that also runs a task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"ci": {Description: "ci", Steps: []WorkflowStep{{Task: "test", Wait: &StepWait{Seconds: 1}}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "wait steps cannot also run a task or workflow",
+		},
+		{
+			name: "hints both read-only and destructive",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"clean": {Description: "c", Command: "rm -rf out", Type: TaskTypeOneShot, Hints: &ToolHints{ReadOnly: ptr(true), Destructive: ptr(true)}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "read_only and destructive cannot both be true",
+		},
+		{
+			name: "cache on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "go run .", Type: TaskTypeDaemon, Cache: &TaskCache{Key: "v1"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "cache is only supported on non-interactive oneshot tasks",
+		},
+		{
+			name: "cache with invalid ttl",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"lint": {Description: "l", Command: "golangci-lint run", Type: TaskTypeOneShot, Cache: &TaskCache{Key: "{{.files_hash}}", TTL: "soon"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "cache.ttl must be a positive duration",
+		},
+		{
+			name: "valid log_output truncated with caps",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, LogOutput: LogOutputTruncated, LogOutputMaxLines: 20, LogOutputMaxBytes: "16KB"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid log_output",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, LogOutput: "quiet"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid log_output 'quiet'",
+		},
+		{
+			name: "log_output caps without truncated",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, LogOutputMaxLines: 20},
+				},
+			},
+			wantError: true,
+			errorMsg:  "require log_output: truncated",
+		},
+		{
+			name: "log_output on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "serve", Type: TaskTypeDaemon, LogOutput: LogOutputNone},
+				},
+			},
+			wantError: true,
+			errorMsg:  "log_output is only supported on oneshot tasks",
+		},
+		{
+			name: "agent runner on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, Runner: "build-box"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "agent runners are only supported on oneshot tasks",
+		},
+		{
+			name: "invalid agent tag",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, Runner: "Build Box"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid runner 'Build Box'",
+		},
+		{
+			name: "agent runner on a oneshot",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, Runner: "build-box"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "ports on a oneshot",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, Ports: []int{3000}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "ports are only supported on daemon tasks",
+		},
+		{
+			name: "duplicate daemon port",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, Ports: []int{3000, 70000, 3000}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "port 3000 is listed twice",
+		},
+		{
+			name: "stop settings on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, StopSignal: "SIGINT", StopGracePeriod: 20},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid stop_signal",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, StopSignal: "KILL"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid stop_signal 'KILL'",
+		},
+		{
+			name: "stop_grace_period on a oneshot",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, StopGracePeriod: 10},
+				},
+			},
+			wantError: true,
+			errorMsg:  "stop_signal and stop_grace_period are only supported on daemon tasks",
+		},
+		{
+			name: "precondition with two checks",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"migrate": {Description: "m", Command: "make migrate", Type: TaskTypeOneShot, Preconditions: []Precondition{
+						{FileExists: ".env"},
+						{Command: "pg_isready", PortFree: 5432},
+					}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "preconditions[1] must set exactly one of command, file_exists, or port_free",
+		},
+		{
+			name: "preconditions on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, Preconditions: []Precondition{{PortFree: 3000}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "preconditions are only supported on oneshot and file_ops tasks",
+		},
+		{
+			name: "unknown output_format",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"report": {Description: "r", Command: "make report", Type: TaskTypeOneShot, OutputFormat: "yaml"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid output_format 'yaml'",
+		},
+		{
+			name: "output_format on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "npm run dev", Type: TaskTypeDaemon, OutputFormat: OutputFormatJSON},
+				},
+			},
+			wantError: true,
+			errorMsg:  "output_format is only supported on oneshot tasks",
+		},
+		{
+			name: "json output_format on an interactive task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"ask": {Description: "a", Command: "./ask.sh", Type: TaskTypeOneShot, Interactive: true, OutputFormat: OutputFormatJSON},
+				},
+			},
+			wantError: true,
+			errorMsg:  "output_format json needs captured output",
+		},
+		{
+			name: "on_crash with a webhook and task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"api":  {Description: "a", Command: "./api", Type: TaskTypeDaemon, OnCrash: &CrashNotify{Webhook: "https://hooks.example.com/x", Task: "page"}},
+					"page": {Description: "p", Command: "./page.sh", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "on_crash on a oneshot task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "b", Command: "make", Type: TaskTypeOneShot, OnCrash: &CrashNotify{Desktop: true}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "on_crash is only supported on daemon tasks",
+		},
+		{
+			name: "async on a daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "Dev", Command: "npm run dev", Type: TaskTypeDaemon, Async: true},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'dev': async is only supported on non-interactive oneshot tasks",
+		},
+		{
+			name: "valid rate_limit",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"deploy": {Description: "Deploy", Command: "./deploy.sh", Type: TaskTypeOneShot, RateLimit: &RateLimit{MaxRuns: 2, Per: "1h"}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "rate_limit without max_runs",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"deploy": {Description: "Deploy", Command: "./deploy.sh", Type: TaskTypeOneShot, RateLimit: &RateLimit{Per: "1h"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'deploy': rate_limit.max_runs must be at least 1",
+		},
+		{
+			name: "rate_limit with an invalid per",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"deploy": {Description: "Deploy", Command: "./deploy.sh", Type: TaskTypeOneShot, RateLimit: &RateLimit{MaxRuns: 1, Per: "hourly"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "rate_limit.per must be a positive duration",
+		},
+		{
+			name: "strict_security fails on unquoted parameters",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{StrictSecurity: true},
+				Tasks: map[string]Task{
+					"checkout": {
+						Description: "Checkout",
+						Command:     "git checkout {{.branch}}",
+						Type:        TaskTypeOneShot,
+						Parameters:  map[string]Param{"branch": {Type: "string", Description: "Branch"}},
+					},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'checkout': parameter 'branch' is interpolated unquoted in command",
+		},
+		{
+			name: "invalid adapters client",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Adapters: AdaptersConfig{NPM: &AdapterConfig{Client: "npx"}},
+			},
+			wantError: true,
+			errorMsg:  "adapters.npm: invalid client 'npx'",
+		},
+		{
+			name: "valid reload_signal",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"proxy": {Description: "Proxy", Command: "nginx", Type: TaskTypeDaemon, Reload: true, ReloadSignal: "SIGUSR2"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "reload on a oneshot task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "Build", Command: "make", Type: TaskTypeOneShot, Reload: true},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'build': reload is only supported on daemon tasks",
+		},
+		{
+			name: "invalid reload_signal",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"proxy": {Description: "Proxy", Command: "nginx", Type: TaskTypeDaemon, Reload: true, ReloadSignal: "KILL"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task 'proxy': invalid reload_signal 'KILL'",
+		},
+		{
+			name: "reload_signal without reload",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"proxy": {Description: "Proxy", Command: "nginx", Type: TaskTypeDaemon, ReloadSignal: "HUP"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "reload_signal requires reload: true",
+		},
+		{
+			name: "on_crash without a channel",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{OnCrash: &CrashNotify{}},
+			},
+			wantError: true,
+			errorMsg:  "defaults: on_crash requires desktop, webhook, or task",
+		},
+		{
+			name: "on_crash with an invalid webhook",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"api": {Description: "a", Command: "./api", Type: TaskTypeDaemon, OnCrash: &CrashNotify{Webhook: "hooks.example.com"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "on_crash.webhook must be an http(s) URL",
+		},
+		{
+			name: "on_crash task that is a daemon",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{OnCrash: &CrashNotify{Task: "api"}},
+				Tasks: map[string]Task{
+					"api": {Description: "a", Command: "./api", Type: TaskTypeDaemon},
+				},
+			},
+			wantError: true,
+			errorMsg:  "on_crash.task 'api' must be a oneshot task",
+		},
+		{
+			name: "on_crash task that does not exist",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{OnCrash: &CrashNotify{Task: "page"}},
+			},
+			wantError: true,
+			errorMsg:  "on_crash.task 'page' does not exist",
+		},
+		{
+			name: "negative stop_grace",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{StopGrace: -1},
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "serve", Type: TaskTypeDaemon},
+				},
+			},
+			wantError: true,
+			errorMsg:  "stop_grace cannot be negative",
+		},
+		{
+			name: "session sink",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{SessionSink: &SessionSink{File: "sessions.jsonl", URL: "https://example.com/sessions", TokenEnv: "SINK_TOKEN"}},
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "session sink without destination",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{SessionSink: &SessionSink{}},
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: true,
+			errorMsg:  "session_sink: requires file or url",
+		},
+		{
+			name: "session sink with invalid url",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{SessionSink: &SessionSink{URL: "example.com"}},
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+			},
+			wantError: true,
+			errorMsg:  "url must be an http(s) URL",
+		},
+		{
+			name: "daemon log rotation",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "serve", Type: TaskTypeDaemon, LogMaxSize: "10MB", LogMaxFiles: 3},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid log_max_size",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "serve", Type: TaskTypeDaemon, LogMaxSize: "ten megs"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "log_max_size: invalid size",
+		},
+		{
+			name: "log_max_files without log_max_size",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "d", Command: "serve", Type: TaskTypeDaemon, LogMaxFiles: 3},
+				},
+			},
+			wantError: true,
+			errorMsg:  "log_max_files requires log_max_size",
+		},
+		{
+			name: "docker runner",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, Runner: RunnerDocker, Container: &ContainerConfig{Image: "golang", Mounts: []string{"./cache:/cache"}}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "docker runner without image",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, Runner: RunnerDocker},
+				},
+			},
+			wantError: true,
+			errorMsg:  "runner docker requires container.image",
+		},
+		{
+			name: "docker runner on daemon",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"db": {Description: "d", Command: "postgres", Type: TaskTypeDaemon, Runner: RunnerDocker, Container: &ContainerConfig{Image: "postgres"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "runner docker is only supported on oneshot tasks",
+		},
+		{
+			name: "invalid container mount",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, Runner: RunnerDocker, Container: &ContainerConfig{Image: "golang", Mounts: []string{"/data"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid container mount '/data'",
+		},
+		{
+			name: "container without docker runner",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot, Container: &ContainerConfig{Image: "golang"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "container requires runner: docker",
+		},
+		{
+			name: "negative max timeout",
+			manifest: &Manifest{
+				Version:  "1.0",
+				Defaults: Defaults{MaxTimeout: -1},
+				Tasks:    map[string]Task{},
+			},
+			wantError: true,
+			errorMsg:  "defaults: max_timeout cannot be negative",
+		},
+		{
+			name: "step output reference to a later step",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"version": {Description: "v", Command: "echo 1", Type: TaskTypeOneShot},
+					"tag":     {Description: "t", Command: "echo {{.v}}", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"release": {
+						Description: "Release",
+						Steps: []WorkflowStep{
+							{Task: "tag", Params: map[string]string{"v": "{{ steps.version.stdout }}"}},
+							{Task: "version"},
+						},
+					},
+				},
+			},
+			wantError: true,
+			errorMsg:  "references step 'version', which does not run before it",
+		},
+		{
+			name: "when references a later step",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test":   {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+					"deploy": {Description: "d", Command: "deploy", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"release": {
+						Description: "Release",
+						Steps: []WorkflowStep{
+							{Task: "deploy", When: "{{ .steps.test.success }}"},
+							{Task: "test"},
+						},
+					},
+				},
+			},
+			wantError: true,
+			errorMsg:  "when references step 'test', which does not run before it",
+		},
+		{
+			name: "fresh step without required daemons",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"test": {Description: "t", Command: "go test", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"ci": {
+						Description: "CI",
+						Steps:       []WorkflowStep{{Task: "test", Fresh: true}},
+					},
+				},
+			},
+			wantError: true,
+			errorMsg:  "fresh requires daemons to restart",
+		},
+		{
+			name: "invalid when expression",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"deploy": {Description: "d", Command: "deploy", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"release": {
+						Description: "Release",
+						Steps:       []WorkflowStep{{Task: "deploy", When: "{{ eq .env "}},
+					},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid when expression",
+		},
+		{
+			name: "workflow step running another workflow",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"unit":   {Description: "u", Command: "go test", Type: TaskTypeOneShot},
+					"deploy": {Description: "d", Command: "deploy", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"smoke_tests": {Description: "Smoke", Steps: []WorkflowStep{{Task: "unit"}}},
+					"release": {
+						Description: "Release",
+						Steps: []WorkflowStep{
+							{Workflow: "smoke_tests"},
+							{Task: "deploy", When: "{{ .steps.smoke_tests.success }}"},
+						},
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "workflow steps forming a cycle",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"unit": {Description: "u", Command: "go test", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"a": {Description: "A", Steps: []WorkflowStep{{Task: "unit"}, {Workflow: "b"}}},
+					"b": {Description: "B", Steps: []WorkflowStep{{Workflow: "a"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "workflow steps form a cycle",
+		},
+		{
+			name: "workflow step referencing a missing workflow",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Workflows: map[string]Workflow{
+					"ci": {Description: "CI", Steps: []WorkflowStep{{Workflow: "smoke_tests"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "references non-existent workflow 'smoke_tests'",
+		},
+		{
+			name: "step with both task and workflow",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"unit": {Description: "u", Command: "go test", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"smoke_tests": {Description: "Smoke", Steps: []WorkflowStep{{Task: "unit"}}},
+					"ci":          {Description: "CI", Steps: []WorkflowStep{{Task: "unit", Workflow: "smoke_tests"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "task and workflow are mutually exclusive",
+		},
+		{
+			name: "duplicate step id",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"version": {Description: "v", Command: "echo 1", Type: TaskTypeOneShot},
+				},
+				Workflows: map[string]Workflow{
+					"release": {
+						Description: "Release",
+						Steps: []WorkflowStep{
+							{ID: "v", Task: "version"},
+							{ID: "v", Task: "version"},
+						},
+					},
+				},
+			},
+			wantError: true,
+			errorMsg:  "duplicate step id 'v'",
+		},
+		{
+			name: "session lifetime on oneshot task",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"build": {Description: "make", Command: "make", Type: TaskTypeOneShot, Lifetime: LifetimeSession},
+				},
+			},
+			wantError: true,
+			errorMsg:  "lifetime is only supported on daemon tasks",
+		},
+		{
+			name: "invalid lifetime",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks: map[string]Task{
+					"dev": {Description: "dev", Command: "serve", Type: TaskTypeDaemon, Lifetime: "forever"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid lifetime 'forever'",
+		},
+		{
+			name: "mtls auth without client CA",
+			manifest: &Manifest{
+				Version: "1.0",
+				Server: ServerConfig{
+					Auth: AuthConfig{Type: AuthTypeMTLS},
+					TLS:  TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "mtls requires server.tls.client_ca_file",
+		},
+		{
+			name: "tls cert without key",
+			manifest: &Manifest{
+				Version: "1.0",
+				Server:  ServerConfig{TLS: TLSConfig{CertFile: "cert.pem"}},
+			},
+			wantError: true,
+			errorMsg:  "cert_file and key_file must be set together",
+		},
+		{
+			name: "webhook auth without url",
+			manifest: &Manifest{
+				Version: "1.0",
+				Server:  ServerConfig{Auth: AuthConfig{Type: AuthTypeWebhook}},
+			},
+			wantError: true,
+			errorMsg:  "url must be an http(s) URL",
+		},
+		{
+			name: "unknown auth type",
+			manifest: &Manifest{
+				Version: "1.0",
+				Server:  ServerConfig{Auth: AuthConfig{Type: "oauth"}},
+			},
+			wantError: true,
+			errorMsg:  "invalid type 'oauth'",
+		},
+		{
+			name: "command auth",
+			manifest: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Server:  ServerConfig{Auth: AuthConfig{Type: AuthTypeCommand, Command: "./check-token"}},
+			},
+			wantError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -991,6 +2392,68 @@ prompts:
 				}
 			},
 		},
+		{
+			name:     "prompts_dir and resources_dir",
+			mainFile: "main.yaml",
+			files: map[string]string{
+				"main.yaml": `version: "1.0"
+imports:
+  - "./docs/docs.yaml"
+prompts_dir: prompts
+tasks:
+  test:
+    description: "Run tests"
+    command: "go test"
+prompts:
+  review:
+    description: "Defined in the manifest"
+    content: "Review it"
+`,
+				"prompts/onboard.md": "---\ndescription: Onboard a new contributor\n---\n\nRead {{.Vars.readme}} first.\n",
+				"prompts/triage.md":  "# Triage failing CI\n\nLook at the logs.\n",
+				"prompts/review.md":  "---\ndescription: From the directory\n---\nIgnored\n",
+				"prompts/notes.txt":  "not markdown",
+				"docs/docs.yaml":     "version: \"1.0\"\nresources_dir: ./guides\n",
+				"docs/guides/api.md": "---\ndescription: API guide\nmime_type: text/plain\n---\nGET /health\n",
+				"docs/guides/old.md": "---\ndescription: Old guide\ndisabled: true\n---\nGone\n",
+			},
+			wantError: false,
+			validate: func(t *testing.T, m *Manifest) {
+				if len(m.Prompts) != 3 {
+					t.Errorf("expected 3 prompts, got %v", m.Prompts)
+				}
+				if p := m.Prompts["onboard"]; p.Description != "Onboard a new contributor" || p.Content != "Read {{.Vars.readme}} first.\n" {
+					t.Errorf("onboard prompt = %+v, want its front matter description and body", p)
+				}
+				if p := m.Prompts["triage"]; p.Description != "Triage failing CI" {
+					t.Errorf("triage prompt description = %q, want its first heading", p.Description)
+				}
+				if p := m.Prompts["review"]; p.Content != "Review it" {
+					t.Errorf("review prompt = %+v, want the manifest's definition", p)
+				}
+				if r := m.Resources["api"]; r.Description != "API guide" || r.MIMEType != "text/plain" || r.Content != "GET /health\n" {
+					t.Errorf("api resource = %+v, want it loaded relative to the imported file", r)
+				}
+				if !m.Resources["old"].Disabled {
+					t.Error("expected front matter disabled to disable the resource")
+				}
+			},
+		},
+		{
+			name:     "missing prompts_dir",
+			mainFile: "main.yaml",
+			files: map[string]string{
+				"main.yaml": `version: "1.0"
+prompts_dir: nowhere
+tasks:
+  test:
+    description: "Run tests"
+    command: "go test"
+`,
+			},
+			wantError: true,
+			errorMsg:  "prompts_dir",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1098,6 +2561,56 @@ func TestMergeManifests(t *testing.T) {
 			wantError: true,
 			errorMsg:  "duplicate task name 'test'",
 		},
+		{
+			name: "imported manifest cannot enable exec",
+			base: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Exec:    ExecConfig{Timeout: 30},
+			},
+			imports: []*Manifest{
+				{Exec: ExecConfig{Enabled: true, Timeout: 90, Allow: []string{"*"}}},
+			},
+			validate: func(t *testing.T, m *Manifest) {
+				if m.Exec.Enabled || m.Exec.Timeout != 30 || len(m.Exec.Allow) != 0 {
+					t.Errorf("expected the base exec settings only, got %+v", m.Exec)
+				}
+			},
+		},
+		{
+			name: "duplicate task template names",
+			base: &Manifest{
+				Version:       "1.0",
+				Tasks:         map[string]Task{},
+				TaskTemplates: map[string]Task{"go": {Shell: "/bin/bash"}},
+			},
+			imports: []*Manifest{
+				{TaskTemplates: map[string]Task{"go": {Shell: "/bin/sh"}}},
+			},
+			wantError: true,
+			errorMsg:  "duplicate task template name 'go'",
+		},
+		{
+			name: "imported manifest cannot change server settings",
+			base: &Manifest{
+				Version: "1.0",
+				Tasks:   map[string]Task{},
+				Server:  ServerConfig{Name: "acme"},
+			},
+			imports: []*Manifest{
+				{Server: ServerConfig{
+					Name:           "other",
+					Instructions:   "Use run_test.",
+					AllowTaskEdits: true,
+					Auth:           AuthConfig{Type: "command", Command: "true"},
+				}},
+			},
+			validate: func(t *testing.T, m *Manifest) {
+				if m.Server.Name != "acme" || m.Server.Instructions != "" || m.Server.AllowTaskEdits || m.Server.Auth.Type != "" {
+					t.Errorf("expected the base server settings only, got %+v", m.Server)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1126,3 +2639,113 @@ func TestMergeManifests(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadManifestTestingFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectConfig(t, dir, `version: "1.0"
+testing:
+  enabled: true
+  seed: 7
+  faults:
+    build:
+      fail_rate: 0.5
+tasks:
+  build:
+    description: "Build"
+    command: "make"
+`)
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, dir)
+
+	manifest, loaded, err := LoadManifest("")
+	if err != nil || !loaded {
+		t.Fatalf("LoadManifest: loaded=%v err=%v", loaded, err)
+	}
+	if !manifest.Testing.Enabled || manifest.Testing.Seed != 7 || manifest.Testing.Faults["build"].FailRate != 0.5 {
+		t.Errorf("testing config not loaded from %s/: %+v", dirs.ConfigDir, manifest.Testing)
+	}
+}
+
+func TestLoadManifestExecFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectConfig(t, dir, `version: "1.0"
+imports:
+  - shared/tools.yaml
+exec:
+  timeout: 20
+tasks:
+  build:
+    description: "Build"
+    command: "make"
+`)
+	shared := filepath.Join(dir, dirs.ConfigDir, "shared")
+	if err := os.MkdirAll(shared, 0755); err != nil {
+		t.Fatal(err)
+	}
+	imported := `version: "1.0"
+exec:
+  enabled: true
+server:
+  allow_task_edits: true
+tasks:
+  lint:
+    description: "Lint"
+    command: "golangci-lint run"
+`
+	if err := os.WriteFile(filepath.Join(shared, "tools.yaml"), []byte(imported), 0644); err != nil {
+		t.Fatal(err)
+	}
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, dir)
+
+	manifest, loaded, err := LoadManifest("")
+	if err != nil || !loaded {
+		t.Fatalf("LoadManifest: loaded=%v err=%v", loaded, err)
+	}
+	if _, ok := manifest.Tasks["lint"]; !ok {
+		t.Fatal("expected the imported task")
+	}
+	if manifest.Exec.Enabled || manifest.Exec.Timeout != 20 {
+		t.Errorf("an import enabled exec: %+v", manifest.Exec)
+	}
+	if manifest.Server.AllowTaskEdits {
+		t.Error("an import allowed task edits")
+	}
+}
+
+func TestLoadManifestDefaultsFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeProjectConfig(t, dir, `version: "1.0"
+defaults:
+  timeout: 77
+  env_policy:
+    mode: allowlist
+    vars: [PATH, "GO*"]
+tasks:
+  build:
+    description: "Build"
+    command: "make"
+  lint:
+    description: "Lint"
+    command: "golangci-lint run"
+    env_policy:
+      mode: none
+`)
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, dir)
+
+	manifest, loaded, err := LoadManifest("")
+	if err != nil || !loaded {
+		t.Fatalf("LoadManifest: loaded=%v err=%v", loaded, err)
+	}
+	build := manifest.Tasks["build"]
+	if build.Timeout != 77 || build.EnvPolicy == nil || build.EnvPolicy.Mode != EnvPolicyAllowlist {
+		t.Errorf("defaults not applied from %s/: timeout=%d env_policy=%+v", dirs.ConfigDir, build.Timeout, build.EnvPolicy)
+	}
+	if lint := manifest.Tasks["lint"]; lint.EnvPolicy == nil || lint.EnvPolicy.Mode != EnvPolicyNone {
+		t.Errorf("expected the task's env_policy to replace the default, got %+v", lint.EnvPolicy)
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// markdownExtensions are the file extensions prompts_dir and resources_dir
+// load.
+var markdownExtensions = []string{".md", ".markdown"}
+
+// contentFrontMatter is the YAML front matter a markdown file in prompts_dir
+// or resources_dir may start with.
+type contentFrontMatter struct {
+	Description string `yaml:"description"`
+	MIMEType    string `yaml:"mime_type"` // Resources only (default text/markdown)
+	Disabled    bool   `yaml:"disabled"`
+}
+
+// contentFile is a markdown file loaded from prompts_dir or resources_dir.
+type contentFile struct {
+	Name        string
+	FrontMatter contentFrontMatter
+	Body        string
+}
+
+// loadContentDirs adds a prompt for every markdown file in the manifest's
+// prompts_dir and a resource for every one in its resources_dir. Both are
+// relative to baseDir, the manifest file's directory. Each is named after
+// its file name without the extension, and described by the description in
+// its front matter, or else its first heading. Prompts and resources the
+// manifest defines by name keep their definition.
+func loadContentDirs(manifest *Manifest, baseDir string) error {
+	if manifest.PromptsDir != "" {
+		files, err := readContentDir(baseDir, manifest.PromptsDir)
+		if err != nil {
+			return fmt.Errorf("prompts_dir: %w", err)
+		}
+		if manifest.Prompts == nil {
+			manifest.Prompts = make(map[string]Prompt)
+		}
+		for _, f := range files {
+			if _, defined := manifest.Prompts[f.Name]; defined {
+				continue
+			}
+			manifest.Prompts[f.Name] = Prompt{
+				Description: f.FrontMatter.Description,
+				Content:     f.Body,
+				Disabled:    f.FrontMatter.Disabled,
+			}
+		}
+	}
+	if manifest.ResourcesDir != "" {
+		files, err := readContentDir(baseDir, manifest.ResourcesDir)
+		if err != nil {
+			return fmt.Errorf("resources_dir: %w", err)
+		}
+		if manifest.Resources == nil {
+			manifest.Resources = make(map[string]Resource)
+		}
+		for _, f := range files {
+			if _, defined := manifest.Resources[f.Name]; defined {
+				continue
+			}
+			manifest.Resources[f.Name] = Resource{
+				Description: f.FrontMatter.Description,
+				Content:     f.Body,
+				MIMEType:    f.FrontMatter.MIMEType,
+				Disabled:    f.FrontMatter.Disabled,
+			}
+		}
+	}
+	return nil
+}
+
+// readContentDir reads the markdown files directly in dir, sorted by name.
+func readContentDir(baseDir, dir string) ([]contentFile, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []contentFile
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !containsFold(markdownExtensions, ext) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parseContentFile(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		file.Name = strings.TrimSuffix(entry.Name(), ext)
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// parseContentFile splits a markdown file into its front matter, between
+// "---" lines at the start of the file, and the text after it. Without a
+// description in the front matter, the first heading describes the file.
+func parseContentFile(text string) (contentFile, error) {
+	var file contentFile
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	file.Body = text
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		front, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			front, found = strings.CutSuffix(rest, "\n---")
+		}
+		if found {
+			if err := yaml.Unmarshal([]byte(front), &file.FrontMatter); err != nil {
+				return file, fmt.Errorf("invalid front matter: %w", err)
+			}
+			file.Body = strings.TrimLeft(body, "\n")
+		}
+	}
+	if file.FrontMatter.Description == "" {
+		for _, line := range strings.Split(file.Body, "\n") {
+			if heading, ok := strings.CutPrefix(line, "# "); ok {
+				file.FrontMatter.Description = strings.TrimSpace(heading)
+				break
+			}
+		}
+	}
+	return file, nil
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
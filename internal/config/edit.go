@@ -0,0 +1,283 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"runbookmcp.dev/internal/dirs"
+)
+
+// TaskNamePattern restricts the names of tasks added with EditTask, so the
+// generated tool names stay valid.
+var TaskNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Task edit operations.
+const (
+	TaskEditAdd    = "add"
+	TaskEditUpdate = "update"
+	TaskEditRemove = "remove"
+)
+
+// ErrTaskNotFound is wrapped by the error EditTask returns for a task that
+// no config file defines.
+var ErrTaskNotFound = errors.New("not found")
+
+// EditTask adds, updates, or removes a task in the manifest files at
+// configPath (a file or directory; "" is ./.runbook/) and returns the file it
+// changed. Fields are task fields by their YAML keys; for update, they replace
+// the task's existing values and a nil value removes the field. The file is
+// rewritten from its parsed YAML, keeping comments, and the whole config is
+// loaded again afterwards: if it no longer loads, the file is restored and
+// the error returned. Tasks from imports cannot be edited.
+func EditTask(configPath, op, name string, fields map[string]interface{}) (string, error) {
+	files, err := manifestFiles(configPath)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no config files found (create one with the init tool first)")
+	}
+
+	var path string
+	var doc yaml.Node
+	for _, f := range files {
+		var d yaml.Node
+		if err := readYAML(f, &d); err != nil {
+			return "", err
+		}
+		if tasks := mappingValue(rootMapping(&d), "tasks"); tasks != nil && mappingValue(tasks, name) != nil {
+			path, doc = f, d
+			break
+		}
+	}
+
+	switch op {
+	case TaskEditAdd:
+		if path != "" {
+			return "", fmt.Errorf("task '%s' already exists in %s", name, path)
+		}
+		if !TaskNamePattern.MatchString(name) {
+			return "", fmt.Errorf("invalid task name '%s' (use letters, digits, '-', and '_')", name)
+		}
+		path = defaultTaskFile(files)
+		if err := readYAML(path, &doc); err != nil {
+			return "", err
+		}
+	case TaskEditUpdate, TaskEditRemove:
+		if path == "" {
+			return "", fmt.Errorf("task '%s' %w in %s", name, ErrTaskNotFound, strings.Join(files, ", "))
+		}
+	default:
+		return "", fmt.Errorf("unknown task edit '%s'", op)
+	}
+
+	root := rootMapping(&doc)
+	if root == nil {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+		root = doc.Content[0]
+	}
+	tasks := mappingValue(root, "tasks")
+	if tasks == nil || tasks.Kind != yaml.MappingNode {
+		tasks = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(root, "tasks", tasks)
+	}
+
+	switch op {
+	case TaskEditAdd:
+		def := &yaml.Node{Kind: yaml.MappingNode}
+		if err := setFields(def, fields); err != nil {
+			return "", err
+		}
+		setMappingValue(tasks, name, def)
+	case TaskEditUpdate:
+		def := mappingValue(tasks, name)
+		if def.Kind != yaml.MappingNode {
+			return "", fmt.Errorf("task '%s' in %s is not a mapping", name, path)
+		}
+		if err := setFields(def, fields); err != nil {
+			return "", err
+		}
+	case TaskEditRemove:
+		deleteMappingValue(tasks, name)
+	}
+
+	if op != TaskEditRemove {
+		if err := checkTaskFields(mappingValue(tasks, name)); err != nil {
+			return "", fmt.Errorf("invalid task '%s': %w", name, err)
+		}
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if _, _, err := LoadManifest(configPath); err != nil {
+		if original != nil {
+			_ = os.WriteFile(path, original, 0644)
+		} else {
+			_ = os.Remove(path)
+		}
+		return "", fmt.Errorf("edit rejected, config would not load: %w", err)
+	}
+	return path, nil
+}
+
+// manifestFiles returns the top-level manifest files at configPath, the way
+// LoadManifest finds them.
+func manifestFiles(configPath string) ([]string, error) {
+	if configPath == "" {
+		configPath = "./" + dirs.ConfigDir
+	}
+	info, err := os.Stat(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", configPath, err)
+	}
+	if !info.IsDir() {
+		return []string{configPath}, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(configPath, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// defaultTaskFile picks the file new tasks are added to: tasks.yaml if there
+// is one, else the first file.
+func defaultTaskFile(files []string) string {
+	for _, f := range files {
+		if filepath.Base(f) == "tasks.yaml" {
+			return f
+		}
+	}
+	return files[0]
+}
+
+// readYAML parses the YAML file at path into doc. A missing or empty file
+// leaves doc empty.
+func readYAML(path string, doc *yaml.Node) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// rootMapping returns the top-level mapping of a parsed document, or nil.
+func rootMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// mappingValue returns the value of key in mapping, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of key in mapping, keeping the key's
+// comments, or appends the key.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// deleteMappingValue removes key from mapping.
+func deleteMappingValue(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// leadingTaskFields are written before other new fields, as in hand-written
+// configs.
+var leadingTaskFields = []string{"description", "command", "type"}
+
+// setFields sets each field in def, new ones in leadingTaskFields and then
+// sorted order; nil values remove the field.
+func setFields(def *yaml.Node, fields map[string]interface{}) error {
+	var keys, rest []string
+	for _, key := range leadingTaskFields {
+		if _, ok := fields[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	for key := range fields {
+		if !slices.Contains(leadingTaskFields, key) {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+	for _, key := range keys {
+		if fields[key] == nil {
+			deleteMappingValue(def, key)
+			continue
+		}
+		var value yaml.Node
+		if err := value.Encode(fields[key]); err != nil {
+			return fmt.Errorf("invalid value for '%s': %w", key, err)
+		}
+		setMappingValue(def, key, &value)
+	}
+	return nil
+}
+
+// checkTaskFields reports unknown fields and values of the wrong type in a
+// task definition.
+func checkTaskFields(def *yaml.Node) error {
+	data, err := yaml.Marshal(def)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var task Task
+	return dec.Decode(&task)
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+const editManifest = `version: "1.0"
+# Build tasks
+tasks:
+  build:
+    description: "Build the project" # keep me
+    command: "make"
+    type: oneshot
+`
+
+func setupEditTest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeProjectConfig(t, dir, editManifest)
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, dir)
+	return filepath.Join(dirs.ConfigDir, "tasks.yaml")
+}
+
+func TestEditTask(t *testing.T) {
+	path := setupEditTest(t)
+
+	file, err := EditTask("", TaskEditAdd, "lint", map[string]interface{}{
+		"description": "Lint",
+		"command":     "golangci-lint run",
+		"type":        "oneshot",
+		"timeout":     float64(120),
+	})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if file != filepath.Join(".", path) && file != path {
+		t.Errorf("expected %s to be edited, got %s", path, file)
+	}
+
+	if _, err := EditTask("", TaskEditUpdate, "build", map[string]interface{}{"command": "make all", "type": nil}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	manifest, _, err := LoadManifest("")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if lint := manifest.Tasks["lint"]; lint.Command != "golangci-lint run" || lint.Timeout != 120 {
+		t.Errorf("unexpected lint task: %+v", lint)
+	}
+	if build := manifest.Tasks["build"]; build.Command != "make all" || build.Type != TaskTypeOneShot {
+		t.Errorf("unexpected build task: %+v", build)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comment := range []string{"# Build tasks", "# keep me"} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("comment %q was lost:\n%s", comment, data)
+		}
+	}
+
+	if _, err := EditTask("", TaskEditRemove, "lint", nil); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	manifest, _, _ = LoadManifest("")
+	if _, ok := manifest.Tasks["lint"]; ok {
+		t.Error("expected lint to be removed")
+	}
+}
+
+func TestEditTaskRejectsInvalid(t *testing.T) {
+	path := setupEditTest(t)
+	before, _ := os.ReadFile(path)
+
+	tests := []struct {
+		name    string
+		op      string
+		task    string
+		fields  map[string]interface{}
+		wantErr string
+	}{
+		{"duplicate", TaskEditAdd, "build", map[string]interface{}{"command": "x"}, "already exists"},
+		{"bad name", TaskEditAdd, "run tests", map[string]interface{}{"command": "x"}, "invalid task name"},
+		{"unknown field", TaskEditAdd, "lint", map[string]interface{}{"command": "x", "comand": "y"}, "field comand not found"},
+		{"fails validation", TaskEditAdd, "lint", map[string]interface{}{"description": "Lint", "command": "x", "type": "weekly"}, "config would not load"},
+		{"missing task", TaskEditUpdate, "deploy", map[string]interface{}{"command": "x"}, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EditTask("", tt.op, tt.task, tt.fields)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			after, _ := os.ReadFile(path)
+			if string(after) != string(before) {
+				t.Errorf("rejected edit changed the file:\n%s", after)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Restricts reports whether the policy keeps any host variable from being
+// inherited. A nil policy does not.
+func (p *EnvPolicy) Restricts() bool {
+	return p != nil && p.Mode != "" && p.Mode != EnvPolicyAll
+}
+
+// Filter returns the entries of environ ("KEY=value") the policy lets a
+// process inherit. A nil policy inherits everything.
+func (p *EnvPolicy) Filter(environ []string) []string {
+	if !p.Restricts() {
+		return environ
+	}
+	result := make([]string, 0, len(environ))
+	for _, entry := range environ {
+		key, _, _ := strings.Cut(entry, "=")
+		switch p.Mode {
+		case EnvPolicyAllowlist:
+			if p.matches(key) {
+				result = append(result, entry)
+			}
+		case EnvPolicyDenylist:
+			if !p.matches(key) {
+				result = append(result, entry)
+			}
+		}
+	}
+	return result
+}
+
+// matches reports whether key is one of the policy's variables.
+func (p *EnvPolicy) matches(key string) bool {
+	for _, pattern := range p.Vars {
+		if ok, _ := filepath.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnvPolicyFilter(t *testing.T) {
+	environ := []string{"PATH=/bin", "HOME=/root", "AWS_SECRET_ACCESS_KEY=x", "AWS_REGION=us-east-1", "GOPATH=/go"}
+	tests := []struct {
+		name   string
+		policy *EnvPolicy
+		want   []string
+	}{
+		{"nil inherits all", nil, environ},
+		{"all", &EnvPolicy{Mode: EnvPolicyAll}, environ},
+		{"allowlist", &EnvPolicy{Mode: EnvPolicyAllowlist, Vars: []string{"PATH", "GO*"}}, []string{"PATH=/bin", "GOPATH=/go"}},
+		{"denylist", &EnvPolicy{Mode: EnvPolicyDenylist, Vars: []string{"AWS_*"}}, []string{"PATH=/bin", "HOME=/root", "GOPATH=/go"}},
+		{"none", &EnvPolicy{Mode: EnvPolicyNone}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Filter(environ); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
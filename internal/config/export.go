@@ -0,0 +1,470 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Export formats of runbook export --format.
+const (
+	ExportGitHubActions = "github-actions"
+	ExportMakefile      = "makefile"
+)
+
+// simpleParamRef matches a bare parameter reference such as {{.env}} or
+// {{ .env }}, the only template action an exported command can translate.
+var simpleParamRef = regexp.MustCompile(`\{\{-?\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*-?\}\}`)
+
+// exportShells are the task shells an exported command can run under.
+var exportShells = []string{"", "bash", "/bin/bash", "sh", "/bin/sh"}
+
+// ExportTargets returns the tasks and workflows runbook export converts when
+// none are named: every enabled oneshot task and workflow, sorted by name.
+func ExportTargets(manifest *Manifest) []string {
+	var names []string
+	for name, task := range manifest.Tasks {
+		if !task.Disabled && task.Type == TaskTypeOneShot {
+			names = append(names, name)
+		}
+	}
+	for name, wf := range manifest.Workflows {
+		if !wf.Disabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkExportTargets reports names that are not enabled oneshot tasks or
+// workflows.
+func checkExportTargets(manifest *Manifest, names []string) error {
+	for _, name := range names {
+		if wf, ok := manifest.Workflows[name]; ok && !wf.Disabled {
+			continue
+		}
+		task, ok := manifest.Tasks[name]
+		if !ok || task.Disabled {
+			return fmt.Errorf("task or workflow '%s' not found", name)
+		}
+		if task.Type != TaskTypeOneShot {
+			return fmt.Errorf("task '%s' is a %s task; only oneshot tasks and workflows can be exported", name, task.Type)
+		}
+	}
+	return nil
+}
+
+// translateCommand replaces the parameter references in a command template
+// with ref(name). It reports false when the template uses anything but bare
+// parameter references, which the export cannot reproduce.
+func translateCommand(command string, ref func(name string) string) (string, bool) {
+	if strings.Contains(simpleParamRef.ReplaceAllString(command, ""), "{{") {
+		return "", false
+	}
+	return simpleParamRef.ReplaceAllStringFunc(command, func(m string) string {
+		return ref(simpleParamRef.FindStringSubmatch(m)[1])
+	}), true
+}
+
+// inlineCommand returns the command of a task with its parameters replaced
+// by ref(name), or false when the task needs runbook to run it.
+func inlineCommand(task Task, ref func(name string) string) (string, bool) {
+	if task.Type != TaskTypeOneShot || task.Interactive || len(task.Preconditions) > 0 ||
+		(task.Runner != "" && task.Runner != RunnerShell) || !slices.Contains(exportShells, task.Shell) {
+		return "", false
+	}
+	for _, p := range task.Parameters {
+		if p.Source != "" {
+			return "", false
+		}
+	}
+	return translateCommand(task.Command, ref)
+}
+
+// runbookCommand is the command that runs a task or workflow through runbook
+// with each of params set to ref(name).
+func runbookCommand(name string, params map[string]Param, ref func(name string) string) string {
+	args := []string{"runbook", "run", name}
+	for _, p := range sortedParamNames(params) {
+		args = append(args, fmt.Sprintf(`--%s="%s"`, p, ref(p)))
+	}
+	return strings.Join(args, " ")
+}
+
+// sortedParamNames returns the names of params, sorted.
+func sortedParamNames(params map[string]Param) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exportableSteps reports whether every step of a workflow can be expressed
+// without runbook: plain task steps whose parameters only reference the
+// workflow's own parameters.
+func exportableSteps(manifest *Manifest, wf Workflow) bool {
+	for _, step := range wf.Steps {
+		if step.Builtin() || step.Workflow != "" || step.Project != "" || step.When != "" || step.Fresh ||
+			step.Retries > 0 || len(step.RequiresDaemon) > 0 {
+			return false
+		}
+		task, ok := manifest.Tasks[step.Task]
+		if !ok || task.Retries > 0 || len(task.RequiresDaemon) > 0 {
+			return false
+		}
+		for _, value := range step.Params {
+			if _, ok := translateCommand(value, func(string) string { return "" }); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// stepParam returns the value a workflow step passes to a task parameter,
+// with workflow parameter references replaced by ref(name). Parameters the
+// step leaves out get the task's default.
+func stepParam(step WorkflowStep, task Task, name string, ref func(name string) string) string {
+	if value, ok := step.Params[name]; ok {
+		out, _ := translateCommand(value, ref)
+		return out
+	}
+	if def := task.Parameters[name].Default; def != nil {
+		return *def
+	}
+	return ""
+}
+
+// ExportMakefileText converts tasks and workflows into a Makefile. Oneshot
+// tasks become targets that run their command, with parameters as make
+// variables and depends_on as prerequisites; workflows become targets that
+// make each step. Anything make cannot reproduce, such as parameter sources
+// or templates beyond {{.name}}, runs through runbook run instead. names
+// selects what to export (default: ExportTargets).
+func ExportMakefileText(manifest *Manifest, names []string) (string, error) {
+	if len(names) == 0 {
+		names = ExportTargets(manifest)
+	}
+	if err := checkExportTargets(manifest, names); err != nil {
+		return "", err
+	}
+
+	names = makeClosure(manifest, names)
+	var b strings.Builder
+	b.WriteString("# Generated by runbook export --format makefile. Do not edit;\n")
+	b.WriteString("# change the runbook config and export again.\n\n")
+	b.WriteString("SHELL := /bin/bash\n.ONESHELL:\n\n")
+	fmt.Fprintf(&b, ".PHONY: %s\n", strings.Join(names, " "))
+
+	for _, name := range names {
+		b.WriteString("\n")
+		if wf, ok := manifest.Workflows[name]; ok && !wf.Disabled {
+			writeMakeComment(&b, wf.Description)
+			writeMakeDefaults(&b, name, wf.Parameters)
+			fmt.Fprintf(&b, "%s:\n", name)
+			if !exportableSteps(manifest, wf) {
+				fmt.Fprintf(&b, "\t%s\n", makeLine(runbookCommand(name, wf.Parameters, makeRef)))
+				continue
+			}
+			for _, step := range wf.Steps {
+				task := manifest.Tasks[step.Task]
+				args := []string{step.Task}
+				for _, p := range sortedParamNames(task.Parameters) {
+					args = append(args, fmt.Sprintf(`%s="%s"`, p, stepParam(step, task, p, makeRef)))
+				}
+				onFailure := "exit"
+				if step.ContinueOnFailure {
+					onFailure = "true"
+				}
+				fmt.Fprintf(&b, "\t$(MAKE) %s || %s\n", makeLine(strings.Join(args, " ")), onFailure)
+			}
+			continue
+		}
+
+		task := manifest.Tasks[name]
+		writeMakeComment(&b, task.Description)
+		writeMakeDefaults(&b, name, task.Parameters)
+		fmt.Fprintf(&b, "%s:%s\n", name, prefixEach(task.DependsOn, " "))
+		command, ok := inlineCommand(task, makeRef)
+		if !ok {
+			fmt.Fprintf(&b, "\t%s\n", makeLine(runbookCommand(name, task.Parameters, makeRef)))
+			continue
+		}
+		for _, p := range sortedParamNames(task.Parameters) {
+			if task.Parameters[p].Required && task.Parameters[p].Default == nil {
+				fmt.Fprintf(&b, "\t[ -n \"$(%s)\" ] || { echo \"%s: %s is required\" >&2; exit 1; }\n", p, name, p)
+			}
+		}
+		if task.WorkingDirectory != "" {
+			fmt.Fprintf(&b, "\tcd %s || exit\n", task.WorkingDirectory)
+		}
+		for _, key := range sortedKeysOf(task.Env) {
+			fmt.Fprintf(&b, "\texport %s=%s\n", key, escapeMake(shellQuote(task.Env[key])))
+		}
+		for _, line := range strings.Split(makeLine(strings.TrimRight(command, "\n")), "\n") {
+			fmt.Fprintf(&b, "\t%s\n", line)
+		}
+	}
+	return b.String(), nil
+}
+
+// makeRef marks a parameter reference in text bound for a Makefile; makeLine
+// turns it into a make variable after escaping the rest of the text.
+func makeRef(name string) string {
+	return "\x00" + name + "\x00"
+}
+
+// makeVarMarker matches the references makeRef leaves in text.
+var makeVarMarker = regexp.MustCompile("\x00([A-Za-z0-9_]+)\x00")
+
+// makeLine escapes dollar signs in s so make passes them to the shell, and
+// turns parameter references into make variables.
+func makeLine(s string) string {
+	return makeVarMarker.ReplaceAllString(escapeMake(s), "$$($1)")
+}
+
+// makeClosure adds to names the tasks they need as make targets: the
+// depends_on of tasks and the steps of workflows, in first-seen order.
+func makeClosure(manifest *Manifest, names []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	var add func(name string)
+	add = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		out = append(out, name)
+		if wf, ok := manifest.Workflows[name]; ok && !wf.Disabled {
+			if exportableSteps(manifest, wf) {
+				for _, step := range wf.Steps {
+					add(step.Task)
+				}
+			}
+			return
+		}
+		for _, dep := range manifest.Tasks[name].DependsOn {
+			add(dep)
+		}
+	}
+	for _, name := range names {
+		add(name)
+	}
+	return out
+}
+
+// writeMakeComment writes a description as a comment above a target.
+func writeMakeComment(b *strings.Builder, description string) {
+	for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		if line != "" {
+			fmt.Fprintf(b, "# %s\n", line)
+		}
+	}
+}
+
+// writeMakeDefaults writes target-specific defaults for parameters, which
+// make's command line overrides.
+func writeMakeDefaults(b *strings.Builder, target string, params map[string]Param) {
+	for _, p := range sortedParamNames(params) {
+		if def := params[p].Default; def != nil {
+			fmt.Fprintf(b, "%s: %s ?= %s\n", target, p, escapeMake(*def))
+		}
+	}
+}
+
+// escapeMake escapes dollar signs so make passes them to the shell.
+func escapeMake(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// prefixEach joins items, putting prefix before each one.
+func prefixEach(items []string, prefix string) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(prefix + item)
+	}
+	return b.String()
+}
+
+// sortedKeysOf returns the keys of m, sorted.
+func sortedKeysOf(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ghWorkflow is a GitHub Actions workflow file.
+type ghWorkflow struct {
+	Name string           `yaml:"name"`
+	On   ghTriggers       `yaml:"on"`
+	Jobs map[string]ghJob `yaml:"jobs"`
+}
+
+type ghTriggers struct {
+	Push             struct{}   `yaml:"push"`
+	PullRequest      struct{}   `yaml:"pull_request"`
+	WorkflowDispatch ghDispatch `yaml:"workflow_dispatch"`
+}
+
+type ghDispatch struct {
+	Inputs map[string]ghInput `yaml:"inputs,omitempty"`
+}
+
+type ghInput struct {
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required"`
+	Default     string `yaml:"default,omitempty"`
+}
+
+type ghJob struct {
+	Name           string   `yaml:"name,omitempty"`
+	RunsOn         string   `yaml:"runs-on"`
+	TimeoutMinutes int      `yaml:"timeout-minutes,omitempty"`
+	Steps          []ghStep `yaml:"steps"`
+}
+
+type ghStep struct {
+	Name             string            `yaml:"name,omitempty"`
+	Uses             string            `yaml:"uses,omitempty"`
+	WorkingDirectory string            `yaml:"working-directory,omitempty"`
+	Env              map[string]string `yaml:"env,omitempty"`
+	Shell            string            `yaml:"shell,omitempty"`
+	Run              string            `yaml:"run,omitempty"`
+	ContinueOnError  bool              `yaml:"continue-on-error,omitempty"`
+	TimeoutMinutes   int               `yaml:"timeout-minutes,omitempty"`
+}
+
+// ExportGitHubActionsText converts tasks and workflows into a GitHub Actions
+// workflow that runs on push, pull requests, and manual dispatch. Each task
+// or workflow becomes a job; parameters become workflow_dispatch inputs that
+// fall back to their defaults. Steps that cannot be inlined run through
+// runbook run, which must then be installed on the runner. names selects
+// what to export (default: ExportTargets).
+func ExportGitHubActionsText(manifest *Manifest, names []string) (string, error) {
+	if len(names) == 0 {
+		names = ExportTargets(manifest)
+	}
+	if err := checkExportTargets(manifest, names); err != nil {
+		return "", err
+	}
+
+	doc := ghWorkflow{Name: "runbook", Jobs: make(map[string]ghJob)}
+	inputs := make(map[string]ghInput)
+	addInputs := func(params map[string]Param) func(string) string {
+		for name, p := range params {
+			if _, seen := inputs[name]; !seen {
+				inputs[name] = ghInput{Description: p.Description}
+			}
+		}
+		return func(name string) string {
+			if def := params[name].Default; def != nil {
+				return fmt.Sprintf("${{ inputs.%s || '%s' }}", name, strings.ReplaceAll(*def, "'", "''"))
+			}
+			return fmt.Sprintf("${{ inputs.%s }}", name)
+		}
+	}
+
+	for _, name := range names {
+		job := ghJob{RunsOn: "ubuntu-latest", Steps: []ghStep{{Uses: "actions/checkout@v4"}}}
+		if wf, ok := manifest.Workflows[name]; ok && !wf.Disabled {
+			job.Name = wf.Description
+			job.TimeoutMinutes = minutes(wf.Timeout)
+			ref := addInputs(wf.Parameters)
+			if !exportableSteps(manifest, wf) {
+				job.Steps = append(job.Steps, ghStep{Name: name, WorkingDirectory: wf.WorkingDirectory, Run: runbookCommand(name, wf.Parameters, ref)})
+				doc.Jobs[name] = job
+				continue
+			}
+			for _, step := range wf.Steps {
+				task := manifest.Tasks[step.Task]
+				params := make(map[string]interface{}, len(task.Parameters))
+				for p := range task.Parameters {
+					params[p] = stepParam(step, task, p, ref)
+				}
+				s := ghTaskStep(step.Task, task, func(p string) string { return params[p].(string) })
+				if s.WorkingDirectory == "" {
+					s.WorkingDirectory = wf.WorkingDirectory
+				}
+				s.ContinueOnError = step.ContinueOnFailure
+				if step.Timeout > 0 {
+					s.TimeoutMinutes = minutes(step.Timeout)
+				}
+				job.Steps = append(job.Steps, s)
+			}
+			doc.Jobs[name] = job
+			continue
+		}
+
+		task := manifest.Tasks[name]
+		job.Name = task.Description
+		ref := addInputs(task.Parameters)
+		for _, dep := range task.DependsOn {
+			depTask := manifest.Tasks[dep]
+			job.Steps = append(job.Steps, ghTaskStep(dep, depTask, addInputs(depTask.Parameters)))
+		}
+		job.Steps = append(job.Steps, ghTaskStep(name, task, ref))
+		doc.Jobs[name] = job
+	}
+	doc.On.WorkflowDispatch.Inputs = inputs
+
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by runbook export --format github-actions. Do not edit;\n")
+	buf.WriteString("# change the runbook config and export again.\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ghTaskStep is the step that runs a task, with its parameters set to
+// ref(name).
+func ghTaskStep(name string, task Task, ref func(name string) string) ghStep {
+	step := ghStep{Name: name, WorkingDirectory: task.WorkingDirectory, TimeoutMinutes: minutes(task.Timeout)}
+	command, ok := inlineCommand(task, ref)
+	if !ok {
+		step.Run = runbookCommand(name, task.Parameters, ref)
+		return step
+	}
+	// Trailing spaces would keep the script out of a YAML literal block
+	lines := strings.Split(strings.TrimRight(command, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	step.Run = strings.Join(lines, "\n")
+	step.Env = task.Env
+	if task.Shell == "sh" || task.Shell == "/bin/sh" {
+		step.Shell = "sh"
+	} else {
+		step.Shell = "bash"
+	}
+	return step
+}
+
+// minutes converts a timeout in seconds to whole minutes, rounding up.
+func minutes(seconds int) int {
+	if seconds <= 0 {
+		return 0
+	}
+	return (seconds + 59) / 60
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func exportManifest() *Manifest {
+	dot, test := ".", "Test"
+	return &Manifest{
+		Version: "1.0",
+		Tasks: map[string]Task{
+			"lint": {Description: "Lint the code", Type: TaskTypeOneShot, Command: "golangci-lint run"},
+			"test": {
+				Description: "Run tests",
+				Type:        TaskTypeOneShot,
+				Command:     "go test -run '{{ .run }}' ./...\necho \"cache $HOME\"\n",
+				Env:         map[string]string{"CGO_ENABLED": "0"},
+				Timeout:     300,
+				DependsOn:   []string{"lint"},
+				Parameters:  map[string]Param{"run": {Description: "Test pattern", Default: &dot}},
+			},
+			"deploy": {
+				Description: "Deploy",
+				Type:        TaskTypeOneShot,
+				Command:     "./deploy.sh {{.env}}{{if .dry}} --dry-run{{end}}",
+				Parameters:  map[string]Param{"env": {Description: "Environment", Required: true}},
+			},
+			"dev": {Description: "Dev server", Type: TaskTypeDaemon, Command: "npm run dev"},
+		},
+		Workflows: map[string]Workflow{
+			"ci": {
+				Description: "CI pipeline",
+				Parameters:  map[string]Param{"pattern": {Description: "Test pattern", Default: &test}},
+				Steps: []WorkflowStep{
+					{Task: "lint", ContinueOnFailure: true},
+					{Task: "test", Params: map[string]string{"run": "{{.pattern}}"}},
+				},
+			},
+		},
+	}
+}
+
+func TestExportMakefile(t *testing.T) {
+	out, err := ExportMakefileText(exportManifest(), nil)
+	if err != nil {
+		t.Fatalf("ExportMakefileText() error = %v", err)
+	}
+	for _, want := range []string{
+		".PHONY: ci lint test deploy\n",
+		"ci: pattern ?= Test\nci:\n\t$(MAKE) lint || true\n\t$(MAKE) test run=\"$(pattern)\" || exit\n",
+		"# Run tests\ntest: run ?= .\ntest: lint\n\texport CGO_ENABLED='0'\n\tgo test -run '$(run)' ./...\n\techo \"cache $$HOME\"\n",
+		"deploy:\n\trunbook run deploy --env=\"$(env)\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Makefile missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "dev:") {
+		t.Errorf("daemons should not be exported:\n%s", out)
+	}
+
+	// Named targets bring along the targets they make
+	out, err = ExportMakefileText(exportManifest(), []string{"test"})
+	if err != nil {
+		t.Fatalf("ExportMakefileText() error = %v", err)
+	}
+	if !strings.Contains(out, ".PHONY: test lint\n") || strings.Contains(out, "deploy") {
+		t.Errorf("expected only test and lint:\n%s", out)
+	}
+
+	if _, err := ExportMakefileText(exportManifest(), []string{"dev"}); err == nil || !strings.Contains(err.Error(), "daemon") {
+		t.Errorf("expected an error exporting a daemon, got %v", err)
+	}
+}
+
+func TestExportGitHubActions(t *testing.T) {
+	out, err := ExportGitHubActionsText(exportManifest(), []string{"ci", "deploy"})
+	if err != nil {
+		t.Fatalf("ExportGitHubActionsText() error = %v", err)
+	}
+
+	var doc struct {
+		On struct {
+			WorkflowDispatch struct {
+				Inputs map[string]map[string]interface{} `yaml:"inputs"`
+			} `yaml:"workflow_dispatch"`
+		} `yaml:"on"`
+		Jobs map[string]struct {
+			Steps []map[string]interface{} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, out)
+	}
+	if _, ok := doc.On.WorkflowDispatch.Inputs["pattern"]; !ok {
+		t.Errorf("expected a pattern input, got %v", doc.On.WorkflowDispatch.Inputs)
+	}
+
+	ci := doc.Jobs["ci"].Steps
+	if len(ci) != 3 {
+		t.Fatalf("ci steps = %v, want checkout, lint, test", ci)
+	}
+	if ci[1]["continue-on-error"] != true {
+		t.Errorf("lint step = %v, want continue-on-error", ci[1])
+	}
+	if want := "go test -run '${{ inputs.pattern || 'Test' }}' ./...\necho \"cache $HOME\""; ci[2]["run"] != want {
+		t.Errorf("test step run = %q, want %q", ci[2]["run"], want)
+	}
+	if ci[2]["timeout-minutes"] != 5 {
+		t.Errorf("test step timeout = %v, want 5", ci[2]["timeout-minutes"])
+	}
+
+	deploy := doc.Jobs["deploy"].Steps
+	if got := deploy[len(deploy)-1]["run"]; got != `runbook run deploy --env="${{ inputs.env }}"` {
+		t.Errorf("deploy step run = %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import "fmt"
+
+// validateHints checks that a task's hints do not contradict each other.
+func validateHints(name string, hints *ToolHints) []string {
+	if hints == nil {
+		return nil
+	}
+	if isTrue(hints.ReadOnly) && isTrue(hints.Destructive) {
+		return []string{fmt.Sprintf("task '%s': hints: read_only and destructive cannot both be true", name)}
+	}
+	return nil
+}
+
+// WorkflowHints returns the hints of a workflow's run_ tool, from those of
+// the tasks its steps run, including in nested workflows. It is read-only
+// or idempotent when every task is, and destructive when any task is. It
+// returns nil when no task sets hints. wait and wait_for steps change
+// nothing and are ignored.
+func WorkflowHints(workflow Workflow, tasks map[string]Task, workflows map[string]Workflow) *ToolHints {
+	var hints []*ToolHints
+	collectStepHints(workflow, tasks, workflows, &hints)
+
+	readOnly, idempotent, safe, anySet := true, true, true, false
+	destructive := false
+	for _, h := range hints {
+		if h == nil {
+			readOnly, idempotent, safe = false, false, false
+			continue
+		}
+		anySet = true
+		readOnly = readOnly && isTrue(h.ReadOnly)
+		idempotent = idempotent && isTrue(h.Idempotent)
+		destructive = destructive || isTrue(h.Destructive)
+		safe = safe && (isTrue(h.ReadOnly) || (h.Destructive != nil && !*h.Destructive))
+	}
+	if !anySet {
+		return nil
+	}
+
+	result := &ToolHints{}
+	if readOnly {
+		result.ReadOnly = &readOnly
+	}
+	if destructive || safe {
+		result.Destructive = &destructive
+	}
+	if idempotent {
+		result.Idempotent = &idempotent
+	}
+	return result
+}
+
+// collectStepHints appends the hints of each task workflow's steps run,
+// nil for tasks without hints.
+func collectStepHints(workflow Workflow, tasks map[string]Task, workflows map[string]Workflow, hints *[]*ToolHints) {
+	for _, step := range workflow.Steps {
+		switch {
+		case step.Builtin():
+		case step.Workflow != "":
+			collectStepHints(workflows[step.Workflow], tasks, workflows, hints)
+		default:
+			*hints = append(*hints, tasks[step.Task].Hints)
+		}
+	}
+}
+
+// isTrue reports whether b is set and true.
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,55 @@
+package config
+
+import "testing"
+
+func TestWorkflowHints(t *testing.T) {
+	yes, no := true, false
+	tasks := map[string]Task{
+		"lint":    {Hints: &ToolHints{ReadOnly: &yes, Idempotent: &yes}},
+		"test":    {Hints: &ToolHints{ReadOnly: &yes, Idempotent: &yes}},
+		"build":   {Hints: &ToolHints{Destructive: &no, Idempotent: &yes}},
+		"drop_db": {Hints: &ToolHints{Destructive: &yes}},
+		"plain":   {},
+	}
+	workflows := map[string]Workflow{
+		"checks": {Steps: []WorkflowStep{{Task: "lint"}, {Wait: &StepWait{Seconds: 1}}, {Task: "test"}}},
+	}
+
+	tests := []struct {
+		name        string
+		steps       []WorkflowStep
+		want        *ToolHints
+		readOnly    bool
+		destructive *bool
+		idempotent  bool
+	}{
+		{name: "no hints", steps: []WorkflowStep{{Task: "plain"}}},
+		{name: "all read-only", steps: []WorkflowStep{{Workflow: "checks"}}, want: &ToolHints{}, readOnly: true, destructive: &no, idempotent: true},
+		{name: "read-only and not destructive", steps: []WorkflowStep{{Task: "lint"}, {Task: "build"}}, want: &ToolHints{}, destructive: &no, idempotent: true},
+		{name: "any destructive", steps: []WorkflowStep{{Task: "lint"}, {Task: "drop_db"}}, want: &ToolHints{}, destructive: &yes},
+		{name: "unhinted step", steps: []WorkflowStep{{Task: "lint"}, {Task: "plain"}}, want: &ToolHints{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WorkflowHints(Workflow{Steps: tt.steps}, tasks, workflows)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("WorkflowHints = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("WorkflowHints = nil")
+			}
+			if isTrue(got.ReadOnly) != tt.readOnly {
+				t.Errorf("read_only = %v, want %v", got.ReadOnly, tt.readOnly)
+			}
+			if (got.Destructive == nil) != (tt.destructive == nil) || (got.Destructive != nil && *got.Destructive != *tt.destructive) {
+				t.Errorf("destructive = %v, want %v", got.Destructive, tt.destructive)
+			}
+			if isTrue(got.Idempotent) != tt.idempotent {
+				t.Errorf("idempotent = %v, want %v", got.Idempotent, tt.idempotent)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// ProjectSeparator joins a hosted project's name and its task and workflow
+// names, e.g. "api__test", which the server exposes as run_api__test.
+const ProjectSeparator = "__"
+
+// projectNamePattern restricts hosted project names to characters that are
+// valid in MCP tool names.
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// ProjectTaskName returns the namespaced name of a task or workflow from a
+// hosted project.
+func ProjectTaskName(project, name string) string {
+	return project + ProjectSeparator + name
+}
+
+// addServerProjects adds every project listed in server.projects to the
+// manifest. Paths are resolved relative to the current working directory.
+func addServerProjects(manifest *Manifest) error {
+	names := make([]string, 0, len(manifest.Server.Projects))
+	for name := range manifest.Server.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := AddProject(manifest, name, manifest.Server.Projects[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddProject loads the .runbook/ config of the project in dir and adds its
+// tasks, workflows, and task groups to manifest under names prefixed with
+// the project name. Tasks run in the project's directory by default.
+// Prompts and resources of hosted projects are not added.
+func AddProject(manifest *Manifest, name, dir string) error {
+	if !projectNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid project name '%s' (use letters, digits, and hyphens)", name)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("project '%s': failed to resolve %s: %w", name, dir, err)
+	}
+	project, err := loadProject(root, topLevelVisiting())
+	if err != nil {
+		return fmt.Errorf("project '%s': %w", name, err)
+	}
+
+	qualify := func(names []string) []string {
+		if names == nil {
+			return nil
+		}
+		out := make([]string, len(names))
+		for i, n := range names {
+			out[i] = ProjectTaskName(name, n)
+		}
+		return out
+	}
+
+	if manifest.Tasks == nil {
+		manifest.Tasks = make(map[string]Task)
+	}
+	for taskName, task := range project.Tasks {
+		qualified := ProjectTaskName(name, taskName)
+		if _, exists := manifest.Tasks[qualified]; exists {
+			return fmt.Errorf("project '%s': task '%s' already exists", name, qualified)
+		}
+		task.WorkingDirectory = projectDir(root, task.WorkingDirectory)
+		task.DependsOn = qualify(task.DependsOn)
+		task.RequiresDaemon = qualify(task.RequiresDaemon)
+		if task.ToolName != "" {
+			task.ToolName = ProjectTaskName(name, task.ToolName)
+		}
+		manifest.Tasks[qualified] = task
+	}
+
+	if len(project.Workflows) > 0 && manifest.Workflows == nil {
+		manifest.Workflows = make(map[string]Workflow)
+	}
+	for workflowName, workflow := range project.Workflows {
+		qualified := ProjectTaskName(name, workflowName)
+		if _, exists := manifest.Workflows[qualified]; exists {
+			return fmt.Errorf("project '%s': workflow '%s' already exists", name, qualified)
+		}
+		if workflow.WorkingDirectory != "" {
+			workflow.WorkingDirectory = projectDir(root, workflow.WorkingDirectory)
+		}
+		steps := make([]WorkflowStep, len(workflow.Steps))
+		for i, step := range workflow.Steps {
+			// Keep the local name so {{ steps.<name>.stdout }} still resolves
+			step.ID = step.Name()
+			if step.Workflow != "" {
+				step.Workflow = ProjectTaskName(name, step.Workflow)
+			} else if step.Task != "" {
+				step.Task = ProjectTaskName(name, step.Task)
+			}
+			step.RequiresDaemon = qualify(step.RequiresDaemon)
+			steps[i] = step
+		}
+		workflow.Steps = steps
+		manifest.Workflows[qualified] = workflow
+	}
+
+	if len(project.TaskGroups) > 0 && manifest.TaskGroups == nil {
+		manifest.TaskGroups = make(map[string]TaskGroup)
+	}
+	for groupName, group := range project.TaskGroups {
+		qualified := ProjectTaskName(name, groupName)
+		if _, exists := manifest.TaskGroups[qualified]; exists {
+			return fmt.Errorf("project '%s': task group '%s' already exists", name, qualified)
+		}
+		group.Tasks = qualify(group.Tasks)
+		manifest.TaskGroups[qualified] = group
+	}
+
+	return nil
+}
+
+// projectDir resolves a working directory from a project rooted at root.
+func projectDir(root, dir string) string {
+	switch {
+	case dir == "":
+		return root
+	case filepath.IsAbs(dir):
+		return dir
+	default:
+		return filepath.Join(root, dir)
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const apiTasks = `version: "1.0"
+tasks:
+  build:
+    description: "Build the API"
+    command: "go build ./..."
+  test:
+    description: "Test the API"
+    command: "go test ./..."
+    working_directory: "internal"
+    depends_on: [build]
+    requires_daemon: [db]
+  db:
+    description: "Database"
+    command: "postgres"
+    type: daemon
+task_groups:
+  ci:
+    description: "CI"
+    tasks: [build, test]
+workflows:
+  check:
+    description: "Build and test"
+    steps:
+      - task: build
+      - task: test
+`
+
+func TestServerProjects(t *testing.T) {
+	setupProjects(t, `version: "1.0"
+server:
+  projects:
+    api: ../api
+tasks:
+  build:
+    description: "Build the app"
+    command: "make"
+`)
+	writeProjectConfig(t, filepath.Join("..", "api"), apiTasks)
+	apiRoot, err := filepath.Abs(filepath.Join("..", "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, loaded, err := LoadManifest("")
+	if err != nil || !loaded {
+		t.Fatalf("LoadManifest: loaded=%v err=%v", loaded, err)
+	}
+
+	if _, ok := manifest.Tasks["build"]; !ok {
+		t.Error("expected the project's own build task")
+	}
+	build, ok := manifest.Tasks["api__build"]
+	if !ok {
+		t.Fatalf("expected api__build, got tasks %v", manifest.Tasks)
+	}
+	if build.WorkingDirectory != apiRoot {
+		t.Errorf("expected api__build to run in %s, got %s", apiRoot, build.WorkingDirectory)
+	}
+
+	test := manifest.Tasks["api__test"]
+	if test.WorkingDirectory != filepath.Join(apiRoot, "internal") {
+		t.Errorf("expected relative working directory resolved in the project, got %s", test.WorkingDirectory)
+	}
+	if len(test.DependsOn) != 1 || test.DependsOn[0] != "api__build" {
+		t.Errorf("expected depends_on [api__build], got %v", test.DependsOn)
+	}
+	if len(test.RequiresDaemon) != 1 || test.RequiresDaemon[0] != "api__db" {
+		t.Errorf("expected requires_daemon [api__db], got %v", test.RequiresDaemon)
+	}
+
+	wf, ok := manifest.Workflows["api__check"]
+	if !ok || wf.Steps[0].Task != "api__build" || wf.Steps[1].Task != "api__test" {
+		t.Errorf("expected namespaced workflow steps, got %+v", wf)
+	}
+	if group := manifest.TaskGroups["api__ci"]; len(group.Tasks) != 2 || group.Tasks[1] != "api__test" {
+		t.Errorf("expected namespaced task group, got %+v", group)
+	}
+}
+
+func TestAddProjectErrors(t *testing.T) {
+	setupProjects(t, `version: "1.0"
+tasks:
+  infra__migrate:
+    description: "Clashes with a hosted task"
+    command: "true"
+`)
+
+	tests := []struct {
+		name     string
+		project  string
+		dir      string
+		errorMsg string
+	}{
+		{"invalid name", "my_api", "../infra", "invalid project name"},
+		{"missing config", "missing", "../missing", "no .runbook/ config found"},
+		{"name clash", "infra", "../infra", "task 'infra__migrate' already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, _, err := LoadManifest("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = AddProject(manifest, tt.project, tt.dir)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
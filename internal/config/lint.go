@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// evalPattern matches commands that run text as shell code a second time,
+// where even a shellquoted value is parsed again.
+var evalPattern = regexp.MustCompile(`\beval\b|\b(sh|bash|zsh)\s+-c\b`)
+
+// LintManifest returns warnings about task commands that interpolate string
+// parameters in ways a value can break out of: unquoted, inside hand-written
+// quotes, through the quote function, or into eval. Commands run by an
+// interpreter other than a shell are not checked. With
+// defaults.strict_security the warnings are validation errors.
+func LintManifest(manifest *Manifest) []string {
+	var warnings []string
+	for name, task := range manifest.Tasks {
+		warnings = append(warnings, lintCommand(name, task)...)
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// commandQuoting is the shell quoting in effect at a point in a command.
+type commandQuoting int
+
+const (
+	unquoted commandQuoting = iota
+	singleQuoted
+	doubleQuoted
+)
+
+// lintCommand returns the warnings for one task's command.
+func lintCommand(name string, task Task) []string {
+	if task.Command == "" || len(strings.Fields(task.Shell)) > 1 {
+		return nil
+	}
+	tree := parse.New("command")
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(task.Command, "", "", map[string]*parse.Tree{}); err != nil {
+		return nil
+	}
+
+	l := &commandLinter{task: name, params: task.Parameters, seen: make(map[string]bool)}
+	l.walk(tree.Root)
+	if evalPattern.MatchString(l.text.String()) {
+		for _, param := range l.interpolated {
+			l.warn("task '%s': parameter '%s' is interpolated into a command that runs eval or sh -c, which runs its value as code", name, param)
+		}
+	}
+	return l.warnings
+}
+
+// commandLinter walks a command template, tracking the shell quoting of the
+// text around each action.
+type commandLinter struct {
+	task         string
+	params       map[string]Param
+	quoting      commandQuoting
+	text         strings.Builder // The command's literal text
+	interpolated []string        // String parameters the command outputs
+	seen         map[string]bool
+	warnings     []string
+}
+
+func (l *commandLinter) warn(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if !l.seen[msg] {
+		l.seen[msg] = true
+		l.warnings = append(l.warnings, msg)
+	}
+}
+
+func (l *commandLinter) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			l.walk(child)
+		}
+	case *parse.TextNode:
+		l.text.Write(n.Text)
+		l.scanQuotes(string(n.Text))
+	case *parse.ActionNode:
+		// Actions that only set variables print nothing
+		if len(n.Pipe.Decl) == 0 {
+			l.checkAction(n.Pipe)
+		}
+	case *parse.IfNode:
+		l.walk(n.List)
+		l.walk(n.ElseList)
+	case *parse.RangeNode:
+		l.walk(n.List)
+		l.walk(n.ElseList)
+	case *parse.WithNode:
+		l.walk(n.List)
+		l.walk(n.ElseList)
+	}
+}
+
+// scanQuotes updates the quoting after a run of literal command text.
+func (l *commandLinter) scanQuotes(text string) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && l.quoting != singleQuoted:
+			i++
+		case c == '\'' && l.quoting == unquoted:
+			l.quoting = singleQuoted
+		case c == '\'' && l.quoting == singleQuoted:
+			l.quoting = unquoted
+		case c == '"' && l.quoting == unquoted:
+			l.quoting = doubleQuoted
+		case c == '"' && l.quoting == doubleQuoted:
+			l.quoting = unquoted
+		}
+	}
+}
+
+// checkAction warns about an action that outputs a string parameter
+// without shellquote as its last function.
+func (l *commandLinter) checkAction(pipe *parse.PipeNode) {
+	param := l.stringParam(pipe)
+	if param == "" {
+		return
+	}
+	l.interpolated = append(l.interpolated, param)
+
+	switch last := pipeFunc(pipe.Cmds[len(pipe.Cmds)-1]); {
+	case last == "shellquote" || last == "shellQuote":
+		return
+	case last == "quote":
+		l.warn("task '%s': parameter '%s' uses quote, whose double quotes still expand $(...) and backticks; use {{.%s | shellquote}}", l.task, param, param)
+		return
+	}
+	switch l.quoting {
+	case unquoted:
+		l.warn("task '%s': parameter '%s' is interpolated unquoted in command; use {{.%s | shellquote}}", l.task, param, param)
+	case doubleQuoted:
+		l.warn("task '%s': parameter '%s' is inside double quotes, where $(...) and backticks in its value still run; use {{.%s | shellquote}} without the quotes", l.task, param, param)
+	case singleQuoted:
+		l.warn("task '%s': parameter '%s' is inside single quotes, which a value containing ' breaks out of; use {{.%s | shellquote}} without the quotes", l.task, param, param)
+	}
+}
+
+// stringParam returns the string parameter a pipeline reads, if any.
+// Parameters passed through a file or stdin are never interpolated.
+func (l *commandLinter) stringParam(pipe *parse.PipeNode) string {
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			field, ok := arg.(*parse.FieldNode)
+			if !ok || len(field.Ident) == 0 {
+				continue
+			}
+			param, ok := l.params[field.Ident[0]]
+			if ok && param.Type == "string" && param.Source == "" {
+				return field.Ident[0]
+			}
+		}
+	}
+	return ""
+}
+
+// pipeFunc returns the function a pipeline command calls, or "".
+func pipeFunc(cmd *parse.CommandNode) string {
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		return ident.Ident
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintManifest(t *testing.T) {
+	str := Param{Type: "string", Description: "A value"}
+	tests := []struct {
+		name    string
+		command string
+		shell   string
+		params  map[string]Param
+		want    []string // Substrings, one per expected warning
+	}{
+		{
+			name:    "shellquoted",
+			command: "git checkout {{.branch | shellquote}}",
+			params:  map[string]Param{"branch": str},
+		},
+		{
+			name:    "unquoted",
+			command: "git checkout {{.branch}}",
+			params:  map[string]Param{"branch": str},
+			want:    []string{"parameter 'branch' is interpolated unquoted"},
+		},
+		{
+			name:    "unquoted through other functions",
+			command: `git checkout {{.branch | trim | default "main"}}`,
+			params:  map[string]Param{"branch": str},
+			want:    []string{"parameter 'branch' is interpolated unquoted"},
+		},
+		{
+			name:    "double quoted",
+			command: `echo "hello {{.name}}"`,
+			params:  map[string]Param{"name": str},
+			want:    []string{"parameter 'name' is inside double quotes"},
+		},
+		{
+			name:    "single quoted",
+			command: `grep '{{.pattern}}' .`,
+			params:  map[string]Param{"pattern": str},
+			want:    []string{"parameter 'pattern' is inside single quotes"},
+		},
+		{
+			name:    "quotes closed before the action",
+			command: `echo 'it''s' "x" {{.name}}`,
+			params:  map[string]Param{"name": str},
+			want:    []string{"parameter 'name' is interpolated unquoted"},
+		},
+		{
+			name:    "escaped quote",
+			command: `echo \"{{.name | shellquote}}`,
+			params:  map[string]Param{"name": str},
+		},
+		{
+			name:    "quote function",
+			command: `echo {{.name | quote}}`,
+			params:  map[string]Param{"name": str},
+			want:    []string{"parameter 'name' uses quote"},
+		},
+		{
+			name:    "eval",
+			command: `eval "$(echo {{.cmd | shellquote}})"`,
+			params:  map[string]Param{"cmd": str},
+			want:    []string{"parameter 'cmd' is interpolated into a command that runs eval"},
+		},
+		{
+			name:    "inside conditional",
+			command: `make {{if .target}}{{.target}}{{end}}`,
+			params:  map[string]Param{"target": str},
+			want:    []string{"parameter 'target' is interpolated unquoted"},
+		},
+		{
+			name:    "numbers and sources are not checked",
+			command: `head -n {{.lines}} {{.input_path}}`,
+			params: map[string]Param{
+				"lines": {Type: "number", Description: "Lines"},
+				"input": {Type: "string", Description: "Input", Source: ParamSourceFile},
+			},
+		},
+		{
+			name:    "interpreter commands are not checked",
+			command: `print("{{.name}}")`,
+			shell:   "python3 -c",
+			params:  map[string]Param{"name": str},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := &Manifest{Tasks: map[string]Task{
+				"task": {Description: "Task", Command: tt.command, Shell: tt.shell, Type: TaskTypeOneShot, Parameters: tt.params},
+			}}
+			got := LintManifest(manifest)
+			if len(got) != len(tt.want) {
+				t.Fatalf("LintManifest() = %q, want %d warnings", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
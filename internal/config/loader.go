@@ -14,8 +14,10 @@ import (
 // 1. Custom path (if provided) — can be a file or directory
 // 2. ./.runbook/ directory (auto-loads all *.yaml files)
 //
-// After loading, it also looks for .runbook.overrides.yaml in CWD and applies
-// any overrides found there.
+// A loaded manifest gains the tasks, workflows, prompts, and resources of the
+// user config directory (see dirs.UserConfigDir) that it does not define
+// itself. After loading, it also looks for .runbook.overrides.yaml in CWD and
+// applies any overrides found there.
 //
 // Returns:
 //   - manifest: The loaded manifest, or an empty manifest if none found
@@ -29,6 +31,12 @@ func LoadManifest(customPath string) (*Manifest, bool, error) {
 			return nil, false, err
 		}
 		if manifest != nil {
+			if err := addServerProjects(manifest); err != nil {
+				return nil, false, err
+			}
+			if err := mergeUserConfig(manifest); err != nil {
+				return nil, false, err
+			}
 			return applyOverridesIfPresent(manifest, true)
 		}
 		// Custom path didn't exist — fall through to defaults
@@ -38,6 +46,12 @@ func LoadManifest(customPath string) (*Manifest, bool, error) {
 	if manifest, err := LoadFromDirectory("./" + dirs.ConfigDir); err != nil {
 		return nil, false, err
 	} else if manifest != nil {
+		if err := addServerProjects(manifest); err != nil {
+			return nil, false, err
+		}
+		if err := mergeUserConfig(manifest); err != nil {
+			return nil, false, err
+		}
 		return applyOverridesIfPresent(manifest, true)
 	}
 
@@ -95,6 +109,10 @@ func loadFromFile(path string) (*Manifest, error) {
 		return nil, fmt.Errorf("failed to parse manifest at %s: %w", path, err)
 	}
 
+	if err := resolveProjectReferences(manifest, ".", topLevelVisiting(), false); err != nil {
+		return nil, fmt.Errorf("invalid manifest at %s: %w", path, err)
+	}
+
 	if err := Validate(manifest); err != nil {
 		return nil, fmt.Errorf("invalid manifest at %s: %w", path, err)
 	}
@@ -104,8 +122,26 @@ func loadFromFile(path string) (*Manifest, error) {
 
 // LoadFromDirectory scans a directory for *.yaml files and merges them
 // into a single manifest. Returns nil manifest if the directory does not
-// exist or contains no YAML files.
+// exist or contains no YAML files. Project references are resolved relative
+// to the current working directory.
 func LoadFromDirectory(dirPath string) (*Manifest, error) {
+	return loadDirectory(dirPath, ".", topLevelVisiting(), false)
+}
+
+// topLevelVisiting returns the project visiting set for a manifest loaded
+// from the current working directory.
+func topLevelVisiting() map[string]bool {
+	visiting := make(map[string]bool)
+	if cwd, err := os.Getwd(); err == nil {
+		visiting[cwd] = true
+	}
+	return visiting
+}
+
+// loadDirectory implements LoadFromDirectory, resolving project references
+// and the remote import lockfile and cache relative to rootDir. hosted is
+// set for projects loaded by a multi-project server.
+func loadDirectory(dirPath string, rootDir string, visiting map[string]bool, hosted bool) (*Manifest, error) {
 	info, err := os.Stat(dirPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -139,10 +175,16 @@ func LoadFromDirectory(dirPath string) (*Manifest, error) {
 	var imported []*Manifest
 	visited := make(map[string]bool)
 	for _, match := range matches {
-		m, nested, err := parseManifestWithImports(match, visited)
+		m, nested, err := parseManifestWithImports(match, rootDir, visited)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse %s: %w", match, err)
 		}
+		// Each file in the directory is a top-level manifest, so its
+		// defaults and exec and server settings apply as if it were the
+		// root; those of the files it imports do not
+		mergeDefaults(&root.Defaults, m.Defaults)
+		mergeExec(&root.Exec, m.Exec)
+		mergeServer(&root.Server, m.Server)
 		imported = append(imported, m)
 		imported = append(imported, nested...)
 	}
@@ -153,9 +195,21 @@ func LoadFromDirectory(dirPath string) (*Manifest, error) {
 		return nil, fmt.Errorf("failed to merge manifests from %s: %w", dirPath, err)
 	}
 
+	if err := applyAdapters(manifest, rootDir); err != nil {
+		return nil, fmt.Errorf("invalid merged manifest from %s: %w", dirPath, err)
+	}
+
+	if err := applyTaskTemplates(manifest); err != nil {
+		return nil, fmt.Errorf("invalid merged manifest from %s: %w", dirPath, err)
+	}
+
 	// Apply defaults
 	applyDefaults(manifest)
 
+	if err := resolveProjectReferences(manifest, rootDir, visiting, hosted); err != nil {
+		return nil, fmt.Errorf("invalid merged manifest from %s: %w", dirPath, err)
+	}
+
 	// Validate the merged manifest
 	if err := Validate(manifest); err != nil {
 		return nil, fmt.Errorf("invalid merged manifest from %s: %w", dirPath, err)
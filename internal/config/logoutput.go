@@ -0,0 +1,68 @@
+package config
+
+import "fmt"
+
+// How much of a oneshot task's stdout its results keep. Full (the default)
+// keeps all of it; truncated keeps the last lines, within the task's line
+// and byte caps; none keeps nothing. The session log always has all of it.
+const (
+	LogOutputFull      = "full"
+	LogOutputTruncated = "truncated"
+	LogOutputNone      = "none"
+)
+
+// Caps of log_output: truncated when the task does not set its own.
+const (
+	DefaultLogOutputMaxLines = 100
+	DefaultLogOutputMaxBytes = 64 << 10
+)
+
+// LogOutputLimits returns how many lines and bytes of stdout the results of
+// a task with log_output: truncated keep. Both are 0 for other tasks.
+func (t Task) LogOutputLimits() (maxLines int, maxBytes int64) {
+	if t.LogOutput != LogOutputTruncated {
+		return 0, 0
+	}
+	maxLines, maxBytes = t.LogOutputMaxLines, DefaultLogOutputMaxBytes
+	if maxLines == 0 {
+		maxLines = DefaultLogOutputMaxLines
+	}
+	if t.LogOutputMaxBytes != "" {
+		if n, err := ParseByteSize(t.LogOutputMaxBytes); err == nil {
+			maxBytes = n
+		}
+	}
+	return maxLines, maxBytes
+}
+
+// validateLogOutput checks a task's log_output and its caps.
+func validateLogOutput(name string, task Task) []string {
+	var errors []string
+	switch task.LogOutput {
+	case "", LogOutputFull, LogOutputTruncated, LogOutputNone:
+	default:
+		errors = append(errors, fmt.Sprintf("task '%s': invalid log_output '%s' (must be full, truncated, or none)", name, task.LogOutput))
+	}
+	if task.LogOutput != "" && task.LogOutput != LogOutputFull {
+		if task.Type != TaskTypeOneShot {
+			errors = append(errors, fmt.Sprintf("task '%s': log_output is only supported on oneshot tasks", name))
+		} else if task.Interactive {
+			errors = append(errors, fmt.Sprintf("task '%s': log_output needs captured output, which interactive tasks do not have", name))
+		}
+	}
+	if task.LogOutputMaxLines == 0 && task.LogOutputMaxBytes == "" {
+		return errors
+	}
+	if task.LogOutput != LogOutputTruncated {
+		errors = append(errors, fmt.Sprintf("task '%s': log_output_max_lines and log_output_max_bytes require log_output: truncated", name))
+	}
+	if task.LogOutputMaxLines < 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': log_output_max_lines cannot be negative", name))
+	}
+	if task.LogOutputMaxBytes != "" {
+		if _, err := ParseByteSize(task.LogOutputMaxBytes); err != nil {
+			errors = append(errors, fmt.Sprintf("task '%s': log_output_max_bytes: %v", name, err))
+		}
+	}
+	return errors
+}
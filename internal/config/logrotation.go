@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultLogMaxFiles is how many rotated log segments a daemon with
+// log_max_size keeps when log_max_files is not set.
+const DefaultLogMaxFiles = 5
+
+// byteSizeUnits maps the suffixes accepted by ParseByteSize to multipliers.
+var byteSizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size such as "512KB", "10MB" or "1G" (binary
+// units, case-insensitive). A plain number is a count of bytes.
+func ParseByteSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	factor := int64(1)
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			factor = unit.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (want a positive number with an optional KB, MB or GB suffix)", value)
+	}
+	return n * factor, nil
+}
+
+// LogRotation returns the size at which the task's session log is rotated
+// and how many rotated segments are kept. maxSize is 0 when the log is not
+// rotated.
+func (t Task) LogRotation() (maxSize int64, maxFiles int) {
+	if t.LogMaxSize == "" {
+		return 0, 0
+	}
+	maxSize, err := ParseByteSize(t.LogMaxSize)
+	if err != nil {
+		return 0, 0
+	}
+	maxFiles = t.LogMaxFiles
+	if maxFiles == 0 {
+		maxFiles = DefaultLogMaxFiles
+	}
+	return maxSize, maxFiles
+}
+
+// validateLogRotation checks a task's log_max_size and log_max_files.
+func validateLogRotation(name string, task Task) []string {
+	if task.LogMaxSize == "" && task.LogMaxFiles == 0 {
+		return nil
+	}
+	var errors []string
+	if !task.Type.IsDaemon() {
+		errors = append(errors, fmt.Sprintf("task '%s': log_max_size and log_max_files are only supported on daemon tasks", name))
+	}
+	if task.LogMaxSize == "" {
+		errors = append(errors, fmt.Sprintf("task '%s': log_max_files requires log_max_size", name))
+	} else if _, err := ParseByteSize(task.LogMaxSize); err != nil {
+		errors = append(errors, fmt.Sprintf("task '%s': log_max_size: %v", name, err))
+	}
+	if task.LogMaxFiles < 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': log_max_files cannot be negative", name))
+	}
+	return errors
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "512", want: 512},
+		{value: "64KB", want: 64 << 10},
+		{value: "10mb", want: 10 << 20},
+		{value: "1G", want: 1 << 30},
+		{value: " 2 MB ", want: 2 << 20},
+		{value: "", wantErr: true},
+		{value: "0MB", wantErr: true},
+		{value: "MB", wantErr: true},
+		{value: "1.5MB", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseByteSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTaskLogRotation(t *testing.T) {
+	if size, files := (Task{}).LogRotation(); size != 0 || files != 0 {
+		t.Errorf("expected no rotation, got %d, %d", size, files)
+	}
+	if size, files := (Task{LogMaxSize: "1KB"}).LogRotation(); size != 1024 || files != DefaultLogMaxFiles {
+		t.Errorf("expected 1024 bytes and the default file count, got %d, %d", size, files)
+	}
+	if _, files := (Task{LogMaxSize: "1KB", LogMaxFiles: 2}).LogRotation(); files != 2 {
+		t.Errorf("expected 2 files, got %d", files)
+	}
+}
@@ -2,21 +2,32 @@ package config
 
 import (
 	"fmt"
+	"slices"
 )
 
 // mergeManifests combines a base manifest with imported manifests
-// The base manifest provides the version and defaults
+// The base manifest provides the version, defaults, and the exec and server
+// settings; imports cannot change them, so a fetched file can never enable
+// exec_command or task edits, or replace auth and TLS
 // Imported manifests contribute tasks, task groups, and prompts
 // Returns an error if duplicate keys are found
 func mergeManifests(base *Manifest, imports []*Manifest) (*Manifest, error) {
 	result := &Manifest{
-		Version:    base.Version,
-		Defaults:   base.Defaults,
-		Tasks:      make(map[string]Task),
-		TaskGroups: make(map[string]TaskGroup),
-		Prompts:    make(map[string]Prompt),
-		Resources:  make(map[string]Resource),
-		Workflows:  make(map[string]Workflow),
+		Version:         base.Version,
+		Defaults:        base.Defaults,
+		Exec:            base.Exec,
+		Server:          base.Server,
+		Testing:         base.Testing,
+		Adapters:        base.Adapters,
+		AllowedProjects: append([]string{}, base.AllowedProjects...),
+		Tasks:           make(map[string]Task),
+		TaskGroups:      make(map[string]TaskGroup),
+		Prompts:         make(map[string]Prompt),
+		PromptPartials:  make(map[string]PromptPartial),
+		Resources:       make(map[string]Resource),
+		Workflows:       make(map[string]Workflow),
+		TaskTemplates:   make(map[string]Task),
+		Vars:            make(map[string]string),
 	}
 
 	// Start with base manifest tasks, groups, prompts, resources, and workflows
@@ -29,12 +40,21 @@ func mergeManifests(base *Manifest, imports []*Manifest) (*Manifest, error) {
 	if err := mergePrompts(result.Prompts, base.Prompts); err != nil {
 		return nil, err
 	}
+	if err := mergePromptPartials(result.PromptPartials, base.PromptPartials); err != nil {
+		return nil, err
+	}
 	if err := mergeResources(result.Resources, base.Resources); err != nil {
 		return nil, err
 	}
 	if err := mergeWorkflows(result.Workflows, base.Workflows); err != nil {
 		return nil, err
 	}
+	if err := mergeTaskTemplates(result.TaskTemplates, base.TaskTemplates); err != nil {
+		return nil, err
+	}
+	if err := mergeVars(result.Vars, base.Vars); err != nil {
+		return nil, err
+	}
 
 	// Merge each imported manifest
 	for _, imported := range imports {
@@ -47,12 +67,24 @@ func mergeManifests(base *Manifest, imports []*Manifest) (*Manifest, error) {
 		if err := mergePrompts(result.Prompts, imported.Prompts); err != nil {
 			return nil, err
 		}
+		if err := mergePromptPartials(result.PromptPartials, imported.PromptPartials); err != nil {
+			return nil, err
+		}
 		if err := mergeResources(result.Resources, imported.Resources); err != nil {
 			return nil, err
 		}
 		if err := mergeWorkflows(result.Workflows, imported.Workflows); err != nil {
 			return nil, err
 		}
+		if err := mergeTaskTemplates(result.TaskTemplates, imported.TaskTemplates); err != nil {
+			return nil, err
+		}
+		if err := mergeVars(result.Vars, imported.Vars); err != nil {
+			return nil, err
+		}
+		mergeTesting(&result.Testing, imported.Testing)
+		mergeAdapters(&result.Adapters, imported.Adapters)
+		result.AllowedProjects = append(result.AllowedProjects, imported.AllowedProjects...)
 	}
 
 	return result, nil
@@ -94,6 +126,18 @@ func mergePrompts(dst, src map[string]Prompt) error {
 	return nil
 }
 
+// mergePromptPartials merges source prompt partials into destination
+// Returns error if duplicate partial names are found
+func mergePromptPartials(dst, src map[string]PromptPartial) error {
+	for name, partial := range src {
+		if _, exists := dst[name]; exists {
+			return fmt.Errorf("duplicate prompt partial name '%s' found during merge", name)
+		}
+		dst[name] = partial
+	}
+	return nil
+}
+
 // mergeResources merges source resources into destination
 // Returns error if duplicate resource names are found
 func mergeResources(dst, src map[string]Resource) error {
@@ -117,3 +161,161 @@ func mergeWorkflows(dst, src map[string]Workflow) error {
 	}
 	return nil
 }
+
+// mergeTaskTemplates merges source task templates into destination
+// Returns error if duplicate template names are found
+func mergeTaskTemplates(dst, src map[string]Task) error {
+	for name, tmpl := range src {
+		if _, exists := dst[name]; exists {
+			return fmt.Errorf("duplicate task template name '%s' found during merge", name)
+		}
+		dst[name] = tmpl
+	}
+	return nil
+}
+
+// mergeVars merges source vars into destination
+// Returns error if duplicate var names are found
+func mergeVars(dst, src map[string]string) error {
+	for name, value := range src {
+		if _, exists := dst[name]; exists {
+			return fmt.Errorf("duplicate var '%s' found during merge", name)
+		}
+		dst[name] = value
+	}
+	return nil
+}
+
+// mergeExec fills unset exec settings in dst from src, another top-level
+// file of the project's config directory. The first file to set a field
+// wins; exec is enabled, or needs confirmation, if any of them says so.
+func mergeExec(dst *ExecConfig, src ExecConfig) {
+	if src.Enabled {
+		dst.Enabled = true
+	}
+	if dst.Timeout == 0 {
+		dst.Timeout = src.Timeout
+	}
+	if dst.MaxTimeout == 0 {
+		dst.MaxTimeout = src.MaxTimeout
+	}
+	if dst.Shell == "" {
+		dst.Shell = src.Shell
+	}
+	if dst.RateLimit == 0 {
+		dst.RateLimit = src.RateLimit
+	}
+	if src.RequiresConfirmation {
+		dst.RequiresConfirmation = true
+	}
+	dst.Allow = append(dst.Allow, src.Allow...)
+	dst.Deny = append(dst.Deny, src.Deny...)
+	dst.AllowedWorkingDirectories = append(dst.AllowedWorkingDirectories, src.AllowedWorkingDirectories...)
+}
+
+// mergeTesting adds the faults of src to dst. Faults already in dst win.
+func mergeTesting(dst *TestingConfig, src TestingConfig) {
+	if src.Enabled {
+		dst.Enabled = true
+	}
+	if dst.Seed == 0 {
+		dst.Seed = src.Seed
+	}
+	for pattern, fault := range src.Faults {
+		if dst.Faults == nil {
+			dst.Faults = make(map[string]Fault)
+		}
+		if _, exists := dst.Faults[pattern]; !exists {
+			dst.Faults[pattern] = fault
+		}
+	}
+}
+
+// mergeServer fills unset server settings in dst from src, another
+// top-level file of the project's config directory. The first file to set a
+// field wins; task edits are allowed if any of them allows them.
+func mergeServer(dst *ServerConfig, src ServerConfig) {
+	if dst.Name == "" {
+		dst.Name = src.Name
+	}
+	if dst.VersionSuffix == "" {
+		dst.VersionSuffix = src.VersionSuffix
+	}
+	if dst.Instructions == "" {
+		dst.Instructions = src.Instructions
+	}
+	if dst.Locale == "" {
+		dst.Locale = src.Locale
+	}
+	if dst.Auth.Type == "" {
+		dst.Auth = src.Auth
+	}
+	if dst.TLS.CertFile == "" {
+		dst.TLS = src.TLS
+	}
+	if src.AllowTaskEdits {
+		dst.AllowTaskEdits = true
+	}
+	if dst.ShutdownGrace == 0 {
+		dst.ShutdownGrace = src.ShutdownGrace
+	}
+	if len(dst.Capabilities) == 0 {
+		dst.Capabilities = src.Capabilities
+	}
+	for name, dir := range src.Projects {
+		if dst.Projects == nil {
+			dst.Projects = make(map[string]string)
+		}
+		if _, exists := dst.Projects[name]; !exists {
+			dst.Projects[name] = dir
+		}
+	}
+}
+
+// mergeDefaults fills the defaults dst leaves unset from src. Env keys and
+// redact patterns are combined, earlier files winning.
+func mergeDefaults(dst *Defaults, src Defaults) {
+	if dst.Timeout == 0 {
+		dst.Timeout = src.Timeout
+	}
+	if dst.MaxTimeout == 0 {
+		dst.MaxTimeout = src.MaxTimeout
+	}
+	if dst.Shell == "" {
+		dst.Shell = src.Shell
+	}
+	if dst.LatencyBudget == 0 {
+		dst.LatencyBudget = src.LatencyBudget
+	}
+	if dst.EnvPolicy == nil {
+		dst.EnvPolicy = src.EnvPolicy
+	}
+	if dst.StopGrace == 0 {
+		dst.StopGrace = src.StopGrace
+	}
+	if dst.SessionSink == nil {
+		dst.SessionSink = src.SessionSink
+	}
+	if dst.MaxConcurrentTasks == 0 {
+		dst.MaxConcurrentTasks = src.MaxConcurrentTasks
+	}
+	if dst.OnCrash == nil {
+		dst.OnCrash = src.OnCrash
+	}
+	if !dst.StrictSecurity {
+		dst.StrictSecurity = src.StrictSecurity
+	}
+	for key, value := range src.Env {
+		if dst.Env == nil {
+			dst.Env = make(map[string]string)
+		}
+		if _, exists := dst.Env[key]; !exists {
+			dst.Env[key] = value
+		}
+	}
+	for _, pattern := range src.Redact {
+		if !slices.Contains(dst.Redact, pattern) {
+			dst.Redact = append(dst.Redact, pattern)
+		}
+	}
+}
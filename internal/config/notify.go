@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CrashNotify returns how a crash of the task is reported: its own on_crash,
+// or else defaults.on_crash. It returns nil when crashes are not reported.
+func (m *Manifest) CrashNotify(taskName string) *CrashNotify {
+	if task, ok := m.Tasks[taskName]; ok && task.OnCrash != nil {
+		return task.OnCrash
+	}
+	return m.Defaults.OnCrash
+}
+
+// validateCrashNotify checks an on_crash block at where ("task 'x'" or
+// "defaults"). A notify task must be a oneshot task that can run unattended.
+func validateCrashNotify(where string, notify *CrashNotify, tasks map[string]Task) []string {
+	if notify == nil {
+		return nil
+	}
+	var errors []string
+	if !notify.Desktop && notify.Webhook == "" && notify.Task == "" {
+		errors = append(errors, fmt.Sprintf("%s: on_crash requires desktop, webhook, or task", where))
+	}
+	if notify.Webhook != "" && !strings.HasPrefix(notify.Webhook, "http://") && !strings.HasPrefix(notify.Webhook, "https://") {
+		errors = append(errors, fmt.Sprintf("%s: on_crash.webhook must be an http(s) URL", where))
+	}
+	if notify.Webhook == "" && notify.TokenEnv != "" {
+		errors = append(errors, fmt.Sprintf("%s: on_crash.token_env requires webhook", where))
+	}
+	if notify.Task != "" {
+		task, ok := tasks[notify.Task]
+		switch {
+		case !ok:
+			errors = append(errors, fmt.Sprintf("%s: on_crash.task '%s' does not exist", where, notify.Task))
+		case task.Type != TaskTypeOneShot:
+			errors = append(errors, fmt.Sprintf("%s: on_crash.task '%s' must be a oneshot task", where, notify.Task))
+		case task.Interactive:
+			errors = append(errors, fmt.Sprintf("%s: on_crash.task '%s' is interactive and cannot run unattended", where, notify.Task))
+		}
+	}
+	return errors
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverlaysKey is the top-level section of a manifest file holding per-OS
+// overlays. Each overlay is a partial manifest, keyed by an OS name as in Go's
+// runtime.GOOS, merged into the file when loaded on that OS:
+//
+//	tasks:
+//	  build:
+//	    command: "make build"
+//	overlays:
+//	  windows:
+//	    tasks:
+//	      build:
+//	        command: "nmake build"
+const OverlaysKey = "overlays"
+
+// overlayOSes are the OS names an overlay can be keyed by.
+var overlayOSes = []string{"linux", "darwin", "windows", "freebsd", "openbsd", "netbsd"}
+
+// applyOverlays merges the overlay for goos into a parsed manifest file and
+// removes the overlays section. Mappings are merged key by key; any other
+// value in the overlay, including a list, replaces the file's value.
+func applyOverlays(doc *yaml.Node, goos string) error {
+	root := rootMapping(doc)
+	overlays := mappingValue(root, OverlaysKey)
+	if overlays == nil {
+		return nil
+	}
+	if overlays.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s must map OS names to manifest sections", OverlaysKey)
+	}
+	for i := 0; i+1 < len(overlays.Content); i += 2 {
+		name, overlay := overlays.Content[i].Value, overlays.Content[i+1]
+		if !slices.Contains(overlayOSes, name) {
+			return fmt.Errorf("%s: unknown OS '%s' (must be one of %s)", OverlaysKey, name, strings.Join(overlayOSes, ", "))
+		}
+		if overlay.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s.%s must be a mapping", OverlaysKey, name)
+		}
+	}
+
+	deleteMappingValue(root, OverlaysKey)
+	if overlay := mappingValue(overlays, goos); overlay != nil {
+		mergeMappingNodes(root, overlay)
+	}
+	return nil
+}
+
+// mergeMappingNodes merges the keys of src into dst, recursing into values
+// that are mappings in both.
+func mergeMappingNodes(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i].Value, src.Content[i+1]
+		if existing := mappingValue(dst, key); existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeMappingNodes(existing, value)
+			continue
+		}
+		setMappingValue(dst, key, value)
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const overlayYAML = `version: "1.0"
+defaults:
+  shell: /bin/bash
+  env:
+    CC: gcc
+tasks:
+  build:
+    description: "Build"
+    command: "make build"
+    env:
+      MODE: release
+overlays:
+  windows:
+    defaults:
+      shell: "pwsh -Command"
+    tasks:
+      build:
+        command: "nmake build"
+        env:
+          VSCMD: "1"
+      clean:
+        description: "Clean"
+        command: "del /q build"
+`
+
+func TestApplyOverlays(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(overlayYAML), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyOverlays(&doc, "windows"); err != nil {
+		t.Fatalf("applyOverlays failed: %v", err)
+	}
+	var manifest Manifest
+	if err := doc.Decode(&manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	build := manifest.Tasks["build"]
+	if build.Command != "nmake build" || build.Description != "Build" {
+		t.Errorf("expected the overlay command with the base description, got %+v", build)
+	}
+	if build.Env["MODE"] != "release" || build.Env["VSCMD"] != "1" {
+		t.Errorf("expected nested mappings to merge, got %v", build.Env)
+	}
+	if _, ok := manifest.Tasks["clean"]; !ok {
+		t.Error("expected the overlay to add the clean task")
+	}
+	if manifest.Defaults.Shell != "pwsh -Command" || manifest.Defaults.Env["CC"] != "gcc" {
+		t.Errorf("expected the overlay defaults merged over the base, got %+v", manifest.Defaults)
+	}
+}
+
+func TestApplyOverlaysOtherOS(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(overlayYAML), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyOverlays(&doc, "linux"); err != nil {
+		t.Fatalf("applyOverlays failed: %v", err)
+	}
+	var manifest Manifest
+	if err := doc.Decode(&manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Tasks["build"].Command != "make build" || len(manifest.Tasks) != 1 {
+		t.Errorf("expected the file unchanged without a linux overlay, got %+v", manifest.Tasks)
+	}
+	if mappingValue(rootMapping(&doc), OverlaysKey) != nil {
+		t.Error("expected the overlays section to be removed")
+	}
+}
+
+func TestApplyOverlaysInvalid(t *testing.T) {
+	tests := []struct {
+		name, yaml, errorMsg string
+	}{
+		{"unknown OS", "overlays:\n  beos:\n    tasks: {}\n", "unknown OS 'beos'"},
+		{"overlay not a mapping", "overlays:\n  linux: [a]\n", "overlays.linux must be a mapping"},
+		{"overlays not a mapping", "overlays: linux\n", "must map OS names"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.yaml), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if err := applyOverlays(&doc, "linux"); err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestParseManifestAppliesOverlays(t *testing.T) {
+	path := writeTempFile(t, "runbook-*.yaml", `version: "1.0"
+tasks:
+  build:
+    description: "Build"
+    command: "make build"
+overlays:
+  `+runtime.GOOS+`:
+    tasks:
+      build:
+        command: "make build-native"
+`)
+	manifest, err := ParseManifest(path)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.Tasks["build"].Command != "make build-native" {
+		t.Errorf("expected the overlay for %s, got %q", runtime.GOOS, manifest.Tasks["build"].Command)
+	}
+}
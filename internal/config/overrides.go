@@ -93,3 +93,24 @@ func matchesPattern(pattern, name string) bool {
 	matched, err := filepath.Match(pattern, name)
 	return err == nil && matched
 }
+
+// TaskDisabled reports whether a tasks pattern in o disables the task name.
+// A nil Overrides disables nothing.
+func (o *Overrides) TaskDisabled(name string) bool {
+	return o != nil && overrideDisables(o.Tasks, name)
+}
+
+// WorkflowDisabled reports whether a workflows pattern in o disables the
+// workflow name.
+func (o *Overrides) WorkflowDisabled(name string) bool {
+	return o != nil && overrideDisables(o.Workflows, name)
+}
+
+func overrideDisables(overrides map[string]ItemOverride, name string) bool {
+	for pattern, override := range overrides {
+		if override.Disabled && matchesPattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
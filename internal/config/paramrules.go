@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// numericParamTypes are the parameter types min and max apply to.
+var numericParamTypes = []string{"number", "integer"}
+
+// CheckParamValues checks the given parameters against the pattern, enum,
+// min, max, min_length, and max_length rules of their definitions.
+// Parameters that were not given are not checked. The error lists every
+// value that breaks a rule.
+func CheckParamValues(defs map[string]Param, params map[string]interface{}) error {
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		value, given := params[name]
+		if !given || value == nil {
+			continue
+		}
+		problems = append(problems, checkParamValue(name, defs[name], value)...)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid parameters: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkParamValue returns how value breaks the rules of def, if it does.
+func checkParamValue(name string, def Param, value interface{}) []string {
+	var problems []string
+	text := paramText(value)
+	if len(def.Enum) > 0 && !slices.Contains(def.Enum, text) {
+		problems = append(problems, fmt.Sprintf("parameter '%s' must be one of %s, got '%s'", name, strings.Join(def.Enum, ", "), text))
+	}
+	if def.Pattern != "" {
+		if re, err := regexp.Compile(def.Pattern); err == nil && !re.MatchString(text) {
+			problems = append(problems, fmt.Sprintf("parameter '%s' must match pattern '%s', got '%s'", name, def.Pattern, text))
+		}
+	}
+	if def.MinLength != nil && utf8.RuneCountInString(text) < *def.MinLength {
+		problems = append(problems, fmt.Sprintf("parameter '%s' must be at least %d characters long", name, *def.MinLength))
+	}
+	if def.MaxLength != nil && utf8.RuneCountInString(text) > *def.MaxLength {
+		problems = append(problems, fmt.Sprintf("parameter '%s' must be at most %d characters long", name, *def.MaxLength))
+	}
+	if def.Min != nil || def.Max != nil || (def.Type == "integer" && text != "") {
+		n, err := paramNumber(value)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("parameter '%s' must be a number, got '%s'", name, text))
+		case def.Type == "integer" && n != float64(int64(n)):
+			problems = append(problems, fmt.Sprintf("parameter '%s' must be an integer, got '%s'", name, text))
+		case def.Min != nil && n < *def.Min:
+			problems = append(problems, fmt.Sprintf("parameter '%s' must be at least %s, got '%s'", name, formatNumber(*def.Min), text))
+		case def.Max != nil && n > *def.Max:
+			problems = append(problems, fmt.Sprintf("parameter '%s' must be at most %s, got '%s'", name, formatNumber(*def.Max), text))
+		}
+	}
+	return problems
+}
+
+// paramText returns a parameter value as it is substituted into commands.
+func paramText(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return formatNumber(f)
+	}
+	return fmt.Sprint(value)
+}
+
+// paramNumber returns a parameter value as a number: JSON numbers as they
+// are, and strings from the CLI or defaults parsed.
+func paramNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	return 0, fmt.Errorf("not a number")
+}
+
+// formatNumber formats f without a trailing ".0" for whole numbers.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// validateParamRules checks the validation rules of parameter definitions:
+// patterns compile, bounds are ordered and fit the type, and defaults obey
+// the rules. owner identifies the task or workflow in error messages.
+func validateParamRules(owner string, defs map[string]Param) []string {
+	var errors []string
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := defs[name]
+		if def.Pattern != "" {
+			if _, err := regexp.Compile(def.Pattern); err != nil {
+				errors = append(errors, fmt.Sprintf("%s: parameter '%s' has an invalid pattern: %v", owner, name, err))
+			}
+		}
+		if (def.Min != nil || def.Max != nil) && !slices.Contains(numericParamTypes, def.Type) {
+			errors = append(errors, fmt.Sprintf("%s: parameter '%s': min and max require type number or integer", owner, name))
+		}
+		if def.Min != nil && def.Max != nil && *def.Min > *def.Max {
+			errors = append(errors, fmt.Sprintf("%s: parameter '%s': min is greater than max", owner, name))
+		}
+		if (def.MinLength != nil || def.MaxLength != nil) && def.Type != "string" {
+			errors = append(errors, fmt.Sprintf("%s: parameter '%s': min_length and max_length require type string", owner, name))
+		}
+		if (def.MinLength != nil && *def.MinLength < 0) || (def.MaxLength != nil && *def.MaxLength < 0) {
+			errors = append(errors, fmt.Sprintf("%s: parameter '%s': min_length and max_length cannot be negative", owner, name))
+		}
+		if def.MinLength != nil && def.MaxLength != nil && *def.MinLength > *def.MaxLength {
+			errors = append(errors, fmt.Sprintf("%s: parameter '%s': min_length is greater than max_length", owner, name))
+		}
+		if def.Default != nil {
+			for _, problem := range checkParamValue(name, def, *def.Default) {
+				errors = append(errors, fmt.Sprintf("%s: default: %s", owner, problem))
+			}
+		}
+	}
+	return errors
+}
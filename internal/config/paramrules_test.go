@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestCheckParamValues(t *testing.T) {
+	defs := map[string]Param{
+		"env":      {Type: "string", Enum: []string{"staging", "prod"}},
+		"branch":   {Type: "string", Pattern: `^[a-z0-9/_-]+$`, MaxLength: ptr(20)},
+		"replicas": {Type: "integer", Min: ptr(1.0), Max: ptr(10.0)},
+		"ratio":    {Type: "number", Min: ptr(0.0), Max: ptr(1.0)},
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			params: map[string]interface{}{"env": "prod", "branch": "feature/x", "replicas": float64(3), "ratio": "0.5"},
+		},
+		{
+			name:   "missing parameters are not checked",
+			params: map[string]interface{}{},
+		},
+		{
+			name:    "not in enum",
+			params:  map[string]interface{}{"env": "dev"},
+			wantErr: []string{"parameter 'env' must be one of staging, prod, got 'dev'"},
+		},
+		{
+			name:    "pattern and length",
+			params:  map[string]interface{}{"branch": "Feature; rm -rf / --no-preserve-root"},
+			wantErr: []string{"parameter 'branch' must match pattern", "parameter 'branch' must be at most 20 characters long"},
+		},
+		{
+			name:    "above max",
+			params:  map[string]interface{}{"replicas": "12"},
+			wantErr: []string{"parameter 'replicas' must be at most 10, got '12'"},
+		},
+		{
+			name:    "not an integer",
+			params:  map[string]interface{}{"replicas": float64(2.5)},
+			wantErr: []string{"parameter 'replicas' must be an integer, got '2.5'"},
+		},
+		{
+			name:    "not a number",
+			params:  map[string]interface{}{"ratio": "half"},
+			wantErr: []string{"parameter 'ratio' must be a number, got 'half'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckParamValues(defs, tt.params)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("CheckParamValues() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("CheckParamValues() succeeded, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("CheckParamValues() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReservedParamPrefix starts the argument names runbook keeps for itself.
+// No parameter, alias, or deprecated name may use it, and task runs drop
+// arguments that do.
+const ReservedParamPrefix = "__"
+
+// ResolveParamNames rewrites arguments passed under a parameter's alias or
+// deprecated name to the parameter's own name. It returns a warning for each
+// deprecated name used, and an error when one parameter is given under more
+// than one name.
+func ResolveParamNames(defs map[string]Param, params map[string]interface{}) ([]string, error) {
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		def := defs[name]
+		given := ""
+		if _, ok := params[name]; ok {
+			given = name
+		}
+		for _, alias := range def.Aliases {
+			if err := renameParam(params, name, alias, &given); err != nil {
+				return nil, err
+			}
+		}
+		for _, old := range def.DeprecatedNames {
+			if _, ok := params[old]; ok {
+				warnings = append(warnings, fmt.Sprintf("parameter '%s' is deprecated, use '%s'", old, name))
+			}
+			if err := renameParam(params, name, old, &given); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// renameParam moves params[from] to params[to]. given tracks the name the
+// parameter was already passed under, if any.
+func renameParam(params map[string]interface{}, to, from string, given *string) error {
+	value, ok := params[from]
+	if !ok {
+		return nil
+	}
+	if *given != "" {
+		return fmt.Errorf("parameter '%s' was passed as both '%s' and '%s'", to, *given, from)
+	}
+	*given = from
+	delete(params, from)
+	params[to] = value
+	return nil
+}
+
+// validateParamNames checks that aliases and deprecated names are unique
+// across parameters, and that no name is reserved. owner identifies the task
+// or workflow in error messages.
+func validateParamNames(owner string, defs map[string]Param) []string {
+	var errors []string
+	seen := make(map[string]string, len(defs))
+	for name := range defs {
+		seen[name] = name
+	}
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := defs[name]
+		if strings.HasPrefix(name, ReservedParamPrefix) {
+			errors = append(errors, fmt.Sprintf("%s: parameter name '%s' is reserved: names starting with '%s' are for runbook", owner, name, ReservedParamPrefix))
+		}
+		for _, other := range append(append([]string{}, def.Aliases...), def.DeprecatedNames...) {
+			if strings.HasPrefix(other, ReservedParamPrefix) {
+				errors = append(errors, fmt.Sprintf("%s: parameter '%s' alias '%s' is reserved: names starting with '%s' are for runbook", owner, name, other, ReservedParamPrefix))
+				continue
+			}
+			if other == "" {
+				errors = append(errors, fmt.Sprintf("%s: parameter '%s' has an empty alias", owner, name))
+				continue
+			}
+			if prev, ok := seen[other]; ok {
+				errors = append(errors, fmt.Sprintf("%s: parameter '%s' alias '%s' is already used by '%s'", owner, name, other, prev))
+				continue
+			}
+			seen[other] = name
+		}
+	}
+	return errors
+}
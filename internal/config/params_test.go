@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveParamNames(t *testing.T) {
+	defs := map[string]Param{
+		"pkg":   {Type: "string", Aliases: []string{"package"}},
+		"level": {Type: "string", DeprecatedNames: []string{"verbosity", "v"}},
+	}
+
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		want     map[string]interface{}
+		warnings []string
+		errMsg   string
+	}{
+		{
+			name:   "own names",
+			params: map[string]interface{}{"pkg": "a", "level": "debug"},
+			want:   map[string]interface{}{"pkg": "a", "level": "debug"},
+		},
+		{
+			name:   "alias without warning",
+			params: map[string]interface{}{"package": "a"},
+			want:   map[string]interface{}{"pkg": "a"},
+		},
+		{
+			name:     "deprecated name with warning",
+			params:   map[string]interface{}{"verbosity": "debug", "other": 1},
+			want:     map[string]interface{}{"level": "debug", "other": 1},
+			warnings: []string{"parameter 'verbosity' is deprecated, use 'level'"},
+		},
+		{
+			name:   "name and alias",
+			params: map[string]interface{}{"pkg": "a", "package": "b"},
+			errMsg: "parameter 'pkg' was passed as both 'pkg' and 'package'",
+		},
+		{
+			name:   "two deprecated names",
+			params: map[string]interface{}{"verbosity": "debug", "v": "info"},
+			errMsg: "parameter 'level' was passed as both 'verbosity' and 'v'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := ResolveParamNames(defs, tt.params)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("err = %v, want %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.params, tt.want) {
+				t.Errorf("params = %v, want %v", tt.params, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("warnings = %v, want %v", warnings, tt.warnings)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -11,7 +13,8 @@ import (
 
 // parseManifestWithImports recursively parses a manifest and all its imports
 // visited tracks files already processed to detect circular dependencies
-func parseManifestWithImports(path string, visited map[string]bool) (*Manifest, []*Manifest, error) {
+// root is the project directory whose lockfile pins remote imports
+func parseManifestWithImports(path string, root string, visited map[string]bool) (*Manifest, []*Manifest, error) {
 	// Normalize path for consistent comparison
 	absPath, err := filepath.Abs(path)
 	if err != nil {
@@ -33,11 +36,28 @@ func parseManifestWithImports(path string, visited map[string]bool) (*Manifest,
 		return nil, nil, fmt.Errorf("failed to read manifest file %s: %w", path, err)
 	}
 
-	var manifest Manifest
-	if err := yaml.Unmarshal(data, &manifest); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, nil, fmt.Errorf("failed to parse YAML from %s: %w", path, err)
 	}
 
+	// Merge in the overlay for this OS before anything reads the file
+	if err := applyOverlays(&doc, runtime.GOOS); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	var manifest Manifest
+	if doc.Kind != 0 {
+		if err := doc.Decode(&manifest); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse YAML from %s: %w", path, err)
+		}
+	}
+
+	// Load prompts_dir and resources_dir relative to this YAML file's directory
+	if err := loadContentDirs(&manifest, filepath.Dir(absPath)); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
 	// Resolve file-based resources relative to this YAML file's directory
 	if err := resolveResourceFiles(&manifest, filepath.Dir(absPath)); err != nil {
 		return nil, nil, fmt.Errorf("failed to resolve resource files in %s: %w", path, err)
@@ -50,7 +70,7 @@ func parseManifestWithImports(path string, visited map[string]bool) (*Manifest,
 
 	// Resolve import paths (expand globs)
 	baseDir := filepath.Dir(path)
-	importPaths, err := resolveImports(baseDir, manifest.Imports)
+	importPaths, err := resolveImports(baseDir, root, manifest.Imports)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to resolve imports in %s: %w", path, err)
 	}
@@ -58,7 +78,7 @@ func parseManifestWithImports(path string, visited map[string]bool) (*Manifest,
 	// Recursively parse all imports
 	var importedManifests []*Manifest
 	for _, importPath := range importPaths {
-		imported, nestedImports, err := parseManifestWithImports(importPath, visited)
+		imported, nestedImports, err := parseManifestWithImports(importPath, root, visited)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -71,13 +91,22 @@ func parseManifestWithImports(path string, visited map[string]bool) (*Manifest,
 }
 
 // resolveImports expands glob patterns and resolves relative paths
-func resolveImports(baseDir string, imports []string) ([]string, error) {
+func resolveImports(baseDir string, root string, imports []string) ([]string, error) {
 	var resolved []string
 	seen := make(map[string]bool)
 
 	for _, importPattern := range imports {
-		// Make path absolute relative to base directory
+		// Fetch remote imports into the cache; they then behave like local paths
 		pattern := importPattern
+		if isRemoteImport(importPattern) {
+			local, err := resolveRemoteImport(importPattern, root)
+			if err != nil {
+				return nil, err
+			}
+			pattern = local
+		}
+
+		// Make path absolute relative to base directory
 		if !filepath.IsAbs(pattern) {
 			pattern = filepath.Join(baseDir, pattern)
 		}
@@ -127,7 +156,7 @@ func detectCircularDependency(path string, visited map[string]bool) error {
 func ParseManifest(path string) (*Manifest, error) {
 	// Parse main manifest and any imports
 	visited := make(map[string]bool)
-	mainManifest, importedManifests, err := parseManifestWithImports(path, visited)
+	mainManifest, importedManifests, err := parseManifestWithImports(path, ".", visited)
 	if err != nil {
 		return nil, err
 	}
@@ -144,13 +173,23 @@ func ParseManifest(path string) (*Manifest, error) {
 		}
 	}
 
+	if err := applyAdapters(manifest, "."); err != nil {
+		return nil, err
+	}
+
+	// Fill tasks from their templates before defaults, so template values
+	// take precedence over manifest-level defaults
+	if err := applyTaskTemplates(manifest); err != nil {
+		return nil, err
+	}
+
 	// Apply defaults to tasks
 	applyDefaults(manifest)
 
 	return manifest, nil
 }
 
-// resolveResourceFiles reads file-based resources and populates their Content field.
+// resolveResourceFiles reads file-based resources and prompt partials and populates their Content field.
 // File paths are resolved relative to the given base directory (the YAML file's dir).
 func resolveResourceFiles(manifest *Manifest, baseDir string) error {
 	for name, resource := range manifest.Resources {
@@ -172,6 +211,26 @@ func resolveResourceFiles(manifest *Manifest, baseDir string) error {
 		resource.File = ""
 		manifest.Resources[name] = resource
 	}
+
+	for name, partial := range manifest.PromptPartials {
+		if partial.File == "" {
+			continue
+		}
+
+		filePath := partial.File
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(baseDir, filePath)
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("prompt partial '%s': failed to read file %s: %w", name, filePath, err)
+		}
+
+		partial.Content = string(data)
+		partial.File = ""
+		manifest.PromptPartials[name] = partial
+	}
 	return nil
 }
 
@@ -189,6 +248,16 @@ func applyDefaults(manifest *Manifest) {
 			task.Timeout = manifest.Defaults.Timeout
 		}
 
+		// Apply default max timeout if not set
+		if task.MaxTimeout == 0 {
+			task.MaxTimeout = manifest.Defaults.MaxTimeout
+		}
+
+		// Apply default latency budget if not set
+		if task.LatencyBudget == 0 {
+			task.LatencyBudget = manifest.Defaults.LatencyBudget
+		}
+
 		// Apply default shell if not set
 		if task.Shell == "" && manifest.Defaults.Shell != "" {
 			task.Shell = manifest.Defaults.Shell
@@ -206,6 +275,18 @@ func applyDefaults(manifest *Manifest) {
 			}
 		}
 
+		// A task's env_policy replaces the default one
+		if task.EnvPolicy == nil {
+			task.EnvPolicy = manifest.Defaults.EnvPolicy
+		}
+
+		// Redact default patterns in addition to the task's own
+		for _, pattern := range manifest.Defaults.Redact {
+			if !slices.Contains(task.Redact, pattern) {
+				task.Redact = append(task.Redact, pattern)
+			}
+		}
+
 		// Update the task in the map
 		manifest.Tasks[taskName] = task
 	}
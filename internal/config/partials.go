@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// PartialRefPattern matches {{partial "name"}} includes in prompt templates.
+// The submatch is the partial name.
+var PartialRefPattern = regexp.MustCompile(`\{\{-?\s*partial\s+"([^"]+)"`)
+
+// validatePromptPartials checks each partial's content and that inline
+// prompts, resources, partials, and server instructions only include defined
+// partials. Prompts with a file are read at request time and not checked.
+func validatePromptPartials(manifest *Manifest) []string {
+	var errors []string
+
+	names := make([]string, 0, len(manifest.PromptPartials))
+	for name := range manifest.PromptPartials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		partial := manifest.PromptPartials[name]
+		if partial.Content == "" && partial.File == "" {
+			errors = append(errors, fmt.Sprintf("prompt partial '%s': either content or file is required", name))
+		}
+		if partial.Content != "" && partial.File != "" {
+			errors = append(errors, fmt.Sprintf("prompt partial '%s': content and file are mutually exclusive", name))
+		}
+		errors = append(errors, checkPartialRefs(fmt.Sprintf("prompt partial '%s'", name), partial.Content, manifest.PromptPartials)...)
+	}
+	for name, prompt := range manifest.Prompts {
+		errors = append(errors, checkPartialRefs(fmt.Sprintf("prompt '%s'", name), prompt.Content, manifest.PromptPartials)...)
+		for _, content := range prompt.ContentByLocale {
+			errors = append(errors, checkPartialRefs(fmt.Sprintf("prompt '%s'", name), content, manifest.PromptPartials)...)
+		}
+	}
+	for name, resource := range manifest.Resources {
+		errors = append(errors, checkPartialRefs(fmt.Sprintf("resource '%s'", name), resource.Content, manifest.PromptPartials)...)
+	}
+	errors = append(errors, checkPartialRefs("server instructions", manifest.Server.Instructions, manifest.PromptPartials)...)
+
+	return errors
+}
+
+// checkPartialRefs reports includes in content of partials that are not defined.
+func checkPartialRefs(owner string, content string, partials map[string]PromptPartial) []string {
+	var errors []string
+	for _, m := range PartialRefPattern.FindAllStringSubmatch(content, -1) {
+		if _, exists := partials[m[1]]; !exists {
+			errors = append(errors, fmt.Sprintf("%s: includes undefined partial '%s'", owner, m[1]))
+		}
+	}
+	return errors
+}
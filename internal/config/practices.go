@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// detachPattern matches daemon commands that put the real process in the
+// background, out of reach of the process group a stop signals.
+var detachPattern = regexp.MustCompile(`(^|[^&])&\s*$|\bnohup\b|\bsetsid\b|\bdisown\b|\bdocker\s+(compose\s+up|run|start)\b.*\s(-d|--detach)\b|--daemon(ize)?\b`)
+
+// LintConfig returns every lint warning for manifest: the command quoting
+// checks of LintManifest and the best-practice checks of LintPractices.
+func LintConfig(manifest *Manifest) []string {
+	warnings := append(LintManifest(manifest), LintPractices(manifest)...)
+	sort.Strings(warnings)
+	return warnings
+}
+
+// LintPractices returns warnings about config that is valid but likely to
+// cause trouble: descriptions that only repeat a name, oneshot tasks
+// without a timeout, parameters a task never reads, daemons whose command
+// detaches so a stop cannot reach it, tasks that run the same command, and
+// task groups that list disabled tasks.
+func LintPractices(manifest *Manifest) []string {
+	var warnings []string
+	commands := make(map[string][]string)
+	for name, task := range manifest.Tasks {
+		if sameName(task.Description, name) {
+			warnings = append(warnings, fmt.Sprintf("task '%s': description only repeats the task name; say what it does", name))
+		}
+		for paramName, param := range task.Parameters {
+			if sameName(param.Description, paramName) {
+				warnings = append(warnings, fmt.Sprintf("task '%s': parameter '%s': description only repeats the parameter name", name, paramName))
+			}
+		}
+		if task.Type == TaskTypeOneShot && task.Command != "" && task.Timeout == 0 {
+			warnings = append(warnings, fmt.Sprintf("task '%s': no timeout, so a run that hangs never ends; set timeout or defaults.timeout", name))
+		}
+		for _, param := range unusedParams(task) {
+			warnings = append(warnings, fmt.Sprintf("task '%s': parameter '%s' is never used by the command", name, param))
+		}
+		if task.Type == TaskTypeDaemon && detachPattern.MatchString(task.Command) {
+			warnings = append(warnings, fmt.Sprintf("task '%s': command detaches from the daemon process, so %s cannot stop what it started; run it in the foreground",
+				name, manifest.ToolName(ToolStop, name)))
+		}
+		if task.Command != "" && !task.Disabled {
+			key := task.Shell + "\x00" + task.WorkingDirectory + "\x00" + strings.Join(strings.Fields(task.Command), " ")
+			commands[key] = append(commands[key], name)
+		}
+	}
+	for _, names := range commands {
+		if len(names) > 1 {
+			sort.Strings(names)
+			warnings = append(warnings, fmt.Sprintf("tasks '%s' run the same command; keep one", strings.Join(names, "', '")))
+		}
+	}
+
+	for name, workflow := range manifest.Workflows {
+		if sameName(workflow.Description, name) {
+			warnings = append(warnings, fmt.Sprintf("workflow '%s': description only repeats the workflow name; say what it does", name))
+		}
+	}
+
+	for name, group := range manifest.TaskGroups {
+		for _, taskName := range group.Tasks {
+			if task, exists := manifest.Tasks[taskName]; exists && task.Disabled {
+				warnings = append(warnings, fmt.Sprintf("task_group '%s': task '%s' is disabled", name, taskName))
+			}
+		}
+	}
+
+	sort.Strings(warnings)
+	return warnings
+}
+
+// sameName reports whether description is just name, ignoring case and
+// separators.
+func sameName(description, name string) bool {
+	normalize := strings.NewReplacer("_", "", "-", "", " ", "", ".", "")
+	return description != "" && strings.EqualFold(normalize.Replace(description), normalize.Replace(name))
+}
+
+// unusedParams returns the parameters of task, sorted, that neither its
+// command nor its preconditions refer to. Commands that include a partial
+// with {{template}} may use any of them, so none are reported; nor are
+// stdin parameters, which reach the command without a reference.
+func unusedParams(task Task) []string {
+	if task.Command == "" || len(task.Parameters) == 0 {
+		return nil
+	}
+	texts := []string{task.Command}
+	for _, check := range task.Preconditions {
+		texts = append(texts, check.Command, check.FileExists)
+	}
+	used := make(map[string]bool)
+	for _, text := range texts {
+		if !templateRefs(text, used) {
+			return nil
+		}
+	}
+
+	var unused []string
+	for name, param := range task.Parameters {
+		switch {
+		case param.Source == ParamSourceStdin:
+		case param.Source == ParamSourceFile && used[name+"_path"]:
+		case !used[name]:
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// templateRefs adds the fields and string constants text refers to to
+// used. It returns false when text cannot be parsed or includes another
+// template, so its references are unknown.
+func templateRefs(text string, used map[string]bool) bool {
+	if text == "" {
+		return true
+	}
+	tree := parse.New("lint")
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(text, "", "", map[string]*parse.Tree{}); err != nil {
+		return false
+	}
+	return collectRefs(tree.Root, used)
+}
+
+// collectRefs adds the references under node to used, as templateRefs
+// does.
+func collectRefs(node parse.Node, used map[string]bool) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, child := range n.Nodes {
+			if !collectRefs(child, used) {
+				return false
+			}
+		}
+	case *parse.TemplateNode:
+		return false
+	case *parse.ActionNode:
+		return collectRefs(n.Pipe, used)
+	case *parse.IfNode:
+		return collectRefs(n.Pipe, used) && collectRefs(n.List, used) && collectRefs(n.ElseList, used)
+	case *parse.RangeNode:
+		return collectRefs(n.Pipe, used) && collectRefs(n.List, used) && collectRefs(n.ElseList, used)
+	case *parse.WithNode:
+		return collectRefs(n.Pipe, used) && collectRefs(n.List, used) && collectRefs(n.ElseList, used)
+	case *parse.PipeNode:
+		if n == nil {
+			return true
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if !collectRefs(arg, used) {
+					return false
+				}
+			}
+		}
+	case *parse.FieldNode:
+		used[n.Ident[0]] = true
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			used[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		return collectRefs(n.Node, used)
+	case *parse.StringNode:
+		used[n.Text] = true
+	}
+	return true
+}
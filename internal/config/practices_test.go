@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintPractices(t *testing.T) {
+	str := Param{Type: "string", Description: "A value"}
+	tests := []struct {
+		name     string
+		manifest *Manifest
+		want     []string // Substrings, one per expected warning
+	}{
+		{
+			name: "clean",
+			manifest: &Manifest{Tasks: map[string]Task{
+				"build": {Description: "Build the binary", Command: "go build {{.pkg | shellquote}}", Type: TaskTypeOneShot, Timeout: 60,
+					Parameters: map[string]Param{"pkg": str}},
+				"dev": {Description: "Run the dev server", Command: "go run ./cmd/server", Type: TaskTypeDaemon},
+			}},
+		},
+		{
+			name: "description repeats the name",
+			manifest: &Manifest{Tasks: map[string]Task{
+				"unit_test": {Description: "Unit test", Command: "go test", Type: TaskTypeOneShot, Timeout: 60},
+			}},
+			want: []string{"task 'unit_test': description only repeats the task name"},
+		},
+		{
+			name: "no timeout",
+			manifest: &Manifest{Tasks: map[string]Task{
+				"build": {Description: "Build the binary", Command: "go build", Type: TaskTypeOneShot},
+			}},
+			want: []string{"task 'build': no timeout"},
+		},
+		{
+			name: "unused parameter",
+			manifest: &Manifest{Tasks: map[string]Task{
+				"build": {Description: "Build the binary", Command: `go build {{index . "pkg" | shellquote}}`, Type: TaskTypeOneShot, Timeout: 60,
+					Parameters: map[string]Param{"pkg": str, "tags": str, "patch": {Type: "string", Description: "Diff", Source: ParamSourceFile}}},
+			}},
+			want: []string{"parameter 'patch' is never used", "parameter 'tags' is never used"},
+		},
+		{
+			name: "parameter used in a precondition or a partial",
+			manifest: &Manifest{Tasks: map[string]Task{
+				"deploy": {Description: "Deploy a build", Command: "./deploy.sh", Type: TaskTypeOneShot, Timeout: 60,
+					Parameters:    map[string]Param{"dir": str},
+					Preconditions: []Precondition{{FileExists: "{{.dir}}/app"}}},
+				"lint": {Description: "Lint the code", Command: `{{template "lint" .}}`, Type: TaskTypeOneShot, Timeout: 60,
+					Parameters: map[string]Param{"fix": str}},
+			}},
+		},
+		{
+			name: "daemon that detaches",
+			manifest: &Manifest{Tasks: map[string]Task{
+				"db": {Description: "Run the database", Command: "docker run -d postgres", Type: TaskTypeDaemon},
+			}},
+			want: []string{"task 'db': command detaches from the daemon process, so stop_db cannot stop"},
+		},
+		{
+			name: "duplicate commands",
+			manifest: &Manifest{Tasks: map[string]Task{
+				"test":  {Description: "Run the tests", Command: "go test ./...", Type: TaskTypeOneShot, Timeout: 60},
+				"check": {Description: "Check everything", Command: "go  test ./...", Type: TaskTypeOneShot, Timeout: 60},
+			}},
+			want: []string{"tasks 'check', 'test' run the same command"},
+		},
+		{
+			name: "group lists a disabled task",
+			manifest: &Manifest{
+				Tasks: map[string]Task{
+					"test": {Description: "Run the tests", Command: "go test ./...", Type: TaskTypeOneShot, Timeout: 60, Disabled: true},
+				},
+				TaskGroups: map[string]TaskGroup{"ci": {Description: "CI", Tasks: []string{"test"}}},
+			},
+			want: []string{"task_group 'ci': task 'test' is disabled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LintPractices(tt.manifest)
+			if len(got) != len(tt.want) {
+				t.Fatalf("LintPractices() = %q, want %d warnings", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
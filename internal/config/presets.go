@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PresetParam is the run_ tool argument, and --preset the CLI flag, that
+// picks one of a task's parameter_presets.
+const PresetParam = "preset"
+
+// PresetNames returns the names of a task's parameter presets, sorted.
+func (t Task) PresetNames() []string {
+	names := make([]string, 0, len(t.ParameterPresets))
+	for name := range t.ParameterPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyPreset fills params with the values of the named preset. Parameters
+// already in params are kept, so explicit arguments override the preset.
+func ApplyPreset(task Task, preset string, params map[string]interface{}) error {
+	values, ok := task.ParameterPresets[preset]
+	if !ok {
+		if len(task.ParameterPresets) == 0 {
+			return fmt.Errorf("unknown preset '%s': the task has no parameter_presets", preset)
+		}
+		return fmt.Errorf("unknown preset '%s' (available: %s)", preset, strings.Join(task.PresetNames(), ", "))
+	}
+	for name, value := range values {
+		if _, given := params[name]; !given {
+			params[name] = value
+		}
+	}
+	return nil
+}
+
+// validatePresets checks that every preset only sets the task's own
+// parameters, and that no parameter is named like the preset argument.
+func validatePresets(name string, task Task) []string {
+	if len(task.ParameterPresets) == 0 {
+		return nil
+	}
+	var errors []string
+	if _, clash := task.Parameters[PresetParam]; clash {
+		errors = append(errors, fmt.Sprintf("task '%s': parameter '%s' conflicts with parameter_presets", name, PresetParam))
+	}
+	for _, preset := range task.PresetNames() {
+		values := task.ParameterPresets[preset]
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, defined := task.Parameters[key]; !defined {
+				errors = append(errors, fmt.Sprintf("task '%s': preset '%s' sets undefined parameter '%s'", name, preset, key))
+			}
+		}
+	}
+	return errors
+}
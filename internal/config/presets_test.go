@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyPreset(t *testing.T) {
+	task := Task{
+		Parameters: map[string]Param{
+			"flags": {Type: "string", Description: "Flags"},
+			"path":  {Type: "string", Description: "Packages"},
+		},
+		ParameterPresets: map[string]map[string]string{
+			"quick": {"flags": "-short", "path": "./internal/..."},
+			"full":  {"flags": "-race"},
+		},
+	}
+
+	params := map[string]interface{}{"path": "./cmd/..."}
+	if err := ApplyPreset(task, "quick", params); err != nil {
+		t.Fatalf("ApplyPreset failed: %v", err)
+	}
+	if params["flags"] != "-short" || params["path"] != "./cmd/..." {
+		t.Errorf("expected preset flags and the explicit path, got %v", params)
+	}
+
+	err := ApplyPreset(task, "slow", map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "available: full, quick") {
+		t.Errorf("expected unknown preset error listing presets, got %v", err)
+	}
+}
+
+func TestValidatePresets(t *testing.T) {
+	task := Task{
+		Parameters: map[string]Param{
+			"flags":  {Type: "string", Description: "Flags"},
+			"preset": {Type: "string", Description: "Clashes"},
+		},
+		ParameterPresets: map[string]map[string]string{
+			"quick": {"flags": "-short", "path": "./internal/..."},
+		},
+	}
+	errors := strings.Join(validatePresets("test", task), "; ")
+	for _, want := range []string{"parameter 'preset' conflicts", "preset 'quick' sets undefined parameter 'path'"} {
+		if !strings.Contains(errors, want) {
+			t.Errorf("expected %q in %q", want, errors)
+		}
+	}
+}
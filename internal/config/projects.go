@@ -0,0 +1,176 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+// unsafeNameChars matches the characters replaced in the project part of a
+// hidden step task's name.
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// resolveProjectReferences replaces tasks and workflow steps that reference a
+// sibling project with the referenced task definitions. Project paths are
+// resolved relative to rootDir and must be listed in allowed_projects.
+// References are only resolved on a multi-project server: when the manifest
+// sets server.projects, or when it is itself a hosted or referenced project
+// (hosted).
+//
+// A referencing task keeps its own name, description, visibility flags, and
+// requires_daemon; everything else comes from the sibling project, with the
+// working directory resolved against that project's root. Workflow steps that
+// reference a project run a hidden task named by projectStepTaskName.
+//
+// visiting holds the absolute roots of projects currently being loaded, to
+// detect projects that reference each other.
+func resolveProjectReferences(manifest *Manifest, rootDir string, visiting map[string]bool, hosted bool) error {
+	allowed := make(map[string]bool)
+	for _, p := range manifest.AllowedProjects {
+		allowed[filepath.Clean(p)] = true
+	}
+
+	type project struct {
+		manifest *Manifest
+		root     string
+	}
+	loaded := make(map[string]project)
+
+	load := func(path string) (project, error) {
+		if p, ok := loaded[path]; ok {
+			return p, nil
+		}
+		if !hosted && len(manifest.Server.Projects) == 0 {
+			return project{}, fmt.Errorf("project '%s' can only be referenced on a multi-project server (set server.projects)", path)
+		}
+		if !allowed[filepath.Clean(path)] {
+			return project{}, fmt.Errorf("project '%s' is not listed in allowed_projects", path)
+		}
+		root := path
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(rootDir, root)
+		}
+		root, err := filepath.Abs(root)
+		if err != nil {
+			return project{}, fmt.Errorf("failed to resolve project '%s': %w", path, err)
+		}
+		m, err := loadProject(root, visiting)
+		if err != nil {
+			return project{}, fmt.Errorf("project '%s': %w", path, err)
+		}
+		p := project{manifest: m, root: root}
+		loaded[path] = p
+		return p, nil
+	}
+
+	for name, task := range manifest.Tasks {
+		if task.Project == "" {
+			if task.ProjectTask != "" {
+				return fmt.Errorf("task '%s': task reference requires project", name)
+			}
+			continue
+		}
+		if task.Command != "" {
+			return fmt.Errorf("task '%s': command cannot be set on a project reference", name)
+		}
+
+		p, err := load(task.Project)
+		if err != nil {
+			return fmt.Errorf("task '%s': %w", name, err)
+		}
+		remoteName := task.ProjectTask
+		if remoteName == "" {
+			remoteName = name
+		}
+		remote, exists := p.manifest.Tasks[remoteName]
+		if !exists {
+			return fmt.Errorf("task '%s': task '%s' not found in project '%s'", name, remoteName, task.Project)
+		}
+		manifest.Tasks[name] = projectTask(task, remote, p.root)
+	}
+
+	for workflowName, workflow := range manifest.Workflows {
+		for i, step := range workflow.Steps {
+			if step.Project == "" || step.Workflow != "" {
+				continue
+			}
+
+			p, err := load(step.Project)
+			if err != nil {
+				return fmt.Errorf("workflow '%s': step %d: %w", workflowName, i, err)
+			}
+			remote, exists := p.manifest.Tasks[step.Task]
+			if !exists {
+				return fmt.Errorf("workflow '%s': step %d: task '%s' not found in project '%s'", workflowName, i, step.Task, step.Project)
+			}
+
+			hiddenName := projectStepTaskName(p.root, step.Task)
+			if _, exists := manifest.Tasks[hiddenName]; !exists {
+				manifest.Tasks[hiddenName] = projectTask(Task{Disabled: true}, remote, p.root)
+			}
+			workflow.Steps[i].ID = step.Name()
+			workflow.Steps[i].Task = hiddenName
+			workflow.Steps[i].Project = ""
+		}
+	}
+
+	return nil
+}
+
+// projectStepTaskName returns the name of the hidden task a workflow step
+// runs for task in the project rooted at root, e.g. "infra-1a2b3c4d__migrate".
+// Task names become log, PID, and event file names, so the project part is
+// the root's base name reduced to safe characters, plus a hash of the root
+// that keeps projects with the same base name apart.
+func projectStepTaskName(root, task string) string {
+	sum := sha256.Sum256([]byte(root))
+	base := unsafeNameChars.ReplaceAllString(filepath.Base(root), "-")
+	return base + "-" + hex.EncodeToString(sum[:4]) + ProjectSeparator + task
+}
+
+// projectTask builds the local task for a reference to remote, a task defined
+// in the project rooted at root.
+func projectTask(local, remote Task, root string) Task {
+	task := remote
+
+	switch {
+	case remote.WorkingDirectory == "":
+		task.WorkingDirectory = root
+	case !filepath.IsAbs(remote.WorkingDirectory):
+		task.WorkingDirectory = filepath.Join(root, remote.WorkingDirectory)
+	}
+
+	if local.Description != "" {
+		task.Description = local.Description
+	}
+	// The remote task's dependencies name tasks in its own project
+	task.DependsOn = nil
+	task.RequiresDaemon = local.RequiresDaemon
+	task.DisableMCP = local.DisableMCP
+	task.Disabled = local.Disabled
+	task.Project = ""
+	task.ProjectTask = ""
+	return task
+}
+
+// loadProject loads the .runbook/ directory of the project at root.
+func loadProject(root string, visiting map[string]bool) (*Manifest, error) {
+	if visiting[root] {
+		return nil, fmt.Errorf("circular project reference to %s", root)
+	}
+	visiting[root] = true
+	defer delete(visiting, root)
+
+	manifest, err := loadDirectory(filepath.Join(root, dirs.ConfigDir), root, visiting, true)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("no %s/ config found in %s", dirs.ConfigDir, root)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+const infraTasks = `version: "1.0"
+tasks:
+  start-db:
+    description: "Start the database"
+    command: "docker compose up db"
+    type: daemon
+    working_directory: "docker"
+  migrate:
+    description: "Run migrations"
+    command: "make migrate"
+    type: oneshot
+`
+
+// setupProjects creates sibling app/ and infra/ projects under a temp dir,
+// changes into app/, and returns the absolute infra root.
+func setupProjects(t *testing.T, appTasks string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	writeProjectConfig(t, filepath.Join(tmpDir, "infra"), infraTasks)
+	writeProjectConfig(t, filepath.Join(tmpDir, "app"), appTasks)
+
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, filepath.Join(tmpDir, "app"))
+
+	infraRoot, err := filepath.Abs(filepath.Join("..", "infra"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return infraRoot
+}
+
+func writeProjectConfig(t *testing.T, root, content string) {
+	t.Helper()
+	configDir := filepath.Join(root, dirs.ConfigDir)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "tasks.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectTaskReference(t *testing.T) {
+	infraRoot := setupProjects(t, `version: "1.0"
+server:
+  projects:
+    infra: ../infra
+allowed_projects:
+  - ../infra
+tasks:
+  db:
+    description: "Shared database"
+    project: ../infra
+    task: start-db
+  test:
+    description: "Integration tests"
+    command: "go test ./..."
+    requires_daemon: [db]
+`)
+
+	manifest, loaded, err := LoadManifest("")
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if !loaded {
+		t.Fatal("expected config to be loaded")
+	}
+
+	db := manifest.Tasks["db"]
+	if db.Command != "docker compose up db" {
+		t.Errorf("expected command from infra project, got %q", db.Command)
+	}
+	if db.Type != TaskTypeDaemon {
+		t.Errorf("expected daemon type from infra project, got %q", db.Type)
+	}
+	if db.Description != "Shared database" {
+		t.Errorf("expected local description to win, got %q", db.Description)
+	}
+	if want := filepath.Join(infraRoot, "docker"); db.WorkingDirectory != want {
+		t.Errorf("expected working directory %q, got %q", want, db.WorkingDirectory)
+	}
+}
+
+func TestProjectWorkflowStep(t *testing.T) {
+	infraRoot := setupProjects(t, `version: "1.0"
+server:
+  projects:
+    infra: ../infra
+allowed_projects: [../infra]
+tasks:
+  test:
+    description: "Integration tests"
+    command: "go test ./..."
+workflows:
+  ci:
+    description: "CI"
+    steps:
+      - project: ../infra
+        task: migrate
+      - task: test
+`)
+
+	manifest, _, err := LoadManifest("")
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+
+	// The relative project path must not leak into the hidden task's name,
+	// which names its log, PID, and event files
+	step := manifest.Workflows["ci"].Steps[0]
+	if step.Task != projectStepTaskName(infraRoot, "migrate") || !strings.HasPrefix(step.Task, "infra-") {
+		t.Fatalf("expected step to run hidden task, got %q", step.Task)
+	}
+	if strings.ContainsAny(step.Task, `/\:.`) {
+		t.Errorf("expected a path-safe hidden task name, got %q", step.Task)
+	}
+	if step.ID != "migrate" {
+		t.Errorf("expected the step to keep its name, got %q", step.ID)
+	}
+	hidden := manifest.Tasks[step.Task]
+	if hidden.Command != "make migrate" || hidden.WorkingDirectory != infraRoot {
+		t.Errorf("unexpected hidden task: %+v", hidden)
+	}
+	if !hidden.Disabled {
+		t.Error("expected hidden step task to be disabled")
+	}
+}
+
+func TestProjectReferenceErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		errorMsg string
+	}{
+		{
+			name: "not allowlisted",
+			config: `version: "1.0"
+server:
+  projects:
+    infra: ../infra
+tasks:
+  db:
+    project: ../infra
+    task: start-db
+`,
+			errorMsg: "not listed in allowed_projects",
+		},
+		{
+			name: "single-project manifest",
+			config: `version: "1.0"
+allowed_projects: [../infra]
+tasks:
+  db:
+    project: ../infra
+    task: start-db
+`,
+			errorMsg: "only be referenced on a multi-project server",
+		},
+		{
+			name: "missing task",
+			config: `version: "1.0"
+server:
+  projects:
+    infra: ../infra
+allowed_projects: [../infra]
+tasks:
+  db:
+    project: ../infra
+    task: start-cache
+`,
+			errorMsg: "task 'start-cache' not found in project '../infra'",
+		},
+		{
+			name: "command on reference",
+			config: `version: "1.0"
+server:
+  projects:
+    infra: ../infra
+allowed_projects: [../infra]
+tasks:
+  db:
+    project: ../infra
+    task: start-db
+    command: "echo"
+`,
+			errorMsg: "command cannot be set on a project reference",
+		},
+		{
+			name: "missing project config",
+			config: `version: "1.0"
+server:
+  projects:
+    infra: ../infra
+allowed_projects: [../nowhere]
+tasks:
+  db:
+    project: ../nowhere
+`,
+			errorMsg: "no .runbook/ config found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupProjects(t, tt.config)
+			_, _, err := LoadManifest("")
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestProjectReferenceCycle(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectConfig(t, filepath.Join(tmpDir, "a"), `version: "1.0"
+server:
+  projects:
+    b: ../b
+allowed_projects: [../b]
+tasks:
+  b-task:
+    project: ../b
+`)
+	writeProjectConfig(t, filepath.Join(tmpDir, "b"), `version: "1.0"
+allowed_projects: [../a]
+tasks:
+  b-task:
+    project: ../a
+    task: a-task
+`)
+
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, filepath.Join(tmpDir, "a"))
+
+	_, _, err := LoadManifest("")
+	if err == nil || !strings.Contains(err.Error(), "circular project reference") {
+		t.Errorf("expected circular project reference error, got: %v", err)
+	}
+}
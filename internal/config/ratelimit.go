@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window returns the rate limit's window, or 0 if per is not a valid
+// positive duration.
+func (r RateLimit) Window() time.Duration {
+	d, err := time.ParseDuration(r.Per)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// validateRateLimit checks a task's rate_limit block.
+func validateRateLimit(name string, task Task) []string {
+	if task.RateLimit == nil {
+		return nil
+	}
+	var errors []string
+	if task.RateLimit.MaxRuns < 1 {
+		errors = append(errors, fmt.Sprintf("task '%s': rate_limit.max_runs must be at least 1", name))
+	}
+	if task.RateLimit.Window() == 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': rate_limit.per must be a positive duration such as 30s or 1h, got '%s'", name, task.RateLimit.Per))
+	}
+	return errors
+}
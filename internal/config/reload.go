@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultReloadSignal is the signal a reload sends when reload_signal is
+// not set.
+const DefaultReloadSignal = "HUP"
+
+// reloadSignals are the signals reload_signal may name. Signals that stop a
+// process by design, such as TERM and KILL, are left out.
+var reloadSignals = []string{"HUP", "USR1", "USR2", "INT", "QUIT", "WINCH"}
+
+// ReloadSignalName returns the signal a reload of the task sends, without
+// its SIG prefix, e.g. "HUP".
+func (t Task) ReloadSignalName() string {
+	if t.ReloadSignal == "" {
+		return DefaultReloadSignal
+	}
+	return strings.TrimPrefix(strings.ToUpper(t.ReloadSignal), "SIG")
+}
+
+// validateReload checks a task's reload settings: only plain daemons can be
+// reloaded, with one of reloadSignals.
+func validateReload(name string, task Task) []string {
+	var errors []string
+	if task.Reload && task.Type != TaskTypeDaemon {
+		errors = append(errors, fmt.Sprintf("task '%s': reload is only supported on daemon tasks", name))
+	}
+	if task.ReloadSignal == "" {
+		return errors
+	}
+	if !task.Reload {
+		errors = append(errors, fmt.Sprintf("task '%s': reload_signal requires reload: true", name))
+	}
+	if !slices.Contains(reloadSignals, task.ReloadSignalName()) {
+		errors = append(errors, fmt.Sprintf("task '%s': invalid reload_signal '%s' (must be one of %s)", name, task.ReloadSignal, strings.Join(reloadSignals, ", ")))
+	}
+	return errors
+}
@@ -0,0 +1,329 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"runbookmcp.dev/internal/dirs"
+)
+
+// importCacheDir holds fetched remote imports, relative to the project
+// root.
+const importCacheDir = dirs.StateDir + "/imports"
+
+// maxImportSize bounds the content of an https:// import.
+const maxImportSize = 10 << 20
+
+// importFetchTimeout bounds an https:// import fetch and each git command
+// of a git:: import, so a stalled server cannot hang every config load.
+const importFetchTimeout = 30 * time.Second
+
+// httpClient fetches https:// imports. Tests replace it to trust a local
+// TLS server.
+var httpClient = &http.Client{Timeout: importFetchTimeout}
+
+// lockMu serializes reads and writes of the lockfile.
+var lockMu sync.Mutex
+
+// Lockfile is the contents of .runbook.lock. It pins every remote import so
+// later loads use the same content without going back to the network.
+type Lockfile struct {
+	Imports map[string]LockedImport `yaml:"imports"`
+}
+
+// LockedImport pins a single remote import.
+type LockedImport struct {
+	SHA256 string `yaml:"sha256,omitempty"` // Content hash of an https:// import
+	Commit string `yaml:"commit,omitempty"` // Resolved commit of a git:: import
+}
+
+// isRemoteImport reports whether an import refers to an https:// URL or a
+// git:: reference rather than a local path.
+func isRemoteImport(ref string) bool {
+	return strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "git::")
+}
+
+// resolveRemoteImport fetches (or reuses from cache) a remote import and
+// returns the local path to import. For git:: references the path may be a
+// glob within the checked-out repository. The lockfile and cache are those of
+// the project at root.
+func resolveRemoteImport(ref string, root string) (string, error) {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+
+	lockPath := filepath.Join(root, dirs.LockFile)
+	lock, err := readLockfile(lockPath)
+	if err != nil {
+		return "", err
+	}
+	locked, isLocked := lock.Imports[ref]
+
+	cacheDir := filepath.Join(root, importCacheDir)
+	var path string
+	var pinned LockedImport
+	if strings.HasPrefix(ref, "git::") {
+		path, pinned, err = fetchGitImport(cacheDir, ref, locked.Commit)
+	} else {
+		path, pinned, err = fetchHTTPSImport(cacheDir, ref, locked.SHA256)
+	}
+	if err != nil {
+		return "", fmt.Errorf("remote import '%s': %w", ref, err)
+	}
+
+	if !isLocked || locked != pinned {
+		lock.Imports[ref] = pinned
+		if err := writeLockfile(lockPath, lock); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Abs(path)
+}
+
+// fetchHTTPSImport returns the path of an https:// import cached under
+// importCache. When sha is set, a cached copy with that hash is used as-is,
+// and freshly fetched content must match it.
+func fetchHTTPSImport(importCache string, url string, sha string) (string, LockedImport, error) {
+	cacheDir := filepath.Join(importCache, "https")
+	if sha != "" {
+		cached := filepath.Join(cacheDir, sha+".yaml")
+		if _, err := os.Stat(cached); err == nil {
+			return cached, LockedImport{SHA256: sha}, nil
+		}
+	}
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", LockedImport{}, fmt.Errorf("fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", LockedImport{}, fmt.Errorf("fetch failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportSize+1))
+	if err != nil {
+		return "", LockedImport{}, fmt.Errorf("fetch failed: %w", err)
+	}
+	if len(data) > maxImportSize {
+		return "", LockedImport{}, fmt.Errorf("content exceeds %d bytes", maxImportSize)
+	}
+
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+	if sha != "" && got != sha {
+		return "", LockedImport{}, fmt.Errorf("content does not match %s (locked sha256 %s, got %s); run 'runbook update-imports' to accept the change", dirs.LockFile, sha, got)
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", LockedImport{}, fmt.Errorf("failed to create import cache: %w", err)
+	}
+	cached := filepath.Join(cacheDir, got+".yaml")
+	if err := os.WriteFile(cached, data, 0644); err != nil {
+		return "", LockedImport{}, fmt.Errorf("failed to cache import: %w", err)
+	}
+	return cached, LockedImport{SHA256: got}, nil
+}
+
+// parseGitImport splits git::<repo>[//<path>][?ref=<ref>] into its parts.
+// The path defaults to every *.yaml file at the repository root.
+func parseGitImport(ref string) (repo, subpath, gitRef string, err error) {
+	s := strings.TrimPrefix(ref, "git::")
+	if i := strings.LastIndex(s, "?"); i >= 0 {
+		query := s[i+1:]
+		s = s[:i]
+		for _, kv := range strings.Split(query, "&") {
+			key, value, _ := strings.Cut(kv, "=")
+			if key != "ref" {
+				return "", "", "", fmt.Errorf("unsupported query parameter '%s'", key)
+			}
+			gitRef = value
+		}
+	}
+
+	// The first "//" after the scheme separates the repository from the path
+	start := 0
+	if i := strings.Index(s, "://"); i >= 0 {
+		start = i + 3
+	}
+	repo = s
+	if j := strings.Index(s[start:], "//"); j >= 0 {
+		repo = s[:start+j]
+		subpath = s[start+j+2:]
+	}
+	if repo == "" {
+		return "", "", "", fmt.Errorf("missing repository")
+	}
+	if subpath == "" {
+		subpath = "*.yaml"
+	}
+	// The path is joined to the checkout, so it must stay inside it
+	if filepath.IsAbs(subpath) || slices.Contains(strings.Split(filepath.ToSlash(subpath), "/"), "..") {
+		return "", "", "", fmt.Errorf("path '%s' must stay inside the repository", subpath)
+	}
+	return repo, subpath, gitRef, nil
+}
+
+// fetchGitImport checks out a git:: import into importCache and returns the
+// path of the imported file(s) inside it. When commit is set that commit is
+// checked out; otherwise the ref (or default branch) is resolved and pinned.
+func fetchGitImport(importCache string, ref string, commit string) (string, LockedImport, error) {
+	repo, subpath, gitRef, err := parseGitImport(ref)
+	if err != nil {
+		return "", LockedImport{}, err
+	}
+
+	repoSum := sha256.Sum256([]byte(repo))
+	repoKey := hex.EncodeToString(repoSum[:8])
+	cacheDir := filepath.Join(importCache, "git")
+	checkoutPath := func(c string) string {
+		return filepath.Join(cacheDir, repoKey+"-"+c)
+	}
+
+	if commit != "" {
+		// The commit comes from the lockfile and names a cache directory
+		// and a git argument, so it must be nothing but a hash
+		if !isCommitHash(commit) {
+			return "", LockedImport{}, fmt.Errorf("invalid commit %q pinned for %s in %s", commit, ref, dirs.LockFile)
+		}
+		if _, err := os.Stat(checkoutPath(commit)); err == nil {
+			return filepath.Join(checkoutPath(commit), subpath), LockedImport{Commit: commit}, nil
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", LockedImport{}, fmt.Errorf("failed to create import cache: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(cacheDir, "clone-")
+	if err != nil {
+		return "", LockedImport{}, fmt.Errorf("failed to create import cache: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if commit != "" {
+		if err := runGit("", "clone", "--quiet", "--no-checkout", "--", repo, tmpDir); err != nil {
+			return "", LockedImport{}, err
+		}
+		if err := runGit(tmpDir, "checkout", "--quiet", commit); err != nil {
+			return "", LockedImport{}, err
+		}
+	} else {
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if gitRef != "" {
+			args = append(args, "--branch", gitRef)
+		}
+		if err := runGit("", append(args, "--", repo, tmpDir)...); err != nil {
+			return "", LockedImport{}, err
+		}
+		out, err := gitOutput(tmpDir, "rev-parse", "HEAD")
+		if err != nil {
+			return "", LockedImport{}, err
+		}
+		if !isCommitHash(out) {
+			return "", LockedImport{}, fmt.Errorf("git rev-parse returned %q, not a commit hash", out)
+		}
+		commit = out
+	}
+
+	dest := checkoutPath(commit)
+	if _, err := os.Stat(dest); err != nil {
+		if err := os.Rename(tmpDir, dest); err != nil {
+			return "", LockedImport{}, fmt.Errorf("failed to cache import: %w", err)
+		}
+	}
+	return filepath.Join(dest, subpath), LockedImport{Commit: commit}, nil
+}
+
+// isCommitHash reports whether s is a full SHA-1 or SHA-256 commit hash.
+func isCommitHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// runGit runs a git command, including its output in the error on failure.
+func runGit(dir string, args ...string) error {
+	_, err := gitOutput(dir, args...)
+	return err
+}
+
+// gitOutput runs a git command and returns its trimmed output, including
+// the output in the error on failure. The command gets importFetchTimeout
+// and may not prompt for credentials, so an unreachable or private
+// repository fails the load instead of hanging it.
+func gitOutput(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), importFetchTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("git %s timed out after %s", args[0], importFetchTimeout)
+		}
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// readLockfile reads the lockfile at path. A missing file yields an empty
+// lockfile.
+func readLockfile(path string) (*Lockfile, error) {
+	lock := &Lockfile{Imports: make(map[string]LockedImport)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if lock.Imports == nil {
+		lock.Imports = make(map[string]LockedImport)
+	}
+	return lock, nil
+}
+
+// writeLockfile writes lock to path.
+func writeLockfile(path string, lock *Lockfile) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	header := "# Generated by runbook. Pins remote imports; commit this file.\n"
+	if err := os.WriteFile(path, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// UpdateRemoteImports discards the lockfile and cached remote imports of the
+// project at root so the next load fetches the latest content and re-pins it.
+func UpdateRemoteImports(root string) error {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+
+	lockPath := filepath.Join(root, dirs.LockFile)
+	if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", lockPath, err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, importCacheDir)); err != nil {
+		return fmt.Errorf("failed to clear import cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+func TestParseGitImport(t *testing.T) {
+	tests := []struct {
+		ref     string
+		repo    string
+		subpath string
+		gitRef  string
+	}{
+		{"git::https://github.com/org/lib.git", "https://github.com/org/lib.git", "*.yaml", ""},
+		{"git::https://github.com/org/lib.git//go/tasks.yaml?ref=v1.2.0", "https://github.com/org/lib.git", "go/tasks.yaml", "v1.2.0"},
+		{"git::git@github.com:org/lib.git//tasks.yaml", "git@github.com:org/lib.git", "tasks.yaml", ""},
+		{"git::file:///srv/lib//*.yaml?ref=main", "file:///srv/lib", "*.yaml", "main"},
+	}
+	for _, tt := range tests {
+		repo, subpath, gitRef, err := parseGitImport(tt.ref)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.ref, err)
+			continue
+		}
+		if repo != tt.repo || subpath != tt.subpath || gitRef != tt.gitRef {
+			t.Errorf("%s: got (%q, %q, %q), want (%q, %q, %q)", tt.ref, repo, subpath, gitRef, tt.repo, tt.subpath, tt.gitRef)
+		}
+	}
+
+	if _, _, _, err := parseGitImport("git::https://github.com/org/lib.git?depth=1"); err == nil {
+		t.Error("expected error for unsupported query parameter")
+	}
+	for _, ref := range []string{
+		"git::https://github.com/org/lib.git//../../etc/*.yaml",
+		"git::https://github.com/org/lib.git//go/../../tasks.yaml",
+		"git::https://github.com/org/lib.git///etc/tasks.yaml",
+	} {
+		if _, _, _, err := parseGitImport(ref); err == nil {
+			t.Errorf("%s: expected error for a path outside the repository", ref)
+		}
+	}
+}
+
+// writeImportingManifest writes runbook.yaml importing ref into the current
+// directory and returns its path.
+func writeImportingManifest(t *testing.T, ref string) string {
+	t.Helper()
+	content := "version: \"1.0\"\nimports:\n  - \"" + ref + "\"\ntasks: {}\n"
+	if err := os.WriteFile("runbook.yaml", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return "runbook.yaml"
+}
+
+func TestRemoteImportHTTPS(t *testing.T) {
+	var mu sync.Mutex
+	body := "version: \"1.0\"\ntasks:\n  lint:\n    description: \"Lint\"\n    command: \"golangci-lint run\"\n"
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	origClient := httpClient
+	httpClient = ts.Client()
+	t.Cleanup(func() { httpClient = origClient })
+
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, t.TempDir())
+
+	path := writeImportingManifest(t, ts.URL+"/go.yaml")
+	manifest, err := ParseManifest(path)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.Tasks["lint"].Command != "golangci-lint run" {
+		t.Fatalf("expected lint task from remote import, got %+v", manifest.Tasks)
+	}
+
+	lock, err := readLockfile(dirs.LockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Imports[ts.URL+"/go.yaml"].SHA256 == "" {
+		t.Fatalf("expected import to be pinned in lockfile, got %+v", lock.Imports)
+	}
+
+	// Upstream changes are ignored while the cached, locked copy exists
+	mu.Lock()
+	body = strings.Replace(body, "golangci-lint run", "go vet ./...", 1)
+	mu.Unlock()
+	manifest, err = ParseManifest(path)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.Tasks["lint"].Command != "golangci-lint run" {
+		t.Errorf("expected locked content, got %q", manifest.Tasks["lint"].Command)
+	}
+
+	// Without the cache, changed content fails the lock check
+	if err := os.RemoveAll(importCacheDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseManifest(path); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected lock mismatch error, got: %v", err)
+	}
+
+	// Updating accepts the new content
+	if err := UpdateRemoteImports("."); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err = ParseManifest(path)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.Tasks["lint"].Command != "go vet ./..." {
+		t.Errorf("expected updated content, got %q", manifest.Tasks["lint"].Command)
+	}
+}
+
+func TestRemoteImportProjectRoot(t *testing.T) {
+	body := "version: \"1.0\"\ntasks:\n  lint:\n    description: \"Lint\"\n    command: \"golangci-lint run\"\n"
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	origClient := httpClient
+	httpClient = ts.Client()
+	t.Cleanup(func() { httpClient = origClient })
+
+	// The lockfile and cache belong to the project, wherever it is loaded from
+	cwd := t.TempDir()
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, cwd)
+
+	project := t.TempDir()
+	writeProjectConfig(t, project, "version: \"1.0\"\nimports:\n  - \""+ts.URL+"/go.yaml\"\ntasks: {}\n")
+	manifest, err := loadDirectory(filepath.Join(project, dirs.ConfigDir), project, topLevelVisiting(), false)
+	if err != nil {
+		t.Fatalf("loadDirectory failed: %v", err)
+	}
+	if _, ok := manifest.Tasks["lint"]; !ok {
+		t.Fatalf("expected lint task from remote import, got %+v", manifest.Tasks)
+	}
+	if _, err := os.Stat(filepath.Join(project, dirs.LockFile)); err != nil {
+		t.Errorf("expected the lockfile in the project root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, importCacheDir)); err != nil {
+		t.Errorf("expected the import cache in the project root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, dirs.StateDir)); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written to the working directory, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, dirs.LockFile)); !os.IsNotExist(err) {
+		t.Errorf("expected no lockfile in the working directory, got %v", err)
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRemoteImportGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	git(t, repo, "init", "--quiet")
+	writeTasks := func(command string) {
+		content := "version: \"1.0\"\ntasks:\n  build:\n    description: \"Build\"\n    command: \"" + command + "\"\n"
+		if err := os.WriteFile(filepath.Join(repo, "tasks.yaml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(t, repo, "add", "tasks.yaml")
+		git(t, repo, "commit", "--quiet", "-m", command)
+	}
+	writeTasks("make build")
+	firstCommit := git(t, repo, "rev-parse", "HEAD")
+
+	origDir := mustGetwd(t)
+	t.Cleanup(func() { mustChdir(t, origDir) })
+	mustChdir(t, t.TempDir())
+
+	ref := "git::file://" + repo + "//tasks.yaml"
+	path := writeImportingManifest(t, ref)
+	manifest, err := ParseManifest(path)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.Tasks["build"].Command != "make build" {
+		t.Fatalf("expected build task from git import, got %+v", manifest.Tasks)
+	}
+
+	lock, err := readLockfile(dirs.LockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Imports[ref].Commit != firstCommit {
+		t.Fatalf("expected commit %s pinned, got %+v", firstCommit, lock.Imports)
+	}
+
+	// The pinned commit is used even after the repository moves on, and even
+	// when the cache has to be rebuilt
+	writeTasks("go build ./...")
+	if err := os.RemoveAll(importCacheDir); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err = ParseManifest(path)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.Tasks["build"].Command != "make build" {
+		t.Errorf("expected pinned content, got %q", manifest.Tasks["build"].Command)
+	}
+
+	if err := UpdateRemoteImports("."); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err = ParseManifest(path)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.Tasks["build"].Command != "go build ./..." {
+		t.Errorf("expected updated content, got %q", manifest.Tasks["build"].Command)
+	}
+	// A lockfile commit that is not a hash never reaches git
+	for _, commit := range []string{"--help", "../../escape", "main"} {
+		if err := writeLockfile(dirs.LockFile, &Lockfile{Imports: map[string]LockedImport{ref: {Commit: commit}}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseManifest(path); err == nil || !strings.Contains(err.Error(), "invalid commit") {
+			t.Errorf("expected commit %q to be rejected, got %v", commit, err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+import "strings"
+
+// ShellArgs returns the argv that runs command with shell. A bare shell path
+// such as /bin/bash runs it as "shell -c command". A shell with arguments is
+// an interpreter command, like "python3 -c" or "node -e", and runs as that
+// argv followed by the command, so tasks can embed scripts in other
+// languages.
+func ShellArgs(shell, command string) []string {
+	args := strings.Fields(shell)
+	if len(args) == 1 {
+		args = append(args, "-c")
+	}
+	return append(args, command)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Parameter sources. A parameter with a source is never interpolated into
+// the command: its value is written to a temp file whose path the command
+// reads as {{.<name>_path}}. A stdin parameter is also fed to the command's
+// standard input.
+const (
+	ParamSourceFile  = "file"
+	ParamSourceStdin = "stdin"
+)
+
+// SourcePathParam returns the template parameter holding the temp file path
+// of a parameter with a source.
+func SourcePathParam(name string) string {
+	return name + "_path"
+}
+
+// validateParamSources checks the source of every task parameter: it must be
+// file or stdin, only on command-running oneshot tasks, with at most one
+// stdin parameter (none on interactive tasks) and no parameter named like a
+// source's path.
+func validateParamSources(name string, task Task) []string {
+	paramNames := make([]string, 0, len(task.Parameters))
+	for paramName := range task.Parameters {
+		paramNames = append(paramNames, paramName)
+	}
+	sort.Strings(paramNames)
+
+	var errors []string
+	var stdin []string
+	for _, paramName := range paramNames {
+		source := task.Parameters[paramName].Source
+		if source == "" {
+			continue
+		}
+		prefix := fmt.Sprintf("task '%s': parameter '%s'", name, paramName)
+		switch source {
+		case ParamSourceFile:
+		case ParamSourceStdin:
+			stdin = append(stdin, paramName)
+		default:
+			errors = append(errors, fmt.Sprintf("%s: invalid source '%s' (must be file or stdin)", prefix, source))
+			continue
+		}
+		if task.Type.IsDaemon() || task.Type == TaskTypeFileOps {
+			errors = append(errors, fmt.Sprintf("%s: source is only supported on oneshot tasks", prefix))
+		}
+		if task.Runner == RunnerDocker {
+			errors = append(errors, fmt.Sprintf("%s: source is not supported with runner: docker", prefix))
+		}
+		if _, clash := task.Parameters[SourcePathParam(paramName)]; clash {
+			errors = append(errors, fmt.Sprintf("%s: parameter '%s' conflicts with its source path", prefix, SourcePathParam(paramName)))
+		}
+	}
+	if len(stdin) > 1 {
+		errors = append(errors, fmt.Sprintf("task '%s': only one parameter can have source: stdin (got %v)", name, stdin))
+	}
+	if len(stdin) > 0 && task.Interactive {
+		errors = append(errors, fmt.Sprintf("task '%s': interactive tasks read the terminal, so no parameter can have source: stdin", name))
+	}
+	return errors
+}
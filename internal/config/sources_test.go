@@ -0,0 +1,41 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateParamSources(t *testing.T) {
+	valid := Task{
+		Type: TaskTypeOneShot,
+		Parameters: map[string]Param{
+			"patch": {Type: "string", Description: "Patch", Source: ParamSourceFile},
+			"sql":   {Type: "string", Description: "SQL", Source: ParamSourceStdin},
+		},
+	}
+	if errors := validateParamSources("apply", valid); len(errors) > 0 {
+		t.Errorf("expected no errors, got %v", errors)
+	}
+
+	invalid := Task{
+		Type: TaskTypeDaemon,
+		Parameters: map[string]Param{
+			"patch":      {Type: "string", Description: "Patch", Source: ParamSourceFile},
+			"patch_path": {Type: "string", Description: "Clashes"},
+			"a":          {Type: "string", Description: "A", Source: ParamSourceStdin},
+			"b":          {Type: "string", Description: "B", Source: ParamSourceStdin},
+			"c":          {Type: "string", Description: "C", Source: "url"},
+		},
+	}
+	errors := strings.Join(validateParamSources("dev", invalid), "; ")
+	for _, want := range []string{
+		"parameter 'c': invalid source 'url'",
+		"parameter 'patch': source is only supported on oneshot tasks",
+		"parameter 'patch_path' conflicts with its source path",
+		"only one parameter can have source: stdin (got [a b])",
+	} {
+		if !strings.Contains(errors, want) {
+			t.Errorf("expected %q in %q", want, errors)
+		}
+	}
+}
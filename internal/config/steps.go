@@ -0,0 +1,80 @@
+package config
+
+import "regexp"
+
+// StepOutputPattern matches references to an earlier workflow step's output
+// in step params, e.g. {{ steps.get_version.stdout }}. The submatches are
+// the step name and the field (stdout, stderr, or exit_code).
+var StepOutputPattern = regexp.MustCompile(`\{\{\s*\.?steps\.([A-Za-z0-9_:-]+)\.(stdout|stderr|exit_code)\s*\}\}`)
+
+// StepConditionRefPattern matches references to an earlier step in a when
+// expression, e.g. {{ .steps.test.success }}. The submatch is the step name.
+var StepConditionRefPattern = regexp.MustCompile(`\.steps\.([A-Za-z0-9_]+)`)
+
+// Name returns the name later steps use to reference this step's output:
+// its id, or the task or workflow it runs.
+func (s WorkflowStep) Name() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Target()
+}
+
+// Built-in step names, used as the Target of wait and wait_for steps.
+const (
+	StepWaitName    = "wait"
+	StepWaitForName = "wait_for"
+)
+
+// Target returns the name of the task or workflow the step runs, or of the
+// built-in step for wait and wait_for steps.
+func (s WorkflowStep) Target() string {
+	switch {
+	case s.Workflow != "":
+		return s.Workflow
+	case s.Wait != nil:
+		return StepWaitName
+	case s.WaitFor != nil:
+		return StepWaitForName
+	}
+	return s.Task
+}
+
+// Builtin reports whether the step is a wait or wait_for step, which the
+// workflow executor runs itself instead of a task.
+func (s WorkflowStep) Builtin() bool {
+	return s.Wait != nil || s.WaitFor != nil
+}
+
+// WorkflowRequiresConfirmation reports whether any step of workflow, or of
+// the workflows it nests, runs a task with requires_confirmation.
+func WorkflowRequiresConfirmation(workflow Workflow, tasks map[string]Task, workflows map[string]Workflow) bool {
+	for _, step := range workflow.Steps {
+		if step.Workflow != "" {
+			if nested, ok := workflows[step.Workflow]; ok && WorkflowRequiresConfirmation(nested, tasks, workflows) {
+				return true
+			}
+			continue
+		}
+		if tasks[step.Task].RequiresConfirmation {
+			return true
+		}
+	}
+	return false
+}
+
+// workflowCycle reports whether the workflow name reaches itself through
+// workflow steps. path holds the workflows currently being followed.
+func workflowCycle(name string, workflows map[string]Workflow, path map[string]bool) bool {
+	if path[name] {
+		return true
+	}
+	path[name] = true
+	defer delete(path, name)
+	for _, step := range workflows[name].Steps {
+		if step.Workflow != "" && workflowCycle(step.Workflow, workflows, path) {
+			return true
+		}
+	}
+	return false
+}
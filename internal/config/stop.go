@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultStopSignal is the signal a stop sends when stop_signal is not set.
+const DefaultStopSignal = "TERM"
+
+// stopSignals are the signals stop_signal may name. KILL is left out, since
+// it is what a stop falls back to after the grace period.
+var stopSignals = []string{"TERM", "INT", "QUIT", "HUP", "USR1", "USR2"}
+
+// StopSignalName returns the signal a stop of the task sends, without its
+// SIG prefix, e.g. "TERM".
+func (t Task) StopSignalName() string {
+	if t.StopSignal == "" {
+		return DefaultStopSignal
+	}
+	return strings.TrimPrefix(strings.ToUpper(t.StopSignal), "SIG")
+}
+
+// validateStop checks a task's stop settings: only plain daemons take them,
+// with one of stopSignals and a grace period that is not negative.
+func validateStop(name string, task Task) []string {
+	var errors []string
+	if (task.StopSignal != "" || task.StopGracePeriod != 0) && task.Type != TaskTypeDaemon {
+		errors = append(errors, fmt.Sprintf("task '%s': stop_signal and stop_grace_period are only supported on daemon tasks", name))
+	}
+	if task.StopSignal != "" && !slices.Contains(stopSignals, task.StopSignalName()) {
+		errors = append(errors, fmt.Sprintf("task '%s': invalid stop_signal '%s' (must be one of %s)", name, task.StopSignal, strings.Join(stopSignals, ", ")))
+	}
+	if task.StopGracePeriod < 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': stop_grace_period cannot be negative", name))
+	}
+	return errors
+}
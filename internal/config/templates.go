@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// applyTaskTemplates fills each task that declares extends with the fields of
+// its task template. Templates may extend other templates. Fields set on the
+// task win; env and parameters are merged key by key.
+func applyTaskTemplates(manifest *Manifest) error {
+	resolved := make(map[string]Task)
+
+	var resolve func(name string, path []string) (Task, error)
+	resolve = func(name string, path []string) (Task, error) {
+		if tmpl, ok := resolved[name]; ok {
+			return tmpl, nil
+		}
+		for _, p := range path {
+			if p == name {
+				return Task{}, fmt.Errorf("task template cycle: %s -> %s", strings.Join(path, " -> "), name)
+			}
+		}
+		tmpl, exists := manifest.TaskTemplates[name]
+		if !exists {
+			return Task{}, fmt.Errorf("task template '%s' does not exist", name)
+		}
+		if tmpl.Extends != "" {
+			parent, err := resolve(tmpl.Extends, append(path, name))
+			if err != nil {
+				return Task{}, err
+			}
+			tmpl = extendTask(tmpl, parent)
+		}
+		resolved[name] = tmpl
+		return tmpl, nil
+	}
+
+	for name, task := range manifest.Tasks {
+		if task.Extends == "" {
+			continue
+		}
+		tmpl, err := resolve(task.Extends, nil)
+		if err != nil {
+			return fmt.Errorf("task '%s': %w", name, err)
+		}
+		manifest.Tasks[name] = extendTask(task, tmpl)
+	}
+	return nil
+}
+
+// extendTask returns task with every unset field taken from base.
+func extendTask(task, base Task) Task {
+	if task.Description == "" {
+		task.Description = base.Description
+	}
+	if task.Command == "" {
+		task.Command = base.Command
+	}
+	if task.Type == "" {
+		task.Type = base.Type
+	}
+	if task.WorkingDirectory == "" {
+		task.WorkingDirectory = base.WorkingDirectory
+	}
+	if !task.ExposeWorkingDirectory {
+		task.ExposeWorkingDirectory = base.ExposeWorkingDirectory
+	}
+	if task.AllowedWorkingDirectories == nil {
+		task.AllowedWorkingDirectories = base.AllowedWorkingDirectories
+	}
+	if task.Timeout == 0 {
+		task.Timeout = base.Timeout
+	}
+	if task.MaxTimeout == 0 {
+		task.MaxTimeout = base.MaxTimeout
+	}
+	if task.Shell == "" {
+		task.Shell = base.Shell
+	}
+	if task.DependsOn == nil {
+		task.DependsOn = base.DependsOn
+	}
+	if task.RequiresDaemon == nil {
+		task.RequiresDaemon = base.RequiresDaemon
+	}
+	if task.Ready == nil {
+		task.Ready = base.Ready
+	}
+	if task.LatencyBudget == 0 {
+		task.LatencyBudget = base.LatencyBudget
+	}
+	if task.Retries == 0 {
+		task.Retries = base.Retries
+	}
+	if task.RetryDelay == 0 {
+		task.RetryDelay = base.RetryDelay
+	}
+	if task.Lifetime == "" {
+		task.Lifetime = base.Lifetime
+	}
+	if task.SessionGrace == 0 {
+		task.SessionGrace = base.SessionGrace
+	}
+	if !task.Interactive {
+		task.Interactive = base.Interactive
+	}
+	if task.LogOutput == "" {
+		task.LogOutput = base.LogOutput
+	}
+	if task.LogOutputMaxLines == 0 {
+		task.LogOutputMaxLines = base.LogOutputMaxLines
+	}
+	if task.LogOutputMaxBytes == "" {
+		task.LogOutputMaxBytes = base.LogOutputMaxBytes
+	}
+	if task.LogMaxSize == "" {
+		task.LogMaxSize = base.LogMaxSize
+	}
+	if task.LogMaxFiles == 0 {
+		task.LogMaxFiles = base.LogMaxFiles
+	}
+	if task.OnCrash == nil {
+		task.OnCrash = base.OnCrash
+	}
+	if !task.Reload {
+		task.Reload = base.Reload
+	}
+	if task.ReloadSignal == "" {
+		task.ReloadSignal = base.ReloadSignal
+	}
+	if task.Ports == nil {
+		task.Ports = base.Ports
+	}
+	if task.StopSignal == "" {
+		task.StopSignal = base.StopSignal
+	}
+	if task.StopGracePeriod == 0 {
+		task.StopGracePeriod = base.StopGracePeriod
+	}
+	if !task.RequiresConfirmation {
+		task.RequiresConfirmation = base.RequiresConfirmation
+	}
+	if task.Hints == nil {
+		task.Hints = base.Hints
+	}
+	if task.RateLimit == nil {
+		task.RateLimit = base.RateLimit
+	}
+	if task.Cache == nil {
+		task.Cache = base.Cache
+	}
+	if task.OutputFormat == "" {
+		task.OutputFormat = base.OutputFormat
+	}
+	if !task.Async {
+		task.Async = base.Async
+	}
+	if task.Runner == "" {
+		task.Runner = base.Runner
+	}
+	if task.Container == nil {
+		task.Container = base.Container
+	}
+	if task.Compose == nil {
+		task.Compose = base.Compose
+	}
+	if task.Operations == nil {
+		task.Operations = base.Operations
+	}
+	if task.Preconditions == nil {
+		task.Preconditions = base.Preconditions
+	}
+	if task.Redact == nil {
+		task.Redact = base.Redact
+	}
+	if task.Artifacts == nil {
+		task.Artifacts = base.Artifacts
+	}
+	if task.ParameterPresets == nil {
+		task.ParameterPresets = base.ParameterPresets
+	}
+	if task.EnvPolicy == nil {
+		task.EnvPolicy = base.EnvPolicy
+	}
+	if !task.DisableMCP {
+		task.DisableMCP = base.DisableMCP
+	}
+	if !task.Disabled {
+		task.Disabled = base.Disabled
+	}
+
+	if len(base.Env) > 0 {
+		env := make(map[string]string, len(base.Env)+len(task.Env))
+		for k, v := range base.Env {
+			env[k] = v
+		}
+		for k, v := range task.Env {
+			env[k] = v
+		}
+		task.Env = env
+	}
+	if len(base.Parameters) > 0 {
+		params := make(map[string]Param, len(base.Parameters)+len(task.Parameters))
+		for k, v := range base.Parameters {
+			params[k] = v
+		}
+		for k, v := range task.Parameters {
+			params[k] = v
+		}
+		task.Parameters = params
+	}
+	return task
+}
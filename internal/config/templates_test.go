@@ -0,0 +1,114 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskTemplates(t *testing.T) {
+	content := `version: "1.0"
+defaults:
+  timeout: 60
+task_templates:
+  node:
+    shell: /bin/zsh
+    timeout: 600
+    env:
+      NODE_ENV: development
+      CI: "true"
+    parameters:
+      args:
+        type: string
+        description: "Extra arguments"
+  node-test:
+    extends: node
+    env:
+      NODE_ENV: test
+tasks:
+  unit:
+    description: "Unit tests"
+    command: "npm test {{.args}}"
+    extends: node-test
+    timeout: 120
+  lint:
+    description: "Lint"
+    command: "npm run lint"
+    extends: node
+    env:
+      CI: "false"
+`
+	manifest, err := ParseManifest(writeTempFile(t, "templates-*.yaml", content))
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+
+	unit := manifest.Tasks["unit"]
+	if unit.Shell != "/bin/zsh" {
+		t.Errorf("expected shell from template, got %q", unit.Shell)
+	}
+	if unit.Timeout != 120 {
+		t.Errorf("expected task timeout to win, got %d", unit.Timeout)
+	}
+	if unit.Env["NODE_ENV"] != "test" || unit.Env["CI"] != "true" {
+		t.Errorf("expected env merged through template chain, got %v", unit.Env)
+	}
+	if _, ok := unit.Parameters["args"]; !ok {
+		t.Errorf("expected parameters from template, got %v", unit.Parameters)
+	}
+
+	lint := manifest.Tasks["lint"]
+	if lint.Timeout != 600 {
+		t.Errorf("expected template timeout to win over defaults, got %d", lint.Timeout)
+	}
+	if lint.Env["CI"] != "false" || lint.Env["NODE_ENV"] != "development" {
+		t.Errorf("expected task env to override template env, got %v", lint.Env)
+	}
+}
+
+func TestTaskTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		errorMsg string
+	}{
+		{
+			name: "missing template",
+			content: `version: "1.0"
+tasks:
+  build:
+    description: "Build"
+    command: "make"
+    extends: go
+`,
+			errorMsg: "task 'build': task template 'go' does not exist",
+		},
+		{
+			name: "template cycle",
+			content: `version: "1.0"
+task_templates:
+  a:
+    extends: b
+  b:
+    extends: a
+tasks:
+  build:
+    description: "Build"
+    command: "make"
+    extends: a
+`,
+			errorMsg: "task template cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest(writeTempFile(t, "templates-*.yaml", tt.content))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
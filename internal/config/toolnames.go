@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Tool kinds: the verbs that start the names of the tools generated for
+// tasks and workflows, as in run_test or start_dev.
+const (
+	ToolRun         = "run"
+	ToolStart       = "start"
+	ToolStop        = "stop"
+	ToolStatus      = "status"
+	ToolLogs        = "logs"
+	ToolSendInput   = "send_input"
+	ToolReload      = "reload"
+	ToolRunWorkflow = "run_workflow"
+)
+
+// toolNamePattern restricts tool_name and tool_prefix to characters that
+// are valid in MCP tool names.
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ToolName returns the name of a task's tool of the given kind: the
+// manifest's defaults.tool_prefix, the kind, and the task's tool_name, or
+// its name when it has none. For example, run_test, or myproj_run_test
+// with tool_prefix "myproj_".
+func (m *Manifest) ToolName(kind, taskName string) string {
+	name := taskName
+	if task, ok := m.Tasks[taskName]; ok && task.ToolName != "" {
+		name = task.ToolName
+	}
+	return m.Defaults.ToolPrefix + kind + "_" + name
+}
+
+// WorkflowToolName returns the name of a workflow's tool, e.g.
+// run_workflow_release.
+func (m *Manifest) WorkflowToolName(workflowName string) string {
+	return m.Defaults.ToolPrefix + ToolRunWorkflow + "_" + workflowName
+}
+
+// TaskToolKinds returns the kinds of tool the server generates for a task:
+// run for oneshot and file_ops tasks, and start, stop, status, and logs,
+// plus send_input and reload when enabled, for daemons.
+func TaskToolKinds(task Task) []string {
+	switch task.Type {
+	case TaskTypeOneShot, TaskTypeFileOps:
+		return []string{ToolRun}
+	case TaskTypeDaemon, TaskTypeCompose:
+		kinds := []string{ToolStart, ToolStop, ToolStatus, ToolLogs}
+		if task.Interactive {
+			kinds = append(kinds, ToolSendInput)
+		}
+		if task.Reload {
+			kinds = append(kinds, ToolReload)
+		}
+		return kinds
+	}
+	return nil
+}
+
+// validateToolNames checks tool_prefix and tool_name, and that no two tasks
+// or workflows end up with the same tool name.
+func validateToolNames(manifest *Manifest) []string {
+	var errors []string
+	if prefix := manifest.Defaults.ToolPrefix; prefix != "" && !toolNamePattern.MatchString(prefix) {
+		errors = append(errors, fmt.Sprintf("defaults: invalid tool_prefix '%s' (use letters, digits, '_', and '-')", prefix))
+	}
+
+	owners := make(map[string]string)
+	claim := func(tool, owner string) {
+		if other, taken := owners[tool]; taken {
+			errors = append(errors, fmt.Sprintf("%s: tool name '%s' is already used by %s", owner, tool, other))
+			return
+		}
+		owners[tool] = owner
+	}
+
+	taskNames := make([]string, 0, len(manifest.Tasks))
+	for name := range manifest.Tasks {
+		taskNames = append(taskNames, name)
+	}
+	sort.Strings(taskNames)
+	for _, name := range taskNames {
+		task := manifest.Tasks[name]
+		owner := fmt.Sprintf("task '%s'", name)
+		if task.ToolName != "" && !toolNamePattern.MatchString(task.ToolName) {
+			errors = append(errors, fmt.Sprintf("%s: invalid tool_name '%s' (use letters, digits, '_', and '-')", owner, task.ToolName))
+		}
+		if task.Disabled || task.DisableMCP {
+			continue
+		}
+		for _, kind := range TaskToolKinds(task) {
+			claim(manifest.ToolName(kind, name), owner)
+		}
+	}
+
+	workflowNames := make([]string, 0, len(manifest.Workflows))
+	for name := range manifest.Workflows {
+		workflowNames = append(workflowNames, name)
+	}
+	sort.Strings(workflowNames)
+	for _, name := range workflowNames {
+		if workflow := manifest.Workflows[name]; workflow.Disabled || workflow.DisableMCP {
+			continue
+		}
+		claim(manifest.WorkflowToolName(name), fmt.Sprintf("workflow '%s'", name))
+	}
+	return errors
+}
@@ -1,5 +1,7 @@
 package config
 
+import "slices"
+
 // TaskType represents the type of task execution
 type TaskType string
 
@@ -8,8 +10,18 @@ const (
 	TaskTypeOneShot TaskType = "oneshot"
 	// TaskTypeDaemon represents a long-running background process
 	TaskTypeDaemon TaskType = "daemon"
+	// TaskTypeCompose represents a docker compose stack managed like a daemon
+	TaskTypeCompose TaskType = "compose"
+	// TaskTypeFileOps represents declarative file operations run without a shell
+	TaskTypeFileOps TaskType = "file_ops"
 )
 
+// IsDaemon reports whether tasks of this type run until stopped and are
+// managed with start, stop, status, and logs.
+func (t TaskType) IsDaemon() bool {
+	return t == TaskTypeDaemon || t == TaskTypeCompose
+}
+
 // Manifest represents the complete task configuration
 type Manifest struct {
 	Version    string                 `yaml:"version"`
@@ -17,9 +29,19 @@ type Manifest struct {
 	Tasks      map[string]Task        `yaml:"tasks"`
 	TaskGroups map[string]TaskGroup   `yaml:"task_groups"`
 	Prompts    map[string]Prompt      `yaml:"prompts"`
+	PromptPartials map[string]PromptPartial `yaml:"prompt_partials,omitempty"`
 	Resources  map[string]Resource    `yaml:"resources"`
+	PromptsDir   string               `yaml:"prompts_dir,omitempty"`   // Directory whose markdown files are each loaded as a prompt
+	ResourcesDir string               `yaml:"resources_dir,omitempty"` // Directory whose markdown files are each loaded as a resource
 	Defaults   Defaults               `yaml:"defaults"`
 	Workflows  map[string]Workflow    `yaml:"workflows"`
+	Exec       ExecConfig             `yaml:"exec,omitempty"`
+	AllowedProjects []string          `yaml:"allowed_projects,omitempty"`
+	TaskTemplates   map[string]Task   `yaml:"task_templates,omitempty"`
+	Server          ServerConfig      `yaml:"server,omitempty"`
+	Testing         TestingConfig     `yaml:"testing,omitempty"`
+	Adapters        AdaptersConfig    `yaml:"adapters,omitempty"`
+	Vars            map[string]string `yaml:"vars,omitempty"` // Values templates read as {{.Vars.name}}; they may use {{env "NAME"}}
 }
 
 // Task represents a single executable task
@@ -29,21 +51,145 @@ type Task struct {
 	Type                   TaskType          `yaml:"type"`
 	WorkingDirectory       string            `yaml:"working_directory"`
 	ExposeWorkingDirectory bool              `yaml:"expose_working_directory"`
+	AllowedWorkingDirectories []string       `yaml:"allowed_working_directories,omitempty"` // Globs a working_directory argument must match
 	Env                    map[string]string `yaml:"env"`
 	Timeout                int               `yaml:"timeout"`
+	MaxTimeout             int               `yaml:"max_timeout,omitempty"` // Upper bound in seconds for a run_ call's timeout override
 	Shell                  string            `yaml:"shell"`
+	Redact                 []string          `yaml:"redact,omitempty"` // Regexes masked in output and logs, after defaults.redact
+	Artifacts              []string          `yaml:"artifacts,omitempty"` // Oneshot: paths or globs copied into the session directory after each run
+	ExpectedExitCodes      map[int]string    `yaml:"expected_exit_codes,omitempty"` // Oneshot: non-zero exit codes that still succeed, with what they mean
+	WarningExitCodes       map[int]string    `yaml:"warning_exit_codes,omitempty"`  // Oneshot: exit codes that succeed with a warning, with what they mean
+	OutputFormat           string            `yaml:"output_format,omitempty"` // Oneshot: "json" parses stdout into the result's output
+	LogOutput              string            `yaml:"log_output,omitempty"` // Oneshot: stdout kept in results: "full" (default), "truncated", or "none"
+	LogOutputMaxLines      int               `yaml:"log_output_max_lines,omitempty"` // log_output truncated: last lines of stdout kept (default 100)
+	LogOutputMaxBytes      string            `yaml:"log_output_max_bytes,omitempty"` // log_output truncated: most bytes of stdout kept, e.g. "16KB" (default 64KB)
+	Async                  bool              `yaml:"async,omitempty"` // Oneshot: run_ calls start the task in the background unless run_async is false
+	EnvPolicy              *EnvPolicy        `yaml:"env_policy,omitempty"` // Host environment inherited, replacing defaults.env_policy
 	Parameters             map[string]Param  `yaml:"parameters"`
+	ParameterPresets       map[string]map[string]string `yaml:"parameter_presets,omitempty"` // Named sets of parameter values, chosen with preset / --preset
 	DependsOn              []string          `yaml:"depends_on"`
+	RequiresDaemon         []string          `yaml:"requires_daemon,omitempty"`
+	Ready                  *ReadyCheck       `yaml:"ready,omitempty"`
+	LatencyBudget          int               `yaml:"latency_budget,omitempty"` // Soft budget in seconds for run_ tool calls (-1 disables)
+	Retries                int               `yaml:"retries,omitempty"`     // Extra attempts when the task fails as a workflow step
+	RetryDelay             int               `yaml:"retry_delay,omitempty"` // Seconds to wait between attempts
+	Lifetime               string            `yaml:"lifetime,omitempty"`      // Daemons: "session" stops it when the MCP client that started it disconnects
+	SessionGrace           int               `yaml:"session_grace,omitempty"` // Seconds to wait after disconnect before stopping a session daemon (default 30)
+	Interactive            bool              `yaml:"interactive,omitempty"`   // Daemons: run on a terminal and expose send_input_<task>
+	LogMaxSize             string            `yaml:"log_max_size,omitempty"`  // Daemons: rotate the session log at this size, e.g. "10MB"
+	LogMaxFiles            int               `yaml:"log_max_files,omitempty"` // Daemons: rotated log segments kept (default 5)
+	OnCrash                *CrashNotify      `yaml:"on_crash,omitempty"`      // Daemons: how a crash is reported, replacing defaults.on_crash
+	Reload                 bool              `yaml:"reload,omitempty"`        // Daemons: expose reload_<task>, which signals the daemon to reload its config
+	ReloadSignal           string            `yaml:"reload_signal,omitempty"` // Daemons: signal sent by reload (default HUP)
+	Ports                  []int             `yaml:"ports,omitempty"`             // Daemons: TCP ports the daemon listens on, checked free before it starts
+	StopSignal             string            `yaml:"stop_signal,omitempty"`       // Daemons: signal sent to stop the daemon (default TERM)
+	StopGracePeriod        int               `yaml:"stop_grace_period,omitempty"` // Daemons: seconds to exit after stop_signal before SIGKILL (default: defaults.stop_grace)
+	RequiresConfirmation   bool              `yaml:"requires_confirmation,omitempty"` // MCP calls need a confirmation token; the CLI prompts
+	Hints                  *ToolHints        `yaml:"hints,omitempty"` // MCP annotations of the run_ or start_ tool
+	RateLimit              *RateLimit        `yaml:"rate_limit,omitempty"` // How often the task may be run or started
+	Cache                  *TaskCache        `yaml:"cache,omitempty"` // Oneshot: reuse the result of an earlier run with the same key
+	Runner                 string            `yaml:"runner,omitempty"`    // Oneshot: "docker" runs the command in Container instead of the host shell; any other value is the tag of remote agents that run it
+	Container              *ContainerConfig  `yaml:"container,omitempty"` // Container settings for runner: docker
+	Compose                *ComposeConfig    `yaml:"compose,omitempty"`   // Stack settings for type: compose
+	Operations             []FileOp          `yaml:"operations,omitempty"` // Steps for type: file_ops
+	Preconditions          []Precondition    `yaml:"preconditions,omitempty"` // Checks that must pass before the command runs
+	Extends                string            `yaml:"extends,omitempty"` // Task template to inherit unset fields from
+	Project                string            `yaml:"project,omitempty"` // Sibling project to take the task definition from
+	ProjectTask            string            `yaml:"task,omitempty"`    // Task name in Project (default: this task's name)
 	DisableMCP             bool              `yaml:"disable_mcp,omitempty"`
+	ToolName               string            `yaml:"tool_name,omitempty"` // Replaces the task name in its tool names, e.g. run_<tool_name>
 	Disabled               bool              `yaml:"disabled,omitempty"`
 }
 
+// Output formats of a oneshot task's stdout. Text (the default) is returned
+// as is; JSON is also parsed into the result's structured output.
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
+// Task runners. Shell (the default) runs the command on the host. Any other
+// runner names a tag of remote agents, see Task.AgentTag.
+const (
+	RunnerShell  = "shell"
+	RunnerDocker = "docker"
+)
+
+// Precondition is a check a oneshot task runs before its command. Exactly
+// one of Command, FileExists, and PortFree is set; Command and FileExists
+// support parameter templates and resolve against the task's working
+// directory.
+type Precondition struct {
+	Command    string `yaml:"command,omitempty"`     // Shell command that must exit 0
+	FileExists string `yaml:"file_exists,omitempty"` // File or directory that must exist
+	PortFree   int    `yaml:"port_free,omitempty"`   // TCP port on localhost that must not be listening
+	Message    string `yaml:"message,omitempty"`     // Hint returned when the check fails, e.g. how to fix it
+}
+
+// ContainerConfig describes the container a task with runner: docker runs
+// in. The task's working directory is always mounted at /workspace.
+type ContainerConfig struct {
+	Image   string   `yaml:"image"`
+	Mounts  []string `yaml:"mounts,omitempty"`  // Extra bind mounts or volumes as "source:target[:options]"
+	Network string   `yaml:"network,omitempty"` // Docker network, e.g. "none" (default: docker's default)
+}
+
+// ComposeConfig describes the stack of a type: compose task. Without it, the
+// compose file in the working directory is used.
+type ComposeConfig struct {
+	File     string   `yaml:"file,omitempty"`     // Compose file (default: docker compose's lookup)
+	Project  string   `yaml:"project,omitempty"`  // Project name (default: from the directory)
+	Services []string `yaml:"services,omitempty"` // Services to run (default: all)
+}
+
+// File operations for type: file_ops tasks.
+const (
+	FileOpCopy     = "copy"
+	FileOpTemplate = "template"
+	FileOpMkdir    = "mkdir"
+	FileOpChmod    = "chmod"
+	FileOpDelete   = "delete"
+)
+
+// FileOp is one step of a file_ops task. Paths are relative to the task's
+// working directory and support parameter templates; paths that are written
+// or deleted must stay inside the working directory.
+type FileOp struct {
+	Op        string `yaml:"op"`
+	Src       string `yaml:"src,omitempty"`       // copy, template: file to read
+	Dest      string `yaml:"dest,omitempty"`      // copy, template: file to write
+	Path      string `yaml:"path,omitempty"`      // mkdir, chmod, delete
+	Mode      string `yaml:"mode,omitempty"`      // Octal permissions, e.g. "0755" (chmod: required; others: optional)
+	Recursive bool   `yaml:"recursive,omitempty"` // delete: allow removing a directory and its contents
+}
+
+// ReadyCheck describes how to tell that a daemon is ready to serve requests.
+// All configured conditions must pass. A daemon without a ready check is
+// considered ready as soon as its process is running.
+type ReadyCheck struct {
+	LogPattern string `yaml:"log_pattern"` // Regex matched against the daemon's session log
+	Port       int    `yaml:"port"`        // TCP port on localhost that must accept connections
+	URL        string `yaml:"url"`         // HTTP URL that must respond with a non-5xx status
+	Command    string `yaml:"command"`     // Shell command that must exit 0
+	Timeout    int    `yaml:"timeout"`     // Seconds to wait before giving up (default 30)
+}
+
 // Param represents a task parameter definition
 type Param struct {
-	Type        string  `yaml:"type"`
-	Required    bool    `yaml:"required"`
-	Description string  `yaml:"description"`
-	Default     *string `yaml:"default"`
+	Type            string   `yaml:"type"`
+	Required        bool     `yaml:"required"`
+	Description     string   `yaml:"description"`
+	Default         *string  `yaml:"default"`
+	Aliases         []string `yaml:"aliases,omitempty"`          // Other accepted names
+	DeprecatedNames []string `yaml:"deprecated_names,omitempty"` // Old names, accepted with a warning
+	Source          string   `yaml:"source,omitempty"`           // file or stdin: pass the value through a temp file
+	Pattern         string   `yaml:"pattern,omitempty"`          // Regex the value must match
+	Enum            []string `yaml:"enum,omitempty"`             // Values the parameter accepts
+	Min             *float64 `yaml:"min,omitempty"`              // Number and integer: smallest accepted value
+	Max             *float64 `yaml:"max,omitempty"`              // Number and integer: largest accepted value
+	MinLength       *int     `yaml:"min_length,omitempty"`       // String: fewest characters accepted
+	MaxLength       *int     `yaml:"max_length,omitempty"`       // String: most characters accepted
 }
 
 // TaskGroup represents a collection of related tasks
@@ -58,9 +204,18 @@ type Prompt struct {
 	Description string `yaml:"description"`
 	Content     string `yaml:"content"`
 	File        string `yaml:"file"`
+	ContentByLocale map[string]string `yaml:"content_by_locale,omitempty"` // Locale (e.g. "de", "pt-BR") to content; falls back to Content or File
 	Disabled    bool   `yaml:"disabled,omitempty"`
 }
 
+// PromptPartial is shared template content that prompts, resources, and
+// server instructions include with {{partial "name"}}. Partials are rendered
+// with the same data and may include other partials.
+type PromptPartial struct {
+	Content string `yaml:"content"`
+	File    string `yaml:"file"`
+}
+
 // Resource represents a custom MCP resource with either inline or file-based content
 type Resource struct {
 	Description string `yaml:"description"`
@@ -72,9 +227,207 @@ type Resource struct {
 
 // Defaults represents default values for task configuration
 type Defaults struct {
-	Timeout int               `yaml:"timeout"`
-	Shell   string            `yaml:"shell"`
-	Env     map[string]string `yaml:"env"`
+	Timeout            int               `yaml:"timeout"`
+	MaxTimeout         int               `yaml:"max_timeout,omitempty"` // Upper bound in seconds for a run_ call's timeout override
+	Shell              string            `yaml:"shell"`
+	Env                map[string]string `yaml:"env"`
+	LatencyBudget      int               `yaml:"latency_budget,omitempty"`       // Soft budget in seconds for run_ tool calls (-1 disables)
+	Redact             []string          `yaml:"redact,omitempty"`               // Regexes masked in every task's output
+	EnvPolicy          *EnvPolicy        `yaml:"env_policy,omitempty"`           // Host environment inherited by tasks without their own env_policy
+	StopGrace          int               `yaml:"stop_grace,omitempty"`           // Seconds a stopped daemon has to exit before SIGKILL (default 5)
+	SessionSink        *SessionSink      `yaml:"session_sink,omitempty"`         // Where the metadata of completed sessions is exported
+	MaxConcurrentTasks int               `yaml:"max_concurrent_tasks,omitempty"` // HTTP server: oneshot runs at once, the rest queue (0 = unlimited)
+	OnCrash            *CrashNotify      `yaml:"on_crash,omitempty"`             // How a daemon crash is reported, for daemons without their own on_crash
+	StrictSecurity     bool              `yaml:"strict_security,omitempty"`       // Command lint warnings fail the load instead
+	ToolPrefix         string            `yaml:"tool_prefix,omitempty"`           // Prepended to every task and workflow tool name, e.g. myproj_run_test
+}
+
+// CrashNotify reports a daemon that exits on its own with a failure status,
+// so the crash is not discovered hours later. Any combination of the
+// channels can be used.
+type CrashNotify struct {
+	Desktop  bool   `yaml:"desktop,omitempty"`   // Show a desktop notification
+	Webhook  string `yaml:"webhook,omitempty"`   // URL to POST the crash event to as JSON
+	TokenEnv string `yaml:"token_env,omitempty"` // Env var holding a bearer token for webhook
+	Task     string `yaml:"task,omitempty"`      // Oneshot task to run, given the crash as parameters
+}
+
+// RateLimit caps how often a task runs, so an agent cannot invoke an
+// expensive task such as a deploy more often than intended.
+type RateLimit struct {
+	MaxRuns int    `yaml:"max_runs"` // Runs allowed in any window of per
+	Per     string `yaml:"per"`      // Window length as a duration, e.g. "1h"
+}
+
+// TaskCache makes repeated runs of a oneshot task with the same inputs
+// return the stored result of an earlier successful run instead of running
+// the command again.
+type TaskCache struct {
+	Key string `yaml:"key"`           // Template of the cache key, e.g. "{{.files_hash}}"
+	TTL string `yaml:"ttl,omitempty"` // How long a result is reused, as a duration, e.g. "1h"; empty means until the key changes
+}
+
+// SessionSink exports the metadata of every completed session, as one JSON
+// object per session, to a JSONL file, a webhook, or both.
+type SessionSink struct {
+	File     string `yaml:"file,omitempty"`      // JSONL file to append to
+	URL      string `yaml:"url,omitempty"`       // Endpoint to POST each session to
+	TokenEnv string `yaml:"token_env,omitempty"` // Env var holding a bearer token for url
+	Timeout  int    `yaml:"timeout,omitempty"`   // Seconds to wait for url (default 5)
+}
+
+// Environment policy modes.
+const (
+	EnvPolicyAll       = "all"       // Inherit the whole host environment (the default)
+	EnvPolicyAllowlist = "allowlist" // Inherit only the variables in Vars
+	EnvPolicyDenylist  = "denylist"  // Inherit everything except the variables in Vars
+	EnvPolicyNone      = "none"      // Inherit nothing; only env is set
+)
+
+// EnvPolicy controls which host environment variables a task's processes
+// inherit. A task's env is set on top either way.
+type EnvPolicy struct {
+	Mode string   `yaml:"mode" json:"mode"`
+	Vars []string `yaml:"vars,omitempty" json:"vars,omitempty"` // Variable names or globs, e.g. "AWS_*"
+}
+
+// ServerConfig customizes the metadata the MCP server advertises during
+// initialize. Instructions may use the same templates as prompts.
+type ServerConfig struct {
+	Name          string `yaml:"name,omitempty"`
+	VersionSuffix string `yaml:"version_suffix,omitempty"`
+	Instructions  string `yaml:"instructions,omitempty"`
+	Locale        string `yaml:"locale,omitempty"` // Default locale for prompts with content_by_locale
+	// Projects maps a name to another project directory whose tasks the
+	// server hosts under "<name>__<task>".
+	Projects map[string]string `yaml:"projects,omitempty"`
+	Auth     AuthConfig        `yaml:"auth,omitempty"`
+	TLS      TLSConfig         `yaml:"tls,omitempty"`
+	// AllowTaskEdits registers the add_task, update_task, and remove_task
+	// tools. Over HTTP they also require server.auth.
+	AllowTaskEdits bool `yaml:"allow_task_edits,omitempty"`
+	// ShutdownGrace is how many seconds runbook serve waits for in-flight
+	// tool calls and runs started with run_async on SIGTERM (default 30,
+	// -1 = don't wait).
+	ShutdownGrace int `yaml:"shutdown_grace,omitempty"`
+	// Capabilities lists the MCP capabilities the server advertises
+	// (default: DefaultCapabilities). Tools are always included.
+	Capabilities []string `yaml:"capabilities,omitempty"`
+}
+
+// MCP capabilities server.capabilities can list.
+const (
+	CapabilityTools     = "tools"
+	CapabilityPrompts   = "prompts"
+	CapabilityResources = "resources"
+	CapabilityLogging   = "logging"
+)
+
+// DefaultCapabilities are advertised when server.capabilities is unset.
+var DefaultCapabilities = []string{CapabilityTools, CapabilityPrompts, CapabilityResources}
+
+// HasCapability reports whether the server advertises the named MCP
+// capability.
+func (c ServerConfig) HasCapability(name string) bool {
+	capabilities := c.Capabilities
+	if len(capabilities) == 0 {
+		capabilities = DefaultCapabilities
+	}
+	return name == CapabilityTools || slices.Contains(capabilities, name)
+}
+
+// Daemon lifetimes. Persistent daemons (the default) run until stopped;
+// session daemons are stopped when the MCP session that started them ends.
+const (
+	LifetimePersistent = "persistent"
+	LifetimeSession    = "session"
+)
+
+// Authentication types for the HTTP server.
+const (
+	AuthTypeBearer  = "bearer"
+	AuthTypeMTLS    = "mtls"
+	AuthTypeCommand = "command"
+	AuthTypeWebhook = "webhook"
+)
+
+// AuthConfig selects how requests to the HTTP server are authenticated.
+// An empty Type leaves the server open.
+type AuthConfig struct {
+	Type            string   `yaml:"type,omitempty"`
+	TokenEnv        string   `yaml:"token_env,omitempty"`        // bearer: env var holding the token (default RUNBOOK_TOKEN)
+	AllowedSubjects []string `yaml:"allowed_subjects,omitempty"` // mtls: client certificate common names to accept (default: any verified)
+	Command         string   `yaml:"command,omitempty"`          // command: shell command that exits 0 to allow a request
+	URL             string   `yaml:"url,omitempty"`              // webhook: endpoint that returns 2xx to allow a request
+	Timeout         int      `yaml:"timeout,omitempty"`          // command/webhook: seconds to wait for a decision (default 5)
+}
+
+// TLSConfig enables HTTPS for the HTTP server. ClientCAFile additionally
+// requires clients to present a certificate signed by that CA.
+type TLSConfig struct {
+	CertFile     string `yaml:"cert_file,omitempty"`
+	KeyFile      string `yaml:"key_file,omitempty"`
+	ClientCAFile string `yaml:"client_ca_file,omitempty"`
+}
+
+// AdaptersConfig imports tasks from a project's existing build files, so
+// they get tools without hand-written YAML. The imported tasks are read again
+// each time the config is loaded.
+type AdaptersConfig struct {
+	Make *AdapterConfig `yaml:"make,omitempty"` // A task per Makefile target
+	NPM  *AdapterConfig `yaml:"npm,omitempty"`  // A task per package.json script
+}
+
+// AdapterConfig selects the build file an adapter reads and which of its
+// targets or scripts become tasks.
+type AdapterConfig struct {
+	File    string   `yaml:"file,omitempty"`    // Relative to the project root (default Makefile or package.json)
+	Prefix  *string  `yaml:"prefix,omitempty"`  // Prepended to task names (default "make_" or "npm_")
+	Include []string `yaml:"include,omitempty"` // Glob patterns of targets or scripts to import (default all)
+	Exclude []string `yaml:"exclude,omitempty"` // Glob patterns of targets or scripts to skip
+	Client  string   `yaml:"client,omitempty"`  // npm: package manager that runs scripts: npm (default), pnpm, yarn, or bun
+}
+
+// TestingConfig injects simulated faults into task runs, so retry and
+// fallback logic built on top of runbook can be exercised. Faults only apply
+// when Enabled is set or the RUNBOOK_TESTING environment variable is "1".
+type TestingConfig struct {
+	Enabled bool             `yaml:"enabled,omitempty"`
+	Seed    int64            `yaml:"seed,omitempty"`   // Random seed for repeatable faults (0 = random; RUNBOOK_TESTING_SEED overrides)
+	Faults  map[string]Fault `yaml:"faults,omitempty"` // Task name or glob pattern to fault
+}
+
+// Fault describes how runs of a task are disturbed. Rates are probabilities
+// from 0 to 1; a rate of 1 affects every run.
+type Fault struct {
+	Delay       int     `yaml:"delay,omitempty"`        // Seconds to wait before the task runs
+	FailRate    float64 `yaml:"fail_rate,omitempty"`    // Fail without running the command
+	ExitCode    int     `yaml:"exit_code,omitempty"`    // Exit code of simulated failures (default 1)
+	TimeoutRate float64 `yaml:"timeout_rate,omitempty"` // Report a timeout without running the command
+}
+
+// ToolHints describe what running a task does, for the MCP tool
+// annotations clients use to decide which calls to confirm. Unset hints are
+// left out, so clients apply their defaults.
+type ToolHints struct {
+	ReadOnly    *bool `yaml:"read_only,omitempty"`   // Running the task does not change anything
+	Destructive *bool `yaml:"destructive,omitempty"` // Running the task may delete or overwrite data
+	Idempotent  *bool `yaml:"idempotent,omitempty"`  // Running it again with the same parameters has no further effect
+}
+
+// ExecConfig controls the ad-hoc shell_exec MCP tool (also registered as
+// exec_command). The tool is only registered when Enabled is true; the CLI
+// exec command is always available and is not subject to the MCP guards.
+type ExecConfig struct {
+	Enabled                   bool     `yaml:"enabled"`
+	Timeout                   int      `yaml:"timeout"`
+	MaxTimeout                int      `yaml:"max_timeout,omitempty"` // Upper bound in seconds for a call's timeout (default: defaults.max_timeout)
+	Shell                     string   `yaml:"shell"`
+	RateLimit                 int      `yaml:"rate_limit,omitempty"`                  // Calls per minute (0 = default of 30, -1 = unlimited)
+	RequiresConfirmation      bool     `yaml:"requires_confirmation,omitempty"`       // Calls need a confirmation token
+	Allow                     []string `yaml:"allow,omitempty"`                       // If set, commands must match one of these patterns
+	Deny                      []string `yaml:"deny,omitempty"`                        // Commands matching any of these patterns are rejected
+	AllowedWorkingDirectories []string `yaml:"allowed_working_directories,omitempty"` // Globs a working_directory argument must match
 }
 
 // Workflow represents a composite workflow that runs multiple tasks sequentially
@@ -91,9 +444,35 @@ type Workflow struct {
 
 // WorkflowStep represents a single step in a workflow
 type WorkflowStep struct {
+	ID                string            `yaml:"id,omitempty"` // Name later steps use in {{ steps.<id>.stdout }} (default: Task)
 	Task              string            `yaml:"task"`
+	Workflow          string            `yaml:"workflow,omitempty"` // Run this workflow instead of a task
 	Params            map[string]string `yaml:"params"`
 	ContinueOnFailure bool             `yaml:"continue_on_failure"`
+	RequiresDaemon    []string          `yaml:"requires_daemon,omitempty"`
+	Project           string            `yaml:"project,omitempty"` // Run Task from this sibling project
+	Retries           int               `yaml:"retries,omitempty"`     // Extra attempts when the step fails (default: the task's retries)
+	RetryDelay        int               `yaml:"retry_delay,omitempty"` // Seconds to wait between attempts (default: the task's retry_delay)
+	When              string            `yaml:"when,omitempty"`        // Template expression; the step is skipped when it renders false or empty
+	Fresh             bool              `yaml:"fresh,omitempty"`       // Restart the required daemons from a clean slate before the step
+	Timeout           int               `yaml:"timeout,omitempty"`     // Seconds the step may run, replacing the task's timeout
+	Wait              *StepWait         `yaml:"wait,omitempty"`        // Pause instead of running a task
+	WaitFor           *StepWaitFor      `yaml:"wait_for,omitempty"`    // Wait for a URL, port, or file instead of running a task
+}
+
+// StepWait is a workflow step that pauses for a fixed time.
+type StepWait struct {
+	Seconds float64 `yaml:"seconds"`
+}
+
+// StepWaitFor is a workflow step that waits until all of its conditions
+// hold. url and file may reference workflow parameters and earlier steps
+// like a when expression.
+type StepWaitFor struct {
+	URL     string `yaml:"url,omitempty"`     // HTTP URL that must respond with a non-5xx status
+	Port    int    `yaml:"port,omitempty"`    // TCP port on localhost that must accept connections
+	File    string `yaml:"file,omitempty"`    // Path that must exist, relative to the workflow's working directory
+	Timeout int    `yaml:"timeout,omitempty"` // Seconds to wait before the step fails (default 30)
 }
 
 // ItemOverride controls visibility for any manifest item.
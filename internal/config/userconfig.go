@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+// mergeUserConfig adds the tasks, task groups, workflows, prompts, prompt
+// partials, and resources of the user's config directory to a project
+// manifest. The project wins on conflicts: a user item is only added when the
+// project has none by that name. Other user settings are not merged, so
+// personal helpers never change how a project's own tasks run.
+func mergeUserConfig(manifest *Manifest) error {
+	dir := dirs.UserConfigDir()
+	if dir == "" {
+		return nil
+	}
+	user, err := loadDirectory(dir, dir, topLevelVisiting(), false)
+	if err != nil {
+		return fmt.Errorf("failed to load user config: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	manifest.Tasks = addMissing(manifest.Tasks, user.Tasks)
+	manifest.TaskGroups = addMissing(manifest.TaskGroups, user.TaskGroups)
+	manifest.Workflows = addMissing(manifest.Workflows, user.Workflows)
+	manifest.Prompts = addMissing(manifest.Prompts, user.Prompts)
+	manifest.PromptPartials = addMissing(manifest.PromptPartials, user.PromptPartials)
+	manifest.Resources = addMissing(manifest.Resources, user.Resources)
+
+	// A user workflow may name a task the project replaced with one it
+	// cannot run, so check the combination too
+	if err := Validate(manifest); err != nil {
+		return fmt.Errorf("invalid manifest with user config from %s: %w", dir, err)
+	}
+	return nil
+}
+
+// addMissing copies the entries of src whose keys dst lacks, and returns dst,
+// allocating it if needed.
+func addMissing[T any](dst, src map[string]T) map[string]T {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]T, len(src))
+	}
+	for name, item := range src {
+		if _, exists := dst[name]; !exists {
+			dst[name] = item
+		}
+	}
+	return dst
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+func TestLoaderMergesUserConfig(t *testing.T) {
+	userHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userHome)
+	t.Chdir(t.TempDir())
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), `version: "1.0"
+tasks:
+  build:
+    description: "Project build"
+    command: "go build"
+    type: oneshot
+`)
+	writeFile(filepath.Join(userHome, "runbook", "personal.yaml"), `version: "1.0"
+tasks:
+  build:
+    description: "My build"
+    command: "make"
+    type: oneshot
+  scratch:
+    description: "Open a scratch file"
+    command: "echo scratch"
+    type: oneshot
+workflows:
+  check:
+    description: "Build then scratch"
+    steps:
+      - task: build
+      - task: scratch
+`)
+
+	t.Run("project wins on conflicts", func(t *testing.T) {
+		manifest, loaded, err := LoadManifest("")
+		if err != nil {
+			t.Fatalf("LoadManifest() error = %v", err)
+		}
+		if !loaded {
+			t.Fatal("expected loaded=true")
+		}
+		if got := manifest.Tasks["build"].Description; got != "Project build" {
+			t.Errorf("build description = %q, want the project's", got)
+		}
+		if _, ok := manifest.Tasks["scratch"]; !ok {
+			t.Error("expected user task 'scratch' to be merged")
+		}
+		if _, ok := manifest.Workflows["check"]; !ok {
+			t.Error("expected user workflow 'check' to be merged")
+		}
+	})
+
+	t.Run("overrides apply to user tasks", func(t *testing.T) {
+		writeFile(dirs.OverridesFile, "tasks:\n  scratch:\n    disabled: true\n")
+		defer os.Remove(dirs.OverridesFile)
+
+		manifest, _, err := LoadManifest("")
+		if err != nil {
+			t.Fatalf("LoadManifest() error = %v", err)
+		}
+		if !manifest.Tasks["scratch"].Disabled {
+			t.Error("expected user task 'scratch' to be disabled by overrides")
+		}
+	})
+
+	t.Run("invalid user config fails the load", func(t *testing.T) {
+		writeFile(filepath.Join(userHome, "runbook", "broken.yaml"), "tasks:\n  bad:\n    command: \"true\"\n")
+		defer os.Remove(filepath.Join(userHome, "runbook", "broken.yaml"))
+
+		_, _, err := LoadManifest("")
+		if err == nil || !strings.Contains(err.Error(), "user config") {
+			t.Errorf("LoadManifest() error = %v, want a user config error", err)
+		}
+	})
+
+	t.Run("no project config ignores user config", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		manifest, loaded, err := LoadManifest("")
+		if err != nil {
+			t.Fatalf("LoadManifest() error = %v", err)
+		}
+		if loaded || len(manifest.Tasks) != 0 {
+			t.Errorf("loaded = %v with %d tasks, want an empty unloaded manifest", loaded, len(manifest.Tasks))
+		}
+	})
+}
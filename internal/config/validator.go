@@ -2,7 +2,12 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 )
 
 // Validate performs validation on a parsed manifest
@@ -53,13 +58,81 @@ func Validate(manifest *Manifest) error {
 		}
 	}
 
+	// Validate prompt partials and references to them
+	errors = append(errors, validatePromptPartials(manifest)...)
+
 	// Validate workflows
 	for workflowName, workflow := range manifest.Workflows {
-		if err := validateWorkflow(workflowName, workflow, manifest.Tasks); err != nil {
+		if err := validateWorkflow(workflowName, workflow, manifest.Tasks, manifest.Workflows); err != nil {
 			errors = append(errors, err.Error())
 		}
 	}
 
+	if manifest.Defaults.MaxTimeout < 0 {
+		errors = append(errors, "defaults: max_timeout cannot be negative")
+	}
+
+	if manifest.Defaults.LatencyBudget < -1 {
+		errors = append(errors, "defaults: latency_budget must be -1 (disabled) or a number of seconds")
+	}
+
+	if manifest.Defaults.StopGrace < 0 {
+		errors = append(errors, "defaults: stop_grace cannot be negative")
+	}
+	if manifest.Defaults.MaxConcurrentTasks < 0 {
+		errors = append(errors, "defaults: max_concurrent_tasks cannot be negative")
+	}
+	errors = append(errors, validateSessionSink(manifest.Defaults.SessionSink)...)
+	errors = append(errors, validateCrashNotify("defaults", manifest.Defaults.OnCrash, manifest.Tasks)...)
+	if manifest.Defaults.StrictSecurity {
+		errors = append(errors, LintManifest(manifest)...)
+	}
+
+	errors = append(errors, validateRedact("defaults", manifest.Defaults.Redact)...)
+	errors = append(errors, validateEnvPolicy("defaults", manifest.Defaults.EnvPolicy)...)
+
+	errors = append(errors, validateAdapters(manifest.Adapters)...)
+	errors = append(errors, validateVars(manifest)...)
+	errors = append(errors, validateToolNames(manifest)...)
+	errors = append(errors, validateServerSecurity(manifest.Server)...)
+	if manifest.Server.ShutdownGrace < -1 {
+		errors = append(errors, "server.shutdown_grace must be -1 (don't wait) or a number of seconds")
+	}
+	for _, capability := range manifest.Server.Capabilities {
+		switch capability {
+		case CapabilityTools, CapabilityPrompts, CapabilityResources, CapabilityLogging:
+		default:
+			errors = append(errors, fmt.Sprintf("server.capabilities: unknown capability '%s' (must be tools, prompts, resources, or logging)", capability))
+		}
+	}
+
+	if manifest.Exec.Timeout < 0 {
+		errors = append(errors, "exec: timeout cannot be negative")
+	}
+	if manifest.Exec.MaxTimeout < 0 {
+		errors = append(errors, "exec: max_timeout cannot be negative")
+	}
+	for _, pattern := range manifest.Exec.AllowedWorkingDirectories {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("exec: invalid allowed_working_directories pattern '%s'", pattern))
+		}
+	}
+	if manifest.Exec.RateLimit < -1 {
+		errors = append(errors, "exec: rate_limit must be -1 (unlimited) or a number of calls per minute")
+	}
+	for _, list := range []struct {
+		field    string
+		patterns []string
+	}{{"allow", manifest.Exec.Allow}, {"deny", manifest.Exec.Deny}} {
+		for _, pattern := range list.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errors = append(errors, fmt.Sprintf("exec: invalid %s pattern '%s': %v", list.field, pattern, err))
+			}
+		}
+	}
+
+	errors = append(errors, validateTesting(manifest.Testing)...)
+
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors:\n  - %s", strings.Join(errors, "\n  - "))
 	}
@@ -67,6 +140,31 @@ func Validate(manifest *Manifest) error {
 	return nil
 }
 
+// validateTesting checks the fault injection settings.
+func validateTesting(cfg TestingConfig) []string {
+	var errors []string
+	for pattern, fault := range cfg.Faults {
+		prefix := fmt.Sprintf("testing: fault '%s'", pattern)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: invalid pattern: %v", prefix, err))
+		}
+		if fault.Delay < 0 {
+			errors = append(errors, prefix+": delay cannot be negative")
+		}
+		if fault.FailRate < 0 || fault.FailRate > 1 {
+			errors = append(errors, prefix+": fail_rate must be between 0 and 1")
+		}
+		if fault.TimeoutRate < 0 || fault.TimeoutRate > 1 {
+			errors = append(errors, prefix+": timeout_rate must be between 0 and 1")
+		}
+		if fault.ExitCode < 0 || fault.ExitCode > 255 {
+			errors = append(errors, prefix+": exit_code must be between 0 and 255")
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
+
 func validateTask(name string, task Task, allTasks map[string]Task) error {
 	var errors []string
 
@@ -75,13 +173,29 @@ func validateTask(name string, task Task, allTasks map[string]Task) error {
 		errors = append(errors, fmt.Sprintf("task '%s': description is required", name))
 	}
 
-	if task.Command == "" {
+	switch {
+	case task.Type == TaskTypeCompose:
+		if task.Command != "" {
+			errors = append(errors, fmt.Sprintf("task '%s': command is not used by compose tasks (configure the stack under compose)", name))
+		}
+	case task.Type == TaskTypeFileOps:
+		if task.Command != "" {
+			errors = append(errors, fmt.Sprintf("task '%s': command is not used by file_ops tasks (list operations instead)", name))
+		}
+		errors = append(errors, validateFileOps(name, task.Operations)...)
+	case task.Command == "":
 		errors = append(errors, fmt.Sprintf("task '%s': command is required", name))
 	}
+	if len(task.Operations) > 0 && task.Type != TaskTypeFileOps {
+		errors = append(errors, fmt.Sprintf("task '%s': operations are only supported on file_ops tasks", name))
+	}
+	if task.Compose != nil && task.Type != TaskTypeCompose {
+		errors = append(errors, fmt.Sprintf("task '%s': compose is only supported on compose tasks", name))
+	}
 
 	// Validate task type (defaults are applied in parser.go applyDefaults)
-	if task.Type != "" && task.Type != TaskTypeOneShot && task.Type != TaskTypeDaemon {
-		errors = append(errors, fmt.Sprintf("task '%s': invalid type '%s' (must be 'oneshot' or 'daemon')", name, task.Type))
+	if task.Type != "" && task.Type != TaskTypeOneShot && task.Type != TaskTypeFileOps && !task.Type.IsDaemon() {
+		errors = append(errors, fmt.Sprintf("task '%s': invalid type '%s' (must be 'oneshot', 'daemon', 'compose', or 'file_ops')", name, task.Type))
 	}
 
 	// Validate parameters
@@ -93,6 +207,20 @@ func validateTask(name string, task Task, allTasks map[string]Task) error {
 			errors = append(errors, fmt.Sprintf("task '%s': parameter '%s' must have a description", name, paramName))
 		}
 	}
+	errors = append(errors, validateParamNames(fmt.Sprintf("task '%s'", name), task.Parameters)...)
+	errors = append(errors, validateParamRules(fmt.Sprintf("task '%s'", name), task.Parameters)...)
+	errors = append(errors, validatePresets(name, task)...)
+	errors = append(errors, validateParamSources(name, task)...)
+	errors = append(errors, validateHints(name, task.Hints)...)
+
+	if len(task.AllowedWorkingDirectories) > 0 && !task.ExposeWorkingDirectory {
+		errors = append(errors, fmt.Sprintf("task '%s': allowed_working_directories requires expose_working_directory", name))
+	}
+	for _, pattern := range task.AllowedWorkingDirectories {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errors = append(errors, fmt.Sprintf("task '%s': invalid allowed_working_directories pattern '%s'", name, pattern))
+		}
+	}
 
 	// Validate dependencies
 	for _, dep := range task.DependsOn {
@@ -101,6 +229,143 @@ func validateTask(name string, task Task, allTasks map[string]Task) error {
 		}
 	}
 
+	// Validate required daemons
+	errors = append(errors, validateRequiredDaemons(fmt.Sprintf("task '%s'", name), task.RequiresDaemon, allTasks)...)
+	if task.Type.IsDaemon() && requiresDaemonCycle(name, allTasks, map[string]bool{}) {
+		errors = append(errors, fmt.Sprintf("task '%s': requires_daemon forms a cycle", name))
+	}
+
+	if task.MaxTimeout < 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': max_timeout cannot be negative", name))
+	}
+
+	errors = append(errors, validateRedact(fmt.Sprintf("task '%s'", name), task.Redact)...)
+	errors = append(errors, validateEnvPolicy(fmt.Sprintf("task '%s'", name), task.EnvPolicy)...)
+
+	if len(task.Artifacts) > 0 && (task.Type.IsDaemon() || task.Type == TaskTypeFileOps) {
+		errors = append(errors, fmt.Sprintf("task '%s': artifacts are only supported on oneshot tasks", name))
+	}
+	for _, pattern := range task.Artifacts {
+		if _, err := filepath.Match(pattern, ""); pattern == "" || err != nil {
+			errors = append(errors, fmt.Sprintf("task '%s': invalid artifact pattern '%s'", name, pattern))
+		}
+	}
+
+	if len(task.Preconditions) > 0 && task.Type.IsDaemon() {
+		errors = append(errors, fmt.Sprintf("task '%s': preconditions are only supported on oneshot and file_ops tasks", name))
+	}
+	for i, check := range task.Preconditions {
+		kinds := 0
+		for _, set := range []bool{check.Command != "", check.FileExists != "", check.PortFree != 0} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			errors = append(errors, fmt.Sprintf("task '%s': preconditions[%d] must set exactly one of command, file_exists, or port_free", name, i))
+		}
+		if check.PortFree < 0 || check.PortFree > 65535 {
+			errors = append(errors, fmt.Sprintf("task '%s': preconditions[%d] port_free %d is out of range", name, i, check.PortFree))
+		}
+	}
+
+	if (len(task.ExpectedExitCodes) > 0 || len(task.WarningExitCodes) > 0) && (task.Type.IsDaemon() || task.Type == TaskTypeFileOps) {
+		errors = append(errors, fmt.Sprintf("task '%s': expected_exit_codes and warning_exit_codes are only supported on oneshot tasks", name))
+	}
+	switch task.OutputFormat {
+	case "", OutputFormatText:
+	case OutputFormatJSON:
+		if task.Type != TaskTypeOneShot {
+			errors = append(errors, fmt.Sprintf("task '%s': output_format is only supported on oneshot tasks", name))
+		} else if task.Interactive {
+			errors = append(errors, fmt.Sprintf("task '%s': output_format json needs captured output, which interactive tasks do not have", name))
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("task '%s': invalid output_format '%s' (must be text or json)", name, task.OutputFormat))
+	}
+
+	var overlapping []int
+	for code := range task.WarningExitCodes {
+		if _, exists := task.ExpectedExitCodes[code]; exists {
+			overlapping = append(overlapping, code)
+		}
+	}
+	sort.Ints(overlapping)
+	for _, code := range overlapping {
+		errors = append(errors, fmt.Sprintf("task '%s': exit code %d is in both expected_exit_codes and warning_exit_codes", name, code))
+	}
+
+	if task.Retries < 0 || task.RetryDelay < 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': retries and retry_delay cannot be negative", name))
+	}
+
+	if task.LatencyBudget < -1 {
+		errors = append(errors, fmt.Sprintf("task '%s': latency_budget must be -1 (disabled) or a number of seconds", name))
+	}
+
+	switch task.Lifetime {
+	case "", LifetimePersistent:
+	case LifetimeSession:
+		if !task.Type.IsDaemon() {
+			errors = append(errors, fmt.Sprintf("task '%s': lifetime is only supported on daemon tasks", name))
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("task '%s': invalid lifetime '%s' (must be persistent or session)", name, task.Lifetime))
+	}
+	if task.SessionGrace < 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': session_grace cannot be negative", name))
+	}
+	if task.Interactive {
+		switch {
+		case task.Type != TaskTypeDaemon && task.Type != TaskTypeOneShot:
+			errors = append(errors, fmt.Sprintf("task '%s': interactive is only supported on daemon and oneshot tasks", name))
+		case task.Type == TaskTypeOneShot && task.Runner == RunnerDocker:
+			errors = append(errors, fmt.Sprintf("task '%s': interactive oneshot tasks cannot use runner docker", name))
+		}
+	}
+	errors = append(errors, validateLogRotation(name, task)...)
+	errors = append(errors, validateLogOutput(name, task)...)
+	if task.OnCrash != nil && task.Type != TaskTypeDaemon {
+		errors = append(errors, fmt.Sprintf("task '%s': on_crash is only supported on daemon tasks", name))
+	}
+	errors = append(errors, validateCrashNotify(fmt.Sprintf("task '%s'", name), task.OnCrash, allTasks)...)
+	errors = append(errors, validateRateLimit(name, task)...)
+	errors = append(errors, validateCache(name, task)...)
+	errors = append(errors, validateReload(name, task)...)
+	errors = append(errors, validateStop(name, task)...)
+	if len(task.Ports) > 0 && task.Type != TaskTypeDaemon {
+		errors = append(errors, fmt.Sprintf("task '%s': ports are only supported on daemon tasks", name))
+	}
+	seenPorts := make(map[int]bool)
+	for _, port := range task.Ports {
+		if port < 1 || port > 65535 {
+			errors = append(errors, fmt.Sprintf("task '%s': port %d is out of range", name, port))
+		} else if seenPorts[port] {
+			errors = append(errors, fmt.Sprintf("task '%s': port %d is listed twice", name, port))
+		}
+		seenPorts[port] = true
+	}
+	if task.Async && (task.Type != TaskTypeOneShot || task.Interactive) {
+		errors = append(errors, fmt.Sprintf("task '%s': async is only supported on non-interactive oneshot tasks", name))
+	}
+
+	errors = append(errors, validateRunner(name, task)...)
+
+	// Validate ready check
+	if task.Ready != nil {
+		if !task.Type.IsDaemon() {
+			errors = append(errors, fmt.Sprintf("task '%s': ready is only supported on daemon tasks", name))
+		}
+		if task.Ready.Timeout < 0 {
+			errors = append(errors, fmt.Sprintf("task '%s': ready timeout cannot be negative", name))
+		}
+		if task.Ready.LogPattern != "" {
+			if _, err := regexp.Compile(task.Ready.LogPattern); err != nil {
+				errors = append(errors, fmt.Sprintf("task '%s': invalid ready log_pattern: %v", name, err))
+			}
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
@@ -148,6 +413,13 @@ func validatePrompt(name string, prompt Prompt) error {
 		errors = append(errors, fmt.Sprintf("prompt '%s': content and file are mutually exclusive", name))
 	}
 
+	for locale, content := range prompt.ContentByLocale {
+		if locale == "" || content == "" {
+			errors = append(errors, fmt.Sprintf("prompt '%s': content_by_locale entries need a locale and content", name))
+			break
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
 	}
@@ -177,7 +449,7 @@ func validateResource(name string, resource Resource) error {
 	return nil
 }
 
-func validateWorkflow(name string, workflow Workflow, allTasks map[string]Task) error {
+func validateWorkflow(name string, workflow Workflow, allTasks map[string]Task, allWorkflows map[string]Workflow) error {
 	var errors []string
 
 	if workflow.Description == "" {
@@ -189,9 +461,33 @@ func validateWorkflow(name string, workflow Workflow, allTasks map[string]Task)
 	}
 
 	// Validate each step
+	seen := make(map[string]bool)
 	for i, step := range workflow.Steps {
+		errors = append(errors, validateStepOutputRefs(name, i, step, seen)...)
+		errors = append(errors, validateStepCondition(name, i, step, seen)...)
+		if step.ID != "" && seen[step.ID] {
+			errors = append(errors, fmt.Sprintf("workflow '%s': step %d: duplicate step id '%s'", name, i, step.ID))
+		}
+		seen[step.Name()] = true
+
+		if step.Retries < 0 || step.RetryDelay < 0 {
+			errors = append(errors, fmt.Sprintf("workflow '%s': step %d: retries and retry_delay cannot be negative", name, i))
+		}
+		if step.Timeout < 0 {
+			errors = append(errors, fmt.Sprintf("workflow '%s': step %d: timeout cannot be negative", name, i))
+		}
+
+		if step.Builtin() {
+			errors = append(errors, validateBuiltinStep(name, i, step, allTasks)...)
+			continue
+		}
+		if step.Workflow != "" {
+			errors = append(errors, validateWorkflowStep(name, i, step, allTasks, allWorkflows)...)
+			continue
+		}
+
 		if step.Task == "" {
-			errors = append(errors, fmt.Sprintf("workflow '%s': step %d must reference a task", name, i))
+			errors = append(errors, fmt.Sprintf("workflow '%s': step %d must reference a task or workflow", name, i))
 			continue
 		}
 
@@ -201,9 +497,20 @@ func validateWorkflow(name string, workflow Workflow, allTasks map[string]Task)
 			continue
 		}
 
-		if task.Type == TaskTypeDaemon {
+		if task.Type.IsDaemon() {
 			errors = append(errors, fmt.Sprintf("workflow '%s': step %d references daemon task '%s' (only oneshot tasks allowed)", name, i, step.Task))
+		} else if task.Interactive {
+			errors = append(errors, fmt.Sprintf("workflow '%s': step %d references interactive task '%s', which needs a terminal", name, i, step.Task))
 		}
+
+		errors = append(errors, validateRequiredDaemons(fmt.Sprintf("workflow '%s': step %d", name, i), step.RequiresDaemon, allTasks)...)
+		if step.Fresh && len(step.RequiresDaemon) == 0 && len(task.RequiresDaemon) == 0 {
+			errors = append(errors, fmt.Sprintf("workflow '%s': step %d: fresh requires daemons to restart (set requires_daemon)", name, i))
+		}
+	}
+
+	if workflowCycle(name, allWorkflows, map[string]bool{}) {
+		errors = append(errors, fmt.Sprintf("workflow '%s': workflow steps form a cycle", name))
 	}
 
 	// Validate workflow parameters
@@ -214,7 +521,12 @@ func validateWorkflow(name string, workflow Workflow, allTasks map[string]Task)
 		if param.Description == "" {
 			errors = append(errors, fmt.Sprintf("workflow '%s': parameter '%s' must have a description", name, paramName))
 		}
+		if param.Source != "" {
+			errors = append(errors, fmt.Sprintf("workflow '%s': parameter '%s': source is only supported on task parameters", name, paramName))
+		}
 	}
+	errors = append(errors, validateParamNames(fmt.Sprintf("workflow '%s'", name), workflow.Parameters)...)
+	errors = append(errors, validateParamRules(fmt.Sprintf("workflow '%s'", name), workflow.Parameters)...)
 
 	if len(errors) > 0 {
 		return fmt.Errorf("%s", strings.Join(errors, "; "))
@@ -222,3 +534,285 @@ func validateWorkflow(name string, workflow Workflow, allTasks map[string]Task)
 
 	return nil
 }
+
+// validateWorkflowStep checks a step that runs another workflow.
+func validateWorkflowStep(name string, index int, step WorkflowStep, allTasks map[string]Task, allWorkflows map[string]Workflow) []string {
+	prefix := fmt.Sprintf("workflow '%s': step %d", name, index)
+	var errors []string
+	if step.Task != "" {
+		errors = append(errors, fmt.Sprintf("%s: task and workflow are mutually exclusive", prefix))
+	}
+	if _, exists := allWorkflows[step.Workflow]; !exists {
+		errors = append(errors, fmt.Sprintf("%s references non-existent workflow '%s'", prefix, step.Workflow))
+	}
+	if step.Project != "" {
+		errors = append(errors, fmt.Sprintf("%s: project is only supported on task steps", prefix))
+	}
+	errors = append(errors, validateRequiredDaemons(prefix, step.RequiresDaemon, allTasks)...)
+	if step.Fresh && len(step.RequiresDaemon) == 0 {
+		errors = append(errors, fmt.Sprintf("%s: fresh requires daemons to restart (set requires_daemon)", prefix))
+	}
+	return errors
+}
+
+// validateBuiltinStep checks a wait or wait_for step.
+func validateBuiltinStep(name string, index int, step WorkflowStep, allTasks map[string]Task) []string {
+	prefix := fmt.Sprintf("workflow '%s': step %d", name, index)
+	var errors []string
+	if step.Wait != nil && step.WaitFor != nil {
+		errors = append(errors, fmt.Sprintf("%s: wait and wait_for are mutually exclusive", prefix))
+	}
+	if step.Task != "" || step.Workflow != "" {
+		errors = append(errors, fmt.Sprintf("%s: %s steps cannot also run a task or workflow", prefix, step.Target()))
+	}
+	if len(step.Params) > 0 || step.Project != "" || step.Retries > 0 || step.Fresh {
+		errors = append(errors, fmt.Sprintf("%s: params, project, retries, and fresh are not supported on %s steps", prefix, step.Target()))
+	}
+	if step.Wait != nil && step.Wait.Seconds <= 0 {
+		errors = append(errors, fmt.Sprintf("%s: wait.seconds must be positive", prefix))
+	}
+	if w := step.WaitFor; w != nil {
+		if w.URL == "" && w.Port == 0 && w.File == "" {
+			errors = append(errors, fmt.Sprintf("%s: wait_for needs a url, port, or file", prefix))
+		}
+		if w.Port < 0 || w.Port > 65535 {
+			errors = append(errors, fmt.Sprintf("%s: wait_for.port %d is out of range", prefix, w.Port))
+		}
+		if w.Timeout < 0 {
+			errors = append(errors, fmt.Sprintf("%s: wait_for.timeout cannot be negative", prefix))
+		}
+	}
+	errors = append(errors, validateRequiredDaemons(prefix, step.RequiresDaemon, allTasks)...)
+	return errors
+}
+
+// validateRequiredDaemons checks that every requires_daemon entry names an
+// existing daemon task. prefix identifies the owner in error messages.
+func validateRequiredDaemons(prefix string, required []string, allTasks map[string]Task) []string {
+	var errors []string
+	for _, dep := range required {
+		daemon, exists := allTasks[dep]
+		if !exists {
+			errors = append(errors, fmt.Sprintf("%s: required daemon '%s' does not exist", prefix, dep))
+			continue
+		}
+		if !daemon.Type.IsDaemon() {
+			errors = append(errors, fmt.Sprintf("%s: required daemon '%s' is not a daemon task", prefix, dep))
+		}
+		// Required daemons are started without arguments
+		for paramName, param := range daemon.Parameters {
+			if param.Required && param.Default == nil {
+				errors = append(errors, fmt.Sprintf("%s: required daemon '%s' has required parameter '%s' without a default", prefix, dep, paramName))
+			}
+		}
+	}
+	return errors
+}
+
+// requiresDaemonCycle reports whether following requires_daemon edges from
+// name leads back to a task already on the current path.
+func requiresDaemonCycle(name string, allTasks map[string]Task, path map[string]bool) bool {
+	if path[name] {
+		return true
+	}
+	path[name] = true
+	defer delete(path, name)
+	for _, dep := range allTasks[name].RequiresDaemon {
+		if requiresDaemonCycle(dep, allTasks, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEnvPolicy checks an env_policy's mode and variable patterns.
+func validateEnvPolicy(owner string, policy *EnvPolicy) []string {
+	if policy == nil {
+		return nil
+	}
+	var errors []string
+	switch policy.Mode {
+	case EnvPolicyAllowlist, EnvPolicyDenylist:
+	case EnvPolicyAll, EnvPolicyNone:
+		if len(policy.Vars) > 0 {
+			errors = append(errors, fmt.Sprintf("%s: env_policy vars only apply to the allowlist and denylist modes", owner))
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("%s: invalid env_policy mode '%s' (must be all, allowlist, denylist, or none)", owner, policy.Mode))
+	}
+	for _, pattern := range policy.Vars {
+		if _, err := filepath.Match(pattern, ""); pattern == "" || err != nil {
+			errors = append(errors, fmt.Sprintf("%s: invalid env_policy variable '%s'", owner, pattern))
+		}
+	}
+	return errors
+}
+
+// validateRedact checks that redact patterns are valid regular expressions.
+func validateRedact(owner string, patterns []string) []string {
+	var errors []string
+	for _, pattern := range patterns {
+		if pattern == "" {
+			errors = append(errors, fmt.Sprintf("%s: redact patterns cannot be empty", owner))
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: invalid redact pattern '%s': %v", owner, pattern, err))
+		}
+	}
+	return errors
+}
+
+// validateFileOps checks the operations of a file_ops task.
+func validateFileOps(name string, ops []FileOp) []string {
+	var errors []string
+	if len(ops) == 0 {
+		errors = append(errors, fmt.Sprintf("task '%s': file_ops tasks need at least one operation", name))
+	}
+	for i, op := range ops {
+		prefix := fmt.Sprintf("task '%s': operation %d (%s)", name, i, op.Op)
+		switch op.Op {
+		case FileOpCopy, FileOpTemplate:
+			if op.Src == "" || op.Dest == "" {
+				errors = append(errors, prefix+": src and dest are required")
+			}
+		case FileOpMkdir, FileOpDelete:
+			if op.Path == "" {
+				errors = append(errors, prefix+": path is required")
+			}
+		case FileOpChmod:
+			if op.Path == "" || op.Mode == "" {
+				errors = append(errors, prefix+": path and mode are required")
+			}
+		default:
+			errors = append(errors, fmt.Sprintf("task '%s': operation %d: invalid op '%s' (must be copy, template, mkdir, chmod, or delete)", name, i, op.Op))
+			continue
+		}
+		if op.Mode != "" {
+			if _, err := strconv.ParseUint(op.Mode, 8, 32); err != nil {
+				errors = append(errors, fmt.Sprintf("%s: invalid mode '%s' (must be octal, e.g. 0755)", prefix, op.Mode))
+			}
+		}
+		if op.Recursive && op.Op != FileOpDelete {
+			errors = append(errors, prefix+": recursive is only supported on delete")
+		}
+	}
+	return errors
+}
+
+// validateRunner checks a task's runner and container settings.
+func validateRunner(name string, task Task) []string {
+	var errors []string
+	switch task.Runner {
+	case "", RunnerShell:
+		if task.Container != nil {
+			errors = append(errors, fmt.Sprintf("task '%s': container requires runner: docker", name))
+		}
+	case RunnerDocker:
+		if task.Type.IsDaemon() {
+			errors = append(errors, fmt.Sprintf("task '%s': runner docker is only supported on oneshot tasks", name))
+		}
+		if task.Container == nil || task.Container.Image == "" {
+			errors = append(errors, fmt.Sprintf("task '%s': runner docker requires container.image", name))
+			break
+		}
+		for _, mount := range task.Container.Mounts {
+			if parts := strings.Split(mount, ":"); len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+				errors = append(errors, fmt.Sprintf("task '%s': invalid container mount '%s' (must be source:target)", name, mount))
+			}
+		}
+	default:
+		errors = append(errors, validateAgentRunner(name, task)...)
+	}
+	return errors
+}
+
+// validateStepOutputRefs checks that {{ steps.<name>.* }} references in a
+// step's params name an earlier step.
+func validateStepOutputRefs(workflow string, index int, step WorkflowStep, earlier map[string]bool) []string {
+	var errors []string
+	for param, value := range step.Params {
+		for _, m := range StepOutputPattern.FindAllStringSubmatch(value, -1) {
+			if !earlier[m[1]] {
+				errors = append(errors, fmt.Sprintf("workflow '%s': step %d: param '%s' references step '%s', which does not run before it", workflow, index, param, m[1]))
+			}
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
+
+// validateStepCondition checks that a step's when expression parses and that
+// its {{ .steps.<name> }} references name an earlier step.
+func validateStepCondition(workflow string, index int, step WorkflowStep, earlier map[string]bool) []string {
+	if step.When == "" {
+		return nil
+	}
+	if _, err := template.New("when").Parse(step.When); err != nil {
+		return []string{fmt.Sprintf("workflow '%s': step %d: invalid when expression: %v", workflow, index, err)}
+	}
+	var errors []string
+	for _, m := range StepConditionRefPattern.FindAllStringSubmatch(step.When, -1) {
+		if !earlier[m[1]] {
+			errors = append(errors, fmt.Sprintf("workflow '%s': step %d: when references step '%s', which does not run before it", workflow, index, m[1]))
+		}
+	}
+	return errors
+}
+
+// validateServerSecurity checks the server auth and TLS settings.
+// validateSessionSink checks that a session sink has a destination and a
+// usable URL.
+func validateSessionSink(sink *SessionSink) []string {
+	if sink == nil {
+		return nil
+	}
+	var errors []string
+	if sink.File == "" && sink.URL == "" {
+		errors = append(errors, "defaults.session_sink: requires file or url")
+	}
+	if sink.URL != "" && !strings.HasPrefix(sink.URL, "http://") && !strings.HasPrefix(sink.URL, "https://") {
+		errors = append(errors, "defaults.session_sink: url must be an http(s) URL")
+	}
+	if sink.URL == "" && (sink.TokenEnv != "" || sink.Timeout != 0) {
+		errors = append(errors, "defaults.session_sink: token_env and timeout require url")
+	}
+	if sink.Timeout < 0 {
+		errors = append(errors, "defaults.session_sink: timeout cannot be negative")
+	}
+	return errors
+}
+
+func validateServerSecurity(server ServerConfig) []string {
+	var errors []string
+
+	tls := server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		errors = append(errors, "server.tls: cert_file and key_file must be set together")
+	}
+	if tls.ClientCAFile != "" && tls.CertFile == "" {
+		errors = append(errors, "server.tls: client_ca_file requires cert_file and key_file")
+	}
+
+	auth := server.Auth
+	switch auth.Type {
+	case "", AuthTypeBearer:
+	case AuthTypeMTLS:
+		if tls.ClientCAFile == "" {
+			errors = append(errors, "server.auth: mtls requires server.tls.client_ca_file")
+		}
+	case AuthTypeCommand:
+		if auth.Command == "" {
+			errors = append(errors, "server.auth: command is required for type command")
+		}
+	case AuthTypeWebhook:
+		if !strings.HasPrefix(auth.URL, "http://") && !strings.HasPrefix(auth.URL, "https://") {
+			errors = append(errors, "server.auth: url must be an http(s) URL for type webhook")
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("server.auth: invalid type '%s' (must be bearer, mtls, command, or webhook)", auth.Type))
+	}
+	if auth.Timeout < 0 {
+		errors = append(errors, "server.auth: timeout cannot be negative")
+	}
+
+	return errors
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"text/template/parse"
+)
+
+// VarsKey is the name templates reach the manifest's vars under, as in
+// {{.Vars.registry}}. Parameters cannot use it.
+const VarsKey = "Vars"
+
+// varNamePattern is the form of var names, which must work as template
+// field names.
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateVars checks the names and templates of the manifest's vars, and
+// that no task parameter hides them.
+func validateVars(manifest *Manifest) []string {
+	var errors []string
+	names := make([]string, 0, len(manifest.Vars))
+	for name := range manifest.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !varNamePattern.MatchString(name) {
+			errors = append(errors, fmt.Sprintf("vars: invalid name '%s' (use letters, digits, and underscores, not starting with a digit)", name))
+			continue
+		}
+		tree := parse.New(name)
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(manifest.Vars[name], "", "", map[string]*parse.Tree{}); err != nil {
+			errors = append(errors, fmt.Sprintf("vars: '%s' is not a valid template: %v", name, err))
+		}
+	}
+	for taskName, task := range manifest.Tasks {
+		if _, ok := task.Parameters[VarsKey]; ok {
+			errors = append(errors, fmt.Sprintf("task '%s': parameter name '%s' is reserved for vars", taskName, VarsKey))
+		}
+	}
+	return errors
+}
@@ -11,3 +11,7 @@ const ConfigDir = ".runbook"
 // OverridesFile is the path to the optional overrides file,
 // relative to the project working directory.
 const OverridesFile = ".runbook.overrides.yaml"
+
+// LockFile records the resolved versions of remote imports,
+// relative to the project working directory.
+const LockFile = ".runbook.lock"
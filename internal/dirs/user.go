@@ -0,0 +1,34 @@
+package dirs
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// UserConfigDir returns the directory of the user's own task configuration,
+// which is merged beneath every project's config: $XDG_CONFIG_HOME/runbook,
+// or ~/.config/runbook. It returns "" if neither can be determined.
+func UserConfigDir() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "runbook")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "runbook")
+}
+
+// UserStateDir returns the directory of runbook's per-user state, such as
+// the index of projects with daemons: $XDG_STATE_HOME/runbook, or
+// ~/.local/state/runbook. It returns "" if neither can be determined.
+func UserStateDir() string {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "runbook")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "runbook")
+}
@@ -0,0 +1,323 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Diff line operations.
+const (
+	DiffEqual   = " "
+	DiffRemoved = "-"
+	DiffAdded   = "+"
+)
+
+// DiffContext is the number of unchanged lines shown around each change.
+const DiffContext = 3
+
+// SessionDiff is the line diff of two sessions' logs.
+type SessionDiff struct {
+	TaskName  string     `json:"task_name"`
+	From      string     `json:"from"` // Older session
+	To        string     `json:"to"`   // Newer session
+	Added     int        `json:"added"`
+	Removed   int        `json:"removed"`
+	NewErrors []string   `json:"new_errors,omitempty"` // Added lines that look like errors
+	Hunks     []DiffHunk `json:"hunks,omitempty"`
+	Truncated bool       `json:"truncated,omitempty"` // Hunks were cut to a line limit
+}
+
+// DiffHunk is a run of changes with the unchanged lines around it. Line
+// numbers are 1-based positions of the hunk's first line in each log.
+type DiffHunk struct {
+	FromLine int        `json:"from_line"`
+	ToLine   int        `json:"to_line"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// DiffLine is one line of a hunk. Text is the original line, from the
+// newer log unless it was removed.
+type DiffLine struct {
+	Op    string `json:"op"`
+	Text  string `json:"text"`
+	Error bool   `json:"error,omitempty"` // An added line that looks like an error
+}
+
+var (
+	ansiPattern      = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?|\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`)
+	durationPattern  = regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|us|ms|s|m|h)\b`)
+	uuidPattern      = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	errorLinePattern = regexp.MustCompile(`(?i)\b(error|errors|fail|failed|failure|panic|fatal|exception|traceback)\b`)
+)
+
+// normalizeLogLine strips ANSI escapes and masks the parts of a line that
+// change between otherwise identical runs: timestamps, durations, and UUIDs.
+func normalizeLogLine(line string) string {
+	line = ansiPattern.ReplaceAllString(line, "")
+	line = timestampPattern.ReplaceAllString(line, "<time>")
+	line = uuidPattern.ReplaceAllString(line, "<uuid>")
+	return durationPattern.ReplaceAllString(line, "<duration>")
+}
+
+// DiffSessions diffs the logs of two sessions of the same task, from the
+// older to the newer one. Lines are compared after normalizeLogLine. At most
+// maxLines hunk lines are returned (0 = all); counts and new errors always
+// cover the whole diff.
+func DiffSessions(sessionA, sessionB string, maxLines int) (*SessionDiff, error) {
+	a, err := ReadSessionMetadata(sessionA)
+	if err != nil {
+		return nil, fmt.Errorf("session '%s': %w", sessionA, err)
+	}
+	b, err := ReadSessionMetadata(sessionB)
+	if err != nil {
+		return nil, fmt.Errorf("session '%s': %w", sessionB, err)
+	}
+	if a.TaskName != b.TaskName {
+		return nil, fmt.Errorf("sessions belong to different tasks ('%s' and '%s')", a.TaskName, b.TaskName)
+	}
+	if b.StartTime.Before(a.StartTime) {
+		a, b = b, a
+	}
+
+	from, err := readLogLines(GetSessionLogPath(a.SessionID))
+	if err != nil {
+		return nil, err
+	}
+	to, err := readLogLines(GetSessionLogPath(b.SessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SessionDiff{TaskName: a.TaskName, From: a.SessionID, To: b.SessionID}
+	lines := diffLines(from, to)
+	for i, l := range lines {
+		switch l.Op {
+		case DiffAdded:
+			diff.Added++
+			if errorLinePattern.MatchString(ansiPattern.ReplaceAllString(l.Text, "")) {
+				lines[i].Error = true
+				diff.NewErrors = append(diff.NewErrors, l.Text)
+			}
+		case DiffRemoved:
+			diff.Removed++
+		}
+	}
+	diff.Hunks, diff.Truncated = groupHunks(lines, DiffContext, maxLines)
+	return diff, nil
+}
+
+// readLogLines reads a log file's lines; a missing log has none.
+func readLogLines(path string) ([]string, error) {
+	file, err := OpenLog(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+	return lines, nil
+}
+
+// maxEditDistance bounds the work of diffLines. Logs further apart than
+// this are diffed as their common start and end around one replaced block.
+const maxEditDistance = 4000
+
+// diffLines returns the shortest edit script from a to b, comparing
+// normalized lines.
+func diffLines(a, b []string) []DiffLine {
+	na := make([]string, len(a))
+	for i, line := range a {
+		na[i] = normalizeLogLine(line)
+	}
+	nb := make([]string, len(b))
+	for i, line := range b {
+		nb[i] = normalizeLogLine(line)
+	}
+
+	// Lines the logs start and end with are unchanged
+	prefix := 0
+	for prefix < len(na) && prefix < len(nb) && na[prefix] == nb[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(na)-prefix && suffix < len(nb)-prefix && na[len(na)-1-suffix] == nb[len(nb)-1-suffix] {
+		suffix++
+	}
+
+	var lines []DiffLine
+	for _, line := range b[:prefix] {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: line})
+	}
+	lines = append(lines, myers(na[prefix:len(na)-suffix], nb[prefix:len(nb)-suffix], a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range b[len(b)-suffix:] {
+		lines = append(lines, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return lines
+}
+
+// myers returns the shortest edit script from na to nb (Myers' algorithm),
+// with the original lines a and b as text.
+func myers(na, nb, a, b []string) []DiffLine {
+	n, m := len(na), len(nb)
+	limit := min(n+m, maxEditDistance)
+
+	// v[k] is the furthest x reached on diagonal k; trace[d] keeps the
+	// diagonals -d..d as they were before step d
+	v := map[int]int{1: 0}
+	var trace []map[int]int
+	for d := 0; d <= limit; d++ {
+		snapshot := make(map[int]int, 2*d+2)
+		for k := -d - 1; k <= d+1; k++ {
+			if x, ok := v[k]; ok {
+				snapshot[k] = x
+			}
+		}
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1] < v[k+1]) {
+				x = v[k+1]
+			} else {
+				x = v[k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && na[x] == nb[y] {
+				x++
+				y++
+			}
+			v[k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+
+	// Too far apart: replace the whole block
+	lines := make([]DiffLine, 0, n+m)
+	for _, line := range a {
+		lines = append(lines, DiffLine{Op: DiffRemoved, Text: line})
+	}
+	for _, line := range b {
+		lines = append(lines, DiffLine{Op: DiffAdded, Text: line})
+	}
+	return lines
+}
+
+// backtrack walks the Myers trace back from the end of both logs to build
+// the edit script.
+func backtrack(trace []map[int]int, a, b []string) []DiffLine {
+	var reversed []DiffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1] < v[k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, DiffLine{Op: DiffEqual, Text: b[y]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, DiffLine{Op: DiffAdded, Text: b[y]})
+		} else {
+			x--
+			reversed = append(reversed, DiffLine{Op: DiffRemoved, Text: a[x]})
+		}
+	}
+
+	lines := make([]DiffLine, len(reversed))
+	for i, l := range reversed {
+		lines[len(reversed)-1-i] = l
+	}
+	return lines
+}
+
+// groupHunks keeps the changed lines of an edit script with up to context
+// unchanged lines around them, cut after maxLines lines (0 = no limit).
+func groupHunks(lines []DiffLine, context, maxLines int) ([]DiffHunk, bool) {
+	var hunks []DiffHunk
+	total := 0
+	fromLine, toLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].Op == DiffEqual {
+			fromLine++
+			toLine++
+			i++
+			continue
+		}
+
+		// Start the hunk up to context lines before the change
+		start := i
+		for start > 0 && i-start < context && lines[start-1].Op == DiffEqual {
+			start--
+		}
+		hunk := DiffHunk{FromLine: fromLine - (i - start), ToLine: toLine - (i - start)}
+
+		// Extend while changes are less than two contexts apart
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != DiffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Op == DiffEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*context {
+				end = min(end+context, run)
+				break
+			}
+			end = run
+		}
+
+		for _, l := range lines[i:end] {
+			switch l.Op {
+			case DiffEqual:
+				fromLine++
+				toLine++
+			case DiffRemoved:
+				fromLine++
+			case DiffAdded:
+				toLine++
+			}
+		}
+		hunk.Lines = lines[start:end]
+		if maxLines > 0 && total+len(hunk.Lines) > maxLines {
+			if remaining := maxLines - total; remaining > 0 {
+				hunk.Lines = hunk.Lines[:remaining]
+				hunks = append(hunks, hunk)
+			}
+			return hunks, true
+		}
+		total += len(hunk.Lines)
+		hunks = append(hunks, hunk)
+		i = end
+	}
+	return hunks, false
+}
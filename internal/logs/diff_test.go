@@ -0,0 +1,104 @@
+package logs
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeLogLine(t *testing.T) {
+	a := normalizeLogLine("\x1b[32m2024-05-01T10:00:00.123Z\x1b[0m ok  pkg/api 0.412s id=3f2b8c1e-1d2a-4c3b-9e8f-0a1b2c3d4e5f")
+	b := normalizeLogLine("2024-05-01T11:30:12.999Z ok  pkg/api 1.2s id=7a6b5c4d-3e2f-4a1b-8c9d-0e1f2a3b4c5d")
+	if a != b {
+		t.Errorf("expected lines to normalize equal:\n%q\n%q", a, b)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	from := []string{"start", "a", "b", "c", "end"}
+	to := []string{"start", "a", "c", "ERROR: boom", "end"}
+	var ops []string
+	for _, l := range diffLines(from, to) {
+		ops = append(ops, l.Op+l.Text)
+	}
+	want := " start, a,-b, c,+ERROR: boom, end"
+	if got := strings.Join(ops, ","); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if lines := diffLines(nil, nil); len(lines) != 0 {
+		t.Errorf("expected no lines for empty logs, got %+v", lines)
+	}
+}
+
+func TestGroupHunks(t *testing.T) {
+	var from, to []string
+	for i := 0; i < 20; i++ {
+		line := "line " + string(rune('a'+i))
+		from = append(from, line)
+		to = append(to, line)
+	}
+	to[2] = "changed 2"
+	to[15] = "changed 15"
+
+	hunks, truncated := groupHunks(diffLines(from, to), DiffContext, 0)
+	if truncated || len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d (truncated=%v)", len(hunks), truncated)
+	}
+	if hunks[0].FromLine != 1 || len(hunks[0].Lines) != 2+2+3 {
+		t.Errorf("unexpected first hunk: %+v", hunks[0])
+	}
+	if hunks[1].FromLine != 13 || hunks[1].ToLine != 13 {
+		t.Errorf("unexpected second hunk start: %+v", hunks[1])
+	}
+
+	hunks, truncated = groupHunks(diffLines(from, to), DiffContext, 5)
+	if !truncated || len(hunks) != 1 || len(hunks[0].Lines) != 5 {
+		t.Errorf("expected one hunk cut to 5 lines, got %+v (truncated=%v)", hunks, truncated)
+	}
+}
+
+func TestDiffSessions(t *testing.T) {
+	oldWd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	writeSession := func(id, task string, start time.Time, log string) {
+		t.Helper()
+		if err := CreateSessionDirectory(id); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteSessionMetadata(id, &SessionMetadata{SessionID: id, TaskName: task, StartTime: start}); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(GetSessionLogPath(id), []byte(log), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	writeSession("old", "test", now.Add(-time.Hour), "=== RUN TestA\n--- PASS: TestA (0.01s)\nok  pkg 0.5s\n")
+	writeSession("new", "test", now, "=== RUN TestA\n--- FAIL: TestA (0.02s)\n    a_test.go:9: boom\nFAIL pkg 0.7s\n")
+	writeSession("other", "build", now, "")
+
+	// Argument order does not matter: the older session is the base
+	diff, err := DiffSessions("new", "old", 0)
+	if err != nil {
+		t.Fatalf("DiffSessions: %v", err)
+	}
+	if diff.From != "old" || diff.To != "new" {
+		t.Errorf("expected old -> new, got %s -> %s", diff.From, diff.To)
+	}
+	if diff.Added != 3 || diff.Removed != 2 {
+		t.Errorf("expected +3 -2, got +%d -%d", diff.Added, diff.Removed)
+	}
+	if len(diff.NewErrors) != 2 || !strings.Contains(diff.NewErrors[0], "FAIL: TestA") {
+		t.Errorf("expected the failing lines as new errors, got %v", diff.NewErrors)
+	}
+
+	if _, err := DiffSessions("old", "other", 0); err == nil || !strings.Contains(err.Error(), "different tasks") {
+		t.Errorf("expected an error for sessions of different tasks, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretEnvName matches environment variable names whose values are masked
+// in session metadata even when no redact pattern covers them.
+var secretEnvName = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|API_?KEY|PRIVATE_?KEY)`)
+
+// CaptureEnviron returns the KEY=value environment of a session's process as
+// a map for its metadata. Later entries win, as they do for the process.
+// Each entry goes through redactor (which may be nil), and values of
+// variables whose names look like secrets are masked.
+func CaptureEnviron(env []string, redactor *Redactor) map[string]string {
+	captured := make(map[string]string, len(env))
+	for _, entry := range env {
+		key, value, _ := strings.Cut(redactor.RedactString(entry), "=")
+		if secretEnvName.MatchString(key) && value != "" {
+			value = RedactedText
+		}
+		captured[key] = value
+	}
+	return captured
+}
+
+// RedactArgs returns args with every argument passed through redactor, which
+// may be nil.
+func RedactArgs(args []string, redactor *Redactor) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = redactor.RedactString(arg)
+	}
+	return out
+}
+
+// SessionChange is a difference in how two sessions were run. Before and
+// After are nil where the field was not set in that session.
+type SessionChange struct {
+	Field  string  `json:"field"` // "command", "working_dir", "parameters.<name>", or "env.<NAME>"
+	Before *string `json:"before,omitempty"`
+	After  *string `json:"after,omitempty"`
+}
+
+// CompareSessions lists what differs between how before and after were run:
+// their command, working directory, parameters, and environment.
+func CompareSessions(before, after *SessionMetadata) []SessionChange {
+	var changes []SessionChange
+	add := func(field string, b, a *string) {
+		if (b == nil) != (a == nil) || (b != nil && *b != *a) {
+			changes = append(changes, SessionChange{Field: field, Before: b, After: a})
+		}
+	}
+	add("command", optional(before.Command), optional(after.Command))
+	add("working_dir", optional(before.WorkingDir), optional(after.WorkingDir))
+
+	params := func(m *SessionMetadata) map[string]string {
+		out := make(map[string]string, len(m.Parameters))
+		for key, value := range m.Parameters {
+			out[key] = fmt.Sprint(value)
+		}
+		return out
+	}
+	for _, c := range compareMaps(params(before), params(after)) {
+		add("parameters."+c.key, c.before, c.after)
+	}
+	for _, c := range compareMaps(before.Env, after.Env) {
+		add("env."+c.key, c.before, c.after)
+	}
+	return changes
+}
+
+// optional returns nil for an empty string and &s otherwise.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type mapChange struct {
+	key           string
+	before, after *string
+}
+
+// compareMaps pairs up the values of every key in either map, sorted by key.
+func compareMaps(before, after map[string]string) []mapChange {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []mapChange
+	for _, key := range sorted {
+		var c mapChange
+		c.key = key
+		if value, ok := before[key]; ok {
+			c.before = &value
+		}
+		if value, ok := after[key]; ok {
+			c.after = &value
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
@@ -0,0 +1,70 @@
+package logs
+
+import "testing"
+
+func TestCaptureEnviron(t *testing.T) {
+	redactor, err := NewRedactor([]string{`ghp_\w+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := CaptureEnviron([]string{
+		"PATH=/usr/bin",
+		"GIT_AUTH=ghp_abc123",
+		"AWS_SECRET_ACCESS_KEY=xyz",
+		"EMPTY_TOKEN=",
+		"PATH=/opt/bin",
+	}, redactor)
+
+	want := map[string]string{
+		"PATH":                  "/opt/bin",
+		"GIT_AUTH":              RedactedText,
+		"AWS_SECRET_ACCESS_KEY": RedactedText,
+		"EMPTY_TOKEN":           "",
+	}
+	if len(env) != len(want) {
+		t.Errorf("env = %v, want %v", env, want)
+	}
+	for key, value := range want {
+		if env[key] != value {
+			t.Errorf("%s = %q, want %q", key, env[key], value)
+		}
+	}
+
+	if got := CaptureEnviron([]string{"A=b=c"}, nil)["A"]; got != "b=c" {
+		t.Errorf("A = %q, want b=c", got)
+	}
+}
+
+func TestCompareSessions(t *testing.T) {
+	before := &SessionMetadata{
+		Command:    "make test",
+		WorkingDir: "/src",
+		Parameters: map[string]interface{}{"race": true},
+		Env:        map[string]string{"GOFLAGS": "-mod=vendor", "CI": "1"},
+	}
+	after := &SessionMetadata{
+		Command:    "make test",
+		WorkingDir: "/src",
+		Parameters: map[string]interface{}{"race": false},
+		Env:        map[string]string{"CI": "1", "CGO_ENABLED": "0"},
+	}
+
+	changes := CompareSessions(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("changes = %+v, want 3", changes)
+	}
+	fields := []string{changes[0].Field, changes[1].Field, changes[2].Field}
+	if fields[0] != "parameters.race" || fields[1] != "env.CGO_ENABLED" || fields[2] != "env.GOFLAGS" {
+		t.Errorf("fields = %v", fields)
+	}
+	if c := changes[1]; c.Before != nil || c.After == nil || *c.After != "0" {
+		t.Errorf("added variable = %+v", c)
+	}
+	if c := changes[2]; c.After != nil || c.Before == nil || *c.Before != "-mod=vendor" {
+		t.Errorf("removed variable = %+v", c)
+	}
+
+	if changes := CompareSessions(before, before); len(changes) != 0 {
+		t.Errorf("expected no changes comparing a session to itself, got %+v", changes)
+	}
+}
@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// EventsDir holds one append-only JSON Lines file of lifecycle events per daemon.
+const EventsDir = LogDir + "/events"
+
+// Daemon lifecycle event types.
+const (
+	EventStart  = "start"  // daemon started
+	EventStop   = "stop"   // daemon stopped on request
+	EventExit   = "exit"   // daemon exited on its own with status 0
+	EventCrash  = "crash"  // daemon exited on its own with a failure status
+	EventAdopt  = "adopt"  // orphaned daemon adopted by a new runbook process
+	EventReload = "reload" // daemon sent its reload signal
+)
+
+// DaemonEvent is a single entry in a daemon's event log.
+type DaemonEvent struct {
+	Time      time.Time `json:"time"`
+	Task      string    `json:"task"`
+	Event     string    `json:"event"`
+	PID       int       `json:"pid,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// eventsMu serializes appends from this process so lines never interleave.
+var eventsMu sync.Mutex
+
+// GetEventsPath returns the event log path for a daemon task.
+func GetEventsPath(taskName string) string {
+	return filepath.Join(EventsDir, taskName+".jsonl")
+}
+
+// AppendDaemonEvent appends an event to the task's event log. Time defaults
+// to now.
+func AppendDaemonEvent(event DaemonEvent) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	if err := os.MkdirAll(EventsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create events directory: %w", err)
+	}
+	f, err := os.OpenFile(GetEventsPath(event.Task), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// RecordDaemonEvent appends an event, printing a warning instead of failing.
+// Event logging must never interfere with managing the daemon itself.
+func RecordDaemonEvent(event DaemonEvent) {
+	if err := AppendDaemonEvent(event); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record %s event for %s: %v\n", event.Event, event.Task, err)
+	}
+}
+
+// ReadDaemonEvents returns the last n events for a task, oldest first
+// (n <= 0 returns all). A task with no event log has no events.
+func ReadDaemonEvents(taskName string, n int) ([]DaemonEvent, error) {
+	f, err := os.Open(GetEventsPath(taskName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	var events []DaemonEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event DaemonEvent
+		// Skip lines that are not valid events (e.g. a torn final write)
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	if n > 0 && len(events) > n {
+		events = events[len(events)-n:]
+	}
+	return events, nil
+}
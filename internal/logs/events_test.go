@@ -0,0 +1,42 @@
+package logs
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDaemonEventLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := ReadDaemonEvents("db", 10)
+	if err != nil || events != nil {
+		t.Fatalf("expected no events for missing log, got %v, %v", events, err)
+	}
+
+	for _, e := range []string{EventStart, EventCrash, EventStart, EventStop} {
+		if err := AppendDaemonEvent(DaemonEvent{Task: "db", Event: e}); err != nil {
+			t.Fatalf("AppendDaemonEvent failed: %v", err)
+		}
+	}
+
+	events, err = ReadDaemonEvents("db", 2)
+	if err != nil {
+		t.Fatalf("ReadDaemonEvents failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Event != EventStart || events[1].Event != EventStop {
+		t.Errorf("expected last two events [start stop], got %+v", events)
+	}
+	if events[0].Time.IsZero() {
+		t.Error("expected event time to default to now")
+	}
+
+	all, _ := ReadDaemonEvents("db", 0)
+	if len(all) != 4 {
+		t.Errorf("expected 4 events, got %d", len(all))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -117,6 +118,65 @@ func TestNewWriter(t *testing.T) {
 	}
 }
 
+func TestUpdateSessionMetadataConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(oldWd); err != nil {
+			t.Errorf("failed to restore working directory: %v", err)
+		}
+	}()
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	if err := Setup(); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	sessionID := GenerateSessionID()
+	if err := CreateSessionDirectory(sessionID); err != nil {
+		t.Fatalf("CreateSessionDirectory failed: %v", err)
+	}
+	if err := WriteSessionMetadata(sessionID, &SessionMetadata{SessionID: sessionID, TaskName: "test-task"}); err != nil {
+		t.Fatalf("WriteSessionMetadata failed: %v", err)
+	}
+
+	// Each update sets a different field, so a lost update drops a field
+	updates := []map[string]interface{}{
+		{"status": "stopped"},
+		{"exit_code": 1},
+		{"success": false},
+		{"agent": "builder"},
+		{"attempt": 2},
+		{"timed_out": true},
+	}
+	var wg sync.WaitGroup
+	for _, update := range updates {
+		wg.Add(1)
+		go func(update map[string]interface{}) {
+			defer wg.Done()
+			if err := UpdateSessionMetadata(sessionID, update); err != nil {
+				t.Errorf("UpdateSessionMetadata failed: %v", err)
+			}
+		}(update)
+	}
+	wg.Wait()
+
+	metadata, err := ReadSessionMetadata(sessionID)
+	if err != nil {
+		t.Fatalf("ReadSessionMetadata failed: %v", err)
+	}
+	if metadata.Status != "stopped" || metadata.ExitCode == nil || metadata.Success == nil ||
+		metadata.Agent != "builder" || metadata.Attempt != 2 || !metadata.TimedOut {
+		t.Errorf("expected every update to be kept, got %+v", metadata)
+	}
+}
+
 func TestWriterWrite(t *testing.T) {
 	// Create temporary directory for test
 	tmpDir := t.TempDir()
@@ -2,48 +2,63 @@ package logs
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
 )
 
+// Errors returned by ReadLog for options that can never match a log.
+var (
+	ErrInvalidStream = errors.New("invalid stream")
+	ErrInvalidFilter = errors.New("invalid regex pattern")
+)
+
 // ReadOptions contains options for reading log files
 type ReadOptions struct {
 	Lines     int    // Number of lines to tail (0 means all)
 	Filter    string // Regex pattern to filter lines (empty means no filter)
 	SessionID string // Optional session ID to read from (empty means latest)
 	Offset    int    // Skip last N lines before tailing (for backward paging)
+	Stream    string // StreamStdout or StreamStderr to read one stream (empty means both, interleaved)
 }
 
 // ReadLog reads the log file for a task with optional tailing and filtering.
 // If SessionID is specified in opts, reads from that specific session.
-// Otherwise, reads from the latest session.
+// Otherwise, reads from the latest session. Stream narrows it to stdout or
+// stderr; sessions from before streams were logged separately have neither.
 // Falls back to flat log file for backward compatibility.
 // Returns the matching lines, the total line count after filtering (before offset/tail), and any error.
 func ReadLog(taskName string, opts ReadOptions) ([]string, int, error) {
+	if !ValidStream(opts.Stream) {
+		return nil, 0, fmt.Errorf("%w '%s' (must be %s or %s)", ErrInvalidStream, opts.Stream, StreamStdout, StreamStderr)
+	}
+
 	var logPath string
 
 	if opts.SessionID != "" {
 		// Read from specific session
-		logPath = GetSessionLogPath(opts.SessionID)
+		logPath = GetSessionStreamPath(opts.SessionID, opts.Stream)
 	} else {
 		// Try to read from latest session
 		sessionID, err := GetLatestSessionID(taskName)
 		if err != nil {
-			// Fall back to flat log file for backward compatibility
+			// Fall back to flat log file for backward compatibility; it
+			// has no separate streams
+			if opts.Stream != "" {
+				return []string{}, 0, nil
+			}
 			logPath = GetLogPath(taskName)
 		} else {
-			logPath = GetSessionLogPath(sessionID)
+			logPath = GetSessionStreamPath(sessionID, opts.Stream)
 		}
 	}
 
-	// Check if log file exists
-	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+	// Open log file, including any rotated segments
+	file, err := OpenLog(logPath)
+	if os.IsNotExist(err) {
 		return []string{}, 0, nil // No log file yet
 	}
-
-	// Open log file
-	file, err := os.Open(logPath)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to open log file: %w", err)
 	}
@@ -100,7 +115,7 @@ func ReadSessionLog(sessionID string, opts ReadOptions) ([]string, int, error) {
 func filterLines(lines []string, pattern string) ([]string, error) {
 	re, err := regexp.Compile(pattern)
 	if err != nil {
-		return nil, fmt.Errorf("invalid regex pattern: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
 	}
 
 	var filtered []string
@@ -0,0 +1,129 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// RedactedText replaces redacted output.
+const RedactedText = "[REDACTED]"
+
+// maxPendingLine bounds how much of an unterminated line a RedactingWriter
+// holds back before redacting and writing it anyway.
+const maxPendingLine = 64 * 1024
+
+// Redactor masks output matching a set of regular expressions. Patterns with
+// capture groups mask only the groups, e.g. `password=(\S+)` keeps
+// "password=" and masks the value; other patterns mask the whole match.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor compiles redaction patterns. It returns nil if there are none.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern '%s': %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns p with every match masked. A nil Redactor returns p as is.
+func (r *Redactor) Redact(p []byte) []byte {
+	if r == nil {
+		return p
+	}
+	for _, re := range r.patterns {
+		matches := re.FindAllSubmatchIndex(p, -1)
+		if matches == nil {
+			continue
+		}
+		var out bytes.Buffer
+		last := 0
+		for _, m := range matches {
+			spans := [][2]int{{m[0], m[1]}}
+			if re.NumSubexp() > 0 {
+				spans = spans[:0]
+				for g := 1; g <= re.NumSubexp(); g++ {
+					if m[2*g] >= 0 {
+						spans = append(spans, [2]int{m[2*g], m[2*g+1]})
+					}
+				}
+			}
+			for _, span := range spans {
+				if span[0] < last {
+					continue // nested group already masked
+				}
+				out.Write(p[last:span[0]])
+				out.WriteString(RedactedText)
+				last = span[1]
+			}
+		}
+		out.Write(p[last:])
+		p = out.Bytes()
+	}
+	return p
+}
+
+// RedactString returns s with every match masked.
+func (r *Redactor) RedactString(s string) string {
+	return string(r.Redact([]byte(s)))
+}
+
+// RedactingWriter redacts output line by line before passing it on, so a
+// secret split across writes is still matched. Close writes any final
+// unterminated line.
+type RedactingWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	redactor *Redactor
+	pending  []byte
+}
+
+// NewRedactingWriter wraps w so everything written to it is redacted.
+func NewRedactingWriter(w io.Writer, redactor *Redactor) *RedactingWriter {
+	return &RedactingWriter{w: w, redactor: redactor}
+}
+
+// Write buffers p and writes every complete line, redacted.
+func (rw *RedactingWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.pending = append(rw.pending, p...)
+	end := bytes.LastIndexByte(rw.pending, '\n') + 1
+	if end == 0 && len(rw.pending) > maxPendingLine {
+		end = len(rw.pending)
+	}
+	if end > 0 {
+		if _, err := rw.w.Write(rw.redactor.Redact(rw.pending[:end])); err != nil {
+			return 0, err
+		}
+		rw.pending = append(rw.pending[:0], rw.pending[end:]...)
+	}
+	return len(p), nil
+}
+
+// Close writes the remaining unterminated line, redacted. It does not close
+// the underlying writer.
+func (rw *RedactingWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if len(rw.pending) == 0 {
+		return nil
+	}
+	_, err := rw.w.Write(rw.redactor.Redact(rw.pending))
+	rw.pending = nil
+	return err
+}
@@ -0,0 +1,63 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	r, err := NewRedactor([]string{`ghp_[A-Za-z0-9]+`, `password=(\S+)`})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"whole match", "token ghp_abc123 used", "token [REDACTED] used"},
+		{"capture group", "login password=hunter2 ok", "login password=[REDACTED] ok"},
+		{"several matches", "ghp_a ghp_b", "[REDACTED] [REDACTED]"},
+		{"no match", "nothing secret", "nothing secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.RedactString(tt.in); got != tt.want {
+				t.Errorf("RedactString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	if r, err := NewRedactor(nil); r != nil || err != nil {
+		t.Errorf("NewRedactor(nil) = %v, %v, want nil, nil", r, err)
+	}
+	if _, err := NewRedactor([]string{"("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestRedactingWriter(t *testing.T) {
+	r, err := NewRedactor([]string{`secret-[0-9]+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	w := NewRedactingWriter(&out, r)
+	// The secret is split across writes
+	for _, chunk := range []string{"key: secr", "et-42\nnext: secret", "-7"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := out.String(); got != "key: [REDACTED]\n" {
+		t.Errorf("before Close, got %q", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != "key: [REDACTED]\nnext: [REDACTED]" {
+		t.Errorf("after Close, got %q", got)
+	}
+}
@@ -0,0 +1,196 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// RotatingWriter writes a session log, moving it aside once it reaches a
+// size limit. Rotated segments sit next to the log as <log>.1 (the most
+// recent) through <log>.<maxFiles>; older ones are deleted.
+type RotatingWriter struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+// OpenRotatingWriter opens the log at path for appending, rotating it when
+// a write would take it past maxSize bytes.
+func OpenRotatingWriter(path string, maxSize int64, maxFiles int) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the current segment and records its size.
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// Write appends p to the log, rotating whenever the current segment is
+// full. Segments are split after a newline where one fits, so lines only
+// straddle segments when they are longer than the limit.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if room := w.maxSize - w.size; int64(len(chunk)) > room {
+			cut := -1
+			if room > 0 {
+				cut = bytes.LastIndexByte(chunk[:room], '\n')
+			}
+			switch {
+			case cut >= 0:
+				chunk = chunk[:cut+1]
+			case w.size > 0:
+				if err := w.rotate(); err != nil {
+					return written, err
+				}
+				continue
+			default:
+				chunk = chunk[:room]
+			}
+		}
+		n, err := w.file.Write(chunk)
+		w.size += int64(n)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// rotate shifts every segment up by one, dropping the oldest, and starts a
+// new, empty log.
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	if w.maxFiles > 0 {
+		_ = os.Remove(segmentPath(w.path, w.maxFiles))
+		for i := w.maxFiles - 1; i >= 1; i-- {
+			_ = os.Rename(segmentPath(w.path, i), segmentPath(w.path, i+1))
+		}
+		if err := os.Rename(w.path, segmentPath(w.path, 1)); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return w.open()
+}
+
+// Close closes the current segment.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// segmentPath returns the path of the n-th most recent rotated segment of
+// the log at path.
+func segmentPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// LogSegments returns the existing files of the log at path, oldest first:
+// its rotated segments followed by the current log.
+func LogSegments(path string) []string {
+	var rotated []string
+	for n := 1; ; n++ {
+		if _, err := os.Stat(segmentPath(path, n)); err != nil {
+			break
+		}
+		rotated = append(rotated, segmentPath(path, n))
+	}
+
+	segments := make([]string, 0, len(rotated)+1)
+	for i := len(rotated) - 1; i >= 0; i-- {
+		segments = append(segments, rotated[i])
+	}
+	if _, err := os.Stat(path); err == nil {
+		segments = append(segments, path)
+	}
+	return segments
+}
+
+// OpenLog opens the log at path for reading across its rotated segments,
+// oldest first, as if it had never been rotated. It returns an error
+// satisfying os.IsNotExist when the log has no files.
+func OpenLog(path string) (io.ReadCloser, error) {
+	segments := LogSegments(path)
+	if len(segments) == 0 {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+
+	log := &segmentedLog{}
+	for _, segment := range segments {
+		file, err := os.Open(segment)
+		if os.IsNotExist(err) {
+			continue // rotated away since it was listed
+		}
+		if err != nil {
+			log.Close()
+			return nil, err
+		}
+		log.files = append(log.files, file)
+	}
+	readers := make([]io.Reader, len(log.files))
+	for i, file := range log.files {
+		readers[i] = file
+	}
+	log.Reader = io.MultiReader(readers...)
+	return log, nil
+}
+
+// segmentedLog reads the segments of a log as one stream.
+type segmentedLog struct {
+	io.Reader
+	files []*os.File
+}
+
+// Close closes every segment.
+func (l *segmentedLog) Close() error {
+	var first error
+	for _, file := range l.files {
+		if err := file.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
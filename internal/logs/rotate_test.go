@@ -0,0 +1,77 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.log")
+	w, err := OpenRotatingWriter(path, 20, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingWriter failed: %v", err)
+	}
+	for i := 1; i <= 8; i++ {
+		if _, err := fmt.Fprintf(w, "line %d\n", i); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	segments := LogSegments(path)
+	want := []string{path + ".2", path + ".1", path}
+	if strings.Join(segments, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected segments %v, got %v", want, segments)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected the oldest segment to be dropped, got %v", err)
+	}
+
+	log, err := OpenLog(path)
+	if err != nil {
+		t.Fatalf("OpenLog failed: %v", err)
+	}
+	defer log.Close()
+	data, err := io.ReadAll(log)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if got := string(data); got != "line 3\nline 4\nline 5\nline 6\nline 7\nline 8\n" {
+		t.Errorf("expected the kept lines in order, got %q", got)
+	}
+}
+
+func TestOpenLogMissing(t *testing.T) {
+	if _, err := OpenLog(filepath.Join(t.TempDir(), "output.log")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+func TestReadLogAcrossSegments(t *testing.T) {
+	setupLogDir(t)
+	sessionID := "rotated"
+	if err := CreateSessionDirectory(sessionID); err != nil {
+		t.Fatal(err)
+	}
+	path := GetSessionLogPath(sessionID)
+	if err := os.WriteFile(path+".1", []byte("first\nsec"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("ond\nthird\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, total, err := ReadSessionLog(sessionID, ReadOptions{Lines: 2})
+	if err != nil {
+		t.Fatalf("ReadSessionLog failed: %v", err)
+	}
+	if total != 3 || strings.Join(lines, ",") != "second,third" {
+		t.Errorf("expected the last 2 of 3 lines, got %v (total %d)", lines, total)
+	}
+}
@@ -0,0 +1,114 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+// SearchOptions scopes a search across session logs.
+type SearchOptions struct {
+	TaskName string    // Only sessions of this task (empty = all tasks)
+	Since    time.Time // Only sessions started at or after this time (zero = no bound)
+	Until    time.Time // Only sessions started before this time (zero = no bound)
+	Limit    int       // Maximum number of matches (0 = no limit)
+}
+
+// SearchMatch is a session log line that matched a search. Log lines carry
+// no timestamps of their own, so StartTime is when the session started.
+type SearchMatch struct {
+	SessionID string    `json:"session_id"`
+	TaskName  string    `json:"task_name"`
+	StartTime time.Time `json:"start_time"`
+	Line      int       `json:"line"` // 1-based line number in the session log
+	Text      string    `json:"text"`
+}
+
+// SearchLogs returns the session log lines matching the regex pattern, from
+// the newest session to the oldest and in log order within a session, so the
+// first match is the most recent occurrence. It reports whether the matches
+// were cut at opts.Limit.
+func SearchLogs(pattern string, opts SearchOptions) ([]SearchMatch, bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid regex pattern: %w", err)
+	}
+
+	sessions, err := listSessions(func(name string) bool {
+		return opts.TaskName == "" || name == opts.TaskName
+	}, 0)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var matches []SearchMatch
+	for _, session := range sessions {
+		if !opts.Since.IsZero() && session.StartTime.Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && !session.StartTime.Before(opts.Until) {
+			continue
+		}
+		truncated, err := searchSessionLog(re, session, opts.Limit, &matches)
+		if err != nil {
+			return nil, false, err
+		}
+		if truncated {
+			return matches, true, nil
+		}
+	}
+	return matches, false, nil
+}
+
+// searchSessionLog appends the matching lines of one session's log to
+// matches, stopping once there are more than limit.
+func searchSessionLog(re *regexp.Regexp, session SessionInfo, limit int, matches *[]SearchMatch) (bool, error) {
+	file, err := OpenLog(session.LogPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !re.MatchString(text) {
+			continue
+		}
+		if limit > 0 && len(*matches) == limit {
+			return true, nil
+		}
+		*matches = append(*matches, SearchMatch{
+			SessionID: session.SessionID,
+			TaskName:  session.TaskName,
+			StartTime: session.StartTime,
+			Line:      line,
+			Text:      text,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read log file: %w", err)
+	}
+	return false, nil
+}
+
+// ParseTimeBound parses a search time bound: an RFC 3339 time, a date
+// (2006-01-02), or a duration such as "24h" meaning that long before now.
+func ParseTimeBound(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC 3339, YYYY-MM-DD, or a duration like 24h)", value)
+}
@@ -0,0 +1,72 @@
+package logs
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSearchLogs(t *testing.T) {
+	setupLogDir(t)
+
+	writeSession := func(id, task string, start time.Time, log string) {
+		t.Helper()
+		if err := CreateSessionDirectory(id); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteSessionMetadata(id, &SessionMetadata{SessionID: id, TaskName: task, StartTime: start}); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(GetSessionLogPath(id), []byte(log), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	writeSession("old", "test", now.Add(-48*time.Hour), "ok\nERROR: disk full\n")
+	writeSession("new", "test", now.Add(-time.Hour), "ERROR: timeout\nok\nERROR: disk full\n")
+	writeSession("build", "build", now, "ERROR: missing dep\n")
+
+	matches, truncated, err := SearchLogs("ERROR", SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchLogs: %v", err)
+	}
+	if truncated || len(matches) != 4 {
+		t.Fatalf("expected 4 matches, got %d (truncated=%v)", len(matches), truncated)
+	}
+	if matches[0].SessionID != "build" || matches[1].SessionID != "new" || matches[3].SessionID != "old" {
+		t.Errorf("expected newest session first, got %+v", matches)
+	}
+	if matches[2].Line != 3 || matches[2].Text != "ERROR: disk full" {
+		t.Errorf("unexpected match: %+v", matches[2])
+	}
+
+	matches, _, _ = SearchLogs("disk", SearchOptions{TaskName: "test", Since: now.Add(-24 * time.Hour)})
+	if len(matches) != 1 || matches[0].SessionID != "new" {
+		t.Errorf("expected only the recent test session, got %+v", matches)
+	}
+
+	matches, truncated, _ = SearchLogs("ERROR", SearchOptions{Limit: 2})
+	if !truncated || len(matches) != 2 {
+		t.Errorf("expected 2 matches and truncated, got %d (truncated=%v)", len(matches), truncated)
+	}
+
+	if _, _, err := SearchLogs("[", SearchOptions{}); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got, _ := ParseTimeBound("24h", now); !got.Equal(now.Add(-24 * time.Hour)) {
+		t.Errorf("duration: got %v", got)
+	}
+	if got, _ := ParseTimeBound("2024-04-01T00:00:00Z", now); !got.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339: got %v", got)
+	}
+	if got, err := ParseTimeBound("2024-04-01", now); err != nil || got.Day() != 1 {
+		t.Errorf("date: got %v (%v)", got, err)
+	}
+	if _, err := ParseTimeBound("yesterday", now); err == nil {
+		t.Error("expected an error for an unparseable bound")
+	}
+}
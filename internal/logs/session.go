@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,10 +22,33 @@ type SessionMetadata struct {
 	Duration   *time.Duration         `json:"duration,omitempty"`
 	ExitCode   *int                   `json:"exit_code,omitempty"`
 	Success    *bool                  `json:"success,omitempty"`
+	Status     string                 `json:"status,omitempty"` // success, warning, or failure
 	TimedOut   bool                   `json:"timed_out"`
 	Parameters map[string]interface{} `json:"parameters,omitempty"`
 	Command    string                 `json:"command,omitempty"`
+	Argv       []string               `json:"argv,omitempty"` // The process's full command line: shell, flags, and command
+	Env        map[string]string      `json:"env,omitempty"`  // The process's environment, redacted
 	WorkingDir string                 `json:"working_dir,omitempty"`
+	Resources  *ResourceUsage         `json:"resources,omitempty"`
+	Workdir    *WorkdirFingerprint    `json:"workdir_fingerprint,omitempty"`
+	Attempt    int                    `json:"attempt,omitempty"` // Workflow step attempt this session ran as, when the step has retries
+	Artifacts  []Artifact             `json:"artifacts,omitempty"`
+	Agent      string                 `json:"agent,omitempty"` // Remote agent that ran the command
+}
+
+// Artifact is a file a task declared in artifacts, copied into the session's
+// artifacts directory when the run finished.
+type Artifact struct {
+	Path string `json:"path"` // Relative to the artifacts directory, as it was to the working directory
+	Size int64  `json:"size"`
+}
+
+// WorkdirFingerprint identifies the code state of a git working tree when a
+// session started.
+type WorkdirFingerprint struct {
+	Commit    string `json:"commit"`               // HEAD commit
+	Dirty     bool   `json:"dirty"`                // Uncommitted or untracked changes exist
+	DirtyHash string `json:"dirty_hash,omitempty"` // SHA-256 of the uncommitted changes and untracked files
 }
 
 // SessionInfo holds basic information about a session
@@ -55,6 +79,11 @@ func GetSessionMetadataPath(sessionID string) string {
 	return filepath.Join(GetSessionDirectory(sessionID), "metadata.json")
 }
 
+// GetSessionArtifactsDir returns the directory a session's artifacts are copied to
+func GetSessionArtifactsDir(sessionID string) string {
+	return filepath.Join(GetSessionDirectory(sessionID), "artifacts")
+}
+
 // GetLatestSymlinkPath returns the path to the latest symlink for a task
 func GetLatestSymlinkPath(taskName string) string {
 	return filepath.Join(LogDir, "latest", taskName)
@@ -69,7 +98,8 @@ func CreateSessionDirectory(sessionID string) error {
 	return nil
 }
 
-// WriteSessionMetadata writes session metadata to a JSON file
+// WriteSessionMetadata writes session metadata to a JSON file. The file is
+// replaced by a rename, so readers never see a partial write.
 func WriteSessionMetadata(sessionID string, metadata *SessionMetadata) error {
 	path := GetSessionMetadataPath(sessionID)
 
@@ -78,7 +108,22 @@ func WriteSessionMetadata(sessionID string, metadata *SessionMetadata) error {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metadata-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 
@@ -102,8 +147,15 @@ func ReadSessionMetadata(sessionID string) (*SessionMetadata, error) {
 	return &metadata, nil
 }
 
+// metadataMu serializes metadata updates, so concurrent updates to the same
+// session don't overwrite each other's fields.
+var metadataMu sync.Mutex
+
 // UpdateSessionMetadata updates specific fields in session metadata
 func UpdateSessionMetadata(sessionID string, updates map[string]interface{}) error {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
 	// Read existing metadata
 	metadata, err := ReadSessionMetadata(sessionID)
 	if err != nil {
@@ -123,9 +175,27 @@ func UpdateSessionMetadata(sessionID string, updates map[string]interface{}) err
 	if success, ok := updates["success"].(bool); ok {
 		metadata.Success = &success
 	}
+	if status, ok := updates["status"].(string); ok {
+		metadata.Status = status
+	}
 	if timedOut, ok := updates["timed_out"].(bool); ok {
 		metadata.TimedOut = timedOut
 	}
+	if resources, ok := updates["resources"].(*ResourceUsage); ok && resources != nil {
+		metadata.Resources = resources
+	}
+	if attempt, ok := updates["attempt"].(int); ok {
+		metadata.Attempt = attempt
+	}
+	if params, ok := updates["parameters"].(map[string]interface{}); ok {
+		metadata.Parameters = params
+	}
+	if artifacts, ok := updates["artifacts"].([]Artifact); ok {
+		metadata.Artifacts = artifacts
+	}
+	if agent, ok := updates["agent"].(string); ok {
+		metadata.Agent = agent
+	}
 
 	// Write updated metadata
 	return WriteSessionMetadata(sessionID, metadata)
@@ -175,6 +245,16 @@ func CreateLatestLink(taskName, sessionID string) error {
 
 // ListSessions lists recent sessions for a task, sorted by start time (newest first)
 func ListSessions(taskName string, limit int) ([]SessionInfo, error) {
+	return listSessions(func(name string) bool { return name == taskName }, limit)
+}
+
+// ListRecentSessions lists recent sessions of every task, sorted by start time (newest first)
+func ListRecentSessions(limit int) ([]SessionInfo, error) {
+	return listSessions(func(string) bool { return true }, limit)
+}
+
+// listSessions lists the sessions whose task name matches, newest first
+func listSessions(match func(taskName string) bool, limit int) ([]SessionInfo, error) {
 	sessionsDir := filepath.Join(LogDir, "sessions")
 
 	// Read all session directories
@@ -203,7 +283,7 @@ func ListSessions(taskName string, limit int) ([]SessionInfo, error) {
 		}
 
 		// Filter by task name
-		if metadata.TaskName != taskName {
+		if !match(metadata.TaskName) {
 			continue
 		}
 
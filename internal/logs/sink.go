@@ -0,0 +1,146 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultSinkTimeout bounds how long a WebhookSink waits for its endpoint.
+const DefaultSinkTimeout = 5 * time.Second
+
+// SessionSink receives the metadata of every completed session.
+type SessionSink interface {
+	Send(metadata *SessionMetadata) error
+}
+
+var (
+	sinkMu sync.RWMutex
+	sink   SessionSink
+)
+
+// SetSessionSink exports every session completed from now on to s. A nil
+// sink turns exporting off.
+func SetSessionSink(s SessionSink) {
+	sinkMu.Lock()
+	sink = s
+	sinkMu.Unlock()
+}
+
+// PublishSession sends the metadata of a completed session to the session
+// sink, if one is set. Failures are reported as warnings, since the session
+// itself is already recorded on disk.
+func PublishSession(sessionID string) {
+	sinkMu.RLock()
+	s := sink
+	sinkMu.RUnlock()
+	if s == nil {
+		return
+	}
+
+	metadata, err := ReadSessionMetadata(sessionID)
+	if err == nil {
+		err = s.Send(metadata)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to export session %s: %v\n", sessionID, err)
+	}
+}
+
+// FileSink appends each session to a JSONL file, one object per line.
+type FileSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Send appends metadata to the file.
+func (f *FileSink) Send(metadata *SessionMetadata) error {
+	line, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open session sink: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write session sink: %w", err)
+	}
+	return file.Close()
+}
+
+// WebhookSink POSTs each session as JSON to a URL, with an optional bearer
+// token. Any non-2xx response is an error.
+type WebhookSink struct {
+	URL     string
+	Token   string
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Send posts metadata to the webhook.
+func (wh *WebhookSink) Send(metadata *SessionMetadata) error {
+	return wh.Post(metadata)
+}
+
+// Post sends v to the webhook as a JSON body.
+func (wh *WebhookSink) Post(v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	timeout := wh.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSinkTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if wh.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+wh.Token)
+	}
+
+	client := wh.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// MultiSink sends each session to every sink in turn, returning the first
+// error.
+type MultiSink []SessionSink
+
+// Send sends metadata to every sink.
+func (m MultiSink) Send(metadata *SessionMetadata) error {
+	var first error
+	for _, s := range m {
+		if err := s.Send(metadata); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
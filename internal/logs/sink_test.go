@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPublishSessionToFileAndWebhook(t *testing.T) {
+	setupLogDir(t)
+
+	var posted SessionMetadata
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &posted); err != nil {
+			t.Errorf("webhook got invalid JSON: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	SetSessionSink(MultiSink{
+		&FileSink{Path: "sessions.jsonl"},
+		&WebhookSink{URL: srv.URL, Token: "secret"},
+	})
+	defer SetSessionSink(nil)
+
+	for _, id := range []string{"s1", "s2"} {
+		writer, err := NewWriter(id, &SessionMetadata{SessionID: id, TaskName: "build", StartTime: time.Now()})
+		if err != nil {
+			t.Fatalf("NewWriter failed: %v", err)
+		}
+		writer.UpdateMetadata(map[string]interface{}{"exit_code": 0, "success": true, "status": "success"})
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	file, err := os.Open("sessions.jsonl")
+	if err != nil {
+		t.Fatalf("expected the sink file: %v", err)
+	}
+	defer file.Close()
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var metadata SessionMetadata
+		if err := json.Unmarshal(scanner.Bytes(), &metadata); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", scanner.Text(), err)
+		}
+		if metadata.EndTime == nil || metadata.Status != "success" {
+			t.Errorf("expected completed metadata, got %+v", metadata)
+		}
+		ids = append(ids, metadata.SessionID)
+	}
+	if len(ids) != 2 || ids[0] != "s1" || ids[1] != "s2" {
+		t.Errorf("expected one line per session, got %v", ids)
+	}
+
+	if posted.SessionID != "s2" || posted.TaskName != "build" {
+		t.Errorf("expected the webhook to get the last session, got %+v", posted)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("expected bearer token, got %q", auth)
+	}
+}
+
+func TestWebhookSinkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := (&WebhookSink{URL: srv.URL}).Send(&SessionMetadata{SessionID: "s1"})
+	if err == nil {
+		t.Fatal("expected an error for a non-2xx response")
+	}
+}
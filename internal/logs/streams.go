@@ -0,0 +1,100 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Output streams a session's log can be narrowed to.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// ValidStream reports whether stream names an output stream, or is empty
+// for the combined log.
+func ValidStream(stream string) bool {
+	return stream == "" || stream == StreamStdout || stream == StreamStderr
+}
+
+// GetSessionStreamPath returns the path to the log holding one stream of a
+// session's output, stdout.log or stderr.log. An empty stream returns the
+// combined log, GetSessionLogPath.
+func GetSessionStreamPath(sessionID, stream string) string {
+	if stream == "" {
+		return GetSessionLogPath(sessionID)
+	}
+	return filepath.Join(GetSessionDirectory(sessionID), stream+".log")
+}
+
+// StreamLogs writes a process's stdout and stderr to its session's
+// stdout.log and stderr.log, and both, interleaved in the order they
+// arrive, to the combined log.
+type StreamLogs struct {
+	mu       sync.Mutex
+	combined io.Writer
+	stdout   io.WriteCloser
+	stderr   io.WriteCloser
+}
+
+// OpenStreamLogs opens the stream logs of a session, with open, or as plain
+// files when open is nil. Writes to either stream also go to combined,
+// which the caller keeps open and closes.
+func OpenStreamLogs(sessionID string, combined io.Writer, open func(path string) (io.WriteCloser, error)) (*StreamLogs, error) {
+	if open == nil {
+		open = func(path string) (io.WriteCloser, error) {
+			return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		}
+	}
+	stdout, err := open(GetSessionStreamPath(sessionID, StreamStdout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout log: %w", err)
+	}
+	stderr, err := open(GetSessionStreamPath(sessionID, StreamStderr))
+	if err != nil {
+		stdout.Close()
+		return nil, fmt.Errorf("failed to open stderr log: %w", err)
+	}
+	return &StreamLogs{combined: combined, stdout: stdout, stderr: stderr}, nil
+}
+
+// Stdout returns the writer for the process's standard output.
+func (s *StreamLogs) Stdout() io.Writer {
+	return streamWriter{s, s.stdout}
+}
+
+// Stderr returns the writer for the process's standard error.
+func (s *StreamLogs) Stderr() io.Writer {
+	return streamWriter{s, s.stderr}
+}
+
+// Close closes the stream logs. The combined log is left open.
+func (s *StreamLogs) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	errOut, errErr := s.stdout.Close(), s.stderr.Close()
+	if errOut != nil {
+		return errOut
+	}
+	return errErr
+}
+
+// streamWriter writes one stream to its own log and the combined log.
+type streamWriter struct {
+	logs *StreamLogs
+	log  io.Writer
+}
+
+// Write writes p to both logs, holding the lock so a chunk of one stream
+// is never split by the other in the combined log.
+func (w streamWriter) Write(p []byte) (int, error) {
+	w.logs.mu.Lock()
+	defer w.logs.mu.Unlock()
+	if _, err := w.log.Write(p); err != nil {
+		return 0, err
+	}
+	return w.logs.combined.Write(p)
+}
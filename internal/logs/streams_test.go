@@ -0,0 +1,67 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestStreamLogs(t *testing.T) {
+	setupLogDir(t)
+	sessionID := "streams"
+	if err := CreateSessionDirectory(sessionID); err != nil {
+		t.Fatal(err)
+	}
+	combined, err := os.Create(GetSessionLogPath(sessionID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer combined.Close()
+
+	streams, err := OpenStreamLogs(sessionID, combined, nil)
+	if err != nil {
+		t.Fatalf("OpenStreamLogs() error = %v", err)
+	}
+	fmt.Fprintln(streams.Stdout(), "building")
+	fmt.Fprintln(streams.Stderr(), "warning: deprecated")
+	fmt.Fprintln(streams.Stdout(), "done")
+	if err := streams.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	tests := []struct {
+		stream string
+		want   []string
+	}{
+		{"", []string{"building", "warning: deprecated", "done"}},
+		{StreamStdout, []string{"building", "done"}},
+		{StreamStderr, []string{"warning: deprecated"}},
+	}
+	for _, tt := range tests {
+		lines, _, err := ReadSessionLog(sessionID, ReadOptions{Stream: tt.stream})
+		if err != nil {
+			t.Fatalf("ReadSessionLog(%q) error = %v", tt.stream, err)
+		}
+		if !slices.Equal(lines, tt.want) {
+			t.Errorf("ReadSessionLog(%q) = %q, want %q", tt.stream, lines, tt.want)
+		}
+	}
+
+	if _, _, err := ReadSessionLog(sessionID, ReadOptions{Stream: "stdin"}); err == nil {
+		t.Error("expected an error for an unknown stream")
+	}
+}
+
+func TestStreamLogsWithoutStreamFiles(t *testing.T) {
+	setupLogDir(t)
+	writeLogFile(t, "legacy", []string{"line"})
+
+	lines, _, err := ReadLog("legacy", ReadOptions{Stream: StreamStderr})
+	if err != nil {
+		t.Fatalf("ReadLog() error = %v", err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("expected no stderr lines for a log without streams, got %q", lines)
+	}
+}
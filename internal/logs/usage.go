@@ -0,0 +1,38 @@
+package logs
+
+import (
+	"os"
+	"time"
+)
+
+// ResourceUsage records what a finished process consumed. CPU times and max
+// RSS include the descendants the process waited for.
+type ResourceUsage struct {
+	MaxRSSBytes      int64   `json:"max_rss_bytes,omitempty"` // 0 where the platform does not report it
+	UserCPUSeconds   float64 `json:"user_cpu_seconds"`
+	SystemCPUSeconds float64 `json:"system_cpu_seconds"`
+	WallSeconds      float64 `json:"wall_seconds"`
+}
+
+// UsageFromProcessState returns the resource usage of a process that has
+// been waited on, with wall as its wall-clock time. It returns nil if the
+// process never ran.
+func UsageFromProcessState(state *os.ProcessState, wall time.Duration) *ResourceUsage {
+	if state == nil {
+		return nil
+	}
+	return &ResourceUsage{
+		MaxRSSBytes:      maxRSSBytes(state),
+		UserCPUSeconds:   state.UserTime().Seconds(),
+		SystemCPUSeconds: state.SystemTime().Seconds(),
+		WallSeconds:      wall.Seconds(),
+	}
+}
+
+// GroupUsage records what a running daemon's process group is using now.
+type GroupUsage struct {
+	RSSBytes   int64   `json:"rss_bytes"`   // Resident memory of every process in the group
+	CPUPercent float64 `json:"cpu_percent"` // Average since each process started, as a share of one core
+	CPUSeconds float64 `json:"cpu_seconds"` // User and system time the live processes have used
+	Children   int     `json:"children"`    // Processes in the group besides the daemon itself
+}
@@ -0,0 +1,17 @@
+//go:build darwin
+
+package logs
+
+import (
+	"os"
+	"syscall"
+)
+
+// maxRSSBytes returns the peak resident set size of a finished process.
+// macOS reports ru_maxrss in bytes.
+func maxRSSBytes(state *os.ProcessState) int64 {
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int64(ru.Maxrss)
+	}
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package logs
+
+import "os"
+
+// maxRSSBytes is not available without rusage.
+func maxRSSBytes(state *os.ProcessState) int64 {
+	return 0
+}
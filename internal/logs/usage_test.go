@@ -0,0 +1,33 @@
+package logs
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestUsageFromProcessState(t *testing.T) {
+	if UsageFromProcessState(nil, time.Second) != nil {
+		t.Error("expected nil usage for a process that never ran")
+	}
+
+	cmd := exec.Command("sh", "-c", "true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("sh not available: %v", err)
+	}
+
+	usage := UsageFromProcessState(cmd.ProcessState, 2*time.Second)
+	if usage == nil {
+		t.Fatal("expected usage")
+	}
+	if usage.WallSeconds != 2 {
+		t.Errorf("expected wall 2s, got %v", usage.WallSeconds)
+	}
+	if usage.UserCPUSeconds < 0 || usage.SystemCPUSeconds < 0 {
+		t.Errorf("expected non-negative CPU times, got %+v", usage)
+	}
+	if (runtime.GOOS == "linux" || runtime.GOOS == "darwin") && usage.MaxRSSBytes <= 0 {
+		t.Errorf("expected max RSS on %s, got %d", runtime.GOOS, usage.MaxRSSBytes)
+	}
+}
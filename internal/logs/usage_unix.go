@@ -0,0 +1,17 @@
+//go:build unix && !darwin
+
+package logs
+
+import (
+	"os"
+	"syscall"
+)
+
+// maxRSSBytes returns the peak resident set size of a finished process.
+// Linux and the BSDs report ru_maxrss in kilobytes.
+func maxRSSBytes(state *os.ProcessState) int64 {
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return int64(ru.Maxrss) * 1024
+	}
+	return 0
+}
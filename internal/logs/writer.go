@@ -82,13 +82,27 @@ func (w *Writer) Close() error {
 	if w.metadata.Success != nil {
 		updates["success"] = *w.metadata.Success
 	}
+	if w.metadata.Status != "" {
+		updates["status"] = w.metadata.Status
+	}
 	if w.metadata.TimedOut {
 		updates["timed_out"] = w.metadata.TimedOut
 	}
+	if w.metadata.Resources != nil {
+		updates["resources"] = w.metadata.Resources
+	}
+	if w.metadata.Artifacts != nil {
+		updates["artifacts"] = w.metadata.Artifacts
+	}
+	if w.metadata.Agent != "" {
+		updates["agent"] = w.metadata.Agent
+	}
 
 	if err := UpdateSessionMetadata(w.sessionID, updates); err != nil {
 		// Non-fatal error - log but don't fail the close
 		fmt.Fprintf(os.Stderr, "Warning: failed to update session metadata: %v\n", err)
+	} else {
+		PublishSession(w.sessionID)
 	}
 
 	return nil
@@ -108,9 +122,21 @@ func (w *Writer) UpdateMetadata(updates map[string]interface{}) {
 	if success, ok := updates["success"].(bool); ok {
 		w.metadata.Success = &success
 	}
+	if status, ok := updates["status"].(string); ok {
+		w.metadata.Status = status
+	}
 	if timedOut, ok := updates["timed_out"].(bool); ok {
 		w.metadata.TimedOut = timedOut
 	}
+	if resources, ok := updates["resources"].(*ResourceUsage); ok {
+		w.metadata.Resources = resources
+	}
+	if artifacts, ok := updates["artifacts"].([]Artifact); ok {
+		w.metadata.Artifacts = artifacts
+	}
+	if agent, ok := updates["agent"].(string); ok {
+		w.metadata.Agent = agent
+	}
 }
 
 // GetSessionID returns the session ID
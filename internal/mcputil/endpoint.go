@@ -2,12 +2,15 @@ package mcputil
 
 import "strings"
 
+// EndpointPath is the path mcp-go's StreamableHTTPServer serves MCP on.
+const EndpointPath = "/mcp"
+
 // Endpoint normalizes an MCP server base address to include the /mcp path,
 // since mcp-go's StreamableHTTPServer registers all handlers at /mcp by default.
 func Endpoint(addr string) string {
 	addr = strings.TrimRight(addr, "/")
-	if !strings.HasSuffix(addr, "/mcp") {
-		return addr + "/mcp"
+	if !strings.HasSuffix(addr, EndpointPath) {
+		return addr + EndpointPath
 	}
 	return addr
 }
@@ -0,0 +1,124 @@
+package mcputil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"runbookmcp.dev/internal/auth"
+)
+
+// CLIClientName is the client name the runbook CLI reports when it connects
+// to a running server.
+const CLIClientName = "runbook-cli"
+
+// AgentTokenEnv is the environment variable holding the token remote agents
+// present to the server, which sets it to the same value.
+const AgentTokenEnv = "RUNBOOK_AGENT_TOKEN"
+
+// AgentTokenHeader carries the agent token on agent API requests.
+const AgentTokenHeader = "X-Runbook-Agent-Token"
+
+// ServerTokenHeader carries the token from the server registry on MCP and
+// REST API requests to a server without server.auth.
+const ServerTokenHeader = "X-Runbook-Server-Token"
+
+// Environment variables holding the TLS files clients use to connect to a
+// server started with server.tls.
+const (
+	ClientCertEnv = "RUNBOOK_CLIENT_CERT" // Certificate presented to servers that require one (mtls)
+	ClientKeyEnv  = "RUNBOOK_CLIENT_KEY"  // Key of the client certificate
+	CACertEnv     = "RUNBOOK_CA_CERT"     // CA that signed the server's certificate (default: system roots)
+)
+
+// ClientConfig holds the credentials CLI clients, the stdio proxy, and
+// agents present to a running server.
+type ClientConfig struct {
+	TokenEnv    string // Env var holding the bearer token (default RUNBOOK_TOKEN)
+	ServerToken string // Token from the server registry, for servers without server.auth
+	CertFile    string // Client certificate, set together with KeyFile
+	KeyFile     string
+	CAFile      string // CA bundle that verifies the server's certificate
+}
+
+// NewClientConfig returns the client settings for a server whose
+// server.auth.token_env is tokenEnv, with TLS files from $RUNBOOK_CLIENT_CERT,
+// $RUNBOOK_CLIENT_KEY, and $RUNBOOK_CA_CERT.
+func NewClientConfig(tokenEnv string) ClientConfig {
+	return ClientConfig{
+		TokenEnv: tokenEnv,
+		CertFile: os.Getenv(ClientCertEnv),
+		KeyFile:  os.Getenv(ClientKeyEnv),
+		CAFile:   os.Getenv(CACertEnv),
+	}
+}
+
+// Headers returns the HTTP headers sent to the server: a bearer token when
+// the token env var is set, and the registry token when there is one.
+func (c ClientConfig) Headers() map[string]string {
+	tokenEnv := c.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = auth.DefaultTokenEnv
+	}
+	headers := map[string]string{}
+	if token := os.Getenv(tokenEnv); token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	if c.ServerToken != "" {
+		headers[ServerTokenHeader] = c.ServerToken
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// TLSConfig builds the TLS settings for connecting to the server. It
+// returns nil when no TLS files are set, leaving the defaults in place.
+func (c ClientConfig) TLSConfig() (*tls.Config, error) {
+	if c.CertFile == "" && c.KeyFile == "" && c.CAFile == "" {
+		return nil, nil
+	}
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, fmt.Errorf("%s and %s must be set together", ClientCertEnv, ClientKeyEnv)
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
+
+// HTTPClient returns an HTTP client that uses the TLS settings, with the
+// given timeout (0 means none).
+func (c ClientConfig) HTTPClient(timeout time.Duration) (*http.Client, error) {
+	tc, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: timeout}
+	if tc != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tc
+		client.Transport = transport
+	}
+	return client, nil
+}
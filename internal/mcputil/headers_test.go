@@ -0,0 +1,59 @@
+package mcputil
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientConfigHeaders(t *testing.T) {
+	t.Setenv("RUNBOOK_TOKEN", "default-token")
+	t.Setenv("PROJECT_TOKEN", "project-token")
+
+	if got := (ClientConfig{}).Headers()["Authorization"]; got != "Bearer default-token" {
+		t.Errorf("default token env: Authorization = %q", got)
+	}
+	if got := (ClientConfig{TokenEnv: "PROJECT_TOKEN"}).Headers()["Authorization"]; got != "Bearer project-token" {
+		t.Errorf("token_env: Authorization = %q", got)
+	}
+	if got := (ClientConfig{TokenEnv: "UNSET_TOKEN"}).Headers(); got != nil {
+		t.Errorf("unset token env: headers = %v, want none", got)
+	}
+}
+
+func TestClientConfigTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := (ClientConfig{}).HTTPClient(0)
+	if err != nil {
+		t.Fatalf("HTTPClient() error = %v", err)
+	}
+	if _, err := plain.Get(ts.URL); err == nil {
+		t.Error("expected the server's certificate to be rejected without its CA")
+	}
+
+	t.Setenv(CACertEnv, caFile)
+	client, err := NewClientConfig("").HTTPClient(0)
+	if err != nil {
+		t.Fatalf("HTTPClient() error = %v", err)
+	}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("expected the CA from %s to verify the server: %v", CACertEnv, err)
+	}
+	resp.Body.Close()
+
+	if _, err := (ClientConfig{CertFile: caFile}).TLSConfig(); err == nil {
+		t.Error("expected an error for a client certificate without a key")
+	}
+}
@@ -0,0 +1,153 @@
+// Package metrics collects task and daemon metrics and renders them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DurationBuckets are the upper bounds, in seconds, of the task duration histogram.
+var DurationBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600, 1800}
+
+// taskMetrics holds the counters and duration histogram for one task.
+type taskMetrics struct {
+	executions int
+	failures   int
+	buckets    []int // cumulative counts per DurationBuckets entry
+	sum        float64
+}
+
+// Registry accumulates metrics for the life of the server. It is safe for
+// concurrent use and implements task.Observer.
+type Registry struct {
+	mu           sync.Mutex
+	tasks        map[string]*taskMetrics
+	daemonStarts map[string]int
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		tasks:        make(map[string]*taskMetrics),
+		daemonStarts: make(map[string]int),
+	}
+}
+
+// ObserveTask records a completed one-shot execution.
+func (r *Registry) ObserveTask(taskName string, success bool, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.tasks[taskName]
+	if !ok {
+		m = &taskMetrics{buckets: make([]int, len(DurationBuckets))}
+		r.tasks[taskName] = m
+	}
+	m.executions++
+	if !success {
+		m.failures++
+	}
+	seconds := duration.Seconds()
+	m.sum += seconds
+	for i, bound := range DurationBuckets {
+		if seconds <= bound {
+			m.buckets[i]++
+		}
+	}
+}
+
+// ObserveDaemonStart records a daemon start. Every start after the first for
+// a task counts as a restart.
+func (r *Registry) ObserveDaemonStart(taskName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.daemonStarts[taskName]++
+}
+
+// Write renders all metrics in the Prometheus text format. activeDaemons
+// maps each daemon task to whether it is currently running.
+func (r *Registry) Write(w io.Writer, activeDaemons map[string]bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	taskNames := sortedKeys(r.tasks)
+
+	header(&b, "runbook_task_executions_total", "counter", "Completed one-shot task executions.")
+	for _, name := range taskNames {
+		fmt.Fprintf(&b, "runbook_task_executions_total{task=%s} %d\n", label(name), r.tasks[name].executions)
+	}
+
+	header(&b, "runbook_task_failures_total", "counter", "One-shot task executions that failed or timed out.")
+	for _, name := range taskNames {
+		fmt.Fprintf(&b, "runbook_task_failures_total{task=%s} %d\n", label(name), r.tasks[name].failures)
+	}
+
+	header(&b, "runbook_task_duration_seconds", "histogram", "Duration of one-shot task executions.")
+	for _, name := range taskNames {
+		m := r.tasks[name]
+		for i, bound := range DurationBuckets {
+			fmt.Fprintf(&b, "runbook_task_duration_seconds_bucket{task=%s,le=\"%g\"} %d\n", label(name), bound, m.buckets[i])
+		}
+		fmt.Fprintf(&b, "runbook_task_duration_seconds_bucket{task=%s,le=\"+Inf\"} %d\n", label(name), m.executions)
+		fmt.Fprintf(&b, "runbook_task_duration_seconds_sum{task=%s} %g\n", label(name), m.sum)
+		fmt.Fprintf(&b, "runbook_task_duration_seconds_count{task=%s} %d\n", label(name), m.executions)
+	}
+
+	daemonNames := sortedKeys(r.daemonStarts)
+
+	header(&b, "runbook_daemon_starts_total", "counter", "Daemon starts.")
+	for _, name := range daemonNames {
+		fmt.Fprintf(&b, "runbook_daemon_starts_total{task=%s} %d\n", label(name), r.daemonStarts[name])
+	}
+
+	header(&b, "runbook_daemon_restarts_total", "counter", "Daemon starts after the first for the same task.")
+	for _, name := range daemonNames {
+		fmt.Fprintf(&b, "runbook_daemon_restarts_total{task=%s} %d\n", label(name), r.daemonStarts[name]-1)
+	}
+
+	active := 0
+	header(&b, "runbook_daemon_up", "gauge", "Whether a daemon is running (1) or not (0).")
+	for _, name := range sortedKeys(activeDaemons) {
+		up := 0
+		if activeDaemons[name] {
+			up = 1
+			active++
+		}
+		fmt.Fprintf(&b, "runbook_daemon_up{task=%s} %d\n", label(name), up)
+	}
+
+	header(&b, "runbook_daemons_active", "gauge", "Number of running daemons.")
+	fmt.Fprintf(&b, "runbook_daemons_active %d\n", active)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// header writes the HELP and TYPE lines for a metric.
+func header(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// labelEscaper escapes a label value per the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label returns v as a quoted label value.
+func label(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	r.ObserveTask("build", true, 2*time.Second)
+	r.ObserveTask("build", false, 45*time.Second)
+	r.ObserveDaemonStart("dev")
+	r.ObserveDaemonStart("dev")
+
+	var b strings.Builder
+	if err := r.Write(&b, map[string]bool{"dev": true, "db": false}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE runbook_task_executions_total counter",
+		`runbook_task_executions_total{task="build"} 2`,
+		`runbook_task_failures_total{task="build"} 1`,
+		`runbook_task_duration_seconds_bucket{task="build",le="1"} 0`,
+		`runbook_task_duration_seconds_bucket{task="build",le="5"} 1`,
+		`runbook_task_duration_seconds_bucket{task="build",le="60"} 2`,
+		`runbook_task_duration_seconds_bucket{task="build",le="+Inf"} 2`,
+		`runbook_task_duration_seconds_sum{task="build"} 47`,
+		`runbook_task_duration_seconds_count{task="build"} 2`,
+		`runbook_daemon_starts_total{task="dev"} 2`,
+		`runbook_daemon_restarts_total{task="dev"} 1`,
+		`runbook_daemon_up{task="db"} 0`,
+		`runbook_daemon_up{task="dev"} 1`,
+		"runbook_daemons_active 1",
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("expected output to contain %q\n%s", want, out)
+		}
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	if got := label("a\"b\\c\nd"); got != `"a\"b\\c\nd"` {
+		t.Errorf("unexpected escaped label %s", got)
+	}
+}
@@ -0,0 +1,126 @@
+package process
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+func setupEventsTest(t *testing.T) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(oldWd); err != nil {
+			t.Errorf("failed to restore working directory: %v", err)
+		}
+	})
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+}
+
+// waitForEvents polls the event log until it holds n events.
+func waitForEvents(t *testing.T, taskName string, n int) []logs.DaemonEvent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		events, err := logs.ReadDaemonEvents(taskName, 0)
+		if err != nil {
+			t.Fatalf("failed to read events: %v", err)
+		}
+		if len(events) >= n || time.Now().After(deadline) {
+			return events
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestDaemonEventsStartStop(t *testing.T) {
+	setupEventsTest(t)
+
+	manager := NewManager()
+	if err := manager.Start("db", "sess-db", "sleep 10", nil, "", logs.GetLogPath("db"), ""); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	if err := manager.Stop("db"); err != nil {
+		t.Fatalf("failed to stop daemon: %v", err)
+	}
+
+	events := waitForEvents(t, "db", 2)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].Event != logs.EventStart || events[0].SessionID != "sess-db" || events[0].PID == 0 {
+		t.Errorf("unexpected start event: %+v", events[0])
+	}
+	if events[1].Event != logs.EventStop || events[1].Reason == "" {
+		t.Errorf("expected stop event with reason, got %+v", events[1])
+	}
+}
+
+func TestDaemonEventsCrash(t *testing.T) {
+	setupEventsTest(t)
+
+	manager := NewManager()
+	if err := manager.Start("api", "sess-api", "exit 3", nil, "", logs.GetLogPath("api"), ""); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+
+	events := waitForEvents(t, "api", 2)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	crash := events[1]
+	if crash.Event != logs.EventCrash {
+		t.Fatalf("expected crash event, got %+v", crash)
+	}
+	if crash.ExitCode == nil || *crash.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %v", crash.ExitCode)
+	}
+	if crash.Reason != "exited with code 3" {
+		t.Errorf("unexpected reason %q", crash.Reason)
+	}
+}
+
+func TestCrashHandler(t *testing.T) {
+	setupEventsTest(t)
+
+	crashes := make(chan logs.DaemonEvent, 3)
+	manager := NewManager()
+	manager.SetCrashHandler(func(event logs.DaemonEvent) { crashes <- event })
+
+	for name, cmd := range map[string]string{"ok": "exit 0", "api": "exit 3", "db": "sleep 10"} {
+		if err := manager.Start(name, "sess-"+name, cmd, nil, "", logs.GetLogPath(name), ""); err != nil {
+			t.Fatalf("failed to start %s: %v", name, err)
+		}
+	}
+	if err := manager.Stop("db"); err != nil {
+		t.Fatalf("failed to stop daemon: %v", err)
+	}
+
+	select {
+	case crash := <-crashes:
+		if crash.Task != "api" || crash.Event != logs.EventCrash || crash.ExitCode == nil || *crash.ExitCode != 3 {
+			t.Errorf("unexpected crash event: %+v", crash)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the crash")
+	}
+
+	// Clean exits and stops are not crashes
+	waitForEvents(t, "ok", 2)
+	select {
+	case crash := <-crashes:
+		t.Errorf("expected a single crash, got another: %+v", crash)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
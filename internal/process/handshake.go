@@ -0,0 +1,88 @@
+package process
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HandshakePath is where the HTTP server answers handshakes. It is served
+// without authentication: the answer proves the server holds the token in
+// its registry without revealing it.
+const HandshakePath = "/handshake"
+
+// HandshakeResponse is the server's answer to a handshake.
+type HandshakeResponse struct {
+	Proof string `json:"proof"` // HandshakeProof of the request's nonce
+}
+
+// NewServerToken returns a random token for the server registry.
+func NewServerToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate server token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HandshakeProof returns the answer to a handshake nonce: an HMAC-SHA256 of
+// the nonce keyed with the registry token.
+func HandshakeProof(token, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyServer checks that the server listening at data.Addr is the one
+// that wrote the registry, by asking it to prove it holds data.Token for a
+// fresh nonce. This keeps the CLI and stdio proxy from talking to another
+// user's process that took over the port. Registries written by servers
+// without a token are trusted as before. client carries the caller's TLS
+// settings; nil means http.DefaultClient.
+func VerifyServer(data *ServerFileData, client *http.Client) error {
+	if data.Token == "" {
+		return nil
+	}
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return fmt.Errorf("failed to generate handshake nonce: %w", err)
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	endpoint := strings.TrimRight(data.Addr, "/") + HandshakePath + "?nonce=" + url.QueryEscape(nonce)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("invalid server address %q: %w", data.Addr, err)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("handshake with %s failed: %w", data.Addr, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("handshake with %s failed: %s", data.Addr, resp.Status)
+	}
+
+	var answer HandshakeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return fmt.Errorf("handshake with %s failed: invalid response: %w", data.Addr, err)
+	}
+	if !hmac.Equal([]byte(answer.Proof), []byte(HandshakeProof(data.Token, nonce))) {
+		return fmt.Errorf("the process at %s could not prove it is this project's server", data.Addr)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package process
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// handshakeServer answers handshakes with proofs computed from token.
+func handshakeServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != HandshakePath {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(HandshakeResponse{Proof: HandshakeProof(token, r.URL.Query().Get("nonce"))})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestVerifyServer(t *testing.T) {
+	token, err := NewServerToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := handshakeServer(t, token)
+
+	if err := VerifyServer(&ServerFileData{Addr: ts.URL, PID: 1, Token: token}, nil); err != nil {
+		t.Errorf("expected the server holding the token to verify, got %v", err)
+	}
+
+	impostor := handshakeServer(t, "guessed")
+	err = VerifyServer(&ServerFileData{Addr: impostor.URL, PID: 1, Token: token}, nil)
+	if err == nil || !strings.Contains(err.Error(), "could not prove") {
+		t.Errorf("expected a server without the token to be rejected, got %v", err)
+	}
+
+	// Registries from servers without a token are trusted
+	if err := VerifyServer(&ServerFileData{Addr: impostor.URL, PID: 1}, nil); err != nil {
+		t.Errorf("expected a tokenless registry to be trusted, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package process
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestInteractiveDaemonInput(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+
+	manager := NewManager()
+	defer func() { _ = manager.StopAll() }()
+	logPath := logs.GetSessionLogPath("repl-session")
+	if err := logs.CreateSessionDirectory("repl-session"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.Start("plain", "plain-session", "sleep 10", nil, "", logs.GetLogPath("plain"), ""); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	if err := manager.SendInput("plain", "x\n"); err == nil || !strings.Contains(err.Error(), "does not accept input") {
+		t.Errorf("expected non-interactive daemon to reject input, got %v", err)
+	}
+
+	script := `while read line; do echo "got $line"; done`
+	if err := manager.StartInteractive("repl", "repl-session", script, nil, "", logPath, "/bin/sh"); err != nil {
+		t.Fatalf("failed to start interactive daemon: %v", err)
+	}
+	if err := manager.SendInput("repl", "hello\n"); err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if strings.Contains(string(data), "got hello\n") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected daemon output in log, got %q", data)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if err := manager.SendInput("missing", "x\n"); err == nil {
+		t.Error("expected error for a daemon that is not running")
+	}
+}
+
+func TestDaemonRedaction(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+
+	manager := NewManager()
+	defer func() { _ = manager.StopAll() }()
+	redactor, err := logs.NewRedactor([]string{`token=(\w+)`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetRedactor("server", redactor)
+
+	logPath := logs.GetSessionLogPath("redact-session")
+	if err := manager.Start("server", "redact-session", "echo token=abc123 >&2; sleep 0.1; echo ready", nil, "", logPath, "/bin/sh"); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if strings.Contains(string(data), "ready\n") {
+			if strings.Contains(string(data), "abc123") || !strings.Contains(string(data), "token=[REDACTED]") {
+				t.Errorf("expected token to be redacted, got %q", data)
+			}
+			stderr, _ := os.ReadFile(logs.GetSessionStreamPath("redact-session", logs.StreamStderr))
+			if string(stderr) != "token=[REDACTED]\n" {
+				t.Errorf("expected the redacted stderr line in stderr.log, got %q", stderr)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected daemon output in log, got %q", data)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestDaemonLogRotation(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+
+	manager := NewManager()
+	defer func() { _ = manager.StopAll() }()
+	manager.SetLogRotation("server", 64, 2)
+
+	logPath := logs.GetSessionLogPath("rotate-session")
+	script := `for i in 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20; do echo "line $i"; done; echo ready; sleep 10`
+	if err := manager.Start("server", "rotate-session", script, nil, "", logPath, "/bin/sh"); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lines, _, _ := logs.ReadSessionLog("rotate-session", logs.ReadOptions{})
+		if len(lines) > 0 && lines[len(lines)-1] == "ready" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected daemon output in log, got %q", lines)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if segments := logs.LogSegments(logPath); len(segments) != 3 {
+		t.Errorf("expected the log and 2 rotated segments, got %v", segments)
+	}
+	if info, err := os.Stat(logPath); err != nil || info.Size() > 64 {
+		t.Errorf("expected the current log to stay within 64 bytes, got %v %v", info, err)
+	}
+}
@@ -1,17 +1,26 @@
 package process
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/logs"
 )
 
+// DefaultStopGrace is how long a stopped daemon has to exit after SIGTERM
+// before it is killed with SIGKILL, unless SetStopGrace changes it.
+const DefaultStopGrace = 5 * time.Second
+
 // ProcessInfo holds information about a running process
 type ProcessInfo struct {
 	PID       int
@@ -20,13 +29,26 @@ type ProcessInfo struct {
 	StartTime time.Time
 	LogFile   string
 	SessionID string
+	Ports     []int         // TCP ports the daemon declared it listens on
 	done      chan struct{} // Closed when process exits
+	stopping  atomic.Bool   // Set when a stop was requested, so the exit is not a crash
+
+	inputMu   sync.Mutex
+	input     io.Writer // stdin of an interactive daemon; nil otherwise
+	inputEcho io.Writer // if set, input is also written here (pipe-backed daemons have no terminal echo)
 }
 
 // Manager manages daemon processes
 type Manager struct {
 	ownerID   string // unique ID for this Manager instance
 	processes map[string]*ProcessInfo
+	redactors map[string]*logs.Redactor // applied to the output of the next start of a task
+	environs  map[string][]string       // inherited in place of the host environment by the next start of a task
+	rotations map[string]logRotation    // log size limits applied from the next start of a task
+	ports     map[string][]int          // TCP ports checked and recorded by the next start of a task
+	stopGrace time.Duration             // how long Stop waits after SIGTERM before SIGKILL
+	stops     map[string]stopSetting    // per-task stop signal and grace period, replacing SIGTERM and stopGrace
+	onCrash   func(logs.DaemonEvent)    // called after a daemon started here crashes
 	mu        sync.RWMutex
 }
 
@@ -36,6 +58,12 @@ func NewManager() *Manager {
 	pm := &Manager{
 		ownerID:   uuid.New().String(),
 		processes: make(map[string]*ProcessInfo),
+		redactors: make(map[string]*logs.Redactor),
+		environs:  make(map[string][]string),
+		rotations: make(map[string]logRotation),
+		ports:     make(map[string][]int),
+		stops:     make(map[string]stopSetting),
+		stopGrace: DefaultStopGrace,
 	}
 	pm.restoreFromPIDFiles()
 	return pm
@@ -58,6 +86,13 @@ func (pm *Manager) restoreFromPIDFiles() {
 	for _, data := range files {
 		if !isProcessAlive(data.PID) {
 			deletePIDFile(data.TaskName)
+			logs.RecordDaemonEvent(logs.DaemonEvent{
+				Task:      data.TaskName,
+				Event:     logs.EventExit,
+				PID:       data.PID,
+				SessionID: data.SessionID,
+				Reason:    "found not running on startup; exited while no runbook process was watching (exit status unknown)",
+			})
 			continue
 		}
 
@@ -67,27 +102,47 @@ func (pm *Manager) restoreFromPIDFiles() {
 		effectiveOwnerID := data.OwnerID
 		if !isProcessAlive(data.OwnerPID) {
 			effectiveOwnerID = pm.ownerID
+			logs.RecordDaemonEvent(logs.DaemonEvent{
+				Task:      data.TaskName,
+				Event:     logs.EventAdopt,
+				PID:       data.PID,
+				SessionID: data.SessionID,
+				Reason:    fmt.Sprintf("owner process %d exited; adopted by runbook process %d", data.OwnerPID, os.Getpid()),
+			})
 		}
 
 		doneChan := make(chan struct{})
-		pm.processes[data.TaskName] = &ProcessInfo{
+		info := &ProcessInfo{
 			PID:       data.PID,
 			OwnerID:   effectiveOwnerID,
 			Cmd:       nil,
 			StartTime: data.StartTime,
 			LogFile:   data.LogFile,
 			SessionID: data.SessionID,
+			Ports:     data.Ports,
 			done:      doneChan,
 		}
+		pm.processes[data.TaskName] = info
 
 		// Poll until the process exits so the map entry and PID file are
 		// cleaned up automatically even if no one explicitly stops it.
 		taskName := data.TaskName
 		pid := data.PID
+		sessionID := data.SessionID
 		go func() {
 			for isProcessAlive(pid) {
 				time.Sleep(500 * time.Millisecond)
 			}
+			// Only the owner records the exit, so observers don't duplicate it
+			if info.OwnerID == pm.ownerID && !info.stopping.Load() {
+				logs.RecordDaemonEvent(logs.DaemonEvent{
+					Task:      taskName,
+					Event:     logs.EventExit,
+					PID:       pid,
+					SessionID: sessionID,
+					Reason:    "exited on its own (exit status unknown for adopted daemons)",
+				})
+			}
 			deletePIDFile(taskName)
 			close(doneChan)
 			pm.mu.Lock()
@@ -99,6 +154,106 @@ func (pm *Manager) restoreFromPIDFiles() {
 
 // Start starts a new daemon process
 func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[string]string, cwd string, logPath string, shell string) error {
+	return pm.start(taskName, sessionID, cmd, env, cwd, logPath, shell, false)
+}
+
+// StartInteractive starts a daemon whose input can be written with SendInput.
+// The daemon runs on a pseudo-terminal where supported, so REPLs and watch
+// modes behave as in a terminal; elsewhere its stdin is a pipe. The terminal
+// closes when this process exits.
+func (pm *Manager) StartInteractive(taskName string, sessionID string, cmd string, env map[string]string, cwd string, logPath string, shell string) error {
+	return pm.start(taskName, sessionID, cmd, env, cwd, logPath, shell, true)
+}
+
+// SetRedactor masks redactor's patterns in the output of the task's daemon
+// from its next start on; nil turns redaction off. Redacted output is copied
+// to the log by this process instead of written by the daemon directly, so it
+// stops being logged when this process exits.
+func (pm *Manager) SetRedactor(taskName string, redactor *logs.Redactor) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.redactors[taskName] = redactor
+}
+
+// SetInheritedEnv makes the task's daemon inherit environ ("KEY=value"
+// entries) instead of this process's environment from its next start on;
+// nil inherits this process's environment again.
+func (pm *Manager) SetInheritedEnv(taskName string, environ []string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if environ == nil {
+		delete(pm.environs, taskName)
+		return
+	}
+	pm.environs[taskName] = environ
+}
+
+// logRotation is the size limit of a daemon's session log.
+type logRotation struct {
+	maxSize  int64
+	maxFiles int
+}
+
+// SetLogRotation rotates the session log of the task's daemon once it
+// reaches maxSize bytes, keeping maxFiles rotated segments, from its next
+// start on; a maxSize of 0 turns rotation off. Like redacted output, rotated
+// output is copied to the log by this process, so it stops being logged when
+// this process exits.
+func (pm *Manager) SetLogRotation(taskName string, maxSize int64, maxFiles int) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if maxSize <= 0 {
+		delete(pm.rotations, taskName)
+		return
+	}
+	pm.rotations[taskName] = logRotation{maxSize: maxSize, maxFiles: maxFiles}
+}
+
+// SetPorts declares the TCP ports the task's daemon listens on, from its
+// next start on. The start fails if any of them is already in use, and the
+// ports are recorded with the running daemon. nil clears them.
+func (pm *Manager) SetPorts(taskName string, ports []int) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if len(ports) == 0 {
+		delete(pm.ports, taskName)
+		return
+	}
+	pm.ports[taskName] = ports
+}
+
+// Ports returns the TCP ports a running daemon declared when it started.
+func (pm *Manager) Ports(taskName string) []int {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	if proc, exists := pm.processes[taskName]; exists {
+		return proc.Ports
+	}
+	return nil
+}
+
+// stopSetting is how a task's daemon is stopped.
+type stopSetting struct {
+	signal string        // Signal name without the SIG prefix, e.g. "INT"
+	grace  time.Duration // How long to wait after signal before SIGKILL
+}
+
+// SetStopSignal makes Stop send the task's daemon the named signal, such as
+// "INT", and wait grace for it to exit before killing it. An empty signal
+// means TERM and a grace of zero or less the manager's stop grace, so
+// SetStopSignal(taskName, "", 0) restores the defaults.
+func (pm *Manager) SetStopSignal(taskName string, signal string, grace time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if signal == "" && grace <= 0 {
+		delete(pm.stops, taskName)
+		return
+	}
+	pm.stops[taskName] = stopSetting{signal: signal, grace: grace}
+}
+
+// start implements Start and StartInteractive.
+func (pm *Manager) start(taskName string, sessionID string, cmd string, env map[string]string, cwd string, logPath string, shell string, interactive bool) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
@@ -112,12 +267,19 @@ func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[
 		delete(pm.processes, taskName)
 	}
 
+	// Refuse to start a daemon whose ports another process already holds
+	ports := pm.ports[taskName]
+	if err := pm.checkPorts(ports); err != nil {
+		return fmt.Errorf("cannot start daemon '%s': %w", taskName, err)
+	}
+
 	if shell == "" {
 		shell = "/bin/bash"
 	}
 
 	// Create command
-	command := exec.Command(shell, "-c", cmd)
+	argv := config.ShellArgs(shell, cmd)
+	command := exec.Command(argv[0], argv[1:]...)
 
 	// Set working directory
 	if cwd != "" {
@@ -126,6 +288,9 @@ func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[
 
 	// Set environment variables
 	command.Env = os.Environ()
+	if environ, ok := pm.environs[taskName]; ok {
+		command.Env = append([]string{}, environ...)
+	}
 	for key, value := range env {
 		command.Env = append(command.Env, fmt.Sprintf("%s=%s", key, value))
 	}
@@ -148,7 +313,9 @@ func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[
 		TaskName:   taskName,
 		TaskType:   "daemon",
 		StartTime:  startTime,
-		Command:    cmd,
+		Command:    pm.redactors[taskName].RedactString(cmd),
+		Argv:       logs.RedactArgs(command.Args, pm.redactors[taskName]),
+		Env:        logs.CaptureEnviron(command.Env, pm.redactors[taskName]),
 		WorkingDir: workingDir,
 	}
 
@@ -163,15 +330,48 @@ func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[
 		fmt.Fprintf(os.Stderr, "Warning: failed to create latest symlink: %v\n", err)
 	}
 
-	// Open log file
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	// Open log file, rotating it by size if configured
+	openLog := func(path string) (io.WriteCloser, error) {
+		return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	}
+	rotation, rotated := pm.rotations[taskName]
+	if rotated {
+		openLog = func(path string) (io.WriteCloser, error) {
+			return logs.OpenRotatingWriter(path, rotation.maxSize, rotation.maxFiles)
+		}
+	}
+	logFile, err := openLog(logPath)
 	if err != nil {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
-
-	// Set stdout and stderr to log file
-	command.Stdout = logFile
-	command.Stderr = logFile
+	closeLogs := func() { _ = logFile.Close() }
+
+	// Set stdout and stderr to the log file. When the output is redacted or
+	// rotated it is copied by this process, which also keeps each stream in
+	// its own log; otherwise the daemon writes the log itself.
+	var logOut, logErr io.Writer = logFile, logFile
+	var redactedOut, redactedErr *logs.RedactingWriter
+	redactor := pm.redactors[taskName]
+	if redactor != nil || rotated {
+		streams, err := logs.OpenStreamLogs(sessionID, logFile, openLog)
+		if err != nil {
+			logFile.Close()
+			return err
+		}
+		closeLogs = func() {
+			_ = streams.Close()
+			_ = logFile.Close()
+		}
+		logOut, logErr = streams.Stdout(), streams.Stderr()
+		command.WaitDelay = time.Second
+	}
+	if redactor != nil {
+		redactedOut = logs.NewRedactingWriter(logOut, redactor)
+		redactedErr = logs.NewRedactingWriter(logErr, redactor)
+		logOut, logErr = redactedOut, redactedErr
+	}
+	command.Stdout = logOut
+	command.Stderr = logErr
 
 	// Set process group attributes for proper daemon isolation
 	// This creates a new process group with the daemon as leader (PGID == PID)
@@ -179,12 +379,50 @@ func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[
 	// This allows us to terminate the entire process tree with kill(-pgid, signal)
 	command.SysProcAttr = getProcAttrs()
 
+	// Interactive daemons get a terminal (or a stdin pipe) for SendInput
+	var input io.Writer
+	var inputEcho io.Writer
+	var terminal, terminalSlave *os.File
+	if interactive {
+		if master, slave, err := openPTY(); err == nil {
+			terminal, terminalSlave = master, slave
+			command.Stdin, command.Stdout, command.Stderr = slave, slave, slave
+			command.SysProcAttr = ptyProcAttrs()
+			input = master
+		} else {
+			stdin, err := command.StdinPipe()
+			if err != nil {
+				closeLogs()
+				return fmt.Errorf("failed to create stdin pipe: %w", err)
+			}
+			input, inputEcho = stdin, logOut
+		}
+	}
+
 	// Start the process
-	if err := command.Start(); err != nil {
-		logFile.Close()
+	err = command.Start()
+	if terminalSlave != nil {
+		terminalSlave.Close() // the daemon holds its own copy
+	}
+	if err != nil {
+		if terminal != nil {
+			terminal.Close()
+		}
+		closeLogs()
 		return fmt.Errorf("failed to start process: %w", err)
 	}
 
+	// Copy terminal output into the log until the daemon closes it
+	terminalDone := make(chan struct{})
+	if terminal != nil {
+		go func() {
+			defer close(terminalDone)
+			_, _ = io.Copy(crlfWriter{logOut}, terminal)
+		}()
+	} else {
+		close(terminalDone)
+	}
+
 	// Persist PID so subsequent CLI invocations can discover this daemon
 	if err := writePIDFile(pidFileData{
 		PID:       command.Process.Pid,
@@ -194,27 +432,56 @@ func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[
 		TaskName:  taskName,
 		StartTime: startTime,
 		LogFile:   logPath,
+		Ports:     ports,
 	}); err != nil {
 		// Non-fatal: in-process tracking still works; warn and continue
 		fmt.Fprintf(os.Stderr, "Warning: failed to write PID file: %v\n", err)
 	}
+	// Record the project so runbook ps finds its daemons
+	if err := registerProject(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record project for runbook ps: %v\n", err)
+	}
 
 	// Store process info
 	doneChan := make(chan struct{})
-	pm.processes[taskName] = &ProcessInfo{
+	info := &ProcessInfo{
 		PID:       command.Process.Pid,
 		OwnerID:   pm.ownerID,
 		Cmd:       command,
 		StartTime: startTime,
 		LogFile:   logPath,
 		SessionID: sessionID,
+		Ports:     ports,
 		done:      doneChan,
+		input:     input,
+		inputEcho: inputEcho,
 	}
+	pm.processes[taskName] = info
+
+	logs.RecordDaemonEvent(logs.DaemonEvent{
+		Task:      taskName,
+		Event:     logs.EventStart,
+		PID:       command.Process.Pid,
+		SessionID: sessionID,
+		Reason:    fmt.Sprintf("started by runbook process %d", os.Getpid()),
+	})
 
 	// Monitor process in background
 	go func() {
 		exitErr := command.Wait() // Capture exit status for metadata
-		_ = logFile.Close()       // Ignore close errors during cleanup
+		if terminal != nil {
+			// Drain remaining output; children may hold the terminal open
+			select {
+			case <-terminalDone:
+			case <-time.After(time.Second):
+			}
+			_ = terminal.Close()
+		}
+		if redactedOut != nil {
+			_ = redactedOut.Close()
+			_ = redactedErr.Close()
+		}
+		closeLogs() // Ignore close errors during cleanup
 
 		// Update session metadata with end time and exit code
 		endTime := time.Now()
@@ -222,89 +489,267 @@ func (pm *Manager) Start(taskName string, sessionID string, cmd string, env map[
 		exitCode := 0
 		success := true
 
+		reason := "exited on its own"
 		if exitErr != nil {
 			if exitStatus, ok := command.ProcessState.Sys().(syscall.WaitStatus); ok {
 				exitCode = exitStatus.ExitStatus()
+				if exitStatus.Signaled() {
+					reason = fmt.Sprintf("killed by signal %s", exitStatus.Signal())
+				} else {
+					reason = fmt.Sprintf("exited with code %d", exitCode)
+				}
 			}
 			success = false
 		}
 
+		// Stops record their own event with the reason for the stop
+		var crash *logs.DaemonEvent
+		if !info.stopping.Load() {
+			event := logs.EventExit
+			if !success {
+				event = logs.EventCrash
+			}
+			code := exitCode
+			exit := logs.DaemonEvent{
+				Task:      taskName,
+				Event:     event,
+				PID:       info.PID,
+				SessionID: sessionID,
+				ExitCode:  &code,
+				Reason:    reason,
+			}
+			logs.RecordDaemonEvent(exit)
+			if !success {
+				crash = &exit
+			}
+		}
+
 		updates := map[string]interface{}{
 			"end_time":  endTime,
 			"duration":  duration,
 			"exit_code": exitCode,
 			"success":   success,
+			"resources": logs.UsageFromProcessState(command.ProcessState, duration),
 		}
 
 		if err := logs.UpdateSessionMetadata(sessionID, updates); err != nil {
 			// Non-fatal error
 			fmt.Fprintf(os.Stderr, "Warning: failed to update session metadata: %v\n", err)
+		} else {
+			logs.PublishSession(sessionID)
 		}
 
 		deletePIDFile(taskName)
 		close(doneChan) // Signal that Wait() has completed
 		pm.mu.Lock()
 		delete(pm.processes, taskName)
+		onCrash := pm.onCrash
 		pm.mu.Unlock()
+
+		// Report the crash once the daemon is gone, so a handler that
+		// restarts it is not refused as already running
+		if crash != nil && onCrash != nil {
+			onCrash(*crash)
+		}
 	}()
 
 	return nil
 }
 
+// SetCrashHandler calls handler whenever a daemon started by this Manager
+// exits on its own with a failure status. Daemons adopted from earlier
+// processes are not reported, since their exit status is unknown.
+func (pm *Manager) SetCrashHandler(handler func(event logs.DaemonEvent)) {
+	pm.mu.Lock()
+	pm.onCrash = handler
+	pm.mu.Unlock()
+}
+
+// SendInput writes input to the stdin of an interactive daemon started by
+// this Manager.
+func (pm *Manager) SendInput(taskName string, input string) error {
+	pm.mu.RLock()
+	proc, exists := pm.processes[taskName]
+	pm.mu.RUnlock()
+	if !exists || !isProcessAlive(proc.PID) {
+		return fmt.Errorf("daemon '%s' is not running", taskName)
+	}
+	if proc.input == nil {
+		return fmt.Errorf("daemon '%s' does not accept input (not started as interactive by this runbook process)", taskName)
+	}
+
+	proc.inputMu.Lock()
+	defer proc.inputMu.Unlock()
+	if proc.inputEcho != nil {
+		_, _ = io.WriteString(proc.inputEcho, input)
+	}
+	if _, err := io.WriteString(proc.input, input); err != nil {
+		return fmt.Errorf("failed to write input: %w", err)
+	}
+	return nil
+}
+
+// crlfWriter converts the CRLF line endings of terminal output to LF.
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\r\n"), []byte("\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // Stop stops a running daemon process. Returns an error if the daemon is not
 // running or was started by a different Manager instance (ownership check).
 func (pm *Manager) Stop(taskName string) error {
+	return pm.stop(taskName, "stop requested")
+}
+
+// stop implements Stop, recording reason in the daemon's event log. The
+// lock is only held to signal the daemon, so several daemons can be stopped
+// at once, each waiting out the grace period in parallel.
+func (pm *Manager) stop(taskName string, reason string) error {
 	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	signal, grace := "TERM", pm.stopGrace
+	if setting, ok := pm.stops[taskName]; ok {
+		if setting.signal != "" {
+			signal = setting.signal
+		}
+		if setting.grace > 0 {
+			grace = setting.grace
+		}
+	}
+	sig, err := lookupSignal(signal)
+	if err != nil {
+		pm.mu.Unlock()
+		return err
+	}
 
 	proc, exists := pm.processes[taskName]
 	if !exists {
+		pm.mu.Unlock()
 		return fmt.Errorf("daemon '%s' is not running", taskName)
 	}
 
 	// Check if process is actually alive
 	if !isProcessAlive(proc.PID) {
 		delete(pm.processes, taskName)
+		pm.mu.Unlock()
 		return fmt.Errorf("daemon '%s' is not running", taskName)
 	}
 
 	// Ownership check: only the Manager that started the daemon can stop it.
 	// Other standalone instances can observe (status/logs) but not modify.
 	if proc.OwnerID != pm.ownerID {
+		pm.mu.Unlock()
 		return fmt.Errorf("daemon '%s' is owned by another runbook process and cannot be stopped from here", taskName)
 	}
 
-	// Send SIGTERM to entire process group
+	// Send the stop signal (SIGTERM by default) to entire process group
 	// The daemon's PID equals its PGID (because we set Setpgid=true)
 	// Negative PID means send to all processes in that process group
 	// This terminates the daemon AND all its children
-	if err := killProcessGroup(proc.PID, syscall.SIGTERM); err != nil {
-		return fmt.Errorf("failed to send SIGTERM to process group: %w", err)
+	proc.stopping.Store(true)
+	if err := killProcessGroup(proc.PID, sig); err != nil {
+		proc.stopping.Store(false)
+		pm.mu.Unlock()
+		return fmt.Errorf("failed to send SIG%s to process group: %w", signal, err)
 	}
+	pm.mu.Unlock()
+	how := "terminated with SIG" + signal
 
-	// Wait for graceful shutdown (5 seconds)
+	// Wait for graceful shutdown
 	// Wait on the done channel instead of calling Wait() again to avoid race
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
 	select {
-	case <-time.After(5 * time.Second):
+	case <-timer.C:
 		// Graceful shutdown timeout, send SIGKILL to entire process group
 		// This force-kills the daemon and all children that didn't exit gracefully
 		if err := killProcessGroup(proc.PID, syscall.SIGKILL); err != nil {
 			return fmt.Errorf("failed to kill process group: %w", err)
 		}
+		how = fmt.Sprintf("killed with SIGKILL after %s SIG%s grace period", grace, signal)
 		// Wait for monitoring goroutine to finish
 		<-proc.done
 	case <-proc.done:
 		// Process terminated gracefully
 	}
 
-	// Clean up (monitoring goroutine already deleted from map)
-	// But we still hold the lock, so make sure it's gone
-	delete(pm.processes, taskName)
-	deletePIDFile(taskName)
+	logs.RecordDaemonEvent(logs.DaemonEvent{
+		Task:      taskName,
+		Event:     logs.EventStop,
+		PID:       proc.PID,
+		SessionID: proc.SessionID,
+		Reason:    fmt.Sprintf("%s; %s", reason, how),
+	})
+
+	// Clean up (monitoring goroutine already deleted from map), unless the
+	// daemon was started again in the meantime
+	pm.mu.Lock()
+	if pm.processes[taskName] == proc {
+		delete(pm.processes, taskName)
+		deletePIDFile(taskName)
+	}
+	pm.mu.Unlock()
 
 	return nil
 }
 
+// Signal sends the named signal, such as "HUP", to a running daemon's
+// process group, for daemons that reload their configuration on a signal.
+// Like Stop, it only signals daemons started by this Manager.
+func (pm *Manager) Signal(taskName string, signal string) error {
+	pm.mu.RLock()
+	proc, exists := pm.processes[taskName]
+	pm.mu.RUnlock()
+	if !exists || !isProcessAlive(proc.PID) {
+		return fmt.Errorf("daemon '%s' is not running", taskName)
+	}
+	if proc.OwnerID != pm.ownerID {
+		return fmt.Errorf("daemon '%s' is owned by another runbook process and cannot be signaled from here", taskName)
+	}
+
+	if err := signalProcessGroup(proc.PID, signal); err != nil {
+		return err
+	}
+	logs.RecordDaemonEvent(logs.DaemonEvent{
+		Task:      taskName,
+		Event:     logs.EventReload,
+		PID:       proc.PID,
+		SessionID: proc.SessionID,
+		Reason:    "sent SIG" + signal,
+	})
+	return nil
+}
+
+// Usage returns the resources a running daemon's process group is using.
+// Unlike Signal it only reads, so daemons of other runbook processes are
+// included.
+func (pm *Manager) Usage(taskName string) (*logs.GroupUsage, error) {
+	pm.mu.RLock()
+	proc, exists := pm.processes[taskName]
+	pm.mu.RUnlock()
+	if !exists || !isProcessAlive(proc.PID) {
+		return nil, fmt.Errorf("daemon '%s' is not running", taskName)
+	}
+	return groupUsage(proc.PID)
+}
+
+// SetStopGrace sets how long a stopped daemon has to exit after SIGTERM
+// before it is killed with SIGKILL. Values of zero or less restore
+// DefaultStopGrace.
+func (pm *Manager) SetStopGrace(grace time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if grace <= 0 {
+		grace = DefaultStopGrace
+	}
+	pm.stopGrace = grace
+}
+
 // Status returns the status of a daemon process
 func (pm *Manager) Status(taskName string) (bool, int, error) {
 	pm.mu.RLock()
@@ -323,7 +768,8 @@ func (pm *Manager) Status(taskName string) (bool, int, error) {
 	return true, proc.PID, nil
 }
 
-// StopAll stops all daemon processes owned by this Manager instance.
+// StopAll stops all daemon processes owned by this Manager instance at once,
+// so they share one grace period instead of each waiting out its own.
 // Daemons started by other Manager instances are left running.
 func (pm *Manager) StopAll() error {
 	pm.mu.Lock()
@@ -334,14 +780,25 @@ func (pm *Manager) StopAll() error {
 		}
 	}
 	pm.mu.Unlock()
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = pm.stop(name, "runbook shutting down")
+		}()
+	}
+	wg.Wait()
 
 	var errors []string
-	for _, name := range names {
-		if err := pm.Stop(name); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+	for i, err := range errs {
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", names[i], err))
 		}
 	}
-
 	if len(errors) > 0 {
 		return fmt.Errorf("failed to stop some daemons: %v", errors)
 	}
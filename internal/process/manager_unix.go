@@ -42,3 +42,41 @@ func killProcessGroup(pid int, sig syscall.Signal) error {
 	return nil
 }
 
+// signalsByName maps the signals a daemon can be sent to reload or stop it,
+// by name without the SIG prefix.
+var signalsByName = map[string]syscall.Signal{
+	"TERM":  syscall.SIGTERM,
+	"HUP":   syscall.SIGHUP,
+	"USR1":  syscall.SIGUSR1,
+	"USR2":  syscall.SIGUSR2,
+	"INT":   syscall.SIGINT,
+	"QUIT":  syscall.SIGQUIT,
+	"WINCH": syscall.SIGWINCH,
+}
+
+// signalProcessGroup sends the named signal to a process group.
+func signalProcessGroup(pid int, name string) error {
+	sig, err := lookupSignal(name)
+	if err != nil {
+		return err
+	}
+	return killProcessGroup(pid, sig)
+}
+
+// lookupSignal returns the signal with the given name, without its SIG
+// prefix.
+func lookupSignal(name string) (syscall.Signal, error) {
+	sig, ok := signalsByName[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown signal '%s'", name)
+	}
+	return sig, nil
+}
+
+// terminateProcess asks a single process to shut down gracefully.
+func terminateProcess(pid int) error {
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
@@ -4,9 +4,12 @@ package process
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"strconv"
 	"syscall"
+
+	"runbookmcp.dev/internal/logs"
 )
 
 // getProcAttrs returns Windows-specific process attributes.
@@ -31,3 +34,40 @@ func killProcessGroup(pid int, sig syscall.Signal) error {
 	}
 	return fmt.Errorf("failed to kill process group (PID %d): %w", pid, err)
 }
+
+// signalProcessGroup fails: Windows has no signals a daemon could reload on.
+func signalProcessGroup(pid int, name string) error {
+	return fmt.Errorf("sending SIG%s is not supported on Windows", name)
+}
+
+// lookupSignal returns SIGTERM for any name: stopping on Windows kills the
+// process tree, whatever signal the daemon asks for.
+func lookupSignal(name string) (syscall.Signal, error) {
+	return syscall.SIGTERM, nil
+}
+
+// portOwner returns 0: the owner of a port is not looked up on Windows.
+func portOwner(port int) int {
+	return 0
+}
+
+// processGroup returns 0: Windows has no process groups.
+func processGroup(pid int) int {
+	return 0
+}
+
+// groupUsage fails: Windows has no process groups to sum usage over.
+func groupUsage(pid int) (*logs.GroupUsage, error) {
+	return nil, fmt.Errorf("process usage is not supported on Windows")
+}
+
+// terminateProcess stops a single process. Windows has no SIGTERM, so the
+// process is killed without draining; its daemons run in their own process
+// groups and survive as orphans.
+func terminateProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	return p.Kill()
+}
@@ -21,6 +21,7 @@ type pidFileData struct {
 	TaskName  string    `json:"task_name"`
 	StartTime time.Time `json:"start_time"`
 	LogFile   string    `json:"log_file"`
+	Ports     []int     `json:"ports,omitempty"`
 }
 
 func pidFilePath(taskName string) string {
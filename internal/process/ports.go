@@ -0,0 +1,41 @@
+package process
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// portInUse reports whether something on this machine is listening on the
+// TCP port.
+func portInUse(port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)), 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// checkPorts returns an error naming the first of ports that is already in
+// use, with the PID listening on it and the daemon that PID belongs to when
+// they can be found. The caller must hold pm.mu.
+func (pm *Manager) checkPorts(ports []int) error {
+	for _, port := range ports {
+		if !portInUse(port) {
+			continue
+		}
+		pid := portOwner(port)
+		if pid == 0 {
+			return fmt.Errorf("port %d is already in use", port)
+		}
+		for name, proc := range pm.processes {
+			if proc.PID == pid || processGroup(pid) == proc.PID {
+				return fmt.Errorf("port %d is already in use by PID %d (daemon '%s')", port, pid, name)
+			}
+		}
+		return fmt.Errorf("port %d is already in use by PID %d", port, pid)
+	}
+	return nil
+}
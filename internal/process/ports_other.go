@@ -0,0 +1,32 @@
+//go:build unix && !linux
+
+package process
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// portOwner returns the PID of a process listening on the TCP port, as lsof
+// reports it, or 0 if none can be found. macOS and the BSDs have no /proc to
+// read instead.
+func portOwner(port int) int {
+	out, err := exec.Command("lsof", "-nP", "-t", "-iTCP:"+strconv.Itoa(port), "-sTCP:LISTEN").Output()
+	if err != nil {
+		return 0
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	pid, _ := strconv.Atoi(first)
+	return pid
+}
+
+// processGroup returns the process group of pid, or 0 if it cannot be read.
+func processGroup(pid int) int {
+	pgid, err := syscall.Getpgid(pid)
+	if err != nil {
+		return 0
+	}
+	return pgid
+}
@@ -0,0 +1,64 @@
+package process
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestManagerPorts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("logs setup: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	busy := listener.Addr().(*net.TCPAddr).Port
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	freePort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	manager := NewManager()
+	manager.SetPorts("web", []int{freePort, busy})
+	err = manager.Start("web", "sess-web", "sleep 30", nil, "", logs.GetLogPath("web"), "")
+	if err == nil {
+		_ = manager.Stop("web")
+		t.Fatal("expected the start to fail on a port in use")
+	}
+	want := fmt.Sprintf("port %d is already in use", busy)
+	if runtime.GOOS == "linux" {
+		want = fmt.Sprintf("port %d is already in use by PID %d", busy, os.Getpid())
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+	if running, _, _ := manager.Status("web"); running {
+		t.Error("daemon should not have started")
+	}
+
+	manager.SetPorts("web", []int{freePort})
+	if err := manager.Start("web", "sess-web", "sleep 30", nil, "", logs.GetLogPath("web"), ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = manager.Stop("web") }()
+	if ports := manager.Ports("web"); len(ports) != 1 || ports[0] != freePort {
+		t.Errorf("Ports() = %v, want [%d]", ports, freePort)
+	}
+	// Other runbook processes read the ports from the PID file
+	if ports := NewManager().Ports("web"); len(ports) != 1 || ports[0] != freePort {
+		t.Errorf("restored Ports() = %v, want [%d]", ports, freePort)
+	}
+}
@@ -7,15 +7,19 @@ import (
 )
 
 // ProbeHTTP returns true if addr responds to an HTTP GET request within 5 seconds.
-// Any HTTP response (even 404/405) means the server is listening.
-func ProbeHTTP(addr string) bool {
+// Any HTTP response (even 404/405) means the server is listening. client
+// carries the caller's TLS settings; nil means http.DefaultClient.
+func ProbeHTTP(addr string, client *http.Client) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "GET", addr, nil)
 	if err != nil {
 		return false
 	}
-	resp, err := http.DefaultClient.Do(req)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return false
 	}
@@ -12,7 +12,7 @@ func TestProbeHTTPSuccess(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	if !ProbeHTTP(srv.URL) {
+	if !ProbeHTTP(srv.URL, nil) {
 		t.Errorf("ProbeHTTP(%s) = false, want true (server is up)", srv.URL)
 	}
 }
@@ -24,14 +24,14 @@ func TestProbeHTTPNotFound(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	if !ProbeHTTP(srv.URL) {
+	if !ProbeHTTP(srv.URL, nil) {
 		t.Errorf("ProbeHTTP(%s) = false for 404, want true (server is listening)", srv.URL)
 	}
 }
 
 func TestProbeHTTPNoServer(t *testing.T) {
 	// Nothing listening on this port.
-	if ProbeHTTP("http://127.0.0.1:19743") {
+	if ProbeHTTP("http://127.0.0.1:19743", nil) {
 		t.Error("ProbeHTTP = true for unbound port, want false")
 	}
 }
@@ -278,6 +278,44 @@ func TestManagerEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestManagerInheritedEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(oldWd); err != nil {
+			t.Errorf("failed to restore working directory: %v", err)
+		}
+	}()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	t.Setenv("RUNBOOK_HOST_SECRET", "leaked")
+
+	manager := NewManager()
+	logPath := logs.GetLogPath("test-daemon")
+	manager.SetInheritedEnv("test-daemon", []string{"KEPT=yes"})
+	env := map[string]string{"TEST_VAR": "set"}
+	if err := manager.Start("test-daemon", "test-session-id", `echo "kept=$KEPT secret=$RUNBOOK_HOST_SECRET var=$TEST_VAR"`, env, "", logPath, ""); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	_ = manager.Stop("test-daemon")
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "kept=yes secret= var=set") {
+		t.Errorf("expected only the inherited env and the task's env, got: %s", content)
+	}
+}
+
 func TestManagerWorkingDirectory(t *testing.T) {
 	// Setup
 	tmpDir := t.TempDir()
@@ -455,7 +493,8 @@ func TestManagerStopAllConcurrent(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		taskName := fmt.Sprintf("concurrent-daemon-%d", i)
 		logPath := logs.GetLogPath(taskName)
-		if err := manager.Start(taskName, "test-session-id", "sleep 10", nil, "", logPath, ""); err != nil {
+		sessionID := fmt.Sprintf("test-session-%d", i)
+		if err := manager.Start(taskName, sessionID, "sleep 10", nil, "", logPath, ""); err != nil {
 			t.Fatalf("failed to start daemon %s: %v", taskName, err)
 		}
 	}
@@ -478,6 +517,42 @@ func TestManagerStopAllConcurrent(t *testing.T) {
 	}
 }
 
+// TestManagerStopAllSharedGrace verifies daemons that ignore SIGTERM are
+// killed after one shared grace period rather than one each.
+func TestManagerStopAllSharedGrace(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("logs setup: %v", err)
+	}
+
+	manager := NewManager()
+	manager.SetStopGrace(time.Second)
+	for i := 0; i < 3; i++ {
+		taskName := fmt.Sprintf("stubborn-%d", i)
+		if err := manager.Start(taskName, "sess-"+taskName, "trap '' TERM; sleep 30", nil, "", logs.GetLogPath(taskName), "/bin/sh"); err != nil {
+			t.Fatalf("failed to start %s: %v", taskName, err)
+		}
+	}
+
+	start := time.Now()
+	if err := manager.StopAll(); err != nil {
+		t.Fatalf("StopAll failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2500*time.Millisecond {
+		t.Errorf("expected one shared 1s grace period, StopAll took %s", elapsed)
+	}
+	for i := 0; i < 3; i++ {
+		if running, _, _ := manager.Status(fmt.Sprintf("stubborn-%d", i)); running {
+			t.Errorf("expected stubborn-%d to be killed", i)
+		}
+	}
+}
+
 // TestNewManagerPreservesDaemonsAcrossInvocations proves that creating a new
 // Manager (what every CLI subcommand does via bootstrap) must NOT kill daemons
 // that are still running. Before the fix, NewManager called restoreFromPIDFiles
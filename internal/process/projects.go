@@ -0,0 +1,174 @@
+package process
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+// projectsFile is the name of the per-user index of project directories
+// that have started daemons, in dirs.UserStateDir.
+const projectsFile = "projects.json"
+
+// projectsLockTimeout bounds how long an update waits for another process
+// to finish updating the projects file.
+const projectsLockTimeout = 5 * time.Second
+
+// staleProjectsLock is the age after which a lock on the projects file is
+// taken to be left by a process that crashed while holding it.
+const staleProjectsLock = 30 * time.Second
+
+// projectsIndex is the content of the projects file.
+type projectsIndex struct {
+	Projects []string `json:"projects"`
+}
+
+// projectsPath returns the path of the projects file, or "" when the user
+// state directory cannot be determined.
+func projectsPath() string {
+	dir := dirs.UserStateDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, projectsFile)
+}
+
+// KnownProjects returns the directories of the projects recorded in the
+// per-user index, sorted.
+func KnownProjects() ([]string, error) {
+	path := projectsPath()
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read project index: %w", err)
+	}
+	var index projectsIndex
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse project index %s: %w", path, err)
+	}
+	return index.Projects, nil
+}
+
+// registerProject adds the current working directory to the per-user
+// index, so runbook ps finds the daemons started there.
+func registerProject() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	return addProject(dir)
+}
+
+// addProject adds dir to the per-user index.
+func addProject(dir string) error {
+	return updateProjects(func(projects []string) ([]string, bool) {
+		if slices.Contains(projects, dir) {
+			return projects, false
+		}
+		return append(projects, dir), true
+	})
+}
+
+// forgetProjects removes gone from the per-user index.
+func forgetProjects(gone []string) error {
+	if len(gone) == 0 {
+		return nil
+	}
+	return updateProjects(func(projects []string) ([]string, bool) {
+		return slices.DeleteFunc(projects, func(p string) bool {
+			return slices.Contains(gone, p)
+		}), true
+	})
+}
+
+// updateProjects applies update to the per-user index while holding its
+// lock, so concurrent updates from several processes do not lose entries.
+// The index is only written when update reports a change.
+func updateProjects(update func(projects []string) ([]string, bool)) error {
+	path := projectsPath()
+	if path == "" {
+		return fmt.Errorf("cannot determine the user state directory")
+	}
+	unlock, err := lockProjects(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	projects, err := KnownProjects()
+	if err != nil {
+		return err
+	}
+	projects, changed := update(projects)
+	if !changed {
+		return nil
+	}
+	return writeProjects(projects)
+}
+
+// lockProjects takes the lock on the projects file at path: a file next to
+// it that only one process can create. A lock older than staleProjectsLock
+// is removed and taken. The returned function releases the lock.
+func lockProjects(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(projectsLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock project index: %w", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleProjectsLock {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the project index lock %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// writeProjects replaces the per-user index with projects, sorted. It is
+// written to a temporary file and renamed into place, so readers never see
+// a partial index.
+func writeProjects(projects []string) error {
+	path := projectsPath()
+	if path == "" {
+		return fmt.Errorf("cannot determine the user state directory")
+	}
+	slices.Sort(projects)
+	b, err := json.MarshalIndent(projectsIndex{Projects: slices.Compact(projects)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".projects-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write project index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write project index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write project index: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,129 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DaemonEntry is a running daemon found by ListAllDaemons.
+type DaemonEntry struct {
+	Project   string    `json:"project"` // Project directory
+	Task      string    `json:"task"`
+	PID       int       `json:"pid"`
+	SessionID string    `json:"session_id"`
+	StartTime time.Time `json:"start_time"`
+	Ports     []int     `json:"ports,omitempty"`
+	OwnerPID  int       `json:"owner_pid"` // Process that manages the daemon, 0 for none
+	Orphaned  bool      `json:"orphaned"`  // No runbook process manages the daemon
+}
+
+// ListAllDaemons returns the running daemons of every project in the
+// per-user index, by project and task. A daemon is managed by the process
+// that started it while that is alive, and otherwise by the project's
+// server; with neither it is orphaned. Projects whose state directory is
+// gone are dropped from the index.
+func ListAllDaemons() ([]DaemonEntry, error) {
+	projects, err := KnownProjects()
+	if err != nil {
+		return nil, err
+	}
+	var entries []DaemonEntry
+	var gone []string
+	for _, project := range projects {
+		found, err := projectDaemons(project)
+		if os.IsNotExist(err) {
+			gone = append(gone, project)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+	if err := forgetProjects(gone); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update project index: %v\n", err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Project != entries[j].Project {
+			return entries[i].Project < entries[j].Project
+		}
+		return entries[i].Task < entries[j].Task
+	})
+	return entries, nil
+}
+
+// projectDaemons returns the running daemons recorded in a project's PID
+// files. It returns an os.IsNotExist error when the project has no state
+// directory.
+func projectDaemons(project string) ([]DaemonEntry, error) {
+	dir := filepath.Join(project, pidsDir)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if _, statErr := os.Stat(filepath.Dir(dir)); statErr == nil {
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+
+	serverPID := 0
+	if server, err := ReadServerFile(project); err == nil && isProcessAlive(server.PID) {
+		serverPID = server.PID
+	}
+	var entries []DaemonEntry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".pid") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			continue
+		}
+		var data pidFileData
+		if json.Unmarshal(b, &data) != nil || !isProcessAlive(data.PID) {
+			continue
+		}
+		entry := DaemonEntry{
+			Project:   project,
+			Task:      data.TaskName,
+			PID:       data.PID,
+			SessionID: data.SessionID,
+			StartTime: data.StartTime,
+			Ports:     data.Ports,
+			OwnerPID:  data.OwnerPID,
+		}
+		if !isProcessAlive(data.OwnerPID) {
+			entry.OwnerPID = serverPID
+		}
+		entry.Orphaned = entry.OwnerPID == 0
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// KillDaemon stops a daemon found by ListAllDaemons the way Manager.Stop
+// does: SIGTERM to its process group, then SIGKILL after DefaultStopGrace.
+// Its PID file is removed.
+func KillDaemon(entry DaemonEntry) error {
+	if err := killProcessGroup(entry.PID, syscall.SIGTERM); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(DefaultStopGrace)
+	for isProcessAlive(entry.PID) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if isProcessAlive(entry.PID) {
+		if err := killProcessGroup(entry.PID, syscall.SIGKILL); err != nil {
+			return err
+		}
+	}
+	_ = os.Remove(filepath.Join(entry.Project, pidsDir, entry.Task+".pid"))
+	return nil
+}
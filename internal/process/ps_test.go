@@ -0,0 +1,108 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestListAllDaemons(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	project := t.TempDir()
+	t.Chdir(project)
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("logs setup: %v", err)
+	}
+
+	manager := NewManager()
+	if err := manager.Start("web", "sess-web", "sleep 30", nil, "", logs.GetLogPath("web"), ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer func() { _ = manager.Stop("web") }()
+
+	// A daemon whose owner has exited, in a project with no server
+	other := t.TempDir()
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	orphan := exec.Command("sleep", "30")
+	orphan.SysProcAttr = getProcAttrs()
+	if err := orphan.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = orphan.Process.Kill() }()
+	go func() { _ = orphan.Wait() }() // Reap it once killed
+	workerPIDFile := filepath.Join(other, pidsDir, "worker.pid")
+	if err := os.MkdirAll(filepath.Dir(workerPIDFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(pidFileData{PID: orphan.Process.Pid, OwnerPID: exited.Process.Pid, TaskName: "worker", StartTime: time.Now()})
+	if err := os.WriteFile(workerPIDFile, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	projects, _ := KnownProjects()
+	if err := writeProjects(append(projects, other, filepath.Join(other, "removed"))); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ListAllDaemons()
+	if err != nil {
+		t.Fatalf("ListAllDaemons() error = %v", err)
+	}
+	byTask := make(map[string]DaemonEntry)
+	for _, e := range entries {
+		byTask[e.Task] = e
+	}
+	if web := byTask["web"]; web.Project != project || web.OwnerPID != os.Getpid() || web.Orphaned {
+		t.Errorf("web = %+v, want it in %s owned by PID %d", web, project, os.Getpid())
+	}
+	worker := byTask["worker"]
+	if worker.Project != other || !worker.Orphaned {
+		t.Fatalf("worker = %+v, want an orphan in %s", worker, other)
+	}
+	if projects, _ := KnownProjects(); len(projects) != 2 {
+		t.Errorf("KnownProjects() = %v, want the removed project dropped", projects)
+	}
+
+	if err := KillDaemon(worker); err != nil {
+		t.Fatalf("KillDaemon() error = %v", err)
+	}
+	if _, err := os.Stat(workerPIDFile); !os.IsNotExist(err) {
+		t.Errorf("PID file still exists: %v", err)
+	}
+}
+
+func TestAddProjectConcurrent(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := addProject(fmt.Sprintf("/projects/p%02d", i)); err != nil {
+				t.Errorf("addProject() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	projects, err := KnownProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 20 {
+		t.Errorf("KnownProjects() = %d projects, want all 20: %v", len(projects), projects)
+	}
+	if _, err := os.Stat(projectsPath() + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock left behind: %v", err)
+	}
+}
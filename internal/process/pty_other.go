@@ -0,0 +1,20 @@
+//go:build !linux
+
+package process
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// openPTY reports that pseudo-terminals are not supported; interactive
+// daemons fall back to a stdin pipe.
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.New("pseudo-terminals are not supported on this platform")
+}
+
+// ptyProcAttrs returns the regular daemon process attributes.
+func ptyProcAttrs() *syscall.SysProcAttr {
+	return getProcAttrs()
+}
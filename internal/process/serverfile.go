@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"runbookmcp.dev/internal/dirs"
 )
@@ -13,10 +16,16 @@ import (
 // server writes its address and PID when it starts.
 const ServerRegistryFile = dirs.StateDir + "/server.json"
 
+// ServerHandoverFile marks that a new server is replacing a running one. It
+// holds the old server's PID; that server leaves its daemons running when it
+// shuts down, for the new one to adopt.
+const ServerHandoverFile = dirs.StateDir + "/server.handover"
+
 // ServerFileData is persisted to disk when the HTTP server starts.
 type ServerFileData struct {
-	Addr string `json:"addr"`
-	PID  int    `json:"pid"`
+	Addr  string `json:"addr"`
+	PID   int    `json:"pid"`
+	Token string `json:"token,omitempty"` // Secret the server proves it holds in the handshake
 }
 
 func serverFilePath(workingDir string) string {
@@ -26,7 +35,10 @@ func serverFilePath(workingDir string) string {
 	return filepath.Join(workingDir, ServerRegistryFile)
 }
 
-// WriteServerFile writes the server registry to disk in the current working directory.
+// WriteServerFile writes the server registry to disk in the current working
+// directory. The file is readable only by its owner, since it holds the
+// server's token; it is written to a temporary file and renamed into place
+// so it is never briefly readable by others.
 func WriteServerFile(data ServerFileData) error {
 	dir := filepath.Dir(ServerRegistryFile)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -36,7 +48,20 @@ func WriteServerFile(data ServerFileData) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal server file: %w", err)
 	}
-	return os.WriteFile(ServerRegistryFile, b, 0644)
+
+	tmp, err := os.CreateTemp(dir, ".server-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create server file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write server file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write server file: %w", err)
+	}
+	return os.Rename(tmp.Name(), ServerRegistryFile)
 }
 
 // ReadServerFile reads the server registry. workingDir="" uses the current working directory.
@@ -61,3 +86,35 @@ func DeleteServerFile(workingDir string) {
 func IsProcessAlive(pid int) bool {
 	return isProcessAlive(pid)
 }
+
+// ReplaceServer gracefully stops the server with the given PID so a new
+// server can take over the project in the current working directory, and
+// waits up to timeout for it to exit. The old server's daemons keep running
+// and are adopted by the next Manager created.
+func ReplaceServer(pid int, timeout time.Duration) error {
+	if err := os.MkdirAll(filepath.Dir(ServerHandoverFile), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(ServerHandoverFile, []byte(strconv.Itoa(pid)), 0644); err != nil {
+		return fmt.Errorf("failed to write handover file: %w", err)
+	}
+	defer os.Remove(ServerHandoverFile)
+
+	if err := terminateProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop server (PID %d): %w", pid, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for isProcessAlive(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("server (PID %d) did not exit within %s", pid, timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+// HandoverRequested reports whether a new server is replacing this process.
+func HandoverRequested() bool {
+	b, err := os.ReadFile(ServerHandoverFile)
+	return err == nil && strings.TrimSpace(string(b)) == strconv.Itoa(os.Getpid())
+}
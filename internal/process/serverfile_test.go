@@ -2,8 +2,11 @@ package process
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"runbookmcp.dev/internal/dirs"
 )
@@ -117,3 +120,71 @@ func TestIsProcessAliveDeadPID(t *testing.T) {
 		t.Error("IsProcessAlive(999999999) = true, expected false for non-existent PID")
 	}
 }
+
+func TestReplaceServer(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	old := exec.Command("sleep", "30")
+	if err := old.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		old.Wait()
+		close(exited)
+	}()
+
+	if err := ReplaceServer(old.Process.Pid, 5*time.Second); err != nil {
+		t.Fatalf("ReplaceServer: %v", err)
+	}
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("old server was not stopped")
+	}
+	if _, err := os.Stat(ServerHandoverFile); !os.IsNotExist(err) {
+		t.Errorf("handover file should be removed, got %v", err)
+	}
+}
+
+func TestHandoverRequested(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if HandoverRequested() {
+		t.Error("no handover file: expected false")
+	}
+	if err := os.MkdirAll(dirs.StateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ServerHandoverFile, []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if HandoverRequested() {
+		t.Error("handover for another PID: expected false")
+	}
+	if err := os.WriteFile(ServerHandoverFile, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !HandoverRequested() {
+		t.Error("handover for this process: expected true")
+	}
+}
+
+func TestWriteServerFileIsPrivate(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if err := WriteServerFile(ServerFileData{Addr: "http://localhost:8080", PID: 1, Token: "secret"}); err != nil {
+		t.Fatalf("WriteServerFile: %v", err)
+	}
+	info, err := os.Stat(ServerRegistryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("server.json permissions = %o, want 600", perm)
+	}
+	got, err := ReadServerFile("")
+	if err != nil || got.Token != "secret" {
+		t.Errorf("expected the token to round-trip, got %+v (%v)", got, err)
+	}
+}
@@ -0,0 +1,83 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+// ServerLockFile is held by the server running for a project, so a second
+// server started in the same directory cannot adopt its daemons and
+// overwrite its PID files and registry. The lock is an OS advisory lock on
+// the file, which the OS drops when its holder exits; the PID written into
+// it only names the holder in errors.
+const ServerLockFile = dirs.StateDir + "/server.lock"
+
+// ServerLockedError is returned by AcquireServerLock when another process
+// holds the lock.
+type ServerLockedError struct {
+	PID int // 0 if the holder has not written its PID yet
+}
+
+func (e *ServerLockedError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("another runbook server holds %s for this directory", ServerLockFile)
+	}
+	return fmt.Sprintf("another runbook server (PID %d) holds %s for this directory", e.PID, ServerLockFile)
+}
+
+// errLockHeld is returned by lockFile when another open file holds the lock.
+var errLockHeld = errors.New("lock held")
+
+// AcquireServerLock takes the server lock of the project in the current
+// working directory. The returned function releases the lock.
+func AcquireServerLock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(ServerLockFile), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	// The file is never removed: a process that opened it just before the
+	// removal would lock a file no one else can see
+	f, err := os.OpenFile(ServerLockFile, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", ServerLockFile, err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errLockHeld) {
+			holder, _ := readServerLock()
+			return nil, &ServerLockedError{PID: holder}
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", ServerLockFile, err)
+	}
+
+	if err := f.Truncate(0); err == nil {
+		_, err = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	if err != nil {
+		_ = unlockFile(f)
+		f.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", ServerLockFile, err)
+	}
+
+	return func() {
+		_ = f.Truncate(0)
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// readServerLock returns the PID in the server lock, or 0 when the file
+// does not hold one.
+func readServerLock() (int, error) {
+	b, err := os.ReadFile(ServerLockFile)
+	if err != nil {
+		return 0, err
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+	return pid, nil
+}
@@ -0,0 +1,94 @@
+package process
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// holdServerLockEnv makes the test binary take the server lock of its
+// working directory and hold it until killed.
+const holdServerLockEnv = "RUNBOOK_TEST_HOLD_SERVER_LOCK"
+
+func holdServerLock() {
+	if _, err := AcquireServerLock(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("locked")
+	select {}
+}
+
+func TestAcquireServerLock(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	release, err := AcquireServerLock()
+	if err != nil {
+		t.Fatalf("AcquireServerLock() error = %v", err)
+	}
+	// A second server sees the lock held and who holds it
+	_, err = AcquireServerLock()
+	var locked *ServerLockedError
+	if !errors.As(err, &locked) || locked.PID != os.Getpid() {
+		t.Fatalf("second AcquireServerLock() error = %v, want it held by PID %d", err, os.Getpid())
+	}
+	release()
+
+	release, err = AcquireServerLock()
+	if err != nil {
+		t.Fatalf("AcquireServerLock() after release error = %v", err)
+	}
+	release()
+
+	// A lock file left by a server that was killed names a PID that may
+	// since have been reused by a live process; only the OS lock counts
+	if err := os.WriteFile(ServerLockFile, []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	release, err = AcquireServerLock()
+	if err != nil {
+		t.Fatalf("AcquireServerLock() over a stale lock file error = %v", err)
+	}
+	defer release()
+	if holder, _ := readServerLock(); holder != os.Getpid() {
+		t.Errorf("lock holder = %d, want %d", holder, os.Getpid())
+	}
+}
+
+func TestServerLockFreedWhenHolderDies(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	holder := exec.Command(os.Args[0], "-test.run=^$")
+	holder.Env = append(os.Environ(), holdServerLockEnv+"=1")
+	stdout, err := holder.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := holder.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Process.Kill()
+	if line, _ := bufio.NewReader(stdout).ReadString('\n'); line != "locked\n" {
+		t.Fatalf("holder did not take the lock: %q", line)
+	}
+
+	_, err = AcquireServerLock()
+	var locked *ServerLockedError
+	if !errors.As(err, &locked) || locked.PID != holder.Process.Pid {
+		t.Fatalf("AcquireServerLock() error = %v, want it held by PID %d", err, holder.Process.Pid)
+	}
+
+	// A killed holder never releases the lock itself
+	if err := holder.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	_ = holder.Wait()
+	release, err := AcquireServerLock()
+	if err != nil {
+		t.Fatalf("AcquireServerLock() after the holder died error = %v", err)
+	}
+	release()
+}
@@ -0,0 +1,117 @@
+package process
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestManagerSignal(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("logs setup: %v", err)
+	}
+
+	manager := NewManager()
+	logPath := logs.GetLogPath("svc")
+	cmd := `trap 'echo reloaded' HUP; echo ready; while true; do sleep 0.1; done`
+	if err := manager.Start("svc", "sess", cmd, nil, "", logPath, ""); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer func() { _ = manager.Stop("svc") }()
+
+	waitForLog := func(want string) bool {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if content, _ := os.ReadFile(logPath); strings.Contains(string(content), want) {
+				return true
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return false
+	}
+	if !waitForLog("ready") {
+		t.Fatal("daemon never became ready")
+	}
+
+	if err := NewManager().Signal("svc", "HUP"); err == nil {
+		t.Error("non-owner Signal should have returned an error")
+	}
+	if err := manager.Signal("svc", "HUP"); err != nil {
+		t.Fatalf("Signal() error = %v", err)
+	}
+	if !waitForLog("reloaded") {
+		t.Error("daemon did not handle SIGHUP")
+	}
+	if running, _, _ := manager.Status("svc"); !running {
+		t.Error("daemon should keep running after a reload signal")
+	}
+
+	events, err := logs.ReadDaemonEvents("svc", 0)
+	if err != nil {
+		t.Fatalf("ReadDaemonEvents() error = %v", err)
+	}
+	if last := events[len(events)-1]; last.Event != logs.EventReload {
+		t.Errorf("last event = %q, want %q", last.Event, logs.EventReload)
+	}
+
+	if err := manager.Signal("missing", "HUP"); err == nil {
+		t.Error("Signal() of a daemon that is not running should fail")
+	}
+}
+
+func TestManagerStopSignal(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("logs setup: %v", err)
+	}
+
+	manager := NewManager()
+	start := func(taskName string) {
+		t.Helper()
+		// Ignores SIGTERM; exits cleanly on SIGINT
+		cmd := `trap '' TERM; trap 'echo interrupted; exit 0' INT; while true; do sleep 0.1; done`
+		if err := manager.Start(taskName, "sess-"+taskName, cmd, nil, "", logs.GetLogPath(taskName), ""); err != nil {
+			t.Fatalf("start %s: %v", taskName, err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	lastStop := func(taskName string) string {
+		t.Helper()
+		events, err := logs.ReadDaemonEvents(taskName, 0)
+		if err != nil || len(events) == 0 {
+			t.Fatalf("ReadDaemonEvents() = %v, %v", events, err)
+		}
+		return events[len(events)-1].Reason
+	}
+
+	start("dev")
+	manager.SetStopSignal("dev", "INT", 0)
+	if err := manager.Stop("dev"); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if reason := lastStop("dev"); !strings.Contains(reason, "terminated with SIGINT") {
+		t.Errorf("stop reason = %q, want the daemon to exit on SIGINT", reason)
+	}
+	if content, _ := os.ReadFile(logs.GetLogPath("dev")); !strings.Contains(string(content), "interrupted") {
+		t.Error("daemon did not handle SIGINT")
+	}
+
+	// Restoring the defaults sends SIGTERM, which this daemon ignores until
+	// the grace period ends
+	start("stubborn")
+	manager.SetStopSignal("stubborn", "", 300*time.Millisecond)
+	begin := time.Now()
+	if err := manager.Stop("stubborn"); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Errorf("expected the 300ms grace period, Stop took %s", elapsed)
+	}
+	if reason := lastStop("stubborn"); !strings.Contains(reason, "SIGKILL after 300ms SIGTERM grace period") {
+		t.Errorf("stop reason = %q", reason)
+	}
+}
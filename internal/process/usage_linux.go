@@ -0,0 +1,114 @@
+//go:build linux
+
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc, which Linux
+// fixes at 100 for user space.
+const clockTicks = 100
+
+// groupUsage sums the /proc stats of every process in the process group
+// led by pgid.
+func groupUsage(pgid int) (*logs.GroupUsage, error) {
+	uptime, err := systemUptime()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc: %w", err)
+	}
+
+	usage := &logs.GroupUsage{}
+	found := false
+	pageSize := int64(os.Getpagesize())
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Processes may exit while the directory is read
+		stat, err := readProcStat(pid)
+		if err != nil || stat.pgrp != pgid {
+			continue
+		}
+		cpu := float64(stat.utime+stat.stime) / clockTicks
+		usage.CPUSeconds += cpu
+		usage.RSSBytes += stat.rssPages * pageSize
+		if elapsed := uptime - float64(stat.starttime)/clockTicks; elapsed > 0 {
+			usage.CPUPercent += cpu / elapsed * 100
+		}
+		if pid == pgid {
+			found = true
+		} else {
+			usage.Children++
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("process %d not found", pgid)
+	}
+	return usage, nil
+}
+
+// procStat holds the fields of /proc/<pid>/stat groupUsage needs.
+type procStat struct {
+	pgrp      int
+	utime     int64
+	stime     int64
+	starttime int64
+	rssPages  int64
+}
+
+// readProcStat parses /proc/<pid>/stat. The command name in parentheses may
+// hold spaces, so fields are counted from the last ')'.
+func readProcStat(pid int) (procStat, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return procStat{}, err
+	}
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("malformed stat for process %d", pid)
+	}
+	// fields[0] is field 3 of proc(5), the state
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat for process %d", pid)
+	}
+	var stat procStat
+	var errs [5]error
+	stat.pgrp, errs[0] = strconv.Atoi(fields[2])
+	stat.utime, errs[1] = strconv.ParseInt(fields[11], 10, 64)
+	stat.stime, errs[2] = strconv.ParseInt(fields[12], 10, 64)
+	stat.starttime, errs[3] = strconv.ParseInt(fields[19], 10, 64)
+	stat.rssPages, errs[4] = strconv.ParseInt(fields[21], 10, 64)
+	for _, err := range errs {
+		if err != nil {
+			return procStat{}, fmt.Errorf("malformed stat for process %d: %w", pid, err)
+		}
+	}
+	return stat, nil
+}
+
+// systemUptime returns the seconds since boot, the clock process start
+// times in /proc are measured on.
+func systemUptime() (float64, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read /proc/uptime: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("malformed /proc/uptime")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
@@ -0,0 +1,74 @@
+//go:build unix && !linux
+
+package process
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// groupUsage sums what ps reports for every process in the process group
+// led by pgid. macOS and the BSDs have no /proc to read instead.
+func groupUsage(pgid int) (*logs.GroupUsage, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,pgid=,rss=,%cpu=,time=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ps: %w", err)
+	}
+
+	usage := &logs.GroupUsage{}
+	found := false
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		group, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil || group != pgid {
+			continue
+		}
+		// ps reports RSS in kilobytes
+		if rss, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			usage.RSSBytes += rss * 1024
+		}
+		if cpu, err := strconv.ParseFloat(fields[3], 64); err == nil {
+			usage.CPUPercent += cpu
+		}
+		usage.CPUSeconds += parseCPUTime(fields[4])
+		if pid == pgid {
+			found = true
+		} else {
+			usage.Children++
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("process %d not found", pgid)
+	}
+	return usage, nil
+}
+
+// parseCPUTime parses a ps time column, [[dd-]hh:]mm:ss[.cc], into seconds.
+// It returns 0 for values it cannot read.
+func parseCPUTime(s string) float64 {
+	var days float64
+	if d, rest, ok := strings.Cut(s, "-"); ok {
+		n, err := strconv.ParseFloat(d, 64)
+		if err != nil {
+			return 0
+		}
+		days, s = n, rest
+	}
+	var seconds float64
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return days*86400 + seconds
+}
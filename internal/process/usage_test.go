@@ -0,0 +1,54 @@
+//go:build unix
+
+package process
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestManagerUsage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("logs setup: %v", err)
+	}
+
+	manager := NewManager()
+	if _, err := manager.Usage("svc"); err == nil {
+		t.Error("Usage() of a stopped daemon should have returned an error")
+	}
+
+	logPath := logs.GetLogPath("svc")
+	cmd := `sleep 30 & sleep 30 & echo ready; wait`
+	if err := manager.Start("svc", "sess", cmd, nil, "", logPath, ""); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer func() { _ = manager.Stop("svc") }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if content, _ := os.ReadFile(logPath); strings.Contains(string(content), "ready") {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Another manager reads usage of daemons it does not own
+	usage, err := NewManager().Usage("svc")
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	if usage.Children < 2 {
+		t.Errorf("Children = %d, want at least the 2 sleeps", usage.Children)
+	}
+	if usage.RSSBytes <= 0 {
+		t.Errorf("RSSBytes = %d, want > 0", usage.RSSBytes)
+	}
+	if usage.CPUPercent < 0 || usage.CPUSeconds < 0 {
+		t.Errorf("CPU = %.1f%% (%.1fs), want non-negative", usage.CPUPercent, usage.CPUSeconds)
+	}
+}
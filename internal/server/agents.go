@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/task"
+)
+
+// maxAgentOutput bounds the body of one output request from an agent.
+const maxAgentOutput = 1 << 20
+
+// agentsResponse is the body of GET /api/agents.
+type agentsResponse struct {
+	Agents []task.AgentInfo `json:"agents"`
+}
+
+// registerAgentAPI adds the endpoints remote agents talk to under
+// APIPath/agents. Agents register, poll for jobs, stream their output back,
+// and report their results; see the agent package.
+//
+// Jobs carry commands and their env, so the endpoints are only added when
+// callers are checked: authenticated is set when server.auth is, and with
+// $RUNBOOK_AGENT_TOKEN every request must also carry that token. It reports
+// whether the endpoints were added.
+func (s *Server) registerAgentAPI(mux *http.ServeMux, authenticated bool) bool {
+	token := os.Getenv(mcputil.AgentTokenEnv)
+	if !authenticated && token == "" {
+		return false
+	}
+	handle := func(pattern string, h http.HandlerFunc) {
+		if token != "" {
+			h = requireAgentToken(token, h)
+		}
+		mux.HandleFunc(pattern, h)
+	}
+	handle("POST "+APIPath+"/agents", s.handleAgentRegister)
+	handle("GET "+APIPath+"/agents", s.handleAgentList)
+	handle("GET "+APIPath+"/agents/{id}/jobs/next", s.handleAgentNextJob)
+	handle("POST "+APIPath+"/agents/{id}/jobs/{job}/output", s.handleAgentOutput)
+	handle("POST "+APIPath+"/agents/{id}/jobs/{job}/result", s.handleAgentResult)
+	return true
+}
+
+// requireAgentToken rejects requests without the agent token in
+// mcputil.AgentTokenHeader.
+func requireAgentToken(token string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get(mcputil.AgentTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			writeAPIJSON(w, http.StatusUnauthorized, apiError{Error: "invalid agent token"})
+			return
+		}
+		h(w, r)
+	}
+}
+
+// hasAgentTasks reports whether any task runs on a remote agent.
+func hasAgentTasks(manifest *config.Manifest) bool {
+	for _, t := range manifest.Tasks {
+		if t.AgentTag() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// handleAgentRegister registers an agent and answers with its AgentInfo,
+// whose ID the agent sends on later requests.
+func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
+	var reg task.AgentRegistration
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if reg.Name == "" {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: "name is required"})
+		return
+	}
+	if len(reg.Tags) == 0 {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: "at least one tag is required"})
+		return
+	}
+	for _, tag := range reg.Tags {
+		if !config.ValidAgentTag(tag) {
+			writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid tag '%s'", tag)})
+			return
+		}
+	}
+	writeAPIJSON(w, http.StatusOK, s.agents.Register(reg.Name, reg.Tags))
+}
+
+// handleAgentList returns the connected agents and the tasks they are
+// running.
+func (s *Server) handleAgentList(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, agentsResponse{Agents: s.agents.Agents()})
+}
+
+// handleAgentNextJob waits up to task.AgentPollWait for a job for the
+// agent and answers with it, or with 204 No Content when none came.
+func (s *Server) handleAgentNextJob(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), task.AgentPollWait)
+	defer cancel()
+	job, err := s.agents.Next(ctx, r.PathValue("id"))
+	if err != nil {
+		writeAgentError(w, err)
+		return
+	}
+	if job == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, job)
+}
+
+// handleAgentOutput passes a chunk of a job's output to its run. The stream
+// query parameter is stdout (the default) or stderr. An empty body only
+// tells the server the agent is alive and still wants the job.
+func (s *Server) handleAgentOutput(w http.ResponseWriter, r *http.Request) {
+	stream := r.URL.Query().Get("stream")
+	switch stream {
+	case "":
+		stream = "stdout"
+	case "stdout", "stderr":
+	default:
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: "stream must be stdout or stderr"})
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAgentOutput))
+	if err != nil {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if err := s.agents.Output(r.PathValue("id"), r.PathValue("job"), stream, data); err != nil {
+		writeAgentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAgentResult ends a job with the agent's result.
+func (s *Server) handleAgentResult(w http.ResponseWriter, r *http.Request) {
+	var result task.AgentResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if err := s.agents.Complete(r.PathValue("id"), r.PathValue("job"), result); err != nil {
+		writeAgentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeAgentError answers an agent request the pool refused: 404 for an
+// unknown agent, which must register again, and 410 for a job it no longer
+// holds, which it should kill.
+func writeAgentError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, task.ErrUnknownAgent):
+		status = http.StatusNotFound
+	case errors.Is(err, task.ErrUnknownAgentJob):
+		status = http.StatusGone
+	}
+	writeAPIJSON(w, status, apiError{Error: err.Error()})
+}
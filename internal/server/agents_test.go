@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/agent"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestAgentRunsTask(t *testing.T) {
+	chdirToTemp(t)
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("logs setup: %v", err)
+	}
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build": {
+				Description:      "Build on the build box",
+				Command:          "pwd; echo built {{.target}}; echo warning >&2; exit 3",
+				Type:             config.TaskTypeOneShot,
+				Runner:           "build-box",
+				WorkingDirectory: "src",
+				Parameters:       map[string]config.Param{"target": {Type: "string"}},
+			},
+			"env": {
+				Description: "Print the agent's environment",
+				Command:     "echo secret=$AGENT_SECRET",
+				Type:        config.TaskTypeOneShot,
+				Runner:      "build-box",
+				EnvPolicy:   &config.EnvPolicy{Mode: config.EnvPolicyNone},
+			},
+		},
+	}
+	t.Setenv(mcputil.AgentTokenEnv, "agent-token")
+	t.Setenv("AGENT_SECRET", "leaked")
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.0.0", "")
+	s.agents = task.NewAgentPool()
+	s.Manager().SetAgentPool(s.agents)
+	mux := http.NewServeMux()
+	if !s.registerAgentAPI(mux, false) {
+		t.Fatal("agent API not registered with an agent token")
+	}
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	t.Cleanup(s.agents.Close)
+
+	result, err := s.Manager().ExecuteOneShot("build", map[string]interface{}{"target": "api"})
+	if err != nil {
+		t.Fatalf("ExecuteOneShot() error = %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "no agent with tag 'build-box' is connected") {
+		t.Fatalf("without agents: success = %v, error = %q", result.Success, result.Error)
+	}
+
+	agentDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(agentDir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	a, err := agent.New(agent.Options{Server: ts.URL, Name: "box-1", Tags: []string{"build-box"}, Dir: agentDir})
+	if err != nil {
+		t.Fatalf("agent.New() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		s.agents.Close()
+		<-done
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.agents.Agents()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("agent did not register")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	result, err = s.Manager().ExecuteOneShot("build", map[string]interface{}{"target": "api"})
+	if err != nil {
+		t.Fatalf("ExecuteOneShot() error = %v", err)
+	}
+	if result.ExitCode != 3 || result.Success {
+		t.Errorf("exit code = %d, success = %v, want 3 and false", result.ExitCode, result.Success)
+	}
+	if result.Agent != "box-1" {
+		t.Errorf("Agent = %q, want box-1", result.Agent)
+	}
+	wantDir, _ := filepath.EvalSymlinks(filepath.Join(agentDir, "src"))
+	if !strings.Contains(result.Stdout, wantDir) || !strings.Contains(result.Stdout, "built api") {
+		t.Errorf("Stdout = %q, want the agent's working directory and the command's output", result.Stdout)
+	}
+	if strings.TrimSpace(result.Stderr) != "warning" {
+		t.Errorf("Stderr = %q, want warning", result.Stderr)
+	}
+
+	content, err := os.ReadFile(logs.GetSessionLogPath(result.SessionID))
+	if err != nil {
+		t.Fatalf("read session log: %v", err)
+	}
+	if !strings.Contains(string(content), "built api") {
+		t.Errorf("session log = %q, want the agent's output", content)
+	}
+	metadata, err := logs.ReadSessionMetadata(result.SessionID)
+	if err != nil {
+		t.Fatalf("read metadata: %v", err)
+	}
+	if metadata.Agent != "box-1" || metadata.ExitCode == nil || *metadata.ExitCode != 3 {
+		t.Errorf("metadata agent = %q, exit code = %v", metadata.Agent, metadata.ExitCode)
+	}
+
+	result, err = s.Manager().ExecuteOneShot("env", nil)
+	if err != nil {
+		t.Fatalf("ExecuteOneShot(env) error = %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "secret=" {
+		t.Errorf("Stdout = %q, want env_policy to keep the agent's environment out", result.Stdout)
+	}
+}
+
+func TestAgentAPIRequiresAuth(t *testing.T) {
+	manifest := &config.Manifest{Version: "1.0", Tasks: map[string]config.Task{}}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.0.0", "")
+	s.agents = task.NewAgentPool()
+	t.Cleanup(s.agents.Close)
+
+	t.Setenv(mcputil.AgentTokenEnv, "")
+	if s.registerAgentAPI(http.NewServeMux(), false) {
+		t.Error("agent API registered without auth or an agent token")
+	}
+	if !s.registerAgentAPI(http.NewServeMux(), true) {
+		t.Error("agent API not registered behind server.auth")
+	}
+
+	t.Setenv(mcputil.AgentTokenEnv, "agent-token")
+	mux := http.NewServeMux()
+	s.registerAgentAPI(mux, false)
+	for _, token := range []string{"", "wrong"} {
+		req := httptest.NewRequest(http.MethodPost, APIPath+"/agents", strings.NewReader(`{"name":"box","tags":["x"]}`))
+		req.Header.Set(mcputil.AgentTokenHeader, token)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, rec.Code)
+		}
+	}
+	req := httptest.NewRequest(http.MethodPost, APIPath+"/agents", strings.NewReader(`{"name":"box","tags":["x"]}`))
+	req.Header.Set(mcputil.AgentTokenHeader, "agent-token")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("valid token: status = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// toolAnnotations returns the MCP annotations for a tool whose task or
+// workflow has hints. Hints left unset stay unset, so clients fall back to
+// their own defaults.
+func toolAnnotations(hints *config.ToolHints) mcp.ToolAnnotation {
+	if hints == nil {
+		return mcp.ToolAnnotation{}
+	}
+	return mcp.ToolAnnotation{
+		ReadOnlyHint:    hints.ReadOnly,
+		DestructiveHint: hints.Destructive,
+		IdempotentHint:  hints.Idempotent,
+	}
+}
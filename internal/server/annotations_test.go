@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestToolAnnotationsFromHints(t *testing.T) {
+	yes, no := true, false
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test":  {Description: "Run tests", Command: "go test ./...", Type: config.TaskTypeOneShot, Hints: &config.ToolHints{ReadOnly: &yes, Idempotent: &yes}},
+			"clean": {Description: "Remove build output", Command: "rm -rf out", Type: config.TaskTypeOneShot, Hints: &config.ToolHints{Destructive: &yes}},
+			"dev":   {Description: "Dev server", Command: "go run .", Type: config.TaskTypeDaemon, Hints: &config.ToolHints{Destructive: &no}},
+			"fmt":   {Description: "Format code", Command: "gofmt -w .", Type: config.TaskTypeOneShot},
+		},
+		Workflows: map[string]config.Workflow{
+			"check": {Description: "Test twice", Steps: []config.WorkflowStep{{Task: "test"}, {Task: "test"}}},
+		},
+	}
+
+	tools := make(map[string]ToolDescription)
+	for _, tool := range DescribeTools(manifest) {
+		tools[tool.Name] = tool
+	}
+
+	test := tools["run_test"].Annotations
+	if test == nil || !isSet(test.ReadOnlyHint, true) || !isSet(test.IdempotentHint, true) || test.DestructiveHint != nil {
+		t.Errorf("run_test annotations = %+v, want read-only and idempotent", test)
+	}
+	if clean := tools["run_clean"].Annotations; clean == nil || !isSet(clean.DestructiveHint, true) {
+		t.Errorf("run_clean annotations = %+v, want destructive", clean)
+	}
+	if dev := tools["start_dev"].Annotations; dev == nil || !isSet(dev.DestructiveHint, false) {
+		t.Errorf("start_dev annotations = %+v, want not destructive", dev)
+	}
+	if format := tools["run_fmt"].Annotations; format != nil {
+		t.Errorf("run_fmt annotations = %+v, want none", format)
+	}
+	if check := tools["run_workflow_check"].Annotations; check == nil || !isSet(check.ReadOnlyHint, true) || !isSet(check.IdempotentHint, true) {
+		t.Errorf("run_workflow_check annotations = %+v, want read-only and idempotent", check)
+	}
+}
+
+// isSet reports whether b is set to want.
+func isSet(b *bool, want bool) bool {
+	return b != nil && *b == want
+}
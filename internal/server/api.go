@@ -0,0 +1,153 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+// APIPath is the prefix of the REST API served next to the MCP endpoint in
+// HTTP mode, for scripts and CI that don't speak JSON-RPC.
+const APIPath = "/api"
+
+// apiError is the body of a failed REST API request.
+type apiError struct {
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code,omitempty"` // Set when the tool returned a coded error
+}
+
+// registerAPI adds the REST API endpoints to mux. Each one answers with the
+// same JSON as the MCP tool it mirrors.
+func (s *Server) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("POST "+APIPath+"/tasks/{name}/run", s.handleAPIRunTask)
+	mux.HandleFunc("GET "+APIPath+"/daemons", s.handleAPIDaemons)
+	mux.HandleFunc("GET "+APIPath+"/sessions/{id}/logs", s.handleAPISessionLogs)
+}
+
+// handleAPIRunTask runs a oneshot task through its run_ tool. The request
+// body, if any, is a JSON object of the tool's arguments.
+func (s *Server) handleAPIRunTask(w http.ResponseWriter, r *http.Request) {
+	args := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil && !errors.Is(err, io.EOF) {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	name := r.PathValue("name")
+	s.callAPITool(w, r, s.current().manifest.ToolName(config.ToolRun, name), fmt.Sprintf("task '%s' not found", name), args)
+}
+
+// handleAPIDaemons returns the status of every daemon exposed over MCP, as
+// status_all does.
+func (s *Server) handleAPIDaemons(w http.ResponseWriter, r *http.Request) {
+	manager := s.Manager()
+	var names []string
+	for _, name := range sortedKeys(manager.GetManifest().Tasks) {
+		if t := manager.GetManifest().Tasks[name]; t.Type.IsDaemon() && !t.Disabled && !t.DisableMCP {
+			names = append(names, name)
+		}
+	}
+
+	statuses := manager.DaemonStatuses(names)
+	resp := statusAllResponse{Total: len(statuses), Daemons: statuses}
+	for _, st := range statuses {
+		if st.Running {
+			resp.Running++
+		}
+	}
+	writeAPIJSON(w, http.StatusOK, resp)
+}
+
+// handleAPISessionLogs reads a session log through read_session_log. The
+// lines, offset, and filter query parameters are passed through.
+func (s *Server) handleAPISessionLogs(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	// Session IDs are UUIDs; rejecting anything else keeps the path inside
+	// the sessions directory
+	if _, err := uuid.Parse(sessionID); err != nil {
+		writeAPIJSON(w, http.StatusBadRequest, apiError{Error: "invalid session id"})
+		return
+	}
+
+	args := map[string]interface{}{"session_id": sessionID}
+	query := r.URL.Query()
+	for _, key := range []string{"lines", "offset"} {
+		if value := query.Get(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				writeAPIJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("%s must be a number", key)})
+				return
+			}
+			args[key] = float64(n)
+		}
+	}
+	for _, key := range []string{"filter", "stream"} {
+		if value := query.Get(key); value != "" {
+			args[key] = value
+		}
+	}
+	s.callAPITool(w, r, "read_session_log", "read_session_log is not available", args)
+}
+
+// callAPITool calls an MCP tool on behalf of a REST request and writes its
+// result: the tool's JSON on success, or an error object with 400 when the
+// tool returned an error and 404 when it or what it looked up does not
+// exist. Calls are counted
+// for graceful shutdown like MCP tool calls.
+func (s *Server) callAPITool(w http.ResponseWriter, r *http.Request, toolName, notFound string, args map[string]interface{}) {
+	tool := s.mcpServer.GetTool(toolName)
+	if tool == nil {
+		writeAPIJSON(w, http.StatusNotFound, apiError{Error: notFound})
+		return
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = toolName
+	req.Params.Arguments = args
+	result, err := s.drain.middleware(tool.Handler)(r.Context(), req)
+	if err != nil {
+		writeAPIJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+
+	text := ""
+	for _, content := range result.Content {
+		if tc, ok := mcp.AsTextContent(content); ok {
+			text += tc.Text
+		}
+	}
+	if result.IsError {
+		status, resp := http.StatusBadRequest, apiError{Error: text}
+		if coded, ok := result.StructuredContent.(toolErrorResponse); ok {
+			resp.ErrorCode = coded.ErrorCode
+			switch coded.ErrorCode {
+			case task.ErrCodeNotFound:
+				status = http.StatusNotFound
+			case task.ErrCodeRateLimited:
+				status = http.StatusTooManyRequests
+			case task.ErrCodeInternal:
+				status = http.StatusInternalServerError
+			}
+		}
+		writeAPIJSON(w, status, resp)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = io.WriteString(w, text)
+}
+
+// writeAPIJSON writes v as a JSON response with the given status.
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+func newAPITestHandler(t *testing.T) http.Handler {
+	t.Helper()
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"greet": {
+				Description: "Greet",
+				Command:     "echo hello {{.name}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"name": {Type: "string", Required: true, Description: "Who to greet"},
+				},
+			},
+			"dev": {Description: "Dev server", Command: "sleep 10", Type: config.TaskTypeDaemon},
+		},
+	}
+	s := newTestServer(t, manifest)
+	setManager(s, task.NewManager(manifest, process.NewManager()))
+	s.registerTools()
+	mux := http.NewServeMux()
+	s.registerAPI(mux)
+	return mux
+}
+
+func TestAPIRunTask(t *testing.T) {
+	handler := newAPITestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", APIPath+"/tasks/greet/run", strings.NewReader(`{"name": "api"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result struct {
+		Success   bool   `json:"success"`
+		Stdout    string `json:"stdout"`
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !result.Success || !strings.Contains(result.Stdout, "hello api") {
+		t.Fatalf("expected the task output, got %+v", result)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", APIPath+"/sessions/"+result.SessionID+"/logs?lines=10", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "hello api") {
+		t.Errorf("expected the session log, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", APIPath+"/tasks/missing/run", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown task, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", APIPath+"/tasks/greet/run", strings.NewReader("not json")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid body, got %d", rec.Code)
+	}
+}
+
+func TestAPIDaemons(t *testing.T) {
+	handler := newAPITestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", APIPath+"/daemons", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp statusAllResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.Total != 1 || resp.Running != 0 || resp.Daemons[0].Task != "dev" {
+		t.Errorf("expected one stopped dev daemon, got %+v", resp)
+	}
+}
+
+func TestAPISessionLogsInvalidID(t *testing.T) {
+	handler := newAPITestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", APIPath+"/sessions/..%2F..%2Fetc/logs", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a session id that is not a UUID, got %d", rec.Code)
+	}
+}
+
+func TestServerTokenGuardsDashboardAndMetrics(t *testing.T) {
+	handler := withServerToken("registry-token", newAPITestHandler(t))
+
+	tests := []struct {
+		path  string
+		token string
+		want  bool // rejected with 401
+	}{
+		{DashboardPath + "/api/logs", "", true},
+		{DashboardPath + "/api/logs", "wrong-token", true},
+		{DashboardPath + "/api/logs", "registry-token", false},
+		{DashboardPath + "/api/state", "", true},
+		{MetricsPath, "", true},
+		{MetricsPath, "registry-token", false},
+		{DashboardPath, "", false},
+		{APIPath + "/daemons", "", false},
+		{APIPath + "/agents", "", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set(mcputil.ServerTokenHeader, tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if got := rec.Code == http.StatusUnauthorized; got != tt.want {
+			t.Errorf("GET %s with token %q: status %d, want rejected = %v", tt.path, tt.token, rec.Code, tt.want)
+		}
+	}
+}
+
+// TestMCPOpenWithoutServerAuth checks that an MCP client that knows nothing
+// of the registry token can still connect to a server without server.auth.
+func TestMCPOpenWithoutServerAuth(t *testing.T) {
+	s := newTestServer(t, &config.Manifest{Version: "1.0", Tasks: map[string]config.Task{}})
+	mux := http.NewServeMux()
+	mux.Handle(mcputil.EndpointPath, mcpserver.NewStreamableHTTPServer(s.mcpServer))
+	handler := withServerToken("registry-token", mux)
+
+	body := `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26", "capabilities": {}, "clientInfo": {"name": "test", "version": "1"}}}`
+	req := httptest.NewRequest("POST", mcputil.EndpointPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "serverInfo") {
+		t.Errorf("expected an unauthenticated initialize to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"runbookmcp.dev/internal/auth"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/process"
+)
+
+// SetAuthenticator installs a custom authenticator for HTTP mode, replacing
+// the one configured by server.auth. It must be called before ServeHTTP.
+func (s *Server) SetAuthenticator(a auth.Authenticator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authenticator = a
+}
+
+// httpAuthenticator returns the authenticator for HTTP mode: the one set
+// with SetAuthenticator, or else the one configured by server.auth. It
+// returns nil when the server is open.
+func (s *Server) httpAuthenticator() (auth.Authenticator, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.authenticator != nil {
+		return s.authenticator, nil
+	}
+	a, err := auth.FromConfig(s.current().manifest.Server.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("server.auth: %w", err)
+	}
+	return a, nil
+}
+
+// tlsConfig builds the TLS settings for HTTP mode. With a client CA, clients
+// must present a certificate signed by it. It returns nil when TLS is off.
+func tlsConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tc.ClientCAs = pool
+		tc.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tc, nil
+}
+
+// withServerToken requires the registry token on dashboard data and metrics
+// requests, for servers without server.auth, so only clients that can read
+// the project's registry can read session logs from a browser or scraper.
+// MCP and REST API clients stay open, as they were before the token; the
+// dashboard page itself holds no data.
+func withServerToken(token string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get(mcputil.ServerTokenHeader)
+		if needsServerToken(r.URL.Path) && subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			writeAPIJSON(w, http.StatusUnauthorized, apiError{Error: fmt.Sprintf("invalid server token: send the token in %s as %s", process.ServerRegistryFile, mcputil.ServerTokenHeader)})
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// needsServerToken reports whether withServerToken checks requests to path.
+func needsServerToken(path string) bool {
+	return path == MetricsPath || strings.HasPrefix(path, DashboardPath+"/api/")
+}
+
+// withAuth wraps h with a when one is configured.
+func withAuth(a auth.Authenticator, h http.Handler) http.Handler {
+	if a == nil {
+		return h
+	}
+	return auth.Middleware(a, h)
+}
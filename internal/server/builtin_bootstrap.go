@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/task"
+)
+
+// suggestedTask is a task proposed by suggest_tasks.
+type suggestedTask struct {
+	Description string `yaml:"description"`
+	Command     string `yaml:"command,omitempty"`
+	Type        string `yaml:"type"`
+}
+
+// daemonScriptNames are package.json scripts that usually run until stopped.
+var daemonScriptNames = map[string]bool{"dev": true, "start": true, "serve": true, "watch": true}
+
+// suggestTasks inspects dir for common project files and proposes tasks for
+// them. It returns the names of the files it recognized and the suggestions.
+func suggestTasks(dir string) ([]string, map[string]suggestedTask) {
+	var detected []string
+	tasks := make(map[string]suggestedTask)
+
+	add := func(name, description, command, taskType string) {
+		if _, exists := tasks[name]; !exists {
+			tasks[name] = suggestedTask{Description: description, Command: command, Type: taskType}
+		}
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	// Makefile targets come first so projects that wrap their tooling in make
+	// keep using it
+	if exists("Makefile") {
+		detected = append(detected, "Makefile")
+		// An unreadable Makefile still marks a make project; it just
+		// contributes no targets
+		targets, _ := config.ReadMakeTargets(filepath.Join(dir, "Makefile"))
+		for _, target := range targets {
+			description := target.Description
+			if description == "" {
+				description = fmt.Sprintf("Run make %s", target.Name)
+			}
+			add(config.ToolSafeName(target.Name), description, "make "+config.ShellWord(target.Name), "oneshot")
+		}
+	}
+
+	if exists("go.mod") {
+		detected = append(detected, "go.mod")
+		add("build", "Build all Go packages", "go build ./...", "oneshot")
+		add("test", "Run Go tests", "go test ./...", "oneshot")
+		add("vet", "Run go vet", "go vet ./...", "oneshot")
+	}
+
+	if exists("package.json") {
+		detected = append(detected, "package.json")
+		scripts, _ := config.ReadNPMScripts(filepath.Join(dir, "package.json"))
+		for _, script := range scripts {
+			taskType := "oneshot"
+			if daemonScriptNames[script.Name] {
+				taskType = "daemon"
+			}
+			add(config.ToolSafeName(script.Name), fmt.Sprintf("Run npm script '%s'", script.Name), "npm run "+config.ShellWord(script.Name), taskType)
+		}
+	}
+
+	if exists("Cargo.toml") {
+		detected = append(detected, "Cargo.toml")
+		add("build", "Build the crate", "cargo build", "oneshot")
+		add("test", "Run cargo tests", "cargo test", "oneshot")
+		add("lint", "Run clippy", "cargo clippy", "oneshot")
+	}
+
+	if exists("pyproject.toml") || exists("requirements.txt") {
+		if exists("pyproject.toml") {
+			detected = append(detected, "pyproject.toml")
+		} else {
+			detected = append(detected, "requirements.txt")
+		}
+		add("test", "Run Python tests", "pytest", "oneshot")
+	}
+
+	for _, compose := range []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"} {
+		if exists(compose) {
+			detected = append(detected, compose)
+			add("services", "Run docker compose services", "", "compose")
+			break
+		}
+	}
+
+	return detected, tasks
+}
+
+// registerSuggestTasksTool registers the suggest_tasks tool, which proposes a
+// starter configuration based on the files in the working directory.
+func (s *Server) registerSuggestTasksTool() {
+	tool := mcp.Tool{
+		Name: "suggest_tasks",
+		Description: "Inspect the project (Makefile, go.mod, package.json, Cargo.toml, pyproject.toml, docker compose) " +
+			"and suggest a runbook configuration. Review and edit the suggestion, write it to " + dirs.ConfigDir + "/tasks.yaml, then call validate_config and refresh_config.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"directory": map[string]interface{}{
+					"type":        "string",
+					"description": "Project directory to inspect (default: server working directory)",
+				},
+			},
+		},
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		dir := "."
+		if d, ok := args["directory"].(string); ok && d != "" {
+			dir = d
+		}
+
+		detected, tasks := suggestTasks(dir)
+
+		names := make([]string, 0, len(tasks))
+		for name := range tasks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		suggestion := struct {
+			Version string                   `yaml:"version"`
+			Tasks   map[string]suggestedTask `yaml:"tasks"`
+		}{Version: "1.0", Tasks: tasks}
+		yamlData, err := yaml.Marshal(suggestion)
+		if err != nil {
+			return codedToolError(task.ErrCodeInternal, fmt.Sprintf("failed to encode suggestion: %v", err)), nil
+		}
+
+		result := map[string]interface{}{
+			"detected": detected,
+			"tasks":    names,
+			"config":   string(yamlData),
+			"path":     dirs.ConfigDir + "/tasks.yaml",
+		}
+		if len(tasks) == 0 {
+			result["message"] = "No known project files found. Use the init tool for a template to fill in by hand."
+		}
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return marshalError(err), nil
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
+
+// registerValidateConfigTool registers the validate_config tool, which loads
+// a configuration and reports validation errors without applying it.
+func (s *Server) registerValidateConfigTool() {
+	tool := mcp.Tool{
+		Name:        "validate_config",
+		Description: "Load and validate a runbook configuration without applying it. Call refresh_config afterwards to load a valid configuration.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Config file or directory to validate (default: ./" + dirs.ConfigDir + "/)",
+				},
+			},
+		},
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		path := s.configPath
+		if p, ok := args["path"].(string); ok && p != "" {
+			path = p
+		}
+
+		result := map[string]interface{}{}
+		manifest, loaded, err := config.LoadManifest(path)
+		switch {
+		case err != nil:
+			result["valid"] = false
+			result["error"] = err.Error()
+		case !loaded:
+			result["valid"] = false
+			result["error"] = fmt.Sprintf("no configuration found (create %s/ or pass a path)", dirs.ConfigDir)
+		default:
+			result["valid"] = true
+			result["tasks"] = len(manifest.Tasks)
+			result["workflows"] = len(manifest.Workflows)
+			result["prompts"] = len(manifest.Prompts)
+		}
+
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return marshalError(err), nil
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
+
+// gettingStartedContent is the body of the getting_started prompt.
+var gettingStartedContent = strings.TrimSpace(`
+This runbook server has no tasks configured yet. To set it up for this project:
+
+1. Call suggest_tasks to get a starter configuration based on the project's files
+   (or init to write a generic template).
+2. Review the suggestion: keep the tasks that are useful, fix commands, and mark
+   long-running processes (dev servers, watchers) as type: daemon.
+3. Write the result to ` + dirs.ConfigDir + `/tasks.yaml.
+4. Call validate_config and fix any reported errors.
+5. Call refresh_config to load the tasks. They then appear as run_<task> tools
+   (and start_/stop_/status_/logs_<task> for daemons).
+
+The dev-workflow://docs/configuration resource documents every configuration option.
+`)
+
+// registerGettingStartedPrompt registers the getting_started prompt that walks
+// an agent through configuring an empty server.
+func (s *Server) registerGettingStartedPrompt() {
+	if s.withheld[config.CapabilityPrompts] {
+		return
+	}
+	prompt := mcp.Prompt{
+		Name:        gettingStartedPrompt,
+		Description: "How to create a runbook configuration for this project",
+	}
+
+	handler := func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult(
+			"Getting started with runbook",
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(gettingStartedContent)),
+			},
+		), nil
+	}
+
+	s.mcpServer.AddPrompt(prompt, handler)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestSuggestTasks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module example.com/app\n",
+		"Makefile":     "GOFLAGS := -v\n.PHONY: test\ntest: deps ## Run the tests\n\tgo test ./...\ndeps:\n\tgo mod download\ndocs/site:\n\tmkdocs build\n",
+		"package.json": `{"scripts": {"dev": "vite", "lint": "eslint .", "prelint": "tsc", "postinstall": "husky"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	detected, tasks := suggestTasks(dir)
+	if len(detected) != 3 {
+		t.Errorf("expected 3 detected files, got %v", detected)
+	}
+
+	want := map[string]suggestedTask{
+		"test":      {Command: "make test", Type: "oneshot"}, // Makefile wins over go.mod
+		"deps":      {Command: "make deps", Type: "oneshot"},
+		"docs_site": {Command: "make docs/site", Type: "oneshot"},
+		"build":     {Command: "go build ./...", Type: "oneshot"},
+		"dev":       {Command: "npm run dev", Type: "daemon"},
+		"lint":      {Command: "npm run lint", Type: "oneshot"},
+	}
+	for name, w := range want {
+		got, ok := tasks[name]
+		if !ok {
+			t.Errorf("missing suggested task %q", name)
+			continue
+		}
+		if got.Command != w.Command || got.Type != w.Type {
+			t.Errorf("task %q: got %s (%s), want %s (%s)", name, got.Command, got.Type, w.Command, w.Type)
+		}
+	}
+	if _, ok := tasks["GOFLAGS"]; ok {
+		t.Error("variable assignment should not be treated as a make target")
+	}
+	if got := tasks["test"].Description; got != "Run the tests" {
+		t.Errorf("task test description = %q, want the Makefile's ## comment", got)
+	}
+	for _, hook := range []string{"prelint", "postinstall"} {
+		if _, ok := tasks[hook]; ok {
+			t.Errorf("npm hook %q should not be suggested as a task", hook)
+		}
+	}
+}
+
+func TestBootstrapToolsOnEmptyServer(t *testing.T) {
+	chdirToTemp(t)
+	manifest := emptyManifest()
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, false, "test", "")
+
+	for _, name := range []string{"init", "suggest_tasks", "validate_config"} {
+		if s.mcpServer.GetTool(name) == nil {
+			t.Errorf("expected %s tool on empty server", name)
+		}
+	}
+
+	// validate_config reports the missing config
+	tool := s.mcpServer.GetTool("validate_config")
+	var req mcp.CallToolRequest
+	res, err := tool.Handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	text, _ := mcp.AsTextContent(res.Content[0])
+	var payload struct {
+		Valid bool   `json:"valid"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(text.Text), &payload); err != nil {
+		t.Fatalf("unmarshal result %q: %v", text.Text, err)
+	}
+	if payload.Valid || payload.Error == "" {
+		t.Errorf("expected invalid result with error, got %+v", payload)
+	}
+
+	// Once tasks exist, refreshing removes the bootstrap tools
+	if err := os.MkdirAll(dirs.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `version: "1.0"
+tasks:
+  greet:
+    description: "say hi"
+    command: "echo hi"
+    type: oneshot
+`
+	if err := os.WriteFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	for _, name := range []string{"init", "suggest_tasks", "validate_config"} {
+		if s.mcpServer.GetTool(name) != nil {
+			t.Errorf("expected %s tool to be removed once tasks are configured", name)
+		}
+	}
+	if s.mcpServer.GetTool("run_greet") == nil {
+		t.Error("expected run_greet tool after refresh")
+	}
+}
@@ -6,8 +6,9 @@ import (
 	"os"
 	"path/filepath"
 
-	"runbookmcp.dev/internal/dirs"
 	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/task"
 )
 
 const minimalConfigTemplate = `version: "1.0"
@@ -39,9 +40,24 @@ task_groups:
       - build
 `
 
-// registerBuiltInTools registers built-in tools that are always available
+// gettingStartedPrompt is the name of the prompt registered alongside the
+// bootstrap tools when no tasks are configured.
+const gettingStartedPrompt = "getting_started"
+
+// needsBootstrap reports whether the server has nothing useful to expose yet:
+// no config was found, or the config defines no tasks.
+func (s *Server) needsBootstrap() bool {
+	st := s.current()
+	return !st.configLoaded || len(st.manifest.Tasks) == 0
+}
+
+// registerBuiltInTools registers the bootstrap tools and prompt that help an
+// agent create a configuration for an empty server
 func (s *Server) registerBuiltInTools() {
 	s.registerInitTool()
+	s.registerSuggestTasksTool()
+	s.registerValidateConfigTool()
+	s.registerGettingStartedPrompt()
 }
 
 // registerInitTool registers the init tool for creating config files
@@ -82,29 +98,29 @@ func (s *Server) registerInitTool() {
 		// Convert to absolute path for better error messages
 		absPath, err := filepath.Abs(targetPath)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid path: %v", err)), nil
+			return argumentError(fmt.Sprintf("invalid path: %v", err)), nil
 		}
 
 		// Check if file exists
 		if _, err := os.Stat(absPath); err == nil && !overwrite {
-			return mcp.NewToolResultError(fmt.Sprintf("file already exists at %s (use overwrite=true to replace)", absPath)), nil
+			return argumentError(fmt.Sprintf("file already exists at %s (use overwrite=true to replace)", absPath)), nil
 		}
 
 		// Create directory if needed
 		dir := filepath.Dir(absPath)
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to create directory: %v", err)), nil
+			return codedToolError(task.ErrCodeIO, fmt.Sprintf("failed to create directory: %v", err)), nil
 		}
 
 		// Write config file
 		if err := os.WriteFile(absPath, []byte(minimalConfigTemplate), 0644); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to write config file: %v", err)), nil
+			return codedToolError(task.ErrCodeIO, fmt.Sprintf("failed to write config file: %v", err)), nil
 		}
 
 		return mcp.NewToolResultText(fmt.Sprintf(`{
   "success": true,
   "path": %q,
-  "message": "Successfully created config file. Restart the MCP server to load the new configuration."
+  "message": "Successfully created config file. Call refresh_config to load the new configuration."
 }`, absPath)), nil
 	}
 
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/task"
+)
+
+// ConfirmationTokenParam is the tool argument that carries a confirmation
+// token back to the server.
+const ConfirmationTokenParam = "confirmation_token"
+
+// confirmationTTL is how long a confirmation token stays valid.
+const confirmationTTL = 5 * time.Minute
+
+// pendingConfirmation is a call waiting to be confirmed.
+type pendingConfirmation struct {
+	tool    string
+	params  string // JSON of the call's arguments, without the token
+	expires time.Time
+}
+
+// confirmations holds the tokens issued for tasks with requires_confirmation.
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+}
+
+// confirmationResponse is returned instead of running a task that requires
+// confirmation.
+type confirmationResponse struct {
+	Success              bool   `json:"success"`
+	ConfirmationRequired bool   `json:"confirmation_required"`
+	ConfirmationToken    string `json:"confirmation_token"`
+	ExpiresIn            string `json:"expires_in"`
+	Preview              string `json:"preview,omitempty"`
+	Message              string `json:"message"`
+}
+
+// confirmationTokenSchema is the input schema property for the token.
+func confirmationTokenSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Token from a previous call's confirmation_required response, passed once the user has approved the call",
+	}
+}
+
+// confirmCall gates a call to a tool that requires confirmation. It removes
+// the token from params and returns nil when the call carries a valid token
+// issued for the same tool and arguments. Otherwise it issues a new token and
+// returns the result to send instead of running the task. preview describes
+// what would run.
+func (s *Server) confirmCall(toolName string, params map[string]interface{}, preview func() string) *mcp.CallToolResult {
+	token, _ := params[ConfirmationTokenParam].(string)
+	delete(params, ConfirmationTokenParam)
+	key := []byte("{}")
+	if len(params) > 0 {
+		key, _ = json.Marshal(params)
+	}
+
+	c := &s.confirmations
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for t, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, t)
+		}
+	}
+
+	if p, ok := c.pending[token]; ok && p.tool == toolName && p.params == string(key) {
+		delete(c.pending, token)
+		return nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return codedToolError(task.ErrCodeInternal, fmt.Sprintf("failed to create confirmation token: %v", err))
+	}
+	newToken := hex.EncodeToString(buf)
+	if c.pending == nil {
+		c.pending = make(map[string]pendingConfirmation)
+	}
+	c.pending[newToken] = pendingConfirmation{tool: toolName, params: string(key), expires: now.Add(confirmationTTL)}
+
+	message := fmt.Sprintf("%s requires confirmation. Show the preview to the user and, only if they approve, call %s again with the same arguments and %s.", toolName, toolName, ConfirmationTokenParam)
+	if token != "" {
+		message = "The confirmation token is invalid, expired, or was issued for different arguments. " + message
+	}
+	resultJSON, _ := json.Marshal(confirmationResponse{
+		ConfirmationRequired: true,
+		ConfirmationToken:    newToken,
+		ExpiresIn:            confirmationTTL.String(),
+		Preview:              preview(),
+		Message:              message,
+	})
+	return mcp.NewToolResultText(string(resultJSON))
+}
+
+// taskPreview returns the command a task invocation would run.
+func (s *Server) taskPreview(taskName string, params map[string]interface{}) string {
+	st := s.current()
+	resolved, err := st.manager.Resolve(taskName, params)
+	if err != nil {
+		return st.manifest.Tasks[taskName].Command
+	}
+	return resolved.Command
+}
+
+// workflowPreview returns the commands a workflow invocation would run, one
+// step per line.
+func (s *Server) workflowPreview(workflowName string, params map[string]interface{}) string {
+	steps, err := s.current().manager.ResolveWorkflow(workflowName, params)
+	if err != nil {
+		return ""
+	}
+	commands := make([]string, len(steps))
+	for i, step := range steps {
+		commands[i] = step.Command
+	}
+	return strings.Join(commands, "\n")
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+)
+
+func callTextTool(t *testing.T, s *Server, name string, args map[string]interface{}) string {
+	t.Helper()
+	st := s.mcpServer.GetTool(name)
+	if st == nil {
+		t.Fatalf("tool %s not registered", name)
+	}
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	res, err := st.Handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("unexpected tool error: %+v", res.Content)
+	}
+	return res.Content[0].(mcp.TextContent).Text
+}
+
+func TestRequiresConfirmation(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"deploy": {
+				Description:          "Deploy",
+				Command:              "echo deploying {{.env}}",
+				Type:                 config.TaskTypeOneShot,
+				RequiresConfirmation: true,
+				Parameters: map[string]config.Param{
+					"env": {Type: "string", Description: "Target"},
+				},
+			},
+		},
+		Workflows: map[string]config.Workflow{
+			"release": {Description: "Release", Steps: []config.WorkflowStep{{Task: "deploy", Params: map[string]string{"env": "prod"}}}},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	if _, ok := s.mcpServer.GetTool("run_deploy").Tool.InputSchema.Properties[ConfirmationTokenParam]; !ok {
+		t.Error("run_deploy schema should include confirmation_token")
+	}
+
+	// First call returns a token instead of running
+	var pending confirmationResponse
+	text := callTextTool(t, s, "run_deploy", map[string]interface{}{"env": "prod"})
+	if err := json.Unmarshal([]byte(text), &pending); err != nil {
+		t.Fatal(err)
+	}
+	if !pending.ConfirmationRequired || pending.ConfirmationToken == "" {
+		t.Fatalf("expected confirmation_required with token, got %s", text)
+	}
+	if pending.Preview != "echo deploying prod" {
+		t.Errorf("preview = %q", pending.Preview)
+	}
+
+	// A token is bound to the arguments it was issued for
+	text = callTextTool(t, s, "run_deploy", map[string]interface{}{"env": "staging", ConfirmationTokenParam: pending.ConfirmationToken})
+	if _, ok := parseConfirmation(text); !ok {
+		t.Fatalf("token for other arguments should not run the task, got %s", text)
+	}
+
+	// Echoing the token with the same arguments runs the task, once
+	var resp oneShotResponse
+	text = callTextTool(t, s, "run_deploy", map[string]interface{}{"env": "prod", ConfirmationTokenParam: pending.ConfirmationToken})
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || resp.Stdout != "deploying prod" {
+		t.Fatalf("confirmed call should run the task, got %s", text)
+	}
+
+	text = callTextTool(t, s, "run_deploy", map[string]interface{}{"env": "prod", ConfirmationTokenParam: pending.ConfirmationToken})
+	if _, ok := parseConfirmation(text); !ok {
+		t.Errorf("a token must not be reusable, got %s", text)
+	}
+
+	// Workflows with a confirmed step are gated too
+	text = callTextTool(t, s, "run_workflow_release", nil)
+	if p, ok := parseConfirmation(text); !ok || p.Preview != "echo deploying prod" {
+		t.Errorf("workflow should require confirmation, got %s", text)
+	}
+}
+
+func parseConfirmation(text string) (confirmationResponse, bool) {
+	var r confirmationResponse
+	if json.Unmarshal([]byte(text), &r) != nil {
+		return r, false
+	}
+	return r, r.ConfirmationRequired
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/google/uuid"
+	"runbookmcp.dev/internal/logs"
+)
+
+// DashboardPath is where the web dashboard is served in HTTP mode.
+const DashboardPath = "/ui"
+
+// dashboardSessionCount is the number of recent sessions the dashboard lists.
+const dashboardSessionCount = 20
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// dashboardTask is a task as shown on the dashboard. Daemon fields are only
+// set for daemon tasks.
+type dashboardTask struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Running     bool   `json:"running,omitempty"`
+	PID         int    `json:"pid,omitempty"`
+	Uptime      string `json:"uptime,omitempty"`
+	Ports       []int  `json:"ports,omitempty"`
+	SessionID   string `json:"session_id,omitempty"`
+}
+
+// dashboardWorkflow is a workflow as shown on the dashboard.
+type dashboardWorkflow struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Steps       []string `json:"steps"`
+}
+
+// dashboardState is the payload of the dashboard state endpoint.
+type dashboardState struct {
+	Version   string                  `json:"version"`
+	Tasks     []dashboardTask         `json:"tasks"`
+	Workflows []dashboardWorkflow     `json:"workflows"`
+	Sessions  []*logs.SessionMetadata `json:"sessions"`
+}
+
+// dashboardLogs is the payload of the dashboard log endpoint. Lines holds
+// the lines after the requested position; Total is the new position.
+type dashboardLogs struct {
+	Lines []string `json:"lines"`
+	Total int      `json:"total"`
+}
+
+// registerDashboard adds the read-only web dashboard and its JSON endpoints
+// to mux.
+func (s *Server) registerDashboard(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+DashboardPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(dashboardHTML)
+	})
+	mux.HandleFunc("GET "+DashboardPath+"/api/state", s.handleDashboardState)
+	mux.HandleFunc("GET "+DashboardPath+"/api/logs", s.handleDashboardLogs)
+}
+
+// handleDashboardState returns the tasks, workflows, daemon states, and
+// recent sessions.
+func (s *Server) handleDashboardState(w http.ResponseWriter, r *http.Request) {
+	manager := s.Manager()
+	manifest := manager.GetManifest()
+
+	state := dashboardState{
+		Version:   s.version,
+		Tasks:     []dashboardTask{},
+		Workflows: []dashboardWorkflow{},
+		Sessions:  []*logs.SessionMetadata{},
+	}
+
+	for _, name := range sortedKeys(manifest.Tasks) {
+		t := manifest.Tasks[name]
+		if t.Disabled {
+			continue
+		}
+		entry := dashboardTask{Name: name, Description: t.Description, Type: string(t.Type)}
+		if t.Type.IsDaemon() {
+			if status, err := manager.DaemonStatus(name); err == nil {
+				entry.Running = status.Running
+				entry.PID = status.PID
+				entry.Uptime = status.Uptime
+				entry.Ports = status.Ports
+				entry.SessionID = status.SessionID
+			}
+		}
+		state.Tasks = append(state.Tasks, entry)
+	}
+
+	for _, name := range sortedKeys(manifest.Workflows) {
+		wf := manifest.Workflows[name]
+		if wf.Disabled {
+			continue
+		}
+		steps := make([]string, 0, len(wf.Steps))
+		for _, step := range wf.Steps {
+			steps = append(steps, step.Target())
+		}
+		state.Workflows = append(state.Workflows, dashboardWorkflow{Name: name, Description: wf.Description, Steps: steps})
+	}
+
+	sessions, err := logs.ListRecentSessions(dashboardSessionCount)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, session := range sessions {
+		if metadata, err := logs.ReadSessionMetadata(session.SessionID); err == nil {
+			state.Sessions = append(state.Sessions, metadata)
+		}
+	}
+
+	writeDashboardJSON(w, state)
+}
+
+// handleDashboardLogs returns the lines of a session log after line "since",
+// which the dashboard polls to tail a log.
+func (s *Server) handleDashboardLogs(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.URL.Query().Get("session")
+	// Session IDs are UUIDs; rejecting anything else keeps the path inside
+	// the sessions directory
+	if _, err := uuid.Parse(sessionID); err != nil {
+		http.Error(w, "invalid session id", http.StatusBadRequest)
+		return
+	}
+	since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+
+	lines, total, err := logs.ReadSessionLog(sessionID, logs.ReadOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// A log that shrank (e.g. rotated) is sent again from the start
+	if since < 0 || since > total {
+		since = 0
+	}
+	writeDashboardJSON(w, dashboardLogs{Lines: lines[since:], Total: total})
+}
+
+// writeDashboardJSON writes v as a JSON response.
+func writeDashboardJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
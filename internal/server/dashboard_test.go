@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+func newDashboardTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build":  {Description: "Build", Command: "make", Type: config.TaskTypeOneShot},
+			"dev":    {Description: "Dev server", Command: "sleep 10", Type: config.TaskTypeDaemon},
+			"hidden": {Description: "Hidden", Command: "true", Type: config.TaskTypeOneShot, Disabled: true},
+		},
+		Workflows: map[string]config.Workflow{
+			"ci": {Description: "CI", Steps: []config.WorkflowStep{{Task: "build"}}},
+		},
+	}
+	s := newTestServer(t, manifest)
+	setManager(s, task.NewManager(manifest, process.NewManager()))
+	mux := http.NewServeMux()
+	s.registerDashboard(mux)
+	return mux
+}
+
+func TestDashboardPage(t *testing.T) {
+	handler := newDashboardTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", DashboardPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "<title>runbook</title>") {
+		t.Error("expected dashboard HTML")
+	}
+}
+
+func TestDashboardState(t *testing.T) {
+	handler := newDashboardTestHandler(t)
+
+	w, err := logs.NewWriter("11111111-1111-1111-1111-111111111111", &logs.SessionMetadata{
+		TaskName:  "build",
+		TaskType:  "oneshot",
+		StartTime: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	_ = w.Close()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", DashboardPath+"/api/state", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var state dashboardState
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(state.Tasks) != 2 || state.Tasks[0].Name != "build" || state.Tasks[1].Name != "dev" {
+		t.Errorf("expected tasks [build dev], got %+v", state.Tasks)
+	}
+	if state.Tasks[1].Running {
+		t.Error("expected dev daemon to be stopped")
+	}
+	if len(state.Workflows) != 1 || state.Workflows[0].Steps[0] != "build" {
+		t.Errorf("unexpected workflows: %+v", state.Workflows)
+	}
+	if len(state.Sessions) != 1 || state.Sessions[0].TaskName != "build" {
+		t.Errorf("expected one build session, got %+v", state.Sessions)
+	}
+}
+
+func TestDashboardLogs(t *testing.T) {
+	handler := newDashboardTestHandler(t)
+
+	sessionID := "22222222-2222-2222-2222-222222222222"
+	w, err := logs.NewWriter(sessionID, &logs.SessionMetadata{TaskName: "build", StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	_, _ = w.Write([]byte("one\ntwo\nthree\n"))
+	_ = w.Close()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", DashboardPath+"/api/logs?session="+sessionID+"&since=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result dashboardLogs
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if result.Total != 3 || len(result.Lines) != 2 || result.Lines[0] != "two" {
+		t.Errorf("expected lines after the first, got %+v", result)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", DashboardPath+"/api/logs?session=../../etc", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid session id, got %d", rec.Code)
+	}
+}
@@ -33,11 +33,15 @@ func newTestServer(t *testing.T, manifest *config.Manifest) *Server {
 	mgr := task.NewManager(manifest, nil)
 	mcp := mcpserver.NewMCPServer("test", "0.0.1")
 
-	return &Server{
-		manifest:  manifest,
-		manager:   mgr,
-		mcpServer: mcp,
-	}
+	s := &Server{mcpServer: mcp}
+	s.setState(manifest, mgr, false)
+	return s
+}
+
+// setManager replaces the task manager of a test server, keeping its manifest.
+func setManager(s *Server, manager *task.Manager) {
+	st := s.current()
+	s.setState(st.manifest, manager, st.configLoaded)
 }
 
 // ---------------------------------------------------------------------------
@@ -56,7 +60,8 @@ func TestCollectToolNamesExcludesDisabledTasks(t *testing.T) {
 		Prompts:   map[string]config.Prompt{},
 	}
 
-	s := &Server{manifest: manifest}
+	s := &Server{}
+	s.setState(manifest, nil, true)
 	names := s.collectToolNames()
 	nameSet := make(map[string]bool, len(names))
 	for _, n := range names {
@@ -90,7 +95,8 @@ func TestCollectToolNamesExcludesDisabledWorkflows(t *testing.T) {
 		Prompts: map[string]config.Prompt{},
 	}
 
-	s := &Server{manifest: manifest}
+	s := &Server{}
+	s.setState(manifest, nil, true)
 	names := s.collectToolNames()
 	nameSet := make(map[string]bool, len(names))
 	for _, n := range names {
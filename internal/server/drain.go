@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"runbookmcp.dev/internal/task"
+)
+
+// DefaultShutdownGrace is how long runbook serve waits for in-flight tool
+// calls when it shuts down.
+const DefaultShutdownGrace = 30 * time.Second
+
+// ResolveShutdownGrace converts a server.shutdown_grace setting in seconds
+// (0 = default, -1 = don't wait) to a duration.
+func ResolveShutdownGrace(seconds int) time.Duration {
+	switch {
+	case seconds == 0:
+		return DefaultShutdownGrace
+	case seconds < 0:
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// drain tracks in-flight tool calls so shutdown can wait for them to finish.
+type drain struct {
+	mu       sync.Mutex
+	draining bool
+	count    int
+	inflight sync.WaitGroup
+}
+
+// middleware counts tool calls while they run and refuses new ones once
+// draining has started.
+func (d *drain) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !d.begin() {
+			return codedToolError(task.ErrCodeUnavailable, "server is shutting down and not accepting new tool calls"), nil
+		}
+		defer d.end()
+		return next(ctx, req)
+	}
+}
+
+func (d *drain) begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.count++
+	d.inflight.Add(1)
+	return true
+}
+
+func (d *drain) end() {
+	d.mu.Lock()
+	d.count--
+	d.mu.Unlock()
+	d.inflight.Done()
+}
+
+// running returns the number of in-flight tool calls.
+func (d *drain) running() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.count
+}
+
+// wait stops accepting tool calls and waits up to grace for the in-flight
+// ones. It reports whether they all finished.
+func (d *drain) wait(grace time.Duration) bool {
+	d.mu.Lock()
+	d.draining = true
+	idle := d.count == 0
+	d.mu.Unlock()
+	if idle {
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(grace):
+		return false
+	}
+}
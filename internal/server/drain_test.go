@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestDrainWaitsForInFlightCalls(t *testing.T) {
+	var d drain
+	release := make(chan struct{})
+	handler := d.middleware(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		res, _ := handler(context.Background(), mcp.CallToolRequest{})
+		results <- res
+	}()
+	for d.running() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waited := make(chan bool, 1)
+	go func() { waited <- d.wait(5 * time.Second) }()
+
+	// New calls are refused while draining
+	for {
+		d.mu.Lock()
+		draining := d.draining
+		d.mu.Unlock()
+		if draining {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	res, _ := handler(context.Background(), mcp.CallToolRequest{})
+	if coded, ok := res.StructuredContent.(toolErrorResponse); !res.IsError || !ok || coded.ErrorCode != task.ErrCodeUnavailable {
+		t.Errorf("expected a new call to be refused while draining with %s, got %+v", task.ErrCodeUnavailable, res)
+	}
+
+	close(release)
+	if res := <-results; res.IsError {
+		t.Errorf("in-flight call should finish normally, got %+v", res.Content)
+	}
+	if !<-waited {
+		t.Error("wait should report that all calls finished")
+	}
+}
+
+func TestDrainGraceExpires(t *testing.T) {
+	var d drain
+	release := make(chan struct{})
+	defer close(release)
+	handler := d.middleware(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+	go handler(context.Background(), mcp.CallToolRequest{})
+	for d.running() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if d.wait(20 * time.Millisecond) {
+		t.Error("wait should time out while a call is still running")
+	}
+	if d.running() != 1 {
+		t.Errorf("running = %d, want 1", d.running())
+	}
+}
+
+func TestResolveShutdownGrace(t *testing.T) {
+	cases := map[int]time.Duration{0: DefaultShutdownGrace, -1: 0, 5: 5 * time.Second}
+	for seconds, want := range cases {
+		if got := ResolveShutdownGrace(seconds); got != want {
+			t.Errorf("ResolveShutdownGrace(%d) = %s, want %s", seconds, got, want)
+		}
+	}
+}
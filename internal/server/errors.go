@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/task"
+)
+
+// toolErrorResponse is the structured content of a failed tool call.
+type toolErrorResponse struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code"` // One of the task.ErrCode constants
+}
+
+// toolError returns the error result of a call that failed with err,
+// carrying err's error code, or INTERNAL_ERROR when it has none.
+func toolError(err error) *mcp.CallToolResult {
+	code := task.ErrorCode(err)
+	if code == "" {
+		code = task.ErrCodeInternal
+	}
+	return codedToolError(code, err.Error())
+}
+
+// argumentError returns the error result of a call whose arguments are
+// invalid.
+func argumentError(message string) *mcp.CallToolResult {
+	return codedToolError(task.ErrCodeConfig, message)
+}
+
+// readError returns the error result of a call that failed to read a
+// session or log, as NOT_FOUND when it does not exist, CONFIG_ERROR when
+// the read options are invalid and IO_ERROR otherwise.
+func readError(message string, err error) *mcp.CallToolResult {
+	code := task.ErrCodeIO
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = task.ErrCodeNotFound
+	case errors.Is(err, logs.ErrInvalidStream), errors.Is(err, logs.ErrInvalidFilter):
+		code = task.ErrCodeConfig
+	}
+	return codedToolError(code, fmt.Sprintf("%s: %v", message, err))
+}
+
+// marshalError returns the error result of a call whose response could not
+// be encoded.
+func marshalError(err error) *mcp.CallToolResult {
+	return codedToolError(task.ErrCodeInternal, fmt.Sprintf("failed to marshal result: %v", err))
+}
+
+// codedErrorf returns an error with code and a formatted message, for
+// toolError to report.
+func codedErrorf(code string, format string, args ...interface{}) error {
+	return &task.CodedError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// codedToolError returns an error result whose text is message and whose
+// structured content carries code, so agents can branch on the kind of
+// failure. Without a code it is a plain text error.
+func codedToolError(code, message string) *mcp.CallToolResult {
+	if code == "" {
+		return mcp.NewToolResultError(message)
+	}
+	result := mcp.NewToolResultStructured(toolErrorResponse{Error: message, ErrorCode: code}, message)
+	result.IsError = true
+	return result
+}
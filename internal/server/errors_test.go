@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestToolErrorCodes(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"greet": {Description: "Greet", Command: "echo {{.Vars.missing}}", Type: config.TaskTypeOneShot},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	var run oneShotResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_greet", map[string]interface{}{})), &run); err != nil {
+		t.Fatal(err)
+	}
+	if run.Success || run.ErrorCode != task.ErrCodeTemplate {
+		t.Errorf("run_greet error_code = %q (%s), want %s", run.ErrorCode, run.Error, task.ErrCodeTemplate)
+	}
+
+	for _, tc := range []struct {
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"read_session_metadata", map[string]interface{}{"session_id": "00000000-0000-0000-0000-000000000000"}, task.ErrCodeNotFound},
+		{"read_session_metadata", map[string]interface{}{}, task.ErrCodeConfig},
+		{"task_status", map[string]interface{}{"session_id": "00000000-0000-0000-0000-000000000000"}, task.ErrCodeNotFound},
+	} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = tc.args
+		res, err := s.mcpServer.GetTool(tc.tool).Handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		coded, ok := res.StructuredContent.(toolErrorResponse)
+		if !res.IsError || !ok || coded.ErrorCode != tc.want {
+			t.Errorf("%s(%v) = %+v, want an error with code %s", tc.tool, tc.args, res, tc.want)
+		}
+	}
+}
+
+// TestEveryToolErrorIsCoded calls every tool with no arguments and with
+// arguments that name nothing that exists, and checks that each failure
+// carries an error code.
+func TestEveryToolErrorIsCoded(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"greet": {
+				Description: "Greet",
+				Command:     "echo hello {{.name}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters:  map[string]config.Param{"name": {Type: "string", Required: true}},
+			},
+			"web": {
+				Description:      "Serve",
+				Command:          "sleep 30",
+				Type:             config.TaskTypeDaemon,
+				WorkingDirectory: "/does-not-exist",
+			},
+		},
+		Workflows: map[string]config.Workflow{
+			"ship": {Description: "Ship", Steps: []config.WorkflowStep{{Task: "greet"}}},
+		},
+		Exec:   config.ExecConfig{Enabled: true},
+		Server: config.ServerConfig{AllowTaskEdits: true},
+	}
+	s := newTestServer(t, manifest)
+	setManager(s, task.NewManager(manifest, process.NewManager()))
+	s.registerBuiltInTools()
+	s.registerRefreshConfigTool()
+	s.registerLintConfigTool()
+	s.registerTools()
+	s.registerSetWorkingDirTool()
+	s.registerRegisterProjectTool()
+	tools := s.mcpServer.ListTools()
+	initial := s.current()
+
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failures := 0
+	for _, name := range names {
+		tool := tools[name]
+		bogus := map[string]interface{}{}
+		for prop, schema := range tool.Tool.InputSchema.Properties {
+			// A background run would outlive the test and its temp dir
+			if prop == RunAsyncParam {
+				continue
+			}
+			switch schema.(map[string]interface{})["type"] {
+			case "number", "integer":
+				bogus[prop] = -1.0
+			case "boolean":
+				bogus[prop] = true
+			case "object":
+				bogus[prop] = map[string]interface{}{"does-not-exist": 1}
+			case "array":
+				bogus[prop] = []interface{}{"does-not-exist"}
+			default:
+				bogus[prop] = "does-not-exist"
+			}
+		}
+		for _, args := range []map[string]interface{}{{}, bogus} {
+			// Tools that reload the config must not hide the manifest from
+			// the tools that run after them
+			s.setState(initial.manifest, initial.manager, initial.configLoaded)
+			req := mcp.CallToolRequest{}
+			req.Params.Name = name
+			req.Params.Arguments = args
+			res, err := tool.Handler(context.Background(), req)
+			if err != nil {
+				t.Errorf("%s(%v) returned a protocol error: %v", name, args, err)
+				continue
+			}
+			if res.IsError {
+				failures++
+				if coded, ok := res.StructuredContent.(toolErrorResponse); !ok || coded.ErrorCode == "" {
+					t.Errorf("%s(%v) failed without an error code: %+v", name, args, res.Content)
+				}
+				continue
+			}
+			// Results that report a failure in their JSON carry the code
+			// there, unless a command ran and its exit code says why
+			var body struct {
+				Success   *bool  `json:"success"`
+				Error     string `json:"error"`
+				ErrorCode string `json:"error_code"`
+				ExitCode  int    `json:"exit_code"`
+			}
+			text, _ := res.Content[0].(mcp.TextContent)
+			if json.Unmarshal([]byte(text.Text), &body) != nil || body.Success == nil || *body.Success || body.Error == "" {
+				continue
+			}
+			failures++
+			if body.ErrorCode == "" && body.ExitCode == 0 {
+				t.Errorf("%s(%v) failed without an error code: %s", name, args, text.Text)
+			}
+		}
+	}
+	if failures < len(names)/2 {
+		t.Errorf("only %d of %d calls failed; the walk is not reaching the error paths", failures, 2*len(names))
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/metrics"
+	"runbookmcp.dev/internal/task"
+)
+
+// ToolDescription is a generated tool's name, description, input schema,
+// and the annotations set from its task's hints.
+type ToolDescription struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	InputSchema mcp.ToolInputSchema `json:"input_schema"`
+	Annotations *mcp.ToolAnnotation `json:"annotations,omitempty"`
+}
+
+// DescribeTools returns the tools a server for manifest exposes, sorted by
+// name. The tools are registered on a throwaway MCP server, so nothing is
+// started or written. Tools only registered in stdio mode are left out.
+func DescribeTools(manifest *config.Manifest) []ToolDescription {
+	s := &Server{
+		metrics:   metrics.NewRegistry(),
+		mcpServer: server.NewMCPServer("export", "0", server.WithToolCapabilities(true)),
+	}
+	s.setState(manifest, task.NewManager(manifest, nil), true)
+	if s.needsBootstrap() {
+		s.registerBuiltInTools()
+	}
+	s.registerRefreshConfigTool()
+	s.registerTools()
+
+	var tools []ToolDescription
+	for name, st := range s.mcpServer.ListTools() {
+		desc := ToolDescription{
+			Name:        name,
+			Description: st.Tool.Description,
+			InputSchema: st.Tool.InputSchema,
+		}
+		if annotations := st.Tool.Annotations; annotations != (mcp.ToolAnnotation{}) {
+			desc.Annotations = &annotations
+		}
+		tools = append(tools, desc)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// ToolsOpenAPI describes the tools of manifest as an OpenAPI 3.1 document.
+// Each tool is a POST operation on /tools/<name> whose request body is the
+// tool's arguments; the paths are descriptive, as the tools themselves are
+// called over MCP with tools/call.
+func ToolsOpenAPI(manifest *config.Manifest, version string) map[string]interface{} {
+	name, advertisedVersion, _ := serverMetadata(manifest, version)
+
+	paths := make(map[string]interface{})
+	for _, tool := range DescribeTools(manifest) {
+		paths["/tools/"+tool.Name] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": tool.Name,
+				"summary":     tool.Description,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": tool.InputSchema},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Tool result, usually a JSON object with a success field",
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       name,
+			"version":     advertisedVersion,
+			"description": "Tools generated from the runbook configuration. Call them over MCP (tools/call) with the request body as arguments.",
+		},
+		"paths": paths,
+	}
+}
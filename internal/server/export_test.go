@@ -0,0 +1,86 @@
+package server
+
+import (
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestDescribeTools(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build": {
+				Description: "Build",
+				Command:     "go build {{.pkg}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"pkg": {Type: "string", Description: "Package", Required: true},
+				},
+			},
+			"old": {Description: "Old", Command: "true", Type: config.TaskTypeOneShot, Disabled: true},
+		},
+	}
+
+	tools := DescribeTools(manifest)
+	var build *ToolDescription
+	for i, tool := range tools {
+		if i > 0 && tools[i-1].Name > tool.Name {
+			t.Errorf("tools not sorted: %s before %s", tools[i-1].Name, tool.Name)
+		}
+		if tool.Name == "run_old" {
+			t.Error("disabled task should not be exported")
+		}
+		if tool.Name == "run_build" {
+			build = &tools[i]
+		}
+	}
+	if build == nil {
+		t.Fatal("run_build not exported")
+	}
+	if build.Description != "Build" {
+		t.Errorf("description = %q, want Build", build.Description)
+	}
+	if _, ok := build.InputSchema.Properties["pkg"]; !ok || len(build.InputSchema.Required) != 1 {
+		t.Errorf("input schema missing required pkg: %+v", build.InputSchema)
+	}
+
+	doc := ToolsOpenAPI(manifest, "1.2.3")
+	if doc["openapi"] != "3.1.0" {
+		t.Errorf("openapi = %v", doc["openapi"])
+	}
+	paths := doc["paths"].(map[string]interface{})
+	if _, ok := paths["/tools/run_build"]; !ok || len(paths) != len(tools) {
+		t.Errorf("expected one path per tool including /tools/run_build, got %d paths", len(paths))
+	}
+	if v := doc["info"].(map[string]interface{})["version"]; v != "1.2.3" {
+		t.Errorf("version = %v, want 1.2.3", v)
+	}
+}
+
+func TestDescribeToolsWithToolNames(t *testing.T) {
+	manifest := &config.Manifest{
+		Version:  "1.0",
+		Defaults: config.Defaults{ToolPrefix: "myproj_"},
+		Tasks: map[string]config.Task{
+			"test": {Description: "Test", Command: "go test", Type: config.TaskTypeOneShot, ToolName: "unit"},
+			"dev":  {Description: "Dev", Command: "npm run dev", Type: config.TaskTypeDaemon},
+		},
+		Workflows: map[string]config.Workflow{
+			"ci": {Description: "CI", Steps: []config.WorkflowStep{{Task: "test"}}},
+		},
+	}
+
+	names := make(map[string]bool)
+	for _, tool := range DescribeTools(manifest) {
+		names[tool.Name] = true
+	}
+	for _, want := range []string{"myproj_run_unit", "myproj_start_dev", "myproj_logs_dev", "myproj_run_workflow_ci", "list_sessions"} {
+		if !names[want] {
+			t.Errorf("tool %s not registered; got %v", want, names)
+		}
+	}
+	if names["run_test"] || names["myproj_run_test"] {
+		t.Error("test should only be registered under its tool_name")
+	}
+}
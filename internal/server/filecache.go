@@ -0,0 +1,78 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+)
+
+// ifNoneMatchArg is the resources/read argument carrying the etag of content
+// the client already has. When it still matches, the read returns no text.
+const ifNoneMatchArg = "if_none_match"
+
+// fileCache holds the rendered content of file-backed prompts and resources,
+// so chatty clients don't re-read and re-render large files on every call.
+// An entry is reused while its file's modification time and size are
+// unchanged. Rendering depends on the manifest, so a refresh clears it.
+type fileCache struct {
+	mu      sync.Mutex
+	entries map[string]fileCacheEntry
+}
+
+// fileCacheEntry is the rendered content of one file.
+type fileCacheEntry struct {
+	modTime time.Time
+	size    int64
+	text    string
+	etag    string
+}
+
+// render returns the content of the file at path rendered with render, and
+// its etag. The file is only read and rendered again when it changed since
+// the last call.
+func (c *fileCache) render(path string, render func(raw string) (string, error)) (string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.text, entry.etag, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	text, err := render(string(data))
+	if err != nil {
+		return "", "", err
+	}
+	entry = fileCacheEntry{modTime: info.ModTime(), size: info.Size(), text: text, etag: contentETag(text)}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]fileCacheEntry)
+	}
+	c.entries[path] = entry
+	c.mu.Unlock()
+	return entry.text, entry.etag, nil
+}
+
+// clear drops all cached content.
+func (c *fileCache) clear() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// contentETag returns an etag identifying rendered content.
+func contentETag(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestFileCacheRender(t *testing.T) {
+	path := t.TempDir() + "/doc.md"
+	if err := os.WriteFile(path, []byte("one"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var c fileCache
+	renders := 0
+	upper := func(raw string) (string, error) {
+		renders++
+		return raw + "!", nil
+	}
+
+	text, etag, err := c.render(path, upper)
+	if err != nil || text != "one!" || etag == "" {
+		t.Fatalf("first render: %q %q %v", text, etag, err)
+	}
+	if again, againTag, _ := c.render(path, upper); again != text || againTag != etag || renders != 1 {
+		t.Errorf("expected a cached render, got %q %q after %d renders", again, againTag, renders)
+	}
+
+	// A changed file is read again
+	if err := os.WriteFile(path, []byte("two"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if text, newTag, _ := c.render(path, upper); text != "two!" || newTag == etag || renders != 2 {
+		t.Errorf("expected a fresh render, got %q %q after %d renders", text, newTag, renders)
+	}
+
+	c.clear()
+	c.render(path, upper)
+	if renders != 3 {
+		t.Errorf("expected clear to drop the entry, got %d renders", renders)
+	}
+
+	if _, _, err := c.render(path+".missing", upper); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestResourceConditionalRead(t *testing.T) {
+	dir := chdirToTemp(t)
+	if err := os.WriteFile(dir+"/guide.md", []byte("Run {{.Tasks.test.Run}}."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test": {Description: "Run tests", Command: "go test ./...", Type: config.TaskTypeOneShot},
+		},
+		Resources: map[string]config.Resource{
+			"guide": {Description: "Guide", File: "guide.md"},
+		},
+	}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.0.0", "")
+
+	read := func(args string) (string, map[string]any) {
+		t.Helper()
+		resp := s.mcpServer.HandleMessage(context.Background(), json.RawMessage(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"runbook://custom/guide","arguments":%s}}`, args)))
+		out, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			Result struct {
+				Contents []struct {
+					Text string         `json:"text"`
+					Meta map[string]any `json:"_meta"`
+				} `json:"contents"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(out, &decoded); err != nil || len(decoded.Result.Contents) != 1 {
+			t.Fatalf("invalid resources/read response %s: %v", out, err)
+		}
+		return decoded.Result.Contents[0].Text, decoded.Result.Contents[0].Meta
+	}
+
+	text, meta := read(`{}`)
+	etag, _ := meta["etag"].(string)
+	if text != "Run run_test." || etag == "" {
+		t.Fatalf("unexpected read: %q %v", text, meta)
+	}
+
+	text, meta = read(fmt.Sprintf(`{"if_none_match":%q}`, etag))
+	if text != "" || meta["not_modified"] != true {
+		t.Errorf("expected a not-modified read, got %q %v", text, meta)
+	}
+
+	text, _ = read(`{"if_none_match":"stale"}`)
+	if text != "Run run_test." {
+		t.Errorf("expected content for a stale etag, got %q", text)
+	}
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+
+	"runbookmcp.dev/internal/process"
+)
+
+// handleHandshake answers the handshake the CLI and stdio proxy use to check
+// that this server wrote the registry: it returns the proof for the nonce
+// query parameter, computed with the registry token.
+func handleHandshake(token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		nonce := r.URL.Query().Get("nonce")
+		if nonce == "" || len(nonce) > 256 {
+			writeAPIJSON(w, http.StatusBadRequest, apiError{Error: "nonce is required (at most 256 characters)"})
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, process.HandshakeResponse{Proof: process.HandshakeProof(token, nonce)})
+	}
+}
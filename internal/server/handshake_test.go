@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"runbookmcp.dev/internal/process"
+)
+
+func TestHandshake(t *testing.T) {
+	ts := httptest.NewServer(handleHandshake("registry-token"))
+	defer ts.Close()
+
+	if err := process.VerifyServer(&process.ServerFileData{Addr: ts.URL, PID: 1, Token: "registry-token"}, nil); err != nil {
+		t.Errorf("expected the handshake to verify, got %v", err)
+	}
+	if err := process.VerifyServer(&process.ServerFileData{Addr: ts.URL, PID: 1, Token: "other-token"}, nil); err == nil {
+		t.Error("expected a different token to fail verification")
+	}
+
+	rec := httptest.NewRecorder()
+	handleHandshake("registry-token")(rec, httptest.NewRequest("GET", process.HandshakePath, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a nonce, got %d", rec.Code)
+	}
+}
+
+func TestHandshakeOverTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(handleHandshake("registry-token"))
+	defer ts.Close()
+
+	data := &process.ServerFileData{Addr: ts.URL, PID: 1, Token: "registry-token"}
+	if err := process.VerifyServer(data, nil); err == nil {
+		t.Error("expected the default client to reject the test server's certificate")
+	}
+	if err := process.VerifyServer(data, ts.Client()); err != nil {
+		t.Errorf("expected the handshake to verify with a client that trusts the server, got %v", err)
+	}
+}
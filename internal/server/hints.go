@@ -0,0 +1,68 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// usageHint is attached to a result that shows the agent is missing a step,
+// such as asking for the status of a daemon it never started. It names the
+// tool to call next and the parameters that tool requires, so agents recover
+// without guessing.
+type usageHint struct {
+	Message        string   `json:"message"`
+	Tool           string   `json:"tool"`
+	RequiredParams []string `json:"required_params,omitempty"`
+}
+
+// requiredParams returns the names of params that are required and have no
+// default, sorted.
+func requiredParams(params map[string]config.Param) []string {
+	var names []string
+	for name, param := range params {
+		if param.Required && param.Default == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// neverStartedHint returns a hint pointing at the start tool of a daemon that
+// is not running and has no sessions or lifecycle events, or nil otherwise.
+func neverStartedHint(manifest *config.Manifest, taskName string, running bool, events []logs.DaemonEvent) *usageHint {
+	if running || len(events) > 0 {
+		return nil
+	}
+	if _, err := logs.GetLatestSessionID(taskName); err == nil {
+		return nil
+	}
+	tool := manifest.ToolName(config.ToolStart, taskName)
+	return &usageHint{
+		Message:        fmt.Sprintf("Daemon '%s' has never been started. Call %s to start it.", taskName, tool),
+		Tool:           tool,
+		RequiredParams: requiredParams(manifest.Tasks[taskName].Parameters),
+	}
+}
+
+// missingParamsHint returns a hint listing the required parameters a call to
+// toolName left out, or nil if it gave them all.
+func missingParamsHint(toolName string, params map[string]config.Param, args map[string]interface{}) *usageHint {
+	var missing []string
+	for _, name := range requiredParams(params) {
+		if value, ok := args[name]; !ok || value == nil || value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &usageHint{
+		Message:        fmt.Sprintf("The call is missing required parameters. Call %s again with them.", toolName),
+		Tool:           toolName,
+		RequiredParams: missing,
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestNeverStartedHint(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"dev": {Description: "Dev server", Command: "sleep 30", Type: config.TaskTypeDaemon, Parameters: map[string]config.Param{
+				"port": {Type: "string", Required: true},
+				"host": {Type: "string"},
+			}},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.processManager = process.NewManager()
+	setManager(s, task.NewManager(manifest, s.processManager))
+	t.Cleanup(func() { _ = s.processManager.StopAll() })
+	s.registerTools()
+
+	var status daemonStatusResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "status_dev", map[string]interface{}{})), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Hint == nil || status.Hint.Tool != "start_dev" || len(status.Hint.RequiredParams) != 1 || status.Hint.RequiredParams[0] != "port" {
+		t.Fatalf("expected a hint to call start_dev with port, got %+v", status.Hint)
+	}
+
+	callTextTool(t, s, "start_dev", map[string]interface{}{"port": "8080"})
+	status = daemonStatusResponse{}
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "status_dev", map[string]interface{}{})), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Hint != nil {
+		t.Errorf("expected no hint once the daemon was started, got %+v", status.Hint)
+	}
+}
+
+func TestMissingParamsHint(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"deploy": {Description: "Deploy", Command: "echo {{.env}}", Type: config.TaskTypeOneShot, Parameters: map[string]config.Param{
+				"env": {Type: "string", Required: true},
+			}},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	var resp oneShotResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_deploy", map[string]interface{}{})), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || resp.Hint == nil || resp.Hint.Tool != "run_deploy" || len(resp.Hint.RequiredParams) != 1 {
+		t.Fatalf("expected a hint listing env, got %+v", resp.Hint)
+	}
+
+	resp = oneShotResponse{}
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_deploy", map[string]interface{}{"env": "prod"})), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success || resp.Hint != nil {
+		t.Errorf("expected success without a hint, got %+v", resp)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBudget is the soft budget for a run_ tool call when neither
+// the task nor the manifest defaults set latency_budget.
+const DefaultLatencyBudget = 30 * time.Second
+
+// latencyStats summarizes the observed latency of one tool.
+type latencyStats struct {
+	Calls int
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Average returns the mean latency over all recorded calls.
+func (l latencyStats) Average() time.Duration {
+	if l.Calls == 0 {
+		return 0
+	}
+	return l.Total / time.Duration(l.Calls)
+}
+
+// latencyTracker records per-tool execution latency for the life of the server.
+type latencyTracker struct {
+	mu    sync.Mutex
+	stats map[string]latencyStats
+}
+
+// record adds a call to the tool's statistics and returns the updated totals.
+func (t *latencyTracker) record(toolName string, d time.Duration) latencyStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stats == nil {
+		t.stats = make(map[string]latencyStats)
+	}
+	stats := t.stats[toolName]
+	stats.Calls++
+	stats.Total += d
+	if d > stats.Max {
+		stats.Max = d
+	}
+	t.stats[toolName] = stats
+	return stats
+}
+
+// latencyHint is attached to a run_ result that exceeded its latency budget.
+// It steers agents to the non-blocking paths instead of waiting on slow calls.
+type latencyHint struct {
+	Budget     string `json:"budget"`
+	Duration   string `json:"duration"`
+	Average    string `json:"average"`
+	Calls      int    `json:"calls"`
+	Suggestion string `json:"suggestion"`
+}
+
+// resolveLatencyBudget converts a latency_budget setting in seconds into a
+// duration: 0 means DefaultLatencyBudget, negative disables the budget.
+func resolveLatencyBudget(seconds int) time.Duration {
+	switch {
+	case seconds < 0:
+		return 0
+	case seconds == 0:
+		return DefaultLatencyBudget
+	default:
+		return time.Duration(seconds) * time.Second
+	}
+}
+
+// checkLatency records a call and returns a hint when it exceeded budget,
+// or nil when the call was within budget or the budget is disabled.
+func (s *Server) checkLatency(toolName string, d, budget time.Duration, sessionID string) *latencyHint {
+	stats := s.latency.record(toolName, d)
+	if budget <= 0 || d <= budget {
+		return nil
+	}
+
+	follow := "read_session_log"
+	if sessionID != "" {
+		follow = fmt.Sprintf("read_session_log with session_id %q", sessionID)
+	}
+	return &latencyHint{
+		Budget:   budget.String(),
+		Duration: d.Round(time.Millisecond).String(),
+		Average:  stats.Average().Round(time.Millisecond).String(),
+		Calls:    stats.Calls,
+		Suggestion: fmt.Sprintf("%s exceeded its %s latency budget. Instead of blocking on long runs, "+
+			"define the work as a daemon task and use its start_/logs_ tools to follow progress, "+
+			"or check results afterwards with %s.", toolName, budget, follow),
+	}
+}
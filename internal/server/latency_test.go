@@ -0,0 +1,57 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveLatencyBudget(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    time.Duration
+	}{
+		{0, DefaultLatencyBudget},
+		{-1, 0},
+		{5, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := resolveLatencyBudget(tt.seconds); got != tt.want {
+			t.Errorf("resolveLatencyBudget(%d) = %v, want %v", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestCheckLatency(t *testing.T) {
+	s := &Server{}
+
+	if hint := s.checkLatency("run_test", time.Second, 10*time.Second, "abc"); hint != nil {
+		t.Errorf("expected no hint within budget, got %+v", hint)
+	}
+	if hint := s.checkLatency("run_test", time.Minute, 0, "abc"); hint != nil {
+		t.Errorf("expected no hint with budget disabled, got %+v", hint)
+	}
+
+	hint := s.checkLatency("run_test", 20*time.Second, 10*time.Second, "abc")
+	if hint == nil {
+		t.Fatal("expected hint over budget")
+	}
+	if hint.Calls != 3 {
+		t.Errorf("expected 3 recorded calls, got %d", hint.Calls)
+	}
+	if hint.Budget != "10s" || hint.Duration != "20s" {
+		t.Errorf("unexpected budget/duration: %+v", hint)
+	}
+	// (1s + 1m + 20s) / 3
+	if hint.Average != "27s" {
+		t.Errorf("expected average 27s, got %s", hint.Average)
+	}
+	if !strings.Contains(hint.Suggestion, `read_session_log with session_id "abc"`) {
+		t.Errorf("expected suggestion to reference the session, got %q", hint.Suggestion)
+	}
+
+	// Latency is tracked per tool
+	if hint := s.checkLatency("run_build", 20*time.Second, 10*time.Second, ""); hint == nil || hint.Calls != 1 {
+		t.Errorf("expected separate stats for run_build, got %+v", hint)
+	}
+}
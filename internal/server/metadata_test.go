@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestServerMetadataDefaults(t *testing.T) {
+	name, version, instructions := serverMetadata(emptyManifest(), "1.2.0")
+	if name != "runbook" || version != "1.2.0" || instructions != "" {
+		t.Errorf("unexpected defaults: %q %q %q", name, version, instructions)
+	}
+}
+
+func TestServerMetadataInitialize(t *testing.T) {
+	chdirToTemp(t)
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test": {Description: "Run tests", Command: "go test ./...", Type: config.TaskTypeOneShot},
+		},
+		Server: config.ServerConfig{
+			Name:          "acme",
+			VersionSuffix: "+acme",
+			Instructions:  "Run {{.Tasks.test.Run}} before committing.",
+		},
+	}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.2.0", "")
+
+	resp := s.mcpServer.HandleMessage(context.Background(), json.RawMessage(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1"},"capabilities":{}}}`))
+	out, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Result struct {
+			ServerInfo struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"serverInfo"`
+			Instructions string `json:"instructions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("invalid initialize response %s: %v", out, err)
+	}
+	if decoded.Result.ServerInfo.Name != "acme" || decoded.Result.ServerInfo.Version != "1.2.0+acme" {
+		t.Errorf("unexpected server info: %+v", decoded.Result.ServerInfo)
+	}
+	if !strings.Contains(decoded.Result.Instructions, "Run run_test before committing.") {
+		t.Errorf("expected resolved instructions, got %q", decoded.Result.Instructions)
+	}
+}
+
+func TestServerMetadataInvalidTemplate(t *testing.T) {
+	manifest := emptyManifest()
+	manifest.Server.Instructions = "Use {{.Broken"
+	if _, _, instructions := serverMetadata(manifest, "1.0.0"); instructions != "Use {{.Broken" {
+		t.Errorf("expected raw instructions on template error, got %q", instructions)
+	}
+}
+
+func TestServerCapabilities(t *testing.T) {
+	chdirToTemp(t)
+	initialize := func(capabilities []string) map[string]json.RawMessage {
+		t.Helper()
+		manifest := &config.Manifest{
+			Version: "1.0",
+			Tasks: map[string]config.Task{
+				"test": {Description: "Run tests", Command: "go test ./...", Type: config.TaskTypeOneShot},
+			},
+			Prompts:   map[string]config.Prompt{"guide": {Description: "Guide", Content: "Run tests"}},
+			Resources: map[string]config.Resource{"notes": {Description: "Notes", Content: "Notes"}},
+			Server:    config.ServerConfig{Capabilities: capabilities},
+		}
+		s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.2.0", "")
+		resp := s.mcpServer.HandleMessage(context.Background(), json.RawMessage(
+			`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1"},"capabilities":{}}}`))
+		out, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			Result struct {
+				Capabilities map[string]json.RawMessage `json:"capabilities"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(out, &decoded); err != nil {
+			t.Fatalf("invalid initialize response %s: %v", out, err)
+		}
+		return decoded.Result.Capabilities
+	}
+
+	got := initialize(nil)
+	for _, name := range []string{"tools", "prompts", "resources"} {
+		if _, ok := got[name]; !ok {
+			t.Errorf("expected %s to be advertised by default, got %v", name, got)
+		}
+	}
+	if _, ok := got["logging"]; ok {
+		t.Error("expected logging to be off by default")
+	}
+
+	got = initialize([]string{config.CapabilityTools, config.CapabilityLogging})
+	if _, ok := got["tools"]; !ok {
+		t.Errorf("expected tools to be advertised, got %v", got)
+	}
+	if _, ok := got["logging"]; !ok {
+		t.Errorf("expected logging to be advertised, got %v", got)
+	}
+	for _, name := range []string{"prompts", "resources"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("expected %s to be withheld, got %v", name, got)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+)
+
+// MetricsPath is where Prometheus metrics are served in HTTP mode.
+const MetricsPath = "/metrics"
+
+// handleMetrics serves the metrics registry in the Prometheus text format,
+// with the current state of every configured daemon.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	manifest := s.Manager().GetManifest()
+
+	active := make(map[string]bool)
+	if s.processManager != nil {
+		for name, t := range manifest.Tasks {
+			if !t.Type.IsDaemon() || t.Disabled {
+				continue
+			}
+			running, _, err := s.processManager.Status(name)
+			active[name] = err == nil && running
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := s.metrics.Write(w, active); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/metrics"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build": {Description: "Build", Command: "true", Type: config.TaskTypeOneShot},
+			"dev":   {Description: "Dev server", Command: "sleep 10", Type: config.TaskTypeDaemon},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.processManager = process.NewManager()
+	s.metrics = metrics.NewRegistry()
+	setManager(s, task.NewManager(manifest, s.processManager))
+	s.current().manager.SetObserver(s.metrics)
+
+	if _, err := s.current().manager.ExecuteOneShot("build", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleMetrics(rec, httptest.NewRequest("GET", MetricsPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`runbook_task_executions_total{task="build"} 1`,
+		`runbook_daemon_up{task="dev"} 0`,
+		"runbook_daemons_active 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+)
+
+func TestRunToolPreset(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test": {
+				Description: "Test",
+				Command:     "echo go test {{.flags}} {{.path}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"flags": {Type: "string", Description: "Flags"},
+					"path":  {Type: "string", Description: "Packages"},
+				},
+				ParameterPresets: map[string]map[string]string{
+					"quick": {"flags": "-short", "path": "./internal/..."},
+				},
+			},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	schema, ok := s.mcpServer.GetTool("run_test").Tool.InputSchema.Properties[config.PresetParam].(map[string]interface{})
+	if !ok {
+		t.Fatal("run_test schema should include preset")
+	}
+	if enum, _ := schema["enum"].([]string); len(enum) != 1 || enum[0] != "quick" {
+		t.Errorf("expected preset enum [quick], got %v", schema["enum"])
+	}
+
+	var resp oneShotResponse
+	text := callTextTool(t, s, "run_test", map[string]interface{}{config.PresetParam: "quick", "path": "./cmd/..."})
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stdout != "go test -short ./cmd/..." {
+		t.Errorf("expected preset values with the explicit path, got %q", resp.Stdout)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{config.PresetParam: "slow"}
+	res, err := s.mcpServer.GetTool("run_test").Handler(t.Context(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError {
+		t.Errorf("expected an error for an unknown preset, got %+v", res.Content)
+	}
+}
@@ -3,15 +3,23 @@ package server
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
 
+	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/template"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// promptLocaleArg is the prompt argument clients use to pick a locale for
+// prompts with content_by_locale.
+const promptLocaleArg = "locale"
+
 // registerPrompts registers all prompts as MCP prompts
 func (s *Server) registerPrompts() {
-	for promptName, promptDef := range s.manifest.Prompts {
+	if s.withheld[config.CapabilityPrompts] {
+		return
+	}
+	for promptName, promptDef := range s.current().manifest.Prompts {
 		if promptDef.Disabled {
 			continue
 		}
@@ -24,23 +32,17 @@ func (s *Server) registerPrompts() {
 			Name:        name,
 			Description: def.Description,
 		}
+		if len(def.ContentByLocale) > 0 {
+			prompt.Arguments = []mcp.PromptArgument{{
+				Name:        promptLocaleArg,
+				Description: fmt.Sprintf("Language of the prompt, e.g. %s (default: the server's locale)", strings.Join(sortedKeys(def.ContentByLocale), ", ")),
+			}}
+		}
 
 		handler := func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-			var rawContent string
-			if def.File != "" {
-				data, err := os.ReadFile(def.File)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read prompt file %s: %w", def.File, err)
-				}
-				rawContent = string(data)
-			} else {
-				rawContent = def.Content
-			}
-
-			// Resolve template variables in prompt content
-			resolvedContent, err := template.ResolvePromptTemplate(rawContent, s.manifest.Tasks)
+			resolvedContent, err := s.promptContent(def, req.Params.Arguments[promptLocaleArg], s.current().manifest.Server.Locale)
 			if err != nil {
-				return nil, fmt.Errorf("failed to resolve prompt template: %w", err)
+				return nil, err
 			}
 
 			return &mcp.GetPromptResult{
@@ -60,3 +62,51 @@ func (s *Server) registerPrompts() {
 		s.mcpServer.AddPrompt(prompt, handler)
 	}
 }
+
+// promptContent returns the rendered content of a prompt for the first of
+// locales that it has content for, falling back to its content or file.
+// File content is rendered through the server's file cache.
+func (s *Server) promptContent(def config.Prompt, locales ...string) (string, error) {
+	manifest := s.current().manifest
+	resolve := func(raw string) (string, error) {
+		resolved, err := template.ResolveManifestTemplate(raw, manifest)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve prompt template: %w", err)
+		}
+		return resolved, nil
+	}
+
+	for _, locale := range locales {
+		if content, ok := localizedContent(def.ContentByLocale, locale); ok {
+			return resolve(content)
+		}
+	}
+
+	if def.File != "" {
+		text, _, err := s.files.render(def.File, resolve)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file %s: %w", def.File, err)
+		}
+		return text, nil
+	}
+	return resolve(def.Content)
+}
+
+// localizedContent looks up locale in byLocale, ignoring case and "_" versus
+// "-", and falls back from a regional locale ("pt-BR") to its language ("pt").
+func localizedContent(byLocale map[string]string, locale string) (string, bool) {
+	normalize := func(l string) string { return strings.ToLower(strings.ReplaceAll(l, "_", "-")) }
+	locale = normalize(locale)
+	if locale == "" {
+		return "", false
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	for _, want := range []string{locale, language} {
+		for key, content := range byLocale {
+			if normalize(key) == want {
+				return content, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestLocalizedContent(t *testing.T) {
+	byLocale := map[string]string{"de": "Hallo", "pt_BR": "Olá", "fr-CA": "Bonjour"}
+
+	tests := []struct {
+		locale string
+		want   string
+		found  bool
+	}{
+		{"de", "Hallo", true},
+		{"de-AT", "Hallo", true},
+		{"pt-br", "Olá", true},
+		{"fr-CA", "Bonjour", true},
+		{"fr", "", false},
+		{"es", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, found := localizedContent(byLocale, tt.locale)
+		if got != tt.want || found != tt.found {
+			t.Errorf("localizedContent(%q) = %q, %v, want %q, %v", tt.locale, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestLocalizedPrompt(t *testing.T) {
+	chdirToTemp(t)
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test": {Description: "Run tests", Command: "go test ./...", Type: config.TaskTypeOneShot},
+		},
+		Prompts: map[string]config.Prompt{
+			"guide": {
+				Description: "Guide",
+				Content:     "Run {{.Tasks.test.Run}}.",
+				ContentByLocale: map[string]string{
+					"de": "Führe {{.Tasks.test.Run}} aus.",
+					"ja": "{{.Tasks.test.Run}} を実行してください。",
+				},
+			},
+		},
+		Server: config.ServerConfig{Locale: "ja"},
+	}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.0.0", "")
+
+	getPrompt := func(args string) string {
+		t.Helper()
+		resp := s.mcpServer.HandleMessage(context.Background(), json.RawMessage(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"guide","arguments":%s}}`, args)))
+		out, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded struct {
+			Result struct {
+				Messages []struct {
+					Content struct {
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"messages"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(out, &decoded); err != nil || len(decoded.Result.Messages) != 1 {
+			t.Fatalf("invalid prompts/get response %s: %v", out, err)
+		}
+		return decoded.Result.Messages[0].Content.Text
+	}
+
+	if got := getPrompt(`{"locale":"de-DE"}`); got != "Führe run_test aus." {
+		t.Errorf("client locale: got %q", got)
+	}
+	if got := getPrompt(`{}`); got != "run_test を実行してください。" {
+		t.Errorf("server locale: got %q", got)
+	}
+
+	manifest.Server.Locale = ""
+	if got := getPrompt(`{"locale":"es"}`); got != "Run run_test." {
+		t.Errorf("fallback: got %q", got)
+	}
+}
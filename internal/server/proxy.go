@@ -4,24 +4,60 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 	"runbookmcp.dev/internal/mcputil"
 )
 
+// DefaultProxyReconnectTimeout is how long the stdio proxy keeps trying to
+// reach a server that stopped responding.
+const DefaultProxyReconnectTimeout = 30 * time.Second
+
+// Reconnect backoff bounds.
+const (
+	proxyBackoffMin = 250 * time.Millisecond
+	proxyBackoffMax = 5 * time.Second
+)
+
+// ProxyOptions controls how the stdio proxy recovers when its HTTP server
+// goes away mid-session.
+type ProxyOptions struct {
+	// ReconnectTimeout bounds reconnect attempts. Zero means
+	// DefaultProxyReconnectTimeout.
+	ReconnectTimeout time.Duration
+	// Fallback builds an in-process server that serves the rest of the
+	// session when the HTTP server does not come back. Nil makes the proxy
+	// exit with an error instead.
+	Fallback func() (*Server, error)
+	// Verify, if set, checks that the server is the one the proxy should
+	// talk to before every connect, including reconnects, e.g. with the
+	// registry handshake. A failed check counts as a failed connect.
+	Verify func() error
+	// Client holds the credentials and TLS settings used to connect to the
+	// server.
+	Client mcputil.ClientConfig
+	// ServerToken, if set, returns the registry token sent on every
+	// connect, so a reconnect to a replaced server sends its new one.
+	ServerToken func() string
+}
+
 // ServeStdioProxy forwards stdin MCP traffic to a running HTTP MCP server and
 // writes responses to stdout. It allows stdio MCP clients (e.g. Claude Desktop)
 // to transparently use a shared running HTTP server instance.
-func ServeStdioProxy(addr string) error {
-	return serveStdioProxy(addr, os.Stdin, os.Stdout)
+func ServeStdioProxy(addr string, opts ProxyOptions) error {
+	return serveStdioProxy(addr, os.Stdin, os.Stdout, opts)
 }
 
 // lineResult carries one read result from the stdin reader goroutine.
@@ -44,37 +80,260 @@ func readLines(r *bufio.Reader, ch chan<- lineResult) {
 	}
 }
 
-// serveStdioProxy is the testable core of ServeStdioProxy. Accepting in/out
-// instead of os.Stdin/Stdout allows tests to pass pipe readers/writers.
-func serveStdioProxy(addr string, in io.Reader, out io.Writer) error {
-	trans, err := transport.NewStreamableHTTP(mcputil.Endpoint(addr))
+// stdioProxy is the state of one proxied stdio session. It remembers the
+// client's handshake so it can be replayed on a new connection or against
+// the local fallback server.
+type stdioProxy struct {
+	addr  string
+	opts  ProxyOptions
+	out   io.Writer
+	trans *transport.StreamableHTTP
+
+	writeMu     sync.Mutex
+	initRequest []byte // The client's initialize request, as sent
+	initialized bool   // The client sent notifications/initialized
+
+	local    *Server         // Set once the proxy has fallen back
+	localCtx context.Context // Carries the fallback server's session
+}
+
+func (p *stdioProxy) writeMsg(v any) {
+	b, err := json.Marshal(v)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP transport: %w", err)
+		return
 	}
+	p.writeMu.Lock()
+	fmt.Fprintf(p.out, "%s\n", b)
+	p.writeMu.Unlock()
+}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+func (p *stdioProxy) writeError(id mcp.RequestId, code int, message string) {
+	msg := map[string]any{
+		"jsonrpc": "2.0",
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+		},
+	}
+	if !id.IsNil() {
+		msg["id"] = id
+	}
+	p.writeMsg(msg)
+}
 
+// connect opens a transport to the server that forwards server-to-client
+// notifications to the output stream.
+func (p *stdioProxy) connect(ctx context.Context) (*transport.StreamableHTTP, error) {
+	if p.opts.Verify != nil {
+		if err := p.opts.Verify(); err != nil {
+			return nil, err
+		}
+	}
+	cfg := p.opts.Client
+	if p.opts.ServerToken != nil {
+		cfg.ServerToken = p.opts.ServerToken()
+	}
+	httpClient, err := cfg.HTTPClient(0)
+	if err != nil {
+		return nil, err
+	}
+	trans, err := transport.NewStreamableHTTP(mcputil.Endpoint(p.addr),
+		transport.WithHTTPHeaders(cfg.Headers()), transport.WithHTTPBasicClient(httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP transport: %w", err)
+	}
 	if err := trans.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start HTTP transport: %w", err)
+		return nil, fmt.Errorf("failed to start HTTP transport: %w", err)
 	}
-	defer trans.Close()
+	trans.SetNotificationHandler(func(notif mcp.JSONRPCNotification) {
+		p.writeMsg(notif)
+	})
+	return trans, nil
+}
 
-	var writeMu sync.Mutex
-	writeMsg := func(v any) {
-		b, err := json.Marshal(v)
-		if err != nil {
-			return
+// reconnect replaces the transport with a new connection, replaying the
+// client's handshake. It retries with backoff until the reconnect timeout.
+func (p *stdioProxy) reconnect(ctx context.Context) error {
+	timeout := p.opts.ReconnectTimeout
+	if timeout <= 0 {
+		timeout = DefaultProxyReconnectTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	backoff := proxyBackoffMin
+
+	var lastErr error
+	for {
+		trans, err := p.connect(ctx)
+		if err == nil {
+			if err = p.handshake(ctx, trans); err == nil {
+				p.trans.Close()
+				p.trans = trans
+				fmt.Fprintf(os.Stderr, "Reconnected to server at %s\n", p.addr)
+				return nil
+			}
+			trans.Close()
 		}
-		writeMu.Lock()
-		fmt.Fprintf(out, "%s\n", b)
-		writeMu.Unlock()
+		lastErr = err
+
+		wait := min(backoff, time.Until(deadline))
+		if wait <= 0 {
+			return fmt.Errorf("server at %s did not come back within %s: %w", p.addr, timeout, lastErr)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, proxyBackoffMax)
 	}
+}
 
-	// Forward server-to-client notifications to the output stream.
-	trans.SetNotificationHandler(func(notif mcp.JSONRPCNotification) {
-		writeMsg(notif)
-	})
+// handshake replays the client's initialize request and initialized
+// notification on trans.
+func (p *stdioProxy) handshake(ctx context.Context, trans *transport.StreamableHTTP) error {
+	if p.initRequest == nil {
+		return nil
+	}
+	var req transport.JSONRPCRequest
+	if err := json.Unmarshal(p.initRequest, &req); err != nil {
+		return err
+	}
+	resp, err := trans.SendRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("initialize failed: %s", resp.Error.Message)
+	}
+	if p.initialized {
+		return trans.SendNotification(ctx, mcp.JSONRPCNotification{
+			JSONRPC:      mcp.JSONRPC_VERSION,
+			Notification: mcp.Notification{Method: "notifications/initialized"},
+		})
+	}
+	return nil
+}
+
+// serverGone reports whether a forwarding error means the connection to the
+// server was lost or the server restarted, rather than a failure of the one
+// request.
+func serverGone(err error) bool {
+	var urlErr *url.Error
+	return errors.Is(err, transport.ErrSessionTerminated) || errors.As(err, &urlErr)
+}
+
+// fallback switches the session to an in-process server, replaying the
+// client's handshake against it.
+func (p *stdioProxy) fallback(ctx context.Context) error {
+	local, err := p.opts.Fallback()
+	if err != nil {
+		return fmt.Errorf("failed to start local server: %w", err)
+	}
+	// The client now owns this process, as in stdio mode
+	local.registerSetWorkingDirTool()
+
+	session := &proxySession{notifications: make(chan mcp.JSONRPCNotification, 16)}
+	if err := local.mcpServer.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("failed to register local session: %w", err)
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notif := <-session.notifications:
+				p.writeMsg(notif)
+			}
+		}
+	}()
+
+	p.local = local
+	p.localCtx = local.mcpServer.WithContext(ctx, session)
+	if p.initRequest != nil {
+		local.mcpServer.HandleMessage(p.localCtx, p.initRequest)
+	}
+	if p.initialized {
+		local.mcpServer.HandleMessage(p.localCtx, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	}
+	fmt.Fprintln(os.Stderr, "runbook: server unavailable, falling back to standalone mode")
+	return nil
+}
+
+// proxySession is the fallback server's client session. Notifications it
+// receives are written to the proxy's output stream.
+type proxySession struct {
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func (s *proxySession) SessionID() string { return "stdio-proxy" }
+
+func (s *proxySession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *proxySession) Initialize() { s.initialized.Store(true) }
+
+func (s *proxySession) Initialized() bool { return s.initialized.Load() }
+
+// forward sends one request to the server and relays the response. When the
+// server has gone away it reconnects, or falls back to a local server, and
+// retries. It returns an error only when the session cannot continue.
+func (p *stdioProxy) forward(ctx context.Context, line string, req transport.JSONRPCRequest) error {
+	resp, err := p.trans.SendRequest(ctx, req)
+	if err != nil && ctx.Err() == nil && serverGone(err) {
+		fmt.Fprintf(os.Stderr, "Lost connection to server at %s, reconnecting\n", p.addr)
+		rerr := p.reconnect(ctx)
+		switch {
+		case rerr == nil:
+			resp, err = p.trans.SendRequest(ctx, req)
+		case p.opts.Fallback != nil && ctx.Err() == nil:
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", rerr)
+			if ferr := p.fallback(ctx); ferr != nil {
+				p.writeError(req.ID, -32603, ferr.Error())
+				return ferr
+			}
+			p.handleLocal([]byte(line))
+			return nil
+		default:
+			p.writeError(req.ID, -32603, rerr.Error())
+			return rerr
+		}
+	}
+	if err != nil {
+		p.writeError(req.ID, -32603, err.Error())
+		return nil
+	}
+	p.writeMsg(resp)
+	return nil
+}
+
+// handleLocal serves one message with the fallback server.
+func (p *stdioProxy) handleLocal(line []byte) {
+	if resp := p.local.mcpServer.HandleMessage(p.localCtx, line); resp != nil {
+		p.writeMsg(resp)
+	}
+}
+
+// serveStdioProxy is the testable core of ServeStdioProxy. Accepting in/out
+// instead of os.Stdin/Stdout allows tests to pass pipe readers/writers.
+func serveStdioProxy(addr string, in io.Reader, out io.Writer, opts ProxyOptions) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := &stdioProxy{addr: addr, opts: opts, out: out}
+	trans, err := p.connect(ctx)
+	if err != nil {
+		return err
+	}
+	p.trans = trans
+	defer func() { p.trans.Close() }()
+	defer func() {
+		// The fallback server's session daemons end with the client
+		if p.local != nil {
+			p.local.stopAllSessionDaemons()
+		}
+	}()
 
 	// Signal handling — cancel ctx on SIGTERM/interrupt so the loop exits.
 	sigChan := make(chan os.Signal, 1)
@@ -113,16 +372,23 @@ func serveStdioProxy(addr string, in io.Reader, out io.Writer) error {
 
 			// Peek at the ID to distinguish requests (have id) from notifications (no id).
 			var peek struct {
-				ID mcp.RequestId `json:"id"`
+				ID     mcp.RequestId `json:"id"`
+				Method string        `json:"method"`
 			}
 			if err := json.Unmarshal([]byte(line), &peek); err != nil {
-				writeMsg(map[string]any{
-					"jsonrpc": "2.0",
-					"error": map[string]any{
-						"code":    -32700,
-						"message": "Parse error",
-					},
-				})
+				p.writeError(mcp.RequestId{}, -32700, "Parse error")
+				continue
+			}
+
+			switch {
+			case peek.Method == string(mcp.MethodInitialize):
+				p.initRequest = []byte(line)
+			case peek.Method == "notifications/initialized":
+				p.initialized = true
+			}
+
+			if p.local != nil {
+				p.handleLocal([]byte(line))
 				continue
 			}
 
@@ -130,36 +396,19 @@ func serveStdioProxy(addr string, in io.Reader, out io.Writer) error {
 				// Notification — forward without expecting a response.
 				var notif mcp.JSONRPCNotification
 				if err := json.Unmarshal([]byte(line), &notif); err == nil {
-					_ = trans.SendNotification(ctx, notif)
-				}
-			} else {
-				// Request — forward and relay the response.
-				var req transport.JSONRPCRequest
-				if err := json.Unmarshal([]byte(line), &req); err != nil {
-					writeMsg(map[string]any{
-						"jsonrpc": "2.0",
-						"id":      peek.ID,
-						"error": map[string]any{
-							"code":    -32700,
-							"message": "Parse error",
-						},
-					})
-					continue
+					_ = p.trans.SendNotification(ctx, notif)
 				}
+				continue
+			}
 
-				resp, err := trans.SendRequest(ctx, req)
-				if err != nil {
-					writeMsg(map[string]any{
-						"jsonrpc": "2.0",
-						"id":      peek.ID,
-						"error": map[string]any{
-							"code":    -32603,
-							"message": err.Error(),
-						},
-					})
-					continue
-				}
-				writeMsg(resp)
+			// Request — forward and relay the response.
+			var req transport.JSONRPCRequest
+			if err := json.Unmarshal([]byte(line), &req); err != nil {
+				p.writeError(peek.ID, -32700, "Parse error")
+				continue
+			}
+			if err := p.forward(ctx, line, req); err != nil {
+				return err
 			}
 		}
 	}
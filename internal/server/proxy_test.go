@@ -2,13 +2,21 @@ package server
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"io"
+	"net"
+	"net/http"
 	"net/http/httptest"
 	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"runbookmcp.dev/internal/config"
 )
 
 // newTestMCPServer starts an httptest server backed by a minimal MCP server.
@@ -88,7 +96,7 @@ func TestServeStdioProxyExitsOnEOF(t *testing.T) {
 
 	done := make(chan error, 1)
 	go func() {
-		done <- serveStdioProxy(ts.URL, pr, io.Discard)
+		done <- serveStdioProxy(ts.URL, pr, io.Discard, ProxyOptions{})
 	}()
 
 	// Closing the write end triggers EOF in the readLines goroutine regardless
@@ -119,7 +127,7 @@ func TestServeStdioProxyExitsCleanlyOnBadAddr(t *testing.T) {
 
 	done := make(chan error, 1)
 	go func() {
-		done <- serveStdioProxy("http://127.0.0.1:19741", pr, io.Discard)
+		done <- serveStdioProxy("http://127.0.0.1:19741", pr, io.Discard, ProxyOptions{})
 	}()
 
 	pw.Close() // signal EOF immediately
@@ -131,3 +139,181 @@ func TestServeStdioProxyExitsCleanlyOnBadAddr(t *testing.T) {
 		t.Fatal("serveStdioProxy with bad addr hung instead of exiting on EOF")
 	}
 }
+
+// startMCPServerAt serves a minimal MCP server with one tool on addr and
+// returns a function that stops it.
+func startMCPServerAt(t *testing.T, addr string) func() {
+	t.Helper()
+	var ln net.Listener
+	var err error
+	for i := 0; i < 50; i++ {
+		if ln, err = net.Listen("tcp", addr); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("listen %s: %v", addr, err)
+	}
+	s := mcpserver.NewMCPServer("proxy-test", "0.1.0", mcpserver.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("remote_tool"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	srv := &http.Server{Handler: mcpserver.NewStreamableHTTPServer(s)}
+	go func() { _ = srv.Serve(ln) }()
+	stopped := false
+	stop := func() {
+		if !stopped {
+			stopped = true
+			srv.Close()
+		}
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// proxyClient drives serveStdioProxy over pipes.
+type proxyClient struct {
+	t    *testing.T
+	in   *io.PipeWriter
+	out  *bufio.Reader
+	done chan error
+}
+
+func startProxy(t *testing.T, addr string, opts ProxyOptions) *proxyClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &proxyClient{t: t, in: inW, out: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		c.done <- serveStdioProxy(addr, inR, outW, opts)
+		outW.Close()
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		outR.Close()
+	})
+	return c
+}
+
+func (c *proxyClient) send(msg string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, msg+"\n"); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+// response returns the next response line, decoded.
+func (c *proxyClient) response() map[string]any {
+	c.t.Helper()
+	lineCh := make(chan string, 1)
+	go func() {
+		line, _ := c.out.ReadString('\n')
+		lineCh <- line
+	}()
+	select {
+	case line := <-lineCh:
+		var msg map[string]any
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			c.t.Fatalf("bad response %q: %v", line, err)
+		}
+		return msg
+	case <-time.After(10 * time.Second):
+		c.t.Fatal("no response from proxy")
+		return nil
+	}
+}
+
+// handshake initializes the session through the proxy.
+func (c *proxyClient) handshake() {
+	c.t.Helper()
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	if resp := c.response(); resp["error"] != nil {
+		c.t.Fatalf("initialize failed: %v", resp["error"])
+	}
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+}
+
+// toolNames lists the tools through the proxy.
+func (c *proxyClient) toolNames(id int) string {
+	c.t.Helper()
+	c.send(`{"jsonrpc":"2.0","id":` + strconv.Itoa(id) + `,"method":"tools/list"}`)
+	resp := c.response()
+	if resp["error"] != nil {
+		c.t.Fatalf("tools/list failed: %v", resp["error"])
+	}
+	var names []string
+	for _, tool := range resp["result"].(map[string]any)["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	return strings.Join(names, ",")
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func TestServeStdioProxyReconnectsAfterRestart(t *testing.T) {
+	addr := freeAddr(t)
+	stop := startMCPServerAt(t, addr)
+	c := startProxy(t, "http://"+addr, ProxyOptions{ReconnectTimeout: 10 * time.Second})
+	c.handshake()
+	if got := c.toolNames(2); got != "remote_tool" {
+		t.Fatalf("tools = %q, want remote_tool", got)
+	}
+
+	// The restarted server does not know the old MCP session
+	stop()
+	startMCPServerAt(t, addr)
+	if got := c.toolNames(3); got != "remote_tool" {
+		t.Errorf("tools after restart = %q, want remote_tool", got)
+	}
+}
+
+func TestServeStdioProxyFallsBackToLocalServer(t *testing.T) {
+	local := newTestServer(t, &config.Manifest{Tasks: map[string]config.Task{}})
+	addr := freeAddr(t)
+	stop := startMCPServerAt(t, addr)
+	c := startProxy(t, "http://"+addr, ProxyOptions{
+		ReconnectTimeout: 200 * time.Millisecond,
+		Fallback:         func() (*Server, error) { return local, nil },
+	})
+	c.handshake()
+
+	stop()
+	if got := c.toolNames(2); got != "set_working_directory" {
+		t.Errorf("tools after fallback = %q, want set_working_directory", got)
+	}
+	// Later requests stay local
+	if got := c.toolNames(3); got != "set_working_directory" {
+		t.Errorf("tools = %q, want set_working_directory", got)
+	}
+}
+
+func TestServeStdioProxyExitsWhenServerDoesNotReturn(t *testing.T) {
+	addr := freeAddr(t)
+	stop := startMCPServerAt(t, addr)
+	c := startProxy(t, "http://"+addr, ProxyOptions{ReconnectTimeout: 200 * time.Millisecond})
+	c.handshake()
+
+	stop()
+	c.send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	resp := c.response()
+	if resp["error"] == nil || resp["id"] != float64(2) {
+		t.Errorf("response = %v, want an error for request 2", resp)
+	}
+	select {
+	case err := <-c.done:
+		if err == nil || !strings.Contains(err.Error(), "did not come back") {
+			t.Errorf("err = %v, want server did not come back", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("proxy did not exit")
+	}
+}
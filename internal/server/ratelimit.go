@@ -0,0 +1,46 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultExecRateLimit is the number of shell_exec calls allowed per minute
+// when exec.rate_limit is not set.
+const defaultExecRateLimit = 30
+
+// rateLimiter allows at most limit calls in any sliding window.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	calls  []time.Time
+	now    func() time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, now: time.Now}
+}
+
+// allow records a call and reports whether it is within the limit. If it is
+// not, it returns how long until the next call will be allowed.
+func (l *rateLimiter) allow() (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-l.window)
+	kept := l.calls[:0]
+	for _, t := range l.calls {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	l.calls = kept
+
+	if len(l.calls) >= l.limit {
+		return false, l.calls[0].Add(l.window).Sub(now)
+	}
+	l.calls = append(l.calls, now)
+	return true, 0
+}
@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
+	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/template"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // registerResources registers MCP resources for task metadata
 func (s *Server) registerResources() {
+	if s.withheld[config.CapabilityResources] {
+		return
+	}
 	// Register task-groups resource
 	s.mcpServer.AddResource(
 		mcp.NewResource(
@@ -21,7 +24,7 @@ func (s *Server) registerResources() {
 		),
 		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 			// Marshal task groups to JSON
-			data, err := json.MarshalIndent(s.manifest.TaskGroups, "", "  ")
+			data, err := json.MarshalIndent(s.current().manifest.TaskGroups, "", "  ")
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal task groups: %w", err)
 			}
@@ -46,7 +49,7 @@ func (s *Server) registerResources() {
 		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 			// Build dependency map
 			dependencies := make(map[string][]string)
-			for taskName, task := range s.manifest.Tasks {
+			for taskName, task := range s.current().manifest.Tasks {
 				if len(task.DependsOn) > 0 {
 					dependencies[taskName] = task.DependsOn
 				}
@@ -239,13 +242,42 @@ You can control whitespace in templates using {{- and -}}:
 
 ## Template Functions
 
-Currently, templates support all standard Go text/template functions:
+Templates support all standard Go text/template functions:
 - and, or, not - Boolean operations
 - eq, ne, lt, le, gt, ge - Comparisons
 - len - Length of arrays, maps, strings
 - index - Index into arrays and maps
 - printf - Formatted printing
 
+Command, prompt, and resource templates also get these built-in functions. Functions that transform a value take it last, so they chain in pipelines:
+- shellquote - Single-quotes a value for safe use in a shell command (` + "`shellQuote`" + ` also works)
+- quote - Double-quotes a value, escaping quotes and backslashes
+- trim, upper, lower - Trim whitespace, change case
+- replace OLD NEW - Replaces every occurrence of OLD with NEW
+- default DEFAULT - Returns DEFAULT when the value is empty
+- join SEP - Joins a list with SEP
+- now [LAYOUT] - The current time, in RFC 3339 or the given Go time layout
+- uuid - A random UUID
+- env NAME - The value of the server's environment variable NAME, or "" when unset
+
+` + "```yaml" + `
+command: "git checkout {{.branch | trim | default \"main\" | shellquote}}"
+` + "```" + `
+
+Prefer ` + "`shellquote`" + ` over hand-written quotes whenever a parameter ends up in a shell command: it stays safe when the value itself contains quotes.
+
+### Command Linting
+
+When the server starts, and with ` + "`runbook validate`" + `, task commands are checked for string parameters a value could break out of. Each finding is a warning naming the task and parameter:
+
+- ` + "`{{.name}}`" + ` with no quoting, or with ` + "`trim`" + `, ` + "`default`" + `, or other functions but not ` + "`shellquote`" + ` last
+- ` + "`\"{{.name}}\"`" + `, where ` + "`$(...)`" + ` and backticks in the value still run
+- ` + "`'{{.name}}'`" + `, which a value containing ` + "`'`" + ` escapes
+- ` + "`{{.name | quote}}`" + `, whose double quotes expand like the above
+- any parameter in a command that uses ` + "`eval`" + ` or ` + "`sh -c`" + `, which parse the value again even when it is shellquoted
+
+Number and boolean parameters, parameters with a ` + "`source`" + `, and commands run by an interpreter such as ` + "`python3 -c`" + ` are not checked. Set ` + "`defaults.strict_security: true`" + ` to make the findings load errors; ` + "`runbook validate --strict`" + ` fails on them in CI without changing the config.
+
 Example with conditionals:
 ` + "```yaml" + `
 command: "{{if .verbose}}set -x; {{end}}./script.sh"
@@ -345,6 +377,8 @@ The server searches for configuration files in this order:
 1. Custom path specified with ` + "`-config <path>`" + ` flag
 2. ` + "`./.runbook/`" + ` directory (all *.yaml files merged)
 
+Once a project config is found, the tasks, task groups, workflows, prompts, and resources in ` + "`~/.config/runbook/*.yaml`" + ` (or ` + "`$XDG_CONFIG_HOME/runbook/`" + `) are added to it. Use it for personal helpers you want in every project. An item the project defines under the same name wins. Other settings in the user config are ignored.
+
 ## Basic Structure
 
 ` + "```yaml" + `
@@ -377,6 +411,22 @@ version: "1.0"
 
 Currently, only "1.0" is supported.
 
+## Tool Names
+
+Task tools are named after the task (` + "`run_test`" + `, ` + "`start_dev`" + `, ` + "`logs_dev`" + `) and workflow tools after the workflow (` + "`run_workflow_ci`" + `). When an agent is connected to several runbook servers, ` + "`defaults.tool_prefix`" + ` namespaces them, and a task's ` + "`tool_name`" + ` replaces its name in them:
+
+` + "```yaml" + `
+defaults:
+  tool_prefix: myproj_
+tasks:
+  test:
+    command: "go test ./..."
+    type: oneshot
+    tool_name: unit_tests   # tool: myproj_run_unit_tests
+` + "```" + `
+
+Both may use letters, digits, ` + "`_`" + `, and ` + "`-`" + `; two tasks or workflows ending up with the same tool name is a config error. Built-in tools such as ` + "`list_sessions`" + ` keep their names. Prompts and resources referring to tools through ` + "`{{.Tasks.<name>.Run}}`" + ` or ` + "`run_task`" + ` get the final names, and the CLI finds them through the local config, so ` + "`runbook run test`" + ` still works.
+
 ## Defaults
 
 **Optional.** Global default values for all tasks.
@@ -384,13 +434,75 @@ Currently, only "1.0" is supported.
 ` + "```yaml" + `
 defaults:
   timeout: 300        # Default timeout in seconds
+  max_timeout: 3600   # Largest timeout a run_ call may request
   shell: "/bin/bash"  # Default shell for command execution
   working_directory: "."           # Default working directory
   env:               # Default environment variables
     NODE_ENV: "development"
+  latency_budget: 30  # Soft budget in seconds for run_ tool calls (-1 disables)
+  redact:            # Regexes masked in every task's output (see Output Redaction)
+    - "ghp_[A-Za-z0-9]+"
+  env_policy:        # Host environment tasks inherit (see Environment Policy)
+    mode: allowlist
+    vars: [PATH, HOME, "GO*"]
+  stop_grace: 5      # Seconds a stopped daemon has to exit after SIGTERM before SIGKILL
+  session_sink:      # Export completed sessions (see Session Export)
+    file: sessions.jsonl
+  max_concurrent_tasks: 4  # HTTP server: oneshot runs at once, the rest queue (0 = unlimited)
+  on_crash:          # Report daemons that crash (see Crash Notifications)
+    desktop: true
+  strict_security: true  # Fail the load on unsafe parameter interpolation (see Command Linting)
+  tool_prefix: myproj_   # Prepended to task and workflow tool names (see Tool Names)
+` + "```" + `
+
+Task-specific values override these defaults. In a ` + "`.runbook/`" + ` directory, the defaults of all files apply, earlier files (by name) winning.
+
+When a run_ tool call (task or workflow) takes longer than its latency budget (default 30 seconds), the result carries a ` + "`latency_hint`" + ` with the budget, the call's duration, the tool's average latency, and a suggestion to follow long work through daemon start_/logs_ tools or ` + "`read_session_log`" + ` instead of blocking. The call itself is not interrupted; use ` + "`timeout`" + ` for a hard limit.
+
+Results also carry a ` + "`hint`" + ` when the agent is missing a step: ` + "`status_<task>`" + ` for a daemon that has never been started points at ` + "`start_<task>`" + `, and a failed ` + "`run_`" + ` or ` + "`start_`" + ` call that left out required parameters points back at the same tool. Each hint has a ` + "`message`" + `, the ` + "`tool`" + ` to call, and its ` + "`required_params`" + `.
+
+A run_ call can pass ` + "`timeout`" + ` (in seconds) to override the task's timeout for that call, e.g. for a known-slow invocation of an otherwise fast task. The value is capped at the task's ` + "`max_timeout`" + `, and the result reports the timeout that was applied. Tasks that define their own ` + "`timeout`" + ` parameter keep it and get no override.
+
+In HTTP server mode, ` + "`max_concurrent_tasks`" + ` caps how many oneshot runs (run_ calls, REST runs, and workflow steps) execute at once on the shared host. Runs over the limit wait in order for a free slot instead of failing; the ` + "`queue_status`" + ` tool lists the runs executing now and each waiting run's ` + "`position`" + `. Time spent waiting does not count toward a run's timeout or duration. Stdio servers and the CLI never queue.
+
+Each run_ result and each session's metadata also records the process's ` + "`resources`" + `: user and system CPU seconds, wall-clock seconds, and peak RSS in bytes (where the platform reports it). Daemon sessions record theirs when the daemon exits.
+
+### Session Export
+
+` + "`defaults.session_sink`" + ` exports the metadata of every completed session (oneshot runs, workflow steps, and daemons when they exit) so execution data can be collected centrally instead of read from the state directory. Each session is the same JSON object ` + "`read_session_metadata`" + ` returns.
+
+` + "```yaml" + `
+defaults:
+  session_sink:
+    file: sessions.jsonl                   # Append one JSON object per line
+    url: https://ci.example.com/sessions   # POST each session as JSON
+    token_env: SESSION_SINK_TOKEN          # Optional bearer token for url
+    timeout: 5                             # Seconds to wait for url (default 5)
 ` + "```" + `
 
-Task-specific values override these defaults.
+Either ` + "`file`" + ` or ` + "`url`" + ` is required; both may be set. Export failures are printed as warnings and never fail the run.
+
+### Environment Policy
+
+By default every task inherits the whole environment of the server or CLI that runs it. ` + "`env_policy`" + `, under ` + "`defaults`" + ` or on a task, limits which host variables its processes (oneshot commands, daemons, compose, and ` + "`shell_exec`" + `) inherit:
+
+| Mode | Inherits |
+|------|----------|
+| all | Everything (the default) |
+| allowlist | Only the variables in ` + "`vars`" + ` |
+| denylist | Everything except the variables in ` + "`vars`" + ` |
+| none | Nothing |
+
+` + "`vars`" + ` are names or globs such as ` + "`AWS_*`" + `. A task's ` + "`env_policy`" + ` replaces the default one rather than adding to it, and its ` + "`env`" + ` is always set on top.
+
+` + "```yaml" + `
+tasks:
+  lint:
+    command: "golangci-lint run"
+    env_policy:
+      mode: denylist
+      vars: ["AWS_*", "GITHUB_TOKEN"]
+` + "```" + `
 
 ## Tasks
 
@@ -398,9 +510,11 @@ Task-specific values override these defaults.
 
 ### Task Types
 
-Two task types are supported:
+Four task types are supported:
 - ` + "`oneshot`" + ` - Runs once and returns output
 - ` + "`daemon`" + ` - Runs continuously in the background
+- ` + "`compose`" + ` - A docker compose stack managed like a daemon
+- ` + "`file_ops`" + ` - Declarative file operations run without a shell (see File Operations)
 
 ### One-Shot Task
 
@@ -439,22 +553,127 @@ tasks:
 - ` + "`status_dev`" + ` - Check if running
 - ` + "`logs_dev`" + ` - Read daemon logs
 
+Every start, stop, exit, crash and adoption of a daemon is appended to ` + "`._runbook_state/logs/events/<task>.jsonl`" + `. The status tool returns the most recent entries in ` + "`last_events`" + `, which shows when and why a daemon stopped, and the most recent exit or crash, however old, in ` + "`last_exit`" + `.
+
+While a daemon runs, the status tool also returns the usage of its process group in ` + "`usage`" + `: ` + "`rss_bytes`" + ` of resident memory, ` + "`cpu_seconds`" + ` of CPU time, ` + "`cpu_percent`" + ` of one core averaged since each process started, and the number of ` + "`children`" + ` besides the daemon. It is read from ` + "`/proc`" + ` on Linux and ` + "`ps`" + ` on macOS, and omitted on Windows.
+
+Set ` + "`lifetime: session`" + ` to tie a daemon to the MCP client that started it. When that client disconnects, the daemon is stopped after ` + "`session_grace`" + ` seconds (default 30) unless the client reconnects first. A stdio server stops its session daemons when it exits. Daemons started from the runbook CLI, or with the default ` + "`lifetime: persistent`" + `, run until stopped.
+
+Set ` + "`log_max_size`" + ` (e.g. ` + "`10MB`" + `; ` + "`KB`" + `, ` + "`MB`" + ` and ` + "`GB`" + ` are accepted) to rotate a long-running daemon's session log. When the log reaches that size it is moved to ` + "`<log>.1`" + `, older segments shift up, and only ` + "`log_max_files`" + ` (default 5) rotated segments are kept. ` + "`logs_dev`" + `, ` + "`search_logs`" + `, and ready ` + "`log_pattern`" + ` checks read across the segments as one log. A rotated daemon's output is copied to its log by the runbook process that started it, so it stops being logged if that process exits.
+
+### Crash Notifications
+
+A daemon that exits on its own with a failure status has crashed. ` + "`on_crash`" + ` reports crashes as they happen instead of leaving them to be found in a status call:
+
+` + "```yaml" + `
+tasks:
+  api:
+    description: "API server"
+    command: "go run ./cmd/api"
+    type: daemon
+    on_crash:
+      desktop: true                      # notify-send on Linux, osascript on macOS
+      webhook: "https://hooks.example.com/runbook"
+      token_env: HOOK_TOKEN              # Optional bearer token for webhook
+      task: page_oncall                  # Oneshot task to run
+` + "```" + `
+
+The webhook receives the crash event as JSON (the same object as in ` + "`last_events`" + `). The notify task gets the crash as whichever of the ` + "`task`" + `, ` + "`exit_code`" + `, ` + "`reason`" + `, ` + "`session_id`" + `, and ` + "`pid`" + ` parameters it declares. ` + "`defaults.on_crash`" + ` applies to daemons without their own. Crashes are reported by the runbook process that started the daemon, typically the MCP server; daemons started from the CLI, or adopted after a restart, are not watched for crashes. Failed notifications are printed as warnings.
+
+### Compose Task
+
+` + "```yaml" + `
+tasks:
+  stack:
+    description: "Database and cache"
+    type: compose
+    compose:
+      file: "docker-compose.dev.yml"  # default: docker compose's lookup
+      project: "myapp"                # default: from the directory
+      services: ["db", "redis"]       # default: all services
+    ready:
+      port: 5432
+` + "```" + `
+
+Compose tasks get the same tools as daemons and take no ` + "`command`" + `. ` + "`start_stack`" + ` runs ` + "`docker compose up --detach`" + ` and then follows ` + "`docker compose logs`" + ` as the daemon process, so ` + "`logs_stack`" + ` and the session log show the stack's output. ` + "`status_stack`" + ` adds each container's state and health from ` + "`docker compose ps`" + ` in ` + "`services`" + `. ` + "`stop_stack`" + ` runs ` + "`docker compose down`" + `, or removes only the listed services when ` + "`services`" + ` is set. Compose tasks can be listed in ` + "`requires_daemon`" + ` and use ` + "`ready`" + ` and ` + "`lifetime`" + ` like other daemons.
+
 ### Task Fields
 
 | Field | Required | Type | Description |
 |-------|----------|------|-------------|
 | description | Yes | string | Human-readable description shown in MCP tools |
 | command | Yes | string | Shell command to execute (supports templates) |
-| type | Yes | string | Either "oneshot" or "daemon" |
+| type | Yes | string | "oneshot", "daemon", "compose", or "file_ops" |
 | timeout | No | int | Timeout in seconds (default: from defaults or 300) |
-| shell | No | string | Shell to use (default: from defaults or /bin/bash) |
+| max_timeout | No | int | Largest timeout a run_ call may request with its ` + "`timeout`" + ` argument (default: from defaults or 3600) |
+| shell | No | string | Shell to use (default: from defaults or /bin/bash), or an interpreter command such as ` + "`python3 -c`" + ` (see Interpreters) |
 | working_directory | No | string | Working directory (default: from defaults or .) |
 | expose_working_directory | No | bool | If true, adds a working_directory parameter to the MCP tool |
+| allowed_working_directories | No | []string | Globs a ` + "`working_directory`" + ` argument must match (see Dynamic Working Directory) |
 | env | No | map | Environment variables to set |
+| redact | No | []string | Regexes masked in the task's output and logs, in addition to ` + "`defaults.redact`" + ` |
+| artifacts | No | []string | Oneshot only: paths or globs kept with the session after each run (see Artifacts) |
+| expected_exit_codes | No | map | Oneshot only: non-zero exit codes that count as success, mapped to what they mean (see Exit Code Classification) |
+| warning_exit_codes | No | map | Oneshot only: exit codes that count as success with a warning, mapped to what they mean |
+| output_format | No | string | Oneshot only: ` + "`text`" + ` (default) or ` + "`json`" + ` to parse stdout into a structured ` + "`output`" + ` |
+| async | No | bool | Oneshot only: run_ calls start the task in the background unless ` + "`run_async: false`" + ` (see Background Runs) |
+| env_policy | No | object | Host environment variables inherited, replacing ` + "`defaults.env_policy`" + ` (see Environment Policy) |
 | parameters | No | map | Parameter definitions (see Parameters section) |
+| parameter_presets | No | map | Named sets of parameter values, chosen with ` + "`preset`" + ` or ` + "`--preset`" + ` (see Parameter Presets) |
 | depends_on | No | []string | List of task names this task depends on |
+| requires_daemon | No | []string | Daemons to start (if not running) and wait on before this task runs |
+| ready | No | object | Daemon only: condition that marks the daemon ready (see Daemon Readiness) |
+| latency_budget | No | int | Soft budget in seconds before run_ results carry a latency_hint (default: from defaults or 30, -1 disables) |
+| retries | No | int | Extra attempts when the task fails as a workflow step (default: 0) |
+| retry_delay | No | int | Seconds to wait between those attempts (default: 0) |
+| lifetime | No | string | Daemon only: ` + "`persistent`" + ` (default) or ` + "`session`" + ` to stop it when the MCP client that started it disconnects |
+| session_grace | No | int | Seconds a session daemon keeps running after its client disconnects (default: 30) |
+| interactive | No | bool | Daemons: run on a terminal and add a ` + "`send_input_`" + ` tool (see Interactive Daemons). Oneshot: run only from the CLI, on its terminal (see Interactive Tasks) |
+| log_max_size | No | string | Daemon only: rotate the session log at this size, e.g. ` + "`10MB`" + ` (see Daemon Task) |
+| log_max_files | No | int | Daemon only: rotated log segments kept (default: 5) |
+| on_crash | No | object | Daemon only: how a crash is reported, replacing ` + "`defaults.on_crash`" + ` (see Crash Notifications) |
+| reload | No | bool | Daemon only: add a ` + "`reload_`" + ` tool that signals the daemon to reload its config (see Reloading Daemons) |
+| reload_signal | No | string | Daemon only: signal sent by ` + "`reload_`" + `: HUP (default), USR1, USR2, INT, QUIT, or WINCH |
+| stop_signal | No | string | Daemon only: signal sent to stop the daemon: TERM (default), INT, QUIT, HUP, USR1, or USR2 |
+| stop_grace_period | No | int | Daemon only: seconds the daemon has to exit after ` + "`stop_signal`" + ` before SIGKILL (default: ` + "`defaults.stop_grace`" + `) |
+| ports | No | []int | Daemon only: ports the daemon listens on; starting fails if one is already in use |
+| requires_confirmation | No | bool | MCP calls must be confirmed with a token and the CLI prompts before running (see Confirmation Gates) |
+| hints | No | object | ` + "`read_only`" + `, ` + "`destructive`" + `, and ` + "`idempotent`" + ` bools sent as MCP tool annotations (see Tool Hints) |
+| rate_limit | No | object | ` + "`max_runs`" + ` runs or starts allowed per ` + "`per`" + ` duration (see Rate Limits) |
+| cache | No | object | Oneshot only: reuse the result of an earlier run whose rendered ` + "`key`" + ` and parameters match, for up to ` + "`ttl`" + ` (see Result Cache) |
+| log_output | No | string | Oneshot only: stdout kept in results, ` + "`full`" + ` (default), ` + "`truncated`" + `, or ` + "`none`" + `; the session log keeps all of it (see Log Output) |
+| log_output_max_lines | No | integer | With ` + "`log_output: truncated`" + `: last lines of stdout kept (default 100) |
+| log_output_max_bytes | No | string | With ` + "`log_output: truncated`" + `: most bytes of stdout kept, e.g. ` + "`16KB`" + ` (default 64KB) |
+| runner | No | string | Oneshot only: ` + "`shell`" + ` (default), ` + "`docker`" + ` to run the command in a container (see Container Runner), or an agent tag to run it on a remote agent (see Remote Agents) |
+| container | No | object | Image, mounts, and network for ` + "`runner: docker`" + ` |
+| compose | No | object | Compose only: ` + "`file`" + `, ` + "`project`" + `, and ` + "`services`" + ` of the stack |
+| operations | No | []object | File_ops only: the operations to run, in order (see File Operations) |
+| preconditions | No | []object | Oneshot and file_ops only: checks that must pass before the command runs (see Preconditions) |
+| extends | No | string | Task template to inherit unset fields from (see Task Templates) |
+| project | No | string | Take this task's definition from a sibling project (see Cross-Project Tasks) |
+| task | No | string | Task name in ` + "`project`" + ` (default: this task's name) |
 | disabled | No | bool | If true, hidden from MCP and CLI entirely |
 | disable_mcp | No | bool | If true, hidden from MCP only; CLI can still run it |
+| tool_name | No | string | Replaces the task name in its tool names, e.g. ` + "`run_<tool_name>`" + ` (see Tool Names) |
+
+### Interpreters
+
+A ` + "`shell`" + ` with arguments is an interpreter command: the task's command is passed as its last argument instead of through ` + "`-c`" + `, so tasks can embed small scripts in other languages:
+
+` + "```yaml" + `
+tasks:
+  count_todos:
+    description: "Count TODOs per file"
+    type: oneshot
+    shell: "python3 -c"
+    command: |
+      import pathlib
+      for p in pathlib.Path("{{.dir}}").rglob("*.go"):
+          n = p.read_text().count("TODO")
+          if n: print(p, n)
+` + "```" + `
+
+A bare path like ` + "`/bin/zsh`" + ` still runs as ` + "`/bin/zsh -c <command>`" + `. Interpreter commands work for oneshot and daemon tasks, in ` + "`defaults.shell`" + `, and inside ` + "`runner: docker`" + ` containers.
 
 ### Parameterized Tasks
 
@@ -510,6 +729,96 @@ tasks:
 | required | Yes | bool | Whether parameter is required |
 | description | Yes | string | Human-readable description |
 | default | No | string | Default value for optional parameters |
+| aliases | No | list | Other names the parameter is accepted under |
+| deprecated_names | No | list | Old names, still accepted; the result includes a ` + "`warnings`" + ` entry |
+| source | No | string | ` + "`file`" + ` or ` + "`stdin`" + `: pass the value through a temp file instead of the command string (oneshot tasks only) |
+| pattern | No | string | Regex the value must match |
+| enum | No | list | Values the parameter accepts |
+| min / max | No | number | Bounds for ` + "`number`" + ` and ` + "`integer`" + ` parameters |
+| min_length / max_length | No | int | Length bounds for ` + "`string`" + ` parameters |
+
+Renaming a parameter without breaking existing prompts or clients:
+
+` + "```yaml" + `
+parameters:
+  package:
+    type: string
+    description: "Package to test"
+    deprecated_names: [pkg]
+` + "```" + `
+
+### Parameter Rules
+
+` + "`pattern`" + `, ` + "`enum`" + `, ` + "`min`" + `/` + "`max`" + `, and ` + "`min_length`" + `/` + "`max_length`" + ` are checked after defaults are applied and before anything is substituted into the command. A value that breaks a rule fails the run with a message naming the parameter and the rule, and nothing runs. The rules are also part of the tool's input schema (` + "`pattern`" + `, ` + "`enum`" + `, ` + "`minimum`" + `, ` + "`maximum`" + `, ` + "`minLength`" + `, ` + "`maxLength`" + `).
+
+` + "```yaml" + `
+parameters:
+  env:
+    type: string
+    description: "Target environment"
+    enum: [staging, prod]
+  replicas:
+    type: integer
+    description: "Replica count"
+    min: 1
+    max: 10
+` + "```" + `
+
+### Parameter Presets
+
+` + "`parameter_presets`" + ` names reusable sets of values for a oneshot task's parameters. The ` + "`run_`" + ` tool gets a ` + "`preset`" + ` argument listing them, and ` + "`runbook run <task> --preset=<name>`" + ` picks one on the CLI. The preset's values are filled in before defaults and template substitution; parameters passed explicitly override them.
+
+` + "```yaml" + `
+tasks:
+  test:
+    description: "Run tests"
+    command: "go test {{.flags}} {{.path}}"
+    type: oneshot
+    parameters:
+      flags:
+        type: string
+        description: "Test flags"
+      path:
+        type: string
+        default: "./..."
+        description: "Packages to test"
+    parameter_presets:
+      quick_test:
+        flags: "-short"
+        path: "./internal/..."
+` + "```" + `
+
+A preset may only set the task's own parameters, and a task with presets cannot have a parameter named ` + "`preset`" + `.
+
+### File and Stdin Inputs
+
+Large or shell-hostile values, like patches or SQL scripts, should not be interpolated into a command. A parameter with ` + "`source: file`" + ` is written to a temp file instead, and the command reads its path as ` + "`{{.<name>_path}}`" + `; ` + "`{{.<name>}}`" + ` itself is not available. ` + "`source: stdin`" + ` does the same and also feeds the file to the command's standard input. Temp files are removed when the run ends.
+
+` + "```yaml" + `
+tasks:
+  apply_patch:
+    description: "Apply a patch"
+    command: "git apply {{.patch_path}}"
+    type: oneshot
+    parameters:
+      patch:
+        type: string
+        required: true
+        description: "Patch contents"
+        source: file
+  query:
+    description: "Run SQL against the dev database"
+    command: "psql dev"
+    type: oneshot
+    parameters:
+      sql:
+        type: string
+        required: true
+        description: "SQL script"
+        source: stdin
+` + "```" + `
+
+MCP clients pass the contents as the parameter's value. On the CLI, ` + "`--patch=@fix.patch`" + ` reads a file, ` + "`--patch=-`" + ` reads stdin, and a ` + "`stdin`" + ` parameter that is not given is read from piped input (` + "`runbook run query < seed.sql`" + `). Only one parameter per task can have ` + "`source: stdin`" + `, and sources are not supported with ` + "`runner: docker`" + `.
 
 ### Dynamic Working Directory
 
@@ -538,6 +847,146 @@ When ` + "`expose_working_directory: true`" + ` is set, the generated MCP tool w
 
 This enables flexible task execution where the working directory can be determined dynamically based on context, while maintaining a sensible default.
 
+To keep callers from pointing a task at ` + "`/`" + ` or an unrelated path, list the directories it may run in with ` + "`allowed_working_directories`" + `:
+
+` + "```yaml" + `
+    expose_working_directory: true
+    allowed_working_directories:
+      - "services/*"
+      - "."
+` + "```" + `
+
+A ` + "`working_directory`" + ` argument must then match one of the globs, or the call fails without running. Relative patterns are resolved against the directory runbook runs in, and paths are compared after resolving ` + "`..`" + ` and symlinks. ` + "`*`" + ` matches a single path element, so ` + "`services/*`" + ` allows ` + "`services/api`" + ` but not ` + "`services/api/internal`" + `.
+
+When a call provides ` + "`working_directory`" + ` inside a git repository, the result and session metadata record a ` + "`workdir_fingerprint`" + ` of the code the run observed: the HEAD ` + "`commit`" + `, whether the tree was ` + "`dirty`" + `, and a ` + "`dirty_hash`" + ` (SHA-256 of uncommitted changes and untracked files). Two runs with the same commit and dirty hash saw the same code.
+
+### Artifacts
+
+Oneshot tasks can list files to keep with each run in ` + "`artifacts`" + `: paths or globs relative to the working directory. When the run finishes, whether or not it succeeded, matches are copied into the session's ` + "`artifacts`" + ` directory (matched directories with their contents) and listed in the session metadata and in the result:
+
+` + "```yaml" + `
+tasks:
+  test:
+    description: "Run tests with coverage"
+    command: "go test -coverprofile=coverage.out ./..."
+    artifacts: ["coverage.out", "reports/*.xml"]
+` + "```" + `
+
+The result's ` + "`artifacts`" + ` gives each file's ` + "`path`" + ` and ` + "`size`" + `, and ` + "`artifacts_dir`" + ` is where they were copied. Files outside the working directory keep only their name. ` + "`runbook artifacts <session|task> [--out=DIR]`" + ` lists them or copies them out.
+
+### Exit Code Classification
+
+Some commands use non-zero exit codes for outcomes that are not failures. ` + "`expected_exit_codes`" + ` and ` + "`warning_exit_codes`" + ` map exit codes to what they mean:
+
+` + "```yaml" + `
+tasks:
+  find_todos:
+    description: "Find TODO comments"
+    command: "grep -rn TODO src/"
+    expected_exit_codes:
+      1: "no matches"
+  lint:
+    description: "Run the linter"
+    command: "eslint src/"
+    warning_exit_codes:
+      2: "lint warnings"
+` + "```" + `
+
+Every result has a ` + "`status`" + `: ` + "`success`" + `, ` + "`warning`" + `, or ` + "`failure`" + `. Listed codes set ` + "`success: true`" + ` with status ` + "`success`" + ` or ` + "`warning`" + `, and ` + "`status_reason`" + ` says what the code means; other non-zero codes fail as usual. Workflows treat warnings as success. The status is also recorded in the session metadata.
+
+### Error Codes
+
+Failed calls set ` + "`error_code`" + ` next to ` + "`error`" + `, so agents can branch on the kind of failure instead of parsing the message:
+
+| Code | Meaning |
+|------|---------|
+| CONFIG_ERROR | The task definition or the call's arguments are invalid, e.g. a missing required parameter or a daemon run with ` + "`run_`" + ` |
+| TEMPLATE_ERROR | A command, var, or cache key template could not be rendered |
+| TIMEOUT | The run, workflow, or step was killed when its timeout passed |
+| CANCELLED | The run or workflow was killed because the client disconnected or the server shut down |
+| NOT_FOUND | No task, workflow, or session has the given name |
+| PERMISSION | A policy refused the call: ` + "`allowed_working_directories`" + `, ` + "`exec.allow`/`exec.deny`" + `, or an interactive task called without a terminal |
+| ALREADY_RUNNING | The daemon to start is already running |
+| NOT_RUNNING | The daemon to stop is not running |
+| RATE_LIMITED | The call came too soon after the last one; wait and retry |
+| PRECONDITION_FAILED | A precondition of the task did not hold, so its command did not run |
+| UNAVAILABLE | The server is draining, or no agent can take the run |
+| IN_PROGRESS | A background run has not finished yet |
+| IO_ERROR | A file, log, or process could not be read, written, or started |
+| INTERNAL_ERROR | The server failed to build its response |
+
+Results (` + "`run_`" + `, ` + "`start_`" + `, ` + "`stop_`" + `, and workflow responses) include ` + "`error_code`" + ` in their JSON; a workflow takes the code of the step that failed it. Calls that fail as tool errors return ` + "`{\"success\": false, \"error\": \"...\", \"error_code\": \"...\"}`" + ` as structured content, with the message as text. A command that ran and exited non-zero has no code: ` + "`exit_code`" + ` and ` + "`status`" + ` describe it.
+
+### Preconditions
+
+Preconditions are checks a oneshot or file_ops task runs before its command, so a missing prerequisite is reported as such instead of as an opaque command failure:
+
+` + "```yaml" + `
+tasks:
+  migrate:
+    description: "Run database migrations"
+    command: "make migrate ENV={{.env}}"
+    preconditions:
+      - file_exists: "config/{{.env}}.yaml"
+        message: "run setup_config first"
+      - command: "pg_isready -q"
+        message: "start the db daemon"
+      - port_free: 9090
+` + "```" + `
+
+Each precondition sets exactly one of ` + "`command`" + ` (a shell command that must exit 0), ` + "`file_exists`" + ` (a file or directory that must exist), or ` + "`port_free`" + ` (a localhost port nothing may be listening on), and optionally a ` + "`message`" + ` saying how to fix it. Commands and paths support parameter templates and resolve against the task's working directory; commands use the task's shell and environment and are given 30 seconds. Checks run in order after parameters are validated. The first failure stops the run without starting the command and returns ` + "`success: false`" + ` with an ` + "`error`" + ` starting "precondition failed" and a ` + "`precondition_failed`" + ` object: ` + "`index`" + `, ` + "`check`" + `, ` + "`target`" + `, ` + "`detail`" + `, ` + "`message`" + `, and, for commands, their ` + "`output`" + `.
+
+### Structured Output
+
+Tasks that print JSON can set ` + "`output_format: json`" + ` so agents don't have to parse text:
+
+` + "```yaml" + `
+tasks:
+  coverage:
+    description: "Report test coverage"
+    command: "./scripts/coverage.sh --json"
+    output_format: json
+` + "```" + `
+
+Stdout is parsed after the run and returned as ` + "`output`" + ` in place of ` + "`stdout`" + `, and the run_ tool's result also carries it as structured content. When stdout is empty or not valid JSON, ` + "`stdout`" + ` is returned as usual and ` + "`output_error`" + ` says why; the run's status is unaffected. Stderr is left alone, so scripts can log there. ` + "`runbook run <task> --output json`" + ` prints the same result as JSON.
+
+### Background Runs
+
+Pass ` + "`run_async: true`" + ` to a run_ tool to start a long task, such as a full test suite, without blocking the call. Tasks with ` + "`async: true`" + ` run this way by default, and ` + "`run_async: false`" + ` makes a call wait:
+
+` + "```yaml" + `
+tasks:
+  e2e:
+    description: "End-to-end tests"
+    command: "npm run e2e"
+    type: oneshot
+    async: true
+` + "```" + `
+
+The call returns at once with the run's ` + "`session_id`" + ` and ` + "`async: true`" + `. ` + "`task_status`" + ` reports whether the session is ` + "`running`" + ` or ` + "`completed`" + ` and how long it has taken, with ` + "`success`" + `, ` + "`status`" + `, and ` + "`exit_code`" + ` once it completes. ` + "`task_result`" + ` returns the finished run's result as the run_ tool would have; while the run is in progress it fails, unless ` + "`wait`" + ` (seconds, up to 300) gives the run time to finish. Background runs are queued, start required daemons, and count against rate limits like other runs. The server keeps the results of the last 100 finished background runs; sessions it did not run in the background are reported by ` + "`task_status`" + ` from their metadata.
+
+On the CLI, ` + "`runbook wait <session> [--timeout=SECONDS]`" + ` waits for a run to finish, prints its result, and exits non-zero if it failed. ` + "`runbook run`" + ` waits for ` + "`async`" + ` tasks unless given ` + "`--run_async=true`" + `.
+
+### Output Streams
+
+A session's ` + "`task.log`" + ` holds stdout and stderr interleaved in the order they were written. Oneshot runs also keep each stream in ` + "`stdout.log`" + ` and ` + "`stderr.log`" + ` next to it, as do daemons with ` + "`redact`" + ` or ` + "`log_max_size`" + `, whose output runbook copies; other daemons write their log themselves, so it keeps growing after the process that started them exits, and have no separate streams. Pass ` + "`stream: stderr`" + ` (or ` + "`stdout`" + `) to ` + "`read_session_log`" + ` or ` + "`logs_<task>`" + ` to read one stream; run_ results always return ` + "`stdout`" + ` and ` + "`stderr`" + ` separately. The CLI equivalent is ` + "`runbook logs <task> --stream stderr`" + `.
+
+### Comparing Sessions
+
+The ` + "`diff_sessions`" + ` tool diffs the logs of two sessions of the same task (IDs from ` + "`list_sessions`" + `), from the older to the newer, to answer "it passed an hour ago, what changed?". Timestamps, durations, UUIDs, and ANSI colors are ignored when comparing lines. The result has ` + "`added`" + ` and ` + "`removed`" + ` counts, unified-diff ` + "`hunks`" + ` with three lines of context, and ` + "`new_errors`" + `: added lines that mention an error, failure, panic, or exception. ` + "`max_lines`" + ` limits the hunk lines returned (default 200). The CLI equivalent is ` + "`runbook sessions diff <a> <b>`" + `.
+
+### Searching Logs
+
+The ` + "`search_logs`" + ` tool greps the logs of all sessions for a regex ` + "`pattern`" + `, newest session first, to answer "when did this error last occur?". ` + "`task_name`" + ` limits it to one task, and ` + "`since`" + ` / ` + "`until`" + ` bound the session start time (RFC 3339, ` + "`YYYY-MM-DD`" + `, or a duration like ` + "`24h`" + ` meaning that long ago). Each match has ` + "`session_id`" + `, ` + "`task_name`" + `, ` + "`start_time`" + `, ` + "`line`" + `, and ` + "`text`" + `; ` + "`limit`" + ` caps the matches (default 100) and ` + "`truncated`" + ` says whether more were found. The CLI equivalent is ` + "`runbook logs search <pattern>`" + `.
+
+### Session Resources
+
+The ` + "`session_info`" + ` tool shows how a session was run, for "why did this run differently?": the task, status, and exit code, the resolved ` + "`command`" + `, the full command line as ` + "`argv`" + ` (shell, flags, and command, or the docker invocation for ` + "`runner: docker`" + `), the ` + "`working_dir`" + `, the ` + "`parameters`" + `, and the process's ` + "`env`" + ` after defaults, task env, and env_policy. The command, command line, and environment pass through the task's redact patterns, and values of variables whose names contain TOKEN, SECRET, PASSWORD, PASSWD, CREDENTIAL, API_KEY, or PRIVATE_KEY are masked. With ` + "`compare_to`" + ` set to another session ID, ` + "`changes`" + ` lists each field that differs, e.g. ` + "`env.GOFLAGS`" + ` or ` + "`parameters.race`" + `, with its ` + "`before`" + ` (the compare_to session) and ` + "`after`" + ` values; a side is left out where the session did not have it. The same fields are in ` + "`read_session_metadata`" + `.
+
+Sessions can also be read as MCP resources, so an agent can cite a run as evidence by URI instead of calling tools. ` + "`runbook://sessions/<task>/latest`" + ` is the task's most recent session and ` + "`runbook://sessions/<id>`" + ` a session by ID. Each is a JSON object with the session's ` + "`metadata`" + ` (as ` + "`read_session_metadata`" + ` returns it), the last 50 lines of its ` + "`log`" + `, and the log's ` + "`total_lines`" + `.
+
+` + "`runbook://tasks/<name>`" + ` is a markdown page for one task, rendered when read: its description and type, the tools it exposes, a table of its parameters with types, required flags, and defaults, its presets, example MCP and CLI calls with the required parameters filled in, and its 5 most recent sessions as ` + "`runbook://sessions/<id>`" + ` links.
+
 ## Workflows
 
 **Optional.** Composite workflows that chain multiple oneshot tasks into a single MCP tool call.
@@ -564,6 +1013,8 @@ workflows:
 
 **Generated MCP Tool:** ` + "`run_workflow_ci`" + ` — description includes step names.
 
+On the CLI, ` + "`runbook workflow list`" + ` lists workflows, ` + "`runbook workflow run ci --test_flags=-race`" + ` runs one (and fails rather than running a task of the same name), and ` + "`runbook workflow show ci`" + ` prints its parameters and step graph: nested workflows expanded, each step's retries, timeout, and required daemons resolved against its task, and the earlier steps it reads from. ` + "`--output json`" + ` prints the graph as JSON.
+
 ### Workflow Fields
 
 | Field | Required | Type | Description |
@@ -579,16 +1030,125 @@ workflows:
 
 | Field | Required | Type | Description |
 |-------|----------|------|-------------|
-| task | Yes | string | Name of an existing oneshot task |
-| params | No | map | Parameter overrides — values can use ` + "`{{.param}}`" + ` to reference workflow parameters |
+| id | No | string | Name for referencing this step's output (default: the task name) |
+| task | Yes* | string | Name of an existing oneshot task |
+| workflow | Yes* | string | Name of another workflow to run instead of a task (see Nested Workflows) |
+| params | No | map | Parameter overrides — values can use ` + "`{{.param}}`" + ` to reference workflow parameters and ` + "`{{ steps.<id>.stdout }}`" + ` to reference earlier step output |
 | continue_on_failure | No | bool | If true, pipeline continues when step fails (default: false) |
+| requires_daemon | No | []string | Daemons to start and wait on before this step runs |
+| project | No | string | Run ` + "`task`" + ` from this sibling project instead of the local manifest |
+| retries | No | int | Extra attempts when the step fails (default: the task's ` + "`retries`" + `) |
+| retry_delay | No | int | Seconds to wait between attempts (default: the task's ` + "`retry_delay`" + `) |
+| when | No | string | Template expression; the step is skipped when it renders empty, ` + "`false`" + `, or ` + "`0`" + ` (see Conditional Steps) |
+| fresh | No | bool | Restart the required daemons from a clean slate before the step, even if running (see Fresh Starts) |
+| timeout | No | int | Seconds the step may run, replacing the task's ` + "`timeout`" + ` |
+| wait | Yes* | object | Built-in step that sleeps for ` + "`seconds`" + ` (see Wait Steps) |
+| wait_for | Yes* | object | Built-in step that polls a ` + "`url`" + `, ` + "`port`" + `, or ` + "`file`" + ` until ready (see Wait Steps) |
+
+*Each step sets exactly one of ` + "`task`" + `, ` + "`workflow`" + `, ` + "`wait`" + `, or ` + "`wait_for`" + `.
 
 ### Behavior
 
 - Steps run sequentially. Failure stops the pipeline unless ` + "`continue_on_failure: true`" + `.
 - Only oneshot tasks can be referenced — daemon tasks are not allowed.
 - Each step gets its own session ID and logs.
-- If ` + "`timeout`" + ` is set and exceeded, remaining steps are marked as skipped.
+- If the workflow's ` + "`timeout`" + ` is exceeded, the running step is interrupted and the remaining steps are marked as skipped.
+- A step's ` + "`timeout`" + ` bounds that step alone; a step over it is killed and reported as timed out.
+- A failed step with ` + "`retries`" + ` runs again after ` + "`retry_delay`" + ` seconds, up to ` + "`retries`" + ` more times. The step result's ` + "`attempts`" + ` counts every run, the step's ` + "`result`" + ` is the last one, and each attempt's session records its number as ` + "`attempt`" + ` in its metadata.
+
+### Step Output
+
+Step params can use the output of an earlier step with ` + "`{{ steps.<id>.stdout }}`" + `, ` + "`{{ steps.<id>.stderr }}`" + `, or ` + "`{{ steps.<id>.exit_code }}`" + `. Trailing whitespace is trimmed, so a command that prints one line yields just that line:
+
+` + "```yaml" + `
+workflows:
+  release:
+    description: "Tag the current version"
+    steps:
+      - task: get_version
+      - task: tag
+        params:
+          version: "{{ steps.get_version.stdout }}"
+` + "```" + `
+
+A step's id defaults to its task name; set ` + "`id`" + ` when a workflow runs the same task twice. References must name a step that runs earlier, and resolve to an empty string if that step failed to start.
+
+### Conditional Steps
+
+A step with ` + "`when`" + ` only runs if the expression renders to something other than empty, ` + "`false`" + `, or ` + "`0`" + `. It is a Go template evaluated against the workflow parameters (` + "`{{ .env }}`" + `) and ` + "`.steps`" + `, which has ` + "`success`" + `, ` + "`exit_code`" + `, ` + "`stdout`" + `, ` + "`stderr`" + `, and ` + "`skipped`" + ` for each earlier step by id:
+
+` + "```yaml" + `
+workflows:
+  ci:
+    description: "Test, then deploy to prod or report failures"
+    parameters:
+      env:
+        type: string
+        default: "staging"
+    steps:
+      - task: test
+        continue_on_failure: true
+      - task: report_failure
+        when: "{{ not .steps.test.success }}"
+      - task: deploy
+        when: "{{ and .steps.test.success (eq .env \"prod\") }}"
+` + "```" + `
+
+A step whose condition is false is marked ` + "`skipped`" + ` with a ` + "`skip_reason`" + ` and does not count as a failure. Use ` + "`{{ index .steps \"step-id\" }}`" + ` for ids with hyphens. An expression that fails to evaluate fails the step.
+
+### Nested Workflows
+
+A step with ` + "`workflow`" + ` runs another workflow, so a common sub-pipeline is defined once and reused:
+
+` + "```yaml" + `
+workflows:
+  smoke_tests:
+    description: "Quick checks"
+    parameters:
+      target:
+        type: string
+        required: true
+    steps:
+      - task: unit
+        params:
+          target: "{{.target}}"
+      - task: e2e
+
+  release:
+    description: "Smoke test, then deploy"
+    steps:
+      - workflow: smoke_tests
+        params:
+          target: "api"
+      - task: deploy
+` + "```" + `
+
+The step's params are the nested workflow's parameters. The nested workflow counts as one step: it succeeds if the nested workflow does, its ` + "`stdout`" + ` and ` + "`stderr`" + ` combine those of its steps, and its ` + "`exit_code`" + ` is its last step's. The step's id defaults to the workflow name. ` + "`requires_daemon`" + `, ` + "`retries`" + `, ` + "`when`" + `, and ` + "`continue_on_failure`" + ` work as on task steps; ` + "`project`" + ` does not. Workflows that nest each other in a cycle are rejected when the config is loaded.
+
+### Wait Steps
+
+` + "`wait`" + ` and ` + "`wait_for`" + ` steps are run by the workflow itself, so pausing for a service needs no shell task:
+
+` + "```yaml" + `
+    steps:
+      - task: start_db
+      - wait_for:
+          port: 5432
+          timeout: 60
+      - wait:
+          seconds: 2
+      - task: migrate
+` + "```" + `
+
+| Field | Type | Description |
+|-------|------|-------------|
+| wait.seconds | number | How long to sleep; must be positive |
+| wait_for.url | string | Ready once it responds with a non-5xx status |
+| wait_for.port | int | Ready once localhost accepts TCP connections on the port |
+| wait_for.file | string | Ready once the file exists; relative paths resolve against the workflow's working directory |
+| wait_for.timeout | int | Seconds to poll before the step fails (default: 30) |
+
+When several ` + "`wait_for`" + ` conditions are set, all must hold. ` + "`url`" + ` and ` + "`file`" + ` are templates over the same data as ` + "`when`" + `. The step's id defaults to ` + "`wait`" + ` or ` + "`wait_for`" + `. ` + "`when`" + `, ` + "`continue_on_failure`" + `, ` + "`requires_daemon`" + `, and ` + "`timeout`" + ` work as on task steps; ` + "`params`" + `, ` + "`project`" + `, ` + "`retries`" + `, and ` + "`fresh`" + ` are rejected.
 
 ## Task Groups
 
@@ -624,10 +1184,28 @@ Task groups are exposed as the ` + "`dev-workflow://task-groups`" + ` MCP resour
 | description | Yes | string | Human-readable description shown in MCP |
 | content | No* | string | Inline prompt content (supports templates) |
 | file | No* | string | Path to file containing prompt content (supports templates) |
+| content_by_locale | No | map | Content per locale, e.g. ` + "`de`" + ` or ` + "`pt-BR`" + ` (supports templates) |
 | disabled | No | bool | If true, hidden from MCP entirely |
 
 *Either ` + "`content`" + ` or ` + "`file`" + ` must be provided.
 
+### Localized Prompts
+
+Prompts with ` + "`content_by_locale`" + ` take an optional ` + "`locale`" + ` argument. The client's locale is used if the prompt has content for it, then ` + "`server.locale`" + `, then ` + "`content`" + ` or ` + "`file`" + `. Locales match case-insensitively, ` + "`_`" + ` and ` + "`-`" + ` are interchangeable, and a regional locale falls back to its language (` + "`de-AT`" + ` uses ` + "`de`" + `).
+
+` + "```yaml" + `
+server:
+  locale: de
+
+prompts:
+  review:
+    description: "Review the current change"
+    content: "Review the diff and run {{.Tasks.test.Run}}."
+    content_by_locale:
+      de: "Prüfe den Diff und führe {{.Tasks.test.Run}} aus."
+      ja: "差分を確認し、{{.Tasks.test.Run}} を実行してください。"
+` + "```" + `
+
 ### Template Methods
 
 Prompts support ` + "`{{.Tasks.<name>.<method>}}`" + ` expressions:
@@ -667,6 +1245,30 @@ prompts:
     disabled: true
 ` + "```" + `
 
+### Partials
+
+Shared boilerplate is defined once under ` + "`prompt_partials`" + ` and included with ` + "`{{partial \"name\"}}`" + ` in prompts, custom resources, server instructions, and other partials:
+
+` + "```yaml" + `
+prompt_partials:
+  safety:
+    content: "Never push to main. Ask before deleting files."
+  conventions:
+    file: "docs/conventions.md"   # relative to the manifest
+    # Partials are templates too
+    # content: "Run {{.Tasks.lint.Run}} before committing. {{partial \"safety\"}}"
+
+prompts:
+  review:
+    description: "Review the current change"
+    content: |
+      Review the diff for bugs.
+
+      {{partial "conventions"}}
+` + "```" + `
+
+Partials render with the same template data as the content that includes them. Each needs ` + "`content`" + ` or ` + "`file`" + `. Including an undefined partial from inline content is a validation error, and a partial cannot include itself. Partial names are shared across imports.
+
 See the Template System documentation for full template syntax.
 
 ## Custom Resources
@@ -693,6 +1295,10 @@ Custom resources are available at ` + "`runbook://custom/<name>`" + `. For examp
 
 Resource content supports the same ` + "`{{.Tasks.<name>.<method>}}`" + ` template syntax as prompts.
 
+### Caching and Conditional Reads
+
+File-backed prompts and resources are rendered once and cached until the file's modification time or size changes, or the config is refreshed. Every resource read returns an ` + "`etag`" + ` in the contents' ` + "`_meta`" + `. A client that passes it back as the ` + "`if_none_match`" + ` argument of ` + "`resources/read`" + ` gets empty text and ` + "`_meta.not_modified: true`" + ` while the content is unchanged.
+
 ### Example
 
 ` + "```yaml" + `
@@ -712,25 +1318,703 @@ resources:
     mime_type: "text/markdown"
 ` + "```" + `
 
-## Disabling and Visibility
+## Prompt and Resource Directories
 
-Items can be hidden from MCP (and optionally the CLI) using ` + "`disabled`" + ` and ` + "`disable_mcp`" + ` flags.
+**Optional.** Instead of listing every file, ` + "`prompts_dir`" + ` and ` + "`resources_dir`" + ` load each markdown file (` + "`.md`" + ` or ` + "`.markdown`" + `) directly in a directory as a prompt or resource. Directories are relative to the manifest file that names them, so an imported file can bring its own:
 
-### Flag Semantics
+` + "```yaml" + `
+prompts_dir: docs/prompts      # docs/prompts/onboard.md becomes the prompt "onboard"
+resources_dir: docs/guides     # docs/guides/api.md becomes runbook://custom/api
+` + "```" + `
 
-| Flag | Effect |
-|------|--------|
-| ` + "`disabled: true`" + ` | Hidden from both MCP and CLI entirely |
-| ` + "`disable_mcp: true`" + ` | Hidden from MCP only; CLI can still run it |
+A file is named after its file name without the extension. Optional YAML front matter between ` + "`---`" + ` lines sets its ` + "`description`" + ` (default: the file's first ` + "`# `" + ` heading), ` + "`disabled`" + `, and, for resources, ` + "`mime_type`" + `:
 
-### Supported Per Item Type
+` + "```markdown" + `
+---
+description: Onboard a new contributor
+---
+Start with {{.Tasks.setup.Run}}, then read the architecture resource.
+` + "```" + `
 
-| Item type | ` + "`disabled`" + ` | ` + "`disable_mcp`" + ` |
-|-----------|-----------|---------------|
-| tasks | yes | yes |
-| workflows | yes | yes |
-| prompts | yes | — |
-| resources | yes | — |
+The rest of the file is the content and supports templates. A prompt or resource defined by name in the manifest keeps its definition over a file of the same name. Files are read when the config is loaded; call ` + "`refresh_config`" + ` after adding or editing them.
+
+## Daemon Readiness
+
+A oneshot task, daemon, or workflow step can list daemons in ` + "`requires_daemon`" + `. Before running, each listed daemon is started if it is not already running, and the run waits until the daemon's ` + "`ready`" + ` condition passes. If the daemon exits or is not ready within the timeout, the task fails without running.
+
+` + "```yaml" + `
+tasks:
+  dev:
+    description: "Start dev server"
+    command: "npm run dev"
+    type: daemon
+    ready:
+      port: 3000                  # TCP port accepting connections on localhost
+      log_pattern: "ready in"     # Regex matched against the daemon's log
+      timeout: 60                 # Seconds to wait (default 30)
+
+  e2e:
+    description: "Run end-to-end tests"
+    command: "npm run e2e"
+    type: oneshot
+    requires_daemon: [dev]
+` + "```" + `
+
+### Ready Fields
+
+| Field | Type | Description |
+|-------|------|-------------|
+| log_pattern | string | Regex that must match the daemon's session log |
+| port | int | TCP port on localhost that must accept connections |
+| url | string | URL that must respond with a non-5xx status |
+| command | string | Shell command that must exit 0 |
+| timeout | int | Seconds to wait for all conditions (default 30) |
+
+All configured conditions must pass. A daemon without ` + "`ready`" + ` is considered ready as soon as it is running. The result of the task lists any daemons it started in ` + "`daemons_started`" + `.
+
+### Fresh Starts
+
+Pass ` + "`fresh: true`" + ` to a ` + "`start_`" + ` tool (or ` + "`runbook start <task> --fresh`" + `) to restart a daemon from a clean slate in one call: it is stopped if running, its previous session's ` + "`latest`" + ` log link is cleared, and it is started and waited on until ready. If it does not become ready it is stopped again and the call fails, so no half-restarted daemon is left behind. The result sets ` + "`restarted`" + ` when a running instance was stopped.
+
+A workflow step with ` + "`fresh: true`" + ` restarts its required daemons (the step's and its task's ` + "`requires_daemon`" + `) this way before it runs, so tests always see a new instance.
+
+### Bulk Daemon Tools
+
+When any daemon is exposed over MCP, three tools act on all of them at once and return a result per daemon:
+
+| Tool | Description |
+|------|-------------|
+| stop_all_daemons | Stop every running daemon, each before the daemons it requires |
+| restart_all_daemons | Stop every running daemon, then start them again in dependency order with the parameters they were started with |
+| status_all | Status of every daemon, with counts of running and total |
+
+Each takes an optional ` + "`tasks`" + ` list to act on only some daemons. Daemons that are not running are skipped. Restarting a running daemon with ` + "`requires_confirmation`" + ` needs confirmation. The CLI equivalents are ` + "`runbook stop --all`" + `, ` + "`runbook restart --all`" + `, and ` + "`runbook status --all`" + `.
+
+## Vars
+
+**Optional.** ` + "`vars`" + ` names values used across many tasks, such as registry URLs and image names. Command, prompt, resource, and server instructions templates read them as ` + "`{{.Vars.name}}`" + `:
+
+` + "```yaml" + `
+vars:
+  registry: '{{env "REGISTRY" | default "ghcr.io/acme"}}'
+  image: api
+
+tasks:
+  push:
+    description: "Push the API image"
+    command: "docker push {{.Vars.registry}}/{{.Vars.image}}:{{.tag | shellquote}}"
+    type: oneshot
+    parameters:
+      tag:
+        type: string
+        required: true
+` + "```" + `
+
+Var values are templates with the built-in functions, so they can read the environment with ` + "`env`" + `; they are resolved each time a template is rendered and cannot refer to other vars. Names use letters, digits, and underscores. Imported manifests may add vars but not redefine one, and no task parameter may be named ` + "`Vars`" + `. Vars are not recorded as session parameters.
+
+## Task Templates
+
+**Optional.** ` + "`task_templates`" + ` defines shared task fields once. A task (or another template) declares ` + "`extends: <template>`" + ` to inherit every field it does not set itself. ` + "`env`" + ` and ` + "`parameters`" + ` are merged key by key, with the task's entries winning.
+
+` + "```yaml" + `
+task_templates:
+  node:
+    shell: /bin/bash
+    timeout: 600
+    env:
+      NODE_ENV: development
+    parameters:
+      args:
+        type: string
+        description: "Extra arguments"
+
+tasks:
+  test:
+    description: "Run tests"
+    command: "npm test {{.args}}"
+    extends: node
+    env:
+      NODE_ENV: test
+` + "```" + `
+
+Templates use the same fields as tasks and are applied before ` + "`defaults`" + `, so template values take precedence over manifest defaults.
+
+## Imports
+
+` + "`imports`" + ` merges other manifest files into this one. Entries can be local paths or globs (relative to the importing file), ` + "`https://`" + ` URLs, or git references:
+
+` + "```yaml" + `
+imports:
+  - "shared/*.yaml"
+  - "https://example.com/runbook/go.yaml"
+  - "git::https://github.com/org/runbook-lib.git//go/tasks.yaml?ref=v1.2.0"
+` + "```" + `
+
+Git references use ` + "`git::<repo>[//<path>][?ref=<branch or tag>]`" + `; the path defaults to ` + "`*.yaml`" + ` at the repository root and may be a glob. Relative imports inside a git fragment resolve within the repository.
+
+Imports contribute tasks, workflows, prompts, resources, and the like. ` + "`exec`" + ` and ` + "`server`" + ` settings are only read from the project's own manifest (or the top-level files of its config directory), so an imported file cannot enable ` + "`exec_command`" + ` or task edits, or change auth and TLS.
+
+Remote imports are cached under the project's ` + "`._runbook_state/imports/`" + ` and pinned in its ` + "`.runbook.lock`" + ` (content hash for URLs, commit for git). A git path may not leave the repository with ` + "`..`" + `, and URL content is limited to 10 MB. Commit the lockfile so every checkout uses the same content. Run ` + "`runbook update-imports`" + ` to fetch the latest versions and rewrite it.
+
+## Adapters
+
+**Optional.** ` + "`adapters`" + ` turns the targets of a Makefile and the scripts of a package.json into oneshot tasks, so a project gets tools without writing YAML for each one:
+
+` + "```yaml" + `
+adapters:
+  make:
+    file: Makefile          # default; relative to the project root
+    prefix: "make_"         # default; "" imports targets under their own names
+    exclude: [clean]        # glob patterns to skip
+  npm:
+    file: web/package.json  # default: package.json
+    include: ["test*", build]  # glob patterns to import (default: all)
+    client: pnpm            # npm (default), pnpm, yarn, or bun
+` + "```" + `
+
+Each target becomes a task such as ` + "`make_build`" + ` running ` + "`make build`" + `, described by a ` + "`## text`" + ` comment after the target's prerequisites or the comment lines above it. Special targets like ` + "`.PHONY`" + `, pattern rules, and targets built from variables are skipped. Each script becomes a task such as ` + "`npm_test`" + ` running ` + "`npm run test`" + `, described by the script's command; install and publish lifecycle scripts and the ` + "`pre`" + `/` + "`post`" + ` hooks of other scripts are skipped. Characters other than letters, digits, ` + "`_`" + `, and ` + "`-`" + ` in names become ` + "`_`" + `. Tasks run in the directory of their file.
+
+The files are read again whenever the config is loaded, so ` + "`refresh_config`" + ` picks up new targets and scripts. Tasks defined in the manifest win over imported ones with the same name, and defaults, overrides, and task groups apply to imported tasks as usual. A missing or unreadable file is a config error.
+
+## OS Overlays
+
+A manifest file can adjust itself per operating system with a top-level ` + "`overlays`" + ` section, so cross-platform teams keep one manifest:
+
+` + "```yaml" + `
+tasks:
+  build:
+    description: "Build"
+    command: "make build"
+  open_docs:
+    description: "Open the docs"
+    command: "xdg-open docs/index.html"
+
+overlays:
+  darwin:
+    tasks:
+      open_docs:
+        command: "open docs/index.html"
+  windows:
+    defaults:
+      shell: "pwsh -Command"
+    tasks:
+      build:
+        command: "nmake build"
+      open_docs:
+        command: "start docs/index.html"
+` + "```" + `
+
+Each overlay is keyed by an OS name as Go reports it (` + "`linux`" + `, ` + "`darwin`" + `, ` + "`windows`" + `, ` + "`freebsd`" + `, ` + "`openbsd`" + `, or ` + "`netbsd`" + `) and is a partial manifest. When the file is loaded, the overlay for the current OS is merged into it before imports, templates, and defaults are applied: mappings merge key by key, and any other value, including a list, replaces the file's. Overlays can add tasks or imports as well as change them. An overlay applies only to the file it is in.
+
+## Cross-Project Tasks
+
+A task or workflow step can use a task defined in another project's ` + "`.runbook/`" + ` directory when the project runs on a multi-project server (` + "`server.projects`" + ` is set, or it is one of the hosted projects). The project must be listed in ` + "`allowed_projects`" + `; paths are relative to the project root.
+
+` + "```yaml" + `
+server:
+  projects:
+    infra: ../shared-infra
+
+allowed_projects:
+  - ../shared-infra
+
+tasks:
+  db:
+    description: "Shared database"
+    project: ../shared-infra
+    task: start-db            # Daemon defined in ../shared-infra/.runbook/
+
+  integration:
+    description: "Run integration tests"
+    command: "go test -tags integration ./..."
+    requires_daemon: [db]
+
+workflows:
+  ci:
+    description: "Migrate and test"
+    steps:
+      - project: ../shared-infra
+        task: migrate
+      - task: integration
+` + "```" + `
+
+A referencing task takes the command, type, parameters, and other settings of the sibling task, and runs in the sibling project's directory. Its own ` + "`description`" + `, ` + "`requires_daemon`" + `, and visibility flags apply. Daemons started this way are tracked by the current project.
+
+## Ad-hoc Commands
+
+**Optional.** The ` + "`exec`" + ` section enables the ` + "`shell_exec`" + ` MCP tool (also registered as ` + "`exec_command`" + `), which runs an arbitrary shell command without a task definition. It is disabled by default.
+
+` + "```yaml" + `
+exec:
+  enabled: true   # Register the shell_exec tool
+  timeout: 60     # Default timeout in seconds (falls back to defaults.timeout)
+  max_timeout: 300 # Largest timeout a call may request (falls back to defaults.max_timeout, then 3600)
+  shell: "/bin/sh" # Shell to use (falls back to defaults.shell)
+  rate_limit: 10  # Calls per minute (default 30, -1 = unlimited)
+  requires_confirmation: true # Calls need a confirmation token
+  allow:          # If set, commands must match one of these regexes
+    - "^(git|ls|cat|grep) "
+  deny:           # Commands matching any of these regexes are rejected
+    - "\\brm\\b"
+  allowed_working_directories: # If set, working_directory must match one of these globs
+    - "./services/*"
+` + "```" + `
+
+Ad-hoc commands are logged as sessions under the task name ` + "`exec`" + ` and receive ` + "`defaults.env`" + `. The command is not treated as a template. Deny patterns are checked first. Calls over the rate limit fail with the time until the next call is allowed; ` + "`shell_exec`" + ` and ` + "`exec_command`" + ` share one limit. A ` + "`timeout`" + ` argument is capped at ` + "`max_timeout`" + `, and a ` + "`working_directory`" + ` outside ` + "`allowed_working_directories`" + ` fails with ` + "`PERMISSION`" + `. The ` + "`runbook exec <command...>`" + ` CLI command is always available and is not subject to the allow/deny patterns or the rate limit.
+
+## Reloading Config
+
+The ` + "`refresh_config`" + ` tool reloads the config from disk and re-registers tools, resources, and prompts without restarting the server; running daemons keep running. Its result reports how the tools changed:
+
+` + "```json" + `
+{
+  "success": true,
+  "tools": {
+    "added": ["run_vet"],
+    "removed": ["run_lint"],
+    "changed": [{"name": "run_build", "fields": ["description"]}]
+  }
+}
+` + "```" + `
+
+A changed tool lists which of ` + "`description`" + `, ` + "`input_schema`" + `, and ` + "`annotations`" + ` differ. Re-read the definitions of added and changed tools rather than relying on a cached tool list. Every reload that changes the tools, including those after task edits or project registration, is also logged by the server.
+
+## Linting Config
+
+The ` + "`lint_config`" + ` tool loads the config from disk (or ` + "`path`" + `) and returns ` + "`warnings`" + ` about config that is valid but likely to cause trouble: string parameters interpolated unsafely, descriptions that only repeat a name, oneshot tasks without a ` + "`timeout`" + `, parameters the command never uses, daemon commands that detach (` + "`nohup`" + `, a trailing ` + "`&`" + `, ` + "`docker run -d`" + `) so a stop cannot reach them, tasks that run the same command, and task groups that list disabled tasks. An invalid config returns ` + "`valid: false`" + ` and its ` + "`error`" + `. Call it after editing the config and before ` + "`refresh_config`" + `. The CLI equivalent is ` + "`runbook lint`" + `.
+
+## Editing Tasks from MCP
+
+**Optional.** With ` + "`server.allow_task_edits: true`" + `, the server offers ` + "`add_task`" + `, ` + "`update_task`" + `, and ` + "`remove_task`" + `, so an agent can save a command it discovered as a task:
+
+` + "```yaml" + `
+server:
+  allow_task_edits: true
+` + "```" + `
+
+` + "`definition`" + ` takes task fields by their config keys. ` + "`update_task`" + ` replaces only the given fields, and a null value removes one. New tasks are written to ` + "`tasks.yaml`" + ` (or the first config file); existing ones are edited in the file that defines them, keeping comments. Each edit is validated by loading the whole config: if it fails, the file is restored and the error returned. Otherwise tools are reloaded immediately. Tasks from imports cannot be edited. Over HTTP, the tools require ` + "`server.auth`" + ` and report the caller as ` + "`edited_by`" + `.
+
+## Graceful Shutdown
+
+**Optional.** On SIGINT or SIGTERM, ` + "`runbook serve`" + ` refuses new tool calls and waits for in-flight ones (oneshot runs, workflows) to finish, so their results are returned and their session metadata is written. It then stops the HTTP server and its daemons.
+
+` + "```yaml" + `
+server:
+  shutdown_grace: 60   # Seconds to wait for in-flight calls and async runs (default 30, -1 = don't wait)
+` + "```" + `
+
+Calls still running when the grace period ends are abandoned.
+
+Daemons are then stopped in reverse dependency order: daemons that no running daemon requires are stopped together, then the daemons they required, and so on. Each group shares one grace window after the stop signal (` + "`defaults.stop_grace`" + `, default 5 seconds, or the longest ` + "`stop_grace_period`" + ` in the group) before the remaining processes are killed, so shutting down many daemons takes about one grace period per level of ` + "`requires_daemon`" + ` rather than one per daemon. ` + "`stop_all_daemons`" + ` and ` + "`runbook stop --all`" + ` stop daemons the same way.
+
+Only one server runs per project. ` + "`runbook serve`" + ` refuses to start while another server for the same project is alive; ` + "`runbook serve --replace`" + ` shuts the old one down this way, except that its daemons keep running and are adopted by the new server. Each server holds an OS file lock on ` + "`._runbook_state/server.lock`" + ` for as long as it runs, so a second server started before the first has registered still refuses to start. The OS drops the lock when the server exits, even if it was killed; the PID in the file only names the holder.
+
+The server is registered in ` + "`._runbook_state/server.json`" + ` with mode 0600, together with a random token generated at startup. The stdio proxy and the CLI send the server a nonce at ` + "`GET /handshake`" + ` and check that the answer is an HMAC of it keyed with the token before connecting, and again on every reconnect. A process that answers at the registered port without the token is refused, so another user on a shared host cannot hijack the proxy. The handshake is the one endpoint that is not checked by ` + "`server.auth`" + `. Without ` + "`server.auth`" + `, requests for ` + "`/metrics`" + ` and the dashboard's data under ` + "`/ui/api/`" + ` must carry the token in the ` + "`X-Runbook-Server-Token`" + ` header and get 401 otherwise; ` + "`runbook serve`" + ` prints the dashboard URL with the token in its fragment, and the page sends it along. MCP and REST API requests need no credentials without ` + "`server.auth`" + `, so set it before exposing the server beyond the local machine. The agent API checks its own token. The CLI and proxy verify TLS servers with the CA in ` + "`$RUNBOOK_CA_CERT`" + `.
+
+## Server Metadata
+
+**Optional.** Customizes what the server advertises to MCP clients during initialize.
+
+` + "```yaml" + `
+server:
+  name: acme-api            # Server name (default: runbook)
+  version_suffix: "+acme"   # Appended to the runbook version
+  locale: de                # Default locale for prompts with content_by_locale
+  instructions: |
+    This is the acme API. Run {{.Tasks.test.Run}} before committing and
+    start the dev server with {{.Tasks.dev.Start}}.
+` + "```" + `
+
+Instructions use the same templates as prompts and are sent to every connecting client, so agents start with project-specific guidance. Server metadata is read when the server starts; restart it to apply changes.
+
+### Capabilities
+
+` + "`server.capabilities`" + ` lists the MCP capabilities the server advertises: ` + "`tools`" + `, ` + "`prompts`" + `, ` + "`resources`" + `, and ` + "`logging`" + `. The default is tools, prompts, and resources. Prompts and resources that are not advertised are not registered, so a tools-only deployment presents nothing else for clients to probe:
+
+` + "```yaml" + `
+server:
+  capabilities: [tools]
+` + "```" + `
+
+Tools are always advertised. ` + "`logging`" + ` only lets clients set a log level. Completion is not implemented and never advertised. Like the rest of the server metadata, capabilities are read when the server starts.
+
+## Multi-Project Servers
+
+**Optional.** One ` + "`runbook serve`" + ` instance can host several projects. List them under ` + "`server.projects`" + ` (paths relative to the server's working directory), or add them at runtime with the ` + "`register_project`" + ` tool, which is available in HTTP mode:
+
+` + "```yaml" + `
+server:
+  projects:
+    api: ../api
+    web: ../web
+` + "```" + `
+
+Each project's tasks, workflows, and task groups are added with names prefixed by the project name and ` + "`__`" + `, so ` + "`test`" + ` in the api project is exposed as ` + "`run_api__test`" + `. References between them (depends_on, requires_daemon, workflow steps) are rewritten to match, and tasks run in their project's directory unless they set working_directory. Prompts and resources come only from the server's own config. Session logs are stored in the server's state directory.
+
+From the CLI, select a project with ` + "`--project`" + `: ` + "`runbook run test --project api`" + ` runs ` + "`api__test`" + `, and ` + "`runbook list --project api`" + ` lists only that project.
+
+## REST API
+
+In HTTP mode (` + "`runbook serve`" + `), a small REST API sits on the same listener as the MCP endpoint, for scripts and CI that don't speak JSON-RPC:
+
+| Endpoint | Description |
+|----------|-------------|
+| ` + "`POST /api/tasks/{name}/run`" + ` | Runs a oneshot task. The optional JSON body holds the ` + "`run_`" + ` tool's arguments; the response is its result |
+| ` + "`GET /api/daemons`" + ` | Status of every daemon, as ` + "`status_all`" + ` returns it |
+| ` + "`GET /api/sessions/{id}/logs`" + ` | A session's log, as ` + "`read_session_log`" + ` returns it; takes ` + "`lines`" + `, ` + "`offset`" + `, ` + "`filter`" + `, and ` + "`stream`" + ` query parameters |
+
+` + "```bash" + `
+curl -X POST localhost:8080/api/tasks/test/run -d '{"package": "./internal/..."}'
+` + "```" + `
+
+A task that ran and failed still returns 200 with ` + "`success: false`" + `; rejected calls (bad arguments, unknown session) return 400 and unknown tasks 404, each with an ` + "`error`" + ` message. Tasks that require confirmation answer with a ` + "`confirmation_token`" + ` to send back in the body.
+
+## Authentication
+
+**Optional.** In HTTP mode (` + "`runbook serve`" + `), every endpoint — MCP, REST API, dashboard, and metrics — can require authentication. Pick one type under ` + "`server.auth`" + `:
+
+` + "```yaml" + `
+server:
+  auth:
+    type: bearer            # bearer, mtls, command, or webhook
+    token_env: RUNBOOK_TOKEN  # bearer: env var holding the token (default)
+  tls:
+    cert_file: server.pem
+    key_file: server-key.pem
+    client_ca_file: ca.pem  # require client certificates signed by this CA
+` + "```" + `
+
+- **bearer**: requests must send ` + "`Authorization: Bearer <token>`" + ` matching the env var. The CLI, stdio proxy, and agents read the token from the same env var, named by the project's ` + "`token_env`" + `, and send it when set.
+- **mtls**: requires ` + "`tls.client_ca_file`" + `. ` + "`allowed_subjects`" + ` limits accepted certificate common names.
+- **command**: runs ` + "`command`" + ` with RUNBOOK_AUTH_TOKEN, RUNBOOK_AUTH_HEADER, RUNBOOK_AUTH_METHOD, RUNBOOK_AUTH_PATH, and RUNBOOK_AUTH_REMOTE_ADDR set. Exit 0 allows the request; the first stdout line names the caller.
+- **webhook**: POSTs the request's method, path, remote_addr, and headers as JSON to ` + "`url`" + `. A 2xx response allows it and may return ` + "`{\"subject\": \"...\"}`" + `.
+
+Clients connecting to a server with TLS trust the CA in ` + "`$RUNBOOK_CA_CERT`" + ` (default: the system roots) and, for ` + "`mtls`" + `, present the certificate in ` + "`$RUNBOOK_CLIENT_CERT`" + ` and ` + "`$RUNBOOK_CLIENT_KEY`" + `.
+
+Command and webhook decisions time out after ` + "`timeout`" + ` seconds (default 5). Rejected requests get 401. Auth and TLS settings are read when the server starts.
+
+## Confirmation Gates
+
+**Optional.** Set ` + "`requires_confirmation: true`" + ` on destructive tasks such as deploys or database resets:
+
+` + "```yaml" + `
+tasks:
+  db-reset:
+    description: "Drop and recreate the dev database"
+    command: "make db-reset"
+    type: oneshot
+    requires_confirmation: true
+` + "```" + `
+
+Over MCP, the first call does not run the task. It returns ` + "`confirmation_required: true`" + `, a ` + "`preview`" + ` of the command, and a ` + "`confirmation_token`" + `. Once the user approves, the agent calls the tool again with the same arguments plus the token. Tokens are single-use, bound to the tool and arguments, and expire after 5 minutes. Workflows with a step that requires confirmation are gated the same way, as are ` + "`start_`" + ` tools for daemons.
+
+The CLI shows the command and asks before running. Pass ` + "`--yes`" + ` to skip the prompt; without a terminal and without ` + "`--yes`" + `, the task is refused.
+
+## Tool Hints
+
+**Optional.** Set ` + "`hints`" + ` to tell MCP clients what a task does, so they can auto-approve safe calls and warn before risky ones:
+
+` + "```yaml" + `
+tasks:
+  test:
+    description: "Run the test suite"
+    command: "go test ./..."
+    type: oneshot
+    hints:
+      read_only: true    # Changes nothing (readOnlyHint)
+      idempotent: true   # Repeating a call has no further effect (idempotentHint)
+  db-reset:
+    description: "Drop and recreate the dev database"
+    command: "make db-reset"
+    type: oneshot
+    hints:
+      destructive: true  # Deletes or overwrites data (destructiveHint)
+` + "```" + `
+
+Hints become the annotations of the task's ` + "`run_`" + ` or ` + "`start_`" + ` tool; hints left out are not sent, and ` + "`read_only`" + ` and ` + "`destructive`" + ` cannot both be true. A workflow's tool is read-only or idempotent when every task it runs is, and destructive when any is. Hints are advice to the client; they do not stop a call (use ` + "`requires_confirmation`" + ` for that).
+
+## Rate Limits
+
+**Optional.** Set ` + "`rate_limit`" + ` on expensive tasks so an agent cannot run them more often than intended:
+
+` + "```yaml" + `
+tasks:
+  deploy:
+    description: "Deploy to staging"
+    command: "./deploy.sh"
+    type: oneshot
+    rate_limit:
+      max_runs: 2   # Runs allowed...
+      per: 1h       # ...in any window this long (a duration: 30s, 10m, 1h)
+` + "```" + `
+
+Runs of oneshot tasks and starts of daemons are counted over a sliding window. A call over the limit fails without running, with an ` + "`error`" + ` saying the task is rate limited and ` + "`retry_after`" + `, the seconds until the oldest counted run leaves the window. Counts are kept by the running server, survive ` + "`refresh_config`" + `, and start over when the server restarts. Workflow steps and daemons started through ` + "`requires_daemon`" + ` are not counted.
+
+## Result Cache
+
+**Optional.** Set ` + "`cache`" + ` on oneshot tasks whose result only depends on their inputs, so repeated calls return at once:
+
+` + "```yaml" + `
+tasks:
+  lint:
+    description: "Run the linters"
+    command: "golangci-lint run ./..."
+    type: oneshot
+    cache:
+      key: "{{.files_hash}}"  # Template; runs with the same key share a result
+      ttl: 1h                 # Optional: how long a result is reused (default: until the key changes)
+` + "```" + `
+
+The key is rendered with the call's parameters and ` + "`.Vars`" + `. ` + "`files_hash`" + ` is a hash of the git state of the task's working directory: the HEAD commit plus uncommitted changes and untracked files. Outside a git repository, a key using it is not cached. A call whose task, command, working directory, parameters, and rendered key match an earlier successful run gets that run's result, with ` + "`cached: true`" + ` and its original ` + "`session_id`" + `; the command does not run. A different ` + "`timeout`" + ` still matches. Failed runs are not stored.
+
+Pass ` + "`no_cache: true`" + ` (or ` + "`--no-cache`" + ` on ` + "`runbook run`" + `) to run the command anyway; its result replaces the stored one. Results are stored under ` + "`._runbook_state/cache/`" + `; delete the directory to clear them.
+
+## Log Output
+
+**Optional.** Tools that print megabytes of progress would fill every response and stored result with noise. ` + "`log_output`" + ` sets how much of a oneshot task's stdout its results keep:
+
+` + "```yaml" + `
+tasks:
+  build:
+    description: "Build everything"
+    command: "make all"
+    type: oneshot
+    log_output: truncated       # full (default), truncated, or none
+    log_output_max_lines: 50    # Optional: last lines kept (default 100)
+    log_output_max_bytes: 16KB  # Optional: most bytes kept (default 64KB)
+` + "```" + `
+
+` + "`truncated`" + ` keeps the last lines of stdout that fit both caps; ` + "`none`" + ` keeps none of it. The session log always has all of it. A result that was cut has ` + "`stdout_truncated: true`" + `, the line count before the cut in ` + "`stdout_total_lines`" + `, the path of the session's stdout log in ` + "`stdout_log`" + `, and a ` + "`stdout_hint`" + ` naming the ` + "`read_session_log`" + ` call that reads the rest. Stderr, ` + "`output_format: json`" + ` output (parsed before the cut), and streamed CLI output are not affected; cached results and ` + "`{{ steps.<id>.stdout }}`" + ` in workflows get the stdout that was kept.
+
+## Reloading Daemons
+
+**Optional.** Daemons that reload their configuration on a signal, like nginx or a dev server with hot reload, can set ` + "`reload: true`" + ` to get a ` + "`reload_<task>`" + ` tool:
+
+` + "```yaml" + `
+tasks:
+  proxy:
+    description: "Reverse proxy"
+    command: "nginx -g 'daemon off;'"
+    type: daemon
+    reload: true
+    reload_signal: HUP   # default
+` + "```" + `
+
+The tool sends ` + "`reload_signal`" + ` to the daemon's whole process group and returns at once; the daemon keeps its PID and session, and a ` + "`reload`" + ` event is added to its event history. It fails if the daemon is not running or was started by another runbook process. Most programs exit on a signal they do not handle, so only set ` + "`reload`" + ` on daemons that handle it. From the CLI, run ` + "`runbook reload <task>`" + `. Signals are not supported on Windows.
+
+## Stop Signals
+
+**Optional.** Stopping a daemon sends SIGTERM to its process group, then SIGKILL to whatever is left after ` + "`defaults.stop_grace`" + ` seconds (default 5). Daemons that need something else can say so:
+
+` + "```yaml" + `
+tasks:
+  web:
+    description: "Next.js dev server"
+    command: "npm run dev"
+    type: daemon
+    stop_signal: INT        # Exit as on Ctrl-C
+    stop_grace_period: 15   # Seconds to wait before SIGKILL
+` + "```" + `
+
+` + "`stop_signal`" + ` is one of TERM (default), INT, QUIT, HUP, USR1, or USR2, with or without the SIG prefix; ` + "`stop_grace_period`" + ` replaces ` + "`defaults.stop_grace`" + ` for the task. Both apply to ` + "`stop_`" + ` tools, ` + "`stop_all_daemons`" + `, ` + "`runbook stop`" + `, restarts, and server shutdown, including daemons started by an earlier runbook process. The stop event in the daemon's history says which signal ended it, or that it was killed after the grace period. On Windows, daemons are always killed.
+
+## Daemon Ports
+
+**Optional.** List the ports a daemon listens on:
+
+` + "```yaml" + `
+tasks:
+  api:
+    command: "go run ./cmd/api"
+    type: daemon
+    ports: [8080, 9090]
+` + "```" + `
+
+Before starting the daemon, runbook checks that each port is free on localhost. If one is taken, the start fails with an error naming the PID that holds it, and the task when that PID belongs to another runbook daemon, so a stale dev server can be found and stopped. The ports of a running daemon are reported in the ` + "`ports`" + ` field of ` + "`status_<task>`" + `, in ` + "`runbook status`" + `, and on the dashboard.
+
+## Interactive Daemons
+
+**Optional.** Set ` + "`interactive: true`" + ` on a daemon that reads input, such as a REPL, a database console, or a watch-mode test runner:
+
+` + "```yaml" + `
+tasks:
+  psql:
+    description: "Postgres console"
+    command: "psql $DATABASE_URL"
+    type: daemon
+    interactive: true
+` + "```" + `
+
+The daemon runs on a pseudo-terminal (Linux; a stdin pipe elsewhere), and a ` + "`send_input_<task>`" + ` tool writes to it. The tool takes ` + "`input`" + ` and appends a newline unless ` + "`newline: false`" + `; control characters such as ` + "`\\u0003`" + ` (Ctrl-C) reach the terminal as keystrokes. Input and output are captured in the daemon's session log, so read replies with ` + "`logs_<task>`" + `. The terminal belongs to the runbook process that started the daemon and closes when it exits, so start interactive daemons through a running server rather than a one-off CLI call.
+
+### Interactive Tasks
+
+A oneshot task with ` + "`interactive: true`" + ` needs a person at a terminal, like ` + "`npm init`" + ` or a one-off database shell. ` + "`runbook run`" + ` always runs it locally, even when a server is running, and hands the process the terminal's stdin, stdout, and stderr directly:
+
+` + "```yaml" + `
+tasks:
+  init:
+    description: "Create package.json"
+    command: "npm init"
+    type: oneshot
+    interactive: true
+` + "```" + `
+
+The session records the command, exit code, and duration, but not the output, which goes only to the terminal. Ctrl-C reaches the task rather than stopping runbook. MCP ` + "`run_`" + ` calls and REST runs are rejected with an error telling the agent to ask the user to run it, and interactive tasks cannot be workflow steps, use ` + "`runner: docker`" + `, or have a ` + "`source: stdin`" + ` parameter.
+
+## Container Runner
+
+**Optional.** Set ` + "`runner: docker`" + ` on a oneshot task to run its command inside a container instead of the host shell, for untrusted or reproducible runs:
+
+` + "```yaml" + `
+tasks:
+  test:
+    description: "Run tests in a clean toolchain"
+    command: "go test ./..."
+    type: oneshot
+    runner: docker
+    shell: sh
+    container:
+      image: "golang:1.24"
+      mounts:
+        - "./.cache:/root/.cache"   # relative sources resolve against the working directory
+        - "gomod:/go/pkg/mod"       # named volume
+      network: none
+` + "```" + `
+
+The task's working directory is mounted at ` + "`/workspace`" + `, which is the container's working directory. The command runs with the task's ` + "`shell`" + ` (default ` + "`sh`" + `) and ` + "`env`" + `. Output, exit codes, timeouts, and session logs work as for host tasks; a timed-out container is removed. The ` + "`docker`" + ` CLI must be on the server's PATH. Recorded ` + "`resources`" + ` describe the docker CLI process, not the container.
+
+### Container Fields
+
+| Field | Required | Type | Description |
+|-------|----------|------|-------------|
+| image | Yes | string | Image to run |
+| mounts | No | []string | Extra bind mounts or volumes as ` + "`source:target[:options]`" + ` |
+| network | No | string | Docker network, e.g. ` + "`none`" + ` to disable networking |
+
+## Remote Agents
+
+**Optional.** A ` + "`runner`" + ` other than ` + "`shell`" + ` or ` + "`docker`" + ` is an agent tag: the task runs on a machine that registered with ` + "`runbook serve`" + ` under that tag, so one server can run tasks on build machines.
+
+` + "```yaml" + `
+tasks:
+  sign:
+    description: "Sign the macOS app"
+    command: "codesign --sign {{.identity}} build/App.app"
+    type: oneshot
+    runner: macos
+    working_directory: app
+    parameters:
+      identity:
+        type: string
+        required: true
+` + "```" + `
+
+On each build machine, run ` + "`runbook agent --server http://central:8080 --tag macos`" + `. Tags are lowercase letters, digits, ` + "`.`" + `, ` + "`_`" + `, and ` + "`-`" + `; repeat ` + "`--tag`" + ` to offer several, and use ` + "`--jobs`" + ` to run more than one task at a time. The agent runs the resolved command with the task's ` + "`shell`" + `, ` + "`env`" + `, and timeout, in ` + "`working_directory`" + ` resolved against its ` + "`--dir`" + ` (default: where it was started).
+
+Output streams back into the server's session log while the task runs, so ` + "`logs_<task>`" + `, ` + "`read_session_log`" + `, and the dashboard work as for local runs; the result and session metadata record the ` + "`agent`" + ` that ran it. A run fails at once when no agent with the tag is connected, and when its agent stops responding for 60 seconds. When the run times out on the server, the agent kills the command. Only ` + "`runbook serve`" + ` accepts agents, and only with ` + "`server.auth`" + ` or ` + "`$RUNBOOK_AGENT_TOKEN`" + ` set, since jobs carry commands and their env; agents send ` + "`$RUNBOOK_TOKEN`" + ` (or the project's ` + "`server.auth.token_env`" + `) as a bearer token and ` + "`$RUNBOOK_AGENT_TOKEN`" + ` in the ` + "`X-Runbook-Agent-Token`" + ` header. A task's ` + "`env_policy`" + ` applies to the agent's environment, and ` + "`GET /api/agents`" + ` lists the connected agents and what they are running.
+
+Agent tasks must be oneshot tasks and cannot use ` + "`preconditions`" + `, ` + "`artifacts`" + `, ` + "`source`" + ` parameters, or ` + "`interactive`" + `, which need the server's filesystem or terminal.
+
+## File Operations
+
+**Optional.** A ` + "`file_ops`" + ` task copies, renders, and removes files natively instead of shelling out, so setup tasks behave the same on every platform:
+
+` + "```yaml" + `
+tasks:
+  setup:
+    description: "Prepare a local config"
+    type: file_ops
+    parameters:
+      env:
+        type: string
+        required: true
+        description: "Environment name"
+    operations:
+      - op: mkdir
+        path: "config/{{.env}}"
+      - op: template
+        src: "config.yaml.tmpl"
+        dest: "config/{{.env}}/config.yaml"
+      - op: copy
+        src: "scripts/run.sh"
+        dest: "bin/run.sh"
+        mode: "0755"
+      - op: delete
+        path: "tmp/cache"
+        recursive: true
+` + "```" + `
+
+Operations run in order and stop at the first failure. Paths are relative to the task's working directory and accept parameter templates; ` + "`template`" + ` also renders the source file's contents with the call's parameters. Every path, including the source that ` + "`copy`" + ` and ` + "`template`" + ` read, must resolve inside the working directory, symlinks included. ` + "`delete`" + ` never removes the working directory itself, needs ` + "`recursive: true`" + ` for directories, and succeeds if the path is already gone. The task is exposed as a ` + "`run_`" + ` tool and logs one line per operation to its session.
+
+### Operation Fields
+
+| Field | Required | Type | Description |
+|-------|----------|------|-------------|
+| op | Yes | string | ` + "`copy`" + `, ` + "`template`" + `, ` + "`mkdir`" + `, ` + "`chmod`" + `, or ` + "`delete`" + ` |
+| src | copy, template | string | File to read |
+| dest | copy, template | string | File to write; parent directories are created |
+| path | mkdir, chmod, delete | string | Target path |
+| mode | chmod | string | Octal permissions, e.g. "0755"; on copy and template defaults to the source's mode |
+| recursive | No | bool | Delete only: allow removing a directory and its contents |
+
+## Output Redaction
+
+**Optional.** ` + "`redact`" + ` lists regular expressions whose matches are replaced with ` + "`[REDACTED]`" + ` before output is written to session logs or returned by tools. Patterns in ` + "`defaults.redact`" + ` apply to every task and to ` + "`exec_command`" + `; a task's own patterns are added to them.
+
+` + "```yaml" + `
+defaults:
+  redact:
+    - "ghp_[A-Za-z0-9]+"          # whole match is masked
+tasks:
+  db:
+    description: "Local database"
+    command: "./start-db.sh"
+    type: daemon
+    redact:
+      - "password=(\\S+)"        # only the capture group is masked
+` + "```" + `
+
+Patterns with capture groups mask only the groups, keeping the surrounding context readable. Output is matched line by line, so a secret split across writes is still caught, but a pattern cannot span lines. For daemons with redaction, the server copies output into the log instead of the daemon writing it directly, so a daemon that outlives the server stops being logged.
+
+## Fault Injection
+
+**Optional.** The ` + "`testing`" + ` section makes tasks fail, slow down, or time out on purpose, so agents and workflows built on runbook can be tested against failures. Faults only apply when ` + "`enabled: true`" + ` or the ` + "`RUNBOOK_TESTING=1`" + ` environment variable is set, so they can be kept in the config and switched on per environment.
+
+` + "```yaml" + `
+testing:
+  enabled: false   # Or set RUNBOOK_TESTING=1
+  seed: 42         # Same seed, same faults for the same runs (RUNBOOK_TESTING_SEED overrides)
+  faults:
+    build:
+      fail_rate: 0.3   # 30% of runs fail without running the command
+      exit_code: 2     # Exit code of simulated failures (default 1)
+    "deploy-*":
+      delay: 5         # Seconds to wait before running
+      timeout_rate: 0.5
+` + "```" + `
+
+Fault keys are task names or glob patterns; an exact name wins. Faults apply to oneshot and file_ops runs, including workflow steps and their retries, not to daemons. Simulated failures and timeouts return without a session and say ` + "`simulated by testing.faults`" + ` in their error.
+
+## Disabling and Visibility
+
+Items can be hidden from MCP (and optionally the CLI) using ` + "`disabled`" + ` and ` + "`disable_mcp`" + ` flags.
+
+### Flag Semantics
+
+| Flag | Effect |
+|------|--------|
+| ` + "`disabled: true`" + ` | Hidden from both MCP and CLI entirely |
+| ` + "`disable_mcp: true`" + ` | Hidden from MCP only; CLI can still run it |
+
+### Supported Per Item Type
+
+| Item type | ` + "`disabled`" + ` | ` + "`disable_mcp`" + ` |
+|-----------|-----------|---------------|
+| tasks | yes | yes |
+| workflows | yes | yes |
+| prompts | yes | — |
+| resources | yes | — |
 
 ### Examples
 
@@ -958,7 +2242,7 @@ prompts:
 The server validates configurations on load:
 
 1. **Required fields**: version, tasks, task.description, task.command, task.type
-2. **Valid task types**: Must be "oneshot" or "daemon"
+2. **Valid task types**: Must be "oneshot", "daemon", "compose", or "file_ops"
 3. **Valid task references**: Task groups and dependencies must reference existing tasks
 4. **Valid parameters**: Parameters must have type, required, and description
 5. **Valid timeouts**: Must be positive integers
@@ -1092,6 +2376,9 @@ tasks:
 - Template documentation: ` + "`runbook://docs/templates`" + `
 - Task groups: ` + "`runbook://task-groups`" + `
 - Task dependencies: ` + "`runbook://task-dependencies`" + `
+- A task's latest session: ` + "`runbook://sessions/<task>/latest`" + `
+- A session by ID: ` + "`runbook://sessions/<id>`" + `
+- A task's documentation: ` + "`runbook://tasks/<name>`" + `
 `
 			return []mcp.ResourceContents{
 				mcp.TextResourceContents{
@@ -1103,13 +2390,17 @@ tasks:
 		},
 	)
 
+	// Register session and task doc resource templates
+	s.registerSessionResources()
+	s.registerTaskDocResources()
+
 	// Register custom resources from config
 	s.registerCustomResources()
 }
 
 // registerCustomResources registers user-defined resources from the manifest
 func (s *Server) registerCustomResources() {
-	for resourceName, resourceDef := range s.manifest.Resources {
+	for resourceName, resourceDef := range s.current().manifest.Resources {
 		if resourceDef.Disabled {
 			continue
 		}
@@ -1131,30 +2422,42 @@ func (s *Server) registerCustomResources() {
 		s.mcpServer.AddResource(
 			mcp.NewResource(uri, name, opts...),
 			func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-				var rawContent string
+				manifest := s.current().manifest
+				resolve := func(raw string) (string, error) {
+					resolved, err := template.ResolveManifestTemplate(raw, manifest)
+					if err != nil {
+						return "", fmt.Errorf("failed to resolve resource template: %w", err)
+					}
+					return resolved, nil
+				}
+
+				var resolvedContent, etag string
 				if def.File != "" {
-					data, err := os.ReadFile(def.File)
+					var err error
+					resolvedContent, etag, err = s.files.render(def.File, resolve)
 					if err != nil {
 						return nil, fmt.Errorf("failed to read resource file %s: %w", def.File, err)
 					}
-					rawContent = string(data)
 				} else {
-					rawContent = def.Content
+					var err error
+					if resolvedContent, err = resolve(def.Content); err != nil {
+						return nil, err
+					}
+					etag = contentETag(resolvedContent)
 				}
 
-				// Resolve template variables in content
-				resolvedContent, err := template.ResolvePromptTemplate(rawContent, s.manifest.Tasks)
-				if err != nil {
-					return nil, fmt.Errorf("failed to resolve resource template: %w", err)
+				// A client that already has this content gets it back without text
+				contents := mcp.TextResourceContents{
+					URI:      uri,
+					MIMEType: mimeType,
+					Meta:     map[string]any{"etag": etag},
 				}
-
-				return []mcp.ResourceContents{
-					mcp.TextResourceContents{
-						URI:      uri,
-						MIMEType: mimeType,
-						Text:     resolvedContent,
-					},
-				}, nil
+				if match, _ := req.Params.Arguments[ifNoneMatchArg].(string); match == etag {
+					contents.Meta["not_modified"] = true
+				} else {
+					contents.Text = resolvedContent
+				}
+				return []mcp.ResourceContents{contents}, nil
 			},
 		)
 	}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/logs"
+)
+
+// sessionResourceLogLines is how many log lines a session resource includes.
+const sessionResourceLogLines = 50
+
+// sessionResource is the content of a runbook://sessions resource.
+type sessionResource struct {
+	Metadata   *logs.SessionMetadata `json:"metadata"`
+	Log        []string              `json:"log"`         // The last sessionResourceLogLines lines
+	TotalLines int                   `json:"total_lines"` // Lines in the whole log
+}
+
+// registerSessionResources registers resource templates that serve a
+// session's metadata and log tail, so agents can cite a run by URI.
+func (s *Server) registerSessionResources() {
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"runbook://sessions/{task}/latest",
+			"Latest Task Session",
+			mcp.WithTemplateDescription("Metadata and log tail of a task's most recent session"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			taskName := resourceArgument(req, "task")
+			if _, ok := s.current().manifest.Tasks[taskName]; !ok {
+				return nil, fmt.Errorf("task '%s' not found", taskName)
+			}
+			sessionID, err := logs.GetLatestSessionID(taskName)
+			if err != nil {
+				return nil, fmt.Errorf("task '%s' has no sessions", taskName)
+			}
+			return sessionResourceContents(req.Params.URI, sessionID)
+		},
+	)
+
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"runbook://sessions/{id}",
+			"Session",
+			mcp.WithTemplateDescription("Metadata and log tail of an execution session"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			sessionID := resourceArgument(req, "id")
+			// Session IDs are UUIDs; rejecting anything else keeps the path
+			// inside the sessions directory
+			if _, err := uuid.Parse(sessionID); err != nil {
+				return nil, fmt.Errorf("invalid session id '%s'", sessionID)
+			}
+			return sessionResourceContents(req.Params.URI, sessionID)
+		},
+	)
+}
+
+// resourceArgument returns a URI template variable of a resource read.
+func resourceArgument(req mcp.ReadResourceRequest, name string) string {
+	switch v := req.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// sessionResourceContents reads a session's metadata and log tail as the
+// contents of the resource at uri.
+func sessionResourceContents(uri, sessionID string) ([]mcp.ResourceContents, error) {
+	metadata, err := logs.ReadSessionMetadata(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("session '%s' not found", sessionID)
+	}
+	lines, total, err := logs.ReadSessionLog(sessionID, logs.ReadOptions{Lines: sessionResourceLogLines})
+	if err != nil {
+		lines = nil
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+
+	data, err := json.MarshalIndent(sessionResource{Metadata: metadata, Log: lines, TotalLines: total}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
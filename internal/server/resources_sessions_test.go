@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestSessionResources(t *testing.T) {
+	chdirToTemp(t)
+
+	sessionID := logs.GenerateSessionID()
+	if err := logs.CreateSessionDirectory(sessionID); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.WriteSessionMetadata(sessionID, &logs.SessionMetadata{SessionID: sessionID, TaskName: "test", TaskType: "oneshot", StartTime: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logs.GetSessionLogPath(sessionID), []byte("PASS\nok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.CreateLatestLink("test", sessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test": {Description: "Run tests", Command: "go test ./...", Type: config.TaskTypeOneShot},
+		},
+	}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.0.0", "")
+
+	read := func(uri string) (string, string) {
+		t.Helper()
+		return readResource(t, s, uri)
+	}
+
+	for _, uri := range []string{"runbook://sessions/test/latest", "runbook://sessions/" + sessionID} {
+		text, errMsg := read(uri)
+		var got sessionResource
+		if err := json.Unmarshal([]byte(text), &got); err != nil {
+			t.Fatalf("%s: expected session JSON, got %q (%s)", uri, text, errMsg)
+		}
+		if got.Metadata.SessionID != sessionID || got.TotalLines != 2 || strings.Join(got.Log, "|") != "PASS|ok" {
+			t.Errorf("%s: unexpected session %+v", uri, got)
+		}
+	}
+
+	if _, errMsg := read("runbook://sessions/missing/latest"); !strings.Contains(errMsg, "not found") {
+		t.Errorf("expected an unknown task to fail, got %q", errMsg)
+	}
+	if _, errMsg := read("runbook://sessions/..%2F..%2Fetc"); !strings.Contains(errMsg, "invalid session id") {
+		t.Errorf("expected a non-UUID session id to be rejected, got %q", errMsg)
+	}
+}
+
+// readResource reads uri from s, returning its text or the error message.
+func readResource(t *testing.T, s *Server, uri string) (string, string) {
+	t.Helper()
+	resp := s.mcpServer.HandleMessage(context.Background(), json.RawMessage(fmt.Sprintf(
+		`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)))
+	out, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Result struct {
+			Contents []struct {
+				Text string `json:"text"`
+			} `json:"contents"`
+		} `json:"result"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("invalid resources/read response %s: %v", out, err)
+	}
+	if len(decoded.Result.Contents) != 1 {
+		return "", decoded.Error.Message
+	}
+	return decoded.Result.Contents[0].Text, ""
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// taskDocSessions is how many recent sessions a task doc lists.
+const taskDocSessions = 5
+
+// registerTaskDocResources registers the runbook://tasks/{name} resource
+// template, which renders a task's documentation when it is read.
+func (s *Server) registerTaskDocResources() {
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			"runbook://tasks/{name}",
+			"Task Documentation",
+			mcp.WithTemplateDescription("A task's description, tools, parameters, usage examples, and recent sessions"),
+			mcp.WithTemplateMIMEType("text/markdown"),
+		),
+		func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			name := resourceArgument(req, "name")
+			manifest := s.current().manifest
+			def, ok := manifest.Tasks[name]
+			if !ok || def.Disabled {
+				return nil, fmt.Errorf("task '%s' not found", name)
+			}
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{
+					URI:      req.Params.URI,
+					MIMEType: "text/markdown",
+					Text:     taskDoc(manifest, name),
+				},
+			}, nil
+		},
+	)
+}
+
+// taskDoc renders the documentation of a task as markdown.
+func taskDoc(manifest *config.Manifest, name string) string {
+	def := manifest.Tasks[name]
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	if def.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", def.Description)
+	}
+
+	fmt.Fprintf(&b, "- Type: %s\n", def.Type)
+	if tools := taskToolNames(manifest, name); len(tools) > 0 {
+		fmt.Fprintf(&b, "- Tools: `%s`\n", strings.Join(tools, "`, `"))
+	} else {
+		b.WriteString("- Tools: none (disable_mcp is set; use the CLI)\n")
+	}
+	if def.WorkingDirectory != "" {
+		fmt.Fprintf(&b, "- Working directory: %s\n", def.WorkingDirectory)
+	}
+	if len(def.DependsOn) > 0 {
+		fmt.Fprintf(&b, "- Depends on: %s\n", strings.Join(def.DependsOn, ", "))
+	}
+	if len(def.RequiresDaemon) > 0 {
+		fmt.Fprintf(&b, "- Requires daemons: %s\n", strings.Join(def.RequiresDaemon, ", "))
+	}
+	if def.RequiresConfirmation {
+		b.WriteString("- Requires confirmation: MCP calls need a confirmation_token\n")
+	}
+
+	params := sortedKeys(def.Parameters)
+	if len(params) > 0 {
+		b.WriteString("\n## Parameters\n\n")
+		b.WriteString("| Name | Type | Required | Default | Description |\n")
+		b.WriteString("|------|------|----------|---------|-------------|\n")
+		for _, pn := range params {
+			p := def.Parameters[pn]
+			paramType := p.Type
+			if paramType == "" {
+				paramType = "string"
+			}
+			required := "no"
+			if p.Required {
+				required = "yes"
+			}
+			defaultValue := ""
+			if p.Default != nil {
+				defaultValue = "`" + *p.Default + "`"
+			}
+			description := p.Description
+			if len(p.Aliases) > 0 {
+				description += " (aliases: " + strings.Join(p.Aliases, ", ") + ")"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", pn, paramType, required, defaultValue, strings.ReplaceAll(description, "|", `\|`))
+		}
+	}
+
+	if len(def.ParameterPresets) > 0 {
+		b.WriteString("\n## Presets\n\n")
+		for _, preset := range sortedKeys(def.ParameterPresets) {
+			values := def.ParameterPresets[preset]
+			pairs := make([]string, 0, len(values))
+			for _, pn := range sortedKeys(values) {
+				pairs = append(pairs, pn+"="+values[pn])
+			}
+			fmt.Fprintf(&b, "- `%s`: %s\n", preset, strings.Join(pairs, ", "))
+		}
+	}
+
+	b.WriteString("\n## Examples\n\n")
+	args := make(map[string]string)
+	var flags []string
+	for _, pn := range params {
+		p := def.Parameters[pn]
+		if !p.Required {
+			continue
+		}
+		value := "<" + pn + ">"
+		if p.Default != nil {
+			value = *p.Default
+		}
+		args[pn] = value
+		flags = append(flags, fmt.Sprintf("--%s=%s", pn, value))
+	}
+	if tools := taskToolNames(manifest, name); len(tools) > 0 {
+		call, _ := json.Marshal(map[string]interface{}{"name": tools[0], "arguments": args})
+		b.WriteString("MCP:\n\n```json\n" + string(call) + "\n```\n\n")
+	}
+	subcmd := "run"
+	if def.Type.IsDaemon() {
+		subcmd = "start"
+	}
+	command := strings.Join(append([]string{"runbook", subcmd, name}, flags...), " ")
+	b.WriteString("CLI:\n\n```bash\n" + command + "\n```\n")
+
+	b.WriteString("\n## Recent sessions\n\n")
+	sessions, _ := logs.ListSessions(name, taskDocSessions)
+	if len(sessions) == 0 {
+		b.WriteString("No sessions yet.\n")
+		return b.String()
+	}
+	b.WriteString("| Session | Started | Status | Exit code | Duration |\n")
+	b.WriteString("|---------|---------|--------|-----------|----------|\n")
+	for _, session := range sessions {
+		status, exitCode, duration := "running", "", ""
+		if metadata, err := logs.ReadSessionMetadata(session.SessionID); err == nil {
+			switch {
+			case metadata.Status != "":
+				status = metadata.Status
+			case metadata.Success != nil && *metadata.Success:
+				status = "success"
+			case metadata.Success != nil:
+				status = "failure"
+			}
+			if metadata.ExitCode != nil {
+				exitCode = fmt.Sprint(*metadata.ExitCode)
+			}
+			if metadata.Duration != nil {
+				duration = metadata.Duration.String()
+			}
+		}
+		fmt.Fprintf(&b, "| `runbook://sessions/%s` | %s | %s | %s | %s |\n",
+			session.SessionID, session.StartTime.UTC().Format("2006-01-02 15:04:05Z"), status, exitCode, duration)
+	}
+	return b.String()
+}
+
+// taskToolNames returns the MCP tools a task exposes, its main tool first.
+func taskToolNames(manifest *config.Manifest, name string) []string {
+	def := manifest.Tasks[name]
+	if def.DisableMCP {
+		return nil
+	}
+	kinds := config.TaskToolKinds(def)
+	if len(kinds) == 0 {
+		kinds = []string{config.ToolRun}
+	}
+	tools := make([]string, len(kinds))
+	for i, kind := range kinds {
+		tools[i] = manifest.ToolName(kind, name)
+	}
+	return tools
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestTaskDocResource(t *testing.T) {
+	chdirToTemp(t)
+
+	sessionID := logs.GenerateSessionID()
+	if err := logs.CreateSessionDirectory(sessionID); err != nil {
+		t.Fatal(err)
+	}
+	success, exitCode := true, 0
+	if err := logs.WriteSessionMetadata(sessionID, &logs.SessionMetadata{
+		SessionID: sessionID, TaskName: "deploy", TaskType: "oneshot", StartTime: time.Now(),
+		Success: &success, ExitCode: &exitCode,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	staging := "staging"
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"deploy": {
+				Description: "Deploy the service",
+				Command:     "deploy {{.env | shellquote}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"env":     {Type: "string", Required: true, Description: "Target environment"},
+					"version": {Type: "string", Default: &staging, Description: "Version | tag"},
+				},
+				ParameterPresets: map[string]map[string]string{"prod": {"env": "prod"}},
+			},
+			"api":     {Description: "API server", Command: "serve", Type: config.TaskTypeDaemon, Reload: true},
+			"retired": {Description: "Old", Command: "true", Type: config.TaskTypeOneShot, Disabled: true},
+		},
+	}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, true, "1.0.0", "")
+
+	doc, errMsg := readResource(t, s, "runbook://tasks/deploy")
+	if errMsg != "" {
+		t.Fatalf("read deploy doc: %s", errMsg)
+	}
+	for _, want := range []string{
+		"# deploy",
+		"Deploy the service",
+		"- Tools: `run_deploy`",
+		"| `env` | string | yes |  | Target environment |",
+		`| Version \| tag |`,
+		"- `prod`: env=prod",
+		`{"arguments":{"env":"\u003cenv\u003e"},"name":"run_deploy"}`,
+		"runbook run deploy --env=<env>",
+		"`runbook://sessions/" + sessionID + "`",
+		"| success | 0 |",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("deploy doc missing %q:\n%s", want, doc)
+		}
+	}
+
+	doc, _ = readResource(t, s, "runbook://tasks/api")
+	for _, want := range []string{"`start_api`, `stop_api`, `status_api`, `logs_api`, `reload_api`", "runbook start api", "No sessions yet."} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("api doc missing %q:\n%s", want, doc)
+		}
+	}
+
+	for _, name := range []string{"missing", "retired"} {
+		if _, errMsg := readResource(t, s, "runbook://tasks/"+name); !strings.Contains(errMsg, "not found") {
+			t.Errorf("expected task %q to be not found, got %q", name, errMsg)
+		}
+	}
+}
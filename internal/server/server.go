@@ -2,65 +2,108 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
+	"runbookmcp.dev/internal/auth"
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/metrics"
 	"runbookmcp.dev/internal/process"
 	"runbookmcp.dev/internal/task"
+	"runbookmcp.dev/internal/template"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // Server wraps the MCP server with task management
 type Server struct {
-	mu             sync.Mutex
+	mu             sync.Mutex // serializes reloads and project changes
 	mcpServer      *server.MCPServer
-	manager        *task.Manager
-	manifest       *config.Manifest
-	configLoaded   bool
+	state          atomic.Pointer[serverState] // swapped whole by a refresh
 	configPath     string
 	version        string
 	processManager task.ProcessManager
+	latency        latencyTracker
+	metrics        *metrics.Registry
+	projects       map[string]string // projects added with register_project, by name
+	authenticator  auth.Authenticator
+	sessions       sessionDaemons
+	confirmations  confirmations
+	execLimiter    *rateLimiter   // shell_exec rate limit, shared by its aliases
+	httpMode       bool           // set by ServeHTTP; edit tools then require an authenticated client
+	drain          drain          // in-flight tool calls, waited for on shutdown
+	runQueue       *task.RunQueue // set by ServeHTTP; bounds concurrent oneshot runs
+	agents         *task.AgentPool // set by ServeHTTP; runs tasks with agent runners
+	files          fileCache      // rendered file-backed prompts and resources
+	// withheld holds the optional MCP capabilities not advertised at startup.
+	// Clients see capabilities once at initialize, so a refresh keeps them.
+	withheld map[string]bool
 }
 
 // NewServer creates a new MCP server with task management
 func NewServer(manifest *config.Manifest, manager *task.Manager, processManager task.ProcessManager, configLoaded bool, version string, configPath string) *Server {
-	// Create MCP server with capabilities
-	mcpServer := server.NewMCPServer(
-		"runbook",
-		version,
-		server.WithToolCapabilities(true),
-		server.WithResourceCapabilities(true, false),
-		server.WithPromptCapabilities(true),
-	)
+	s := NewInspectionServer(manifest, manager, processManager, configLoaded, version, configPath)
+
+	// Clean up old sessions at startup to bound directory size
+	if _, err := logs.CleanupAllSessions(logs.DefaultRetention); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: session cleanup failed: %v\n", err)
+	}
+	return s
+}
 
+// NewInspectionServer creates a server with the same tools, resources, and
+// prompts as NewServer, for inspecting them without serving. Unlike
+// NewServer it leaves the session logs alone.
+func NewInspectionServer(manifest *config.Manifest, manager *task.Manager, processManager task.ProcessManager, configLoaded bool, version string, configPath string) *Server {
+	// Create MCP server with capabilities
 	s := &Server{
-		mcpServer:      mcpServer,
-		manager:        manager,
-		manifest:       manifest,
-		configLoaded:   configLoaded,
 		configPath:     configPath,
 		version:        version,
 		processManager: processManager,
+		metrics:        metrics.NewRegistry(),
 	}
+	s.setState(manifest, manager, configLoaded)
 
-	// Clean up old sessions at startup to bound directory size
-	if _, err := logs.CleanupAllSessions(logs.DefaultRetention); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: session cleanup failed: %v\n", err)
+	name, advertisedVersion, instructions := serverMetadata(manifest, version)
+	s.withheld = make(map[string]bool)
+	for _, capability := range []string{config.CapabilityPrompts, config.CapabilityResources, config.CapabilityLogging} {
+		s.withheld[capability] = !manifest.Server.HasCapability(capability)
+	}
+	opts := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithInstructions(instructions),
+		server.WithHooks(s.sessionHooks()),
+		server.WithToolHandlerMiddleware(s.drain.middleware),
+	}
+	if !s.withheld[config.CapabilityResources] {
+		opts = append(opts, server.WithResourceCapabilities(true, false))
+	}
+	if !s.withheld[config.CapabilityPrompts] {
+		opts = append(opts, server.WithPromptCapabilities(true))
+	}
+	if !s.withheld[config.CapabilityLogging] {
+		opts = append(opts, server.WithLogging())
 	}
+	s.mcpServer = server.NewMCPServer(name, advertisedVersion, opts...)
+	manager.SetObserver(s.metrics)
 
-	// Register built-in tools (only if no config loaded)
-	if !configLoaded {
+	// Register bootstrap tools (only if there are no tasks to expose)
+	if s.needsBootstrap() {
 		s.registerBuiltInTools()
 	}
 
-	// Register config refresh tool (always available)
+	// Register config refresh and lint tools (always available)
 	s.registerRefreshConfigTool()
+	s.registerLintConfigTool()
 
 	// Register tools, resources, and prompts from config
 	s.registerTools()
@@ -70,6 +113,28 @@ func NewServer(manifest *config.Manifest, manager *task.Manager, processManager
 	return s
 }
 
+// serverMetadata returns the name, version, and instructions advertised
+// during initialize, applying the manifest's server settings. Instructions
+// that fail to resolve as a template are sent as written.
+func serverMetadata(manifest *config.Manifest, version string) (string, string, string) {
+	name := "runbook"
+	if manifest.Server.Name != "" {
+		name = manifest.Server.Name
+	}
+
+	instructions := manifest.Server.Instructions
+	if instructions != "" {
+		resolved, err := template.ResolveManifestTemplate(instructions, manifest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to resolve server instructions template: %v\n", err)
+		} else {
+			instructions = resolved
+		}
+	}
+
+	return name, version + manifest.Server.VersionSuffix, instructions
+}
+
 // Serve starts the MCP server over stdio
 func (s *Server) Serve() error {
 	// set_working_directory is only registered in local stdio mode, where a
@@ -77,19 +142,84 @@ func (s *Server) Serve() error {
 	// which would break the cooperative multi-client model of HTTP server mode,
 	// so ServeHTTP deliberately does not register it.
 	s.registerSetWorkingDirTool()
+	// The client owns this process, so its session daemons end with it
+	defer s.stopAllSessionDaemons()
 	return server.ServeStdio(s.mcpServer)
 }
 
 // ServeHTTP starts the MCP server as a standalone HTTP server using
-// StreamableHTTP transport. It handles graceful shutdown on SIGINT/SIGTERM.
+// StreamableHTTP transport, with the web dashboard at DashboardPath,
+// Prometheus metrics at MetricsPath, and a REST API under APIPath. Every
+// endpoint sits behind the configured authenticator; without one, dashboard
+// data and metrics requests must send the registry token. TLS is used when
+// server.tls is set. It handles graceful shutdown on SIGINT/SIGTERM.
 // It writes a server registry file on start and removes it on shutdown.
 func (s *Server) ServeHTTP(addr string) error {
-	httpServer := server.NewStreamableHTTPServer(s.mcpServer)
+	s.httpMode = true
+	authenticator, err := s.httpAuthenticator()
+	if err != nil {
+		return err
+	}
+	tlsCfg := s.current().manifest.Server.TLS
+	tlsConf, err := tlsConfig(tlsCfg)
+	if err != nil {
+		return fmt.Errorf("server.tls: %w", err)
+	}
+
+	// The registry token lets local clients check that this process is the
+	// server before they talk to it; the handshake needs no credentials.
+	// Without server.auth, the dashboard and metrics also require it
+	token, err := process.NewServerToken()
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	root := http.NewServeMux()
+	root.HandleFunc("GET "+process.HandshakePath, handleHandshake(token))
+	if authenticator != nil {
+		root.Handle("/", withAuth(authenticator, mux))
+	} else {
+		root.Handle("/", withServerToken(token, mux))
+	}
+	opts := []server.StreamableHTTPOption{
+		server.WithStreamableHTTPServer(&http.Server{
+			Addr:      addr,
+			Handler:   root,
+			TLSConfig: tlsConf,
+		}),
+	}
+	if tlsConf != nil {
+		opts = append(opts, server.WithTLSCert(tlsCfg.CertFile, tlsCfg.KeyFile))
+	}
+	httpServer := server.NewStreamableHTTPServer(s.mcpServer, opts...)
+	// register_project is only offered by the shared HTTP server, which is
+	// the mode that hosts several projects
+	s.registerRegisterProjectTool()
+	// Clients share the host, so runs beyond defaults.max_concurrent_tasks
+	// wait their turn
+	st := s.current()
+	s.runQueue = task.NewRunQueue(st.manifest.Defaults.MaxConcurrentTasks)
+	st.manager.SetRunQueue(s.runQueue)
+	s.registerQueueStatusTool()
+	s.agents = task.NewAgentPool()
+	st.manager.SetAgentPool(s.agents)
+
+	mux.Handle(mcputil.EndpointPath, s.endSessionOnDelete(httpServer))
+	mux.HandleFunc("GET "+MetricsPath, s.handleMetrics)
+	s.registerDashboard(mux)
+	s.registerAPI(mux)
+	if !s.registerAgentAPI(mux, authenticator != nil) && hasAgentTasks(st.manifest) {
+		fmt.Fprintf(os.Stderr, "Warning: remote agents are disabled; set server.auth or $%s to let them connect\n", mcputil.AgentTokenEnv)
+	}
 
 	normalizedAddr := normalizeAddr(addr)
+	if tlsConf != nil {
+		normalizedAddr = "https://" + strings.TrimPrefix(normalizedAddr, "http://")
+	}
 	if err := process.WriteServerFile(process.ServerFileData{
-		Addr: normalizedAddr,
-		PID:  os.Getpid(),
+		Addr:  normalizedAddr,
+		PID:   os.Getpid(),
+		Token: token,
 	}); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to write server registry: %v\n", err)
 	}
@@ -99,24 +229,93 @@ func (s *Server) ServeHTTP(addr string) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	stopping := make(chan struct{})
+	stopped := make(chan struct{})
 	go func() {
 		<-sigChan
+		close(stopping)
+		defer close(stopped)
 		fmt.Fprintln(os.Stderr, "\nShutting down HTTP server...")
+		s.shutdown(httpServer)
+	}()
 
-		if err := httpServer.Shutdown(context.Background()); err != nil {
-			fmt.Fprintf(os.Stderr, "Error shutting down HTTP server: %v\n", err)
+	fmt.Fprintf(os.Stderr, "Dev Workflow MCP server listening on %s\n", normalizedAddr)
+	if authenticator != nil {
+		fmt.Fprintf(os.Stderr, "Dashboard available at %s%s\n", normalizedAddr, DashboardPath)
+	} else {
+		// The dashboard page reads the token from the fragment, which the
+		// browser does not send to the server
+		fmt.Fprintf(os.Stderr, "Dashboard available at %s%s#token=%s\n", normalizedAddr, DashboardPath, token)
+	}
+	err = httpServer.Start(addr)
+	select {
+	case <-stopping:
+		// Let the shutdown finish stopping daemons before returning
+		<-stopped
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
 		}
+	default:
+	}
+	return err
+}
 
-		// Stop all running daemons
-		if s.processManager != nil {
-			if err := s.processManager.StopAll(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error stopping daemons: %v\n", err)
-			}
+// shutdown refuses new tool calls and waits up to server.shutdown_grace for
+// in-flight ones and for runs started with run_async, so their results are
+// returned and their session metadata is written. It then stops the HTTP
+// server and all running daemons, unless a new server is taking over.
+func (s *Server) shutdown(httpServer *server.StreamableHTTPServer) {
+	grace := ResolveShutdownGrace(s.current().manifest.Server.ShutdownGrace)
+	deadline := time.Now().Add(grace)
+	if n := s.drain.running(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Waiting up to %s for %d in-flight tool call(s)...\n", grace, n)
+	}
+	if !s.drain.wait(grace) {
+		fmt.Fprintf(os.Stderr, "Warning: %d tool call(s) still running after %s, shutting down anyway\n", s.drain.running(), grace)
+	}
+	// Tool calls are refused now, so no new background runs start; both
+	// waits share the grace period
+	background := s.current().manager.BackgroundRuns()
+	if n := background.Running(); n > 0 {
+		remaining := max(time.Until(deadline), 0)
+		fmt.Fprintf(os.Stderr, "Waiting up to %s for %d background run(s)...\n", remaining.Round(time.Second), n)
+		if !background.Wait(remaining) {
+			fmt.Fprintf(os.Stderr, "Warning: %d background run(s) still running after %s, shutting down anyway\n", background.Running(), grace)
 		}
-	}()
+	}
 
-	fmt.Fprintf(os.Stderr, "Dev Workflow MCP server listening on %s\n", normalizedAddr)
-	return httpServer.Start(addr)
+	// Release agents waiting for jobs, or their polls hold up the shutdown
+	s.agents.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error shutting down HTTP server: %v\n", err)
+	}
+
+	// A server taking over the project adopts the daemons. Session daemons
+	// end with their MCP sessions, which end here.
+	if process.HandoverRequested() {
+		s.stopAllSessionDaemons()
+		fmt.Fprintln(os.Stderr, "Leaving daemons running for the server taking over")
+		return
+	}
+
+	// Stop all running daemons: the manifest's in reverse dependency order,
+	// independent ones together, then anything else this process owns.
+	// Failures are retried and reported by StopAll.
+	if s.processManager != nil {
+		st := s.current()
+		var daemons []string
+		for name, t := range st.manifest.Tasks {
+			if t.Type.IsDaemon() {
+				daemons = append(daemons, name)
+			}
+		}
+		st.manager.StopDaemons(daemons)
+		if err := s.processManager.StopAll(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error stopping daemons: %v\n", err)
+		}
+	}
 }
 
 // normalizeAddr expands a bare port like ":8080" to "http://localhost:8080".
@@ -130,6 +329,12 @@ func normalizeAddr(addr string) string {
 	return addr
 }
 
+// Manager returns the task manager for the currently loaded manifest. It
+// changes when the config is refreshed.
+func (s *Server) Manager() *task.Manager {
+	return s.current().manager
+}
+
 // GetMCPServer returns the underlying MCP server
 func (s *Server) GetMCPServer() *server.MCPServer {
 	return s.mcpServer
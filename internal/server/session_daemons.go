@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/mcputil"
+)
+
+// DefaultSessionGrace is how long a daemon with lifetime: session keeps
+// running after the MCP session that started it ends.
+const DefaultSessionGrace = 30 * time.Second
+
+// sessionDaemons tracks which MCP session started each session-scoped daemon
+// and the stops scheduled for sessions that have ended.
+type sessionDaemons struct {
+	mu      sync.Mutex
+	owners  map[string]string        // task name -> MCP session ID
+	pending map[string][]*time.Timer // MCP session ID -> scheduled stops
+}
+
+// sessionHooks returns the MCP hooks that track session activity for
+// session-scoped daemons.
+func (s *Server) sessionHooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.sessionActive(session.SessionID())
+	})
+	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			s.sessionActive(session.SessionID())
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.sessionEnded(session.SessionID())
+	})
+	return hooks
+}
+
+// claimSessionDaemons records the MCP session in ctx as the owner of the
+// given daemons that have lifetime: session. Other daemons are ignored, as
+// are daemons started by the runbook CLI, which disconnects right away.
+func (s *Server) claimSessionDaemons(ctx context.Context, taskNames ...string) {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+	if info, ok := session.(server.SessionWithClientInfo); ok && info.GetClientInfo().Name == mcputil.CLIClientName {
+		return
+	}
+	sessionID := session.SessionID()
+
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	for _, name := range taskNames {
+		if task, ok := s.taskDef(name); !ok || task.Lifetime != config.LifetimeSession {
+			continue
+		}
+		if s.sessions.owners == nil {
+			s.sessions.owners = make(map[string]string)
+		}
+		s.sessions.owners[name] = sessionID
+	}
+}
+
+// releaseSessionDaemon forgets the owner of a daemon that was stopped
+// explicitly, so a later persistent start is not stopped with the session.
+func (s *Server) releaseSessionDaemon(taskName string) {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	delete(s.sessions.owners, taskName)
+}
+
+// sessionActive cancels stops scheduled for a session that has reconnected
+// or sent another request.
+func (s *Server) sessionActive(sessionID string) {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	for _, t := range s.sessions.pending[sessionID] {
+		t.Stop()
+	}
+	delete(s.sessions.pending, sessionID)
+}
+
+// sessionEnded schedules a stop for each daemon owned by the session, after
+// the daemon's session_grace period.
+func (s *Server) sessionEnded(sessionID string) {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	for name, owner := range s.sessions.owners {
+		if owner != sessionID {
+			continue
+		}
+		grace := DefaultSessionGrace
+		if task, ok := s.taskDef(name); ok && task.SessionGrace > 0 {
+			grace = time.Duration(task.SessionGrace) * time.Second
+		}
+		taskName := name
+		t := time.AfterFunc(grace, func() { s.stopSessionDaemon(taskName, sessionID) })
+		if s.sessions.pending == nil {
+			s.sessions.pending = make(map[string][]*time.Timer)
+		}
+		s.sessions.pending[sessionID] = append(s.sessions.pending[sessionID], t)
+	}
+}
+
+// stopSessionDaemon stops a daemon if the session still owns it.
+func (s *Server) stopSessionDaemon(taskName, sessionID string) {
+	s.sessions.mu.Lock()
+	if s.sessions.owners[taskName] != sessionID {
+		s.sessions.mu.Unlock()
+		return
+	}
+	delete(s.sessions.owners, taskName)
+	s.sessions.mu.Unlock()
+
+	result, err := s.Manager().StopDaemon(taskName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to stop session daemon %s: %v\n", taskName, err)
+		return
+	}
+	if result.Success {
+		fmt.Fprintf(os.Stderr, "Stopped session daemon %s: MCP session %s ended\n", taskName, sessionID)
+	}
+}
+
+// stopAllSessionDaemons stops every session-scoped daemon immediately. It is
+// used when the server itself exits.
+func (s *Server) stopAllSessionDaemons() {
+	s.sessions.mu.Lock()
+	owners := s.sessions.owners
+	s.sessions.owners = nil
+	for _, timers := range s.sessions.pending {
+		for _, t := range timers {
+			t.Stop()
+		}
+	}
+	s.sessions.pending = nil
+	s.sessions.mu.Unlock()
+
+	for _, name := range sortedKeys(owners) {
+		if _, err := s.Manager().StopDaemon(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to stop session daemon %s: %v\n", name, err)
+		}
+	}
+}
+
+// endSessionOnDelete wraps the MCP endpoint so a DELETE that terminates a
+// session ends it for session-scoped daemons. mcp-go does not run its
+// unregister hook for sessions that never opened a GET stream.
+func (s *Server) endSessionOnDelete(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.Method == http.MethodDelete {
+			if sessionID := r.Header.Get(server.HeaderKeySessionID); sessionID != "" {
+				s.sessionEnded(sessionID)
+			}
+		}
+	})
+}
+
+// taskDef returns the current definition of a task.
+func (s *Server) taskDef(name string) (config.Task, bool) {
+	task, ok := s.current().manifest.Tasks[name]
+	return task, ok
+}
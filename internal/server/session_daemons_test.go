@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+// fakeSession is a minimal MCP client session for tests.
+type fakeSession struct {
+	id     string
+	client string
+}
+
+func (f fakeSession) SessionID() string                                   { return f.id }
+func (f fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (f fakeSession) Initialize()                                         {}
+func (f fakeSession) Initialized() bool                                   { return true }
+func (f fakeSession) GetClientInfo() mcp.Implementation                   { return mcp.Implementation{Name: f.client} }
+func (f fakeSession) SetClientInfo(mcp.Implementation)                    {}
+func (f fakeSession) GetClientCapabilities() mcp.ClientCapabilities       { return mcp.ClientCapabilities{} }
+func (f fakeSession) SetClientCapabilities(mcp.ClientCapabilities)        {}
+
+var _ mcpserver.SessionWithClientInfo = fakeSession{}
+
+func newSessionDaemonServer(t *testing.T) *Server {
+	t.Helper()
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"dev":   {Description: "Dev server", Command: "sleep 30", Type: config.TaskTypeDaemon, Lifetime: config.LifetimeSession, SessionGrace: 1},
+			"db":    {Description: "Database", Command: "sleep 30", Type: config.TaskTypeDaemon},
+			"build": {Description: "Build", Command: "true", Type: config.TaskTypeOneShot},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.processManager = process.NewManager()
+	setManager(s, task.NewManager(manifest, s.processManager))
+	t.Cleanup(func() { _ = s.processManager.StopAll() })
+	return s
+}
+
+func sessionContext(s *Server, id, client string) context.Context {
+	return s.mcpServer.WithContext(context.Background(), fakeSession{id: id, client: client})
+}
+
+func daemonRunning(t *testing.T, s *Server, name string) bool {
+	t.Helper()
+	running, _, err := s.processManager.Status(name)
+	if err != nil {
+		t.Fatalf("status %s: %v", name, err)
+	}
+	return running
+}
+
+func TestSessionDaemonStoppedAfterDisconnect(t *testing.T) {
+	s := newSessionDaemonServer(t)
+	for _, name := range []string{"dev", "db"} {
+		if res, err := s.current().manager.StartDaemon(name, nil); err != nil || !res.Success {
+			t.Fatalf("start %s: %v %+v", name, err, res)
+		}
+	}
+	s.claimSessionDaemons(sessionContext(s, "agent-1", "agent"), "dev", "db")
+
+	if got := s.sessions.owners; len(got) != 1 || got["dev"] != "agent-1" {
+		t.Fatalf("owners = %v, want only dev owned by agent-1", got)
+	}
+
+	// A session that comes back within the grace period keeps its daemons
+	s.sessionEnded("agent-1")
+	s.sessionActive("agent-1")
+	time.Sleep(1500 * time.Millisecond)
+	if !daemonRunning(t, s, "dev") {
+		t.Fatal("dev stopped although the session came back")
+	}
+
+	s.sessionEnded("agent-1")
+	deadline := time.Now().Add(5 * time.Second)
+	for daemonRunning(t, s, "dev") {
+		if time.Now().After(deadline) {
+			t.Fatal("dev still running after the grace period")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if !daemonRunning(t, s, "db") {
+		t.Error("persistent daemon db was stopped with the session")
+	}
+}
+
+func TestSessionDaemonIgnoresCLIAndOtherSessions(t *testing.T) {
+	s := newSessionDaemonServer(t)
+
+	s.claimSessionDaemons(sessionContext(s, "cli", mcputil.CLIClientName), "dev")
+	if len(s.sessions.owners) != 0 {
+		t.Fatalf("CLI session claimed daemons: %v", s.sessions.owners)
+	}
+
+	s.claimSessionDaemons(sessionContext(s, "agent-1", "agent"), "dev")
+	s.sessionEnded("agent-2")
+	if len(s.sessions.pending) != 0 {
+		t.Errorf("ending an unrelated session scheduled stops: %v", s.sessions.pending)
+	}
+
+	s.releaseSessionDaemon("dev")
+	s.sessionEnded("agent-1")
+	if len(s.sessions.pending) != 0 {
+		t.Errorf("released daemon was scheduled to stop: %v", s.sessions.pending)
+	}
+}
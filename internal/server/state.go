@@ -0,0 +1,27 @@
+package server
+
+import (
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+// serverState is the config a server works from: the manifest, the task
+// manager built from it, and whether a config file was found. It is never
+// changed once stored; a refresh stores a new one, so a handler that reads
+// the state once sees a single config even when a refresh lands mid-call.
+type serverState struct {
+	manifest     *config.Manifest
+	manager      *task.Manager
+	configLoaded bool
+}
+
+// current returns the server's state. Callers that read more than one field,
+// or the same field twice, should call it once and keep the result.
+func (s *Server) current() *serverState {
+	return s.state.Load()
+}
+
+// setState replaces the server's state.
+func (s *Server) setState(manifest *config.Manifest, manager *task.Manager, configLoaded bool) {
+	s.state.Store(&serverState{manifest: manifest, manager: manager, configLoaded: configLoaded})
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/task"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -17,18 +21,43 @@ type oneShotResponse struct {
 	SessionID        string `json:"session_id,omitempty"`
 	LogPath          string `json:"log_path,omitempty"`
 	Success          bool   `json:"success"`
+	Status           string `json:"status,omitempty"`
+	StatusReason     string `json:"status_reason,omitempty"`
 	ExitCode         int    `json:"exit_code"`
 	Duration         string `json:"duration"`
 	Error            string `json:"error,omitempty"`
+	ErrorCode        string `json:"error_code,omitempty"` // Kind of failure, one of the task.ErrCode constants
 	TimedOut         bool   `json:"timed_out,omitempty"`
+	Timeout          int    `json:"timeout,omitempty"`
 	Stdout           string `json:"stdout,omitempty"`
 	StdoutLines      int    `json:"stdout_lines,omitempty"`
 	StdoutTotalLines int    `json:"stdout_total_lines,omitempty"`
 	StdoutTruncated  bool   `json:"stdout_truncated,omitempty"`
+	StdoutLog        string `json:"stdout_log,omitempty"`  // Session log with all of stdout, when log_output cut it
+	StdoutHint       string `json:"stdout_hint,omitempty"` // How to read the rest of stdout that log_output cut
 	Stderr           string `json:"stderr,omitempty"`
 	StderrLines      int    `json:"stderr_lines,omitempty"`
 	StderrTotalLines int    `json:"stderr_total_lines,omitempty"`
 	StderrTruncated  bool   `json:"stderr_truncated,omitempty"`
+	DaemonsStarted   []string `json:"daemons_started,omitempty"`
+	LatencyHint      *latencyHint `json:"latency_hint,omitempty"`
+	Resources        *logs.ResourceUsage `json:"resources,omitempty"`
+	Warnings         []string `json:"warnings,omitempty"` // Deprecated parameter names used by the call
+	Hint             *usageHint `json:"hint,omitempty"` // Set when a failed call left out required parameters
+	Workdir          *logs.WorkdirFingerprint `json:"workdir_fingerprint,omitempty"`
+	Artifacts        []logs.Artifact `json:"artifacts,omitempty"`
+	ArtifactsDir     string `json:"artifacts_dir,omitempty"`
+	Output           json.RawMessage `json:"output,omitempty"` // Stdout parsed as JSON; stdout is then omitted
+	OutputError      string `json:"output_error,omitempty"`
+	RetryAfter       int    `json:"retry_after,omitempty"` // Seconds until a rate-limited run is allowed
+	PreconditionFailed *task.PreconditionFailure `json:"precondition_failed,omitempty"`
+	Cached           bool   `json:"cached,omitempty"` // Result of an earlier run with the same cache key
+}
+
+// MarshalOneShotResult returns the JSON a run_ tool responds with for
+// result, with output untruncated, so CLI runs can print the same result.
+func MarshalOneShotResult(result *task.ExecutionResult) ([]byte, error) {
+	return json.Marshal(newOneShotResponse(result, 0))
 }
 
 // mcpOutputMaxLines is the maximum number of output lines returned in MCP responses.
@@ -59,31 +88,170 @@ func truncateToLines(s string, max int) (result string, shown int, total int) {
 	return strings.Join(lines, "\n"), len(lines), total
 }
 
+// newOneShotResponse builds the MCP response for an execution result,
+// truncating stdout and stderr to the last maxLines lines (0 = unlimited).
+// Stdout is left out when it was parsed into output.
+func newOneShotResponse(result *task.ExecutionResult, maxLines int) oneShotResponse {
+	// Parsed output replaces the text it was parsed from
+	stdout, stdoutShown, stdoutTotal := "", 0, 0
+	if result.Output == nil {
+		stdout, stdoutShown, stdoutTotal = truncateToLines(result.Stdout, maxLines)
+	}
+	stderr, stderrShown, stderrTotal := truncateToLines(result.Stderr, maxLines)
+	// The task kept only part of stdout; count the lines it had, and point
+	// at the session log that has all of them
+	stdoutHint := ""
+	if result.StdoutLog != "" {
+		stdoutTotal = max(stdoutTotal, result.StdoutTotalLines)
+		stdoutHint = fmt.Sprintf("log_output cut stdout; read all %d lines with read_session_log with session_id %q and stream %q",
+			result.StdoutTotalLines, result.SessionID, logs.StreamStdout)
+	}
+
+	return oneShotResponse{
+		TaskName:         result.TaskName,
+		SessionID:        result.SessionID,
+		LogPath:          result.LogPath,
+		Success:          result.Success,
+		Status:           result.Status,
+		StatusReason:     result.StatusReason,
+		ExitCode:         result.ExitCode,
+		Duration:         result.Duration.String(),
+		Error:            result.Error,
+		ErrorCode:        result.ErrorCode,
+		TimedOut:         result.TimedOut,
+		Timeout:          result.Timeout,
+		Stdout:           stdout,
+		StdoutLines:      stdoutShown,
+		StdoutTotalLines: stdoutTotal,
+		StdoutTruncated:  stdoutTotal > stdoutShown,
+		StdoutLog:        result.StdoutLog,
+		StdoutHint:       stdoutHint,
+		Stderr:           stderr,
+		StderrLines:      stderrShown,
+		StderrTotalLines: stderrTotal,
+		StderrTruncated:  stderrTotal > stderrShown,
+		DaemonsStarted:   result.DaemonsStarted,
+		Resources:        result.Resources,
+		Workdir:          result.Workdir,
+		Artifacts:        result.Artifacts,
+		ArtifactsDir:     artifactsDir(result),
+		Output:           result.Output,
+		OutputError:      result.OutputError,
+		RetryAfter:       result.RetryAfter,
+		PreconditionFailed: result.PreconditionFailed,
+		Cached:           result.Cached,
+	}
+}
+
+// artifactsDir is where a result's artifacts were copied, if it has any.
+func artifactsDir(result *task.ExecutionResult) string {
+	if len(result.Artifacts) == 0 {
+		return ""
+	}
+	return logs.GetSessionArtifactsDir(result.SessionID)
+}
+
 // registerTools registers all tasks as MCP tools
 func (s *Server) registerTools() {
+	manifest := s.current().manifest
+
 	// Register session management tools
 	s.registerSessionManagementTools()
 
 	// Register task-specific tools
-	for taskName, taskDef := range s.manifest.Tasks {
+	for taskName, taskDef := range manifest.Tasks {
 		if taskDef.Disabled || taskDef.DisableMCP {
 			continue
 		}
 		switch taskDef.Type {
-		case config.TaskTypeOneShot:
+		case config.TaskTypeOneShot, config.TaskTypeFileOps:
 			s.registerOneShotTool(taskName, taskDef)
-		case config.TaskTypeDaemon:
+		case config.TaskTypeDaemon, config.TaskTypeCompose:
 			s.registerDaemonTools(taskName, taskDef)
 		}
 	}
 
 	// Register workflow tools
 	s.registerWorkflowTools()
+
+	// Register tools that act on every daemon at once
+	if len(s.mcpDaemonNames()) > 0 {
+		s.registerBulkDaemonTools()
+	}
+
+	// Register the ad-hoc exec tool if the manifest opts in
+	if manifest.Exec.Enabled {
+		s.registerExecCommandTool()
+	}
+
+	// Register the manifest editing tools if the manifest opts in
+	if manifest.Server.AllowTaskEdits {
+		s.registerEditTools()
+	}
+}
+
+// timeoutOverrideSchema is the input schema property for overriding a task's
+// timeout on a single run_ call.
+func timeoutOverrideSchema(def config.Task) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "number",
+		"description": fmt.Sprintf("Timeout in seconds for this call, overriding the task's timeout (max %d)", task.MaxTimeout(def)),
+	}
+}
+
+// addNoCacheSchema adds the cache bypass argument to the input schema of a
+// task with a cache, unless the task defines a parameter of the same name.
+func addNoCacheSchema(inputSchema mcp.ToolInputSchema, def config.Task) {
+	if _, defined := def.Parameters[task.NoCacheParam]; def.Cache == nil || defined {
+		return
+	}
+	inputSchema.Properties[task.NoCacheParam] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "Run the command even if a cached result exists for the same inputs, and cache the new result",
+	}
+}
+
+// buildParamSchema returns the JSON Schema of a parameter, with its
+// pattern, enum, min/max, and length rules so clients can check arguments
+// before calling the tool.
+func buildParamSchema(param config.Param) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":        param.Type,
+		"description": param.Description,
+	}
+	if param.Pattern != "" {
+		schema["pattern"] = param.Pattern
+	}
+	if len(param.Enum) > 0 {
+		enum := make([]interface{}, len(param.Enum))
+		for i, value := range param.Enum {
+			enum[i] = value
+			if param.Type == "number" || param.Type == "integer" {
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					enum[i] = n
+				}
+			}
+		}
+		schema["enum"] = enum
+	}
+	if param.Min != nil {
+		schema["minimum"] = *param.Min
+	}
+	if param.Max != nil {
+		schema["maximum"] = *param.Max
+	}
+	if param.MinLength != nil {
+		schema["minLength"] = *param.MinLength
+	}
+	if param.MaxLength != nil {
+		schema["maxLength"] = *param.MaxLength
+	}
+	return schema
 }
 
 // registerOneShotTool registers a one-shot task as an MCP tool
 func (s *Server) registerOneShotTool(taskName string, task config.Task) {
-	toolName := "run_" + taskName
+	toolName := s.current().manifest.ToolName(config.ToolRun, taskName)
 
 	// Build input schema
 	inputSchema := mcp.ToolInputSchema{
@@ -93,11 +261,7 @@ func (s *Server) registerOneShotTool(taskName string, task config.Task) {
 	}
 
 	for paramName, param := range task.Parameters {
-		paramSchema := map[string]interface{}{
-			"type":        param.Type,
-			"description": param.Description,
-		}
-		inputSchema.Properties[paramName] = paramSchema
+		inputSchema.Properties[paramName] = buildParamSchema(param)
 		if param.Required {
 			inputSchema.Required = append(inputSchema.Required, paramName)
 		}
@@ -111,20 +275,60 @@ func (s *Server) registerOneShotTool(taskName string, task config.Task) {
 		}
 	}
 
+	// Add timeout override unless the task defines its own timeout parameter
+	if _, defined := task.Parameters["timeout"]; !defined {
+		inputSchema.Properties["timeout"] = timeoutOverrideSchema(task)
+	}
+
+	addNoCacheSchema(inputSchema, task)
+
+	if len(task.ParameterPresets) > 0 {
+		inputSchema.Properties[config.PresetParam] = map[string]interface{}{
+			"type":        "string",
+			"enum":        task.PresetNames(),
+			"description": "Named set of parameter values to use; parameters passed explicitly override it",
+		}
+	}
+
 	// Add max_output_lines parameter for clients that want unlimited output
 	inputSchema.Properties["max_output_lines"] = map[string]interface{}{
 		"type":        "number",
 		"description": "Maximum output lines to return per stream (default 100, 0=unlimited). For CLI use.",
 	}
 
+	if task.RequiresConfirmation {
+		inputSchema.Properties[ConfirmationTokenParam] = confirmationTokenSchema()
+	}
+
+	if !task.Interactive {
+		inputSchema.Properties[RunAsyncParam] = runAsyncSchema(task)
+	}
+
+	description := task.Description
+	if task.Interactive {
+		description += fmt.Sprintf(" (Interactive: needs a terminal, so calls are rejected; ask the user to run 'runbook run --local %s'.)", taskName)
+	}
 	tool := mcp.Tool{
 		Name:        toolName,
-		Description: task.Description,
+		Description: description,
 		InputSchema: inputSchema,
+		Annotations: toolAnnotations(task.Hints),
 	}
 
+	budget := resolveLatencyBudget(task.LatencyBudget)
+
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		params := req.GetArguments()
+		warnings, err := config.ResolveParamNames(task.Parameters, params)
+		if err != nil {
+			return argumentError(err.Error()), nil
+		}
+		if preset, ok := params[config.PresetParam].(string); ok && len(task.ParameterPresets) > 0 {
+			delete(params, config.PresetParam)
+			if err := config.ApplyPreset(task, preset, params); err != nil {
+				return argumentError(err.Error()), nil
+			}
+		}
 
 		// Read and remove max_output_lines before passing to task executor
 		maxLines := mcpOutputMaxLines
@@ -132,39 +336,41 @@ func (s *Server) registerOneShotTool(taskName string, task config.Task) {
 			maxLines = int(v)
 			delete(params, "max_output_lines")
 		}
+		async := takeRunAsync(task, params)
+
+		if task.RequiresConfirmation {
+			preview := func() string { return s.taskPreview(taskName, params) }
+			if res := s.confirmCall(toolName, params, preview); res != nil {
+				return res, nil
+			}
+		}
 
-		result, err := s.manager.ExecuteOneShot(taskName, params)
+		if async {
+			return s.runOneShotAsync(taskName, params, warnings)
+		}
+
+		start := time.Now()
+		result, err := s.current().manager.ExecuteOneShot(taskName, params)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return toolError(err), nil
 		}
+		s.claimSessionDaemons(ctx, result.DaemonsStarted...)
 
-		stdout, stdoutShown, stdoutTotal := truncateToLines(result.Stdout, maxLines)
-		stderr, stderrShown, stderrTotal := truncateToLines(result.Stderr, maxLines)
-
-		resp := oneShotResponse{
-			TaskName:         result.TaskName,
-			SessionID:        result.SessionID,
-			LogPath:          result.LogPath,
-			Success:          result.Success,
-			ExitCode:         result.ExitCode,
-			Duration:         result.Duration.String(),
-			Error:            result.Error,
-			TimedOut:         result.TimedOut,
-			Stdout:           stdout,
-			StdoutLines:      stdoutShown,
-			StdoutTotalLines: stdoutTotal,
-			StdoutTruncated:  stdoutTotal > stdoutShown,
-			Stderr:           stderr,
-			StderrLines:      stderrShown,
-			StderrTotalLines: stderrTotal,
-			StderrTruncated:  stderrTotal > stderrShown,
+		resp := newOneShotResponse(result, maxLines)
+		resp.LatencyHint = s.checkLatency(toolName, time.Since(start), budget, result.SessionID)
+		resp.Warnings = warnings
+		if !result.Success {
+			resp.Hint = missingParamsHint(toolName, task.Parameters, params)
 		}
 
 		resultJSON, err := json.Marshal(resp)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+			return marshalError(err), nil
 		}
 
+		if resp.Output != nil {
+			return mcp.NewToolResultStructured(resp, string(resultJSON)), nil
+		}
 		return mcp.NewToolResultText(string(resultJSON)), nil
 	}
 
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+// RunAsyncParam is the run_ tool argument that starts the task in the
+// background and returns its session ID at once, overriding the task's
+// async setting.
+const RunAsyncParam = "run_async"
+
+// maxResultWait bounds how long a task_result call waits for a run, in
+// seconds, so a call cannot block until the client gives up.
+const maxResultWait = 300
+
+// asyncRunResponse is a run_ tool result for a run started in the background.
+type asyncRunResponse struct {
+	TaskName  string   `json:"task_name"`
+	SessionID string   `json:"session_id"`
+	Async     bool     `json:"async"`
+	State     string   `json:"state"`
+	Hint      string   `json:"hint"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// runAsyncSchema is the input schema property for run_async.
+func runAsyncSchema(def config.Task) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "boolean",
+		"description": fmt.Sprintf("Start the task in the background and return its session_id at once; follow it with task_status and task_result (default %t)",
+			def.Async),
+	}
+}
+
+// takeRunAsync removes run_async from params and reports whether the call
+// runs in the background. The CLI proxy sends flags as strings.
+func takeRunAsync(def config.Task, params map[string]interface{}) bool {
+	value, ok := params[RunAsyncParam]
+	if !ok {
+		return def.Async
+	}
+	delete(params, RunAsyncParam)
+	async, err := strconv.ParseBool(fmt.Sprint(value))
+	if err != nil {
+		return def.Async
+	}
+	return async
+}
+
+// runOneShotAsync starts a run_ call in the background and answers with its
+// session ID.
+func (s *Server) runOneShotAsync(taskName string, params map[string]interface{}, warnings []string) (*mcp.CallToolResult, error) {
+	sessionID, err := s.current().manager.ExecuteOneShotAsync(taskName, params)
+	if err != nil {
+		return toolError(err), nil
+	}
+	resultJSON, _ := json.Marshal(asyncRunResponse{
+		TaskName:  taskName,
+		SessionID: sessionID,
+		Async:     true,
+		State:     task.BackgroundRunning,
+		Hint:      "Poll task_status with this session_id, then call task_result for the output",
+		Warnings:  warnings,
+	})
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// registerBackgroundTools registers task_status and task_result, which follow
+// runs started with run_async.
+func (s *Server) registerBackgroundTools() {
+	s.mcpServer.AddTool(mcp.Tool{
+		Name: "task_status",
+		Description: "Check on a task run by session ID: whether it is running or completed, how long it has taken, " +
+			"and once completed its success and exit code. Use it to poll runs started with run_async.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"session_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Session ID returned by a run_ call",
+				},
+			},
+			Required: []string{"session_id"},
+		},
+	}, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionID, ok := req.GetArguments()["session_id"].(string)
+		if !ok || sessionID == "" {
+			return argumentError("session_id is required"), nil
+		}
+		status, err := s.current().manager.BackgroundStatus(sessionID)
+		if err != nil {
+			return toolError(err), nil
+		}
+		resultJSON, _ := json.Marshal(status)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	})
+
+	s.mcpServer.AddTool(mcp.Tool{
+		Name: "task_result",
+		Description: "Get the result of a run started with run_async, as its run_ tool would have returned it. " +
+			"Fails while the run is in progress unless wait gives it time to finish.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"session_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Session ID returned by a run_ call with run_async",
+				},
+				"wait": map[string]interface{}{
+					"type":        "number",
+					"description": fmt.Sprintf("Seconds to wait for the run to finish (default 0, max %d)", maxResultWait),
+				},
+				"max_output_lines": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum output lines to return per stream (default 100, 0=unlimited). For CLI use.",
+				},
+			},
+			Required: []string{"session_id"},
+		},
+	}, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		sessionID, ok := args["session_id"].(string)
+		if !ok || sessionID == "" {
+			return argumentError("session_id is required"), nil
+		}
+		var wait time.Duration
+		if v, ok := args["wait"].(float64); ok && v > 0 {
+			wait = time.Duration(min(v, maxResultWait) * float64(time.Second))
+		}
+		maxLines := mcpOutputMaxLines
+		if v, ok := args["max_output_lines"].(float64); ok {
+			maxLines = int(v)
+		}
+
+		result, err := s.current().manager.BackgroundResult(sessionID, wait)
+		if errors.Is(err, task.ErrRunInProgress) {
+			return codedToolError(task.ErrCodeInProgress, fmt.Sprintf("session '%s' is still running; call task_result again later or pass wait", sessionID)), nil
+		}
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		resultJSON, err := json.Marshal(newOneShotResponse(result, maxLines))
+		if err != nil {
+			return marshalError(err), nil
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	})
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestRunAsync(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"suite": {Description: "Slow suite", Command: "while [ ! -f go ]; do sleep 0.05; done; echo passed", Type: config.TaskTypeOneShot, Async: true},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	var started asyncRunResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_suite", map[string]interface{}{})), &started); err != nil {
+		t.Fatal(err)
+	}
+	if !started.Async || started.SessionID == "" || started.State != task.BackgroundRunning {
+		t.Fatalf("expected the run to start in the background, got %+v", started)
+	}
+
+	var status task.BackgroundStatus
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "task_status", map[string]interface{}{"session_id": started.SessionID})), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.State != task.BackgroundRunning || status.TaskName != "suite" {
+		t.Errorf("expected a running status, got %+v", status)
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"session_id": started.SessionID}
+	res, err := s.mcpServer.GetTool("task_result").Handler(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, "still running") {
+		t.Errorf("expected task_result to fail while running, got %+v", res.Content)
+	}
+
+	if err := os.WriteFile("go", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var result oneShotResponse
+	text := callTextTool(t, s, "task_result", map[string]interface{}{"session_id": started.SessionID, "wait": float64(10)})
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.SessionID != started.SessionID || !strings.Contains(result.Stdout, "passed") {
+		t.Errorf("expected the finished run's result, got %s", text)
+	}
+
+	// run_async: false blocks as usual
+	text = callTextTool(t, s, "run_suite", map[string]interface{}{RunAsyncParam: "false"})
+	if err := json.Unmarshal([]byte(text), &result); err != nil || !result.Success {
+		t.Errorf("expected a blocking run, got %s", text)
+	}
+}
+
+func TestBackgroundRunsWait(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"suite": {Description: "Slow suite", Command: "while [ ! -f go ]; do sleep 0.05; done; echo passed", Type: config.TaskTypeOneShot, Async: true},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	var started asyncRunResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_suite", map[string]interface{}{})), &started); err != nil {
+		t.Fatal(err)
+	}
+	background := s.current().manager.BackgroundRuns()
+	if background.Running() != 1 {
+		t.Fatalf("Running = %d, want 1", background.Running())
+	}
+	if background.Wait(20 * time.Millisecond) {
+		t.Error("Wait should time out while the run is still going")
+	}
+
+	if err := os.WriteFile("go", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !background.Wait(10 * time.Second) {
+		t.Fatal("Wait should report that the run finished")
+	}
+	if background.Running() != 0 {
+		t.Errorf("Running = %d after Wait, want 0", background.Running())
+	}
+	metadata, err := logs.ReadSessionMetadata(started.SessionID)
+	if err != nil || metadata.EndTime == nil || metadata.Success == nil || !*metadata.Success {
+		t.Errorf("expected the run's exit metadata to be written, got %+v (%v)", metadata, err)
+	}
+}
+
+func TestRunIgnoresClientSessionID(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"greet": {Description: "Greet", Command: "echo hello", Type: config.TaskTypeOneShot},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+	mux := http.NewServeMux()
+	s.registerAPI(mux)
+	const chosen = "../../../escaped"
+
+	var started asyncRunResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_greet", map[string]interface{}{"__session_id": chosen, RunAsyncParam: true})), &started); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uuid.Parse(started.SessionID); err != nil {
+		t.Errorf("expected a generated session ID for the async run, got %q", started.SessionID)
+	}
+	if !s.current().manager.BackgroundRuns().Wait(10 * time.Second) {
+		t.Fatal("background run did not finish")
+	}
+
+	var result oneShotResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_greet", map[string]interface{}{"__session_id": chosen})), &result); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uuid.Parse(result.SessionID); err != nil || !result.Success {
+		t.Errorf("expected a successful run with a generated session ID, got %+v", result)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", APIPath+"/tasks/greet/run", strings.NewReader(`{"__session_id": "`+chosen+`"}`)))
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uuid.Parse(result.SessionID); err != nil || !result.Success {
+		t.Errorf("expected the API run to get a generated session ID, got %s", rec.Body.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(logs.LogDir, "sessions", chosen)); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written at the client's session path, got %v", err)
+	}
+}
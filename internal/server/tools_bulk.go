@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/task"
+)
+
+// bulkToolNames are the tools that act on every daemon at once, registered
+// when the manifest has at least one daemon exposed over MCP.
+var bulkToolNames = []string{"stop_all_daemons", "restart_all_daemons", "status_all"}
+
+// bulkResponse is the MCP response of stop_all_daemons and
+// restart_all_daemons. Success is false if any daemon failed.
+type bulkResponse struct {
+	Success bool                    `json:"success"`
+	Daemons []task.DaemonBulkResult `json:"daemons"`
+}
+
+// statusAllResponse is the MCP response of status_all.
+type statusAllResponse struct {
+	Running int                      `json:"running"`
+	Total   int                      `json:"total"`
+	Daemons []task.NamedDaemonStatus `json:"daemons"`
+}
+
+// newBulkResponse wraps per-daemon results.
+func newBulkResponse(results []task.DaemonBulkResult) bulkResponse {
+	resp := bulkResponse{Success: true, Daemons: results}
+	for _, r := range results {
+		if !r.Success {
+			resp.Success = false
+		}
+	}
+	return resp
+}
+
+// mcpDaemonNames returns the daemons exposed over MCP, sorted by name.
+func (s *Server) mcpDaemonNames() []string {
+	var names []string
+	for name, t := range s.current().manifest.Tasks {
+		if t.Type.IsDaemon() && !t.Disabled && !t.DisableMCP {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bulkDaemonNames returns the daemons a bulk tool call acts on: those named
+// in its optional tasks argument, or else every daemon exposed over MCP.
+func (s *Server) bulkDaemonNames(args map[string]interface{}) ([]string, error) {
+	all := s.mcpDaemonNames()
+	requested, _ := args["tasks"].([]interface{})
+	delete(args, "tasks")
+	if len(requested) == 0 {
+		return all, nil
+	}
+	var names []string
+	for _, v := range requested {
+		name, _ := v.(string)
+		if !slices.Contains(all, name) {
+			return nil, &task.CodedError{Code: task.ErrCodeNotFound, Err: fmt.Errorf("'%v' is not a daemon", v)}
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// registerBulkDaemonTools registers stop_all_daemons, restart_all_daemons,
+// and status_all.
+func (s *Server) registerBulkDaemonTools() {
+	tasksSchema := map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Only these daemons (default: all)",
+	}
+	noArgs := mcp.ToolInputSchema{Type: "object", Properties: map[string]interface{}{"tasks": tasksSchema}}
+
+	s.mcpServer.AddTool(mcp.Tool{
+		Name:        "stop_all_daemons",
+		Description: "Stop every running daemon in one call, each before the daemons it requires. Returns a result per daemon.",
+		InputSchema: noArgs,
+	}, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		names, err := s.bulkDaemonNames(req.GetArguments())
+		if err != nil {
+			return toolError(err), nil
+		}
+		results := s.current().manager.StopDaemons(names)
+		for _, r := range results {
+			if r.Action == task.BulkActionStopped {
+				s.releaseSessionDaemon(r.Task)
+			}
+		}
+		resultJSON, _ := json.Marshal(newBulkResponse(results))
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	})
+
+	restartSchema := mcp.ToolInputSchema{
+		Type:       "object",
+		Properties: map[string]interface{}{"tasks": tasksSchema, ConfirmationTokenParam: confirmationTokenSchema()},
+	}
+	s.mcpServer.AddTool(mcp.Tool{
+		Name: "restart_all_daemons",
+		Description: "Restart every running daemon with the parameters it was started with: all are stopped, then started again in dependency order. " +
+			"Stopped daemons are left alone. Returns a result per daemon.",
+		InputSchema: restartSchema,
+	}, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		params := req.GetArguments()
+		names, err := s.bulkDaemonNames(params)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		// Restarting starts daemons again, so those that need confirmation
+		// to start need it here too
+		st := s.current()
+		var gated []string
+		for _, name := range names {
+			if !st.manifest.Tasks[name].RequiresConfirmation {
+				continue
+			}
+			if status, err := st.manager.DaemonStatus(name); err == nil && status.Running {
+				gated = append(gated, name)
+			}
+		}
+		if len(gated) > 0 {
+			preview := func() string { return "restart " + strings.Join(gated, ", ") }
+			if res := s.confirmCall("restart_all_daemons", params, preview); res != nil {
+				return res, nil
+			}
+		}
+
+		resultJSON, _ := json.Marshal(newBulkResponse(st.manager.RestartDaemons(names)))
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	})
+
+	s.mcpServer.AddTool(mcp.Tool{
+		Name:        "status_all",
+		Description: "Show the status of every daemon in one call: whether it is running, its PID, uptime, and session.",
+		InputSchema: noArgs,
+	}, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		names, err := s.bulkDaemonNames(req.GetArguments())
+		if err != nil {
+			return toolError(err), nil
+		}
+		statuses := s.current().manager.DaemonStatuses(names)
+		resp := statusAllResponse{Total: len(statuses), Daemons: statuses}
+		for _, st := range statuses {
+			if st.Running {
+				resp.Running++
+			}
+		}
+		resultJSON, _ := json.Marshal(resp)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	})
+}
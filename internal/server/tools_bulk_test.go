@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+)
+
+func TestBulkDaemonToolsRegisteredOnlyWithDaemons(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build": {Type: config.TaskTypeOneShot, Command: "go build"},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+	for _, name := range bulkToolNames {
+		if s.mcpServer.GetTool(name) != nil {
+			t.Errorf("expected %s not to be registered without daemons", name)
+		}
+	}
+
+	manifest.Tasks["serve"] = config.Task{Type: config.TaskTypeDaemon, Command: "go run ."}
+	s = newTestServer(t, manifest)
+	s.registerTools()
+	for _, name := range bulkToolNames {
+		if s.mcpServer.GetTool(name) == nil {
+			t.Errorf("expected %s to be registered", name)
+		}
+	}
+}
+
+func TestBulkDaemonNames(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"web":    {Type: config.TaskTypeDaemon, Command: "serve"},
+			"db":     {Type: config.TaskTypeDaemon, Command: "db"},
+			"hidden": {Type: config.TaskTypeDaemon, Command: "x", DisableMCP: true},
+			"build":  {Type: config.TaskTypeOneShot, Command: "go build"},
+		},
+	}
+	s := newTestServer(t, manifest)
+
+	names, err := s.bulkDaemonNames(map[string]interface{}{})
+	if err != nil || len(names) != 2 || names[0] != "db" || names[1] != "web" {
+		t.Fatalf("expected [db web], got %v (%v)", names, err)
+	}
+
+	names, err = s.bulkDaemonNames(map[string]interface{}{"tasks": []interface{}{"web"}})
+	if err != nil || len(names) != 1 || names[0] != "web" {
+		t.Fatalf("expected [web], got %v (%v)", names, err)
+	}
+
+	for _, bad := range []string{"build", "hidden", "missing"} {
+		if _, err := s.bulkDaemonNames(map[string]interface{}{"tasks": []interface{}{bad}}); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+
+	s.registerTools()
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"tasks": []interface{}{"build"}}
+	res, _ := s.mcpServer.GetTool("status_all").Handler(context.Background(), req)
+	if !res.IsError {
+		t.Error("expected status_all to reject a task that is not a daemon")
+	}
+}
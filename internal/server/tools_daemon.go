@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/task"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -16,10 +18,33 @@ func (s *Server) registerDaemonTools(taskName string, task config.Task) {
 	s.registerDaemonStopTool(taskName, task)
 	s.registerDaemonStatusTool(taskName, task)
 	s.registerDaemonLogsTool(taskName, task)
+	if task.Interactive {
+		s.registerDaemonInputTool(taskName, task)
+	}
+	if task.Reload {
+		s.registerDaemonReloadTool(taskName, task)
+	}
+}
+
+// FreshParam is the start_ tool argument requesting a clean restart of the
+// daemon instead of failing when it is already running.
+const FreshParam = "fresh"
+
+// daemonStartResponse is a start_ tool result.
+type daemonStartResponse struct {
+	*task.DaemonStartResult
+	Warnings []string   `json:"warnings,omitempty"` // Deprecated parameter names used by the call
+	Hint     *usageHint `json:"hint,omitempty"`     // Set when a failed call left out required parameters
+}
+
+// daemonStatusResponse is the MCP response of a status_ tool.
+type daemonStatusResponse struct {
+	*task.DaemonStatus
+	Hint *usageHint `json:"hint,omitempty"` // Set when the daemon has never been started
 }
 
 func (s *Server) registerDaemonStartTool(taskName string, task config.Task) {
-	toolName := "start_" + taskName
+	toolName := s.current().manifest.ToolName(config.ToolStart, taskName)
 
 	// Build input schema with task parameters
 	inputSchema := mcp.ToolInputSchema{
@@ -29,11 +54,7 @@ func (s *Server) registerDaemonStartTool(taskName string, task config.Task) {
 	}
 
 	for paramName, param := range task.Parameters {
-		paramSchema := map[string]interface{}{
-			"type":        param.Type,
-			"description": param.Description,
-		}
-		inputSchema.Properties[paramName] = paramSchema
+		inputSchema.Properties[paramName] = buildParamSchema(param)
 		if param.Required {
 			inputSchema.Required = append(inputSchema.Required, paramName)
 		}
@@ -47,21 +68,62 @@ func (s *Server) registerDaemonStartTool(taskName string, task config.Task) {
 		}
 	}
 
+	// A task parameter of the same name takes precedence over fresh
+	_, freshTaken := task.Parameters[FreshParam]
+	if !freshTaken {
+		inputSchema.Properties[FreshParam] = map[string]interface{}{
+			"type":        "boolean",
+			"description": "Stop the daemon if it is running, clear its previous session, start it and wait until it is ready, as one operation",
+		}
+	}
+
+	if task.RequiresConfirmation {
+		inputSchema.Properties[ConfirmationTokenParam] = confirmationTokenSchema()
+	}
+
 	tool := mcp.Tool{
 		Name:        toolName,
 		Description: fmt.Sprintf("Start daemon: %s", task.Description),
 		InputSchema: inputSchema,
+		Annotations: toolAnnotations(task.Hints),
 	}
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		params := req.GetArguments()
+		warnings, err := config.ResolveParamNames(task.Parameters, params)
+		if err != nil {
+			return argumentError(err.Error()), nil
+		}
 
-		result, err := s.manager.StartDaemon(taskName, params)
+		if task.RequiresConfirmation {
+			preview := func() string { return s.taskPreview(taskName, params) }
+			if res := s.confirmCall(toolName, params, preview); res != nil {
+				return res, nil
+			}
+		}
+
+		manager := s.current().manager
+		start := manager.StartDaemon
+		if !freshTaken {
+			// The CLI proxy sends flags as strings
+			if fresh, _ := strconv.ParseBool(fmt.Sprint(params[FreshParam])); fresh {
+				start = manager.StartDaemonFresh
+			}
+			delete(params, FreshParam)
+		}
+		result, err := start(taskName, params)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return toolError(err), nil
+		}
+		if result.Success {
+			s.claimSessionDaemons(ctx, taskName)
 		}
 
-		resultJSON, _ := json.Marshal(result)
+		resp := daemonStartResponse{DaemonStartResult: result, Warnings: warnings}
+		if !result.Success {
+			resp.Hint = missingParamsHint(toolName, task.Parameters, params)
+		}
+		resultJSON, _ := json.Marshal(resp)
 		return mcp.NewToolResultText(string(resultJSON)), nil
 	}
 
@@ -69,7 +131,7 @@ func (s *Server) registerDaemonStartTool(taskName string, task config.Task) {
 }
 
 func (s *Server) registerDaemonStopTool(taskName string, task config.Task) {
-	toolName := "stop_" + taskName
+	toolName := s.current().manifest.ToolName(config.ToolStop, taskName)
 
 	tool := mcp.Tool{
 		Name:        toolName,
@@ -78,10 +140,11 @@ func (s *Server) registerDaemonStopTool(taskName string, task config.Task) {
 	}
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		result, err := s.manager.StopDaemon(taskName)
+		result, err := s.current().manager.StopDaemon(taskName)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return toolError(err), nil
 		}
+		s.releaseSessionDaemon(taskName)
 
 		resultJSON, _ := json.Marshal(result)
 		return mcp.NewToolResultText(string(resultJSON)), nil
@@ -91,7 +154,7 @@ func (s *Server) registerDaemonStopTool(taskName string, task config.Task) {
 }
 
 func (s *Server) registerDaemonStatusTool(taskName string, task config.Task) {
-	toolName := "status_" + taskName
+	toolName := s.current().manifest.ToolName(config.ToolStatus, taskName)
 
 	tool := mcp.Tool{
 		Name:        toolName,
@@ -100,12 +163,15 @@ func (s *Server) registerDaemonStatusTool(taskName string, task config.Task) {
 	}
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		status, err := s.manager.DaemonStatus(taskName)
+		status, err := s.current().manager.DaemonStatus(taskName)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return toolError(err), nil
 		}
 
-		resultJSON, _ := json.Marshal(status)
+		resultJSON, _ := json.Marshal(daemonStatusResponse{
+			DaemonStatus: status,
+			Hint:         neverStartedHint(s.current().manifest, taskName, status.Running, status.LastEvents),
+		})
 		return mcp.NewToolResultText(string(resultJSON)), nil
 	}
 
@@ -133,12 +199,17 @@ func daemonLogsInputSchema() mcp.ToolInputSchema {
 				"type":        "number",
 				"description": "Skip the last N lines (for paging backwards through history)",
 			},
+			"stream": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{logs.StreamStdout, logs.StreamStderr},
+				"description": "Only return stdout or stderr (default: both, interleaved)",
+			},
 		},
 	}
 }
 
 func (s *Server) registerDaemonLogsTool(taskName string, task config.Task) {
-	toolName := "logs_" + taskName
+	toolName := s.current().manifest.ToolName(config.ToolLogs, taskName)
 
 	inputSchema := daemonLogsInputSchema()
 
@@ -164,10 +235,13 @@ func (s *Server) registerDaemonLogsTool(taskName string, task config.Task) {
 		if offset, ok := args["offset"].(float64); ok {
 			opts.Offset = int(offset)
 		}
+		if stream, ok := args["stream"].(string); ok {
+			opts.Stream = stream
+		}
 
 		logLines, totalLines, err := logs.ReadLog(taskName, opts)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to read logs: %v", err)), nil
+			return readError("failed to read logs", err), nil
 		}
 
 		result := map[string]interface{}{
@@ -183,3 +257,67 @@ func (s *Server) registerDaemonLogsTool(taskName string, task config.Task) {
 
 	s.mcpServer.AddTool(tool, handler)
 }
+
+func (s *Server) registerDaemonInputTool(taskName string, task config.Task) {
+	toolName := s.current().manifest.ToolName(config.ToolSendInput, taskName)
+
+	tool := mcp.Tool{
+		Name:        toolName,
+		Description: fmt.Sprintf("Send input to daemon: %s. Output appears in %s.", task.Description, s.current().manifest.ToolName(config.ToolLogs, taskName)),
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"input": map[string]interface{}{
+					"type":        "string",
+					"description": "Text to write to the daemon's stdin; control characters such as \u0003 (Ctrl-C) are passed through",
+				},
+				"newline": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Append a newline to the input (default: true)",
+				},
+			},
+			Required: []string{"input"},
+		},
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		input, _ := args["input"].(string)
+		if newline, ok := args["newline"].(bool); !ok || newline {
+			input += "\n"
+		}
+
+		result, err := s.current().manager.SendInput(taskName, input)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		resultJSON, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
+
+func (s *Server) registerDaemonReloadTool(taskName string, task config.Task) {
+	toolName := s.current().manifest.ToolName(config.ToolReload, taskName)
+
+	tool := mcp.Tool{
+		Name: toolName,
+		Description: fmt.Sprintf("Reload daemon without restarting it, by sending it SIG%s: %s",
+			task.ReloadSignalName(), task.Description),
+		InputSchema: mcp.ToolInputSchema{Type: "object", Properties: make(map[string]interface{})},
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := s.current().manager.ReloadDaemon(taskName)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		resultJSON, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
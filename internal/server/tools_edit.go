@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/auth"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+// editToolNames are the manifest editing tools, registered when the manifest
+// sets server.allow_task_edits.
+var editToolNames = []string{"add_task", "update_task", "remove_task"}
+
+// registerEditTools registers add_task, update_task, and remove_task, which
+// change task definitions in the config files and reload the server. Over
+// HTTP, only authenticated clients may call them.
+func (s *Server) registerEditTools() {
+	nameSchema := map[string]interface{}{
+		"type":        "string",
+		"description": "Task name",
+	}
+	definitionSchema := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":                 "object",
+			"description":          description,
+			"additionalProperties": true,
+		}
+	}
+
+	tools := []struct {
+		op   string
+		tool mcp.Tool
+	}{
+		{config.TaskEditAdd, mcp.Tool{
+			Name: "add_task",
+			Description: "Add a task to the runbook config, so a command worth repeating becomes a run_/start_ tool. " +
+				"The config is validated and reloaded; invalid definitions are rejected and leave the files unchanged.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"name":       nameSchema,
+					"definition": definitionSchema(`Task fields as in the config, e.g. {"description": "Run tests", "command": "go test ./...", "type": "oneshot"}`),
+				},
+				Required: []string{"name", "definition"},
+			},
+		}},
+		{config.TaskEditUpdate, mcp.Tool{
+			Name: "update_task",
+			Description: "Change fields of a task in the runbook config. Given fields replace the existing values; " +
+				"a null value removes the field. Other fields and comments are kept. The config is validated and reloaded.",
+			InputSchema: mcp.ToolInputSchema{
+				Type: "object",
+				Properties: map[string]interface{}{
+					"name":       nameSchema,
+					"definition": definitionSchema("Task fields to set, e.g. {\"timeout\": 600}"),
+				},
+				Required: []string{"name", "definition"},
+			},
+		}},
+		{config.TaskEditRemove, mcp.Tool{
+			Name:        "remove_task",
+			Description: "Remove a task from the runbook config. The config is validated and reloaded.",
+			InputSchema: mcp.ToolInputSchema{
+				Type:       "object",
+				Properties: map[string]interface{}{"name": nameSchema},
+				Required:   []string{"name"},
+			},
+		}},
+	}
+
+	for _, t := range tools {
+		op := t.op
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+			name, _ := args["name"].(string)
+			fields, _ := args["definition"].(map[string]interface{})
+			if name == "" {
+				return argumentError("name is required"), nil
+			}
+			if op != config.TaskEditRemove && len(fields) == 0 {
+				return argumentError("definition is required"), nil
+			}
+
+			var editor string
+			if s.httpMode {
+				id, ok := auth.IdentityFromContext(ctx)
+				if !ok {
+					return codedToolError(task.ErrCodePermission, "editing tasks over HTTP requires an authenticated client (configure server.auth)"), nil
+				}
+				editor = id.Subject
+			}
+
+			file, err := s.EditTask(op, name, fields)
+			if err != nil {
+				return toolError(err), nil
+			}
+
+			result := map[string]interface{}{
+				"success": true,
+				"task":    name,
+				"file":    file,
+				"message": fmt.Sprintf("Task '%s' %s; tools were reloaded.", name, map[string]string{
+					config.TaskEditAdd:    "added",
+					config.TaskEditUpdate: "updated",
+					config.TaskEditRemove: "removed",
+				}[op]),
+			}
+			if editor != "" {
+				result["edited_by"] = editor
+			}
+			b, _ := json.Marshal(result)
+			return mcp.NewToolResultText(string(b)), nil
+		}
+		s.mcpServer.AddTool(t.tool, handler)
+	}
+}
+
+// EditTask adds, updates, or removes a task in the config files (see
+// config.EditTask) and reloads the server's tools. It returns the file that
+// was changed.
+func (s *Server) EditTask(op, name string, fields map[string]interface{}) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := config.EditTask(s.configPath, op, name, fields)
+	if errors.Is(err, config.ErrTaskNotFound) {
+		return "", codedErrorf(task.ErrCodeNotFound, "%w", err)
+	}
+	if err != nil {
+		return "", codedErrorf(task.ErrCodeConfig, "%w", err)
+	}
+	if _, _, err := s.reloadLocked(); err != nil {
+		return file, codedErrorf(task.ErrCodeConfig, "%s was changed but reloading failed: %w", file, err)
+	}
+	return file, nil
+}
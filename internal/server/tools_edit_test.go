@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/auth"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/task"
+)
+
+func newEditTestServer(t *testing.T) *Server {
+	t.Helper()
+	chdirToTemp(t)
+	if err := os.MkdirAll(dirs.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `version: "1.0"
+server:
+  allow_task_edits: true
+tasks:
+  build:
+    description: "Build"
+    command: "echo built"
+    type: oneshot
+`
+	if err := os.WriteFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _, err := config.LoadManifest("")
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	return NewServer(manifest, task.NewManager(manifest, nil), nil, true, "test", "")
+}
+
+func callEditTool(s *Server, ctx context.Context, name string, args map[string]interface{}) *mcp.CallToolResult {
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	res, _ := s.mcpServer.GetTool(name).Handler(ctx, req)
+	return res
+}
+
+func TestEditToolsReloadTools(t *testing.T) {
+	s := newEditTestServer(t)
+
+	text := callTextTool(t, s, "add_task", map[string]interface{}{
+		"name":       "lint",
+		"definition": map[string]interface{}{"description": "Lint", "command": "echo linted", "type": "oneshot"},
+	})
+	if !strings.Contains(text, `"success":true`) {
+		t.Fatalf("add_task: %s", text)
+	}
+	if s.mcpServer.GetTool("run_lint") == nil {
+		t.Fatal("expected run_lint to be registered after add_task")
+	}
+	if out := callTextTool(t, s, "run_lint", map[string]interface{}{}); !strings.Contains(out, "linted") {
+		t.Errorf("unexpected run_lint result: %s", out)
+	}
+
+	callTextTool(t, s, "update_task", map[string]interface{}{
+		"name":       "lint",
+		"definition": map[string]interface{}{"command": "echo relinted"},
+	})
+	if out := callTextTool(t, s, "run_lint", map[string]interface{}{}); !strings.Contains(out, "relinted") {
+		t.Errorf("expected updated command, got %s", out)
+	}
+
+	callTextTool(t, s, "remove_task", map[string]interface{}{"name": "lint"})
+	if s.mcpServer.GetTool("run_lint") != nil {
+		t.Error("expected run_lint to be removed after remove_task")
+	}
+
+	res := callEditTool(s, context.Background(), "add_task", map[string]interface{}{
+		"name":       "bad",
+		"definition": map[string]interface{}{"command": "true", "type": "weekly"},
+	})
+	if !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, "config would not load") {
+		t.Errorf("expected invalid definition to be rejected, got %+v", res.Content)
+	}
+}
+
+func TestEditToolsRequireAuthOverHTTP(t *testing.T) {
+	s := newEditTestServer(t)
+	s.httpMode = true
+	args := map[string]interface{}{
+		"name":       "lint",
+		"definition": map[string]interface{}{"description": "Lint", "command": "echo linted", "type": "oneshot"},
+	}
+
+	res := callEditTool(s, context.Background(), "add_task", args)
+	if !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, "authenticated client") {
+		t.Fatalf("expected unauthenticated edit to be rejected, got %+v", res.Content)
+	}
+
+	// Run the request through the auth middleware to get an authenticated context
+	var ctx context.Context
+	authenticator := auth.AuthenticatorFunc(func(r *http.Request) (auth.Identity, error) {
+		return auth.Identity{Subject: "alice"}, nil
+	})
+	auth.Middleware(authenticator, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/mcp", nil))
+
+	res = callEditTool(s, ctx, "add_task", args)
+	text := res.Content[0].(mcp.TextContent).Text
+	if res.IsError || !strings.Contains(text, `"edited_by":"alice"`) {
+		t.Errorf("expected authenticated edit to succeed, got %s", text)
+	}
+}
+
+func TestEditToolsOptIn(t *testing.T) {
+	s := newTestServer(t, &config.Manifest{Version: "1.0", Tasks: map[string]config.Task{}})
+	s.registerTools()
+	for _, name := range editToolNames {
+		if s.mcpServer.GetTool(name) != nil {
+			t.Errorf("%s must not be registered unless server.allow_task_edits is set", name)
+		}
+	}
+}
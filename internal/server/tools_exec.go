@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/task"
+)
+
+// execToolNames are the names the ad-hoc exec tool is registered under.
+// exec_command is kept for clients written against earlier versions.
+var execToolNames = []string{"shell_exec", "exec_command"}
+
+// execPolicy holds the compiled exec.allow and exec.deny patterns.
+type execPolicy struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+func newExecPolicy(allow, deny []string) (*execPolicy, error) {
+	p := &execPolicy{}
+	for _, pattern := range allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exec allow pattern '%s': %w", pattern, err)
+		}
+		p.allow = append(p.allow, re)
+	}
+	for _, pattern := range deny {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exec deny pattern '%s': %w", pattern, err)
+		}
+		p.deny = append(p.deny, re)
+	}
+	return p, nil
+}
+
+// check returns an error if command is denied, or if an allow list is set and
+// command matches none of it.
+func (p *execPolicy) check(command string) error {
+	for _, re := range p.deny {
+		if re.MatchString(command) {
+			return fmt.Errorf("command denied by exec policy (matches deny pattern '%s')", re)
+		}
+	}
+	if len(p.allow) == 0 {
+		return nil
+	}
+	for _, re := range p.allow {
+		if re.MatchString(command) {
+			return nil
+		}
+	}
+	return fmt.Errorf("command not allowed by exec policy (matches no allow pattern)")
+}
+
+// registerExecCommandTool registers the shell_exec tool, and its exec_command
+// alias, which run an arbitrary shell command as a logged session. It is only
+// registered when the manifest opts in with exec.enabled. Calls are checked
+// against the exec allow/deny policy and rate limit, and optionally need
+// confirmation.
+func (s *Server) registerExecCommandTool() {
+	cfg := s.current().manifest.Exec
+
+	limit := cfg.RateLimit
+	if limit == 0 {
+		limit = defaultExecRateLimit
+	}
+	if limit < 0 {
+		s.execLimiter = nil
+	} else if s.execLimiter == nil || s.execLimiter.limit != limit {
+		s.execLimiter = newRateLimiter(limit, time.Minute)
+	}
+	limiter := s.execLimiter
+
+	policy, policyErr := newExecPolicy(cfg.Allow, cfg.Deny)
+
+	properties := map[string]interface{}{
+		"command": map[string]interface{}{
+			"type":        "string",
+			"description": "Shell command to run",
+		},
+		"working_directory": map[string]interface{}{
+			"type":        "string",
+			"description": "Working directory for command execution (default: server working directory)",
+		},
+		"timeout": map[string]interface{}{
+			"type":        "number",
+			"description": fmt.Sprintf("Timeout in seconds (default: exec/defaults timeout, max %d)", task.ExecMaxTimeout(s.current().manifest)),
+		},
+		"max_output_lines": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum output lines to return per stream (default 100, 0=unlimited). For CLI use.",
+		},
+	}
+	if cfg.RequiresConfirmation {
+		properties[ConfirmationTokenParam] = confirmationTokenSchema()
+	}
+
+	description := "Run an ad-hoc shell command that is not defined as a task. " +
+		"The command is logged as a session (task name 'exec') with the same timeout and working directory handling as tasks."
+	if limiter != nil {
+		description += fmt.Sprintf(" Limited to %d calls per minute.", limit)
+	}
+
+	for _, toolName := range execToolNames {
+		toolName := toolName
+		tool := mcp.Tool{
+			Name:        toolName,
+			Description: description,
+			InputSchema: mcp.ToolInputSchema{
+				Type:       "object",
+				Properties: properties,
+				Required:   []string{"command"},
+			},
+		}
+
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := req.GetArguments()
+
+			command, _ := args["command"].(string)
+			if command == "" {
+				return argumentError("command is required"), nil
+			}
+			if policyErr != nil {
+				return codedToolError(task.ErrCodeConfig, policyErr.Error()), nil
+			}
+			if err := policy.check(command); err != nil {
+				return codedToolError(task.ErrCodePermission, err.Error()), nil
+			}
+			workingDir, _ := args["working_directory"].(string)
+			timeout := 0
+			if v, ok := args["timeout"].(float64); ok {
+				timeout = int(v)
+			}
+			maxLines := mcpOutputMaxLines
+			if v, ok := args["max_output_lines"].(float64); ok {
+				maxLines = int(v)
+				delete(args, "max_output_lines")
+			}
+
+			if cfg.RequiresConfirmation {
+				preview := func() string {
+					if workingDir != "" {
+						return fmt.Sprintf("cd %s && %s", workingDir, command)
+					}
+					return command
+				}
+				if res := s.confirmCall(toolName, args, preview); res != nil {
+					return res, nil
+				}
+			}
+
+			if limiter != nil {
+				if ok, wait := limiter.allow(); !ok {
+					return codedToolError(task.ErrCodeRateLimited, fmt.Sprintf("rate limit exceeded: %s allows %d calls per minute; retry in %s", toolName, limit, wait.Round(time.Second))), nil
+				}
+			}
+
+			result, err := s.current().manager.ExecuteCommand(command, workingDir, timeout)
+			if err != nil {
+				return toolError(err), nil
+			}
+
+			resultJSON, err := json.Marshal(newOneShotResponse(result, maxLines))
+			if err != nil {
+				return marshalError(err), nil
+			}
+
+			return mcp.NewToolResultText(string(resultJSON)), nil
+		}
+
+		s.mcpServer.AddTool(tool, handler)
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+)
+
+func TestExecCommandToolOptIn(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks:   map[string]config.Task{},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+	if s.mcpServer.GetTool("exec_command") != nil {
+		t.Fatal("exec_command must not be registered unless exec.enabled is set")
+	}
+
+	manifest.Exec.Enabled = true
+	s.registerTools()
+	st := s.mcpServer.GetTool("exec_command")
+	if st == nil {
+		t.Fatal("expected exec_command to be registered when exec.enabled is set")
+	}
+
+	found := false
+	for _, name := range s.collectToolNames() {
+		if name == "exec_command" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("collectToolNames() must include exec_command when enabled")
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"command": "echo adhoc"}
+	res, err := st.Handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("unexpected tool error: %+v", res.Content)
+	}
+	var resp oneShotResponse
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.Success || resp.Stdout != "adhoc" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestShellExecRateLimit(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks:   map[string]config.Task{},
+		Exec:    config.ExecConfig{Enabled: true, RateLimit: 2},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	// The limit is shared between shell_exec and its exec_command alias.
+	for i, name := range []string{"shell_exec", "exec_command"} {
+		if text := callTextTool(t, s, name, map[string]interface{}{"command": "true"}); !strings.Contains(text, `"success":true`) {
+			t.Fatalf("call %d: unexpected result: %s", i, text)
+		}
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]interface{}{"command": "true"}
+	res, err := s.mcpServer.GetTool("shell_exec").Handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, "rate limit exceeded") {
+		t.Errorf("expected rate limit error, got %+v", res.Content)
+	}
+}
+
+func TestShellExecPolicy(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks:   map[string]config.Task{},
+		Exec: config.ExecConfig{
+			Enabled: true,
+			Allow:   []string{`^(echo|ls)\b`},
+			Deny:    []string{`\brm\b`},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+	handler := s.mcpServer.GetTool("shell_exec").Handler
+
+	tests := []struct {
+		command string
+		wantErr string
+	}{
+		{"echo ok", ""},
+		{"echo ok; rm -rf x", "denied by exec policy"},
+		{"cat /etc/passwd", "not allowed by exec policy"},
+	}
+	for _, tt := range tests {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]interface{}{"command": tt.command}
+		res, err := handler(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%q: handler error: %v", tt.command, err)
+		}
+		text := res.Content[0].(mcp.TextContent).Text
+		if tt.wantErr == "" {
+			if res.IsError {
+				t.Errorf("%q: unexpected error: %s", tt.command, text)
+			}
+		} else if !res.IsError || !strings.Contains(text, tt.wantErr) {
+			t.Errorf("%q: expected error containing %q, got %s", tt.command, tt.wantErr, text)
+		}
+	}
+}
+
+func TestShellExecConfirmation(t *testing.T) {
+	chdirToTemp(t)
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks:   map[string]config.Task{},
+		Exec:    config.ExecConfig{Enabled: true, RequiresConfirmation: true},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	args := map[string]interface{}{"command": "touch created"}
+	var resp confirmationResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "shell_exec", args)), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !resp.ConfirmationRequired || resp.Preview != "touch created" {
+		t.Fatalf("expected confirmation with preview, got %+v", resp)
+	}
+	if _, err := os.Stat("created"); err == nil {
+		t.Fatal("command must not run before confirmation")
+	}
+
+	args = map[string]interface{}{"command": "touch created", ConfirmationTokenParam: resp.ConfirmationToken}
+	if text := callTextTool(t, s, "shell_exec", args); !strings.Contains(text, `"success":true`) {
+		t.Fatalf("unexpected result: %s", text)
+	}
+	if _, err := os.Stat("created"); err != nil {
+		t.Errorf("expected command to run after confirmation: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestSendInputTool(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"repl": {Description: "Echo REPL", Command: `while read line; do echo "got $line"; done`, Type: config.TaskTypeDaemon, Shell: "/bin/sh", Interactive: true},
+			"db":   {Description: "Database", Command: "sleep 30", Type: config.TaskTypeDaemon},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.processManager = process.NewManager()
+	setManager(s, task.NewManager(manifest, s.processManager))
+	t.Cleanup(func() { _ = s.processManager.StopAll() })
+	s.registerTools()
+
+	if s.mcpServer.GetTool("send_input_db") != nil {
+		t.Error("send_input_ must only be registered for interactive daemons")
+	}
+	names := strings.Join(s.collectToolNames(), ",")
+	if !strings.Contains(names, "send_input_repl") {
+		t.Errorf("collectToolNames() should include send_input_repl, got %s", names)
+	}
+
+	callTextTool(t, s, "start_repl", map[string]interface{}{})
+
+	var result task.DaemonInputResult
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "send_input_repl", map[string]interface{}{"input": "ping"})), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.Bytes != len("ping\n") {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	logPath := logs.GetSessionLogPath(result.SessionID)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if strings.Contains(string(data), "got ping") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the daemon's reply in its session log, got %q", data)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
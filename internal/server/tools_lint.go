@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
+)
+
+// registerLintConfigTool registers lint_config, which loads the config from
+// disk and reports the warnings runbook lint prints, so an agent can check
+// its edits before calling refresh_config.
+func (s *Server) registerLintConfigTool() {
+	s.mcpServer.AddTool(mcp.Tool{
+		Name: "lint_config",
+		Description: "Lint the runbook configuration on disk: unsafe parameter interpolation, descriptions that only repeat a name, " +
+			"oneshot tasks without a timeout, unused parameters, daemons whose command detaches, tasks that run the same command, " +
+			"and task groups that list disabled tasks. Returns valid and warnings; an invalid config returns its error instead.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Config file or directory to lint (default: the server's config)",
+				},
+			},
+		},
+	}, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		path := s.configPath
+		if p, ok := req.GetArguments()["path"].(string); ok && p != "" {
+			path = p
+		}
+
+		result := map[string]interface{}{}
+		manifest, loaded, err := config.LoadManifest(path)
+		switch {
+		case err != nil:
+			result["valid"] = false
+			result["error"] = err.Error()
+		case !loaded:
+			result["valid"] = false
+			result["error"] = fmt.Sprintf("no configuration found (create %s/ or pass a path)", dirs.ConfigDir)
+		default:
+			warnings := config.LintConfig(manifest)
+			if warnings == nil {
+				warnings = []string{}
+			}
+			result["valid"] = true
+			result["warnings"] = warnings
+		}
+		resultJSON, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	})
+}
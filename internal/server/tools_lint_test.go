@@ -0,0 +1,37 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestLintConfigTool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	yaml := `version: "1.0"
+tasks:
+  build:
+    description: "Build the binary"
+    command: "go build"
+    type: oneshot
+`
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	s := newTestServer(t, &config.Manifest{Version: "1.0", Tasks: map[string]config.Task{}})
+	s.registerLintConfigTool()
+
+	var result struct {
+		Valid    bool     `json:"valid"`
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "lint_config", map[string]interface{}{"path": path})), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !result.Valid || len(result.Warnings) != 1 {
+		t.Errorf("expected one warning for the missing timeout, got %+v", result)
+	}
+}
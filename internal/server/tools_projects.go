@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/task"
+)
+
+// registerRegisterProjectTool registers the register_project tool, which adds
+// another project directory to a shared HTTP server. Its tasks and workflows
+// are exposed with names prefixed by the project name.
+func (s *Server) registerRegisterProjectTool() {
+	tool := mcp.Tool{
+		Name: "register_project",
+		Description: "Host another project on this server. Loads the project's " + dirs.ConfigDir + "/ config and exposes its " +
+			"tasks and workflows as tools namespaced by the project name (e.g. run_<name>" + config.ProjectSeparator + "<task>). " +
+			"Registrations last until the server stops; list projects permanently under server.projects in the config.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Project name used as the tool name prefix (letters, digits, and hyphens)",
+				},
+				"directory": map[string]interface{}{
+					"type":        "string",
+					"description": "Project directory containing " + dirs.ConfigDir + "/ (absolute, or relative to the server's working directory)",
+				},
+			},
+			Required: []string{"name", "directory"},
+		},
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		name, _ := args["name"].(string)
+		dir, _ := args["directory"].(string)
+		if name == "" || dir == "" {
+			return argumentError("name and directory are required"), nil
+		}
+
+		tasks, err := s.RegisterProject(name, dir)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		result := map[string]interface{}{
+			"success": true,
+			"project": name,
+			"tasks":   tasks,
+			"message": fmt.Sprintf("Registered project '%s'; its tools are prefixed with %s%s.", name, name, config.ProjectSeparator),
+		}
+		b, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(b)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
+
+// RegisterProject hosts the project in dir under name until the server stops,
+// reloading configuration so its tools are registered. It returns the
+// namespaced names of the project's tasks.
+func (s *Server) RegisterProject(name, dir string) ([]string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, codedErrorf(task.ErrCodeConfig, "invalid directory: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.current().manifest.Server.Projects[name]; exists {
+		return nil, codedErrorf(task.ErrCodeConfig, "project '%s' is already configured in server.projects", name)
+	}
+	if _, exists := s.projects[name]; exists {
+		return nil, codedErrorf(task.ErrCodeConfig, "project '%s' is already registered", name)
+	}
+
+	if s.projects == nil {
+		s.projects = make(map[string]string)
+	}
+	s.projects[name] = root
+	if _, _, err := s.reloadLocked(); err != nil {
+		delete(s.projects, name)
+		return nil, codedErrorf(task.ErrCodeConfig, "%w", err)
+	}
+
+	prefix := name + config.ProjectSeparator
+	var tasks []string
+	for taskName := range s.current().manifest.Tasks {
+		if strings.HasPrefix(taskName, prefix) {
+			tasks = append(tasks, taskName)
+		}
+	}
+	sort.Strings(tasks)
+	return tasks, nil
+}
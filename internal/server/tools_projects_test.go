@@ -0,0 +1,60 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/dirs"
+)
+
+func TestRegisterProject(t *testing.T) {
+	s := newTestServer(t, emptyManifest())
+
+	projectDir := t.TempDir()
+	cfgDir := filepath.Join(projectDir, dirs.ConfigDir)
+	if err := os.MkdirAll(cfgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `version: "1.0"
+tasks:
+  test:
+    description: "Run tests"
+    command: "echo ok"
+`
+	if err := os.WriteFile(filepath.Join(cfgDir, "tasks.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := s.RegisterProject("api", projectDir)
+	if err != nil {
+		t.Fatalf("RegisterProject: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0] != "api__test" {
+		t.Errorf("expected [api__test], got %v", tasks)
+	}
+	if s.mcpServer.GetTool("run_api__test") == nil {
+		t.Error("expected run_api__test to be registered")
+	}
+
+	// Registered projects survive a refresh
+	if _, _, err := s.reloadLocked(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := s.current().manifest.Tasks["api__test"]; !ok {
+		t.Error("expected api__test after reload")
+	}
+
+	if _, err := s.RegisterProject("api", projectDir); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("expected duplicate registration error, got %v", err)
+	}
+
+	// A project that fails to load is not kept
+	if _, err := s.RegisterProject("broken", t.TempDir()); err == nil {
+		t.Fatal("expected error for a directory without config")
+	}
+	if _, exists := s.projects["broken"]; exists {
+		t.Error("expected failed project registration to be removed")
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerQueueStatusTool registers queue_status, which shows the oneshot
+// runs holding a slot under defaults.max_concurrent_tasks and those waiting
+// for one. It is only offered by the HTTP server, the mode that queues runs.
+func (s *Server) registerQueueStatusTool() {
+	s.mcpServer.AddTool(mcp.Tool{
+		Name: "queue_status",
+		Description: "Show the task run queue: the limit on concurrent oneshot runs (defaults.max_concurrent_tasks, 0 = unlimited), " +
+			"the runs executing now, and the runs waiting for a slot with their queue position.",
+		InputSchema: mcp.ToolInputSchema{Type: "object", Properties: map[string]interface{}{}},
+	}, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		resultJSON, _ := json.Marshal(s.runQueue.Status())
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	})
+}
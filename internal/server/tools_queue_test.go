@@ -0,0 +1,30 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestQueueStatusTool(t *testing.T) {
+	manifest := &config.Manifest{
+		Version:  "1.0",
+		Defaults: config.Defaults{MaxConcurrentTasks: 2},
+		Tasks: map[string]config.Task{
+			"build": {Description: "Build", Command: "true", Type: config.TaskTypeOneShot},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.runQueue = task.NewRunQueue(manifest.Defaults.MaxConcurrentTasks)
+	s.registerQueueStatusTool()
+
+	var status task.QueueStatus
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "queue_status", nil)), &status); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if status.MaxConcurrent != 2 || len(status.Running) != 0 || len(status.Queued) != 0 {
+		t.Errorf("expected an idle queue with a limit of 2, got %+v", status)
+	}
+}
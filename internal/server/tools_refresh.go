@@ -1,9 +1,11 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/task"
@@ -15,7 +17,7 @@ import (
 func (s *Server) registerRefreshConfigTool() {
 	tool := mcp.Tool{
 		Name:        "refresh_config",
-		Description: "Reload all configuration from disk. Re-registers tools, resources, and prompts without restarting the server.",
+		Description: "Reload all configuration from disk. Re-registers tools, resources, and prompts without restarting the server, and reports which tools were added, removed, or changed.",
 		InputSchema: mcp.ToolInputSchema{
 			Type:       "object",
 			Properties: make(map[string]interface{}),
@@ -23,21 +25,19 @@ func (s *Server) registerRefreshConfigTool() {
 	}
 
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		if err := s.Refresh(); err != nil {
-			result := map[string]interface{}{
-				"success": false,
-				"error":   err.Error(),
-			}
-			resultJSON, _ := json.Marshal(result)
-			return mcp.NewToolResultError(string(resultJSON)), nil
+		diff, err := s.Refresh()
+		if err != nil {
+			return toolError(err), nil
 		}
 
+		manifest := s.current().manifest
 		result := map[string]interface{}{
 			"success":   true,
 			"message":   "Configuration reloaded successfully",
-			"tasks":     len(s.manifest.Tasks),
-			"prompts":   len(s.manifest.Prompts),
-			"workflows": len(s.manifest.Workflows),
+			"tasks":     len(manifest.Tasks),
+			"prompts":   len(manifest.Prompts),
+			"workflows": len(manifest.Workflows),
+			"tools":     diff,
 		}
 		resultJSON, _ := json.Marshal(result)
 		return mcp.NewToolResultText(string(resultJSON)), nil
@@ -46,50 +46,104 @@ func (s *Server) registerRefreshConfigTool() {
 	s.mcpServer.AddTool(tool, handler)
 }
 
+// ToolDiff is how a reload changed the MCP tools, so clients that cached the
+// tool list know what to look at again.
+type ToolDiff struct {
+	Added   []string     `json:"added"`
+	Removed []string     `json:"removed"`
+	Changed []ToolChange `json:"changed"`
+}
+
+// ToolChange names a tool that is registered before and after a reload,
+// and which parts of its definition differ.
+type ToolChange struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"` // "description", "input_schema", and/or "annotations"
+}
+
+// Empty reports whether the reload left the tools as they were.
+func (d ToolDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String summarizes the diff for the server log.
+func (d ToolDiff) String() string {
+	return fmt.Sprintf("%d tool(s) added, %d removed, %d changed", len(d.Added), len(d.Removed), len(d.Changed))
+}
+
 // Refresh reloads configuration from disk, creates a new task manager,
 // and re-registers all tools, resources, and prompts on the MCP server.
-// Running daemons are not disrupted.
-func (s *Server) Refresh() error {
+// Running daemons are not disrupted. It returns how the tools changed.
+func (s *Server) Refresh() (ToolDiff, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	loaded, err := s.reloadLocked()
+	diff, loaded, err := s.reloadLocked()
 	if err != nil {
-		return err
+		return ToolDiff{}, codedErrorf(task.ErrCodeConfig, "%w", err)
 	}
 	if !loaded {
-		return fmt.Errorf("no config found at startup path %q", s.configPath)
+		return diff, codedErrorf(task.ErrCodeNotFound, "no config found at startup path %q", s.configPath)
 	}
-	return nil
+	return diff, nil
 }
 
 // reloadLocked reloads config from s.configPath and re-registers all tools,
-// resources, and prompts. The caller must hold s.mu. It returns whether a
-// config file was actually found and loaded.
-func (s *Server) reloadLocked() (bool, error) {
+// resources, and prompts. The caller must hold s.mu. It returns how the
+// tools changed, logging any change, and whether a config file was actually
+// found and loaded.
+func (s *Server) reloadLocked() (ToolDiff, bool, error) {
 	// Reload config from the current config path
 	manifest, loaded, err := config.LoadManifest(s.configPath)
 	if err != nil {
-		return false, fmt.Errorf("failed to reload config: %w", err)
+		return ToolDiff{}, false, fmt.Errorf("failed to reload config: %w", err)
+	}
+
+	// Re-add projects registered at runtime with register_project
+	for name, dir := range s.projects {
+		if err := config.AddProject(manifest, name, dir); err != nil {
+			return ToolDiff{}, false, fmt.Errorf("failed to reload config: %w", err)
+		}
 	}
 
+	before := s.toolSnapshot()
+
 	// Collect current tool names to remove them (uses the old manifest, so it
-	// must run before s.manifest is replaced)
+	// must run before the state is replaced)
 	oldToolNames := s.collectToolNames()
 
-	// Update server state
-	s.manifest = manifest
-	s.configLoaded = loaded
-	s.manager = task.NewManager(manifest, s.processManager)
+	// Build the new manager in full before swapping it in, so calls that
+	// arrive during the reload see either the old config or the new one
+	old := s.current()
+	manager := task.NewManager(manifest, s.processManager)
+	manager.SetRateLimiter(old.manager.RateLimiter())
+	manager.SetBackgroundRuns(old.manager.BackgroundRuns())
+	if s.metrics != nil {
+		manager.SetObserver(s.metrics)
+	}
+	if s.runQueue != nil {
+		s.runQueue.SetLimit(manifest.Defaults.MaxConcurrentTasks)
+		manager.SetRunQueue(s.runQueue)
+	}
+	if s.agents != nil {
+		manager.SetAgentPool(s.agents)
+	}
+
+	// Update server state; cached file content was rendered with the old manifest
+	s.setState(manifest, manager, loaded)
+	s.files.clear()
 
 	// Remove old tools (except built-in ones we'll re-register)
 	if len(oldToolNames) > 0 {
 		s.mcpServer.DeleteTools(oldToolNames...)
 	}
 
-	// Re-register built-in tools if needed
-	if !s.configLoaded {
+	// Re-register bootstrap tools if needed; the getting-started prompt is
+	// not task-derived, so remove it explicitly once tasks exist
+	if s.needsBootstrap() {
 		s.registerBuiltInTools()
+	} else {
+		s.mcpServer.DeletePrompts(gettingStartedPrompt)
 	}
 
 	// Re-register config-derived tools, resources, and prompts
@@ -97,40 +151,112 @@ func (s *Server) reloadLocked() (bool, error) {
 	s.registerResources()
 	s.registerPrompts()
 
-	return loaded, nil
+	diff := diffTools(before, s.toolSnapshot())
+	if !diff.Empty() {
+		fmt.Fprintf(os.Stderr, "Config reloaded: %s\n", diff)
+	}
+	return diff, loaded, nil
+}
+
+// toolSnapshot returns the definitions of every registered tool by name.
+func (s *Server) toolSnapshot() map[string]mcp.Tool {
+	snapshot := make(map[string]mcp.Tool)
+	for name, tool := range s.mcpServer.ListTools() {
+		snapshot[name] = tool.Tool
+	}
+	return snapshot
+}
+
+// diffTools compares the tools registered before and after a reload. Names
+// in each list are sorted.
+func diffTools(before, after map[string]mcp.Tool) ToolDiff {
+	diff := ToolDiff{Added: []string{}, Removed: []string{}, Changed: []ToolChange{}}
+	for _, name := range sortedKeys(after) {
+		old, existed := before[name]
+		if !existed {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		if fields := changedToolFields(old, after[name]); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, ToolChange{Name: name, Fields: fields})
+		}
+	}
+	for _, name := range sortedKeys(before) {
+		if _, exists := after[name]; !exists {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return diff
+}
+
+// changedToolFields lists the parts of a tool's definition that differ
+// between old and updated.
+func changedToolFields(old, updated mcp.Tool) []string {
+	var fields []string
+	if old.Description != updated.Description {
+		fields = append(fields, "description")
+	}
+	if !sameJSON(old.InputSchema, updated.InputSchema) || !bytes.Equal(old.RawInputSchema, updated.RawInputSchema) {
+		fields = append(fields, "input_schema")
+	}
+	if !sameJSON(old.Annotations, updated.Annotations) {
+		fields = append(fields, "annotations")
+	}
+	return fields
+}
+
+// sameJSON reports whether a and b marshal to the same JSON. Maps marshal
+// with sorted keys, so equal schemas compare equal.
+func sameJSON(a, b interface{}) bool {
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return bytes.Equal(aJSON, bJSON)
 }
 
 // collectToolNames returns the names of all currently registered task-derived tools.
 // This is used during refresh to know which tools to remove before re-registering.
 func (s *Server) collectToolNames() []string {
+	manifest := s.current().manifest
 	var names []string
 
 	// Session management tools
-	names = append(names, "list_sessions", "read_session_metadata", "read_session_log")
+	names = append(names, "list_sessions", "read_session_metadata", "session_info", "read_session_log", "diff_sessions", "search_logs", "task_status", "task_result")
 
 	// Task-derived tools
-	for taskName, taskDef := range s.manifest.Tasks {
+	for taskName, taskDef := range manifest.Tasks {
 		if taskDef.Disabled || taskDef.DisableMCP {
 			continue
 		}
-		switch taskDef.Type {
-		case config.TaskTypeOneShot:
-			names = append(names, "run_"+taskName)
-		case config.TaskTypeDaemon:
-			names = append(names, "start_"+taskName, "stop_"+taskName, "status_"+taskName, "logs_"+taskName)
+		for _, kind := range config.TaskToolKinds(taskDef) {
+			names = append(names, manifest.ToolName(kind, taskName))
 		}
 	}
 
 	// Workflow-derived tools
-	for workflowName, workflowDef := range s.manifest.Workflows {
+	for workflowName, workflowDef := range manifest.Workflows {
 		if workflowDef.Disabled || workflowDef.DisableMCP {
 			continue
 		}
-		names = append(names, "run_workflow_"+workflowName)
+		names = append(names, manifest.WorkflowToolName(workflowName))
+	}
+
+	// Bulk daemon tools
+	if len(s.mcpDaemonNames()) > 0 {
+		names = append(names, bulkToolNames...)
+	}
+
+	// Ad-hoc exec tool
+	if manifest.Exec.Enabled {
+		names = append(names, execToolNames...)
+	}
+
+	// Manifest editing tools
+	if manifest.Server.AllowTaskEdits {
+		names = append(names, editToolNames...)
 	}
 
 	// Built-in tools
-	names = append(names, "init")
+	names = append(names, "init", "suggest_tasks", "validate_config")
 
 	return names
 }
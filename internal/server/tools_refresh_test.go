@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestDiffTools(t *testing.T) {
+	before := map[string]mcp.Tool{
+		"run_build": mcp.NewTool("run_build", mcp.WithDescription("Build")),
+		"run_lint":  mcp.NewTool("run_lint", mcp.WithDescription("Lint")),
+		"run_test":  mcp.NewTool("run_test", mcp.WithDescription("Test")),
+	}
+	after := map[string]mcp.Tool{
+		"run_build": mcp.NewTool("run_build", mcp.WithDescription("Build everything")),
+		"run_lint":  mcp.NewTool("run_lint", mcp.WithDescription("Lint")),
+		"run_test":  mcp.NewTool("run_test", mcp.WithDescription("Test"), mcp.WithString("package")),
+		"start_dev": mcp.NewTool("start_dev", mcp.WithDescription("Dev server")),
+	}
+
+	diff := diffTools(before, after)
+	want := ToolDiff{
+		Added:   []string{"start_dev"},
+		Removed: []string{},
+		Changed: []ToolChange{
+			{Name: "run_build", Fields: []string{"description"}},
+			{Name: "run_test", Fields: []string{"input_schema"}},
+		},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("diffTools = %+v, want %+v", diff, want)
+	}
+	if !diffTools(after, after).Empty() {
+		t.Error("expected no changes between identical tool sets")
+	}
+}
+
+func TestRefreshReportsToolDiff(t *testing.T) {
+	chdirToTemp(t)
+	if err := os.MkdirAll(dirs.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dirs.ConfigDir, "tasks.yaml")
+	write := func(cfg string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(cfg), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`version: "1.0"
+tasks:
+  build:
+    description: "Build"
+    command: "make"
+  lint:
+    description: "Lint"
+    command: "make lint"
+`)
+	manifest, loaded, err := config.LoadManifest("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, loaded, "test", "")
+
+	write(`version: "1.0"
+tasks:
+  build:
+    description: "Build the app"
+    command: "make"
+  vet:
+    description: "Vet"
+    command: "go vet ./..."
+`)
+	diff, err := s.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if !reflect.DeepEqual(diff.Added, []string{"run_vet"}) || !reflect.DeepEqual(diff.Removed, []string{"run_lint"}) {
+		t.Errorf("expected run_vet added and run_lint removed, got %v and %v", diff.Added, diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Name != "run_build" {
+		t.Errorf("expected run_build changed, got %+v", diff.Changed)
+	}
+
+	diff, err = s.Refresh()
+	if err != nil || !diff.Empty() {
+		t.Errorf("expected an unchanged refresh to report no changes, got %s (%v)", diff, err)
+	}
+}
+
+func TestRefreshWhileCallingTools(t *testing.T) {
+	chdirToTemp(t)
+	if err := os.MkdirAll(dirs.ConfigDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `version: "1.0"
+tasks:
+  build:
+    description: "Build"
+    command: "true"
+`
+	if err := os.WriteFile(filepath.Join(dirs.ConfigDir, "tasks.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, loaded, err := config.LoadManifest("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(manifest, task.NewManager(manifest, nil), nil, loaded, "test", "")
+
+	// Handlers registered before a refresh keep working through it, and
+	// under -race the refresh must not race with them
+	handler := s.mcpServer.GetTool("run_build").Handler
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				res, err := handler(context.Background(), mcp.CallToolRequest{})
+				if err != nil || res.IsError {
+					t.Errorf("run_build during refresh: %v %+v", err, res)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := s.Refresh(); err != nil {
+			t.Errorf("Refresh failed: %v", err)
+		}
+	}
+	wg.Wait()
+}
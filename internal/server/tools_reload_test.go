@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
+)
+
+func TestReloadTool(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"proxy": {
+				Description:  "Reverse proxy",
+				Command:      `trap 'echo reloaded' USR1; echo ready; while true; do sleep 0.1; done`,
+				Type:         config.TaskTypeDaemon,
+				Shell:        "/bin/sh",
+				Reload:       true,
+				ReloadSignal: "USR1",
+			},
+			"db": {Description: "Database", Command: "sleep 30", Type: config.TaskTypeDaemon},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.processManager = process.NewManager()
+	setManager(s, task.NewManager(manifest, s.processManager))
+	t.Cleanup(func() { _ = s.processManager.StopAll() })
+	s.registerTools()
+
+	if s.mcpServer.GetTool("reload_db") != nil {
+		t.Error("reload_ must only be registered for daemons with reload: true")
+	}
+	if _, err := s.current().manager.ReloadDaemon("db"); err == nil || !strings.Contains(err.Error(), "does not support reload") {
+		t.Errorf("ReloadDaemon(db) error = %v, want a does not support reload error", err)
+	}
+	names := strings.Join(s.collectToolNames(), ",")
+	if !strings.Contains(names, "reload_proxy") {
+		t.Errorf("collectToolNames() should include reload_proxy, got %s", names)
+	}
+
+	var result task.DaemonReloadResult
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "reload_proxy", map[string]interface{}{})), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Success {
+		t.Fatalf("reloading a stopped daemon should fail, got %+v", result)
+	}
+
+	var started task.DaemonStartResult
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "start_proxy", map[string]interface{}{})), &started); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the trap to be set, or the signal would stop the daemon
+	logPath := logs.GetSessionLogPath(started.SessionID)
+	waitForLog := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			data, _ := os.ReadFile(logPath)
+			if strings.Contains(string(data), want) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %q in the daemon's session log, got %q", want, data)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	waitForLog("ready")
+
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "reload_proxy", map[string]interface{}{})), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.Signal != "SIGUSR1" || result.SessionID != started.SessionID {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	waitForLog("reloaded")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"runbookmcp.dev/internal/logs"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -13,7 +14,11 @@ import (
 func (s *Server) registerSessionManagementTools() {
 	s.registerListSessionsTool()
 	s.registerReadSessionMetadataTool()
+	s.registerSessionInfoTool()
 	s.registerReadSessionLogTool()
+	s.registerDiffSessionsTool()
+	s.registerSearchLogsTool()
+	s.registerBackgroundTools()
 }
 
 // registerListSessionsTool registers the list_sessions tool
@@ -44,7 +49,7 @@ func (s *Server) registerListSessionsTool() {
 
 		taskName, ok := args["task_name"].(string)
 		if !ok {
-			return mcp.NewToolResultError("task_name is required"), nil
+			return argumentError("task_name is required"), nil
 		}
 
 		limit := 20
@@ -54,7 +59,7 @@ func (s *Server) registerListSessionsTool() {
 
 		sessions, err := logs.ListSessions(taskName, limit)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to list sessions: %v", err)), nil
+			return readError("failed to list sessions", err), nil
 		}
 
 		resultJSON, _ := json.Marshal(sessions)
@@ -88,12 +93,12 @@ func (s *Server) registerReadSessionMetadataTool() {
 
 		sessionID, ok := args["session_id"].(string)
 		if !ok {
-			return mcp.NewToolResultError("session_id is required"), nil
+			return argumentError("session_id is required"), nil
 		}
 
 		metadata, err := logs.ReadSessionMetadata(sessionID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to read session metadata: %v", err)), nil
+			return readError("failed to read session metadata", err), nil
 		}
 
 		resultJSON, _ := json.Marshal(metadata)
@@ -103,6 +108,98 @@ func (s *Server) registerReadSessionMetadataTool() {
 	s.mcpServer.AddTool(tool, handler)
 }
 
+// sessionInfoResponse is what session_info returns: how a session was run.
+type sessionInfoResponse struct {
+	SessionID  string                 `json:"session_id"`
+	TaskName   string                 `json:"task_name"`
+	TaskType   string                 `json:"task_type"`
+	StartTime  string                 `json:"start_time"`
+	Status     string                 `json:"status"`
+	ExitCode   *int                   `json:"exit_code,omitempty"`
+	Command    string                 `json:"command,omitempty"`
+	Argv       []string               `json:"argv,omitempty"`
+	WorkingDir string                 `json:"working_dir,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Env        map[string]string      `json:"env,omitempty"`
+	CompareTo  string                 `json:"compare_to,omitempty"`
+	Changes    []logs.SessionChange   `json:"changes,omitempty"` // Differences from compare_to
+}
+
+// registerSessionInfoTool registers the session_info tool
+func (s *Server) registerSessionInfoTool() {
+	inputSchema := mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"session_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Session ID to describe",
+			},
+			"compare_to": map[string]interface{}{
+				"type":        "string",
+				"description": "Another session ID; lists how the two runs' command, working directory, parameters, and environment differ",
+			},
+		},
+		Required: []string{"session_id"},
+	}
+
+	tool := mcp.Tool{
+		Name: "session_info",
+		Description: "Show how a session was run: the resolved command and command line, working directory, parameters, and environment (redacted). " +
+			"With compare_to, also lists what differs from another session.",
+		InputSchema: inputSchema,
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		sessionID, _ := args["session_id"].(string)
+		if sessionID == "" {
+			return argumentError("session_id is required"), nil
+		}
+		metadata, err := logs.ReadSessionMetadata(sessionID)
+		if err != nil {
+			return readError("failed to read session metadata", err), nil
+		}
+
+		status := "running"
+		switch {
+		case metadata.Status != "":
+			status = metadata.Status
+		case metadata.Success != nil && *metadata.Success:
+			status = "success"
+		case metadata.Success != nil:
+			status = "failure"
+		}
+		info := sessionInfoResponse{
+			SessionID:  metadata.SessionID,
+			TaskName:   metadata.TaskName,
+			TaskType:   metadata.TaskType,
+			StartTime:  metadata.StartTime.Format(time.RFC3339),
+			Status:     status,
+			ExitCode:   metadata.ExitCode,
+			Command:    metadata.Command,
+			Argv:       metadata.Argv,
+			WorkingDir: metadata.WorkingDir,
+			Parameters: metadata.Parameters,
+			Env:        metadata.Env,
+		}
+
+		if other, _ := args["compare_to"].(string); other != "" {
+			otherMetadata, err := logs.ReadSessionMetadata(other)
+			if err != nil {
+				return readError("failed to read session metadata of compare_to", err), nil
+			}
+			info.CompareTo = other
+			info.Changes = logs.CompareSessions(otherMetadata, metadata)
+		}
+
+		resultJSON, _ := json.Marshal(info)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
+
 // sessionLogInputSchema returns the input schema for the read_session_log tool.
 func sessionLogInputSchema() mcp.ToolInputSchema {
 	return mcp.ToolInputSchema{
@@ -124,6 +221,11 @@ func sessionLogInputSchema() mcp.ToolInputSchema {
 				"type":        "number",
 				"description": "Skip the last N lines (for paging backwards through history)",
 			},
+			"stream": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{logs.StreamStdout, logs.StreamStderr},
+				"description": "Only return stdout or stderr (default: both, interleaved)",
+			},
 		},
 		Required: []string{"session_id"},
 	}
@@ -144,7 +246,7 @@ func (s *Server) registerReadSessionLogTool() {
 
 		sessionID, ok := args["session_id"].(string)
 		if !ok {
-			return mcp.NewToolResultError("session_id is required"), nil
+			return argumentError("session_id is required"), nil
 		}
 
 		opts := logs.ReadOptions{
@@ -161,10 +263,13 @@ func (s *Server) registerReadSessionLogTool() {
 		if offset, ok := args["offset"].(float64); ok {
 			opts.Offset = int(offset)
 		}
+		if stream, ok := args["stream"].(string); ok {
+			opts.Stream = stream
+		}
 
 		logLines, totalLines, err := logs.ReadSessionLog(sessionID, opts)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to read session log: %v", err)), nil
+			return readError("failed to read session log", err), nil
 		}
 
 		result := map[string]interface{}{
@@ -180,3 +285,149 @@ func (s *Server) registerReadSessionLogTool() {
 
 	s.mcpServer.AddTool(tool, handler)
 }
+
+// diffSessionsMaxLines is the default number of hunk lines diff_sessions returns.
+const diffSessionsMaxLines = 200
+
+// registerDiffSessionsTool registers the diff_sessions tool
+func (s *Server) registerDiffSessionsTool() {
+	inputSchema := mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"session_a": map[string]interface{}{
+				"type":        "string",
+				"description": "A session ID",
+			},
+			"session_b": map[string]interface{}{
+				"type":        "string",
+				"description": "Another session ID of the same task",
+			},
+			"max_lines": map[string]interface{}{
+				"type":        "number",
+				"description": fmt.Sprintf("Maximum number of diff lines to return (default: %d, 0 = all)", diffSessionsMaxLines),
+			},
+		},
+		Required: []string{"session_a", "session_b"},
+	}
+
+	tool := mcp.Tool{
+		Name: "diff_sessions",
+		Description: "Diff the logs of two sessions of the same task, older to newer, ignoring timestamps, durations, UUIDs, and colors. " +
+			"Returns changed hunks and new_errors: added lines that look like errors.",
+		InputSchema: inputSchema,
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		sessionA, _ := args["session_a"].(string)
+		sessionB, _ := args["session_b"].(string)
+		if sessionA == "" || sessionB == "" {
+			return argumentError("session_a and session_b are required"), nil
+		}
+
+		maxLines := diffSessionsMaxLines
+		if l, ok := args["max_lines"].(float64); ok {
+			maxLines = int(l)
+		}
+
+		diff, err := logs.DiffSessions(sessionA, sessionB, maxLines)
+		if err != nil {
+			return readError("failed to diff sessions", err), nil
+		}
+
+		resultJSON, _ := json.Marshal(diff)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
+
+// searchLogsLimit is the default number of matches search_logs returns.
+const searchLogsLimit = 100
+
+// registerSearchLogsTool registers the search_logs tool
+func (s *Server) registerSearchLogsTool() {
+	inputSchema := mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"pattern": map[string]interface{}{
+				"type":        "string",
+				"description": "Regex to match log lines against",
+			},
+			"task_name": map[string]interface{}{
+				"type":        "string",
+				"description": "Only search this task's sessions (default: all tasks)",
+			},
+			"since": map[string]interface{}{
+				"type":        "string",
+				"description": "Only sessions started at or after this time: RFC 3339, YYYY-MM-DD, or a duration like 24h",
+			},
+			"until": map[string]interface{}{
+				"type":        "string",
+				"description": "Only sessions started before this time, in the same formats as since",
+			},
+			"limit": map[string]interface{}{
+				"type":        "number",
+				"description": fmt.Sprintf("Maximum number of matches to return (default: %d, 0 = all)", searchLogsLimit),
+			},
+		},
+		Required: []string{"pattern"},
+	}
+
+	tool := mcp.Tool{
+		Name: "search_logs",
+		Description: "Search the logs of all sessions for lines matching a regex, newest session first. " +
+			"Each match has its session ID, task, session start time, and line number, so the first match is the most recent occurrence.",
+		InputSchema: inputSchema,
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+
+		pattern, _ := args["pattern"].(string)
+		if pattern == "" {
+			return argumentError("pattern is required"), nil
+		}
+
+		opts := logs.SearchOptions{Limit: searchLogsLimit}
+		opts.TaskName, _ = args["task_name"].(string)
+		if l, ok := args["limit"].(float64); ok {
+			opts.Limit = int(l)
+		}
+		now := time.Now()
+		bounds := []struct {
+			key string
+			t   *time.Time
+		}{{"since", &opts.Since}, {"until", &opts.Until}}
+		for _, bound := range bounds {
+			value, _ := args[bound.key].(string)
+			if value == "" {
+				continue
+			}
+			t, err := logs.ParseTimeBound(value, now)
+			if err != nil {
+				return argumentError(fmt.Sprintf("%s: %v", bound.key, err)), nil
+			}
+			*bound.t = t
+		}
+
+		matches, truncated, err := logs.SearchLogs(pattern, opts)
+		if err != nil {
+			return readError("failed to search logs", err), nil
+		}
+		if matches == nil {
+			matches = []logs.SearchMatch{}
+		}
+
+		result := map[string]interface{}{
+			"matches":   matches,
+			"count":     len(matches),
+			"truncated": truncated,
+		}
+		resultJSON, _ := json.Marshal(result)
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	s.mcpServer.AddTool(tool, handler)
+}
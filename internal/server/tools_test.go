@@ -1,9 +1,14 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/process"
+	"runbookmcp.dev/internal/task"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -17,7 +22,8 @@ func TestCollectToolNamesExcludesRefreshConfig(t *testing.T) {
 		Prompts:   map[string]config.Prompt{},
 	}
 
-	s := &Server{manifest: manifest}
+	s := &Server{}
+	s.setState(manifest, nil, true)
 	names := s.collectToolNames()
 
 	for _, name := range names {
@@ -59,7 +65,8 @@ func TestCollectToolNamesExcludesDisableMCPTasks(t *testing.T) {
 		Prompts:   map[string]config.Prompt{},
 	}
 
-	s := &Server{manifest: manifest}
+	s := &Server{}
+	s.setState(manifest, nil, true)
 	names := s.collectToolNames()
 
 	nameSet := make(map[string]bool, len(names))
@@ -418,3 +425,94 @@ func TestHasMoreCalculation(t *testing.T) {
 		})
 	}
 }
+
+func TestRunToolStructuredOutput(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"report": {
+				Description:  "Report",
+				Command:      `echo '{"passed": 3}'`,
+				Type:         config.TaskTypeOneShot,
+				OutputFormat: config.OutputFormatJSON,
+			},
+		},
+	}
+	s := newTestServer(t, manifest)
+	setManager(s, task.NewManager(manifest, process.NewManager()))
+	s.registerTools()
+
+	req := mcp.CallToolRequest{}
+	res, err := s.mcpServer.GetTool("run_report").Handler(context.Background(), req)
+	if err != nil || res.IsError {
+		t.Fatalf("run_report failed: %+v (%v)", res, err)
+	}
+
+	var resp struct {
+		Stdout string          `json:"stdout"`
+		Output json.RawMessage `json:"output"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if string(resp.Output) != `{"passed":3}` || resp.Stdout != "" {
+		t.Errorf("expected the parsed output in place of stdout, got %+v", resp)
+	}
+	if res.StructuredContent == nil {
+		t.Error("expected structured content in the tool result")
+	}
+}
+
+func TestRunToolLogOutputPointsAtSessionLog(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"noisy": {
+				Description:       "Noisy",
+				Command:           "seq 1 500",
+				Type:              config.TaskTypeOneShot,
+				LogOutput:         config.LogOutputTruncated,
+				LogOutputMaxLines: 10,
+			},
+		},
+	}
+	s := newTestServer(t, manifest)
+	setManager(s, task.NewManager(manifest, process.NewManager()))
+	s.registerTools()
+
+	req := mcp.CallToolRequest{}
+	res, err := s.mcpServer.GetTool("run_noisy").Handler(context.Background(), req)
+	if err != nil || res.IsError {
+		t.Fatalf("run_noisy failed: %+v (%v)", res, err)
+	}
+
+	var resp oneShotResponse
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.StdoutLines != 10 || resp.StdoutTotalLines != 500 || !resp.StdoutTruncated {
+		t.Errorf("stdout lines = %d of %d (truncated %v), want 10 of 500", resp.StdoutLines, resp.StdoutTotalLines, resp.StdoutTruncated)
+	}
+	if resp.StdoutLog == "" || !strings.Contains(resp.StdoutHint, resp.SessionID) {
+		t.Errorf("expected a pointer to the session log, got log %q hint %q", resp.StdoutLog, resp.StdoutHint)
+	}
+}
+
+func TestBuildParamSchemaIncludesRules(t *testing.T) {
+	lo, hi, maxLength := 1.0, 10.0, 20
+	schema := buildParamSchema(config.Param{Type: "integer", Enum: []string{"1", "3"}, Min: &lo, Max: &hi})
+	if schema["minimum"] != 1.0 || schema["maximum"] != 10.0 {
+		t.Errorf("schema = %v, want minimum 1 and maximum 10", schema)
+	}
+	if enum, ok := schema["enum"].([]interface{}); !ok || len(enum) != 2 || enum[1] != 3.0 {
+		t.Errorf("enum = %v, want numbers [1 3]", schema["enum"])
+	}
+
+	schema = buildParamSchema(config.Param{Type: "string", Pattern: "^v[0-9]+$", MaxLength: &maxLength})
+	if schema["pattern"] != "^v[0-9]+$" || schema["maxLength"] != 20 {
+		t.Errorf("schema = %v, want pattern and maxLength", schema)
+	}
+	if _, ok := schema["minLength"]; ok {
+		t.Errorf("schema = %v, want no minLength", schema)
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestRunToolTimeoutOverride(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build": {
+				Description: "Build",
+				Command:     "echo built",
+				Type:        config.TaskTypeOneShot,
+				Timeout:     10,
+				MaxTimeout:  60,
+			},
+			"wait": {
+				Description: "Wait",
+				Command:     "echo {{.timeout}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"timeout": {Type: "string", Description: "Task's own timeout parameter"},
+				},
+			},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	if _, ok := s.mcpServer.GetTool("run_build").Tool.InputSchema.Properties["timeout"]; !ok {
+		t.Error("run_build schema should include the timeout override")
+	}
+
+	cases := []struct {
+		args map[string]interface{}
+		want int
+	}{
+		{map[string]interface{}{}, 10},
+		{map[string]interface{}{"timeout": float64(30)}, 30},
+		{map[string]interface{}{"timeout": float64(600)}, 60},
+	}
+	for _, tc := range cases {
+		var resp oneShotResponse
+		if err := json.Unmarshal([]byte(callTextTool(t, s, "run_build", tc.args)), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Success || resp.Timeout != tc.want {
+			t.Errorf("args %v: expected success with timeout %d, got %+v", tc.args, tc.want, resp)
+		}
+	}
+
+	// A task parameter named timeout is passed to the command instead
+	var resp oneShotResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_wait", map[string]interface{}{"timeout": "5m"})), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stdout != "5m" || resp.Timeout != 0 {
+		t.Errorf("expected the timeout parameter to reach the command, got %+v", resp)
+	}
+}
+
+func TestToolParameterAliases(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"greet": {
+				Description: "Greet",
+				Command:     "echo {{.name}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"name": {Type: "string", Description: "Who", Aliases: []string{"who"}, DeprecatedNames: []string{"user"}},
+				},
+			},
+		},
+	}
+	s := newTestServer(t, manifest)
+	s.registerTools()
+
+	var resp oneShotResponse
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_greet", map[string]interface{}{"who": "ada"})), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stdout != "ada" || len(resp.Warnings) != 0 {
+		t.Errorf("alias: expected stdout ada without warnings, got %+v", resp)
+	}
+
+	resp = oneShotResponse{}
+	if err := json.Unmarshal([]byte(callTextTool(t, s, "run_greet", map[string]interface{}{"user": "bob"})), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Stdout != "bob" || len(resp.Warnings) != 1 || resp.Warnings[0] != "parameter 'user' is deprecated, use 'name'" {
+		t.Errorf("deprecated name: expected stdout bob with a warning, got %+v", resp)
+	}
+}
@@ -3,13 +3,13 @@ package server
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"runbookmcp.dev/internal/dirs"
 	"runbookmcp.dev/internal/logs"
-	"github.com/mark3labs/mcp-go/mcp"
+	"runbookmcp.dev/internal/task"
 )
 
 // registerSetWorkingDirTool registers the set_working_directory tool, which
@@ -38,24 +38,23 @@ func (s *Server) registerSetWorkingDirTool() {
 		args := req.GetArguments()
 		dir, _ := args["directory"].(string)
 		if dir == "" {
-			return mcp.NewToolResultError(`{"success":false,"error":"directory is required"}`), nil
+			return argumentError("directory is required"), nil
 		}
 
 		loaded, err := s.SwitchWorkingDirectory(dir)
 		if err != nil {
-			result := map[string]interface{}{"success": false, "error": err.Error()}
-			b, _ := json.Marshal(result)
-			return mcp.NewToolResultError(string(b)), nil
+			return toolError(err), nil
 		}
 
 		cwd, _ := os.Getwd()
+		manifest := s.current().manifest
 		result := map[string]interface{}{
 			"success":           true,
 			"working_directory": cwd,
 			"config_loaded":     loaded,
-			"tasks":             len(s.manifest.Tasks),
-			"prompts":           len(s.manifest.Prompts),
-			"workflows":         len(s.manifest.Workflows),
+			"tasks":             len(manifest.Tasks),
+			"prompts":           len(manifest.Prompts),
+			"workflows":         len(manifest.Workflows),
 		}
 		if loaded {
 			result["message"] = "Switched working directory and reloaded configuration."
@@ -81,24 +80,27 @@ func (s *Server) SwitchWorkingDirectory(dir string) (bool, error) {
 
 	abs, err := filepath.Abs(dir)
 	if err != nil {
-		return false, fmt.Errorf("invalid directory %q: %w", dir, err)
+		return false, codedErrorf(task.ErrCodeConfig, "invalid directory %q: %w", dir, err)
 	}
 
 	info, err := os.Stat(abs)
+	if os.IsNotExist(err) {
+		return false, codedErrorf(task.ErrCodeNotFound, "cannot access directory %q: %w", abs, err)
+	}
 	if err != nil {
-		return false, fmt.Errorf("cannot access directory %q: %w", abs, err)
+		return false, codedErrorf(task.ErrCodeIO, "cannot access directory %q: %w", abs, err)
 	}
 	if !info.IsDir() {
-		return false, fmt.Errorf("%q is not a directory", abs)
+		return false, codedErrorf(task.ErrCodeConfig, "%q is not a directory", abs)
 	}
 
 	if err := os.Chdir(abs); err != nil {
-		return false, fmt.Errorf("failed to change directory to %q: %w", abs, err)
+		return false, codedErrorf(task.ErrCodeIO, "failed to change directory to %q: %w", abs, err)
 	}
 
 	// Re-create the state/log directory structure under the new working dir.
 	if err := logs.Setup(); err != nil {
-		return false, fmt.Errorf("failed to set up logs in %q: %w", abs, err)
+		return false, codedErrorf(task.ErrCodeIO, "failed to set up logs in %q: %w", abs, err)
 	}
 
 	// Load config from the new directory's default location, ignoring any
@@ -106,5 +108,9 @@ func (s *Server) SwitchWorkingDirectory(dir string) (bool, error) {
 	// the new directory's runbook configuration.
 	s.configPath = ""
 
-	return s.reloadLocked()
+	_, loaded, err := s.reloadLocked()
+	if err != nil {
+		return loaded, codedErrorf(task.ErrCodeConfig, "%w", err)
+	}
+	return loaded, nil
 }
@@ -55,8 +55,8 @@ tasks:
 	}
 
 	// The new task must be present in the reloaded manifest.
-	if _, ok := s.manifest.Tasks["greet"]; !ok {
-		t.Errorf("expected task 'greet' after switch; tasks = %v", s.manifest.Tasks)
+	if _, ok := s.current().manifest.Tasks["greet"]; !ok {
+		t.Errorf("expected task 'greet' after switch; tasks = %v", s.current().manifest.Tasks)
 	}
 }
 
@@ -123,7 +123,8 @@ func TestSetWorkingDirNotDeletedByRefresh(t *testing.T) {
 		Workflows: map[string]config.Workflow{},
 		Prompts:   map[string]config.Prompt{},
 	}
-	s := &Server{manifest: manifest}
+	s := &Server{}
+	s.setState(manifest, nil, true)
 	for _, name := range s.collectToolNames() {
 		if name == "set_working_directory" {
 			t.Fatalf("collectToolNames() must not include set_working_directory (it would be deleted on refresh)")
@@ -5,14 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/task"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // registerWorkflowTools registers all workflows as MCP tools
 func (s *Server) registerWorkflowTools() {
-	for workflowName, workflow := range s.manifest.Workflows {
+	for workflowName, workflow := range s.current().manifest.Workflows {
 		if workflow.Disabled || workflow.DisableMCP {
 			continue
 		}
@@ -22,12 +24,12 @@ func (s *Server) registerWorkflowTools() {
 
 // registerWorkflowTool registers a single workflow as an MCP tool
 func (s *Server) registerWorkflowTool(workflowName string, workflow config.Workflow) {
-	toolName := "run_workflow_" + workflowName
+	toolName := s.current().manifest.WorkflowToolName(workflowName)
 
 	// Build description with step names
 	stepNames := make([]string, len(workflow.Steps))
 	for i, step := range workflow.Steps {
-		stepNames[i] = step.Task
+		stepNames[i] = step.Target()
 	}
 	description := fmt.Sprintf("%s (steps: %s)", workflow.Description, strings.Join(stepNames, " -> "))
 
@@ -39,11 +41,7 @@ func (s *Server) registerWorkflowTool(workflowName string, workflow config.Workf
 	}
 
 	for paramName, param := range workflow.Parameters {
-		paramSchema := map[string]interface{}{
-			"type":        param.Type,
-			"description": param.Description,
-		}
-		inputSchema.Properties[paramName] = paramSchema
+		inputSchema.Properties[paramName] = buildParamSchema(param)
 		if param.Required {
 			inputSchema.Required = append(inputSchema.Required, paramName)
 		}
@@ -57,23 +55,59 @@ func (s *Server) registerWorkflowTool(workflowName string, workflow config.Workf
 		}
 	}
 
+	manifest := s.current().manifest
+	requiresConfirmation := config.WorkflowRequiresConfirmation(workflow, manifest.Tasks, manifest.Workflows)
+	if requiresConfirmation {
+		inputSchema.Properties[ConfirmationTokenParam] = confirmationTokenSchema()
+	}
+
 	tool := mcp.Tool{
 		Name:        toolName,
 		Description: description,
 		InputSchema: inputSchema,
+		Annotations: toolAnnotations(config.WorkflowHints(workflow, manifest.Tasks, manifest.Workflows)),
 	}
 
+	budget := resolveLatencyBudget(manifest.Defaults.LatencyBudget)
+
 	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		params := req.GetArguments()
+		warnings, err := config.ResolveParamNames(workflow.Parameters, params)
+		if err != nil {
+			return argumentError(err.Error()), nil
+		}
 
-		result, err := s.manager.ExecuteWorkflow(workflowName, params)
+		if requiresConfirmation {
+			preview := func() string { return s.workflowPreview(workflowName, params) }
+			if res := s.confirmCall(toolName, params, preview); res != nil {
+				return res, nil
+			}
+		}
+
+		start := time.Now()
+		result, err := s.current().manager.ExecuteWorkflow(workflowName, params)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return toolError(err), nil
+		}
+		for _, step := range result.Steps {
+			if step.Result != nil {
+				s.claimSessionDaemons(ctx, step.Result.DaemonsStarted...)
+			}
+		}
+
+		resp := struct {
+			*task.WorkflowResult
+			LatencyHint *latencyHint `json:"latency_hint,omitempty"`
+			Warnings    []string     `json:"warnings,omitempty"`
+		}{
+			WorkflowResult: result,
+			LatencyHint:    s.checkLatency(toolName, time.Since(start), budget, ""),
+			Warnings:       warnings,
 		}
 
-		resultJSON, err := json.Marshal(result)
+		resultJSON, err := json.Marshal(resp)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+			return marshalError(err), nil
 		}
 
 		return mcp.NewToolResultText(string(resultJSON)), nil
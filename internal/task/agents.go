@@ -0,0 +1,531 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// AgentTimeout is how long an agent may go without contacting the server
+// before it is dropped, failing the run it holds.
+const AgentTimeout = 60 * time.Second
+
+// AgentPollWait is how long an agent's request for its next job waits for
+// one before the server answers that there is none.
+const AgentPollWait = 25 * time.Second
+
+// ErrUnknownAgent is returned for requests from an agent the pool does not
+// know, such as one dropped after AgentTimeout. The agent must register
+// again.
+var ErrUnknownAgent = errors.New("unknown agent")
+
+// ErrUnknownAgentJob is returned for output or results of a run the agent
+// does not hold, such as one that timed out on the server. The agent should
+// kill it.
+var ErrUnknownAgentJob = errors.New("unknown job")
+
+// AgentRegistration is the body of an agent's registration request.
+type AgentRegistration struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// AgentJob is a run handed to a remote agent: the resolved command and the
+// settings it runs with.
+type AgentJob struct {
+	ID         string            `json:"id"`
+	SessionID  string            `json:"session_id"`
+	Task       string            `json:"task"`
+	Command    string            `json:"command"`
+	Shell      string            `json:"shell,omitempty"`
+	WorkingDir string            `json:"working_dir,omitempty"` // Relative to the agent's directory unless absolute
+	Env        map[string]string `json:"env,omitempty"`
+	EnvPolicy  *config.EnvPolicy `json:"env_policy,omitempty"` // Agent environment the command inherits
+	Timeout    int               `json:"timeout,omitempty"`    // Seconds; the server enforces it too
+}
+
+// AgentResult is how a job ended on the agent. ExitCode is -1 when the
+// command could not be started, with Error saying why.
+type AgentResult struct {
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+// AgentInfo describes an agent registered with the pool.
+type AgentInfo struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Tags     []string  `json:"tags"`
+	Since    time.Time `json:"since"`
+	LastSeen time.Time `json:"last_seen"`
+	Running  []string  `json:"running"` // Tasks of the jobs it holds
+}
+
+// agent is a registered agent; wake is signaled when a job it can take is
+// queued.
+type agent struct {
+	info AgentInfo
+	wake chan struct{}
+}
+
+// agentJob is a job waiting for, or held by, an agent. output receives the
+// command's output as it arrives, and done its result.
+type agentJob struct {
+	job       AgentJob
+	tag       string
+	agentID   string
+	agentName string
+	output    func(stream string, data []byte)
+	done      chan AgentResult
+}
+
+// AgentPool holds the remote agents registered with an HTTP server and
+// hands them the runs of tasks whose runner names one of their tags. A nil
+// AgentPool has no agents.
+type AgentPool struct {
+	mu      sync.Mutex
+	agents  map[string]*agent
+	jobs    map[string]*agentJob
+	pending []*agentJob
+	closed  chan struct{}
+	close   sync.Once
+}
+
+// NewAgentPool creates an empty pool.
+func NewAgentPool() *AgentPool {
+	return &AgentPool{
+		agents: make(map[string]*agent),
+		jobs:   make(map[string]*agentJob),
+		closed: make(chan struct{}),
+	}
+}
+
+// Close ends every waiting Next call, so a server shutting down need not
+// wait for agents' polls to time out.
+func (p *AgentPool) Close() {
+	if p != nil {
+		p.close.Do(func() { close(p.closed) })
+	}
+}
+
+// Register adds an agent offering tags and returns it, with the ID it must
+// send on later requests.
+func (p *AgentPool) Register(name string, tags []string) AgentInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	a := &agent{
+		info: AgentInfo{ID: uuid.NewString(), Name: name, Tags: slices.Clone(tags), Since: now, LastSeen: now},
+		wake: make(chan struct{}, 1),
+	}
+	p.agents[a.info.ID] = a
+	return a.info
+}
+
+// Agents returns the live agents, oldest first.
+func (p *AgentPool) Agents() []AgentInfo {
+	infos := []AgentInfo{}
+	if p == nil {
+		return infos
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prune()
+	for _, a := range p.agents {
+		info := a.info
+		info.Running = []string{}
+		for _, j := range p.jobs {
+			if j.agentID == info.ID {
+				info.Running = append(info.Running, j.job.Task)
+			}
+		}
+		sort.Strings(info.Running)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Since.Before(infos[j].Since) })
+	return infos
+}
+
+// Next waits until a job for one of the agent's tags is queued and hands it
+// to the agent. It returns nil when ctx is done or the pool is closed first.
+func (p *AgentPool) Next(ctx context.Context, agentID string) (*AgentJob, error) {
+	for {
+		p.mu.Lock()
+		a, err := p.touch(agentID)
+		if err != nil {
+			p.mu.Unlock()
+			return nil, err
+		}
+		for i, j := range p.pending {
+			if slices.Contains(a.info.Tags, j.tag) {
+				p.pending = slices.Delete(p.pending, i, i+1)
+				j.agentID, j.agentName = agentID, a.info.Name
+				p.mu.Unlock()
+				job := j.job
+				return &job, nil
+			}
+		}
+		p.mu.Unlock()
+
+		select {
+		case <-a.wake:
+		case <-p.closed:
+			return nil, nil
+		case <-ctx.Done():
+			p.mu.Lock()
+			_, _ = p.touch(agentID)
+			p.mu.Unlock()
+			return nil, nil
+		}
+	}
+}
+
+// Output passes output of a job the agent holds to its run. stream is
+// "stdout" or "stderr".
+func (p *AgentPool) Output(agentID, jobID, stream string, data []byte) error {
+	p.mu.Lock()
+	j, err := p.held(agentID, jobID)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		j.output(stream, data)
+	}
+	return nil
+}
+
+// Complete ends a job the agent holds with result.
+func (p *AgentPool) Complete(agentID, jobID string, result AgentResult) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	j, err := p.held(agentID, jobID)
+	if err != nil {
+		return err
+	}
+	delete(p.jobs, jobID)
+	j.done <- result
+	return nil
+}
+
+// run queues job for an agent with tag, passes its output to output, and
+// waits for its result. It fails at once when no agent has the tag, and
+// when ctx is done or the agent holding the job stops responding. It
+// returns the name of the agent that ran the job.
+func (p *AgentPool) run(ctx context.Context, tag string, job AgentJob, output func(stream string, data []byte)) (string, AgentResult, error) {
+	if p == nil {
+		return "", AgentResult{}, codedErrorf(ErrCodeConfig, "runner '%s' needs remote agents, which only runbook serve accepts", tag)
+	}
+
+	job.ID = uuid.NewString()
+	j := &agentJob{job: job, tag: tag, output: output, done: make(chan AgentResult, 1)}
+
+	p.mu.Lock()
+	p.prune()
+	if !p.wake(tag) {
+		p.mu.Unlock()
+		return "", AgentResult{}, codedErrorf(ErrCodeUnavailable, "no agent with tag '%s' is connected", tag)
+	}
+	p.jobs[job.ID] = j
+	p.pending = append(p.pending, j)
+	p.mu.Unlock()
+
+	check := time.NewTicker(AgentTimeout / 4)
+	defer check.Stop()
+	for {
+		select {
+		case result := <-j.done:
+			return p.agentName(j), result, nil
+		case <-ctx.Done():
+			p.drop(j)
+			return p.agentName(j), AgentResult{}, ctx.Err()
+		case <-check.C:
+			if err := p.orphaned(j); err != nil {
+				select {
+				case result := <-j.done:
+					return p.agentName(j), result, nil
+				default:
+				}
+				p.drop(j)
+				return p.agentName(j), AgentResult{}, err
+			}
+		}
+	}
+}
+
+// wake signals the agents with tag that a job for them is queued, and
+// reports whether there are any. Callers hold mu.
+func (p *AgentPool) wake(tag string) bool {
+	found := false
+	for _, a := range p.agents {
+		if slices.Contains(a.info.Tags, tag) {
+			found = true
+			select {
+			case a.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+	return found
+}
+
+// orphaned returns why j can no longer finish: the agent holding it was
+// dropped, or no agent is left to take it. It returns nil otherwise.
+func (p *AgentPool) orphaned(j *agentJob) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prune()
+	if _, ok := p.jobs[j.job.ID]; !ok {
+		return codedErrorf(ErrCodeUnavailable, "agent '%s' stopped responding while running '%s'", j.agentName, j.job.Task)
+	}
+	if j.agentID == "" && !p.wake(j.tag) {
+		return codedErrorf(ErrCodeUnavailable, "no agent with tag '%s' is connected", j.tag)
+	}
+	return nil
+}
+
+// agentName returns the name of the agent that took j, or "" if none has.
+func (p *AgentPool) agentName(j *agentJob) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return j.agentName
+}
+
+// drop forgets a job whose run has ended on the server.
+func (p *AgentPool) drop(j *agentJob) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.jobs, j.job.ID)
+	p.pending = slices.DeleteFunc(p.pending, func(q *agentJob) bool { return q == j })
+}
+
+// touch marks the agent as seen and returns it. Callers hold mu.
+func (p *AgentPool) touch(agentID string) (*agent, error) {
+	a, ok := p.agents[agentID]
+	if !ok {
+		return nil, ErrUnknownAgent
+	}
+	a.info.LastSeen = time.Now()
+	return a, nil
+}
+
+// held returns the job the agent holds and marks the agent as seen.
+// Callers hold mu.
+func (p *AgentPool) held(agentID, jobID string) (*agentJob, error) {
+	if _, err := p.touch(agentID); err != nil {
+		return nil, err
+	}
+	j, ok := p.jobs[jobID]
+	if !ok || j.agentID != agentID {
+		return nil, ErrUnknownAgentJob
+	}
+	return j, nil
+}
+
+// prune drops agents not seen for AgentTimeout, along with the jobs they
+// hold. Callers hold mu.
+func (p *AgentPool) prune() {
+	cutoff := time.Now().Add(-AgentTimeout)
+	for id, a := range p.agents {
+		if a.info.LastSeen.After(cutoff) {
+			continue
+		}
+		delete(p.agents, id)
+		for jobID, j := range p.jobs {
+			if j.agentID == id {
+				delete(p.jobs, jobID)
+			}
+		}
+	}
+}
+
+// runOnAgent runs an already-resolved command on a remote agent with the
+// task's tag, logging its output to a new session as it arrives.
+func (e *Executor) runOnAgent(parent context.Context, sessionID, taskName string, task config.Task, command string, params map[string]interface{}, startTime time.Time) (result *ExecutionResult) {
+	defer func() {
+		if result.Status == "" {
+			result.Status = resultStatus(result.Success)
+		}
+	}()
+	if e.observer != nil {
+		defer func() { e.observer.ObserveTask(taskName, result.Success, result.Duration) }()
+	}
+
+	redactor, err := logs.NewRedactor(task.Redact)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     err.Error(),
+			ErrorCode: ErrCodeConfig,
+			Duration:  time.Since(startTime),
+		}
+	}
+
+	workingDir := resolveWorkingDirectory(task, params)
+	metadata := &logs.SessionMetadata{
+		SessionID:  sessionID,
+		TaskName:   taskName,
+		TaskType:   "oneshot",
+		StartTime:  startTime,
+		Parameters: params,
+		Command:    redactor.RedactString(command),
+		WorkingDir: workingDir,
+	}
+	logWriter, err := logs.NewWriter(sessionID, metadata)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     fmt.Sprintf("failed to create log writer: %v", err),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+			SessionID: sessionID,
+		}
+	}
+	defer logWriter.Close()
+	streams, err := logs.OpenStreamLogs(sessionID, logWriter, nil)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     err.Error(),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+			SessionID: sessionID,
+		}
+	}
+	defer streams.Close()
+
+	// Output arrives from agent requests while the run waits; it goes to the
+	// logs as it comes so logs_ and tail show remote runs live
+	var mu sync.Mutex
+	var stdoutBuf, stderrBuf bytes.Buffer
+	var stdout, stderr io.Writer = io.MultiWriter(&stdoutBuf, streams.Stdout()), io.MultiWriter(&stderrBuf, streams.Stderr())
+	if e.stdout != nil {
+		stdout = io.MultiWriter(stdout, e.stdout)
+	}
+	if e.stderr != nil {
+		stderr = io.MultiWriter(stderr, e.stderr)
+	}
+	var redactedStdout, redactedStderr *logs.RedactingWriter
+	if redactor != nil {
+		redactedStdout = logs.NewRedactingWriter(stdout, redactor)
+		redactedStderr = logs.NewRedactingWriter(stderr, redactor)
+		stdout, stderr = redactedStdout, redactedStderr
+	}
+	finished := false
+	output := func(stream string, data []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		if finished {
+			return
+		}
+		w := stdout
+		if stream == logs.StreamStderr {
+			w = stderr
+		}
+		_, _ = w.Write(data)
+	}
+
+	ctx := parent
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, time.Duration(task.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	job := AgentJob{
+		SessionID:  sessionID,
+		Task:       taskName,
+		Command:    command,
+		Shell:      task.Shell,
+		WorkingDir: workingDir,
+		Env:        task.Env,
+		EnvPolicy:  task.EnvPolicy,
+		Timeout:    task.Timeout,
+	}
+	agentName, agentResult, runErr := e.agents.run(ctx, task.AgentTag(), job, output)
+
+	mu.Lock()
+	finished = true
+	if redactor != nil {
+		_ = redactedStdout.Close()
+		_ = redactedStderr.Close()
+	}
+	mu.Unlock()
+	duration := time.Since(startTime)
+
+	exitCode := agentResult.ExitCode
+	success := true
+	errorMsg := ""
+	status, reason := StatusSuccess, ""
+	timedOut := false
+	errorCode := ""
+	switch {
+	case runErr != nil && errors.Is(runErr, context.DeadlineExceeded) && parent.Err() == nil:
+		timedOut = true
+		success, exitCode, status = false, -1, StatusFailure
+		errorMsg = fmt.Sprintf("command timed out after %d seconds", task.Timeout)
+		errorCode = ErrCodeTimeout
+	case runErr != nil && parent.Err() != nil:
+		success, exitCode, status = false, -1, StatusFailure
+		errorMsg = fmt.Sprintf("command interrupted: %v", context.Cause(parent))
+	case runErr != nil:
+		success, exitCode, status = false, -1, StatusFailure
+		errorMsg = runErr.Error()
+		errorCode = ErrorCode(runErr)
+	case agentResult.Error != "":
+		// The agent could not start the command
+		success, status = false, StatusFailure
+		errorMsg = agentResult.Error
+		errorCode = ErrCodeIO
+	default:
+		status, reason = classifyExitCode(task, exitCode)
+		if status == StatusFailure {
+			success = false
+			errorMsg = fmt.Sprintf("command exited with code %d", exitCode)
+		}
+	}
+	if agentName != "" && errorMsg != "" {
+		errorMsg = fmt.Sprintf("%s (agent '%s')", errorMsg, agentName)
+	}
+
+	logWriter.UpdateMetadata(map[string]interface{}{
+		"exit_code": exitCode,
+		"success":   success,
+		"status":    status,
+		"timed_out": timedOut,
+		"agent":     agentName,
+	})
+
+	return &ExecutionResult{
+		Success:      success,
+		Status:       status,
+		StatusReason: reason,
+		ExitCode:     exitCode,
+		Stdout:       stdoutBuf.String(),
+		Stderr:       stderrBuf.String(),
+		Duration:     duration,
+		Error:        errorMsg,
+		ErrorCode:    errorCode,
+		TaskName:     taskName,
+		LogPath:      logWriter.GetLogPath(),
+		TimedOut:     timedOut,
+		SessionID:    sessionID,
+		Streamed:     e.stdout != nil,
+		Timeout:      task.Timeout,
+		Agent:        agentName,
+	}
+}
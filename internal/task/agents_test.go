@@ -0,0 +1,64 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAgentPoolMatchesTags(t *testing.T) {
+	pool := NewAgentPool()
+	linux := pool.Register("linux-1", []string{"linux"})
+	mac := pool.Register("mac-1", []string{"macos", "arm"})
+
+	type outcome struct {
+		agent  string
+		result AgentResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		name, result, err := pool.run(context.Background(), "macos", AgentJob{Task: "sign"}, func(string, []byte) {})
+		done <- outcome{name, result, err}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if job, err := pool.Next(ctx, linux.ID); job != nil || err != nil {
+		t.Fatalf("linux agent got job %v, err %v; want none", job, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	job, err := pool.Next(ctx, mac.ID)
+	if err != nil || job == nil || job.Task != "sign" {
+		t.Fatalf("mac agent got job %v, err %v; want sign", job, err)
+	}
+	if err := pool.Complete(linux.ID, job.ID, AgentResult{}); !errors.Is(err, ErrUnknownAgentJob) {
+		t.Errorf("Complete() from another agent error = %v, want ErrUnknownAgentJob", err)
+	}
+	if err := pool.Output("missing", job.ID, "stdout", nil); !errors.Is(err, ErrUnknownAgent) {
+		t.Errorf("Output() from an unknown agent error = %v, want ErrUnknownAgent", err)
+	}
+	if err := pool.Complete(mac.ID, job.ID, AgentResult{ExitCode: 2}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	got := <-done
+	if got.err != nil || got.agent != "mac-1" || got.result.ExitCode != 2 {
+		t.Errorf("run() = %q, %+v, %v; want mac-1 with exit code 2", got.agent, got.result, got.err)
+	}
+
+	if _, _, err := pool.run(context.Background(), "windows", AgentJob{Task: "sign"}, nil); err == nil {
+		t.Error("run() with no agent for the tag should fail")
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := pool.run(ctx, "linux", AgentJob{Task: "test"}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("run() with a done context error = %v, want context.Canceled", err)
+	}
+	if infos := pool.Agents(); len(infos) != 2 || len(infos[0].Running) != 0 {
+		t.Errorf("Agents() = %+v, want two idle agents", infos)
+	}
+}
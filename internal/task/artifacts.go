@@ -0,0 +1,101 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// collectArtifacts copies the files matching patterns, relative to
+// workingDir, into the session's artifacts directory. Matched directories are
+// copied with their contents. Files keep their path relative to workingDir;
+// files outside it keep only their name. A pattern that matches nothing is
+// not an error.
+func collectArtifacts(sessionID, workingDir string, patterns []string) ([]logs.Artifact, error) {
+	if workingDir == "" {
+		workingDir = "."
+	}
+	dest := logs.GetSessionArtifactsDir(sessionID)
+
+	copied := make(map[string]bool)
+	var artifacts []logs.Artifact
+	var errs []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(workingDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid pattern '%s': %v", pattern, err))
+			continue
+		}
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.Type().IsRegular() {
+					return nil
+				}
+				rel := artifactPath(workingDir, path)
+				if copied[rel] {
+					return nil
+				}
+				size, err := copyFile(path, filepath.Join(dest, rel))
+				if err != nil {
+					return err
+				}
+				copied[rel] = true
+				artifacts = append(artifacts, logs.Artifact{Path: filepath.ToSlash(rel), Size: size})
+				return nil
+			})
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path < artifacts[j].Path })
+	if len(errs) > 0 {
+		return artifacts, fmt.Errorf("failed to collect artifacts: %s", strings.Join(errs, "; "))
+	}
+	return artifacts, nil
+}
+
+// artifactPath returns where path is stored in the artifacts directory.
+func artifactPath(workingDir, path string) string {
+	rel, err := filepath.Rel(workingDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(path)
+	}
+	return rel
+}
+
+// copyFile copies src to dst, creating dst's directory, and returns the
+// number of bytes copied.
+func copyFile(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return 0, err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestExecuteCollectsArtifacts(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test": {
+				Command:   "mkdir -p reports/unit && echo cover > coverage.out && echo ok > reports/unit/junit.xml && exit 1",
+				Type:      config.TaskTypeOneShot,
+				Timeout:   30,
+				Artifacts: []string{"coverage.out", "reports", "missing-*.txt"},
+			},
+		},
+	}
+	result, err := NewExecutor(manifest).Execute("test", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success {
+		t.Fatal("expected the task to fail")
+	}
+
+	want := []logs.Artifact{{Path: "coverage.out", Size: 6}, {Path: "reports/unit/junit.xml", Size: 3}}
+	if len(result.Artifacts) != len(want) {
+		t.Fatalf("expected artifacts %+v even on failure, got %+v", want, result.Artifacts)
+	}
+	for i, a := range want {
+		if result.Artifacts[i] != a {
+			t.Errorf("artifact %d: expected %+v, got %+v", i, a, result.Artifacts[i])
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(logs.GetSessionArtifactsDir(result.SessionID), "reports", "unit", "junit.xml"))
+	if err != nil || string(data) != "ok\n" {
+		t.Errorf("expected copied artifact, got %q (%v)", data, err)
+	}
+
+	metadata, err := logs.ReadSessionMetadata(result.SessionID)
+	if err != nil {
+		t.Fatalf("ReadSessionMetadata: %v", err)
+	}
+	if len(metadata.Artifacts) != 2 {
+		t.Errorf("expected artifacts in session metadata, got %+v", metadata.Artifacts)
+	}
+}
+
+func TestArtifactPathOutsideWorkingDir(t *testing.T) {
+	if got := artifactPath("/work/app", "/work/app/dist/app.js"); got != filepath.Join("dist", "app.js") {
+		t.Errorf("expected path relative to the working directory, got %q", got)
+	}
+	if got := artifactPath("/work/app", "/tmp/build.log"); got != "build.log" {
+		t.Errorf("expected only the name of a file outside the working directory, got %q", got)
+	}
+}
@@ -0,0 +1,234 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// maxFinishedBackgroundRuns is how many finished background runs keep their
+// results; older ones are forgotten first.
+const maxFinishedBackgroundRuns = 100
+
+// ErrRunInProgress is returned for the result of a background run that has
+// not finished.
+var ErrRunInProgress = errors.New("run is still in progress")
+
+// Background run states.
+const (
+	BackgroundRunning   = "running"
+	BackgroundCompleted = "completed"
+)
+
+// BackgroundRuns tracks oneshot runs started in the background, by session
+// ID, so their status and result can be fetched after the call that started
+// them has returned.
+type BackgroundRuns struct {
+	mu       sync.Mutex
+	runs     map[string]*backgroundRun
+	finished []string // Session IDs of finished runs, oldest first
+	active   int
+	inflight sync.WaitGroup
+}
+
+// backgroundRun is one run; done is closed when result is set.
+type backgroundRun struct {
+	task    string
+	started time.Time
+	done    chan struct{}
+	result  *ExecutionResult
+	err     error
+}
+
+// BackgroundStatus describes a background run, or a session found on disk.
+type BackgroundStatus struct {
+	SessionID string    `json:"session_id"`
+	TaskName  string    `json:"task_name"`
+	State     string    `json:"state"` // BackgroundRunning or BackgroundCompleted
+	StartTime time.Time `json:"start_time"`
+	Elapsed   string    `json:"elapsed"`
+	Success   *bool     `json:"success,omitempty"`   // Set once completed
+	Status    string    `json:"status,omitempty"`    // success, warning, or failure, once completed
+	ExitCode  *int      `json:"exit_code,omitempty"` // Set once completed
+}
+
+// NewBackgroundRuns creates an empty set of background runs.
+func NewBackgroundRuns() *BackgroundRuns {
+	return &BackgroundRuns{runs: make(map[string]*backgroundRun)}
+}
+
+// SetBackgroundRuns makes the manager track background runs in b, so a
+// reloaded manager still knows the runs started by the one it replaces.
+func (m *Manager) SetBackgroundRuns(b *BackgroundRuns) {
+	m.background = b
+}
+
+// BackgroundRuns returns the manager's background runs.
+func (m *Manager) BackgroundRuns() *BackgroundRuns {
+	return m.background
+}
+
+// ExecuteOneShotAsync starts a oneshot task in the background and returns
+// the session ID it will run as, without waiting for it to start. Queued
+// runs, required daemons, and rate limits apply as for ExecuteOneShot.
+func (m *Manager) ExecuteOneShotAsync(taskName string, params map[string]interface{}) (string, error) {
+	task, exists := m.manifest.Tasks[taskName]
+	if !exists {
+		return "", errTaskNotFound(taskName)
+	}
+	if task.Type.IsDaemon() {
+		return "", fmt.Errorf("task '%s' is a daemon, use daemon operations instead", taskName)
+	}
+	if task.Interactive {
+		return "", errNeedsTerminal(taskName)
+	}
+
+	// The session ID is chosen here so it can be returned at once
+	sessionID := logs.GenerateSessionID()
+	run := &backgroundRun{task: taskName, started: time.Now(), done: make(chan struct{})}
+	m.background.add(sessionID, run)
+	go func() {
+		result, err := m.executeOneShot(taskName, params, sessionID)
+		m.background.finish(sessionID, run, result, err)
+	}()
+	return sessionID, nil
+}
+
+// add records a run that has just started.
+func (b *BackgroundRuns) add(sessionID string, run *backgroundRun) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.runs[sessionID] = run
+	b.active++
+	b.inflight.Add(1)
+}
+
+// finish stores a run's result, and forgets the oldest finished runs over
+// maxFinishedBackgroundRuns.
+func (b *BackgroundRuns) finish(sessionID string, run *backgroundRun, result *ExecutionResult, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.inflight.Done()
+	b.active--
+	run.result, run.err = result, err
+	close(run.done)
+	b.finished = append(b.finished, sessionID)
+	for len(b.finished) > maxFinishedBackgroundRuns {
+		delete(b.runs, b.finished[0])
+		b.finished = b.finished[1:]
+	}
+}
+
+// Running returns the number of background runs that have not finished.
+func (b *BackgroundRuns) Running() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.active
+}
+
+// Wait waits up to grace for the running background runs to finish, so
+// their results and session metadata are written. It reports whether they
+// all finished.
+func (b *BackgroundRuns) Wait(grace time.Duration) bool {
+	if b.Running() == 0 {
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		b.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(grace):
+		return false
+	}
+}
+
+// get returns the background run with the given session ID.
+func (b *BackgroundRuns) get(sessionID string) (*backgroundRun, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	run, ok := b.runs[sessionID]
+	return run, ok
+}
+
+// BackgroundStatus returns the state of a background run. Sessions that were
+// not started in the background, or were started by an earlier server, are
+// described from their metadata.
+func (m *Manager) BackgroundStatus(sessionID string) (*BackgroundStatus, error) {
+	run, ok := m.background.get(sessionID)
+	if !ok {
+		return sessionStatus(sessionID)
+	}
+
+	status := &BackgroundStatus{SessionID: sessionID, TaskName: run.task, State: BackgroundRunning, StartTime: run.started}
+	select {
+	case <-run.done:
+		status.State = BackgroundCompleted
+		success := run.err == nil && run.result.Success
+		status.Success = &success
+		if run.result != nil {
+			status.Status = run.result.Status
+			status.ExitCode = &run.result.ExitCode
+			status.Elapsed = run.result.Duration.String()
+		}
+	default:
+	}
+	if status.Elapsed == "" {
+		status.Elapsed = time.Since(run.started).Round(time.Millisecond).String()
+	}
+	return status, nil
+}
+
+// sessionStatus describes a session from its metadata. A session without an
+// end time is reported as running.
+func sessionStatus(sessionID string) (*BackgroundStatus, error) {
+	metadata, err := logs.ReadSessionMetadata(sessionID)
+	if err != nil {
+		return nil, codedErrorf(ErrCodeNotFound, "session '%s' not found", sessionID)
+	}
+	status := &BackgroundStatus{
+		SessionID: sessionID,
+		TaskName:  metadata.TaskName,
+		State:     BackgroundRunning,
+		StartTime: metadata.StartTime,
+		Elapsed:   time.Since(metadata.StartTime).Round(time.Millisecond).String(),
+	}
+	if metadata.EndTime != nil {
+		status.State = BackgroundCompleted
+		status.Elapsed = metadata.EndTime.Sub(metadata.StartTime).String()
+		status.Success = metadata.Success
+		status.Status = metadata.Status
+		status.ExitCode = metadata.ExitCode
+	}
+	return status, nil
+}
+
+// BackgroundResult returns the result of a background run, waiting up to
+// wait for it to finish. It returns ErrRunInProgress if the run is still
+// going when the wait is over.
+func (m *Manager) BackgroundResult(sessionID string, wait time.Duration) (*ExecutionResult, error) {
+	run, ok := m.background.get(sessionID)
+	if !ok {
+		return nil, codedErrorf(ErrCodeNotFound, "no background run with session '%s'; read finished sessions with read_session_metadata", sessionID)
+	}
+
+	select {
+	case <-run.done:
+		return run.result, run.err
+	default:
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-run.done:
+		return run.result, run.err
+	case <-timer.C:
+		return nil, ErrRunInProgress
+	}
+}
@@ -0,0 +1,189 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// Actions reported in DaemonBulkResult.
+const (
+	BulkActionStopped   = "stopped"
+	BulkActionRestarted = "restarted"
+	BulkActionSkipped   = "skipped"
+)
+
+// StopDaemons stops the running daemons among names, each before the daemons
+// it requires. Daemons that nothing left running requires are stopped
+// together, so they share one grace period. Daemons that are not running are
+// skipped.
+func (m *Manager) StopDaemons(names []string) []DaemonBulkResult {
+	results := make([]DaemonBulkResult, 0, len(names))
+	for _, wave := range m.stopWaves(names) {
+		waveResults := make([]DaemonBulkResult, len(wave))
+		var wg sync.WaitGroup
+		for i, name := range wave {
+			if running, _, _ := m.processManager.Status(name); !running {
+				waveResults[i] = DaemonBulkResult{Task: name, Success: true, Action: BulkActionSkipped, Message: "not running"}
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				waveResults[i] = m.stopForBulk(name)
+			}()
+		}
+		wg.Wait()
+		results = append(results, waveResults...)
+	}
+	return results
+}
+
+// RestartDaemons restarts the running daemons among names with the
+// parameters they were started with. All of them are stopped first, each
+// before the daemons it requires, and then started in dependency order.
+// Daemons that are not running are skipped.
+func (m *Manager) RestartDaemons(names []string) []DaemonBulkResult {
+	order := m.daemonOrder(names)
+	results := make(map[string]DaemonBulkResult, len(order))
+	params := make(map[string]map[string]interface{})
+
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+		if running, _, _ := m.processManager.Status(name); !running {
+			results[name] = DaemonBulkResult{Task: name, Success: true, Action: BulkActionSkipped, Message: "not running"}
+			continue
+		}
+		if sessionID, err := m.processManager.GetSessionID(name); err == nil {
+			if metadata, err := logs.ReadSessionMetadata(sessionID); err == nil {
+				params[name] = metadata.Parameters
+			}
+		}
+		if stopped := m.stopForBulk(name); !stopped.Success {
+			results[name] = stopped
+		}
+	}
+
+	ordered := make([]DaemonBulkResult, 0, len(order))
+	for _, name := range order {
+		if result, done := results[name]; done {
+			ordered = append(ordered, result)
+			continue
+		}
+		start, err := m.startDaemon(name, m.manifest.Tasks[name], params[name])
+		if err == nil && !start.Success {
+			err = fmt.Errorf("%s", start.Error)
+		}
+		if err != nil {
+			ordered = append(ordered, DaemonBulkResult{Task: name, Action: BulkActionStopped, Error: fmt.Sprintf("stopped but failed to start again: %v", err)})
+			continue
+		}
+		ordered = append(ordered, DaemonBulkResult{
+			Task:      name,
+			Success:   true,
+			Action:    BulkActionRestarted,
+			PID:       start.PID,
+			SessionID: start.SessionID,
+		})
+	}
+	return ordered
+}
+
+// DaemonStatuses returns the status of each daemon in names, sorted by name.
+func (m *Manager) DaemonStatuses(names []string) []NamedDaemonStatus {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	statuses := make([]NamedDaemonStatus, 0, len(sorted))
+	for _, name := range sorted {
+		entry := NamedDaemonStatus{Task: name}
+		if status, err := m.DaemonStatus(name); err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.DaemonStatus = *status
+		}
+		statuses = append(statuses, entry)
+	}
+	return statuses
+}
+
+// stopForBulk stops a running daemon and reports the outcome.
+func (m *Manager) stopForBulk(name string) DaemonBulkResult {
+	stop, err := m.StopDaemon(name)
+	if err != nil {
+		return DaemonBulkResult{Task: name, Error: err.Error()}
+	}
+	if !stop.Success {
+		return DaemonBulkResult{Task: name, Error: stop.Error}
+	}
+	return DaemonBulkResult{Task: name, Success: true, Action: BulkActionStopped, Message: stop.Message}
+}
+
+// stopWaves groups the daemons among names into the order they can be
+// stopped in: each daemon is in a later wave than every daemon that
+// requires it, and the daemons within a wave are independent of each other.
+func (m *Manager) stopWaves(names []string) [][]string {
+	order := m.daemonOrder(names)
+	selected := make(map[string]bool, len(order))
+	for _, name := range order {
+		selected[name] = true
+	}
+
+	// Walk dependents before their dependencies, pushing each dependency
+	// at least one wave past the daemons that require it.
+	wave := make(map[string]int, len(order))
+	last := 0
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+		for _, dep := range m.manifest.Tasks[name].RequiresDaemon {
+			if selected[dep] && wave[dep] < wave[name]+1 {
+				wave[dep] = wave[name] + 1
+			}
+		}
+		last = max(last, wave[name])
+	}
+
+	waves := make([][]string, last+1)
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+		waves[wave[name]] = append(waves[wave[name]], name)
+	}
+	return waves
+}
+
+// daemonOrder returns the daemons among names that exist in the manifest,
+// sorted so that each comes after the daemons it requires.
+func (m *Manager) daemonOrder(names []string) []string {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		if task, ok := m.manifest.Tasks[name]; ok && task.Type.IsDaemon() {
+			selected[name] = true
+		}
+	}
+	sorted := make([]string, 0, len(selected))
+	for name := range selected {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var order []string
+	visited := make(map[string]bool, len(sorted))
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, dep := range m.manifest.Tasks[name].RequiresDaemon {
+			if selected[dep] {
+				visit(dep)
+			}
+		}
+		order = append(order, name)
+	}
+	for _, name := range sorted {
+		visit(name)
+	}
+	return order
+}
@@ -0,0 +1,139 @@
+package task
+
+import (
+	"fmt"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// sessionProcessManager writes session metadata on start, like the real
+// process manager, so parameters recorded by the manager can be read back.
+type sessionProcessManager struct {
+	*MockProcessManager
+}
+
+func (m sessionProcessManager) Start(taskName, sessionID, cmd string, env map[string]string, cwd, logPath, shell string) error {
+	if err := m.MockProcessManager.Start(taskName, sessionID, cmd, env, cwd, logPath, shell); err != nil {
+		return err
+	}
+	if err := logs.CreateSessionDirectory(sessionID); err != nil {
+		return err
+	}
+	return logs.WriteSessionMetadata(sessionID, &logs.SessionMetadata{SessionID: sessionID, TaskName: taskName, TaskType: "daemon"})
+}
+
+func bulkTestManager(t *testing.T) (*Manager, *MockProcessManager) {
+	t.Helper()
+	defaultPort := "8080"
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"db":     {Command: "sleep 100", Type: config.TaskTypeDaemon},
+			"api":    {Command: "serve --port {{.port}}", Type: config.TaskTypeDaemon, RequiresDaemon: []string{"db"}, Parameters: map[string]config.Param{"port": {Type: "string", Default: &defaultPort}}},
+			"worker": {Command: "sleep 100", Type: config.TaskTypeDaemon},
+			"build":  {Command: "echo build", Type: config.TaskTypeOneShot},
+		},
+	}
+	pm := NewMockProcessManager()
+	return NewManager(manifest, sessionProcessManager{pm}), pm
+}
+
+func TestStopDaemonsOrder(t *testing.T) {
+	defer setupWorkflowTest(t)()
+	manager, pm := bulkTestManager(t)
+	for _, name := range []string{"db", "api"} {
+		if result, err := manager.StartDaemon(name, nil); err != nil || !result.Success {
+			t.Fatalf("failed to start %s: %v %+v", name, err, result)
+		}
+	}
+
+	results := manager.StopDaemons([]string{"db", "api", "worker", "build"})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results (oneshot ignored), got %+v", results)
+	}
+	got := []string{results[0].Task, results[1].Task, results[2].Task}
+	want := []string{"worker", "api", "db"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+	if results[0].Action != BulkActionSkipped || !results[0].Success {
+		t.Errorf("expected worker to be skipped, got %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if r.Action != BulkActionStopped || !r.Success {
+			t.Errorf("expected %s to be stopped, got %+v", r.Task, r)
+		}
+		if running, _, _ := pm.Status(r.Task); running {
+			t.Errorf("expected %s to be stopped", r.Task)
+		}
+	}
+}
+
+func TestStopWaves(t *testing.T) {
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"db":     {Command: "sleep 100", Type: config.TaskTypeDaemon},
+			"api":    {Command: "sleep 100", Type: config.TaskTypeDaemon, RequiresDaemon: []string{"db"}},
+			"web":    {Command: "sleep 100", Type: config.TaskTypeDaemon, RequiresDaemon: []string{"api"}},
+			"worker": {Command: "sleep 100", Type: config.TaskTypeDaemon, RequiresDaemon: []string{"db"}},
+			"cache":  {Command: "sleep 100", Type: config.TaskTypeDaemon},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	got := fmt.Sprint(manager.stopWaves([]string{"db", "api", "web", "worker", "cache"}))
+	if want := "[[worker web cache] [api] [db]]"; got != want {
+		t.Errorf("expected waves %s, got %s", want, got)
+	}
+	if got := fmt.Sprint(manager.stopWaves(nil)); got != "[[]]" {
+		t.Errorf("expected one empty wave, got %s", got)
+	}
+}
+
+func TestRestartDaemonsKeepsParameters(t *testing.T) {
+	defer setupWorkflowTest(t)()
+	manager, pm := bulkTestManager(t)
+	if result, err := manager.StartDaemon("api", map[string]interface{}{"port": "9000"}); err != nil || !result.Success {
+		t.Fatalf("failed to start api: %v %+v", err, result)
+	}
+	oldSession, _ := pm.GetSessionID("api")
+
+	results := manager.RestartDaemons([]string{"db", "api", "worker"})
+	byName := make(map[string]DaemonBulkResult)
+	for _, r := range results {
+		byName[r.Task] = r
+	}
+	if r := byName["api"]; !r.Success || r.Action != BulkActionRestarted {
+		t.Fatalf("expected api to restart, got %+v", r)
+	}
+	if r := byName["worker"]; r.Action != BulkActionSkipped {
+		t.Errorf("expected worker to be skipped, got %+v", r)
+	}
+	if cmd, _ := pm.GetCommand("api"); cmd != "serve --port 9000" {
+		t.Errorf("expected api restarted with its parameters, got %q", cmd)
+	}
+	if newSession, _ := pm.GetSessionID("api"); newSession == oldSession {
+		t.Error("expected a new session after restart")
+	}
+}
+
+func TestDaemonStatuses(t *testing.T) {
+	defer setupWorkflowTest(t)()
+	manager, _ := bulkTestManager(t)
+	if _, err := manager.StartDaemon("worker", nil); err != nil {
+		t.Fatalf("failed to start worker: %v", err)
+	}
+
+	statuses := manager.DaemonStatuses([]string{"worker", "db"})
+	if len(statuses) != 2 || statuses[0].Task != "db" || statuses[1].Task != "worker" {
+		t.Fatalf("expected statuses sorted by name, got %+v", statuses)
+	}
+	if statuses[0].Running || !statuses[1].Running {
+		t.Errorf("expected only worker running, got %+v", statuses)
+	}
+}
@@ -0,0 +1,124 @@
+package task
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/dirs"
+	"runbookmcp.dev/internal/template"
+)
+
+// NoCacheParam is the call parameter that makes a task with a cache run its
+// command even when a cached result exists. The new result replaces it.
+const NoCacheParam = "no_cache"
+
+// cacheDir holds the stored results of tasks with a cache, one file per key.
+const cacheDir = dirs.StateDir + "/cache"
+
+// cacheEntry is a stored result and when it was stored.
+type cacheEntry struct {
+	StoredAt time.Time        `json:"stored_at"`
+	Result   *ExecutionResult `json:"result"`
+}
+
+// takeNoCache removes the no_cache argument from params and reports whether
+// it was set, unless the task defines a parameter of the same name.
+func takeNoCache(task config.Task, params map[string]interface{}) bool {
+	if _, defined := task.Parameters[NoCacheParam]; defined {
+		return false
+	}
+	value := params[NoCacheParam]
+	delete(params, NoCacheParam)
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// cacheKey returns the key of a run of task: its rendered cache.key along
+// with the task name, command, working directory, and parameters, so a
+// change to any of them misses; a timeout override does not. A key that
+// refers to files_hash gets the git state of the working directory; outside
+// a git repository the run is not cached and cacheKey returns "".
+func cacheKey(taskName string, task config.Task, params map[string]interface{}, data map[string]interface{}) (string, error) {
+	workingDir := resolveWorkingDirectory(task, params)
+	if strings.Contains(task.Cache.Key, config.FilesHashKey) {
+		if _, defined := task.Parameters[config.FilesHashKey]; !defined {
+			dir := workingDir
+			if dir == "" {
+				dir = "."
+			}
+			fp := fingerprintWorkdir(dir)
+			if fp == nil {
+				return "", nil
+			}
+			data[config.FilesHashKey] = fmt.Sprintf("%x", sha256.Sum256([]byte(fp.Commit+"\x00"+fp.DirtyHash)))
+		}
+	}
+	key, err := template.SubstituteParameters(task.Cache.Key, data)
+	if err != nil {
+		return "", fmt.Errorf("cache key: %w", err)
+	}
+	keyParams := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		keyParams[name] = value
+	}
+	if _, defined := task.Parameters[TimeoutParam]; !defined {
+		delete(keyParams, TimeoutParam)
+	}
+	inputs := strings.Join([]string{taskName, task.Command, workingDir, key}, "\x00")
+	return dedupKey(inputs, keyParams), nil
+}
+
+// cachePath returns the file a cache key's result is stored in.
+func cachePath(key string) string {
+	return filepath.Join(cacheDir, key+".json")
+}
+
+// loadCached returns the stored result for key, or nil when there is none
+// or it is older than the task's cache.ttl. The result is marked Cached.
+func loadCached(task config.Task, key string) *ExecutionResult {
+	data, err := os.ReadFile(cachePath(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Result == nil {
+		return nil
+	}
+	if ttl := task.Cache.Expiry(); ttl > 0 && time.Since(entry.StoredAt) > ttl {
+		_ = os.Remove(cachePath(key))
+		return nil
+	}
+	entry.Result.Cached = true
+	return entry.Result
+}
+
+// storeCached stores a successful result under key. Failed runs are not
+// stored, so the next run tries again.
+func storeCached(key string, result *ExecutionResult) {
+	if result == nil || !result.Success || result.TimedOut {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{StoredAt: time.Now(), Result: result})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return
+	}
+	tmp := cachePath(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, cachePath(key))
+}
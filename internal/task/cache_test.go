@@ -0,0 +1,128 @@
+package task
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestExecutorCache(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	counter, _ := filepath.Abs("runs")
+	command := "echo run >> " + counter + "; echo {{.pkg}}; test {{.pkg}} != bad"
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"lint": {
+				Description: "Lint",
+				Command:     command,
+				Type:        config.TaskTypeOneShot,
+				Parameters:  map[string]config.Param{"pkg": {Type: "string", Description: "Package"}},
+				Cache:       &config.TaskCache{Key: "v1", TTL: "200ms"},
+			},
+		},
+	}
+	executor := NewExecutor(manifest)
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "run")
+	}
+	run := func(params map[string]interface{}) *ExecutionResult {
+		t.Helper()
+		result, err := executor.Execute("lint", params)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return result
+	}
+
+	first := run(map[string]interface{}{"pkg": "a"})
+	second := run(map[string]interface{}{"pkg": "a", TimeoutParam: float64(30)})
+	if first.Cached || !second.Cached || runs() != 1 {
+		t.Fatalf("cached = %v, %v after %d runs; want the second call served from the cache", first.Cached, second.Cached, runs())
+	}
+	if second.Stdout != "a\n" || second.SessionID != first.SessionID {
+		t.Errorf("cached result = %+v, want the first run's", second)
+	}
+
+	if run(map[string]interface{}{"pkg": "b"}).Cached || runs() != 2 {
+		t.Errorf("different parameters should run the command, runs = %d", runs())
+	}
+	if run(map[string]interface{}{"pkg": "a", NoCacheParam: true}).Cached || runs() != 3 {
+		t.Errorf("no_cache should run the command, runs = %d", runs())
+	}
+
+	run(map[string]interface{}{"pkg": "bad"})
+	if run(map[string]interface{}{"pkg": "bad"}).Cached || runs() != 5 {
+		t.Errorf("failed runs should not be cached, runs = %d", runs())
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if run(map[string]interface{}{"pkg": "a"}).Cached || runs() != 6 {
+		t.Errorf("results older than ttl should not be used, runs = %d", runs())
+	}
+}
+
+func TestExecutorCacheFilesHash(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git unavailable: %v: %s", err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"lint": {
+				Description:      "Lint",
+				Command:          "cat main.go",
+				Type:             config.TaskTypeOneShot,
+				WorkingDirectory: repo,
+				Cache:            &config.TaskCache{Key: "{{.files_hash}}"},
+			},
+		},
+	}
+	executor := NewExecutor(manifest)
+	execute := func() *ExecutionResult {
+		t.Helper()
+		result, err := executor.Execute("lint", nil)
+		if err != nil || !result.Success {
+			t.Fatalf("Execute() = %+v, %v", result, err)
+		}
+		return result
+	}
+
+	execute()
+	if !execute().Cached {
+		t.Error("unchanged files should be served from the cache")
+	}
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := execute(); result.Cached || !strings.Contains(result.Stdout, "func main") {
+		t.Errorf("changed files should run the command, got %+v", result)
+	}
+}
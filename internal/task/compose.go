@@ -0,0 +1,115 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// composeCommand is the CLI that manages compose tasks.
+var composeCommand = []string{"docker", "compose"}
+
+// ComposeService is one container of a compose task's stack, as reported by
+// docker compose ps.
+type ComposeService struct {
+	Name    string `json:"name"`
+	Service string `json:"service"`
+	State   string `json:"state"`
+	Health  string `json:"health,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+// composeArgs returns the full compose command line for a subcommand of the
+// task's stack. Services are appended when withServices is set.
+func composeArgs(task config.Task, withServices bool, args ...string) []string {
+	cmd := append([]string{}, composeCommand...)
+	if c := task.Compose; c != nil {
+		if c.File != "" {
+			cmd = append(cmd, "-f", c.File)
+		}
+		if c.Project != "" {
+			cmd = append(cmd, "-p", c.Project)
+		}
+	}
+	cmd = append(cmd, args...)
+	if withServices && task.Compose != nil {
+		cmd = append(cmd, task.Compose.Services...)
+	}
+	return cmd
+}
+
+// runCompose runs a compose subcommand for the task's stack in dir and
+// returns its combined output.
+func runCompose(task config.Task, dir string, withServices bool, args ...string) (string, error) {
+	argv := composeArgs(task, withServices, args...)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = taskEnviron(task)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("%s failed: %w: %s", strings.Join(argv[:len(composeCommand)+1], " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// composeFollowCommand returns the shell command that follows the stack's
+// logs. It runs as the task's daemon process, so its session log and status
+// track the stack.
+func composeFollowCommand(task config.Task) string {
+	argv := composeArgs(task, true, "logs", "--follow", "--no-color")
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return "exec " + strings.Join(quoted, " ")
+}
+
+// composeServices lists the containers of the task's stack.
+func composeServices(task config.Task, dir string) ([]ComposeService, error) {
+	out, err := runCompose(task, dir, true, "ps", "--all", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+	return parseComposePS([]byte(out))
+}
+
+// parseComposePS parses docker compose ps JSON output, which is either an
+// array or one object per line depending on the compose version.
+func parseComposePS(out []byte) ([]ComposeService, error) {
+	type psEntry struct {
+		Name    string `json:"Name"`
+		Service string `json:"Service"`
+		State   string `json:"State"`
+		Health  string `json:"Health"`
+		Status  string `json:"Status"`
+	}
+
+	var entries []psEntry
+	out = bytes.TrimSpace(out)
+	if bytes.HasPrefix(out, []byte("[")) {
+		if err := json.Unmarshal(out, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse compose ps output: %w", err)
+		}
+	} else {
+		for _, line := range bytes.Split(out, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var e psEntry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse compose ps output: %w", err)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	services := make([]ComposeService, len(entries))
+	for i, e := range entries {
+		services[i] = ComposeService{Name: e.Name, Service: e.Service, State: e.State, Health: e.Health, Status: e.Status}
+	}
+	return services, nil
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// fakeCompose replaces the compose CLI with a script that records its
+// arguments and prints canned ps output.
+func fakeCompose(t *testing.T) (argsLog string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsLog = filepath.Join(dir, "args")
+	script := filepath.Join(dir, "compose")
+	body := `#!/bin/sh
+echo "$@" >> ` + argsLog + `
+case "$*" in
+  *" ps "*) echo '{"Name":"app-db-1","Service":"db","State":"running","Health":"healthy"}' ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := composeCommand
+	composeCommand = []string{script}
+	t.Cleanup(func() { composeCommand = old })
+	return argsLog
+}
+
+func TestComposeTask(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	argsLog := fakeCompose(t)
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"stack": {
+				Description: "App stack",
+				Type:        config.TaskTypeCompose,
+				Compose:     &config.ComposeConfig{File: "dev compose.yml", Project: "app", Services: []string{"db"}},
+			},
+		},
+	}
+	pm := NewMockProcessManager()
+	manager := NewManager(manifest, pm)
+
+	result, err := manager.StartDaemon("stack", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("StartDaemon failed: %v %+v", err, result)
+	}
+	follow, _ := pm.GetCommand("stack")
+	if !strings.Contains(follow, "'-f' 'dev compose.yml' '-p' 'app' 'logs' '--follow' '--no-color' 'db'") {
+		t.Errorf("unexpected follow command: %s", follow)
+	}
+
+	status, err := manager.DaemonStatus("stack")
+	if err != nil {
+		t.Fatalf("DaemonStatus failed: %v", err)
+	}
+	if !status.Running || len(status.Services) != 1 || status.Services[0].Health != "healthy" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	stop, err := manager.StopDaemon("stack")
+	if err != nil || !stop.Success {
+		t.Fatalf("StopDaemon failed: %v %+v", err, stop)
+	}
+	if running, _, _ := pm.Status("stack"); running {
+		t.Error("expected log follower to be stopped")
+	}
+
+	data, _ := os.ReadFile(argsLog)
+	want := "-f dev compose.yml -p app up --detach db\n" +
+		"-f dev compose.yml -p app ps --all --format json db\n" +
+		"-f dev compose.yml -p app rm --stop --force db\n"
+	if string(data) != want {
+		t.Errorf("unexpected compose calls:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestParseComposePS(t *testing.T) {
+	array := `[{"Name":"a-web-1","Service":"web","State":"running"},{"Name":"a-db-1","Service":"db","State":"exited","Status":"Exited (1)"}]`
+	lines := "{\"Name\":\"a-web-1\",\"Service\":\"web\",\"State\":\"running\"}\n{\"Name\":\"a-db-1\",\"Service\":\"db\",\"State\":\"exited\",\"Status\":\"Exited (1)\"}\n"
+	for _, out := range []string{array, lines} {
+		services, err := parseComposePS([]byte(out))
+		if err != nil {
+			t.Fatalf("parseComposePS failed: %v", err)
+		}
+		if len(services) != 2 || services[1].Service != "db" || services[1].Status != "Exited (1)" {
+			t.Errorf("unexpected services: %+v", services)
+		}
+	}
+	if services, err := parseComposePS(nil); err != nil || len(services) != 0 {
+		t.Errorf("expected no services for empty output, got %v, %v", services, err)
+	}
+}
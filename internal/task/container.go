@@ -0,0 +1,68 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// ContainerWorkdir is where a container task's working directory is mounted.
+const ContainerWorkdir = "/workspace"
+
+// dockerBinary is the container CLI used for runner: docker.
+const dockerBinary = "docker"
+
+// containerName returns the name given to the container of a session, so it
+// can be removed if the run times out.
+func containerName(sessionID string) string {
+	return "runbook-" + sessionID
+}
+
+// containerArgs returns the docker CLI arguments that run command in the
+// task's container. workingDir is mounted at ContainerWorkdir, and relative
+// mount sources are resolved against it. Env values are not on the command
+// line; docker reads them from the CLI's environment.
+func containerArgs(name string, task config.Task, command string, workingDir string) []string {
+	c := task.Container
+	args := []string{"run", "--rm", "-i", "--name", name}
+	if c.Network != "" {
+		args = append(args, "--network", c.Network)
+	}
+
+	args = append(args, "-v", workingDir+":"+ContainerWorkdir, "-w", ContainerWorkdir)
+	for _, mount := range c.Mounts {
+		if strings.HasPrefix(mount, ".") {
+			source, rest, _ := strings.Cut(mount, ":")
+			mount = filepath.Join(workingDir, source) + ":" + rest
+		}
+		args = append(args, "-v", mount)
+	}
+
+	keys := make([]string, 0, len(task.Env))
+	for key := range task.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", key)
+	}
+
+	shell := task.Shell
+	if shell == "" {
+		shell = "sh"
+	}
+	return append(append(args, c.Image), config.ShellArgs(shell, command)...)
+}
+
+// removeContainer force-removes a container left running after its docker
+// CLI process was killed.
+func removeContainer(name string) {
+	if err := exec.Command(dockerBinary, "rm", "-f", name).Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove container %s: %v\n", name, err)
+	}
+}
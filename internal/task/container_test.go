@@ -0,0 +1,46 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestContainerArgs(t *testing.T) {
+	task := config.Task{
+		Runner: config.RunnerDocker,
+		Env:    map[string]string{"TOKEN": "secret", "CI": "1"},
+		Container: &config.ContainerConfig{
+			Image:   "golang:1.24",
+			Mounts:  []string{"./cache:/root/.cache:ro", "gomod:/go/pkg/mod"},
+			Network: "none",
+		},
+	}
+
+	got := containerArgs("runbook-abc", task, "go test ./...", "/src/app")
+	want := []string{
+		"run", "--rm", "-i", "--name", "runbook-abc",
+		"--network", "none",
+		"-v", "/src/app:/workspace", "-w", "/workspace",
+		"-v", "/src/app/cache:/root/.cache:ro",
+		"-v", "gomod:/go/pkg/mod",
+		"-e", "CI", "-e", "TOKEN",
+		"golang:1.24", "sh", "-c", "go test ./...",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected args:\n got %q\nwant %q", got, want)
+	}
+
+	task.Shell = "/bin/bash"
+	got = containerArgs("runbook-abc", task, "true", "/src/app")
+	if shell := got[len(got)-3]; shell != "/bin/bash" {
+		t.Errorf("expected the task's shell in the container, got %q", shell)
+	}
+
+	task.Shell = "python3 -c"
+	got = containerArgs("runbook-abc", task, "print(1)", "/src/app")
+	if tail := got[len(got)-3:]; !reflect.DeepEqual(tail, []string{"python3", "-c", "print(1)"}) {
+		t.Errorf("expected the interpreter argv in the container, got %q", tail)
+	}
+}
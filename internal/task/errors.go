@@ -0,0 +1,88 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Error codes classify why a call failed, so agents can branch on the kind
+// of failure instead of parsing the message. Results and tool errors carry
+// them as error_code. A task whose command ran and exited non-zero has no
+// code; its exit_code says how it failed.
+const (
+	ErrCodeConfig         = "CONFIG_ERROR"        // The task definition or the call's arguments are invalid
+	ErrCodeTemplate       = "TEMPLATE_ERROR"      // A command, var, or key template could not be rendered
+	ErrCodeTimeout        = "TIMEOUT"             // The run was killed when its timeout passed
+	ErrCodeCancelled      = "CANCELLED"           // The run was killed because its caller went away or the server shut down
+	ErrCodeNotFound       = "NOT_FOUND"           // No task, workflow, or session has the given name
+	ErrCodePermission     = "PERMISSION"          // A policy refused the call, e.g. allowed_working_directories
+	ErrCodeAlreadyRunning = "ALREADY_RUNNING"     // The daemon to start is already running
+	ErrCodeNotRunning     = "NOT_RUNNING"         // The daemon to stop is not running
+	ErrCodeRateLimited    = "RATE_LIMITED"        // The task or tool was called too often; retry_after says when to retry
+	ErrCodePrecondition   = "PRECONDITION_FAILED" // A precondition kept the command from running
+	ErrCodeUnavailable    = "UNAVAILABLE"         // Something the call needs is missing, e.g. an agent, or the server is shutting down
+	ErrCodeInProgress     = "IN_PROGRESS"         // The run has not finished; ask for its result again later
+	ErrCodeIO             = "IO_ERROR"            // A file, log, or process could not be created, read, or written
+	ErrCodeInternal       = "INTERNAL_ERROR"      // The server failed to build its response
+)
+
+// CodedError is an error with one of the error codes.
+type CodedError struct {
+	Code string
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// codedErrorf returns a CodedError with code and a formatted message.
+func codedErrorf(code string, format string, args ...interface{}) error {
+	return &CodedError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// ErrorCode returns the error code of err, or "" when it has none.
+func ErrorCode(err error) string {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ""
+}
+
+// errorCodeOr returns the error code of err, or code when it has none.
+func errorCodeOr(err error, code string) string {
+	if c := ErrorCode(err); c != "" {
+		return c
+	}
+	return code
+}
+
+// contextCode returns the error code of a run ended by ctx: TIMEOUT when
+// its deadline passed, CANCELLED when it was cancelled, and "" while it is
+// live.
+func contextCode(ctx context.Context) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrCodeTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		return ErrCodeCancelled
+	}
+	return ""
+}
+
+// errTaskNotFound is returned for a call naming a task the manifest does not
+// define.
+func errTaskNotFound(taskName string) error {
+	return codedErrorf(ErrCodeNotFound, "task '%s' not found", taskName)
+}
+
+// errNotDaemon is returned for a daemon operation on a oneshot task.
+func errNotDaemon(taskName string) error {
+	return codedErrorf(ErrCodeConfig, "task '%s' is not a daemon", taskName)
+}
@@ -0,0 +1,75 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestErrorCodes(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"bad_template": {Description: "b", Command: "echo {{.missing}}", Type: config.TaskTypeOneShot},
+			"slow":         {Description: "s", Command: "sleep 5", Type: config.TaskTypeOneShot, Timeout: 1},
+			"confined": {
+				Description:               "c",
+				Command:                   "true",
+				Type:                      config.TaskTypeOneShot,
+				ExposeWorkingDirectory:    true,
+				AllowedWorkingDirectories: []string{"/nonexistent/*"},
+			},
+			"dev":  {Description: "d", Command: "go run .", Type: config.TaskTypeDaemon},
+			"repl": {Description: "r", Command: "cat", Type: config.TaskTypeDaemon, Interactive: true},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	if _, err := manager.ExecuteOneShot("nope", nil); ErrorCode(err) != ErrCodeNotFound {
+		t.Errorf("unknown task: error = %v, want code %s", err, ErrCodeNotFound)
+	}
+	if _, err := manager.ExecuteOneShot("dev", nil); ErrorCode(err) != ErrCodeConfig {
+		t.Errorf("daemon run as oneshot: error = %v, want code %s", err, ErrCodeConfig)
+	}
+	for name, want := range map[string]string{
+		"bad_template": ErrCodeTemplate,
+		"slow":         ErrCodeTimeout,
+		"confined":     ErrCodePermission,
+	} {
+		result, err := manager.ExecuteOneShot(name, map[string]interface{}{"working_directory": "/tmp"})
+		if err != nil {
+			t.Fatalf("%s: ExecuteOneShot() error = %v", name, err)
+		}
+		if result.Success || result.ErrorCode != want {
+			t.Errorf("%s: error_code = %q (%s), want %s", name, result.ErrorCode, result.Error, want)
+		}
+	}
+
+	if result, _ := manager.StartDaemon("dev", nil); !result.Success {
+		t.Fatalf("StartDaemon() = %+v", result)
+	}
+	if result, _ := manager.StartDaemon("dev", nil); result.Success || result.ErrorCode != ErrCodeAlreadyRunning {
+		t.Errorf("second start: %+v, want error_code %s", result, ErrCodeAlreadyRunning)
+	}
+	if result, _ := manager.StopDaemon("nope"); result.ErrorCode != ErrCodeNotFound {
+		t.Errorf("stop unknown: %+v, want error_code %s", result, ErrCodeNotFound)
+	}
+
+	// A run whose caller goes away is cancelled, not timed out
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	if result, err := manager.executor.executeContext(ctx, "slow", nil, 0); err != nil || result.ErrorCode != ErrCodeCancelled {
+		t.Errorf("cancelled run: %+v (%v), want error_code %s", result, err, ErrCodeCancelled)
+	}
+
+	if _, err := manager.SendInput("repl", "x\n"); ErrorCode(err) != ErrCodeUnavailable {
+		t.Errorf("send input without interactive support: error = %v, want code %s", err, ErrCodeUnavailable)
+	}
+}
@@ -0,0 +1,146 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestExecuteCommand(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks:   map[string]config.Task{},
+		Defaults: config.Defaults{
+			Env: map[string]string{"RUNBOOK_EXEC_TEST": "from-defaults"},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	result, err := manager.ExecuteCommand("echo $RUNBOOK_EXEC_TEST {{.not_a_template}}", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got: %s", result.Error)
+	}
+	if !strings.Contains(result.Stdout, "from-defaults {{.not_a_template}}") {
+		t.Errorf("expected defaults env and literal braces in output, got %q", result.Stdout)
+	}
+	if result.TaskName != AdHocTaskName {
+		t.Errorf("expected task name %q, got %q", AdHocTaskName, result.TaskName)
+	}
+
+	metadata, err := logs.ReadSessionMetadata(result.SessionID)
+	if err != nil {
+		t.Fatalf("failed to read session metadata: %v", err)
+	}
+	if metadata.TaskName != AdHocTaskName {
+		t.Errorf("expected session task name %q, got %q", AdHocTaskName, metadata.TaskName)
+	}
+}
+
+func TestExecuteCommandTimeout(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks:   map[string]config.Task{},
+		Exec:    config.ExecConfig{Timeout: 1},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	result, err := manager.ExecuteCommand("sleep 5", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.TimedOut {
+		t.Error("expected exec timeout to apply")
+	}
+
+	if _, err := manager.ExecuteCommand("", "", 0); err == nil {
+		t.Error("expected error for empty command")
+	}
+}
+
+func TestExecuteCommandLimits(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	allowed := t.TempDir()
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks:   map[string]config.Task{},
+		Exec:    config.ExecConfig{MaxTimeout: 1, AllowedWorkingDirectories: []string{allowed}},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	result, err := manager.ExecuteCommand("sleep 5", "", 3600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.TimedOut {
+		t.Error("expected the timeout argument to be capped at exec.max_timeout")
+	}
+
+	result, err = manager.ExecuteCommand("pwd", t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success || result.ErrorCode != ErrCodePermission {
+		t.Errorf("expected a PERMISSION failure outside allowed_working_directories, got %+v", result)
+	}
+	if result.SessionID != "" {
+		t.Errorf("expected the refused command not to run, got session %s", result.SessionID)
+	}
+
+	result, err = manager.ExecuteCommand("pwd", allowed, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected success in an allowed directory, got: %s", result.Error)
+	}
+}
+
+func TestExecuteRedactsOutput(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"deploy": {
+				Description: "Deploy",
+				Command:     "echo token=abc123; echo 'password: hunter2' >&2",
+				Type:        config.TaskTypeOneShot,
+				Redact:      []string{`token=(\w+)`, `hunter2`},
+			},
+		},
+	}
+
+	result, err := NewExecutor(manifest).Execute("deploy", nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Stdout != "token=[REDACTED]\n" {
+		t.Errorf("stdout = %q", result.Stdout)
+	}
+	if result.Stderr != "password: [REDACTED]\n" {
+		t.Errorf("stderr = %q", result.Stderr)
+	}
+
+	lines, _, err := logs.ReadSessionLog(result.SessionID, logs.ReadOptions{})
+	if err != nil {
+		t.Fatalf("failed to read session log: %v", err)
+	}
+	log := strings.Join(lines, "\n")
+	if strings.Contains(log, "abc123") || strings.Contains(log, "hunter2") {
+		t.Errorf("expected secrets to be redacted in the log, got %q", log)
+	}
+}
@@ -7,24 +7,44 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/logs"
 	"runbookmcp.dev/internal/template"
 )
 
+// AdHocTaskName is the task name under which ad-hoc exec sessions are logged.
+const AdHocTaskName = "exec"
+
+// TimeoutParam is the call parameter that overrides a task's timeout for a
+// single run, unless the task defines a parameter of the same name.
+const TimeoutParam = "timeout"
+
+// DefaultMaxTimeout bounds the timeout override, in seconds, when neither the
+// task nor the manifest defaults set max_timeout.
+const DefaultMaxTimeout = 3600
+
 // Executor handles execution of one-shot tasks
 type Executor struct {
 	manifest *config.Manifest
-	stdout   io.Writer // if set, stream stdout here in addition to logging
-	stderr   io.Writer // if set, stream stderr here in addition to logging
+	stdout   io.Writer      // if set, stream stdout here in addition to logging
+	stderr   io.Writer      // if set, stream stderr here in addition to logging
+	observer Observer       // if set, notified of every completed run
+	faults   *faultInjector // if set, simulates failures from testing.faults
+	queue    *RunQueue      // if set, bounds how many tasks run at once
+	terminal *Terminal      // if set, interactive tasks run on it
+	agents   *AgentPool     // if set, tasks with an agent runner run on its agents
 }
 
 // NewExecutor creates a new task executor
 func NewExecutor(manifest *config.Manifest) *Executor {
 	return &Executor{
 		manifest: manifest,
+		faults:   newFaultInjector(manifest.Testing),
 	}
 }
 
@@ -39,6 +59,62 @@ func resolveWorkingDirectory(task config.Task, params map[string]interface{}) st
 	return task.WorkingDirectory
 }
 
+// resolveTimeout determines the timeout in seconds for a task run
+// Priority: 1) timeout parameter, capped at the task's max_timeout, 2) static task field
+func resolveTimeout(task config.Task, params map[string]interface{}) int {
+	if _, defined := task.Parameters[TimeoutParam]; defined {
+		return task.Timeout
+	}
+	var override int
+	switch v := params[TimeoutParam].(type) {
+	case float64:
+		override = int(v)
+	case int:
+		override = v
+	}
+	if override <= 0 {
+		return task.Timeout
+	}
+	return min(override, MaxTimeout(task))
+}
+
+// taskEnviron returns the environment a task's processes run with: the host
+// variables its env_policy lets through, then its env.
+func taskEnviron(task config.Task) []string {
+	env := task.EnvPolicy.Filter(os.Environ())
+	for key, value := range task.Env {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
+// dropReservedParams removes arguments with config.ReservedParamPrefix, so
+// a client cannot reach runbook's internals through a task's arguments.
+func dropReservedParams(params map[string]interface{}) {
+	for name := range params {
+		if strings.HasPrefix(name, config.ReservedParamPrefix) {
+			delete(params, name)
+		}
+	}
+}
+
+// MaxTimeout returns the largest timeout override, in seconds, accepted for a task.
+func MaxTimeout(task config.Task) int {
+	if task.MaxTimeout > 0 {
+		return task.MaxTimeout
+	}
+	return DefaultMaxTimeout
+}
+
+// ExecMaxTimeout returns the largest timeout, in seconds, accepted for an
+// ad-hoc command: exec.max_timeout, then defaults.max_timeout.
+func ExecMaxTimeout(manifest *config.Manifest) int {
+	if manifest.Exec.MaxTimeout > 0 {
+		return manifest.Exec.MaxTimeout
+	}
+	return MaxTimeout(config.Task{MaxTimeout: manifest.Defaults.MaxTimeout})
+}
+
 // applyDefaults merges default parameter values into the provided params map
 // Returns a new map with defaults applied for missing parameters
 func (e *Executor) applyDefaults(task config.Task, params map[string]interface{}) map[string]interface{} {
@@ -62,54 +138,270 @@ func (e *Executor) applyDefaults(task config.Task, params map[string]interface{}
 
 // Execute runs a one-shot task with the given parameters
 func (e *Executor) Execute(taskName string, params map[string]interface{}) (*ExecutionResult, error) {
+	return e.executeContext(context.Background(), taskName, params, 0)
+}
+
+// executeContext runs a one-shot task like Execute, and kills it if ctx is
+// done first. A timeout above 0 replaces the task's timeout, in seconds.
+func (e *Executor) executeContext(ctx context.Context, taskName string, params map[string]interface{}, timeout int) (*ExecutionResult, error) {
+	return e.executeSession(ctx, "", taskName, params, timeout)
+}
+
+// executeSession runs a one-shot task like executeContext, as the session
+// sessionID, which must be a UUID. An empty sessionID starts a new session.
+func (e *Executor) executeSession(ctx context.Context, sessionID string, taskName string, params map[string]interface{}, timeout int) (*ExecutionResult, error) {
+	if sessionID == "" {
+		sessionID = logs.GenerateSessionID()
+	} else if _, err := uuid.Parse(sessionID); err != nil {
+		// The ID names the session's log directory
+		return nil, codedErrorf(ErrCodeConfig, "invalid session ID %q", sessionID)
+	}
+	result, err := e.execute(ctx, sessionID, taskName, params, timeout)
+	if result != nil && result.Status == "" {
+		result.Status = resultStatus(result.Success)
+	}
+	return result, err
+}
+
+// execute runs a one-shot task; Execute fills in the status of results that
+// did not get one from their exit code.
+func (e *Executor) execute(ctx context.Context, sessionID string, taskName string, params map[string]interface{}, timeout int) (*ExecutionResult, error) {
 	// Get task definition
 	task, exists := e.manifest.Tasks[taskName]
 	if !exists {
-		return nil, fmt.Errorf("task '%s' not found", taskName)
+		return nil, errTaskNotFound(taskName)
 	}
 
 	// Verify task type
-	if task.Type == config.TaskTypeDaemon {
-		return nil, fmt.Errorf("task '%s' is a daemon, use daemon operations instead", taskName)
+	if task.Type.IsDaemon() {
+		return nil, codedErrorf(ErrCodeConfig, "task '%s' is a daemon, use daemon operations instead", taskName)
+	}
+	if task.Interactive && e.terminal == nil {
+		return nil, errNeedsTerminal(taskName)
 	}
 
-	// Generate session ID
-	sessionID := logs.GenerateSessionID()
 	startTime := time.Now()
 
 	// Apply default parameter values
 	params = e.applyDefaults(task, params)
+	dropReservedParams(params)
+	noCache := takeNoCache(task, params)
+
+	if err := checkParams(taskName, task, params); err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     err.Error(),
+			ErrorCode: errorCodeOr(err, ErrCodeConfig),
+			Duration:  time.Since(startTime),
+		}, nil
+	}
+
+	if e.faults != nil {
+		if result := e.faults.apply(taskName, task, startTime); result != nil {
+			if e.observer != nil {
+				e.observer.ObserveTask(taskName, result.Success, result.Duration)
+			}
+			return result, nil
+		}
+	}
+
+	// Templates see the call's parameters and the manifest's vars
+	vars, err := template.ResolveVars(e.manifest.Vars)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     err.Error(),
+			ErrorCode: ErrCodeTemplate,
+			Duration:  time.Since(startTime),
+		}, nil
+	}
+
+	if failure := checkPreconditions(task, template.CommandData(params, vars)); failure != nil {
+		return &ExecutionResult{
+			Success:            false,
+			TaskName:           taskName,
+			Error:              preconditionError(failure),
+			ErrorCode:          ErrCodePrecondition,
+			Duration:           time.Since(startTime),
+			PreconditionFailed: failure,
+		}, nil
+	}
+
+	// File operations run in-process instead of through a shell
+	if task.Type == config.TaskTypeFileOps {
+		return e.runFileOps(sessionID, taskName, task, params, template.CommandData(params, vars), startTime), nil
+	}
+
+	// Tasks with a cache return the result of an earlier run with the same
+	// key, computed before file parameters are replaced by temp paths
+	var cacheKeyValue string
+	if task.Cache != nil {
+		cacheKeyValue, err = cacheKey(taskName, task, params, template.CommandData(params, vars))
+		if err != nil {
+			return &ExecutionResult{
+				Success:   false,
+				TaskName:  taskName,
+				Error:     err.Error(),
+				ErrorCode: ErrCodeTemplate,
+				Duration:  time.Since(startTime),
+			}, nil
+		}
+		if cacheKeyValue != "" && !noCache {
+			if cached := loadCached(task, cacheKeyValue); cached != nil {
+				return cached, nil
+			}
+		}
+	}
+
+	// Pass file and stdin parameters to the command through temp files
+	cleanup, err := stageInputs(task, params)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     err.Error(),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+		}, nil
+	}
+	defer cleanup()
 
 	// Substitute parameters in command
-	command, err := template.SubstituteParameters(task.Command, params)
+	command, err := template.SubstituteParameters(task.Command, template.CommandData(params, vars))
 	if err != nil {
 		return &ExecutionResult{
-			Success:  false,
-			TaskName: taskName,
-			Error:    fmt.Sprintf("parameter substitution failed: %v", err),
-			Duration: time.Since(startTime),
+			Success:   false,
+			TaskName:  taskName,
+			Error:     fmt.Sprintf("parameter substitution failed: %v", err),
+			ErrorCode: ErrCodeTemplate,
+			Duration:  time.Since(startTime),
 		}, nil
 	}
 
+	task.Timeout = resolveTimeout(task, params)
+	if timeout > 0 {
+		task.Timeout = timeout
+	}
+
+	// Agents run the command on their own hosts, so agent runs don't take a
+	// slot here
+	if task.AgentTag() != "" {
+		result := e.runOnAgent(ctx, sessionID, taskName, task, command, params, time.Now())
+		parseOutput(task, result)
+		limitStdout(task, result)
+		if cacheKeyValue != "" {
+			storeCached(cacheKeyValue, result)
+		}
+		return result, nil
+	}
+
+	// Wait for a free slot; time spent queued is not part of the run
+	release := e.queue.acquire(taskName)
+	defer release()
+	startTime = time.Now()
+
+	result := e.run(ctx, sessionID, taskName, task, command, params, startTime)
+	parseOutput(task, result)
+	limitStdout(task, result)
+	if cacheKeyValue != "" {
+		storeCached(cacheKeyValue, result)
+	}
+	return result, nil
+}
+
+// ExecuteAdHoc runs an arbitrary shell command that is not defined in the
+// manifest. It goes through the same session logging, timeout, and working
+// directory handling as a task, and is logged under AdHocTaskName. The
+// timeout is capped at exec.max_timeout and the working directory must match
+// exec.allowed_working_directories. The command is not treated as a template.
+func (e *Executor) ExecuteAdHoc(command string, workingDir string, timeout int) *ExecutionResult {
+	startTime := time.Now()
+	task := config.Task{
+		Command:                   command,
+		Type:                      config.TaskTypeOneShot,
+		WorkingDirectory:          workingDir,
+		ExposeWorkingDirectory:    true,
+		AllowedWorkingDirectories: e.manifest.Exec.AllowedWorkingDirectories,
+		Timeout:                   e.manifest.Exec.Timeout,
+		MaxTimeout:                ExecMaxTimeout(e.manifest),
+		Shell:                     e.manifest.Exec.Shell,
+		Env:                       e.manifest.Defaults.Env,
+		Redact:                    e.manifest.Defaults.Redact,
+		EnvPolicy:                 e.manifest.Defaults.EnvPolicy,
+	}
+	if task.Timeout == 0 {
+		task.Timeout = e.manifest.Defaults.Timeout
+	}
+	if task.Shell == "" {
+		task.Shell = e.manifest.Defaults.Shell
+	}
+	params := map[string]interface{}{"working_directory": workingDir, TimeoutParam: timeout}
+	if err := checkWorkingDirectory(AdHocTaskName, task, params); err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			Status:    StatusFailure,
+			TaskName:  AdHocTaskName,
+			Error:     err.Error(),
+			ErrorCode: errorCodeOr(err, ErrCodePermission),
+			Duration:  time.Since(startTime),
+		}
+	}
+	task.Timeout = resolveTimeout(task, params)
+	return e.run(context.Background(), logs.GenerateSessionID(), AdHocTaskName, task, command, map[string]interface{}{}, startTime)
+}
+
+// run executes an already-resolved command for the given task definition,
+// capturing output into a new session. The command is killed when the task
+// times out or ctx is done, whichever comes first.
+func (e *Executor) run(parent context.Context, sessionID, taskName string, task config.Task, command string, params map[string]interface{}, startTime time.Time) (result *ExecutionResult) {
+	defer func() {
+		if result.Status == "" {
+			result.Status = resultStatus(result.Success)
+		}
+	}()
+	if e.observer != nil {
+		defer func() { e.observer.ObserveTask(taskName, result.Success, result.Duration) }()
+	}
+
 	// Determine shell
 	shell := task.Shell
 	if shell == "" {
 		shell = "/bin/bash"
 	}
 
-	// Create command
-	cmd := exec.Command(shell, "-c", command)
-
 	// Set working directory
 	workingDir := resolveWorkingDirectory(task, params)
+
+	// Create command, inside a container for runner: docker
+	argv := config.ShellArgs(shell, command)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	if task.Runner == config.RunnerDocker {
+		hostDir, _ := filepath.Abs(workingDir)
+		cmd = exec.Command(dockerBinary, containerArgs(containerName(sessionID), task, command, hostDir)...)
+	}
 	if workingDir != "" {
 		cmd.Dir = workingDir
 	}
 
 	// Set environment variables
-	cmd.Env = os.Environ()
-	for key, value := range task.Env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	cmd.Env = taskEnviron(task)
+
+	// Feed a stdin parameter to the command
+	stdin, err := stdinInput(task, params)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     fmt.Sprintf("failed to open stdin input: %v", err),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+		}
+	}
+	if stdin != nil {
+		defer stdin.Close()
+		cmd.Stdin = stdin
 	}
 
 	// Create buffers for output; stream to caller if writers are set
@@ -125,12 +417,30 @@ func (e *Executor) Execute(taskName string, params map[string]interface{}) (*Exe
 		cmd.Stderr = &stderrBuf
 	}
 
+	// Mask redact patterns before output reaches the caller or the log
+	redactor, err := logs.NewRedactor(task.Redact)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     err.Error(),
+			ErrorCode: ErrCodeConfig,
+			Duration:  time.Since(startTime),
+		}
+	}
+
 	// Get current working directory for metadata
 	cwd, _ := os.Getwd()
 	if workingDir != "" {
 		cwd = workingDir
 	}
 
+	// Record the code state of a working directory chosen at call time
+	var fingerprint *logs.WorkdirFingerprint
+	if wd, ok := params["working_directory"].(string); ok && wd != "" && task.ExposeWorkingDirectory {
+		fingerprint = fingerprintWorkdir(wd)
+	}
+
 	// Create session metadata
 	metadata := &logs.SessionMetadata{
 		SessionID:  sessionID,
@@ -138,8 +448,11 @@ func (e *Executor) Execute(taskName string, params map[string]interface{}) (*Exe
 		TaskType:   "oneshot",
 		StartTime:  startTime,
 		Parameters: params,
-		Command:    command,
+		Command:    redactor.RedactString(command),
+		Argv:       logs.RedactArgs(cmd.Args, redactor),
+		Env:        logs.CaptureEnviron(cmd.Env, redactor),
 		WorkingDir: cwd,
+		Workdir:    fingerprint,
 	}
 
 	// Create log writer
@@ -149,32 +462,64 @@ func (e *Executor) Execute(taskName string, params map[string]interface{}) (*Exe
 			Success:   false,
 			TaskName:  taskName,
 			Error:     fmt.Sprintf("failed to create log writer: %v", err),
+			ErrorCode: ErrCodeIO,
 			Duration:  time.Since(startTime),
 			SessionID: sessionID,
-		}, nil
+		}
 	}
 	defer logWriter.Close()
 
+	// Log each stream as it arrives, to its own log and the combined one
+	streams, err := logs.OpenStreamLogs(sessionID, logWriter, nil)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     err.Error(),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+			SessionID: sessionID,
+		}
+	}
+	defer streams.Close()
+	cmd.Stdout = io.MultiWriter(cmd.Stdout, streams.Stdout())
+	cmd.Stderr = io.MultiWriter(cmd.Stderr, streams.Stderr())
+
+	var redactedStdout, redactedStderr *logs.RedactingWriter
+	if redactor != nil {
+		redactedStdout = logs.NewRedactingWriter(cmd.Stdout, redactor)
+		redactedStderr = logs.NewRedactingWriter(cmd.Stderr, redactor)
+		cmd.Stdout, cmd.Stderr = redactedStdout, redactedStderr
+	}
+
+	// Interactive tasks get the terminal itself; their output goes straight
+	// to it and is not captured
+	if task.Interactive {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = e.terminal.Stdin, e.terminal.Stdout, e.terminal.Stderr
+		defer ignoreInterrupts()()
+	}
+
 	// Handle timeout
 	var ctx context.Context
 	var cancel context.CancelFunc
 	timedOut := false
 
 	if task.Timeout > 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(task.Timeout)*time.Second)
+		ctx, cancel = context.WithTimeout(parent, time.Duration(task.Timeout)*time.Second)
 		defer cancel()
 	} else {
-		ctx = context.Background()
+		ctx = parent
 	}
 
 	// Start command
 	if err := cmd.Start(); err != nil {
 		return &ExecutionResult{
-			Success:  false,
-			TaskName: taskName,
-			Error:    fmt.Sprintf("failed to start command: %v", err),
-			Duration: time.Since(startTime),
-		}, nil
+			Success:   false,
+			TaskName:  taskName,
+			Error:     fmt.Sprintf("failed to start command: %v", err),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+		}
 	}
 
 	// Wait for command to complete or timeout
@@ -191,12 +536,19 @@ func (e *Executor) Execute(taskName string, params map[string]interface{}) (*Exe
 				fmt.Fprintf(os.Stderr, "Warning: failed to kill process: %v\n", killErr)
 			}
 		}
+		if task.Runner == config.RunnerDocker {
+			removeContainer(containerName(sessionID))
+		}
 		timedOut = true
 		// Wait for Wait() to complete after kill
 		<-done
 	case <-done:
 		// Command completed (error is captured in ProcessState)
 	}
+	if redactor != nil {
+		_ = redactedStdout.Close()
+		_ = redactedStderr.Close()
+	}
 
 	duration := time.Since(startTime)
 
@@ -204,51 +556,87 @@ func (e *Executor) Execute(taskName string, params map[string]interface{}) (*Exe
 	stdout := stdoutBuf.String()
 	stderr := stderrBuf.String()
 
-	// Write to log file
-	logContent := stdout
-	if stderr != "" {
-		logContent += "\n" + stderr
-	}
-	if _, err := logWriter.Write([]byte(logContent)); err != nil {
-		// Log write error but don't fail the task
-		fmt.Fprintf(os.Stderr, "Warning: failed to write to log: %v\n", err)
-	}
-
 	// Determine success
 	exitCode := 0
 	success := true
 	errorMsg := ""
+	status, reason := StatusSuccess, ""
 
+	errorCode := ""
 	if timedOut {
+		// The task's own deadline, or the caller going away
+		errorCode = contextCode(ctx)
 		success = false
 		exitCode = -1
 		errorMsg = fmt.Sprintf("command timed out after %d seconds", task.Timeout)
+		if parent.Err() != nil {
+			errorMsg = fmt.Sprintf("command interrupted: %v", context.Cause(parent))
+		}
+		status = StatusFailure
 	} else if cmd.ProcessState != nil {
 		exitCode = cmd.ProcessState.ExitCode()
-		if exitCode != 0 {
+		status, reason = classifyExitCode(task, exitCode)
+		if status == StatusFailure {
 			success = false
 			errorMsg = fmt.Sprintf("command exited with code %d", exitCode)
 		}
 	}
 
+	resources := logs.UsageFromProcessState(cmd.ProcessState, duration)
+
+	// Keep declared output files with the session, whether or not the run succeeded
+	var artifacts []logs.Artifact
+	if len(task.Artifacts) > 0 {
+		artifacts, err = collectArtifacts(sessionID, workingDir, task.Artifacts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
 	// Update writer metadata with execution results
 	logWriter.UpdateMetadata(map[string]interface{}{
 		"exit_code": exitCode,
 		"success":   success,
+		"status":    status,
 		"timed_out": timedOut,
+		"resources": resources,
+		"artifacts": artifacts,
 	})
 
 	return &ExecutionResult{
-		Success:   success,
-		ExitCode:  exitCode,
-		Stdout:    stdout,
-		Stderr:    stderr,
-		Duration:  duration,
-		Error:     errorMsg,
-		TaskName:  taskName,
-		LogPath:   logWriter.GetLogPath(),
-		TimedOut:  timedOut,
-		SessionID: sessionID,
-		Streamed:  e.stdout != nil,
-	}, nil
+		Success:      success,
+		Status:       status,
+		StatusReason: reason,
+		ExitCode:     exitCode,
+		Stdout:       stdout,
+		Stderr:       stderr,
+		Duration:     duration,
+		Error:        errorMsg,
+		ErrorCode:    errorCode,
+		TaskName:     taskName,
+		LogPath:      logWriter.GetLogPath(),
+		TimedOut:     timedOut,
+		SessionID:    sessionID,
+		Streamed:     e.stdout != nil || task.Interactive,
+		Resources:    resources,
+		Timeout:      task.Timeout,
+		Workdir:      fingerprint,
+		Artifacts:    artifacts,
+	}
+}
+
+// classifyExitCode returns the status of a run that exited with exitCode and
+// what the code means, per the task's expected_exit_codes and
+// warning_exit_codes. Other non-zero codes are failures.
+func classifyExitCode(task config.Task, exitCode int) (string, string) {
+	if reason, ok := task.WarningExitCodes[exitCode]; ok {
+		return StatusWarning, reason
+	}
+	if reason, ok := task.ExpectedExitCodes[exitCode]; ok {
+		return StatusSuccess, reason
+	}
+	if exitCode != 0 {
+		return StatusFailure, ""
+	}
+	return StatusSuccess, ""
 }
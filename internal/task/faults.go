@@ -0,0 +1,113 @@
+package task
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// Environment variables that control fault injection.
+const (
+	TestingEnv     = "RUNBOOK_TESTING"      // "1" enables testing.faults without testing.enabled
+	TestingSeedEnv = "RUNBOOK_TESTING_SEED" // overrides testing.seed
+)
+
+// faultInjector applies the testing.faults of a manifest to task runs. Runs
+// draw from one seeded source in order, so a fixed seed gives the same
+// faults for the same sequence of runs.
+type faultInjector struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	faults map[string]config.Fault
+	sleep  func(time.Duration)
+}
+
+// newFaultInjector returns nil unless faults are configured and enabled.
+func newFaultInjector(cfg config.TestingConfig) *faultInjector {
+	if len(cfg.Faults) == 0 || (!cfg.Enabled && os.Getenv(TestingEnv) != "1") {
+		return nil
+	}
+	seed := cfg.Seed
+	if v, err := strconv.ParseInt(os.Getenv(TestingSeedEnv), 10, 64); err == nil {
+		seed = v
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &faultInjector{
+		rng:    rand.New(rand.NewSource(seed)),
+		faults: cfg.Faults,
+		sleep:  time.Sleep,
+	}
+}
+
+// faultFor returns the fault for taskName. An exact name wins over glob
+// patterns, which are tried in sorted order.
+func (f *faultInjector) faultFor(taskName string) (config.Fault, bool) {
+	if fault, ok := f.faults[taskName]; ok {
+		return fault, true
+	}
+	patterns := make([]string, 0, len(f.faults))
+	for pattern := range f.faults {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, taskName); matched {
+			return f.faults[pattern], true
+		}
+	}
+	return config.Fault{}, false
+}
+
+// apply waits out the fault's delay and returns a simulated result if the
+// run should fail or time out, or nil if the task should run normally.
+func (f *faultInjector) apply(taskName string, task config.Task, startTime time.Time) *ExecutionResult {
+	fault, ok := f.faultFor(taskName)
+	if !ok {
+		return nil
+	}
+
+	f.mu.Lock()
+	failRoll, timeoutRoll := f.rng.Float64(), f.rng.Float64()
+	f.mu.Unlock()
+
+	if fault.Delay > 0 {
+		f.sleep(time.Duration(fault.Delay) * time.Second)
+	}
+
+	switch {
+	case timeoutRoll < fault.TimeoutRate:
+		return &ExecutionResult{
+			Success:   false,
+			ExitCode:  -1,
+			TimedOut:  true,
+			Error:     fmt.Sprintf("command timed out after %d seconds (simulated by testing.faults)", task.Timeout),
+			ErrorCode: ErrCodeTimeout,
+			TaskName:  taskName,
+			Duration:  time.Since(startTime),
+			Timeout:   task.Timeout,
+		}
+	case failRoll < fault.FailRate:
+		exitCode := fault.ExitCode
+		if exitCode == 0 {
+			exitCode = 1
+		}
+		return &ExecutionResult{
+			Success:  false,
+			ExitCode: exitCode,
+			Error:    fmt.Sprintf("command exited with code %d (simulated by testing.faults)", exitCode),
+			TaskName: taskName,
+			Duration: time.Since(startTime),
+			Timeout:  task.Timeout,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func faultManifest(testing config.TestingConfig) *config.Manifest {
+	return &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build":      {Command: "echo built", Type: config.TaskTypeOneShot, Timeout: 30},
+			"deploy-api": {Command: "echo deployed", Type: config.TaskTypeOneShot, Timeout: 30},
+		},
+		Testing: testing,
+	}
+}
+
+func TestFaultsDisabledByDefault(t *testing.T) {
+	defer setupWorkflowTest(t)()
+	t.Setenv(TestingEnv, "")
+
+	manifest := faultManifest(config.TestingConfig{Faults: map[string]config.Fault{"build": {FailRate: 1}}})
+	result, err := NewExecutor(manifest).Execute("build", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("faults must not apply unless enabled, got %+v", result)
+	}
+
+	t.Setenv(TestingEnv, "1")
+	result, err = NewExecutor(manifest).Execute("build", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success {
+		t.Errorf("expected %s=1 to enable faults", TestingEnv)
+	}
+}
+
+func TestFaultKinds(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := faultManifest(config.TestingConfig{
+		Enabled: true,
+		Faults: map[string]config.Fault{
+			"build":    {FailRate: 1, ExitCode: 3, Delay: 2},
+			"deploy-*": {TimeoutRate: 1},
+		},
+	})
+	executor := NewExecutor(manifest)
+	var slept time.Duration
+	executor.faults.sleep = func(d time.Duration) { slept += d }
+
+	result, err := executor.Execute("build", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success || result.ExitCode != 3 || result.Stdout != "" {
+		t.Errorf("expected simulated failure with exit code 3, got %+v", result)
+	}
+	if slept != 2*time.Second {
+		t.Errorf("expected a 2s delay, slept %s", slept)
+	}
+
+	result, err = executor.Execute("deploy-api", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success || !result.TimedOut || result.ExitCode != -1 {
+		t.Errorf("expected simulated timeout, got %+v", result)
+	}
+}
+
+func TestFaultsSeedIsRepeatable(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	cfg := config.TestingConfig{Enabled: true, Seed: 42, Faults: map[string]config.Fault{"build": {FailRate: 0.5}}}
+	outcomes := func() []bool {
+		executor := NewExecutor(faultManifest(cfg))
+		var got []bool
+		for i := 0; i < 20; i++ {
+			result, err := executor.Execute("build", nil)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			got = append(got, result.Success)
+		}
+		return got
+	}
+
+	first, second := outcomes(), outcomes()
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("run %d differs between executors with the same seed", i)
+		}
+		if !first[i] {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(first) {
+		t.Errorf("expected a mix of failures with fail_rate 0.5, got %d of %d", failures, len(first))
+	}
+}
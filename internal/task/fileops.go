@@ -0,0 +1,282 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/template"
+)
+
+// runFileOps executes the operations of a file_ops task in-process, logging
+// one line per operation. It stops at the first failing operation. params
+// are recorded with the session; data is what templates render with.
+func (e *Executor) runFileOps(sessionID, taskName string, task config.Task, params, data map[string]interface{}, startTime time.Time) (result *ExecutionResult) {
+	if e.observer != nil {
+		defer func() { e.observer.ObserveTask(taskName, result.Success, result.Duration) }()
+	}
+
+	workingDir := resolveWorkingDirectory(task, params)
+	if workingDir == "" {
+		workingDir, _ = os.Getwd()
+	}
+	root, err := filepath.Abs(workingDir)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     fmt.Sprintf("failed to resolve working directory: %v", err),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+		}
+	}
+
+	metadata := &logs.SessionMetadata{
+		SessionID:  sessionID,
+		TaskName:   taskName,
+		TaskType:   string(config.TaskTypeFileOps),
+		StartTime:  startTime,
+		Parameters: params,
+		WorkingDir: root,
+	}
+	logWriter, err := logs.NewWriter(sessionID, metadata)
+	if err != nil {
+		return &ExecutionResult{
+			Success:   false,
+			TaskName:  taskName,
+			Error:     fmt.Sprintf("failed to create log writer: %v", err),
+			ErrorCode: ErrCodeIO,
+			Duration:  time.Since(startTime),
+			SessionID: sessionID,
+		}
+	}
+	defer logWriter.Close()
+
+	var out strings.Builder
+	var w io.Writer = &out
+	if e.stdout != nil {
+		w = io.MultiWriter(e.stdout, &out)
+	}
+	if redactor, err := logs.NewRedactor(task.Redact); err == nil && redactor != nil {
+		rw := logs.NewRedactingWriter(w, redactor)
+		defer rw.Close()
+		w = rw
+	}
+
+	errorMsg, errorCode := "", ""
+	for i, op := range task.Operations {
+		desc, err := applyFileOp(root, op, data)
+		if err != nil {
+			errorMsg = fmt.Sprintf("operation %d (%s) failed: %v", i, op.Op, err)
+			errorCode = errorCodeOr(err, ErrCodeIO)
+			fmt.Fprintln(w, errorMsg)
+			break
+		}
+		fmt.Fprintln(w, desc)
+	}
+
+	if _, err := logWriter.Write([]byte(out.String())); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write to log: %v\n", err)
+	}
+
+	success := errorMsg == ""
+	exitCode := 0
+	if !success {
+		exitCode = 1
+	}
+	logWriter.UpdateMetadata(map[string]interface{}{
+		"exit_code": exitCode,
+		"success":   success,
+		"timed_out": false,
+	})
+
+	return &ExecutionResult{
+		Success:   success,
+		ExitCode:  exitCode,
+		Stdout:    out.String(),
+		Duration:  time.Since(startTime),
+		Error:     errorMsg,
+		ErrorCode: errorCode,
+		TaskName:  taskName,
+		LogPath:   logWriter.GetLogPath(),
+		SessionID: sessionID,
+		Streamed:  e.stdout != nil,
+	}
+}
+
+// applyFileOp performs a single operation relative to root and returns a
+// one-line description of what was done.
+func applyFileOp(root string, op config.FileOp, params map[string]interface{}) (string, error) {
+	var mode os.FileMode
+	if op.Mode != "" {
+		m, err := strconv.ParseUint(op.Mode, 8, 32)
+		if err != nil {
+			return "", codedErrorf(ErrCodeConfig, "invalid mode '%s': %w", op.Mode, err)
+		}
+		mode = os.FileMode(m)
+	}
+
+	switch op.Op {
+	case config.FileOpCopy, config.FileOpTemplate:
+		src, err := resolveOpPath(root, op.Src, params)
+		if err != nil {
+			return "", err
+		}
+		dest, err := resolveOpPath(root, op.Dest, params)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return "", err
+		}
+		if op.Op == config.FileOpTemplate {
+			rendered, err := template.SubstituteParameters(string(data), params)
+			if err != nil {
+				return "", codedErrorf(ErrCodeTemplate, "failed to render %s: %w", op.Src, err)
+			}
+			data = []byte(rendered)
+		}
+		if mode == 0 {
+			info, err := os.Stat(src)
+			if err != nil {
+				return "", err
+			}
+			mode = info.Mode().Perm()
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(dest, data, mode); err != nil {
+			return "", err
+		}
+		if err := os.Chmod(dest, mode); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s -> %s", op.Op, relPath(root, src), relPath(root, dest)), nil
+
+	case config.FileOpMkdir:
+		path, err := resolveOpPath(root, op.Path, params)
+		if err != nil {
+			return "", err
+		}
+		if mode == 0 {
+			mode = 0755
+		}
+		if err := os.MkdirAll(path, mode); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("mkdir %s", relPath(root, path)), nil
+
+	case config.FileOpChmod:
+		path, err := resolveOpPath(root, op.Path, params)
+		if err != nil {
+			return "", err
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("chmod %s %s", op.Mode, relPath(root, path)), nil
+
+	case config.FileOpDelete:
+		path, err := resolveOpPath(root, op.Path, params)
+		if err != nil {
+			return "", err
+		}
+		if path == root {
+			return "", codedErrorf(ErrCodePermission, "refusing to delete the working directory")
+		}
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("delete %s (not present)", relPath(root, path)), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.IsDir() {
+			if !op.Recursive {
+				return "", codedErrorf(ErrCodeConfig, "%s is a directory (set recursive: true to delete it)", relPath(root, path))
+			}
+			if err := os.RemoveAll(path); err != nil {
+				return "", err
+			}
+		} else if err := os.Remove(path); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("delete %s", relPath(root, path)), nil
+	}
+
+	return "", codedErrorf(ErrCodeConfig, "unknown operation '%s'", op.Op)
+}
+
+// resolveOpPath substitutes parameters into p and resolves it against root.
+// The path must stay inside root, after following any symlinks in its
+// existing parent directories and, when it exists, in the path itself, so
+// a source cannot read and a destination cannot write outside the tree.
+func resolveOpPath(root string, p string, params map[string]interface{}) (string, error) {
+	p, err := template.SubstituteParameters(p, params)
+	if err != nil {
+		return "", &CodedError{Code: ErrCodeTemplate, Err: err}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	p = filepath.Clean(p)
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	realParent, err := evalExistingPrefix(filepath.Dir(p))
+	if err != nil {
+		return "", err
+	}
+	if !withinDir(realRoot, filepath.Join(realParent, filepath.Base(p))) || !withinDir(root, p) {
+		return "", codedErrorf(ErrCodePermission, "path %s is outside the working directory", p)
+	}
+	if real, err := filepath.EvalSymlinks(p); err == nil && !withinDir(realRoot, real) {
+		return "", codedErrorf(ErrCodePermission, "path %s is outside the working directory", p)
+	}
+	return p, nil
+}
+
+// evalExistingPrefix resolves symlinks in the longest existing prefix of dir
+// and appends the remaining, not yet created, elements.
+func evalExistingPrefix(dir string) (string, error) {
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+		dir = parent
+	}
+}
+
+// withinDir reports whether path is dir or inside it.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// relPath returns path relative to root for log output.
+func relPath(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
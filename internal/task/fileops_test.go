@@ -0,0 +1,151 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestFileOpsTask(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+
+	work := t.TempDir()
+	if err := os.WriteFile(filepath.Join(work, "app.tmpl"), []byte("env: {{.env}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(work, "run.sh"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(work, "stale", "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"setup": {
+				Description:      "Setup",
+				Type:             config.TaskTypeFileOps,
+				WorkingDirectory: work,
+				Parameters:       map[string]config.Param{"env": {Type: "string", Required: true}},
+				Operations: []config.FileOp{
+					{Op: config.FileOpMkdir, Path: "out/{{.env}}"},
+					{Op: config.FileOpTemplate, Src: "app.tmpl", Dest: "out/{{.env}}/app.yaml"},
+					{Op: config.FileOpCopy, Src: "run.sh", Dest: "out/run.sh", Mode: "0755"},
+					{Op: config.FileOpChmod, Path: "run.sh", Mode: "0700"},
+					{Op: config.FileOpDelete, Path: "stale", Recursive: true},
+					{Op: config.FileOpDelete, Path: "missing.txt"},
+				},
+			},
+		},
+	}
+
+	result, err := NewExecutor(manifest).Execute("setup", map[string]interface{}{"env": "dev"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error %q, output:\n%s", result.Error, result.Stdout)
+	}
+	if result.SessionID == "" || result.LogPath == "" {
+		t.Error("expected a session with a log")
+	}
+
+	data, err := os.ReadFile(filepath.Join(work, "out", "dev", "app.yaml"))
+	if err != nil || string(data) != "env: dev\n" {
+		t.Errorf("template output = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(work, "out", "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("copied file mode = %v, %v", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(work, "run.sh")); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("chmod mode = %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(work, "stale")); !os.IsNotExist(err) {
+		t.Errorf("expected stale to be deleted, stat err = %v", err)
+	}
+	if !strings.Contains(result.Stdout, "template app.tmpl -> out/dev/app.yaml") {
+		t.Errorf("expected operation log in stdout, got:\n%s", result.Stdout)
+	}
+}
+
+func TestFileOpsSafety(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+
+	work := t.TempDir()
+	outside := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(work, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(work, "link")); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(outside, "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(work, "secret-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		op       config.FileOp
+		errorMsg string
+	}{
+		{"delete outside", config.FileOp{Op: config.FileOpDelete, Path: "../x"}, "outside the working directory"},
+		{"write through symlink", config.FileOp{Op: config.FileOpMkdir, Path: "link/escape"}, "outside the working directory"},
+		{"copy from outside", config.FileOp{Op: config.FileOpCopy, Src: secret, Dest: "copied"}, "outside the working directory"},
+		{"template through symlink", config.FileOp{Op: config.FileOpTemplate, Src: "secret-link", Dest: "copied"}, "outside the working directory"},
+		{"delete working directory", config.FileOp{Op: config.FileOpDelete, Path: "."}, "refusing to delete the working directory"},
+		{"delete directory without recursive", config.FileOp{Op: config.FileOpDelete, Path: "dir"}, "set recursive: true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := &config.Manifest{
+				Version: "1.0",
+				Tasks: map[string]config.Task{
+					"ops": {Description: "Ops", Type: config.TaskTypeFileOps, WorkingDirectory: work, Operations: []config.FileOp{tt.op}},
+				},
+			}
+			result, err := NewExecutor(manifest).Execute("ops", nil)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Success {
+				t.Fatal("expected failure")
+			}
+			if !strings.Contains(result.Error, tt.errorMsg) {
+				t.Errorf("error = %q, want it to contain %q", result.Error, tt.errorMsg)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(work, "dir")); err != nil {
+		t.Errorf("expected dir to survive: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(work, "copied")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be copied from outside, stat error = %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package task
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// fingerprintWorkdir returns the git code state of the repository containing
+// dir, or nil if dir is not in a git repository with at least one commit.
+// The dirty hash covers tracked changes against HEAD and the contents of
+// untracked, non-ignored files, so equal hashes mean the same code.
+func fingerprintWorkdir(dir string) *logs.WorkdirFingerprint {
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	root := strings.TrimSpace(string(top))
+	commit, err := gitOutput(root, "rev-parse", "HEAD")
+	if err != nil {
+		return nil
+	}
+	fp := &logs.WorkdirFingerprint{Commit: strings.TrimSpace(string(commit))}
+
+	status, err := gitOutput(root, "status", "--porcelain", "--untracked-files=all")
+	if err != nil || len(bytes.TrimSpace(status)) == 0 {
+		return fp
+	}
+	fp.Dirty = true
+
+	h := sha256.New()
+	if diff, err := gitOutput(root, "diff", "HEAD", "--binary"); err == nil {
+		h.Write(diff)
+	}
+	if untracked, err := gitOutput(root, "ls-files", "--others", "--exclude-standard", "-z"); err == nil {
+		for _, name := range strings.Split(string(untracked), "\x00") {
+			if name == "" {
+				continue
+			}
+			h.Write([]byte("\x00" + name + "\x00"))
+			if f, err := os.Open(filepath.Join(root, name)); err == nil {
+				_, _ = io.Copy(h, f)
+				f.Close()
+			}
+		}
+	}
+	fp.DirtyHash = hex.EncodeToString(h.Sum(nil))
+	return fp
+}
+
+// gitOutput runs a git command in dir and returns its stdout.
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestWorkdirFingerprint(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git unavailable: %v: %s", err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	head := git("rev-parse", "HEAD")
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test":   {Description: "Test", Command: "true", Type: config.TaskTypeOneShot, ExposeWorkingDirectory: true},
+			"static": {Description: "Static", Command: "true", Type: config.TaskTypeOneShot, WorkingDirectory: repo},
+		},
+	}
+	executor := NewExecutor(manifest)
+	run := func() *logs.WorkdirFingerprint {
+		t.Helper()
+		result, err := executor.Execute("test", map[string]interface{}{"working_directory": repo})
+		if err != nil || !result.Success {
+			t.Fatalf("Execute failed: %v %+v", err, result)
+		}
+		metadata, err := logs.ReadSessionMetadata(result.SessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if metadata.Workdir == nil || *metadata.Workdir != *result.Workdir {
+			t.Errorf("session metadata fingerprint %+v does not match result %+v", metadata.Workdir, result.Workdir)
+		}
+		return result.Workdir
+	}
+
+	clean := run()
+	if clean == nil || clean.Commit != head || clean.Dirty || clean.DirtyHash != "" {
+		t.Fatalf("unexpected clean fingerprint: %+v", clean)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := run()
+	if !modified.Dirty || modified.DirtyHash == "" {
+		t.Fatalf("expected dirty fingerprint, got %+v", modified)
+	}
+	if again := run(); again.DirtyHash != modified.DirtyHash {
+		t.Error("expected the same dirty hash for the same changes")
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "new.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if untracked := run(); untracked.DirtyHash == modified.DirtyHash {
+		t.Error("expected an untracked file to change the dirty hash")
+	}
+
+	result, _ := executor.Execute("static", map[string]interface{}{})
+	if result.Workdir != nil {
+		t.Errorf("expected no fingerprint for a static working directory, got %+v", result.Workdir)
+	}
+	if fingerprintWorkdir(t.TempDir()) != nil {
+		t.Error("expected no fingerprint outside a git repository")
+	}
+}
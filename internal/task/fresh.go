@@ -0,0 +1,146 @@
+package task
+
+import (
+	"fmt"
+	"os"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// StartDaemonFresh restarts a daemon from a clean slate as a single
+// operation: it stops the daemon if it is running, clears the previous
+// session's latest log link, starts it, and waits for its ready check. If
+// the new instance never becomes ready it is stopped again, so a failed
+// fresh start never leaves a half-restarted daemon behind.
+func (m *Manager) StartDaemonFresh(taskName string, params map[string]interface{}) (*DaemonStartResult, error) {
+	task, exists := m.manifest.Tasks[taskName]
+	if !exists {
+		return &DaemonStartResult{
+			Success:   false,
+			Error:     fmt.Sprintf("task '%s' not found", taskName),
+			ErrorCode: ErrCodeNotFound,
+		}, nil
+	}
+	if !task.Type.IsDaemon() {
+		return &DaemonStartResult{
+			Success:   false,
+			Error:     fmt.Sprintf("task '%s' is not a daemon", taskName),
+			ErrorCode: ErrCodeConfig,
+		}, nil
+	}
+
+	if result := m.checkStartRateLimit(taskName, task); result != nil {
+		return result, nil
+	}
+
+	// Hold the daemon's lock throughout so no other caller observes it
+	// between the stop and the start.
+	defer m.lockDaemon(taskName)()
+
+	for _, dep := range task.RequiresDaemon {
+		if _, err := m.ensureDaemon(dep, map[string]bool{taskName: true}); err != nil {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     err.Error(),
+				ErrorCode: ErrorCode(err),
+			}, nil
+		}
+	}
+
+	return m.freshDaemon(taskName, task, params)
+}
+
+// FreshDaemons restarts every named daemon from a clean slate, as
+// StartDaemonFresh does, starting each one's own required daemons first if
+// they are not running. It returns the names of the daemons it started.
+func (m *Manager) FreshDaemons(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var started []string
+	for _, name := range names {
+		result, err := m.freshRequiredDaemon(name)
+		if err != nil {
+			return started, err
+		}
+		if !result.Success {
+			return started, fmt.Errorf("failed to fresh-start required daemon '%s': %s", name, result.Error)
+		}
+		started = append(started, name)
+	}
+	return started, nil
+}
+
+// freshRequiredDaemon fresh-starts one daemon for FreshDaemons, holding its
+// lock, after starting its own required daemons if they are not running.
+func (m *Manager) freshRequiredDaemon(name string) (*DaemonStartResult, error) {
+	daemon, exists := m.manifest.Tasks[name]
+	if !exists {
+		return nil, fmt.Errorf("required daemon '%s' not found", name)
+	}
+	if !daemon.Type.IsDaemon() {
+		return nil, fmt.Errorf("required task '%s' is not a daemon", name)
+	}
+	if m.processManager == nil {
+		return nil, fmt.Errorf("cannot start required daemon '%s': no process manager", name)
+	}
+
+	defer m.lockDaemon(name)()
+	for _, dep := range daemon.RequiresDaemon {
+		if _, err := m.ensureDaemon(dep, map[string]bool{name: true}); err != nil {
+			return nil, err
+		}
+	}
+	return m.freshDaemon(name, daemon, nil)
+}
+
+// freshDaemon stops, cleans up, starts and waits on a single validated
+// daemon. The caller must hold the daemon's lock.
+func (m *Manager) freshDaemon(taskName string, task config.Task, params map[string]interface{}) (*DaemonStartResult, error) {
+	running, _, err := m.processManager.Status(taskName)
+	if err != nil {
+		return &DaemonStartResult{
+			Success:   false,
+			Error:     fmt.Sprintf("failed to check status: %v", err),
+			ErrorCode: ErrCodeIO,
+		}, nil
+	}
+	if running || task.Type == config.TaskTypeCompose {
+		stopped, err := m.StopDaemon(taskName)
+		if err != nil {
+			return nil, err
+		}
+		if !stopped.Success && running {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     stopped.Error,
+				ErrorCode: stopped.ErrorCode,
+			}, nil
+		}
+	}
+
+	// Drop the previous session's link so readers never mistake its logs
+	// for the new instance's if the start fails.
+	if err := os.Remove(logs.GetLatestSymlinkPath(taskName)); err != nil && !os.IsNotExist(err) {
+		return &DaemonStartResult{
+			Success:   false,
+			Error:     fmt.Sprintf("failed to clear latest session link: %v", err),
+			ErrorCode: ErrCodeIO,
+		}, nil
+	}
+
+	result, err := m.startDaemon(taskName, task, params)
+	if err != nil || !result.Success {
+		return result, err
+	}
+	result.Restarted = running
+
+	if err := m.waitReady(taskName, task.Ready, result.SessionID); err != nil {
+		_, _ = m.StopDaemon(taskName)
+		result.Success = false
+		result.Error = err.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package task
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestStartDaemonFresh(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	t.Run("not running", func(t *testing.T) {
+		manager := NewManager(readinessManifest(nil), NewMockProcessManager())
+		result, err := manager.StartDaemonFresh("dev", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Success || result.Restarted {
+			t.Errorf("expected a plain start, got %+v", result)
+		}
+	})
+
+	t.Run("running", func(t *testing.T) {
+		pm := NewMockProcessManager()
+		manager := NewManager(readinessManifest(nil), pm)
+		first, err := manager.StartDaemon("dev", nil)
+		if err != nil || !first.Success {
+			t.Fatalf("failed to start daemon: %v %+v", err, first)
+		}
+		if err := logs.CreateLatestLink("dev", first.SessionID); err != nil {
+			t.Fatalf("failed to link session: %v", err)
+		}
+
+		result, err := manager.StartDaemonFresh("dev", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Success || !result.Restarted {
+			t.Fatalf("expected a restart, got %+v", result)
+		}
+		if result.SessionID == first.SessionID {
+			t.Error("expected a new session")
+		}
+		if _, err := os.Lstat(logs.GetLatestSymlinkPath("dev")); !os.IsNotExist(err) {
+			t.Errorf("expected the previous latest link to be cleared, got %v", err)
+		}
+		if running, _, _ := pm.Status("dev"); !running {
+			t.Error("expected dev daemon to be running")
+		}
+	})
+
+	t.Run("never ready", func(t *testing.T) {
+		pm := NewMockProcessManager()
+		manager := NewManager(readinessManifest(&config.ReadyCheck{Command: "false", Timeout: 1}), pm)
+		result, err := manager.StartDaemonFresh("dev", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Success {
+			t.Fatal("expected failure when daemon never becomes ready")
+		}
+		if !strings.Contains(result.Error, "not ready") {
+			t.Errorf("expected not ready error, got: %s", result.Error)
+		}
+		if running, _, _ := pm.Status("dev"); running {
+			t.Error("expected the unready daemon to be stopped")
+		}
+	})
+
+	t.Run("oneshot", func(t *testing.T) {
+		manager := NewManager(readinessManifest(nil), NewMockProcessManager())
+		result, err := manager.StartDaemonFresh("unit", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Success || !strings.Contains(result.Error, "not a daemon") {
+			t.Errorf("expected not a daemon error, got %+v", result)
+		}
+	})
+}
+
+func TestWorkflowStepFresh(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := readinessManifest(nil)
+	manifest.Workflows["ci"] = config.Workflow{
+		Description: "CI",
+		Steps: []config.WorkflowStep{
+			{Task: "unit", RequiresDaemon: []string{"dev"}, Fresh: true},
+		},
+	}
+	pm := NewMockProcessManager()
+	manager := NewManager(manifest, pm)
+	first, err := manager.StartDaemon("dev", nil)
+	if err != nil || !first.Success {
+		t.Fatalf("failed to start daemon: %v %+v", err, first)
+	}
+
+	result, err := manager.ExecuteWorkflow("ci", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error: %s", result.Error)
+	}
+	if got := result.Steps[0].Result.DaemonsStarted; len(got) != 1 || got[0] != "dev" {
+		t.Errorf("expected step to restart dev, got %v", got)
+	}
+	if sessionID, _ := pm.GetSessionID("dev"); sessionID == first.SessionID {
+		t.Error("expected dev to run in a new session")
+	}
+}
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"fmt"
+	"os"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// stageInputs writes the value of every parameter of task with a source to
+// a temp file, replacing it in params with the file's path under
+// <name>_path, so large or awkward values never reach the shell string. It
+// returns a function that removes the files.
+func stageInputs(task config.Task, params map[string]interface{}) (func(), error) {
+	var paths []string
+	cleanup := func() {
+		for _, path := range paths {
+			_ = os.Remove(path)
+		}
+	}
+
+	for name, param := range task.Parameters {
+		if param.Source == "" {
+			continue
+		}
+		value, given := params[name]
+		if !given {
+			continue
+		}
+		file, err := os.CreateTemp("", "runbook-input-*")
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to stage parameter '%s': %w", name, err)
+		}
+		paths = append(paths, file.Name())
+		_, err = fmt.Fprint(file, value)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to stage parameter '%s': %w", name, err)
+		}
+		delete(params, name)
+		params[config.SourcePathParam(name)] = file.Name()
+	}
+	return cleanup, nil
+}
+
+// stdinInput opens the staged file of task's stdin parameter, if it has one
+// and it was given.
+func stdinInput(task config.Task, params map[string]interface{}) (*os.File, error) {
+	for name, param := range task.Parameters {
+		if param.Source != config.ParamSourceStdin {
+			continue
+		}
+		path, ok := params[config.SourcePathParam(name)].(string)
+		if !ok {
+			return nil, nil
+		}
+		return os.Open(path)
+	}
+	return nil, nil
+}
@@ -0,0 +1,61 @@
+package task
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestExecuteParamSources(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"apply": {
+				Command: "cat {{.patch_path}} && tr a-z A-Z && echo {{.patch_path}}",
+				Type:    config.TaskTypeOneShot,
+				Timeout: 30,
+				Parameters: map[string]config.Param{
+					"patch": {Type: "string", Description: "Patch", Source: config.ParamSourceFile},
+					"sql":   {Type: "string", Description: "SQL", Source: config.ParamSourceStdin},
+				},
+			},
+			"raw": {
+				Command: "echo {{.patch}}",
+				Type:    config.TaskTypeOneShot,
+				Timeout: 30,
+				Parameters: map[string]config.Param{
+					"patch": {Type: "string", Description: "Patch", Source: config.ParamSourceFile},
+				},
+			},
+		},
+	}
+	executor := NewExecutor(manifest)
+
+	patch := "--- a\n+++ b\n$(rm -rf /)\n"
+	result, err := executor.Execute("apply", map[string]interface{}{"patch": patch, "sql": "select 1;\n"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got %s: %s", result.Error, result.Stderr)
+	}
+	if !strings.HasPrefix(result.Stdout, patch+"SELECT 1;\n") {
+		t.Errorf("expected the patch file then upper-cased stdin, got %q", result.Stdout)
+	}
+	path := strings.TrimSpace(strings.TrimPrefix(result.Stdout, patch+"SELECT 1;\n"))
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected temp file %q to be removed after the run, got %v", path, err)
+	}
+
+	result, err = executor.Execute("raw", map[string]interface{}{"patch": patch})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "parameter substitution failed") {
+		t.Errorf("expected a file parameter to be unavailable to the command, got %+v", result)
+	}
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// limitStdout cuts the stdout of a run of a task with log_output truncated
+// or none down to what the task keeps. When anything was cut, the result
+// records how many lines stdout had and the session log that still holds
+// all of it.
+func limitStdout(task config.Task, result *ExecutionResult) {
+	if result.Stdout == "" || result.SessionID == "" {
+		return
+	}
+	kept := ""
+	switch task.LogOutput {
+	case config.LogOutputNone:
+	case config.LogOutputTruncated:
+		maxLines, maxBytes := task.LogOutputLimits()
+		kept = tailOutput(result.Stdout, maxLines, maxBytes)
+	default:
+		return
+	}
+	if kept == result.Stdout {
+		return
+	}
+	result.StdoutTotalLines = countLines(result.Stdout)
+	result.Stdout = kept
+	result.StdoutLog = logs.GetSessionStreamPath(result.SessionID, logs.StreamStdout)
+}
+
+// tailOutput returns the last maxLines lines of s that fit in maxBytes.
+// A last line longer than maxBytes is cut to its end.
+func tailOutput(s string, maxLines int, maxBytes int64) string {
+	trimmed := strings.TrimSuffix(s, "\n")
+	// cut ends up at the newline before the first line kept, or -1
+	cut := len(trimmed)
+	for n := 0; n < maxLines && cut >= 0; n++ {
+		cut = strings.LastIndexByte(trimmed[:cut], '\n')
+	}
+	start := cut + 1
+	if int64(len(s)-start) > maxBytes {
+		start = len(s) - int(maxBytes)
+		if i := strings.IndexByte(s[start:], '\n'); i >= 0 && i < len(s)-start-1 {
+			start += i + 1
+		}
+		for start < len(s) && !utf8.RuneStart(s[start]) {
+			start++
+		}
+	}
+	return s[start:]
+}
+
+// countLines returns the number of lines in s. A trailing newline does not
+// start another line.
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(s, "\n"), "\n") + 1
+}
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestTailOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxLines int
+		maxBytes int64
+		want     string
+	}{
+		{name: "within caps", input: "a\nb\n", maxLines: 5, maxBytes: 100, want: "a\nb\n"},
+		{name: "last lines", input: "a\nb\nc\n", maxLines: 2, maxBytes: 100, want: "b\nc\n"},
+		{name: "no trailing newline", input: "a\nb\nc", maxLines: 1, maxBytes: 100, want: "c"},
+		{name: "bytes cut at a line start", input: "aaaa\nbb\ncc\n", maxLines: 10, maxBytes: 5, want: "cc\n"},
+		{name: "long last line", input: "a\nbbbbbbbb\n", maxLines: 10, maxBytes: 4, want: "bbb\n"},
+		{name: "multibyte rune not split", input: "x\néé\n", maxLines: 10, maxBytes: 4, want: "é\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tailOutput(tt.input, tt.maxLines, tt.maxBytes); got != tt.want {
+				t.Errorf("tailOutput(%q, %d, %d) = %q, want %q", tt.input, tt.maxLines, tt.maxBytes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutorLogOutput(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"noisy": {Description: "Noisy", Command: "seq 1 50", Type: config.TaskTypeOneShot, LogOutput: config.LogOutputTruncated, LogOutputMaxLines: 3},
+			"quiet": {Description: "Quiet", Command: "seq 1 50", Type: config.TaskTypeOneShot, LogOutput: config.LogOutputNone},
+			"full":  {Description: "Full", Command: "seq 1 50", Type: config.TaskTypeOneShot},
+		},
+	}
+	executor := NewExecutor(manifest)
+	run := func(name string) *ExecutionResult {
+		t.Helper()
+		result, err := executor.Execute(name, nil)
+		if err != nil || !result.Success {
+			t.Fatalf("Execute(%s) = %+v, %v", name, result, err)
+		}
+		return result
+	}
+
+	noisy := run("noisy")
+	if noisy.Stdout != "48\n49\n50\n" || noisy.StdoutTotalLines != 50 {
+		t.Errorf("truncated stdout = %q of %d lines, want the last 3 of 50", noisy.Stdout, noisy.StdoutTotalLines)
+	}
+	data, err := os.ReadFile(noisy.StdoutLog)
+	if err != nil || strings.Count(string(data), "\n") != 50 {
+		t.Errorf("stdout log %q should have all 50 lines: %v", noisy.StdoutLog, err)
+	}
+
+	if quiet := run("quiet"); quiet.Stdout != "" || quiet.StdoutTotalLines != 50 || quiet.StdoutLog == "" {
+		t.Errorf("log_output none result = %+v, want no stdout and a pointer to the log", quiet)
+	}
+	if full := run("full"); full.StdoutLog != "" || strings.Count(full.Stdout, "\n") != 50 {
+		t.Errorf("log_output full result = %+v, want all of stdout", full)
+	}
+}
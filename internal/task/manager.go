@@ -1,8 +1,12 @@
 package task
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"sync"
+	"time"
 
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/logs"
@@ -19,6 +23,63 @@ type ProcessManager interface {
 	StopAll() error
 }
 
+// InteractiveProcessManager is implemented by process managers that can
+// start daemons with input and forward input to them.
+type InteractiveProcessManager interface {
+	StartInteractive(taskName string, sessionID string, cmd string, env map[string]string, cwd string, logPath string, shell string) error
+	SendInput(taskName string, input string) error
+}
+
+// RedactingProcessManager is implemented by process managers that can mask
+// patterns in a daemon's output before it reaches its log.
+type RedactingProcessManager interface {
+	SetRedactor(taskName string, redactor *logs.Redactor)
+}
+
+// LogRotatingProcessManager is implemented by process managers that can
+// rotate a daemon's session log by size.
+type LogRotatingProcessManager interface {
+	SetLogRotation(taskName string, maxSize int64, maxFiles int)
+}
+
+// GracefulProcessManager is implemented by process managers whose SIGTERM
+// grace period on stop can be configured.
+type GracefulProcessManager interface {
+	SetStopGrace(grace time.Duration)
+}
+
+// PortProcessManager is implemented by process managers that can check a
+// daemon's ports are free before starting it and report the ports a running
+// daemon owns.
+type PortProcessManager interface {
+	SetPorts(taskName string, ports []int)
+	Ports(taskName string) []int
+}
+
+// StopSignalProcessManager is implemented by process managers that can stop
+// a daemon with a signal other than SIGTERM and its own grace period.
+type StopSignalProcessManager interface {
+	SetStopSignal(taskName string, signal string, grace time.Duration)
+}
+
+// UsageProcessManager is implemented by process managers that can report
+// the live resource usage of a daemon's process group.
+type UsageProcessManager interface {
+	Usage(taskName string) (*logs.GroupUsage, error)
+}
+
+// EnvProcessManager is implemented by process managers that can start a
+// daemon with a filtered host environment instead of the whole of it.
+type EnvProcessManager interface {
+	SetInheritedEnv(taskName string, environ []string)
+}
+
+// Observer receives task execution and daemon start events, e.g. for metrics.
+type Observer interface {
+	ObserveTask(taskName string, success bool, duration time.Duration)
+	ObserveDaemonStart(taskName string)
+}
+
 // Manager coordinates task execution
 type Manager struct {
 	executor         *Executor
@@ -26,18 +87,47 @@ type Manager struct {
 	workflowExecutor *WorkflowExecutor
 	processManager   ProcessManager
 	manifest         *config.Manifest
+	daemonLocksMu    sync.Mutex
+	daemonLocks      map[string]*sync.Mutex // per daemon, serializes its automatic starts
+	observer         Observer
+	rateLimiter      *RateLimiter
+	background       *BackgroundRuns
 }
 
 // NewManager creates a new task manager
 func NewManager(manifest *config.Manifest, processManager ProcessManager) *Manager {
+	m := NewInspectionManager(manifest, processManager)
+	logs.SetSessionSink(newSessionSink(manifest.Defaults.SessionSink))
+	return m
+}
+
+// NewInspectionManager creates a task manager for resolving tasks without
+// running them. Unlike NewManager it leaves the process-wide session sink
+// alone.
+func NewInspectionManager(manifest *config.Manifest, processManager ProcessManager) *Manager {
 	executor := NewExecutor(manifest)
-	return &Manager{
+	m := &Manager{
 		executor:         executor,
 		dedupExecutor:    NewDedupExecutor(executor),
 		workflowExecutor: NewWorkflowExecutor(executor, manifest),
 		processManager:   processManager,
 		manifest:         manifest,
+		rateLimiter:      NewRateLimiter(),
+		background:       NewBackgroundRuns(),
+	}
+	m.workflowExecutor.ensureDaemons = func(names []string, fresh bool) ([]string, error) {
+		if fresh {
+			return m.FreshDaemons(names)
+		}
+		return m.EnsureDaemons(names)
+	}
+	if gpm, ok := processManager.(GracefulProcessManager); ok {
+		gpm.SetStopGrace(time.Duration(manifest.Defaults.StopGrace) * time.Second)
+	}
+	if cpm, ok := processManager.(CrashReportingProcessManager); ok {
+		cpm.SetCrashHandler(m.reportCrash)
 	}
+	return m
 }
 
 // SetStreaming configures the executor to stream stdout/stderr to the given
@@ -49,11 +139,91 @@ func (m *Manager) SetStreaming(stdout, stderr io.Writer) {
 	m.executor.stderr = stderr
 }
 
+// SetObserver reports every task execution and daemon start to o.
+func (m *Manager) SetObserver(o Observer) {
+	m.observer = o
+	m.executor.observer = o
+}
+
+// SetRunQueue makes every oneshot task run, including workflow steps, wait
+// for a slot in q.
+func (m *Manager) SetRunQueue(q *RunQueue) {
+	m.executor.queue = q
+}
+
+// SetAgentPool makes tasks whose runner names an agent tag run on the
+// agents registered in p.
+func (m *Manager) SetAgentPool(p *AgentPool) {
+	m.executor.agents = p
+}
+
+// SetRateLimiter makes the manager count runs against task rate limits in
+// l, so a reloaded manager keeps the runs counted by the one it replaces.
+func (m *Manager) SetRateLimiter(l *RateLimiter) {
+	m.rateLimiter = l
+}
+
+// RateLimiter returns the runs counted against task rate limits.
+func (m *Manager) RateLimiter() *RateLimiter {
+	return m.rateLimiter
+}
+
 // ExecuteOneShot executes a one-shot task with deduplication.
 // If the same task+params is already running, callers wait for
 // the existing execution and receive the same result.
+//
+// Daemons listed in the task's requires_daemon are started (if needed) and
+// waited on until ready before the task runs.
 func (m *Manager) ExecuteOneShot(taskName string, params map[string]interface{}) (*ExecutionResult, error) {
-	return m.dedupExecutor.Execute(taskName, params)
+	return m.executeOneShot(taskName, params, "")
+}
+
+// executeOneShot runs a one-shot task like ExecuteOneShot. A sessionID other
+// than "" runs it as that session without deduplication, since a shared
+// result would belong to another session.
+func (m *Manager) executeOneShot(taskName string, params map[string]interface{}, sessionID string) (*ExecutionResult, error) {
+	if task, exists := m.manifest.Tasks[taskName]; exists && !task.Type.IsDaemon() {
+		if err := m.rateLimiter.allow(taskName, task); err != nil {
+			return &ExecutionResult{
+				Success:    false,
+				Status:     StatusFailure,
+				TaskName:   taskName,
+				Error:      err.Error(),
+				ErrorCode:  ErrCodeRateLimited,
+				RetryAfter: retryAfterSeconds(err),
+			}, nil
+		}
+	}
+
+	var started []string
+	if task, exists := m.manifest.Tasks[taskName]; exists && len(task.RequiresDaemon) > 0 {
+		var err error
+		started, err = m.EnsureDaemons(task.RequiresDaemon)
+		if err != nil {
+			return &ExecutionResult{
+				Success:        false,
+				TaskName:       taskName,
+				Error:          err.Error(),
+				ErrorCode:      ErrorCode(err),
+				DaemonsStarted: started,
+			}, nil
+		}
+	}
+
+	var result *ExecutionResult
+	var err error
+	if sessionID != "" {
+		result, err = m.executor.executeSession(context.Background(), sessionID, taskName, params, 0)
+	} else {
+		result, err = m.dedupExecutor.Execute(taskName, params)
+	}
+	if result != nil && len(started) > 0 {
+		// Copy so callers sharing a deduplicated result are not affected
+		withStarted := *result
+		withStarted.DaemonsStarted = started
+		result = &withStarted
+	}
+	return result, err
 }
 
 // ExecuteWorkflow runs a composite workflow by name with the given parameters.
@@ -62,47 +232,116 @@ func (m *Manager) ExecuteWorkflow(workflowName string, params map[string]interfa
 	return m.workflowExecutor.Execute(workflowName, params)
 }
 
+// ExecuteCommand runs an ad-hoc shell command under the same session logging,
+// timeout, and working directory machinery as tasks. A timeout of 0 falls back
+// to the exec and manifest defaults.
+func (m *Manager) ExecuteCommand(command string, workingDir string, timeout int) (*ExecutionResult, error) {
+	if command == "" {
+		return nil, codedErrorf(ErrCodeConfig, "command is required")
+	}
+	return m.executor.ExecuteAdHoc(command, workingDir, timeout), nil
+}
+
 // StartDaemon starts a daemon task
 func (m *Manager) StartDaemon(taskName string, params map[string]interface{}) (*DaemonStartResult, error) {
 	// Get task definition
 	task, exists := m.manifest.Tasks[taskName]
 	if !exists {
 		return &DaemonStartResult{
-			Success: false,
-			Error:   fmt.Sprintf("task '%s' not found", taskName),
+			Success:   false,
+			Error:     fmt.Sprintf("task '%s' not found", taskName),
+			ErrorCode: ErrCodeNotFound,
 		}, nil
 	}
 
 	// Verify task type
-	if task.Type != config.TaskTypeDaemon {
+	if !task.Type.IsDaemon() {
 		return &DaemonStartResult{
-			Success: false,
-			Error:   fmt.Sprintf("task '%s' is not a daemon", taskName),
+			Success:   false,
+			Error:     fmt.Sprintf("task '%s' is not a daemon", taskName),
+			ErrorCode: ErrCodeConfig,
 		}, nil
 	}
 
+	if result := m.checkStartRateLimit(taskName, task); result != nil {
+		return result, nil
+	}
+
+	// Start any daemons this one requires before starting it
+	if len(task.RequiresDaemon) > 0 {
+		if _, err := m.EnsureDaemons(task.RequiresDaemon); err != nil {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     err.Error(),
+				ErrorCode: ErrorCode(err),
+			}, nil
+		}
+	}
+
+	return m.startDaemon(taskName, task, params)
+}
+
+// lockDaemon locks the automatic starts of the named daemon and returns the
+// function that unlocks them. A daemon's lock is taken before those of the
+// daemons it requires, which cannot form a cycle.
+func (m *Manager) lockDaemon(name string) func() {
+	m.daemonLocksMu.Lock()
+	if m.daemonLocks == nil {
+		m.daemonLocks = make(map[string]*sync.Mutex)
+	}
+	mu, ok := m.daemonLocks[name]
+	if !ok {
+		mu = &sync.Mutex{}
+		m.daemonLocks[name] = mu
+	}
+	m.daemonLocksMu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// startDaemon starts an already-validated daemon task definition.
+func (m *Manager) startDaemon(taskName string, task config.Task, params map[string]interface{}) (*DaemonStartResult, error) {
 	// Check if already running
 	running, _, err := m.processManager.Status(taskName)
 	if err != nil {
 		return &DaemonStartResult{
-			Success: false,
-			Error:   fmt.Sprintf("failed to check status: %v", err),
+			Success:   false,
+			Error:     fmt.Sprintf("failed to check status: %v", err),
+			ErrorCode: ErrCodeIO,
 		}, nil
 	}
 	if running {
 		return &DaemonStartResult{
-			Success: false,
-			Error:   fmt.Sprintf("daemon '%s' is already running", taskName),
+			Success:   false,
+			Error:     fmt.Sprintf("daemon '%s' is already running", taskName),
+			ErrorCode: ErrCodeAlreadyRunning,
 		}, nil
 	}
 
 	params = m.applyDefaults(task, params)
 
-	command, err := template.SubstituteParameters(task.Command, params)
+	if err := checkParams(taskName, task, params); err != nil {
+		return &DaemonStartResult{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: errorCodeOr(err, ErrCodeConfig),
+		}, nil
+	}
+
+	vars, err := template.ResolveVars(m.manifest.Vars)
 	if err != nil {
 		return &DaemonStartResult{
-			Success: false,
-			Error:   fmt.Sprintf("failed to substitute parameters: %v", err),
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: ErrCodeTemplate,
+		}, nil
+	}
+	command, err := template.SubstituteParameters(task.Command, template.CommandData(params, vars))
+	if err != nil {
+		return &DaemonStartResult{
+			Success:   false,
+			Error:     fmt.Sprintf("failed to substitute parameters: %v", err),
+			ErrorCode: ErrCodeTemplate,
 		}, nil
 	}
 
@@ -111,10 +350,94 @@ func (m *Manager) StartDaemon(taskName string, params map[string]interface{}) (*
 	logPath := logs.GetSessionLogPath(sessionID)
 
 	workingDir := resolveWorkingDirectory(task, params)
-	if err := m.processManager.Start(taskName, sessionID, command, task.Env, workingDir, logPath, task.Shell); err != nil {
+	if task.Type == config.TaskTypeCompose {
+		// Bring the stack up, then follow its logs as the daemon process
+		if _, err := runCompose(task, workingDir, true, "up", "--detach"); err != nil {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     fmt.Sprintf("failed to start compose stack: %v", err),
+				ErrorCode: ErrCodeIO,
+			}, nil
+		}
+		command = composeFollowCommand(task)
+	}
+	start := m.processManager.Start
+	if task.Interactive {
+		ipm, ok := m.processManager.(InteractiveProcessManager)
+		if !ok {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     fmt.Sprintf("daemon '%s' is interactive, which this process manager does not support", taskName),
+				ErrorCode: ErrCodeConfig,
+			}, nil
+		}
+		start = ipm.StartInteractive
+	}
+	if len(task.Redact) > 0 {
+		redactor, err := logs.NewRedactor(task.Redact)
+		if err != nil {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     err.Error(),
+				ErrorCode: ErrCodeConfig,
+			}, nil
+		}
+		rpm, ok := m.processManager.(RedactingProcessManager)
+		if !ok {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     fmt.Sprintf("daemon '%s' sets redact, which this process manager does not support", taskName),
+				ErrorCode: ErrCodeConfig,
+			}, nil
+		}
+		rpm.SetRedactor(taskName, redactor)
+	} else if rpm, ok := m.processManager.(RedactingProcessManager); ok {
+		rpm.SetRedactor(taskName, nil)
+	}
+	if maxSize, maxFiles := task.LogRotation(); maxSize > 0 {
+		lpm, ok := m.processManager.(LogRotatingProcessManager)
+		if !ok {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     fmt.Sprintf("daemon '%s' sets log_max_size, which this process manager does not support", taskName),
+				ErrorCode: ErrCodeConfig,
+			}, nil
+		}
+		lpm.SetLogRotation(taskName, maxSize, maxFiles)
+	} else if lpm, ok := m.processManager.(LogRotatingProcessManager); ok {
+		lpm.SetLogRotation(taskName, 0, 0)
+	}
+	if len(task.Ports) > 0 {
+		ppm, ok := m.processManager.(PortProcessManager)
+		if !ok {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     fmt.Sprintf("daemon '%s' sets ports, which this process manager does not support", taskName),
+				ErrorCode: ErrCodeConfig,
+			}, nil
+		}
+		ppm.SetPorts(taskName, task.Ports)
+	} else if ppm, ok := m.processManager.(PortProcessManager); ok {
+		ppm.SetPorts(taskName, nil)
+	}
+	if task.EnvPolicy.Restricts() {
+		epm, ok := m.processManager.(EnvProcessManager)
+		if !ok {
+			return &DaemonStartResult{
+				Success:   false,
+				Error:     fmt.Sprintf("daemon '%s' sets env_policy, which this process manager does not support", taskName),
+				ErrorCode: ErrCodeConfig,
+			}, nil
+		}
+		epm.SetInheritedEnv(taskName, task.EnvPolicy.Filter(os.Environ()))
+	} else if epm, ok := m.processManager.(EnvProcessManager); ok {
+		epm.SetInheritedEnv(taskName, nil)
+	}
+	if err := start(taskName, sessionID, command, task.Env, workingDir, logPath, task.Shell); err != nil {
 		return &DaemonStartResult{
-			Success: false,
-			Error:   fmt.Sprintf("failed to start daemon: %v", err),
+			Success:   false,
+			Error:     fmt.Sprintf("failed to start daemon: %v", err),
+			ErrorCode: errorCodeOr(err, ErrCodeIO),
 		}, nil
 	}
 
@@ -122,11 +445,21 @@ func (m *Manager) StartDaemon(taskName string, params map[string]interface{}) (*
 	_, pid, err := m.processManager.Status(taskName)
 	if err != nil {
 		return &DaemonStartResult{
-			Success: false,
-			Error:   fmt.Sprintf("failed to get daemon status: %v", err),
+			Success:   false,
+			Error:     fmt.Sprintf("failed to get daemon status: %v", err),
+			ErrorCode: ErrCodeIO,
 		}, nil
 	}
 
+	if m.observer != nil {
+		m.observer.ObserveDaemonStart(taskName)
+	}
+
+	// Record the parameters so the daemon can be restarted with them
+	if len(params) > 0 {
+		_ = logs.UpdateSessionMetadata(sessionID, map[string]interface{}{"parameters": params})
+	}
+
 	return &DaemonStartResult{
 		Success:   true,
 		PID:       pid,
@@ -141,39 +474,59 @@ func (m *Manager) StopDaemon(taskName string) (*DaemonStopResult, error) {
 	task, exists := m.manifest.Tasks[taskName]
 	if !exists {
 		return &DaemonStopResult{
-			Success: false,
-			Error:   fmt.Sprintf("task '%s' not found", taskName),
+			Success:   false,
+			Error:     fmt.Sprintf("task '%s' not found", taskName),
+			ErrorCode: ErrCodeNotFound,
 		}, nil
 	}
 
 	// Verify task type
-	if task.Type != config.TaskTypeDaemon {
+	if !task.Type.IsDaemon() {
 		return &DaemonStopResult{
-			Success: false,
-			Error:   fmt.Sprintf("task '%s' is not a daemon", taskName),
+			Success:   false,
+			Error:     fmt.Sprintf("task '%s' is not a daemon", taskName),
+			ErrorCode: ErrCodeConfig,
 		}, nil
 	}
+	if task.Type == config.TaskTypeCompose {
+		return m.stopCompose(taskName, task)
+	}
 
 	// Check if running
 	running, _, err := m.processManager.Status(taskName)
 	if err != nil {
 		return &DaemonStopResult{
-			Success: false,
-			Error:   fmt.Sprintf("failed to check status: %v", err),
+			Success:   false,
+			Error:     fmt.Sprintf("failed to check status: %v", err),
+			ErrorCode: ErrCodeIO,
 		}, nil
 	}
 	if !running {
 		return &DaemonStopResult{
-			Success: false,
-			Error:   fmt.Sprintf("daemon '%s' is not running", taskName),
+			Success:   false,
+			Error:     fmt.Sprintf("daemon '%s' is not running", taskName),
+			ErrorCode: ErrCodeNotRunning,
+		}, nil
+	}
+
+	// Apply the task's stop settings at stop time, so daemons started by an
+	// earlier runbook process are stopped the same way
+	if spm, ok := m.processManager.(StopSignalProcessManager); ok {
+		spm.SetStopSignal(taskName, task.StopSignalName(), time.Duration(task.StopGracePeriod)*time.Second)
+	} else if task.StopSignal != "" || task.StopGracePeriod > 0 {
+		return &DaemonStopResult{
+			Success:   false,
+			Error:     fmt.Sprintf("daemon '%s' sets stop_signal or stop_grace_period, which this process manager does not support", taskName),
+			ErrorCode: ErrCodeConfig,
 		}, nil
 	}
 
 	// Stop daemon
 	if err := m.processManager.Stop(taskName); err != nil {
 		return &DaemonStopResult{
-			Success: false,
-			Error:   fmt.Sprintf("failed to stop daemon: %v", err),
+			Success:   false,
+			Error:     fmt.Sprintf("failed to stop daemon: %v", err),
+			ErrorCode: ErrCodeIO,
 		}, nil
 	}
 
@@ -183,17 +536,78 @@ func (m *Manager) StopDaemon(taskName string) (*DaemonStopResult, error) {
 	}, nil
 }
 
+// stopCompose stops a compose task's log follower, then takes its stack
+// down. The stack is taken down even if the follower already exited.
+func (m *Manager) stopCompose(taskName string, task config.Task) (*DaemonStopResult, error) {
+	if running, _, _ := m.processManager.Status(taskName); running {
+		if err := m.processManager.Stop(taskName); err != nil {
+			return &DaemonStopResult{
+				Success:   false,
+				Error:     fmt.Sprintf("failed to stop daemon: %v", err),
+				ErrorCode: ErrCodeIO,
+			}, nil
+		}
+	}
+
+	args := []string{"down"}
+	if task.Compose != nil && len(task.Compose.Services) > 0 {
+		// Leave the rest of a shared project running
+		args = []string{"rm", "--stop", "--force"}
+	}
+	if _, err := runCompose(task, task.WorkingDirectory, true, args...); err != nil {
+		return &DaemonStopResult{
+			Success:   false,
+			Error:     fmt.Sprintf("failed to stop compose stack: %v", err),
+			ErrorCode: ErrCodeIO,
+		}, nil
+	}
+
+	return &DaemonStopResult{
+		Success: true,
+		Message: fmt.Sprintf("compose stack '%s' stopped successfully", taskName),
+	}, nil
+}
+
+// SendInput writes input to the stdin of a running interactive daemon.
+func (m *Manager) SendInput(taskName string, input string) (*DaemonInputResult, error) {
+	task, exists := m.manifest.Tasks[taskName]
+	if !exists {
+		return nil, errTaskNotFound(taskName)
+	}
+	if task.Type != config.TaskTypeDaemon || !task.Interactive {
+		return nil, codedErrorf(ErrCodeConfig, "task '%s' is not an interactive daemon", taskName)
+	}
+
+	ipm, ok := m.processManager.(InteractiveProcessManager)
+	if !ok {
+		return nil, codedErrorf(ErrCodeUnavailable, "process manager does not support interactive daemons")
+	}
+	if err := ipm.SendInput(taskName, input); err != nil {
+		code := ErrCodeUnavailable
+		if running, _, _ := m.processManager.Status(taskName); !running {
+			code = ErrCodeNotRunning
+		}
+		return &DaemonInputResult{Success: false, Error: err.Error(), ErrorCode: code}, nil
+	}
+
+	sessionID, _ := m.processManager.GetSessionID(taskName)
+	return &DaemonInputResult{Success: true, Bytes: len(input), SessionID: sessionID}, nil
+}
+
+// StatusEventCount is how many recent lifecycle events DaemonStatus includes.
+const StatusEventCount = 10
+
 // DaemonStatus returns the status of a daemon task
 func (m *Manager) DaemonStatus(taskName string) (*DaemonStatus, error) {
 	// Get task definition
 	task, exists := m.manifest.Tasks[taskName]
 	if !exists {
-		return nil, fmt.Errorf("task '%s' not found", taskName)
+		return nil, errTaskNotFound(taskName)
 	}
 
 	// Verify task type
-	if task.Type != config.TaskTypeDaemon {
-		return nil, fmt.Errorf("task '%s' is not a daemon", taskName)
+	if !task.Type.IsDaemon() {
+		return nil, errNotDaemon(taskName)
 	}
 
 	// Get status
@@ -208,18 +622,54 @@ func (m *Manager) DaemonStatus(taskName string) (*DaemonStatus, error) {
 		sessionID, _ = m.processManager.GetSessionID(taskName)
 	}
 
-	// Get log path
+	// Get log path and uptime from the running session
 	logPath := ""
+	var startTime time.Time
+	uptime := ""
 	if sessionID != "" {
 		logPath = logs.GetSessionLogPath(sessionID)
+		if metadata, err := logs.ReadSessionMetadata(sessionID); err == nil {
+			startTime = metadata.StartTime
+			uptime = time.Since(startTime).Round(time.Second).String()
+		}
 	}
 
-	return &DaemonStatus{
-		Running:   running,
-		PID:       pid,
-		LogPath:   logPath,
-		SessionID: sessionID,
-	}, nil
+	// Recent lifecycle events and the last exit, which may be older; a
+	// missing or unreadable log is not fatal
+	events, _ := logs.ReadDaemonEvents(taskName, 0)
+	var lastExit *logs.DaemonEvent
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Event == logs.EventExit || events[i].Event == logs.EventCrash {
+			lastExit = &events[i]
+			break
+		}
+	}
+	if len(events) > StatusEventCount {
+		events = events[len(events)-StatusEventCount:]
+	}
+
+	status := &DaemonStatus{
+		Running:    running,
+		PID:        pid,
+		StartTime:  startTime,
+		Uptime:     uptime,
+		LogPath:    logPath,
+		SessionID:  sessionID,
+		LastEvents: events,
+		LastExit:   lastExit,
+	}
+	if ppm, ok := m.processManager.(PortProcessManager); ok && running {
+		status.Ports = ppm.Ports(taskName)
+	}
+	if upm, ok := m.processManager.(UsageProcessManager); ok && running {
+		// Usage is best effort; the daemon may exit while it is read
+		status.Usage, _ = upm.Usage(taskName)
+	}
+	if task.Type == config.TaskTypeCompose {
+		// The stack's containers; an unreachable docker is not fatal
+		status.Services, _ = composeServices(task, task.WorkingDirectory)
+	}
+	return status, nil
 }
 
 // GetManifest returns the manifest
@@ -0,0 +1,104 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// CrashReportingProcessManager is implemented by process managers that can
+// report daemons exiting on their own with a failure status.
+type CrashReportingProcessManager interface {
+	SetCrashHandler(handler func(event logs.DaemonEvent))
+}
+
+// reportCrash sends the notifications configured in on_crash for a crashed
+// daemon. Each one runs in the background, and failures are printed as
+// warnings; a notification must never hold up managing the daemons.
+func (m *Manager) reportCrash(event logs.DaemonEvent) {
+	notify := m.manifest.CrashNotify(event.Task)
+	if notify == nil {
+		return
+	}
+
+	send := func(channel string, fn func() error) {
+		go func() {
+			if err := fn(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to send %s crash notification for %s: %v\n", channel, event.Task, err)
+			}
+		}()
+	}
+	if notify.Desktop {
+		send("desktop", func() error { return desktopNotify(event) })
+	}
+	if notify.Webhook != "" {
+		webhook := &logs.WebhookSink{URL: notify.Webhook}
+		if notify.TokenEnv != "" {
+			webhook.Token = os.Getenv(notify.TokenEnv)
+		}
+		send("webhook", func() error { return webhook.Post(event) })
+	}
+	if notify.Task != "" {
+		send("task", func() error { return m.runNotifyTask(notify.Task, event) })
+	}
+}
+
+// runNotifyTask runs the on_crash task, passing the crash as whichever of
+// the task, exit_code, reason, session_id, and pid parameters it declares.
+func (m *Manager) runNotifyTask(taskName string, event logs.DaemonEvent) error {
+	crash := map[string]string{
+		"task":       event.Task,
+		"reason":     event.Reason,
+		"session_id": event.SessionID,
+		"pid":        strconv.Itoa(event.PID),
+	}
+	if event.ExitCode != nil {
+		crash["exit_code"] = strconv.Itoa(*event.ExitCode)
+	}
+	params := make(map[string]interface{})
+	for name := range m.manifest.Tasks[taskName].Parameters {
+		if value, ok := crash[name]; ok {
+			params[name] = value
+		}
+	}
+
+	result, err := m.ExecuteOneShot(taskName, params)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("task '%s' failed: %s", taskName, result.Error)
+	}
+	return nil
+}
+
+// desktopNotify shows a desktop notification for a crash.
+func desktopNotify(event logs.DaemonEvent) error {
+	argv, err := desktopNotifyArgs(runtime.GOOS, "runbook: "+event.Task+" crashed", event.Reason)
+	if err != nil {
+		return err
+	}
+	if out, err := exec.Command(argv[0], argv[1:]...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v: %s", argv[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// desktopNotifyArgs returns the command that shows a notification on goos:
+// notify-send on Linux and the BSDs, and osascript on macOS.
+func desktopNotifyArgs(goos, title, message string) ([]string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(message), strconv.Quote(title))
+		return []string{"osascript", "-e", script}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return []string{"notify-send", title, message}, nil
+	default:
+		return nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+	}
+}
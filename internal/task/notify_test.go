@@ -0,0 +1,100 @@
+package task
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestReportCrash(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	posted := make(chan logs.DaemonEvent, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event logs.DaemonEvent
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		posted <- event
+	}))
+	defer hook.Close()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"api": {Command: "./api", Type: config.TaskTypeDaemon, OnCrash: &config.CrashNotify{Webhook: hook.URL, Task: "page"}},
+			"page": {
+				Command: "echo {{.task}} {{.exit_code}} > crash.txt",
+				Type:    config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"task":      {Type: "string"},
+					"exit_code": {Type: "string"},
+				},
+			},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	code := 3
+	manager.reportCrash(logs.DaemonEvent{Task: "api", Event: logs.EventCrash, PID: 42, ExitCode: &code, Reason: "exited with code 3"})
+
+	event := <-posted
+	if event.Task != "api" || event.Reason != "exited with code 3" {
+		t.Errorf("unexpected webhook event: %+v", event)
+	}
+	waitFor(t, func() bool {
+		data, _ := os.ReadFile("crash.txt")
+		return strings.TrimSpace(string(data)) == "api 3"
+	})
+}
+
+func TestDesktopNotifyArgs(t *testing.T) {
+	argv, err := desktopNotifyArgs("linux", "runbook: api crashed", "exited with code 3")
+	if err != nil || strings.Join(argv, "|") != "notify-send|runbook: api crashed|exited with code 3" {
+		t.Errorf("unexpected linux command %v (%v)", argv, err)
+	}
+
+	argv, err = desktopNotifyArgs("darwin", "runbook: api crashed", `killed by "signal"`)
+	if err != nil || argv[0] != "osascript" || !strings.Contains(argv[2], `display notification "killed by \"signal\"" with title "runbook: api crashed"`) {
+		t.Errorf("unexpected macOS command %v (%v)", argv, err)
+	}
+
+	if _, err := desktopNotifyArgs("plan9", "t", "m"); err == nil {
+		t.Error("expected an error on an unsupported OS")
+	}
+}
+
+func TestDaemonStatusLastExit(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"api": {Command: "./api", Type: config.TaskTypeDaemon},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	code := 1
+	logs.RecordDaemonEvent(logs.DaemonEvent{Task: "api", Event: logs.EventStart, PID: 10})
+	logs.RecordDaemonEvent(logs.DaemonEvent{Task: "api", Event: logs.EventCrash, PID: 10, ExitCode: &code, Reason: "exited with code 1"})
+	for i := 0; i < StatusEventCount; i++ {
+		logs.RecordDaemonEvent(logs.DaemonEvent{Task: "api", Event: logs.EventStart, PID: 11})
+		logs.RecordDaemonEvent(logs.DaemonEvent{Task: "api", Event: logs.EventStop, PID: 11})
+	}
+
+	status, err := manager.DaemonStatus("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.LastEvents) != StatusEventCount {
+		t.Errorf("expected %d recent events, got %d", StatusEventCount, len(status.LastEvents))
+	}
+	if status.LastExit == nil || status.LastExit.Event != logs.EventCrash || status.LastExit.Reason != "exited with code 1" {
+		t.Errorf("expected the crash older than the recent events, got %+v", status.LastExit)
+	}
+}
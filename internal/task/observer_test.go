@@ -0,0 +1,76 @@
+package task
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	tasks  map[string][]bool
+	starts []string
+}
+
+func (o *recordingObserver) ObserveTask(taskName string, success bool, duration time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.tasks == nil {
+		o.tasks = make(map[string][]bool)
+	}
+	o.tasks[taskName] = append(o.tasks[taskName], success)
+}
+
+func (o *recordingObserver) ObserveDaemonStart(taskName string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.starts = append(o.starts, taskName)
+}
+
+func TestManagerObserver(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"ok":   {Description: "ok", Command: "true", Type: config.TaskTypeOneShot},
+			"fail": {Description: "fail", Command: "exit 1", Type: config.TaskTypeOneShot},
+			"dev":  {Description: "dev", Command: "sleep 10", Type: config.TaskTypeDaemon},
+		},
+		Workflows: map[string]config.Workflow{
+			"ci": {Description: "ci", Steps: []config.WorkflowStep{{Task: "ok"}, {Task: "fail"}}},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+	observer := &recordingObserver{}
+	manager.SetObserver(observer)
+
+	if _, err := manager.ExecuteOneShot("ok", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.ExecuteWorkflow("ci", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.ExecuteCommand("true", "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.StartDaemon("dev", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := observer.tasks["ok"]; len(got) != 2 || !got[0] || !got[1] {
+		t.Errorf("expected two successful runs of ok, got %v", got)
+	}
+	if got := observer.tasks["fail"]; len(got) != 1 || got[0] {
+		t.Errorf("expected one failed run of fail, got %v", got)
+	}
+	if got := observer.tasks[AdHocTaskName]; len(got) != 1 {
+		t.Errorf("expected one ad-hoc run, got %v", got)
+	}
+	if len(observer.starts) != 1 || observer.starts[0] != "dev" {
+		t.Errorf("expected one start of dev, got %v", observer.starts)
+	}
+}
@@ -0,0 +1,30 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// parseOutput sets the structured output of a run of a task with
+// output_format: json from its stdout. Stdout that is not valid JSON leaves
+// the output unset and is reported in OutputError; the run's status is not
+// affected, since the command itself ran as it did.
+func parseOutput(task config.Task, result *ExecutionResult) {
+	if task.OutputFormat != config.OutputFormatJSON || result.SessionID == "" {
+		return
+	}
+	stdout := bytes.TrimSpace([]byte(result.Stdout))
+	if len(stdout) == 0 {
+		result.OutputError = "stdout is empty"
+		return
+	}
+	var output bytes.Buffer
+	if err := json.Compact(&output, stdout); err != nil {
+		result.OutputError = fmt.Sprintf("stdout is not valid JSON: %v", err)
+		return
+	}
+	result.Output = output.Bytes()
+}
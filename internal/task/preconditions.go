@@ -0,0 +1,108 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/template"
+)
+
+const (
+	// preconditionTimeout bounds how long a command precondition may run.
+	preconditionTimeout = 30 * time.Second
+	// preconditionOutputLimit caps the output kept from a failed command check.
+	preconditionOutputLimit = 2048
+)
+
+// checkPreconditions runs the task's preconditions in order and returns the
+// first one that fails, or nil when all pass.
+func checkPreconditions(task config.Task, params map[string]interface{}) *PreconditionFailure {
+	workingDir := resolveWorkingDirectory(task, params)
+	for i, check := range task.Preconditions {
+		failure := checkPrecondition(task, check, params, workingDir)
+		if failure != nil {
+			failure.Index = i
+			failure.Message = check.Message
+			return failure
+		}
+	}
+	return nil
+}
+
+// checkPrecondition evaluates a single precondition.
+func checkPrecondition(task config.Task, check config.Precondition, params map[string]interface{}, workingDir string) *PreconditionFailure {
+	switch {
+	case check.Command != "":
+		command, err := template.SubstituteParameters(check.Command, params)
+		if err != nil {
+			return &PreconditionFailure{Check: "command", Target: check.Command, Detail: fmt.Sprintf("parameter substitution failed: %v", err)}
+		}
+		shell := task.Shell
+		if shell == "" {
+			shell = "/bin/bash"
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), preconditionTimeout)
+		defer cancel()
+		argv := config.ShellArgs(shell, command)
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Dir = workingDir
+		cmd.Env = taskEnviron(task)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		detail := fmt.Sprintf("command failed: %v", err)
+		if ctx.Err() == context.DeadlineExceeded {
+			detail = fmt.Sprintf("command timed out after %s", preconditionTimeout)
+		}
+		out := strings.TrimSpace(string(output))
+		if len(out) > preconditionOutputLimit {
+			out = out[len(out)-preconditionOutputLimit:]
+		}
+		return &PreconditionFailure{Check: "command", Target: command, Detail: detail, Output: out}
+
+	case check.FileExists != "":
+		path, err := template.SubstituteParameters(check.FileExists, params)
+		if err != nil {
+			return &PreconditionFailure{Check: "file_exists", Target: check.FileExists, Detail: fmt.Sprintf("parameter substitution failed: %v", err)}
+		}
+		resolved := path
+		if !filepath.IsAbs(resolved) && workingDir != "" {
+			resolved = filepath.Join(workingDir, resolved)
+		}
+		if _, err := os.Stat(resolved); err != nil {
+			if os.IsNotExist(err) {
+				return &PreconditionFailure{Check: "file_exists", Target: path, Detail: fmt.Sprintf("'%s' does not exist", path)}
+			}
+			return &PreconditionFailure{Check: "file_exists", Target: path, Detail: err.Error()}
+		}
+		return nil
+
+	case check.PortFree != 0:
+		target := strconv.Itoa(check.PortFree)
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", target), time.Second)
+		if err != nil {
+			return nil
+		}
+		conn.Close()
+		return &PreconditionFailure{Check: "port_free", Target: target, Detail: fmt.Sprintf("port %d is already in use", check.PortFree)}
+	}
+	return nil
+}
+
+// preconditionError is the result error of a failed precondition.
+func preconditionError(failure *PreconditionFailure) string {
+	msg := fmt.Sprintf("precondition failed: %s", failure.Detail)
+	if failure.Message != "" {
+		msg += " (" + failure.Message + ")"
+	}
+	return msg
+}
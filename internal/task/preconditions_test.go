@@ -0,0 +1,133 @@
+package task
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+func TestPreconditions(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldWd) }()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+
+	work := t.TempDir()
+	if err := os.WriteFile(filepath.Join(work, "dev.env"), []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	busyPort := listener.Addr().(*net.TCPAddr).Port
+
+	newManifest := func(checks ...config.Precondition) *config.Manifest {
+		return &config.Manifest{
+			Version: "1.0",
+			Tasks: map[string]config.Task{
+				"migrate": {
+					Description:      "Migrate",
+					Type:             config.TaskTypeOneShot,
+					Command:          "echo migrated > ran.txt",
+					WorkingDirectory: work,
+					Env:              map[string]string{"DB_READY": "yes"},
+					Parameters:       map[string]config.Param{"env": {Type: "string", Required: true}},
+					Preconditions:    checks,
+				},
+			},
+		}
+	}
+	params := func() map[string]interface{} { return map[string]interface{}{"env": "dev"} }
+
+	t.Run("all pass", func(t *testing.T) {
+		manifest := newManifest(
+			config.Precondition{FileExists: "{{.env}}.env"},
+			config.Precondition{Command: `test "$DB_READY" = yes`},
+		)
+		result, err := NewExecutor(manifest).Execute("migrate", params())
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if !result.Success || result.PreconditionFailed != nil {
+			t.Fatalf("expected success, got error %q", result.Error)
+		}
+		if _, err := os.Stat(filepath.Join(work, "ran.txt")); err != nil {
+			t.Errorf("expected the command to run: %v", err)
+		}
+		os.Remove(filepath.Join(work, "ran.txt"))
+	})
+
+	tests := []struct {
+		name       string
+		checks     []config.Precondition
+		wantIndex  int
+		wantCheck  string
+		wantTarget string
+		wantError  string
+		wantOutput string
+	}{
+		{
+			name:       "missing file",
+			checks:     []config.Precondition{{FileExists: "{{.env}}.env"}, {FileExists: "secrets/{{.env}}.key", Message: "run make secrets first"}},
+			wantIndex:  1,
+			wantCheck:  "file_exists",
+			wantTarget: "secrets/dev.key",
+			wantError:  "precondition failed: 'secrets/dev.key' does not exist (run make secrets first)",
+		},
+		{
+			name:       "failing command",
+			checks:     []config.Precondition{{Command: "echo database down; exit 3"}},
+			wantCheck:  "command",
+			wantTarget: "echo database down; exit 3",
+			wantError:  "precondition failed: command failed: exit status 3",
+			wantOutput: "database down",
+		},
+		{
+			name:       "port in use",
+			checks:     []config.Precondition{{PortFree: busyPort}},
+			wantCheck:  "port_free",
+			wantTarget: strconv.Itoa(busyPort),
+			wantError:  "is already in use",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewExecutor(newManifest(tt.checks...)).Execute("migrate", params())
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Success {
+				t.Fatal("expected failure")
+			}
+			f := result.PreconditionFailed
+			if f == nil {
+				t.Fatalf("expected a precondition failure, got error %q", result.Error)
+			}
+			if f.Index != tt.wantIndex || f.Check != tt.wantCheck || f.Target != tt.wantTarget {
+				t.Errorf("failure = %+v, want index %d check %s target %s", f, tt.wantIndex, tt.wantCheck, tt.wantTarget)
+			}
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("error = %q, want it to contain %q", result.Error, tt.wantError)
+			}
+			if f.Output != tt.wantOutput {
+				t.Errorf("output = %q, want %q", f.Output, tt.wantOutput)
+			}
+			if _, err := os.Stat(filepath.Join(work, "ran.txt")); !os.IsNotExist(err) {
+				t.Error("expected the command not to run")
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RunQueue bounds how many oneshot tasks run at once. Runs over the limit
+// wait in FIFO order until a slot frees up. A nil RunQueue, or one with a
+// limit of 0, never makes a run wait.
+type RunQueue struct {
+	mu      sync.Mutex
+	limit   int
+	nextID  int
+	running map[int]QueuedRun
+	waiting []*queuedRun
+}
+
+// queuedRun is a run waiting for a slot; ready is closed when it gets one.
+type queuedRun struct {
+	id    int
+	run   QueuedRun
+	ready chan struct{}
+}
+
+// QueuedRun describes a run holding or waiting for a slot.
+type QueuedRun struct {
+	Position int       `json:"position,omitempty"` // 1-based place in the queue, for waiting runs
+	Task     string    `json:"task"`
+	Since    time.Time `json:"since"` // When the run started, or joined the queue
+}
+
+// QueueStatus is a snapshot of a RunQueue.
+type QueueStatus struct {
+	MaxConcurrent int         `json:"max_concurrent"` // 0 means unlimited
+	Running       []QueuedRun `json:"running"`
+	Queued        []QueuedRun `json:"queued"`
+}
+
+// NewRunQueue creates a queue that lets limit runs execute at once.
+func NewRunQueue(limit int) *RunQueue {
+	return &RunQueue{limit: limit, running: make(map[int]QueuedRun)}
+}
+
+// SetLimit changes how many runs execute at once. Raising it starts waiting
+// runs right away; lowering it lets running ones finish.
+func (q *RunQueue) SetLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+	q.dispatch()
+}
+
+// acquire blocks until taskName may run, and returns the function that gives
+// its slot back.
+func (q *RunQueue) acquire(taskName string) func() {
+	if q == nil {
+		return func() {}
+	}
+
+	q.mu.Lock()
+	q.nextID++
+	w := &queuedRun{id: q.nextID, run: QueuedRun{Task: taskName, Since: time.Now()}, ready: make(chan struct{})}
+	q.waiting = append(q.waiting, w)
+	q.dispatch()
+	q.mu.Unlock()
+
+	<-w.ready
+	return func() {
+		q.mu.Lock()
+		delete(q.running, w.id)
+		q.dispatch()
+		q.mu.Unlock()
+	}
+}
+
+// dispatch moves waiting runs into free slots, oldest first. Callers hold mu.
+func (q *RunQueue) dispatch() {
+	for len(q.waiting) > 0 && (q.limit <= 0 || len(q.running) < q.limit) {
+		w := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.running[w.id] = QueuedRun{Task: w.run.Task, Since: time.Now()}
+		close(w.ready)
+	}
+}
+
+// Status returns the runs currently holding a slot, oldest first, and the
+// runs waiting for one in queue order.
+func (q *RunQueue) Status() QueueStatus {
+	status := QueueStatus{Running: []QueuedRun{}, Queued: []QueuedRun{}}
+	if q == nil {
+		return status
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	status.MaxConcurrent = q.limit
+	for _, run := range q.running {
+		status.Running = append(status.Running, run)
+	}
+	sort.Slice(status.Running, func(i, j int) bool { return status.Running[i].Since.Before(status.Running[j].Since) })
+	for i, w := range q.waiting {
+		run := w.run
+		run.Position = i + 1
+		status.Queued = append(status.Queued, run)
+	}
+	return status
+}
@@ -0,0 +1,116 @@
+package task
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestRunQueueLimitsConcurrency(t *testing.T) {
+	q := NewRunQueue(1)
+
+	release := q.acquire("build")
+	acquired := make(chan string, 2)
+	var wg sync.WaitGroup
+	for i, name := range []string{"lint", "test"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			done := q.acquire(name)
+			acquired <- name
+			done()
+		}(name)
+		// Let each waiter join the queue before the next one
+		waitFor(t, func() bool { return len(q.Status().Queued) == i+1 })
+	}
+
+	status := q.Status()
+	if status.MaxConcurrent != 1 || len(status.Running) != 1 || status.Running[0].Task != "build" {
+		t.Fatalf("expected build to hold the only slot, got %+v", status)
+	}
+	if len(status.Queued) != 2 || status.Queued[0].Task != "lint" || status.Queued[0].Position != 1 || status.Queued[1].Position != 2 {
+		t.Fatalf("expected lint then test in the queue, got %+v", status.Queued)
+	}
+	select {
+	case name := <-acquired:
+		t.Fatalf("%s ran while the slot was taken", name)
+	default:
+	}
+
+	release()
+	wg.Wait()
+	if first, second := <-acquired, <-acquired; first != "lint" || second != "test" {
+		t.Errorf("expected runs in queue order, got %s then %s", first, second)
+	}
+	if status := q.Status(); len(status.Running) != 0 || len(status.Queued) != 0 {
+		t.Errorf("expected an empty queue, got %+v", status)
+	}
+}
+
+func TestRunQueueSetLimit(t *testing.T) {
+	q := NewRunQueue(1)
+	release := q.acquire("build")
+	defer release()
+
+	started := make(chan struct{})
+	go func() {
+		done := q.acquire("test")
+		close(started)
+		done()
+	}()
+	waitFor(t, func() bool { return len(q.Status().Queued) == 1 })
+
+	q.SetLimit(0)
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected raising the limit to start the waiting run")
+	}
+}
+
+func TestExecutorWaitsForQueue(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"hello": {Command: "echo hello", Type: config.TaskTypeOneShot, Timeout: 30},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+	q := NewRunQueue(1)
+	manager.SetRunQueue(q)
+
+	release := q.acquire("other")
+	results := make(chan *ExecutionResult, 1)
+	go func() {
+		result, _ := manager.ExecuteOneShot("hello", nil)
+		results <- result
+	}()
+	waitFor(t, func() bool { return len(q.Status().Queued) == 1 })
+	time.Sleep(100 * time.Millisecond)
+	release()
+
+	result := <-results
+	if !result.Success || !strings.Contains(result.Stdout, "hello") {
+		t.Fatalf("expected the queued run to succeed, got %+v", result)
+	}
+	if result.Duration >= 100*time.Millisecond {
+		t.Errorf("expected time spent queued to be left out of the duration, got %v", result.Duration)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after two seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
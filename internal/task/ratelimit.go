@@ -0,0 +1,91 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// RateLimiter enforces the rate_limit of tasks by remembering when each one
+// was last run. Runs are counted in a sliding window, so a task with
+// max_runs: 2 and per: 1h can run twice in any hour.
+type RateLimiter struct {
+	mu   sync.Mutex
+	runs map[string][]time.Time
+	now  func() time.Time
+}
+
+// NewRateLimiter creates a limiter with no runs recorded.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{runs: make(map[string][]time.Time), now: time.Now}
+}
+
+// RateLimitError is returned for a run over its task's rate_limit.
+type RateLimitError struct {
+	Task       string
+	Limit      config.RateLimit
+	RetryAfter time.Duration // Until the oldest run in the window expires
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("task '%s' is rate limited to %d runs per %s; retry after %s",
+		e.Task, e.Limit.MaxRuns, e.Limit.Per, e.RetryAfter.Round(time.Second))
+}
+
+// allow records a run of taskName, or returns a *RateLimitError without
+// recording it when the task has used up its rate_limit.
+func (l *RateLimiter) allow(taskName string, task config.Task) error {
+	if l == nil || task.RateLimit == nil {
+		return nil
+	}
+	window := task.RateLimit.Window()
+	if window == 0 || task.RateLimit.MaxRuns < 1 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	cutoff := now.Add(-window)
+	var kept []time.Time
+	for _, t := range l.runs[taskName] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= task.RateLimit.MaxRuns {
+		l.runs[taskName] = kept
+		return &RateLimitError{Task: taskName, Limit: *task.RateLimit, RetryAfter: kept[0].Add(window).Sub(now)}
+	}
+	l.runs[taskName] = append(kept, now)
+	return nil
+}
+
+// checkStartRateLimit counts a start of a daemon against its rate limit,
+// returning the failed start result when it is over the limit.
+func (m *Manager) checkStartRateLimit(taskName string, task config.Task) *DaemonStartResult {
+	if err := m.rateLimiter.allow(taskName, task); err != nil {
+		return &DaemonStartResult{
+			Success:    false,
+			Error:      err.Error(),
+			ErrorCode:  ErrCodeRateLimited,
+			RetryAfter: retryAfterSeconds(err),
+		}
+	}
+	return nil
+}
+
+// retryAfterSeconds is how many whole seconds a *RateLimitError asks the
+// caller to wait, rounded up so retrying then succeeds.
+func retryAfterSeconds(err error) int {
+	var limited *RateLimitError
+	if !errors.As(err, &limited) {
+		return 0
+	}
+	return int(math.Ceil(limited.RetryAfter.Seconds()))
+}
@@ -0,0 +1,64 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestRateLimit(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	limit := &config.RateLimit{MaxRuns: 2, Per: "1h"}
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"deploy": {Command: "echo deployed", Type: config.TaskTypeOneShot, RateLimit: limit},
+			"dev":    {Command: "sleep 10", Type: config.TaskTypeDaemon, RateLimit: &config.RateLimit{MaxRuns: 1, Per: "1m"}},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+	now := time.Now()
+	manager.rateLimiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		result, err := manager.ExecuteOneShot("deploy", nil)
+		if err != nil || !result.Success {
+			t.Fatalf("run %d: expected success, got %+v (%v)", i+1, result, err)
+		}
+		now = now.Add(10 * time.Minute)
+	}
+
+	result, err := manager.ExecuteOneShot("deploy", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || result.RetryAfter != 40*60 || !strings.Contains(result.Error, "retry after 40m0s") {
+		t.Errorf("expected the third run to be rate limited for 40m, got %+v", result)
+	}
+
+	// The first run leaves the window an hour after it started
+	now = now.Add(40 * time.Minute)
+	if result, _ := manager.ExecuteOneShot("deploy", nil); !result.Success {
+		t.Errorf("expected a run once the window passed, got %+v", result)
+	}
+
+	// A reloaded manager keeps counting against the same limiter
+	reloaded := NewManager(manifest, NewMockProcessManager())
+	reloaded.SetRateLimiter(manager.RateLimiter())
+	if result, _ := reloaded.ExecuteOneShot("deploy", nil); result.Success || result.RetryAfter == 0 {
+		t.Errorf("expected the reloaded manager to keep the limit, got %+v", result)
+	}
+
+	if start, _ := manager.StartDaemon("dev", nil); !start.Success {
+		t.Fatalf("expected the first start to succeed, got %+v", start)
+	}
+	if _, err := manager.StopDaemon("dev"); err != nil {
+		t.Fatal(err)
+	}
+	if start, _ := manager.StartDaemonFresh("dev", nil); start.Success || start.RetryAfter != 60 {
+		t.Errorf("expected the second start to be rate limited, got %+v", start)
+	}
+}
@@ -0,0 +1,181 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+const (
+	// defaultReadyTimeout is how long to wait for a daemon's ready check
+	// when the task does not set ready.timeout.
+	defaultReadyTimeout = 30 * time.Second
+	// readyPollInterval is how often ready conditions are re-evaluated.
+	readyPollInterval = 200 * time.Millisecond
+)
+
+// EnsureDaemons starts every named daemon that is not already running and
+// waits until each one passes its ready check. It returns the names of the
+// daemons it started. Daemons that are already running are still checked
+// for readiness, since they may have been started moments ago.
+func (m *Manager) EnsureDaemons(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var started []string
+	for _, name := range names {
+		wasStarted, err := m.ensureDaemon(name, map[string]bool{})
+		if err != nil {
+			return started, err
+		}
+		if wasStarted {
+			started = append(started, name)
+		}
+	}
+	return started, nil
+}
+
+// ensureDaemon starts a single daemon (after its own required daemons) if it
+// is not running, then waits for it to become ready. It holds the daemon's
+// lock throughout, so concurrent callers requiring the same daemon don't
+// race to start it twice, while other daemons start in parallel. visiting
+// guards against cycles.
+func (m *Manager) ensureDaemon(name string, visiting map[string]bool) (bool, error) {
+	if visiting[name] {
+		return false, fmt.Errorf("required daemon '%s' forms a cycle", name)
+	}
+	visiting[name] = true
+	defer m.lockDaemon(name)()
+
+	daemon, exists := m.manifest.Tasks[name]
+	if !exists {
+		return false, fmt.Errorf("required daemon '%s' not found", name)
+	}
+	if !daemon.Type.IsDaemon() {
+		return false, fmt.Errorf("required task '%s' is not a daemon", name)
+	}
+	if m.processManager == nil {
+		return false, fmt.Errorf("cannot start required daemon '%s': no process manager", name)
+	}
+
+	running, _, err := m.processManager.Status(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check status of required daemon '%s': %w", name, err)
+	}
+
+	started := false
+	if !running {
+		for _, dep := range daemon.RequiresDaemon {
+			if _, err := m.ensureDaemon(dep, visiting); err != nil {
+				return false, err
+			}
+		}
+		result, err := m.startDaemon(name, daemon, nil)
+		if err != nil {
+			return false, err
+		}
+		if !result.Success {
+			return false, fmt.Errorf("failed to start required daemon '%s': %s", name, result.Error)
+		}
+		started = true
+	}
+
+	sessionID, _ := m.processManager.GetSessionID(name)
+	if err := m.waitReady(name, daemon.Ready, sessionID); err != nil {
+		return started, err
+	}
+	return started, nil
+}
+
+// waitReady polls the daemon's ready conditions until they all pass, the
+// daemon exits, or the ready timeout elapses.
+func (m *Manager) waitReady(name string, check *config.ReadyCheck, sessionID string) error {
+	timeout := defaultReadyTimeout
+	if check != nil && check.Timeout > 0 {
+		timeout = time.Duration(check.Timeout) * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		running, _, err := m.processManager.Status(name)
+		if err != nil {
+			return fmt.Errorf("failed to check status of required daemon '%s': %w", name, err)
+		}
+		if !running {
+			return fmt.Errorf("required daemon '%s' exited before becoming ready", name)
+		}
+
+		ready, reason := checkReady(check, sessionID)
+		if ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("required daemon '%s' not ready after %s: %s", name, timeout, reason)
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// checkReady evaluates every configured ready condition once. It returns
+// false with a description of the first failing condition.
+func checkReady(check *config.ReadyCheck, sessionID string) (bool, string) {
+	if check == nil {
+		return true, ""
+	}
+
+	if check.LogPattern != "" {
+		re, err := regexp.Compile(check.LogPattern)
+		if err != nil {
+			return false, fmt.Sprintf("invalid log_pattern: %v", err)
+		}
+		var data []byte
+		if log, err := logs.OpenLog(logs.GetSessionLogPath(sessionID)); err == nil {
+			data, _ = io.ReadAll(log)
+			log.Close()
+		}
+		if !re.Match(data) {
+			return false, fmt.Sprintf("log_pattern %q not matched", check.LogPattern)
+		}
+	}
+
+	if check.Port > 0 {
+		addr := net.JoinHostPort("localhost", strconv.Itoa(check.Port))
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err != nil {
+			return false, fmt.Sprintf("port %d not accepting connections", check.Port)
+		}
+		conn.Close()
+	}
+
+	if check.URL != "" {
+		client := http.Client{Timeout: 2 * time.Second}
+		resp, err := client.Get(check.URL)
+		if err != nil {
+			return false, fmt.Sprintf("url %s unreachable", check.URL)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return false, fmt.Sprintf("url %s returned %d", check.URL, resp.StatusCode)
+		}
+	}
+
+	if check.Command != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := exec.CommandContext(ctx, "/bin/sh", "-c", check.Command).Run(); err != nil {
+			return false, fmt.Sprintf("command %q failed: %v", check.Command, err)
+		}
+	}
+
+	return true, ""
+}
@@ -0,0 +1,190 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func readinessManifest(ready *config.ReadyCheck) *config.Manifest {
+	return &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"dev": {
+				Description: "Dev server",
+				Command:     "sleep 60",
+				Type:        config.TaskTypeDaemon,
+				Ready:       ready,
+			},
+			"e2e": {
+				Description:    "End-to-end tests",
+				Command:        "echo e2e",
+				Type:           config.TaskTypeOneShot,
+				RequiresDaemon: []string{"dev"},
+			},
+			"unit": {
+				Description: "Unit tests",
+				Command:     "echo unit",
+				Type:        config.TaskTypeOneShot,
+			},
+		},
+		Workflows: map[string]config.Workflow{
+			"ci": {
+				Description: "CI",
+				Steps: []config.WorkflowStep{
+					{Task: "unit", RequiresDaemon: []string{"dev"}},
+				},
+			},
+		},
+	}
+}
+
+func TestExecuteOneShotStartsRequiredDaemon(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	pm := NewMockProcessManager()
+	manager := NewManager(readinessManifest(nil), pm)
+
+	result, err := manager.ExecuteOneShot("e2e", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error: %s", result.Error)
+	}
+	if len(result.DaemonsStarted) != 1 || result.DaemonsStarted[0] != "dev" {
+		t.Errorf("expected DaemonsStarted [dev], got %v", result.DaemonsStarted)
+	}
+	if running, _, _ := pm.Status("dev"); !running {
+		t.Error("expected dev daemon to be running")
+	}
+}
+
+func TestExecuteOneShotRequiredDaemonAlreadyRunning(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	pm := NewMockProcessManager()
+	manager := NewManager(readinessManifest(nil), pm)
+	if _, err := manager.StartDaemon("dev", nil); err != nil {
+		t.Fatalf("failed to start daemon: %v", err)
+	}
+
+	result, err := manager.ExecuteOneShot("e2e", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error: %s", result.Error)
+	}
+	if len(result.DaemonsStarted) != 0 {
+		t.Errorf("expected no daemons started, got %v", result.DaemonsStarted)
+	}
+}
+
+func TestExecuteOneShotReadyCheck(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	t.Run("command passes", func(t *testing.T) {
+		manager := NewManager(readinessManifest(&config.ReadyCheck{Command: "true"}), NewMockProcessManager())
+		result, err := manager.ExecuteOneShot("e2e", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Success {
+			t.Errorf("expected success, got error: %s", result.Error)
+		}
+	})
+
+	t.Run("command never passes", func(t *testing.T) {
+		manager := NewManager(readinessManifest(&config.ReadyCheck{Command: "false", Timeout: 1}), NewMockProcessManager())
+		result, err := manager.ExecuteOneShot("e2e", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Success {
+			t.Fatal("expected failure when daemon never becomes ready")
+		}
+		if !strings.Contains(result.Error, "not ready") {
+			t.Errorf("expected not ready error, got: %s", result.Error)
+		}
+		if result.Stdout != "" {
+			t.Errorf("expected task not to run, got stdout: %q", result.Stdout)
+		}
+	})
+}
+
+func TestExecuteOneShotRequiredDaemonNoProcessManager(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manager := NewManager(readinessManifest(nil), nil)
+	result, err := manager.ExecuteOneShot("e2e", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("expected failure without a process manager")
+	}
+	if !strings.Contains(result.Error, "no process manager") {
+		t.Errorf("unexpected error: %s", result.Error)
+	}
+}
+
+func TestWorkflowStepRequiresDaemon(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	pm := NewMockProcessManager()
+	manager := NewManager(readinessManifest(nil), pm)
+
+	result, err := manager.ExecuteWorkflow("ci", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got error: %s", result.Error)
+	}
+	if got := result.Steps[0].Result.DaemonsStarted; len(got) != 1 || got[0] != "dev" {
+		t.Errorf("expected step to start dev, got %v", got)
+	}
+	if running, _, _ := pm.Status("dev"); !running {
+		t.Error("expected dev daemon to be running")
+	}
+}
+
+func TestEnsureDaemonsLocksPerDaemon(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := readinessManifest(nil)
+	manifest.Tasks["slow"] = config.Task{
+		Description: "Slow to become ready",
+		Command:     "sleep 60",
+		Type:        config.TaskTypeDaemon,
+		Ready:       &config.ReadyCheck{Command: "sleep 2", Timeout: 10},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := manager.EnsureDaemons([]string{"slow"})
+		slowDone <- err
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := manager.EnsureDaemons([]string{"dev"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("dev waited %v for another daemon's ready check", elapsed)
+	}
+	if err := <-slowDone; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package task
+
+import (
+	"fmt"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// SignalingProcessManager is implemented by process managers that can send
+// a running daemon a signal, e.g. to make it reload its configuration.
+type SignalingProcessManager interface {
+	Signal(taskName string, signal string) error
+}
+
+// DaemonReloadResult represents the result of reloading a daemon
+type DaemonReloadResult struct {
+	Success   bool   `json:"success"`
+	Signal    string `json:"signal,omitempty"` // The signal sent, e.g. "SIGHUP"
+	PID       int    `json:"pid,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReloadDaemon sends a running daemon with reload: true its reload_signal,
+// so it can pick up configuration changes without a restart.
+func (m *Manager) ReloadDaemon(taskName string) (*DaemonReloadResult, error) {
+	task, exists := m.manifest.Tasks[taskName]
+	if !exists {
+		return nil, errTaskNotFound(taskName)
+	}
+	if task.Type != config.TaskTypeDaemon {
+		return nil, errNotDaemon(taskName)
+	}
+	if !task.Reload {
+		return nil, codedErrorf(ErrCodeConfig, "task '%s' does not support reload (set reload: true on the task)", taskName)
+	}
+
+	spm, ok := m.processManager.(SignalingProcessManager)
+	if !ok {
+		return nil, fmt.Errorf("process manager does not support signaling daemons")
+	}
+	signal := "SIG" + task.ReloadSignalName()
+	running, pid, err := m.processManager.Status(taskName)
+	if err != nil {
+		return &DaemonReloadResult{Success: false, Error: fmt.Sprintf("failed to check status: %v", err)}, nil
+	}
+	if !running {
+		return &DaemonReloadResult{Success: false, Error: fmt.Sprintf("daemon '%s' is not running", taskName)}, nil
+	}
+	if err := spm.Signal(taskName, task.ReloadSignalName()); err != nil {
+		return &DaemonReloadResult{Success: false, Signal: signal, Error: err.Error()}, nil
+	}
+
+	sessionID, _ := m.processManager.GetSessionID(taskName)
+	return &DaemonReloadResult{
+		Success:   true,
+		Signal:    signal,
+		PID:       pid,
+		SessionID: sessionID,
+		Message:   fmt.Sprintf("sent %s to daemon '%s'", signal, taskName),
+	}, nil
+}
@@ -0,0 +1,111 @@
+package task
+
+import (
+	"fmt"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/template"
+)
+
+// ResolvedTask describes exactly what the Executor would run for a task
+// invocation, after parameter defaults and template substitution are applied.
+type ResolvedTask struct {
+	TaskName   string
+	Command    string
+	Shell      string
+	WorkingDir string
+	Env        map[string]string
+	Params     map[string]interface{}
+}
+
+// Resolve applies parameter defaults and substitutes parameters into the
+// task's command without executing anything.
+func (e *Executor) Resolve(taskName string, params map[string]interface{}) (*ResolvedTask, error) {
+	task, exists := e.manifest.Tasks[taskName]
+	if !exists {
+		return nil, errTaskNotFound(taskName)
+	}
+
+	params = e.applyDefaults(task, params)
+	if err := checkParams(taskName, task, params); err != nil {
+		return nil, err
+	}
+
+	vars, err := template.ResolveVars(e.manifest.Vars)
+	if err != nil {
+		return nil, err
+	}
+	command, err := template.SubstituteParameters(task.Command, template.CommandData(params, vars))
+	if err != nil {
+		return nil, fmt.Errorf("parameter substitution failed: %w", err)
+	}
+
+	shell := task.Shell
+	if shell == "" {
+		shell = "/bin/bash"
+	}
+
+	return &ResolvedTask{
+		TaskName:   taskName,
+		Command:    command,
+		Shell:      shell,
+		WorkingDir: resolveWorkingDirectory(task, params),
+		Env:        task.Env,
+		Params:     params,
+	}, nil
+}
+
+// Resolve resolves every step of a workflow into the task invocation it
+// would execute, in order, with the steps of nested workflows in place.
+// Steps are not executed, so references to earlier step output are left as
+// written. wait and wait_for steps run no task and are left out.
+func (we *WorkflowExecutor) Resolve(workflowName string, params map[string]interface{}) ([]*ResolvedTask, error) {
+	workflow, exists := we.manifest.Workflows[workflowName]
+	if !exists {
+		return nil, fmt.Errorf("workflow '%s' not found", workflowName)
+	}
+
+	resolvedParams := applyWorkflowDefaults(workflow, params)
+	if err := config.CheckParamValues(workflow.Parameters, resolvedParams); err != nil {
+		return nil, fmt.Errorf("workflow '%s': %w", workflowName, err)
+	}
+	workflowWorkingDir := resolveWorkflowWorkingDirectory(workflow, resolvedParams)
+
+	var resolved []*ResolvedTask
+	for i, step := range workflow.Steps {
+		if step.Builtin() {
+			continue
+		}
+		stepParams := resolveStepParams(step.Params, resolvedParams, nil)
+		if workflowWorkingDir != "" {
+			stepParams["working_directory"] = workflowWorkingDir
+		}
+
+		if step.Workflow != "" {
+			nested, err := we.Resolve(step.Workflow, stepParams)
+			if err != nil {
+				return nil, fmt.Errorf("step %d (%s): %w", i, step.Workflow, err)
+			}
+			resolved = append(resolved, nested...)
+			continue
+		}
+
+		rt, err := we.executor.Resolve(step.Task, stepParams)
+		if err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", i, step.Task, err)
+		}
+		resolved = append(resolved, rt)
+	}
+
+	return resolved, nil
+}
+
+// Resolve resolves a task invocation (one-shot or daemon) without executing it.
+func (m *Manager) Resolve(taskName string, params map[string]interface{}) (*ResolvedTask, error) {
+	return m.executor.Resolve(taskName, params)
+}
+
+// ResolveWorkflow resolves all steps of a workflow without executing them.
+func (m *Manager) ResolveWorkflow(workflowName string, params map[string]interface{}) ([]*ResolvedTask, error) {
+	return m.workflowExecutor.Resolve(workflowName, params)
+}
@@ -129,6 +129,9 @@ func TestSessionBasedLoggingIntegration(t *testing.T) {
 	if metadata.TimedOut {
 		t.Error("Metadata TimedOut should be false")
 	}
+	if metadata.Resources == nil || result.Resources == nil {
+		t.Error("Resource usage was not recorded")
+	}
 	t.Logf("✓ Metadata validated successfully")
 
 	// Verify log file exists and has content
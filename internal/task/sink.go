@@ -0,0 +1,32 @@
+package task
+
+import (
+	"os"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+)
+
+// newSessionSink builds the sink for defaults.session_sink, or returns nil
+// when it is not configured.
+func newSessionSink(cfg *config.SessionSink) logs.SessionSink {
+	if cfg == nil {
+		return nil
+	}
+	var sinks logs.MultiSink
+	if cfg.File != "" {
+		sinks = append(sinks, &logs.FileSink{Path: cfg.File})
+	}
+	if cfg.URL != "" {
+		webhook := &logs.WebhookSink{URL: cfg.URL, Timeout: time.Duration(cfg.Timeout) * time.Second}
+		if cfg.TokenEnv != "" {
+			webhook.Token = os.Getenv(cfg.TokenEnv)
+		}
+		sinks = append(sinks, webhook)
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	return sinks
+}
@@ -0,0 +1,89 @@
+package task
+
+import (
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestWorkflowStepOutputPiping(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"get_version": {
+				Description: "Print version",
+				Command:     "echo 1.2.3",
+				Type:        config.TaskTypeOneShot,
+			},
+			"tag": {
+				Description: "Tag a release",
+				Command:     "echo tagging v{{.version}} after exit {{.code}}",
+				Type:        config.TaskTypeOneShot,
+			},
+		},
+		Workflows: map[string]config.Workflow{
+			"release": {
+				Description: "Release",
+				Steps: []config.WorkflowStep{
+					{Task: "get_version"},
+					{
+						ID:   "tag_it",
+						Task: "tag",
+						Params: map[string]string{
+							"version": "{{ steps.get_version.stdout }}",
+							"code":    "{{steps.get_version.exit_code}}",
+						},
+					},
+					{
+						Task: "tag",
+						Params: map[string]string{
+							"version": "{{ steps.tag_it.stdout }}",
+							"code":    "{{ steps.tag_it.exit_code }}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	we := NewWorkflowExecutor(NewExecutor(manifest), manifest)
+	result, err := we.Execute("release", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %s", result.Error)
+	}
+	if got := result.Steps[1].Result.Stdout; got != "tagging v1.2.3 after exit 0\n" {
+		t.Errorf("step 1 stdout = %q", got)
+	}
+	if got := result.Steps[2].Result.Stdout; got != "tagging vtagging v1.2.3 after exit 0 after exit 0\n" {
+		t.Errorf("step 2 stdout = %q", got)
+	}
+}
+
+func TestSubstituteStepOutputs(t *testing.T) {
+	outputs := map[string]*ExecutionResult{
+		"build": {Stdout: "out\n\n", Stderr: "warn\n", ExitCode: 2},
+	}
+
+	tests := []struct {
+		tmpl    string
+		outputs map[string]*ExecutionResult
+		want    string
+	}{
+		{"{{ steps.build.stdout }}", outputs, "out"},
+		{"{{ steps.build.stderr }}", outputs, "warn"},
+		{"code={{ steps.build.exit_code }}", outputs, "code=2"},
+		{"{{ steps.missing.stdout }}", outputs, ""},
+		{"{{ steps.build.stdout }}", nil, "{{ steps.build.stdout }}"},
+	}
+	for _, tt := range tests {
+		if got := substituteStepOutputs(tt.tmpl, tt.outputs); got != tt.want {
+			t.Errorf("substituteStepOutputs(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
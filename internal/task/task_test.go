@@ -3,7 +3,10 @@ package task
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 )
 
 type MockProcessManager struct {
+	mu          sync.Mutex
 	processes   map[string]*mockProcess
 	capturedCwd string
 }
@@ -30,6 +34,8 @@ func NewMockProcessManager() *MockProcessManager {
 }
 
 func (m *MockProcessManager) Start(taskName string, sessionID string, cmd string, env map[string]string, cwd string, logPath string, shell string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if _, exists := m.processes[taskName]; exists && m.processes[taskName].running {
 		return fmt.Errorf("process already running")
 	}
@@ -44,6 +50,8 @@ func (m *MockProcessManager) Start(taskName string, sessionID string, cmd string
 }
 
 func (m *MockProcessManager) GetCommand(taskName string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if proc, exists := m.processes[taskName]; exists {
 		return proc.command, nil
 	}
@@ -51,6 +59,8 @@ func (m *MockProcessManager) GetCommand(taskName string) (string, error) {
 }
 
 func (m *MockProcessManager) Stop(taskName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if proc, exists := m.processes[taskName]; exists && proc.running {
 		proc.running = false
 		return nil
@@ -59,6 +69,8 @@ func (m *MockProcessManager) Stop(taskName string) error {
 }
 
 func (m *MockProcessManager) Status(taskName string) (bool, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if proc, exists := m.processes[taskName]; exists {
 		return proc.running, proc.pid, nil
 	}
@@ -66,6 +78,8 @@ func (m *MockProcessManager) Status(taskName string) (bool, int, error) {
 }
 
 func (m *MockProcessManager) StopAll() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	for _, proc := range m.processes {
 		proc.running = false
 	}
@@ -73,6 +87,8 @@ func (m *MockProcessManager) StopAll() error {
 }
 
 func (m *MockProcessManager) GetSessionID(taskName string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if proc, exists := m.processes[taskName]; exists {
 		return proc.sessionID, nil
 	}
@@ -974,3 +990,313 @@ func TestWorkingDirectoryResolutionDaemon(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		task   config.Task
+		params map[string]interface{}
+		want   int
+	}{
+		{"task timeout", config.Task{Timeout: 30}, map[string]interface{}{}, 30},
+		{"override", config.Task{Timeout: 30}, map[string]interface{}{"timeout": float64(120)}, 120},
+		{"override shortens", config.Task{Timeout: 30}, map[string]interface{}{"timeout": 5}, 5},
+		{"capped at max_timeout", config.Task{Timeout: 30, MaxTimeout: 60}, map[string]interface{}{"timeout": float64(600)}, 60},
+		{"capped at default max", config.Task{}, map[string]interface{}{"timeout": float64(99999)}, DefaultMaxTimeout},
+		{"non-positive ignored", config.Task{Timeout: 30}, map[string]interface{}{"timeout": float64(0)}, 30},
+		{"task parameter wins", config.Task{Timeout: 30, Parameters: map[string]config.Param{"timeout": {Type: "number"}}}, map[string]interface{}{"timeout": float64(120)}, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveTimeout(tt.task, tt.params); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExecutorEnvPolicy(t *testing.T) {
+	defer setupWorkflowTest(t)()
+	t.Setenv("RUNBOOK_HOST_SECRET", "leaked")
+	t.Setenv("RUNBOOK_HOST_KEPT", "kept")
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"show": {
+				Command:   `echo "secret=$RUNBOOK_HOST_SECRET kept=$RUNBOOK_HOST_KEPT own=$OWN"`,
+				Type:      config.TaskTypeOneShot,
+				Timeout:   30,
+				Env:       map[string]string{"OWN": "set"},
+				EnvPolicy: &config.EnvPolicy{Mode: config.EnvPolicyDenylist, Vars: []string{"RUNBOOK_HOST_SECRET"}},
+			},
+		},
+	}
+	result, err := NewExecutor(manifest).Execute("show", nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got := strings.TrimSpace(result.Stdout); got != "secret= kept=kept own=set" {
+		t.Errorf("expected the denied variable to be withheld, got %q", got)
+	}
+}
+
+func TestExecutorExitCodeClassification(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	grep := config.Task{
+		Type:              config.TaskTypeOneShot,
+		Timeout:           30,
+		ExpectedExitCodes: map[int]string{1: "no matches"},
+		WarningExitCodes:  map[int]string{2: "lint warnings"},
+	}
+	tests := []struct {
+		command string
+		success bool
+		status  string
+		reason  string
+	}{
+		{"exit 0", true, StatusSuccess, ""},
+		{"exit 1", true, StatusSuccess, "no matches"},
+		{"exit 2", true, StatusWarning, "lint warnings"},
+		{"exit 3", false, StatusFailure, ""},
+	}
+	for _, tt := range tests {
+		task := grep
+		task.Command = tt.command
+		manifest := &config.Manifest{Version: "1.0", Tasks: map[string]config.Task{"grep": task}}
+		result, err := NewExecutor(manifest).Execute("grep", nil)
+		if err != nil {
+			t.Fatalf("%s: Execute: %v", tt.command, err)
+		}
+		if result.Success != tt.success || result.Status != tt.status || result.StatusReason != tt.reason {
+			t.Errorf("%s: got success=%v status=%q reason=%q, want %v %q %q", tt.command, result.Success, result.Status, result.StatusReason, tt.success, tt.status, tt.reason)
+		}
+	}
+
+	// Results that never ran a command still get a status
+	manifest := &config.Manifest{Version: "1.0", Tasks: map[string]config.Task{
+		"broken": {Type: config.TaskTypeOneShot, Command: "echo {{.missing}}"},
+	}}
+	result, _ := NewExecutor(manifest).Execute("broken", nil)
+	if result.Status != StatusFailure {
+		t.Errorf("expected failure status for a substitution error, got %q", result.Status)
+	}
+}
+
+func TestExecutorInterpreterShell(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"sh":     {Type: config.TaskTypeOneShot, Timeout: 30, Shell: "/bin/sh", Command: "echo $((6 * 7))"},
+			"strict": {Type: config.TaskTypeOneShot, Timeout: 30, Shell: "sh -ec", Command: "false; echo unreachable"},
+			"python": {Type: config.TaskTypeOneShot, Timeout: 30, Shell: "python3 -c", Command: "import sys\nprint(sys.version_info[0] * 14)"},
+		},
+	}
+	executor := NewExecutor(manifest)
+
+	result, err := executor.Execute("sh", nil)
+	if err != nil || strings.TrimSpace(result.Stdout) != "42" {
+		t.Errorf("expected a bare shell to run with -c, got %+v (%v)", result, err)
+	}
+
+	result, err = executor.Execute("strict", nil)
+	if err != nil || result.Success || strings.Contains(result.Stdout, "unreachable") {
+		t.Errorf("expected the interpreter's own flags to apply, got %+v (%v)", result, err)
+	}
+
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not installed")
+	}
+	result, err = executor.Execute("python", nil)
+	if err != nil || strings.TrimSpace(result.Stdout) != "42" {
+		t.Errorf("expected the script to run with python3 -c, got %+v (%v)", result, err)
+	}
+}
+
+func TestExecutorInteractiveTask(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"ask": {Command: `read -r name; echo "hello $name"`, Type: config.TaskTypeOneShot, Timeout: 30, Interactive: true},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	if _, err := manager.ExecuteOneShot("ask", nil); err == nil || !strings.Contains(err.Error(), "needs a terminal") {
+		t.Fatalf("expected an interactive task to be rejected without a terminal, got %v", err)
+	}
+
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.WriteString("world\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetTerminal(&Terminal{Stdin: stdin, Stdout: stdout, Stderr: stdout})
+
+	result, err := manager.ExecuteOneShot("ask", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("expected the task to run on the terminal, got %+v (%v)", result, err)
+	}
+	if !result.Streamed || result.Stdout != "" {
+		t.Errorf("expected output to go to the terminal only, got %+v", result)
+	}
+	written, _ := os.ReadFile(stdout.Name())
+	if string(written) != "hello world\n" {
+		t.Errorf("expected the task to read and write the terminal, got %q", written)
+	}
+}
+
+func TestExecutorJSONOutput(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"report":  {Command: `echo '{"passed": 3, "failed": []}'`, Type: config.TaskTypeOneShot, OutputFormat: config.OutputFormatJSON},
+			"garbled": {Command: "echo not json", Type: config.TaskTypeOneShot, OutputFormat: config.OutputFormatJSON},
+			"silent":  {Command: "true", Type: config.TaskTypeOneShot, OutputFormat: config.OutputFormatJSON},
+		},
+	}
+	manager := NewManager(manifest, NewMockProcessManager())
+
+	result, err := manager.ExecuteOneShot("report", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("report failed: %+v (%v)", result, err)
+	}
+	if string(result.Output) != `{"passed":3,"failed":[]}` || result.OutputError != "" {
+		t.Errorf("expected the parsed output, got %s (%q)", result.Output, result.OutputError)
+	}
+
+	result, err = manager.ExecuteOneShot("garbled", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("expected invalid JSON not to fail the run, got %+v (%v)", result, err)
+	}
+	if result.Output != nil || !strings.Contains(result.OutputError, "not valid JSON") {
+		t.Errorf("expected an output error, got %s (%q)", result.Output, result.OutputError)
+	}
+
+	result, err = manager.ExecuteOneShot("silent", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OutputError != "stdout is empty" {
+		t.Errorf("expected an empty stdout error, got %q", result.OutputError)
+	}
+}
+
+func TestExecutorVars(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	t.Setenv("RUNBOOK_TEST_REGISTRY", "registry.example.com")
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"push": {
+				Description: "Push the image",
+				Command:     "echo {{.Vars.registry}}/{{.Vars.image}}:{{.tag}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters:  map[string]config.Param{"tag": {Type: "string", Required: true}},
+			},
+		},
+		Vars: map[string]string{
+			"registry": `{{env "RUNBOOK_TEST_REGISTRY"}}`,
+			"image":    "api",
+		},
+	}
+
+	result, err := NewExecutor(manifest).Execute("push", map[string]interface{}{"tag": "v1"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.Success || strings.TrimSpace(result.Stdout) != "registry.example.com/api:v1" {
+		t.Errorf("success = %v, stdout = %q, error = %q", result.Success, result.Stdout, result.Error)
+	}
+	metadata, err := logs.ReadSessionMetadata(result.SessionID)
+	if err != nil {
+		t.Fatalf("read metadata: %v", err)
+	}
+	if _, ok := metadata.Parameters[config.VarsKey]; ok {
+		t.Errorf("session parameters = %v, want no vars", metadata.Parameters)
+	}
+}
+
+func TestExecutorRejectsInvalidParams(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"deploy": {
+				Description: "Deploy",
+				Command:     "touch deployed-{{.env}}",
+				Type:        config.TaskTypeOneShot,
+				Parameters: map[string]config.Param{
+					"env": {Type: "string", Required: true, Enum: []string{"staging", "prod"}},
+				},
+			},
+		},
+	}
+
+	result, err := NewExecutor(manifest).Execute("deploy", map[string]interface{}{"env": "x; rm -rf ."})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "parameter 'env' must be one of staging, prod") {
+		t.Errorf("success = %v, error = %q, want an enum error", result.Success, result.Error)
+	}
+	if matches, _ := filepath.Glob("deployed-*"); len(matches) > 0 {
+		t.Errorf("command ran: %v", matches)
+	}
+}
+
+func TestExecutorLogsStreamsSeparately(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := logs.Setup(); err != nil {
+		t.Fatalf("failed to setup logs: %v", err)
+	}
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"build": {Description: "Build", Command: "echo compiling; sleep 0.1; echo 'warning: unused' >&2; sleep 0.1; echo done", Type: config.TaskTypeOneShot},
+		},
+	}
+
+	result, err := NewExecutor(manifest).Execute("build", nil)
+	if err != nil || !result.Success {
+		t.Fatalf("Execute() = %+v, %v", result, err)
+	}
+	if result.Stdout != "compiling\ndone\n" || result.Stderr != "warning: unused\n" {
+		t.Errorf("stdout = %q, stderr = %q", result.Stdout, result.Stderr)
+	}
+	for stream, want := range map[string]string{
+		"":                "compiling\nwarning: unused\ndone",
+		logs.StreamStdout: "compiling\ndone",
+		logs.StreamStderr: "warning: unused",
+	} {
+		lines, _, err := logs.ReadSessionLog(result.SessionID, logs.ReadOptions{Stream: stream})
+		if err != nil {
+			t.Fatalf("ReadSessionLog(%q) error = %v", stream, err)
+		}
+		if got := strings.Join(lines, "\n"); got != want {
+			t.Errorf("stream %q = %q, want %q", stream, got, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package task
+
+import (
+	"os"
+	"os/signal"
+)
+
+// Terminal is the terminal interactive oneshot tasks run on. The CLI sets it
+// for local runs; without one, interactive tasks are rejected, so MCP and
+// REST callers get an error instead of a process waiting on input no one can
+// give.
+type Terminal struct {
+	Stdin  *os.File
+	Stdout *os.File
+	Stderr *os.File
+}
+
+// SetTerminal runs interactive oneshot tasks on t.
+func (m *Manager) SetTerminal(t *Terminal) {
+	m.executor.terminal = t
+}
+
+// errNeedsTerminal is returned for an interactive task run without a
+// terminal.
+func errNeedsTerminal(taskName string) error {
+	return codedErrorf(ErrCodePermission, "task '%s' is interactive and needs a terminal; run it from a shell with 'runbook run --local %s'", taskName, taskName)
+}
+
+// ignoreInterrupts keeps Ctrl-C from stopping runbook while an interactive
+// task has the terminal: the task gets the signal and decides what it means.
+// Call the returned function when the task exits.
+func ignoreInterrupts() func() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		for range interrupts {
+		}
+	}()
+	return func() {
+		signal.Stop(interrupts)
+		close(interrupts)
+	}
+}
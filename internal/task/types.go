@@ -1,24 +1,70 @@
 package task
 
 import (
+	"encoding/json"
 	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+// Result statuses. A warning is a success whose exit code the task lists in
+// warning_exit_codes.
+const (
+	StatusSuccess = "success"
+	StatusWarning = "warning"
+	StatusFailure = "failure"
 )
 
 // ExecutionResult represents the result of a task execution
 type ExecutionResult struct {
 	Success      bool          `json:"success"`
+	Status       string        `json:"status"`                  // StatusSuccess, StatusWarning, or StatusFailure
+	StatusReason string        `json:"status_reason,omitempty"` // What a classified exit code means, e.g. "no matches"
 	ExitCode     int           `json:"exit_code"`
 	Stdout       string        `json:"stdout,omitempty"`
+	StdoutTotalLines int       `json:"stdout_total_lines,omitempty"` // Lines stdout had before log_output cut it
+	StdoutLog    string        `json:"stdout_log,omitempty"`         // Session log with all of stdout, set when log_output cut it
 	Stderr       string        `json:"stderr,omitempty"`
 	Duration     time.Duration `json:"duration"`
 	Error        string        `json:"error,omitempty"`
+	ErrorCode    string        `json:"error_code,omitempty"` // Kind of failure, one of the ErrCode constants
 	TaskName     string        `json:"task_name"`
 	LogPath      string        `json:"log_path,omitempty"`
 	TimedOut     bool          `json:"timed_out"`
 	SessionID    string        `json:"session_id,omitempty"`
+	DaemonsStarted []string    `json:"daemons_started,omitempty"`
+	Resources    *logs.ResourceUsage `json:"resources,omitempty"`
+	Timeout      int           `json:"timeout,omitempty"` // Timeout applied to the run, in seconds
+	Workdir      *logs.WorkdirFingerprint `json:"workdir_fingerprint,omitempty"` // Code state of a call-time working_directory
+	Artifacts    []logs.Artifact `json:"artifacts,omitempty"` // Files copied into the session's artifacts directory
+	Output       json.RawMessage `json:"output,omitempty"`       // Stdout parsed as JSON, for output_format: json
+	OutputError  string          `json:"output_error,omitempty"` // Why stdout could not be parsed as output
+	RetryAfter   int             `json:"retry_after,omitempty"`  // Seconds until a run refused by rate_limit is allowed
+	PreconditionFailed *PreconditionFailure `json:"precondition_failed,omitempty"` // The check that kept the command from running
+	Agent        string          `json:"agent,omitempty"`        // Remote agent that ran the command, for agent runners
+	Cached       bool            `json:"cached,omitempty"`       // Stored result of an earlier run with the same cache key
 	Streamed     bool          `json:"-"`
 }
 
+// PreconditionFailure describes the precondition that failed before a task's
+// command ran.
+type PreconditionFailure struct {
+	Index   int    `json:"index"`             // Position in the task's preconditions list
+	Check   string `json:"check"`             // "command", "file_exists", or "port_free"
+	Target  string `json:"target"`            // The command, path, or port that was checked
+	Detail  string `json:"detail"`            // What went wrong
+	Output  string `json:"output,omitempty"`  // Combined output of a failed command check
+	Message string `json:"message,omitempty"` // The precondition's configured hint
+}
+
+// resultStatus is the status of a result that no exit code classified.
+func resultStatus(success bool) string {
+	if success {
+		return StatusSuccess
+	}
+	return StatusFailure
+}
+
 // DaemonStatus represents the status of a daemon task
 type DaemonStatus struct {
 	Running   bool      `json:"running"`
@@ -27,6 +73,11 @@ type DaemonStatus struct {
 	Uptime    string    `json:"uptime,omitempty"`
 	LogPath   string    `json:"log_path"`
 	SessionID string    `json:"session_id,omitempty"`
+	LastEvents []logs.DaemonEvent `json:"last_events,omitempty"`
+	LastExit   *logs.DaemonEvent  `json:"last_exit,omitempty"` // Most recent time the daemon exited or crashed on its own
+	Usage      *logs.GroupUsage   `json:"usage,omitempty"`     // Live resources of the daemon's process group
+	Ports      []int              `json:"ports,omitempty"`     // TCP ports the running daemon declared
+	Services   []ComposeService   `json:"services,omitempty"` // Compose tasks: containers of the stack
 }
 
 // DaemonStartResult represents the result of starting a daemon
@@ -35,14 +86,45 @@ type DaemonStartResult struct {
 	PID       int    `json:"pid"`
 	LogPath   string `json:"log_path"`
 	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"` // Kind of failure, one of the ErrCode constants
 	SessionID string `json:"session_id,omitempty"`
+	Restarted bool   `json:"restarted,omitempty"` // A fresh start stopped a running instance first
+	RetryAfter int   `json:"retry_after,omitempty"` // Seconds until a start refused by rate_limit is allowed
 }
 
 // DaemonStopResult represents the result of stopping a daemon
 type DaemonStopResult struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"` // Kind of failure, one of the ErrCode constants
+}
+
+// DaemonBulkResult is the outcome of a bulk daemon operation for one daemon.
+type DaemonBulkResult struct {
+	Task      string `json:"task"`
+	Success   bool   `json:"success"`
+	Action    string `json:"action"` // "stopped", "restarted", or "skipped"
+	PID       int    `json:"pid,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// NamedDaemonStatus is the status of one daemon in a bulk status result.
+type NamedDaemonStatus struct {
+	Task  string `json:"task"`
+	Error string `json:"error,omitempty"`
+	DaemonStatus
+}
+
+// DaemonInputResult represents the result of sending input to a daemon
+type DaemonInputResult struct {
+	Success   bool   `json:"success"`
+	Bytes     int    `json:"bytes"`
+	SessionID string `json:"session_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"` // Kind of failure, one of the ErrCode constants
 }
 
 // WorkflowStepResult represents the result of a single workflow step
@@ -51,6 +133,8 @@ type WorkflowStepResult struct {
 	TaskName  string           `json:"task_name"`
 	Result    *ExecutionResult `json:"result,omitempty"`
 	Skipped   bool             `json:"skipped"`
+	Attempts  int              `json:"attempts,omitempty"` // Times the step ran, including retries
+	SkipReason string          `json:"skip_reason,omitempty"` // Why the step did not run, when skipped by its when expression
 }
 
 // WorkflowResult represents the aggregated result of a workflow execution
@@ -60,6 +144,7 @@ type WorkflowResult struct {
 	Steps        []WorkflowStepResult `json:"steps"`
 	Duration     time.Duration        `json:"duration"`
 	Error        string               `json:"error,omitempty"`
+	ErrorCode    string               `json:"error_code,omitempty"` // Error code of the step that failed the workflow
 	StepsRun     int                  `json:"steps_run"`
 	StepsFailed  int                  `json:"steps_failed"`
 }
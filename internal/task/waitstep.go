@@ -0,0 +1,122 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/template"
+)
+
+// runBuiltinStep runs a wait or wait_for step. data is what url and file
+// are rendered with: the workflow parameters and earlier steps, as for when
+// expressions. Relative files resolve against workingDir. The step fails
+// when ctx is done first.
+func runBuiltinStep(ctx context.Context, step config.WorkflowStep, data map[string]interface{}, workingDir string) (*ExecutionResult, error) {
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, time.Duration(step.Timeout)*time.Second,
+			fmt.Errorf("step timed out after %d seconds", step.Timeout))
+		defer cancel()
+	}
+
+	start := time.Now()
+	result := &ExecutionResult{TaskName: step.Target()}
+	var err error
+	if step.Wait != nil {
+		err = sleepContext(ctx, time.Duration(step.Wait.Seconds*float64(time.Second)))
+		result.Stdout = fmt.Sprintf("waited %s\n", time.Since(start).Round(time.Millisecond))
+	} else {
+		var check waitCondition
+		check, err = newWaitCondition(step.WaitFor, data, workingDir)
+		if err == nil {
+			err = check.wait(ctx)
+			result.Stdout = fmt.Sprintf("%s ready after %s\n", check, time.Since(start).Round(time.Millisecond))
+		}
+	}
+
+	result.Duration = time.Since(start)
+	result.Success = err == nil
+	result.Status = resultStatus(result.Success)
+	if err != nil {
+		result.Stdout = ""
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// waitCondition is a wait_for step with its url and file rendered.
+type waitCondition struct {
+	ready   config.ReadyCheck
+	file    string
+	timeout time.Duration
+}
+
+// newWaitCondition renders the url and file of w with data.
+func newWaitCondition(w *config.StepWaitFor, data map[string]interface{}, workingDir string) (waitCondition, error) {
+	c := waitCondition{ready: config.ReadyCheck{Port: w.Port}, timeout: defaultReadyTimeout}
+	if w.Timeout > 0 {
+		c.timeout = time.Duration(w.Timeout) * time.Second
+	}
+	var err error
+	if c.ready.URL, err = template.SubstituteParameters(w.URL, data); err != nil {
+		return c, fmt.Errorf("wait_for.url: %w", err)
+	}
+	if c.file, err = template.SubstituteParameters(w.File, data); err != nil {
+		return c, fmt.Errorf("wait_for.file: %w", err)
+	}
+	if c.file != "" && !filepath.IsAbs(c.file) && workingDir != "" {
+		c.file = filepath.Join(workingDir, c.file)
+	}
+	return c, nil
+}
+
+// String describes what the condition waits for.
+func (c waitCondition) String() string {
+	switch {
+	case c.ready.URL != "":
+		return "url " + c.ready.URL
+	case c.ready.Port > 0:
+		return fmt.Sprintf("port %d", c.ready.Port)
+	}
+	return "file " + c.file
+}
+
+// wait polls the condition until it holds, its timeout passes, or ctx is
+// done.
+func (c waitCondition) wait(ctx context.Context) error {
+	deadline := time.Now().Add(c.timeout)
+	for {
+		ready, reason := checkReady(&c.ready, "")
+		if ready && c.file != "" {
+			if _, err := os.Stat(c.file); err != nil {
+				ready, reason = false, fmt.Sprintf("file %s does not exist", c.file)
+			}
+		}
+		if ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("wait_for not satisfied after %s: %s", c.timeout, reason)
+		}
+		if err := sleepContext(ctx, readyPollInterval); err != nil {
+			return fmt.Errorf("%w: %s", err, reason)
+		}
+	}
+}
+
+// sleepContext sleeps for d, or returns the cause when ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
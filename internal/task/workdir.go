@@ -0,0 +1,70 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"runbookmcp.dev/internal/config"
+)
+
+// checkParams checks the arguments of a run of task before they are
+// substituted into its command: their parameter rules, then the working
+// directory.
+func checkParams(taskName string, task config.Task, params map[string]interface{}) error {
+	if err := config.CheckParamValues(task.Parameters, params); err != nil {
+		return fmt.Errorf("task '%s': %w", taskName, err)
+	}
+	return checkWorkingDirectory(taskName, task, params)
+}
+
+// checkWorkingDirectory rejects a working_directory argument that matches
+// none of the task's allowed_working_directories. Tasks without the list
+// accept any directory, and the static working_directory is always allowed.
+// Paths and patterns are compared as absolute paths with symlinks resolved,
+// so "../" and links cannot step outside the allowed directories.
+func checkWorkingDirectory(taskName string, task config.Task, params map[string]interface{}) error {
+	if !task.ExposeWorkingDirectory || len(task.AllowedWorkingDirectories) == 0 {
+		return nil
+	}
+	wd, ok := params["working_directory"].(string)
+	if !ok || wd == "" {
+		return nil
+	}
+
+	dir := resolvePath(wd)
+	for _, pattern := range task.AllowedWorkingDirectories {
+		if matched, _ := filepath.Match(resolvePattern(pattern), dir); matched {
+			return nil
+		}
+	}
+	return codedErrorf(ErrCodePermission, "task '%s': working directory '%s' is not in allowed_working_directories", taskName, wd)
+}
+
+// resolvePath returns path as an absolute, clean path with symlinks
+// resolved, or unresolved if it does not exist.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// resolvePattern makes a glob absolute and resolves symlinks in its leading
+// directories, up to the first one containing a glob character.
+func resolvePattern(pattern string) string {
+	abs, err := filepath.Abs(pattern)
+	if err != nil {
+		return pattern
+	}
+	prefix, rest := abs, ""
+	for strings.ContainsAny(prefix, "*?[") && filepath.Dir(prefix) != prefix {
+		rest = filepath.Join(filepath.Base(prefix), rest)
+		prefix = filepath.Dir(prefix)
+	}
+	return filepath.Join(resolvePath(prefix), rest)
+}
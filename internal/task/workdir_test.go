@@ -0,0 +1,84 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+)
+
+func TestCheckWorkingDirectory(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"services/api", "services/web", "secrets"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(root, "secrets"), filepath.Join(root, "services", "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	task := config.Task{
+		ExposeWorkingDirectory:    true,
+		AllowedWorkingDirectories: []string{filepath.Join(root, "services", "*")},
+	}
+	tests := []struct {
+		dir     string
+		allowed bool
+	}{
+		{filepath.Join(root, "services", "api"), true},
+		{filepath.Join(root, "services", "web") + "/", true},
+		{filepath.Join(root, "secrets"), false},
+		{filepath.Join(root, "services", "api", "..", "..", "secrets"), false},
+		{filepath.Join(root, "services", "link"), false},
+		{"/", false},
+		{"", true},
+	}
+	for _, tt := range tests {
+		err := checkWorkingDirectory("test", task, map[string]interface{}{"working_directory": tt.dir})
+		if (err == nil) != tt.allowed {
+			t.Errorf("%q: allowed=%v, got err=%v", tt.dir, tt.allowed, err)
+		}
+	}
+
+	// Without the list any directory is accepted
+	task.AllowedWorkingDirectories = nil
+	if err := checkWorkingDirectory("test", task, map[string]interface{}{"working_directory": "/"}); err != nil {
+		t.Errorf("expected no restriction without allowed_working_directories, got %v", err)
+	}
+}
+
+func TestExecutorRejectsWorkingDirectory(t *testing.T) {
+	defer setupWorkflowTest(t)()
+	if err := os.Mkdir("app", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"pwd": {
+				Command:                   "pwd",
+				Type:                      config.TaskTypeOneShot,
+				ExposeWorkingDirectory:    true,
+				AllowedWorkingDirectories: []string{"app"},
+			},
+		},
+	}
+	executor := NewExecutor(manifest)
+
+	result, err := executor.Execute("pwd", map[string]interface{}{"working_directory": "app"})
+	if err != nil || !result.Success || !strings.HasSuffix(strings.TrimSpace(result.Stdout), "app") {
+		t.Errorf("expected an allowed directory to run, got %+v (%v)", result, err)
+	}
+
+	result, err = executor.Execute("pwd", map[string]interface{}{"working_directory": "/"})
+	if err != nil || result.Success || !strings.Contains(result.Error, "not in allowed_working_directories") {
+		t.Errorf("expected / to be rejected, got %+v (%v)", result, err)
+	}
+	if result.SessionID != "" {
+		t.Errorf("expected a rejected call not to start a session, got %s", result.SessionID)
+	}
+}
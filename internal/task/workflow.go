@@ -3,16 +3,23 @@ package task
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/template"
 )
 
 // WorkflowExecutor handles execution of composite workflows
 type WorkflowExecutor struct {
 	executor *Executor
 	manifest *config.Manifest
+	// ensureDaemons starts (or, when fresh, restarts) and waits on required
+	// daemons; set by the Manager so steps with requires_daemon can use its
+	// process manager.
+	ensureDaemons func(names []string, fresh bool) ([]string, error)
 }
 
 // NewWorkflowExecutor creates a new workflow executor
@@ -25,27 +32,35 @@ func NewWorkflowExecutor(executor *Executor, manifest *config.Manifest) *Workflo
 
 // Execute runs a workflow by name with the given parameters
 func (we *WorkflowExecutor) Execute(workflowName string, params map[string]interface{}) (*WorkflowResult, error) {
+	return we.execute(context.Background(), workflowName, params)
+}
+
+// execute runs a workflow, interrupting the running step when the workflow
+// times out or parent is done.
+func (we *WorkflowExecutor) execute(parent context.Context, workflowName string, params map[string]interface{}) (*WorkflowResult, error) {
 	workflow, exists := we.manifest.Workflows[workflowName]
 	if !exists {
-		return nil, fmt.Errorf("workflow '%s' not found", workflowName)
+		return nil, codedErrorf(ErrCodeNotFound, "workflow '%s' not found", workflowName)
 	}
 
 	startTime := time.Now()
 
 	// Apply workflow-level parameter defaults
 	resolvedParams := applyWorkflowDefaults(workflow, params)
+	if err := config.CheckParamValues(workflow.Parameters, resolvedParams); err != nil {
+		return nil, &CodedError{Code: ErrCodeConfig, Err: fmt.Errorf("workflow '%s': %w", workflowName, err)}
+	}
 
 	// Resolve workflow-level working directory
 	workflowWorkingDir := resolveWorkflowWorkingDirectory(workflow, resolvedParams)
 
 	// Create workflow-level timeout context if configured
-	var ctx context.Context
-	var cancel context.CancelFunc
+	ctx := parent
 	if workflow.Timeout > 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(workflow.Timeout)*time.Second)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(parent, time.Duration(workflow.Timeout)*time.Second,
+			fmt.Errorf("workflow timed out after %d seconds", workflow.Timeout))
 		defer cancel()
-	} else {
-		ctx = context.Background()
 	}
 
 	result := &WorkflowResult{
@@ -54,6 +69,11 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 	}
 
 	allSuccess := true
+	// outputs holds each finished step's result by step name, for
+	// {{ steps.<name>.stdout }} references in later steps
+	outputs := make(map[string]*ExecutionResult)
+	// skipped holds the names of steps whose when expression was false
+	skipped := make(map[string]bool)
 
 	for i, step := range workflow.Steps {
 		// Check if workflow timeout has expired
@@ -63,11 +83,12 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 			for j := i; j < len(workflow.Steps); j++ {
 				result.Steps[j] = WorkflowStepResult{
 					StepIndex: j,
-					TaskName:  workflow.Steps[j].Task,
+					TaskName:  workflow.Steps[j].Target(),
 					Skipped:   true,
 				}
 			}
-			result.Error = fmt.Sprintf("workflow timed out after %d seconds at step %d (%s)", workflow.Timeout, i, step.Task)
+			result.Error = fmt.Sprintf("%v at step %d (%s)", context.Cause(ctx), i, step.Target())
+			result.ErrorCode = contextCode(ctx)
 			result.Success = false
 			result.Duration = time.Since(startTime)
 			result.StepsRun = i
@@ -76,8 +97,28 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 		default:
 		}
 
+		// Skip the step when its when expression is false; an expression
+		// that fails to evaluate fails the step
+		var err error
+		if step.When != "" {
+			var run bool
+			run, err = template.EvaluateCondition(step.When, conditionData(resolvedParams, outputs, skipped))
+			if err != nil {
+				err = fmt.Errorf("when: %w", err)
+			} else if !run {
+				result.Steps[i] = WorkflowStepResult{
+					StepIndex:  i,
+					TaskName:   step.Target(),
+					Skipped:    true,
+					SkipReason: "when: " + step.When,
+				}
+				skipped[step.Name()] = true
+				continue
+			}
+		}
+
 		// Resolve step params by substituting workflow param values
-		stepParams := resolveStepParams(step.Params, resolvedParams)
+		stepParams := resolveStepParams(step.Params, resolvedParams, outputs)
 
 		// Inject workflow working directory into step params if set;
 		// tasks with expose_working_directory: true will use it, others ignore it
@@ -85,22 +126,61 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 			stepParams["working_directory"] = workflowWorkingDir
 		}
 
-		// Execute the step task
-		execResult, err := we.executor.Execute(step.Task, stepParams)
+		// Start required daemons (step-level and task-level) before the step
+		var execResult *ExecutionResult
+		var started []string
+		if required := we.requiredDaemons(step); err == nil && len(required) > 0 {
+			if we.ensureDaemons == nil {
+				err = fmt.Errorf("cannot start required daemons %v: no process manager", required)
+			} else {
+				started, err = we.ensureDaemons(required, step.Fresh)
+			}
+		}
+
+		// Execute the step task, retrying failed runs per the retry policy;
+		// wait and wait_for steps run here, once
+		attempts := 0
+		if err == nil && step.Builtin() {
+			attempts = 1
+			execResult, err = runBuiltinStep(ctx, step, conditionData(resolvedParams, outputs, skipped), workflowWorkingDir)
+			execResult.DaemonsStarted = started
+		} else if err == nil {
+			retries, delay := we.retryPolicy(step)
+			for {
+				attempts++
+				execResult, err = we.runStep(ctx, step, stepParams)
+				if err != nil {
+					break
+				}
+				if retries > 0 && execResult.SessionID != "" {
+					recordAttempt(execResult.SessionID, attempts)
+				}
+				if execResult.Success || attempts > retries || !waitRetryDelay(ctx, delay) {
+					break
+				}
+			}
+			if execResult != nil {
+				execResult.DaemonsStarted = append(started, execResult.DaemonsStarted...)
+			}
+		}
 
 		stepResult := WorkflowStepResult{
 			StepIndex: i,
-			TaskName:  step.Task,
+			TaskName:  step.Target(),
+			Attempts:  attempts,
 		}
 
 		if err != nil {
 			stepResult.Result = &ExecutionResult{
-				Success:  false,
-				TaskName: step.Task,
-				Error:    err.Error(),
+				Success:   false,
+				Status:    StatusFailure,
+				TaskName:  step.Target(),
+				Error:     err.Error(),
+				ErrorCode: ErrorCode(err),
 			}
 			allSuccess = false
 			result.Steps[i] = stepResult
+			outputs[step.Name()] = stepResult.Result
 			result.StepsRun = i + 1
 			result.StepsFailed = countFailed(result.Steps)
 
@@ -109,12 +189,13 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 				for j := i + 1; j < len(workflow.Steps); j++ {
 					result.Steps[j] = WorkflowStepResult{
 						StepIndex: j,
-						TaskName:  workflow.Steps[j].Task,
+						TaskName:  workflow.Steps[j].Target(),
 						Skipped:   true,
 					}
 				}
 				result.Success = false
-				result.Error = fmt.Sprintf("step %d (%s) failed: %s", i, step.Task, err.Error())
+				result.Error = fmt.Sprintf("step %d (%s) failed: %s", i, step.Target(), err.Error())
+				result.ErrorCode = ErrorCode(err)
 				result.Duration = time.Since(startTime)
 				return result, nil
 			}
@@ -123,6 +204,7 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 
 		stepResult.Result = execResult
 		result.Steps[i] = stepResult
+		outputs[step.Name()] = execResult
 
 		if !execResult.Success {
 			allSuccess = false
@@ -131,12 +213,17 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 				for j := i + 1; j < len(workflow.Steps); j++ {
 					result.Steps[j] = WorkflowStepResult{
 						StepIndex: j,
-						TaskName:  workflow.Steps[j].Task,
+						TaskName:  workflow.Steps[j].Target(),
 						Skipped:   true,
 					}
 				}
 				result.Success = false
-				result.Error = fmt.Sprintf("step %d (%s) failed: %s", i, step.Task, execResult.Error)
+				result.Error = fmt.Sprintf("step %d (%s) failed: %s", i, step.Target(), execResult.Error)
+				result.ErrorCode = execResult.ErrorCode
+				if ctx.Err() != nil {
+					result.Error = fmt.Sprintf("%v at step %d (%s)", context.Cause(ctx), i, step.Target())
+					result.ErrorCode = contextCode(ctx)
+				}
 				result.Duration = time.Since(startTime)
 				result.StepsRun = i + 1
 				result.StepsFailed = countFailed(result.Steps)
@@ -152,6 +239,126 @@ func (we *WorkflowExecutor) Execute(workflowName string, params map[string]inter
 	return result, nil
 }
 
+// runStep runs a step's task, or the workflow it nests, stopping it when ctx
+// is done or the step's timeout passes. A step timeout replaces the task's
+// own. A nested workflow's result is summarized as a single execution: it
+// succeeds if the workflow did, with the combined output of the steps that
+// ran and the exit code of the last one.
+func (we *WorkflowExecutor) runStep(ctx context.Context, step config.WorkflowStep, params map[string]interface{}) (*ExecutionResult, error) {
+	if step.Workflow == "" {
+		return we.executor.executeContext(ctx, step.Task, params, step.Timeout)
+	}
+
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, time.Duration(step.Timeout)*time.Second,
+			fmt.Errorf("step timed out after %d seconds", step.Timeout))
+		defer cancel()
+	}
+	nested, err := we.execute(ctx, step.Workflow, params)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExecutionResult{
+		Success:   nested.Success,
+		Status:    resultStatus(nested.Success),
+		TaskName:  step.Workflow,
+		Duration:  nested.Duration,
+		Error:     nested.Error,
+		ErrorCode: nested.ErrorCode,
+	}
+	var stdout, stderr strings.Builder
+	for _, s := range nested.Steps {
+		if s.Skipped || s.Result == nil {
+			continue
+		}
+		stdout.WriteString(s.Result.Stdout)
+		stderr.WriteString(s.Result.Stderr)
+		result.ExitCode = s.Result.ExitCode
+		result.DaemonsStarted = append(result.DaemonsStarted, s.Result.DaemonsStarted...)
+	}
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+	return result, nil
+}
+
+// conditionData builds the data when expressions are evaluated against: the
+// workflow parameters, plus steps with the success, exit_code, stdout,
+// stderr, and skipped of each earlier step by name.
+func conditionData(params map[string]interface{}, outputs map[string]*ExecutionResult, skipped map[string]bool) map[string]interface{} {
+	steps := make(map[string]interface{})
+	for name, out := range outputs {
+		steps[name] = map[string]interface{}{
+			"success":   out.Success,
+			"exit_code": out.ExitCode,
+			"stdout":    strings.TrimRight(out.Stdout, " \t\r\n"),
+			"stderr":    strings.TrimRight(out.Stderr, " \t\r\n"),
+			"skipped":   false,
+		}
+	}
+	for name := range skipped {
+		steps[name] = map[string]interface{}{"success": false, "exit_code": 0, "stdout": "", "stderr": "", "skipped": true}
+	}
+
+	data := make(map[string]interface{}, len(params)+1)
+	for k, v := range params {
+		data[k] = v
+	}
+	data["steps"] = steps
+	return data
+}
+
+// retryPolicy returns how many times a failed step is retried and the delay
+// in seconds between attempts. Step settings override the task's.
+func (we *WorkflowExecutor) retryPolicy(step config.WorkflowStep) (int, int) {
+	task := we.manifest.Tasks[step.Task]
+	retries, delay := task.Retries, task.RetryDelay
+	if step.Retries > 0 {
+		retries = step.Retries
+	}
+	if step.RetryDelay > 0 {
+		delay = step.RetryDelay
+	}
+	return retries, delay
+}
+
+// waitRetryDelay sleeps for delay seconds before the next attempt. It returns
+// false if the workflow timed out first.
+func waitRetryDelay(ctx context.Context, delay int) bool {
+	timer := time.NewTimer(time.Duration(delay) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// recordAttempt stores the attempt number in a step session's metadata.
+func recordAttempt(sessionID string, attempt int) {
+	if err := logs.UpdateSessionMetadata(sessionID, map[string]interface{}{"attempt": attempt}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record attempt for session %s: %v\n", sessionID, err)
+	}
+}
+
+// requiredDaemons returns the daemons a step needs: its own requires_daemon
+// followed by those of the task it runs, without duplicates.
+func (we *WorkflowExecutor) requiredDaemons(step config.WorkflowStep) []string {
+	var required []string
+	seen := make(map[string]bool)
+	names := append([]string{}, step.RequiresDaemon...)
+	if task, exists := we.manifest.Tasks[step.Task]; exists {
+		names = append(names, task.RequiresDaemon...)
+	}
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
 // resolveWorkflowWorkingDirectory determines the working directory for a workflow.
 // Priority: 1) parameter if exposed and provided, 2) static workflow field
 func resolveWorkflowWorkingDirectory(workflow config.Workflow, params map[string]interface{}) string {
@@ -181,17 +388,45 @@ func applyWorkflowDefaults(workflow config.Workflow, params map[string]interface
 }
 
 // resolveStepParams substitutes workflow parameter values into step param templates.
-// Step params use {{.param_name}} syntax to reference workflow-level parameters.
-func resolveStepParams(stepParams map[string]string, workflowParams map[string]interface{}) map[string]interface{} {
+// Step params use {{.param_name}} syntax to reference workflow-level parameters
+// and {{ steps.<name>.stdout }} (or stderr, exit_code) to reference the output
+// of an earlier step.
+func resolveStepParams(stepParams map[string]string, workflowParams map[string]interface{}, outputs map[string]*ExecutionResult) map[string]interface{} {
 	resolved := make(map[string]interface{})
 
 	for key, tmpl := range stepParams {
-		resolved[key] = substituteTemplate(tmpl, workflowParams)
+		resolved[key] = substituteStepOutputs(substituteTemplate(tmpl, workflowParams), outputs)
 	}
 
 	return resolved
 }
 
+// substituteStepOutputs replaces {{ steps.<name>.<field> }} references with
+// the output of earlier steps. Output has trailing whitespace trimmed, so a
+// command that prints a single line yields just that line. References to
+// steps that have not run resolve to an empty string. With nil outputs, as
+// when resolving without executing, references are left as written.
+func substituteStepOutputs(tmpl string, outputs map[string]*ExecutionResult) string {
+	if outputs == nil {
+		return tmpl
+	}
+	return config.StepOutputPattern.ReplaceAllStringFunc(tmpl, func(ref string) string {
+		m := config.StepOutputPattern.FindStringSubmatch(ref)
+		out := outputs[m[1]]
+		if out == nil {
+			return ""
+		}
+		switch m[2] {
+		case "stdout":
+			return strings.TrimRight(out.Stdout, " \t\r\n")
+		case "stderr":
+			return strings.TrimRight(out.Stderr, " \t\r\n")
+		default:
+			return fmt.Sprintf("%d", out.ExitCode)
+		}
+	})
+}
+
 // substituteTemplate performs simple {{.key}} substitution in a template string
 func substituteTemplate(tmpl string, params map[string]interface{}) string {
 	result := tmpl
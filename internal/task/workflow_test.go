@@ -1,8 +1,11 @@
 package task
 
 import (
+	"net"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"runbookmcp.dev/internal/config"
 	"runbookmcp.dev/internal/logs"
@@ -350,6 +353,7 @@ func TestWorkflowExecutorTimeout(t *testing.T) {
 	executor := NewExecutor(manifest)
 	we := NewWorkflowExecutor(executor, manifest)
 
+	start := time.Now()
 	result, err := we.Execute("ci", map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -358,8 +362,308 @@ func TestWorkflowExecutorTimeout(t *testing.T) {
 	if result.Success {
 		t.Errorf("expected failure due to timeout")
 	}
-	// The slow task runs but the workflow timeout should eventually cause skipping
-	// Since the slow task has no per-task timeout, it will run to the workflow timeout
+	// The workflow timeout interrupts the slow step instead of waiting for it
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("expected the running step to be interrupted, took %s", elapsed)
+	}
+	if !strings.Contains(result.Error, "workflow timed out after 1 seconds at step 0") {
+		t.Errorf("unexpected error: %s", result.Error)
+	}
+	if slow := result.Steps[0].Result; slow == nil || !slow.TimedOut {
+		t.Errorf("expected the slow step to be timed out, got %+v", slow)
+	}
+	if !result.Steps[1].Skipped {
+		t.Errorf("expected the fast step to be skipped")
+	}
+}
+
+func TestWorkflowStepTimeout(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"slow": {Description: "Slow task", Command: "sleep 5", Type: config.TaskTypeOneShot, Timeout: 60},
+			"fast": {Description: "Fast task", Command: "echo done", Type: config.TaskTypeOneShot},
+		},
+		Workflows: map[string]config.Workflow{
+			"ci": {
+				Description: "CI with a step timeout",
+				Steps: []config.WorkflowStep{
+					{Task: "slow", Timeout: 1, ContinueOnFailure: true},
+					{Task: "fast"},
+				},
+			},
+		},
+	}
+
+	we := NewWorkflowExecutor(NewExecutor(manifest), manifest)
+	result, err := we.Execute("ci", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slow := result.Steps[0].Result
+	if slow == nil || !slow.TimedOut || !strings.Contains(slow.Error, "timed out after 1 seconds") {
+		t.Errorf("expected the step timeout to replace the task's, got %+v", slow)
+	}
+	if fast := result.Steps[1].Result; fast == nil || !fast.Success {
+		t.Errorf("expected the next step to run, got %+v", fast)
+	}
+}
+
+func TestWorkflowExecutorRetries(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	// Fails until it has been run three times
+	flaky := `n=$(cat count 2>/dev/null || echo 0); n=$((n+1)); echo $n > count; [ $n -ge 3 ]`
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"flaky":  {Description: "Flaky", Command: flaky, Type: config.TaskTypeOneShot, Retries: 1},
+			"broken": {Description: "Broken", Command: "exit 1", Type: config.TaskTypeOneShot},
+		},
+		Workflows: map[string]config.Workflow{
+			"ci": {
+				Description: "Retry flaky step",
+				Steps:       []config.WorkflowStep{{Task: "flaky", Retries: 2}},
+			},
+			"task_policy": {
+				Description: "Use the task's retries",
+				Steps:       []config.WorkflowStep{{Task: "broken", Retries: 1}, {Task: "flaky"}},
+			},
+		},
+	}
+
+	we := NewWorkflowExecutor(NewExecutor(manifest), manifest)
+
+	result, err := we.Execute("ci", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success after retries, got error: %s", result.Error)
+	}
+	step := result.Steps[0]
+	if step.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", step.Attempts)
+	}
+	metadata, err := logs.ReadSessionMetadata(step.Result.SessionID)
+	if err != nil {
+		t.Fatalf("failed to read session metadata: %v", err)
+	}
+	if metadata.Attempt != 3 {
+		t.Errorf("expected attempt 3 in session metadata, got %d", metadata.Attempt)
+	}
+
+	if err := os.Remove("count"); err != nil {
+		t.Fatal(err)
+	}
+	result, err = we.Execute("task_policy", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("expected failure")
+	}
+	if result.Steps[0].Attempts != 2 {
+		t.Errorf("expected 2 attempts of the broken step, got %d", result.Steps[0].Attempts)
+	}
+	if !result.Steps[1].Skipped {
+		t.Error("expected the step after the failure to be skipped")
+	}
+}
+
+func TestWorkflowExecutorWhen(t *testing.T) {
+	cleanup := setupWorkflowTest(t)
+	defer cleanup()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"test":   {Description: "Test", Command: "exit 1", Type: config.TaskTypeOneShot},
+			"report": {Description: "Report", Command: "echo report", Type: config.TaskTypeOneShot},
+			"deploy": {Description: "Deploy", Command: "echo deploy", Type: config.TaskTypeOneShot},
+			"notify": {Description: "Notify", Command: "echo notify", Type: config.TaskTypeOneShot},
+		},
+		Workflows: map[string]config.Workflow{
+			"ci": {
+				Description: "Conditional steps",
+				Steps: []config.WorkflowStep{
+					{Task: "test", ContinueOnFailure: true},
+					{Task: "report", When: "{{ not .steps.test.success }}"},
+					{Task: "deploy", When: `{{ eq .env "prod" }}`},
+					{Task: "notify", When: "{{ .steps.deploy.skipped }}"},
+				},
+			},
+			"broken": {
+				Description: "Invalid expression",
+				Steps:       []config.WorkflowStep{{Task: "report", When: "{{ len 3 }}"}},
+			},
+		},
+	}
+	we := NewWorkflowExecutor(NewExecutor(manifest), manifest)
+
+	result, err := we.Execute("ci", map[string]interface{}{"env": "staging"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Steps[1].Skipped || result.Steps[1].Result == nil || !result.Steps[1].Result.Success {
+		t.Errorf("expected report to run after the failed test, got %+v", result.Steps[1])
+	}
+	if !result.Steps[2].Skipped || result.Steps[2].SkipReason == "" {
+		t.Errorf("expected deploy to be skipped outside prod, got %+v", result.Steps[2])
+	}
+	if result.Steps[3].Skipped {
+		t.Error("expected notify to run when deploy was skipped")
+	}
+	if result.StepsFailed != 1 {
+		t.Errorf("expected only the test step to fail, got %d", result.StepsFailed)
+	}
+
+	result, err = we.Execute("ci", map[string]interface{}{"env": "prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Steps[2].Skipped || !result.Steps[3].Skipped {
+		t.Errorf("expected deploy to run and notify to be skipped in prod, got %+v", result.Steps)
+	}
+
+	result, err = we.Execute("broken", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success || result.Steps[0].Result == nil {
+		t.Errorf("expected a failing when expression to fail the step, got %+v", result)
+	}
+}
+
+func TestWorkflowExecutorWaitSteps(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"touch": {Description: "Touch", Command: "touch ready.txt", Type: config.TaskTypeOneShot},
+		},
+		Workflows: map[string]config.Workflow{
+			"up": {
+				Description: "Waits",
+				Parameters:  map[string]config.Param{"marker": {Type: "string"}},
+				Steps: []config.WorkflowStep{
+					{Wait: &config.StepWait{Seconds: 0.1}},
+					{Task: "touch"},
+					{WaitFor: &config.StepWaitFor{File: "{{.marker}}", Timeout: 5}},
+					{WaitFor: &config.StepWaitFor{Port: port, Timeout: 5}},
+				},
+			},
+			"missing": {
+				Description: "Never ready",
+				Steps:       []config.WorkflowStep{{WaitFor: &config.StepWaitFor{File: "missing.txt", Timeout: 1}}},
+			},
+		},
+	}
+	we := NewWorkflowExecutor(NewExecutor(manifest), manifest)
+
+	result, err := we.Execute("up", map[string]interface{}{"marker": "ready.txt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected the workflow to succeed, got %+v", result)
+	}
+	if result.Steps[0].TaskName != config.StepWaitName || result.Steps[0].Result.Duration < 100*time.Millisecond {
+		t.Errorf("expected the wait step to sleep, got %+v", result.Steps[0].Result)
+	}
+	if result.Steps[2].TaskName != config.StepWaitForName {
+		t.Errorf("expected a wait_for step, got %q", result.Steps[2].TaskName)
+	}
+
+	result, err = we.Execute("missing", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "missing.txt does not exist") {
+		t.Errorf("expected wait_for to fail on the missing file, got %+v", result)
+	}
+}
+
+func TestWorkflowExecutorNested(t *testing.T) {
+	defer setupWorkflowTest(t)()
+
+	manifest := &config.Manifest{
+		Version: "1.0",
+		Tasks: map[string]config.Task{
+			"unit":   {Description: "Unit tests", Command: "echo unit-{{.target}}", Type: config.TaskTypeOneShot},
+			"e2e":    {Description: "E2E tests", Command: "echo e2e-ok", Type: config.TaskTypeOneShot},
+			"broken": {Description: "Broken", Command: "exit 3", Type: config.TaskTypeOneShot},
+			"report": {Description: "Report", Command: "echo got {{.tests | shellquote}}", Type: config.TaskTypeOneShot},
+		},
+		Workflows: map[string]config.Workflow{
+			"smoke_tests": {
+				Description: "Smoke tests",
+				Steps: []config.WorkflowStep{
+					{Task: "unit", Params: map[string]string{"target": "{{.target}}"}},
+					{Task: "e2e"},
+				},
+			},
+			"ci": {
+				Description: "CI",
+				Steps: []config.WorkflowStep{
+					{Workflow: "smoke_tests", Params: map[string]string{"target": "api"}},
+					{Task: "report", Params: map[string]string{"tests": "{{ steps.smoke_tests.stdout }}"}},
+				},
+			},
+			"failing": {
+				Description: "Failing",
+				Steps: []config.WorkflowStep{
+					{Task: "broken"},
+				},
+			},
+			"release": {
+				Description: "Release",
+				Steps: []config.WorkflowStep{
+					{Workflow: "failing"},
+					{Task: "e2e"},
+				},
+			},
+		},
+	}
+	we := NewWorkflowExecutor(NewExecutor(manifest), manifest)
+
+	result, err := we.Execute("ci", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %s", result.Error)
+	}
+	if got := result.Steps[0]; got.TaskName != "smoke_tests" || got.Result.Stdout != "unit-api\ne2e-ok\n" {
+		t.Errorf("expected the nested workflow's combined output, got %+v", got.Result)
+	}
+	if got := result.Steps[1].Result.Stdout; got != "got unit-api\ne2e-ok\n" {
+		t.Errorf("expected the nested output to be referenceable, got %q", got)
+	}
+
+	result, err = we.Execute("release", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success || !result.Steps[1].Skipped {
+		t.Errorf("expected a failed nested workflow to stop the parent, got %+v", result)
+	}
+	if got := result.Steps[0].Result; got.ExitCode != 3 || got.Status != StatusFailure {
+		t.Errorf("expected the nested failure's exit code, got %+v", got)
+	}
 }
 
 func TestWorkflowManagerExecuteWorkflow(t *testing.T) {
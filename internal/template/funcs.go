@@ -0,0 +1,75 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// builtinFuncs returns the functions available in command, prompt, and
+// resource templates. Functions that transform a value take it last, so they
+// chain in pipelines: {{.name | trim | upper}}.
+func builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		"trim":       strings.TrimSpace,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"default":    defaultValue,
+		"quote":      func(v interface{}) string { return strconv.Quote(fmt.Sprint(v)) },
+		"shellquote": func(v interface{}) string { return shellQuote(fmt.Sprint(v)) },
+		"shellQuote": shellQuote,
+		"join":       join,
+		"now":        now,
+		"uuid":       func() string { return uuid.New().String() },
+		"env":        os.Getenv,
+	}
+}
+
+// defaultValue returns value, or def when value is empty: nil, "", zero, false,
+// or an empty list or map.
+func defaultValue(def, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		if v.Len() == 0 {
+			return def
+		}
+	default:
+		if v.IsZero() {
+			return def
+		}
+	}
+	return value
+}
+
+// join joins the elements of a list with sep. A value that is not a list is
+// returned as is.
+func join(sep string, list interface{}) string {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprint(list)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// now returns the current time in RFC 3339, or in the given Go time layout.
+func now(layout ...string) string {
+	if len(layout) > 0 {
+		return time.Now().Format(layout[0])
+	}
+	return time.Now().Format(time.RFC3339)
+}
@@ -3,6 +3,7 @@ package template
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -20,31 +21,40 @@ type TaskWrapper struct {
 	Name        string
 	Description string
 	Type        config.TaskType
+	manifest    *config.Manifest // Names the task's tools; nil uses the default names
+}
+
+// tool returns the name of the task's tool of the given kind.
+func (t *TaskWrapper) tool(kind string) string {
+	if t.manifest == nil {
+		return kind + "_" + t.Name
+	}
+	return t.manifest.ToolName(kind, t.Name)
 }
 
 // Run returns the tool name for running a one-shot task
 func (t *TaskWrapper) Run() string {
-	return "run_" + t.Name
+	return t.tool(config.ToolRun)
 }
 
 // Start returns the tool name for starting a daemon
 func (t *TaskWrapper) Start() string {
-	return "start_" + t.Name
+	return t.tool(config.ToolStart)
 }
 
 // Stop returns the tool name for stopping a daemon
 func (t *TaskWrapper) Stop() string {
-	return "stop_" + t.Name
+	return t.tool(config.ToolStop)
 }
 
 // Status returns the tool name for checking daemon status
 func (t *TaskWrapper) Status() string {
-	return "status_" + t.Name
+	return t.tool(config.ToolStatus)
 }
 
 // Logs returns the tool name for reading task logs
 func (t *TaskWrapper) Logs() string {
-	return "logs_" + t.Name
+	return t.tool(config.ToolLogs)
 }
 
 // Desc returns the task description
@@ -54,32 +64,74 @@ func (t *TaskWrapper) Desc() string {
 
 // TaskTemplateData wraps tasks for template execution
 type TaskTemplateData struct {
-	Tasks map[string]*TaskWrapper
+	Tasks    map[string]*TaskWrapper
+	Vars     map[string]string
+	manifest *config.Manifest // Names tools for run_task
 }
 
 // ResolvePromptTemplate resolves template variables in prompt content
 // Uses standard delimiters {{ and }} for template actions
 // Provides task operations through TaskWrapper methods
 func ResolvePromptTemplate(content string, tasks map[string]config.Task) (string, error) {
-	funcs := template.FuncMap{
-		"run_task": func(name string) string { return "run_" + name },
-	}
+	return ResolvePromptTemplateWithPartials(content, tasks, nil)
+}
 
-	// Create template with standard delimiters {{ and }}
-	tmpl, err := template.New("prompt").Funcs(funcs).Parse(content)
+// ResolvePromptTemplateWithPartials resolves prompt content like
+// ResolvePromptTemplate, and also renders {{partial "name"}} includes.
+// Partials are rendered with the same data and may include other partials,
+// but not themselves.
+func ResolvePromptTemplateWithPartials(content string, tasks map[string]config.Task, partials map[string]config.PromptPartial) (string, error) {
+	manifest := &config.Manifest{Tasks: tasks}
+	return renderPrompt("prompt", content, TaskTemplateData{Tasks: wrapTasks(manifest), manifest: manifest}, partials, nil)
+}
+
+// ResolveManifestTemplate resolves prompt, resource, or instructions
+// content like ResolvePromptTemplateWithPartials, with the manifest's tasks
+// and partials, and its vars as {{.Vars.name}}.
+func ResolveManifestTemplate(content string, manifest *config.Manifest) (string, error) {
+	vars, err := ResolveVars(manifest.Vars)
 	if err != nil {
-		return "", fmt.Errorf("parse template: %w", err)
+		return "", err
 	}
+	data := TaskTemplateData{Tasks: wrapTasks(manifest), Vars: vars, manifest: manifest}
+	return renderPrompt("prompt", content, data, manifest.PromptPartials, nil)
+}
 
-	// Wrap tasks for template access
-	data := TaskTemplateData{Tasks: make(map[string]*TaskWrapper)}
-	for name, task := range tasks {
-		data.Tasks[name] = &TaskWrapper{
+// wrapTasks wraps the manifest's tasks for template access.
+func wrapTasks(manifest *config.Manifest) map[string]*TaskWrapper {
+	wrapped := make(map[string]*TaskWrapper, len(manifest.Tasks))
+	for name, task := range manifest.Tasks {
+		wrapped[name] = &TaskWrapper{
 			Name:        name,
 			Description: task.Description,
 			Type:        task.Type,
+			manifest:    manifest,
 		}
 	}
+	return wrapped
+}
+
+// renderPrompt renders one prompt template. including holds the partials
+// currently being rendered, to reject include cycles.
+func renderPrompt(name string, content string, data TaskTemplateData, partials map[string]config.PromptPartial, including []string) (string, error) {
+	funcs := builtinFuncs()
+	funcs["run_task"] = func(name string) string { return data.manifest.ToolName(config.ToolRun, name) }
+	funcs["partial"] = func(partial string) (string, error) {
+		p, exists := partials[partial]
+		if !exists {
+			return "", fmt.Errorf("undefined partial '%s'", partial)
+		}
+		if slices.Contains(including, partial) {
+			return "", fmt.Errorf("partial '%s' includes itself", partial)
+		}
+		return renderPrompt(partial, p.Content, data, partials, append(including, partial))
+	}
+
+	// Create template with standard delimiters {{ and }}
+	tmpl, err := template.New(name).Funcs(funcs).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("parse template: %w", err)
+	}
 
 	// Execute template
 	var buf bytes.Buffer
@@ -96,7 +148,7 @@ func ResolvePromptTemplate(content string, tasks map[string]config.Task) (string
 func SubstituteParameters(command string, params map[string]interface{}) (string, error) {
 	// Create template with strict mode (fails on missing keys)
 	tmpl, err := template.New("command").
-		Funcs(template.FuncMap{"shellQuote": shellQuote}).
+		Funcs(builtinFuncs()).
 		Option("missingkey=error").
 		Parse(command)
 	if err != nil {
@@ -111,3 +163,54 @@ func SubstituteParameters(command string, params map[string]interface{}) (string
 
 	return buf.String(), nil
 }
+
+// ResolveVars renders the values of a manifest's vars, which may read the
+// environment with {{env "NAME"}}. Vars cannot refer to each other.
+func ResolveVars(vars map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(vars))
+	for name, value := range vars {
+		tmpl, err := template.New(name).Funcs(builtinFuncs()).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("vars: parse '%s': %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, nil); err != nil {
+			return nil, fmt.Errorf("vars: execute '%s': %w", name, err)
+		}
+		resolved[name] = buf.String()
+	}
+	return resolved, nil
+}
+
+// CommandData returns the data command templates render with: the call's
+// parameters, and vars resolved by ResolveVars under config.VarsKey. params
+// is not modified.
+func CommandData(params map[string]interface{}, vars map[string]string) map[string]interface{} {
+	data := make(map[string]interface{}, len(params)+1)
+	for key, value := range params {
+		data[key] = value
+	}
+	data[config.VarsKey] = vars
+	return data
+}
+
+// EvaluateCondition renders a workflow step's when expression against data
+// and reports whether the step should run. Output that is empty, "false",
+// "0", or "<no value>" (after trimming whitespace) means the step is skipped.
+func EvaluateCondition(expr string, data map[string]interface{}) (bool, error) {
+	tmpl, err := template.New("when").Parse(expr)
+	if err != nil {
+		return false, fmt.Errorf("parse when expression: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return false, fmt.Errorf("execute when expression: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(buf.String())) {
+	case "", "false", "0", "<no value>":
+		return false, nil
+	}
+	return true, nil
+}
@@ -3,6 +3,7 @@ package template
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"runbookmcp.dev/internal/config"
 )
@@ -294,6 +295,113 @@ func TestResolvePromptTemplateTaskFunc(t *testing.T) {
 	}
 }
 
+func TestResolvePromptTemplatePartials(t *testing.T) {
+	tasks := map[string]config.Task{
+		"test": {
+			Description: "Run tests",
+			Command:     "go test ./...",
+			Type:        config.TaskTypeOneShot,
+		},
+	}
+	partials := map[string]config.PromptPartial{
+		"safety":      {Content: "Never push to main."},
+		"conventions": {Content: `Run {{.Tasks.test.Run}} before committing. {{partial "safety"}}`},
+		"loop":        {Content: `{{partial "loop"}}`},
+	}
+
+	tests := []struct {
+		name      string
+		template  string
+		want      string
+		wantError bool
+	}{
+		{
+			name:     "simple partial",
+			template: `Review the diff. {{partial "safety"}}`,
+			want:     "Review the diff. Never push to main.",
+		},
+		{
+			name:     "nested partial with task data",
+			template: `{{partial "conventions"}}`,
+			want:     "Run run_test before committing. Never push to main.",
+		},
+		{
+			name:      "undefined partial",
+			template:  `{{partial "missing"}}`,
+			wantError: true,
+		},
+		{
+			name:      "partial including itself",
+			template:  `{{partial "loop"}}`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ResolvePromptTemplateWithPartials(tt.template, tasks, partials)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if result != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestResolveVars(t *testing.T) {
+	t.Setenv("RUNBOOK_TEST_REGISTRY", "registry.example.com")
+	manifest := &config.Manifest{
+		Tasks: map[string]config.Task{
+			"deploy": {Description: "Deploy", Type: config.TaskTypeOneShot},
+		},
+		Vars: map[string]string{
+			"registry": `{{env "RUNBOOK_TEST_REGISTRY"}}`,
+			"region":   `{{env "RUNBOOK_TEST_UNSET" | default "us-east-1"}}`,
+			"image":    "api",
+		},
+	}
+
+	vars, err := ResolveVars(manifest.Vars)
+	if err != nil {
+		t.Fatalf("ResolveVars() error = %v", err)
+	}
+	if vars["registry"] != "registry.example.com" || vars["region"] != "us-east-1" || vars["image"] != "api" {
+		t.Errorf("ResolveVars() = %v", vars)
+	}
+
+	command, err := SubstituteParameters("docker push {{.Vars.registry}}/{{.Vars.image}}:{{.tag}}", CommandData(map[string]interface{}{"tag": "v1"}, vars))
+	if err != nil {
+		t.Fatalf("SubstituteParameters() error = %v", err)
+	}
+	if command != "docker push registry.example.com/api:v1" {
+		t.Errorf("command = %q", command)
+	}
+	if _, err := SubstituteParameters("echo {{.Vars.missing}}", CommandData(nil, vars)); err == nil {
+		t.Error("expected an error for an undefined var")
+	}
+
+	prompt, err := ResolveManifestTemplate("Deploy to {{.Vars.region}} with {{.Tasks.deploy.Run}}.", manifest)
+	if err != nil {
+		t.Fatalf("ResolveManifestTemplate() error = %v", err)
+	}
+	if prompt != "Deploy to us-east-1 with run_deploy." {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	if _, err := ResolveVars(map[string]string{"bad": "{{.Vars.image}}"}); err == nil {
+		t.Error("expected an error for a var that refers to another var")
+	}
+}
+
 func TestPromptTemplateEdgeCases(t *testing.T) {
 	tasks := map[string]config.Task{
 		"task_with_special_chars": {
@@ -476,3 +584,98 @@ func TestParameterSubstitutionEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestBuiltinFuncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		params  map[string]interface{}
+		want    string
+	}{
+		{
+			name:    "trim and upper",
+			command: "echo {{.env | trim | upper}}",
+			params:  map[string]interface{}{"env": "  staging\n"},
+			want:    "echo STAGING",
+		},
+		{
+			name:    "lower",
+			command: "{{lower .name}}",
+			params:  map[string]interface{}{"name": "API"},
+			want:    "api",
+		},
+		{
+			name:    "replace",
+			command: "{{.branch | replace \"/\" \"-\"}}",
+			params:  map[string]interface{}{"branch": "feature/login"},
+			want:    "feature-login",
+		},
+		{
+			name:    "default for empty value",
+			command: "git checkout {{.branch | default \"main\"}}",
+			params:  map[string]interface{}{"branch": ""},
+			want:    "git checkout main",
+		},
+		{
+			name:    "default keeps value",
+			command: "git checkout {{.branch | default \"main\"}}",
+			params:  map[string]interface{}{"branch": "dev"},
+			want:    "git checkout dev",
+		},
+		{
+			name:    "quote",
+			command: "echo {{quote .msg}}",
+			params:  map[string]interface{}{"msg": `say "hi"`},
+			want:    `echo "say \"hi\""`,
+		},
+		{
+			name:    "shellquote",
+			command: "echo {{.msg | shellquote}}",
+			params:  map[string]interface{}{"msg": "it's $(whoami)"},
+			want:    "echo 'it'\\''s $(whoami)'",
+		},
+		{
+			name:    "shellquote non-string",
+			command: "sleep {{shellquote .n}}",
+			params:  map[string]interface{}{"n": 5},
+			want:    "sleep '5'",
+		},
+		{
+			name:    "join",
+			command: "go test {{join \" \" .pkgs}}",
+			params:  map[string]interface{}{"pkgs": []interface{}{"./a", "./b"}},
+			want:    "go test ./a ./b",
+		},
+		{
+			name:    "now with layout",
+			command: "{{now \"2006\"}}",
+			params:  map[string]interface{}{},
+			want:    time.Now().Format("2006"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SubstituteParameters(tt.command, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, result)
+			}
+		})
+	}
+
+	id, err := SubstituteParameters("{{uuid}}", nil)
+	if err != nil || len(id) != 36 {
+		t.Errorf("expected a uuid, got %q (%v)", id, err)
+	}
+
+	prompt, err := ResolvePromptTemplate("{{upper \"run\"}} {{.Tasks.build.Run | quote}}", map[string]config.Task{"build": {}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prompt != `RUN "run_build"` {
+		t.Errorf("expected functions in prompts, got %q", prompt)
+	}
+}
@@ -0,0 +1,217 @@
+// Package runbook is the supported Go API for embedding runbook in other
+// tools. It loads manifests, runs tasks, workflows, and daemons, and serves
+// the same MCP tools, resources, and prompts as the runbook binary:
+//
+//	manifest, _, err := runbook.LoadManifest(".runbook")
+//	if err != nil {
+//		return err
+//	}
+//	srv, err := runbook.NewServer(manifest, runbook.Options{Version: "1.0.0"})
+//	if err != nil {
+//		return err
+//	}
+//	defer srv.Close()
+//	return srv.ServeStdio()
+package runbook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"runbookmcp.dev/internal/auth"
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/logs"
+	"runbookmcp.dev/internal/mcputil"
+	"runbookmcp.dev/internal/process"
+	mcpserver "runbookmcp.dev/internal/server"
+	"runbookmcp.dev/internal/task"
+)
+
+// Manifest is a parsed and validated runbook configuration.
+type Manifest = config.Manifest
+
+// Task is a single task definition within a Manifest.
+type Task = config.Task
+
+// Workflow is a workflow definition within a Manifest.
+type Workflow = config.Workflow
+
+// TaskManager runs tasks, workflows, and daemons for a Manifest.
+type TaskManager = task.Manager
+
+// ProcessManager starts and tracks daemon processes.
+type ProcessManager = task.ProcessManager
+
+// ExecutionResult is the result of running a oneshot task.
+type ExecutionResult = task.ExecutionResult
+
+// WorkflowResult is the result of running a workflow.
+type WorkflowResult = task.WorkflowResult
+
+// DaemonStartResult is the result of starting a daemon.
+type DaemonStartResult = task.DaemonStartResult
+
+// Authenticator decides whether an HTTP request may reach the server.
+type Authenticator = auth.Authenticator
+
+// Identity describes a caller accepted by an Authenticator.
+type Identity = auth.Identity
+
+// IdentityFromContext returns the identity of the caller for a request
+// served in HTTP mode.
+var IdentityFromContext = auth.IdentityFromContext
+
+// LoadManifest loads a manifest from path (a file or directory), falling back
+// to the .runbook/ directory when path is empty or does not exist. The
+// overrides file is applied if present. The returned bool reports whether a
+// config was found; when false the manifest is empty but usable.
+func LoadManifest(path string) (*Manifest, bool, error) {
+	return config.LoadManifest(path)
+}
+
+// NewTaskManager creates a TaskManager for running tasks without an MCP
+// server. pm may be nil if the manifest's daemons are never started.
+func NewTaskManager(manifest *Manifest, pm ProcessManager) *TaskManager {
+	return task.NewManager(manifest, pm)
+}
+
+// Options configures NewServer. The zero value is usable.
+type Options struct {
+	// Version is reported to MCP clients (default "dev").
+	Version string
+	// ConfigPath is the path reloaded by the refresh_config tool. Empty
+	// means the default .runbook/ location.
+	ConfigPath string
+	// ProcessManager manages daemons. Defaults to the same PID-file based
+	// manager the runbook binary uses.
+	ProcessManager ProcessManager
+	// Auth authenticates HTTP requests, replacing the manifest's
+	// server.auth settings. It is not used in stdio mode.
+	Auth Authenticator
+}
+
+// Server is an embeddable runbook MCP server.
+type Server struct {
+	srv            *mcpserver.Server
+	processManager ProcessManager
+}
+
+// NewServer creates a server for manifest. A nil manifest starts the server
+// with an empty configuration, which registers the init tool. Session logs
+// are written under the state directory in the current working directory.
+func NewServer(manifest *Manifest, opts Options) (*Server, error) {
+	if err := logs.Setup(); err != nil {
+		return nil, fmt.Errorf("failed to setup logs: %w", err)
+	}
+
+	loaded := manifest != nil
+	if manifest == nil {
+		manifest = &config.Manifest{
+			Version: "1.0",
+			Tasks:   make(map[string]config.Task),
+		}
+	}
+	if opts.Version == "" {
+		opts.Version = "dev"
+	}
+	if opts.ProcessManager == nil {
+		opts.ProcessManager = process.NewManager()
+	}
+
+	manager := task.NewManager(manifest, opts.ProcessManager)
+	srv := mcpserver.NewServer(manifest, manager, opts.ProcessManager, loaded, opts.Version, opts.ConfigPath)
+	if opts.Auth != nil {
+		srv.SetAuthenticator(opts.Auth)
+	}
+	return &Server{
+		srv:            srv,
+		processManager: opts.ProcessManager,
+	}, nil
+}
+
+// ServeStdio serves MCP over stdin/stdout until the input is closed.
+func (s *Server) ServeStdio() error {
+	return s.srv.Serve()
+}
+
+// ServeHTTP serves MCP over streamable HTTP on addr (e.g. ":8080"). It
+// registers the server so CLI commands in the same project proxy to it, and
+// stops all daemons on SIGINT/SIGTERM.
+func (s *Server) ServeHTTP(addr string) error {
+	return s.srv.ServeHTTP(addr)
+}
+
+// Tasks returns the task manager backing the server's tools. The manager is
+// replaced when the config is refreshed, so don't hold on to it.
+func (s *Server) Tasks() *TaskManager {
+	return s.srv.Manager()
+}
+
+// MCPServer returns the underlying mcp-go server, for adding tools or
+// serving over a custom transport.
+func (s *Server) MCPServer() *server.MCPServer {
+	return s.srv.GetMCPServer()
+}
+
+// Refresh reloads the manifest from the configured path and re-registers
+// tools, resources, and prompts.
+func (s *Server) Refresh() error {
+	_, err := s.srv.Refresh()
+	return err
+}
+
+// Close stops all daemons started by the server.
+func (s *Server) Close() error {
+	return s.processManager.StopAll()
+}
+
+// ServeStdioProxy forwards MCP traffic on stdin/stdout to a runbook HTTP
+// server at addr.
+func ServeStdioProxy(addr string) error {
+	return ServeStdioProxyWithOptions(addr, ProxyOptions{})
+}
+
+// ProxyOptions controls how the stdio proxy recovers when its server stops
+// responding. The proxy first reconnects with backoff, replaying the
+// client's handshake.
+type ProxyOptions struct {
+	// ReconnectTimeout bounds reconnect attempts. Zero means 30 seconds.
+	ReconnectTimeout time.Duration
+	// Fallback builds a server that serves the rest of the session in
+	// process when the HTTP server does not come back. Nil makes the proxy
+	// return an error instead.
+	Fallback func() (*Server, error)
+	// Verify, if set, is called before every connect and reconnect; an
+	// error refuses the server, as if it were unreachable.
+	Verify func() error
+	// TokenEnv names the env var holding the bearer token sent to the
+	// server, as in server.auth.token_env. Empty means RUNBOOK_TOKEN. TLS
+	// files come from $RUNBOOK_CLIENT_CERT, $RUNBOOK_CLIENT_KEY, and
+	// $RUNBOOK_CA_CERT.
+	TokenEnv string
+	// ServerToken, if set, returns the token from the server registry, which
+	// servers without server.auth require. It is called on every connect.
+	ServerToken func() string
+}
+
+// ServeStdioProxyWithOptions is ServeStdioProxy with control over
+// reconnection and fallback.
+func ServeStdioProxyWithOptions(addr string, opts ProxyOptions) error {
+	proxyOpts := mcpserver.ProxyOptions{
+		ReconnectTimeout: opts.ReconnectTimeout,
+		Verify:           opts.Verify,
+		Client:           mcputil.NewClientConfig(opts.TokenEnv),
+		ServerToken:      opts.ServerToken,
+	}
+	if opts.Fallback != nil {
+		proxyOpts.Fallback = func() (*mcpserver.Server, error) {
+			s, err := opts.Fallback()
+			if err != nil {
+				return nil, err
+			}
+			return s.srv, nil
+		}
+	}
+	return mcpserver.ServeStdioProxy(addr, proxyOpts)
+}
@@ -0,0 +1,83 @@
+package runbook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(oldWd); err != nil {
+			t.Errorf("failed to restore working directory: %v", err)
+		}
+	})
+	return tmpDir
+}
+
+func TestNewServer(t *testing.T) {
+	dir := chdirTemp(t)
+	configPath := filepath.Join(dir, "runbook.yaml")
+	content := `version: "1.0"
+tasks:
+  hello:
+    description: "Say hello"
+    command: "echo hello"
+    type: oneshot
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	manifest, loaded, err := LoadManifest(configPath)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if !loaded {
+		t.Fatal("expected config to be loaded")
+	}
+
+	srv, err := NewServer(manifest, Options{ConfigPath: configPath})
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	defer srv.Close()
+
+	if srv.MCPServer().GetTool("run_hello") == nil {
+		t.Error("expected run_hello tool to be registered")
+	}
+	if srv.MCPServer().GetTool("init") != nil {
+		t.Error("expected init tool not to be registered when a config is loaded")
+	}
+
+	result, err := srv.Tasks().ExecuteOneShot("hello", nil)
+	if err != nil {
+		t.Fatalf("ExecuteOneShot failed: %v", err)
+	}
+	if !result.Success || result.Stdout != "hello\n" {
+		t.Errorf("unexpected result: success=%v stdout=%q error=%s", result.Success, result.Stdout, result.Error)
+	}
+}
+
+func TestNewServerNilManifest(t *testing.T) {
+	chdirTemp(t)
+
+	srv, err := NewServer(nil, Options{})
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	defer srv.Close()
+
+	if srv.MCPServer().GetTool("init") == nil {
+		t.Error("expected init tool to be registered without a config")
+	}
+}
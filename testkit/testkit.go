@@ -0,0 +1,305 @@
+// Package testkit provides helpers for testing runbook configurations from Go.
+//
+// It loads a manifest exactly as the runbook binary would, resolves task and
+// workflow invocations against a fake executor that records commands instead
+// of running them, and exposes the generated MCP tool schemas. Projects with
+// complex manifests can use it to assert on their runbooks in their own CI:
+//
+//	func TestRunbook(t *testing.T) {
+//		rb := testkit.MustLoad(t, ".runbook")
+//		rb.AssertCommand(t, "test", map[string]interface{}{"pkg": "./..."}, "go test './...'")
+//		rb.AssertToolParam(t, "run_test", "pkg", true)
+//	}
+package testkit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"runbookmcp.dev/internal/config"
+	"runbookmcp.dev/internal/server"
+	"runbookmcp.dev/internal/task"
+)
+
+// Call is a single recorded invocation captured by the fake executor.
+type Call struct {
+	Task       string
+	Command    string
+	Shell      string
+	WorkingDir string
+	Env        map[string]string
+	Params     map[string]interface{}
+}
+
+// Tool describes a generated MCP tool and its input schema.
+type Tool struct {
+	Name        string
+	Description string
+	Properties  map[string]Property
+	Required    []string
+}
+
+// Property describes a single input parameter of a generated tool.
+type Property struct {
+	Type        string
+	Description string
+}
+
+// Runbook is a loaded manifest wired to a fake executor.
+type Runbook struct {
+	manifest *config.Manifest
+	manager  *task.Manager
+	tools    map[string]Tool
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Load loads and validates the manifest at path (a file or a .runbook/
+// directory), applying overrides the same way the runbook binary does.
+func Load(path string) (*Runbook, error) {
+	manifest, loaded, err := config.LoadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if !loaded {
+		return nil, fmt.Errorf("no manifest found at %s", path)
+	}
+
+	// The inspection constructors leave the caller's session logs and
+	// session sink alone
+	pm := &fakeProcessManager{}
+	manager := task.NewInspectionManager(manifest, pm)
+	srv := server.NewInspectionServer(manifest, manager, pm, true, "testkit", path)
+
+	tools := make(map[string]Tool)
+	for name, st := range srv.GetMCPServer().ListTools() {
+		t := Tool{
+			Name:        name,
+			Description: st.Tool.Description,
+			Properties:  make(map[string]Property),
+			Required:    append([]string(nil), st.Tool.InputSchema.Required...),
+		}
+		sort.Strings(t.Required)
+		for pn, raw := range st.Tool.InputSchema.Properties {
+			prop := Property{}
+			if m, ok := raw.(map[string]interface{}); ok {
+				prop.Type, _ = m["type"].(string)
+				prop.Description, _ = m["description"].(string)
+			}
+			t.Properties[pn] = prop
+		}
+		tools[name] = t
+	}
+
+	return &Runbook{
+		manifest: manifest,
+		manager:  manager,
+		tools:    tools,
+	}, nil
+}
+
+// MustLoad is like Load but fails the test on error.
+func MustLoad(t testing.TB, path string) *Runbook {
+	t.Helper()
+	rb, err := Load(path)
+	if err != nil {
+		t.Fatalf("testkit: failed to load manifest: %v", err)
+	}
+	return rb
+}
+
+// Tasks returns the sorted names of all enabled tasks.
+func (r *Runbook) Tasks() []string {
+	var names []string
+	for name, t := range r.manifest.Tasks {
+		if !t.Disabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Workflows returns the sorted names of all enabled workflows.
+func (r *Runbook) Workflows() []string {
+	var names []string
+	for name, wf := range r.manifest.Workflows {
+		if !wf.Disabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run resolves a task invocation and records it with the fake executor.
+// Nothing is executed.
+func (r *Runbook) Run(taskName string, params map[string]interface{}) (Call, error) {
+	if err := r.checkRequired(taskName, params); err != nil {
+		return Call{}, err
+	}
+	rt, err := r.manager.Resolve(taskName, params)
+	if err != nil {
+		return Call{}, err
+	}
+	call := callFromResolved(rt)
+	r.record(call)
+	return call, nil
+}
+
+// RunWorkflow resolves every step of a workflow and records each one with the
+// fake executor, returning the calls in step order.
+func (r *Runbook) RunWorkflow(workflowName string, params map[string]interface{}) ([]Call, error) {
+	resolved, err := r.manager.ResolveWorkflow(workflowName, params)
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]Call, 0, len(resolved))
+	for _, rt := range resolved {
+		call := callFromResolved(rt)
+		r.record(call)
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// Calls returns every invocation recorded so far, in order.
+func (r *Runbook) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Reset clears the recorded invocations.
+func (r *Runbook) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+// Tools returns every generated MCP tool, sorted by name.
+func (r *Runbook) Tools() []Tool {
+	tools := make([]Tool, 0, len(r.tools))
+	for _, t := range r.tools {
+		tools = append(tools, t)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// Tool returns the generated MCP tool with the given name.
+func (r *Runbook) Tool(name string) (Tool, bool) {
+	t, ok := r.tools[name]
+	return t, ok
+}
+
+// AssertCommand fails the test unless the task resolves to want.
+func (r *Runbook) AssertCommand(t testing.TB, taskName string, params map[string]interface{}, want string) {
+	t.Helper()
+	call, err := r.Run(taskName, params)
+	if err != nil {
+		t.Errorf("task '%s': %v", taskName, err)
+		return
+	}
+	if call.Command != want {
+		t.Errorf("task '%s': expected command %q, got %q", taskName, want, call.Command)
+	}
+}
+
+// AssertRunError fails the test unless resolving the task returns an error.
+func (r *Runbook) AssertRunError(t testing.TB, taskName string, params map[string]interface{}) {
+	t.Helper()
+	if _, err := r.Run(taskName, params); err == nil {
+		t.Errorf("task '%s': expected an error, got none", taskName)
+	}
+}
+
+// AssertTool fails the test unless a tool with the given name is registered.
+func (r *Runbook) AssertTool(t testing.TB, name string) {
+	t.Helper()
+	if _, ok := r.tools[name]; !ok {
+		t.Errorf("expected tool '%s' to be registered", name)
+	}
+}
+
+// AssertNoTool fails the test if a tool with the given name is registered.
+func (r *Runbook) AssertNoTool(t testing.TB, name string) {
+	t.Helper()
+	if _, ok := r.tools[name]; ok {
+		t.Errorf("expected tool '%s' not to be registered", name)
+	}
+}
+
+// AssertToolParam fails the test unless the tool exposes the parameter with
+// the given required-ness.
+func (r *Runbook) AssertToolParam(t testing.TB, toolName, param string, required bool) {
+	t.Helper()
+	tool, ok := r.tools[toolName]
+	if !ok {
+		t.Errorf("expected tool '%s' to be registered", toolName)
+		return
+	}
+	if _, ok := tool.Properties[param]; !ok {
+		t.Errorf("tool '%s': expected parameter '%s'", toolName, param)
+		return
+	}
+	isRequired := false
+	for _, name := range tool.Required {
+		if name == param {
+			isRequired = true
+			break
+		}
+	}
+	if isRequired != required {
+		t.Errorf("tool '%s': parameter '%s' required=%v, want %v", toolName, param, isRequired, required)
+	}
+}
+
+// checkRequired mirrors the required-parameter check performed by MCP
+// clients and the CLI before a task reaches the executor.
+func (r *Runbook) checkRequired(taskName string, params map[string]interface{}) error {
+	def, exists := r.manifest.Tasks[taskName]
+	if !exists {
+		return fmt.Errorf("task '%s' not found", taskName)
+	}
+	for name, p := range def.Parameters {
+		if !p.Required {
+			continue
+		}
+		if _, ok := params[name]; !ok {
+			return fmt.Errorf("required parameter '%s' is missing", name)
+		}
+	}
+	return nil
+}
+
+func (r *Runbook) record(call Call) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func callFromResolved(rt *task.ResolvedTask) Call {
+	return Call{
+		Task:       rt.TaskName,
+		Command:    rt.Command,
+		Shell:      rt.Shell,
+		WorkingDir: rt.WorkingDir,
+		Env:        rt.Env,
+		Params:     rt.Params,
+	}
+}
+
+// fakeProcessManager satisfies task.ProcessManager without starting anything.
+type fakeProcessManager struct{}
+
+func (fakeProcessManager) Start(string, string, string, map[string]string, string, string, string) error {
+	return nil
+}
+func (fakeProcessManager) Stop(string) error                   { return nil }
+func (fakeProcessManager) Status(string) (bool, int, error)    { return false, 0, nil }
+func (fakeProcessManager) GetSessionID(string) (string, error) { return "", nil }
+func (fakeProcessManager) StopAll() error                      { return nil }
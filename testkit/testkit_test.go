@@ -0,0 +1,142 @@
+package testkit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"runbookmcp.dev/internal/logs"
+)
+
+const testManifest = `version: "1.0"
+tasks:
+  test:
+    description: "Run tests"
+    command: "go test {{.pkg}}"
+    parameters:
+      pkg:
+        type: string
+        required: true
+        description: "Package pattern"
+  lint:
+    description: "Run linter"
+    command: "golangci-lint run {{.flags}}"
+    parameters:
+      flags:
+        type: string
+        description: "Extra flags"
+        default: "--fast"
+  dev:
+    description: "Dev server"
+    command: "npm run dev"
+    type: daemon
+  hidden:
+    description: "Hidden from MCP"
+    command: "echo hidden"
+    disable_mcp: true
+workflows:
+  ci:
+    description: "CI pipeline"
+    parameters:
+      pkg:
+        type: string
+        description: "Package pattern"
+        default: "./..."
+    steps:
+      - task: lint
+      - task: test
+        params:
+          pkg: "{{.pkg}}"
+`
+
+func loadTestRunbook(t *testing.T) *Runbook {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.yaml")
+	if err := os.WriteFile(path, []byte(testManifest), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	return MustLoad(t, path)
+}
+
+func TestRunResolvesCommand(t *testing.T) {
+	rb := loadTestRunbook(t)
+
+	rb.AssertCommand(t, "test", map[string]interface{}{"pkg": "./internal/..."}, "go test ./internal/...")
+	rb.AssertCommand(t, "lint", nil, "golangci-lint run --fast")
+	rb.AssertRunError(t, "test", nil)
+	rb.AssertRunError(t, "missing", nil)
+
+	calls := rb.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 recorded calls, got %d", len(calls))
+	}
+	if calls[0].Shell != "/bin/bash" {
+		t.Errorf("expected default shell /bin/bash, got %s", calls[0].Shell)
+	}
+
+	rb.Reset()
+	if len(rb.Calls()) != 0 {
+		t.Error("expected Reset to clear recorded calls")
+	}
+}
+
+func TestRunWorkflowResolvesSteps(t *testing.T) {
+	rb := loadTestRunbook(t)
+
+	calls, err := rb.RunWorkflow("ci", nil)
+	if err != nil {
+		t.Fatalf("RunWorkflow failed: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(calls))
+	}
+	if calls[1].Command != "go test ./..." {
+		t.Errorf("expected step param default to flow through, got %q", calls[1].Command)
+	}
+}
+
+func TestToolSchemas(t *testing.T) {
+	rb := loadTestRunbook(t)
+
+	rb.AssertTool(t, "run_test")
+	rb.AssertTool(t, "start_dev")
+	rb.AssertTool(t, "run_workflow_ci")
+	rb.AssertNoTool(t, "run_hidden")
+	rb.AssertToolParam(t, "run_test", "pkg", true)
+	rb.AssertToolParam(t, "run_lint", "flags", false)
+
+	tool, ok := rb.Tool("run_test")
+	if !ok {
+		t.Fatal("expected run_test tool")
+	}
+	if tool.Properties["pkg"].Type != "string" {
+		t.Errorf("expected pkg type string, got %q", tool.Properties["pkg"].Type)
+	}
+
+	if got := rb.Tasks(); len(got) != 4 {
+		t.Errorf("expected 4 tasks, got %v", got)
+	}
+	if got := rb.Workflows(); len(got) != 1 || got[0] != "ci" {
+		t.Errorf("expected [ci], got %v", got)
+	}
+}
+
+func TestLoadKeepsSessionLogs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	sessionID := logs.GenerateSessionID()
+	if err := logs.CreateSessionDirectory(sessionID); err != nil {
+		t.Fatal(err)
+	}
+	old := &logs.SessionMetadata{SessionID: sessionID, TaskName: "build", StartTime: time.Now().Add(-30 * 24 * time.Hour)}
+	if err := logs.WriteSessionMetadata(sessionID, old); err != nil {
+		t.Fatal(err)
+	}
+
+	loadTestRunbook(t)
+
+	if _, err := logs.ReadSessionMetadata(sessionID); err != nil {
+		t.Errorf("expected Load to leave the session logs alone: %v", err)
+	}
+}